@@ -26,7 +26,8 @@ type CandidateRepo struct {
 // a port here lets the audit unit tests run without seeding SQLite.
 type DataProvider interface {
 	// OtherDriftCandidates returns rows where `primary_subcategory='other'`,
-	// `is_curated_list=0`, and `status='active'`. Topics MUST already be
+	// `is_curated_list=0`, and `status='active'`, minus repos with a
+	// secondary label in a concrete subcategory. Topics MUST already be
 	// populated (the production impl wraps the DB query and a topic
 	// live-fetch via internal/github).
 	OtherDriftCandidates(ctx context.Context) ([]CandidateRepo, error)
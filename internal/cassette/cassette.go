@@ -0,0 +1,383 @@
+// Package cassette records the HTTP traffic of github-radar's outbound
+// clients (GitHub, Ollama, gharchive.org) to a directory and replays it,
+// so a scan can be reproduced without the network.
+//
+// A cassette directory holds one subdirectory per service. Every
+// interaction is a numbered JSON file with the request line, the
+// response status and headers, and a sibling .body file with the
+// response body as received. Request headers are never stored, so
+// tokens stay out of the cassette; GitHub App installation tokens in
+// response bodies are redacted.
+//
+// Replay serves a request the first unserved recording with the same
+// method, URL and body. When there is none it falls back to the same
+// method and path, ignoring the query and body, which absorbs
+// time-dependent parameters such as `since=`. Recordings are served in
+// the order they were made; once all of a match's recordings have been
+// served, the last one is repeated.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Mode selects what a Cassette does with traffic.
+type Mode int
+
+const (
+	// Record passes requests through and writes every interaction.
+	Record Mode = iota + 1
+	// Replay serves recorded interactions and never touches the network.
+	Replay
+)
+
+// String returns the mode's flag name.
+func (m Mode) String() string {
+	switch m {
+	case Record:
+		return "record"
+	case Replay:
+		return "replay"
+	}
+	return "off"
+}
+
+// Service names, one cassette subdirectory each.
+const (
+	ServiceGitHub    = "github"
+	ServiceOllama    = "ollama"
+	ServiceOpenAI    = "openai"
+	ServiceAnthropic = "anthropic"
+	ServiceGHArchive = "gharchive"
+)
+
+// maxInlineRequestBody bounds the request bodies stored in the JSON
+// file. Larger ones are matched by hash only.
+const maxInlineRequestBody = 64 << 10
+
+// ErrNotRecorded is returned in replay mode for a request the cassette
+// has no recording for.
+var ErrNotRecorded = errors.New("cassette: request not recorded")
+
+// Cassette records or replays HTTP interactions under one directory.
+type Cassette struct {
+	dir  string
+	mode Mode
+	seq  atomic.Int64
+
+	// base is the transport Record sends requests with.
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	exact  map[string][]*entry // service + method + URL + body hash
+	loose  map[string][]*entry // service + method + path
+	served map[*entry]bool
+}
+
+// interaction is the JSON file of one recorded request.
+type interaction struct {
+	Request struct {
+		Method     string `json:"method"`
+		URL        string `json:"url"`
+		Body       string `json:"body,omitempty"`
+		BodySHA256 string `json:"body_sha256,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Status int         `json:"status"`
+		Header http.Header `json:"header"`
+	} `json:"response"`
+	// Error is the transport error the request failed with, in which
+	// case there is no response.
+	Error string `json:"error,omitempty"`
+}
+
+// entry is a loaded interaction and where its body lives.
+type entry struct {
+	interaction
+	bodyPath string
+}
+
+// Open prepares dir for mode. Record creates the directory; Replay
+// loads every interaction recorded in it.
+func Open(dir string, mode Mode) (*Cassette, error) {
+	c := &Cassette{
+		dir:    dir,
+		mode:   mode,
+		base:   http.DefaultTransport,
+		exact:  make(map[string][]*entry),
+		loose:  make(map[string][]*entry),
+		served: make(map[*entry]bool),
+	}
+	switch mode {
+	case Record:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating cassette dir: %w", err)
+		}
+		// Continue numbering after an earlier recording in the same
+		// directory so files are never overwritten.
+		files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+		c.seq.Store(int64(len(files)))
+	case Replay:
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cassette: unknown mode %d", mode)
+	}
+	return c, nil
+}
+
+// Mode returns what the cassette does.
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Dir returns the cassette directory.
+func (c *Cassette) Dir() string {
+	return c.dir
+}
+
+// load indexes every interaction under c.dir, in recording order.
+func (c *Cassette) load() error {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*", "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("cassette %s: no recorded interactions", c.dir)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return filepath.Base(files[i]) < filepath.Base(files[j])
+	})
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("reading cassette: %w", err)
+		}
+		e := &entry{bodyPath: strings.TrimSuffix(f, ".json") + ".body"}
+		if err := json.Unmarshal(data, &e.interaction); err != nil {
+			return fmt.Errorf("cassette %s: %w", f, err)
+		}
+		service := filepath.Base(filepath.Dir(f))
+		exact, loose, err := keys(service, e.Request.Method, e.Request.URL, e.Request.BodySHA256)
+		if err != nil {
+			return fmt.Errorf("cassette %s: %w", f, err)
+		}
+		c.exact[exact] = append(c.exact[exact], e)
+		c.loose[loose] = append(c.loose[loose], e)
+	}
+	return nil
+}
+
+// keys returns the exact and loose match keys of a request.
+func keys(service, method, rawURL, bodyHash string) (exact, loose string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	return service + " " + method + " " + rawURL + " " + bodyHash,
+		service + " " + method + " " + u.Scheme + "://" + u.Host + u.Path, nil
+}
+
+// Transport returns the round tripper the service's HTTP client should
+// use: a recording one passing requests on to the default transport,
+// or a replaying one.
+func (c *Cassette) Transport(service string) http.RoundTripper {
+	return &transport{c: c, service: service}
+}
+
+type transport struct {
+	c       *Cassette
+	service string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	hash := ""
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		hash = hex.EncodeToString(sum[:])
+	}
+	if t.c.mode == Replay {
+		return t.c.replay(t.service, req, hash)
+	}
+	return t.c.record(t.service, req, body, hash)
+}
+
+// readRequestBody reads req's body and puts an unread copy back.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// record sends req and writes the interaction. The body file is written
+// as the caller reads the response.
+func (c *Cassette) record(service string, req *http.Request, body []byte, hash string) (*http.Response, error) {
+	var in interaction
+	in.Request.Method = req.Method
+	in.Request.URL = req.URL.String()
+	in.Request.BodySHA256 = hash
+	if len(body) <= maxInlineRequestBody {
+		in.Request.Body = string(body)
+	}
+
+	resp, err := c.base.RoundTrip(req)
+
+	dir := filepath.Join(c.dir, service)
+	if mkErr := os.MkdirAll(dir, 0o755); mkErr != nil {
+		return resp, err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%06d", c.seq.Add(1)))
+
+	if err != nil {
+		in.Error = err.Error()
+		writeJSON(base+".json", in)
+		return nil, err
+	}
+
+	in.Response.Status = resp.StatusCode
+	in.Response.Header = resp.Header.Clone()
+	in.Response.Header.Del("Set-Cookie")
+	writeJSON(base+".json", in)
+
+	if strings.HasSuffix(req.URL.Path, "/access_tokens") {
+		// GitHub App installation tokens are short-lived but still
+		// secrets; replay never sends them anywhere.
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		_ = os.WriteFile(base+".body", redactToken(data), 0o644)
+		return resp, nil
+	}
+
+	f, ferr := os.Create(base + ".body")
+	if ferr != nil {
+		return resp, nil
+	}
+	resp.Body = &recordingBody{rc: resp.Body, f: f}
+	return resp, nil
+}
+
+// redactToken blanks the "token" field of a JSON object.
+func redactToken(data []byte) []byte {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(data, &obj) != nil {
+		return data
+	}
+	if _, ok := obj["token"]; !ok {
+		return data
+	}
+	obj["token"] = json.RawMessage(`"REDACTED"`)
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+func writeJSON(path string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+// recordingBody copies the response body to the cassette as it is read.
+// Close copies whatever the caller left unread, so the recording holds
+// the whole body.
+type recordingBody struct {
+	rc io.ReadCloser
+	f  *os.File
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		_, _ = b.f.Write(p[:n])
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	_, _ = io.Copy(b.f, b.rc)
+	b.f.Close()
+	return b.rc.Close()
+}
+
+// replay serves req from the recording.
+func (c *Cassette) replay(service string, req *http.Request, hash string) (*http.Response, error) {
+	exact, loose, err := keys(service, req.Method, req.URL.String(), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	e := c.next(c.exact[exact])
+	if e == nil {
+		e = c.next(c.loose[loose])
+	}
+	c.mu.Unlock()
+	if e == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, req.URL)
+	}
+	if e.Error != "" {
+		return nil, errors.New(e.Error)
+	}
+
+	body, err := os.ReadFile(e.bodyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Response.Status, http.StatusText(e.Response.Status)),
+		StatusCode:    e.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// next returns the first unserved entry of candidates, or the last one
+// when all have been served. Caller must hold c.mu.
+func (c *Cassette) next(candidates []*entry) *entry {
+	if len(candidates) == 0 {
+		return nil
+	}
+	for _, e := range candidates {
+		if !c.served[e] {
+			c.served[e] = true
+			return e
+		}
+	}
+	return candidates[len(candidates)-1]
+}
@@ -0,0 +1,131 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, rt http.RoundTripper, url string) (int, string, error) {
+	t.Helper()
+	client := &http.Client{Transport: rt}
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer secret-pat")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), nil
+}
+
+func TestCassette_RecordThenReplayOffline(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		switch r.URL.Path {
+		case "/repos/o/r":
+			w.Write([]byte(`{"stargazers_count":` + strings.Repeat("1", calls) + `}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	dir := t.TempDir()
+
+	rec, err := Open(dir, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := get(t, rec.Transport(ServiceGitHub), server.URL+"/repos/o/r?since=2026-10-0"+string(rune('1'+i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if status, _, _ := get(t, rec.Transport(ServiceGitHub), server.URL+"/repos/o/missing"); status != http.StatusNotFound {
+		t.Fatalf("recorded status %d, want 404", status)
+	}
+	server.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, ServiceGitHub, "*"))
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if strings.Contains(string(data), "secret-pat") {
+			t.Errorf("%s contains the request token", f)
+		}
+	}
+
+	rep, err := Open(dir, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := rep.Transport(ServiceGitHub)
+
+	// A different since= falls back to the path match; recordings are
+	// served in order and the last one repeats.
+	for i, want := range []string{`{"stargazers_count":1}`, `{"stargazers_count":11}`, `{"stargazers_count":11}`} {
+		status, body, err := get(t, rt, server.URL+"/repos/o/r?since=2026-10-18")
+		if err != nil {
+			t.Fatalf("replay %d: %v", i, err)
+		}
+		if status != http.StatusOK || body != want {
+			t.Errorf("replay %d = %d %s, want 200 %s", i, status, body, want)
+		}
+	}
+	if status, _, err := get(t, rt, server.URL+"/repos/o/missing"); err != nil || status != http.StatusNotFound {
+		t.Errorf("replayed missing repo = %d, %v; want 404", status, err)
+	}
+
+	if _, _, err := get(t, rt, server.URL+"/repos/o/other"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded request error = %v, want ErrNotRecorded", err)
+	}
+	// Services are recorded separately.
+	if _, _, err := get(t, rep.Transport(ServiceOllama), server.URL+"/repos/o/r"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("other service error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestCassette_RedactsInstallationTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token":"ghs_live","expires_at":"2026-10-18T12:00:00Z"}`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	rec, err := Open(dir, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, err := get(t, rec.Transport(ServiceGitHub), server.URL+"/app/installations/1/access_tokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body, "ghs_live") {
+		t.Errorf("caller got %s, want the real token", body)
+	}
+
+	rep, err := Open(dir, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, err = get(t, rep.Transport(ServiceGitHub), server.URL+"/app/installations/1/access_tokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(body, "ghs_live") || !strings.Contains(body, "REDACTED") {
+		t.Errorf("replayed body %s, want the token redacted", body)
+	}
+}
+
+func TestOpen_ReplayEmptyDir(t *testing.T) {
+	if _, err := Open(t.TempDir(), Replay); err == nil {
+		t.Error("Open() on an empty cassette succeeded, want error")
+	}
+}
@@ -0,0 +1,31 @@
+package cassette
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// current is the process-wide cassette selected by --record/--replay.
+var current atomic.Pointer[Cassette]
+
+// Use makes c the cassette every HTTP client built afterwards records to
+// or replays from. Use(nil) turns recording and replay off again.
+func Use(c *Cassette) {
+	current.Store(c)
+}
+
+// Current returns the cassette in use, or nil.
+func Current() *Cassette {
+	return current.Load()
+}
+
+// Transport returns the transport a client of service should be built
+// with. It is nil, i.e. http.DefaultTransport, unless a cassette is in
+// use.
+func Transport(service string) http.RoundTripper {
+	c := current.Load()
+	if c == nil {
+		return nil
+	}
+	return c.Transport(service)
+}
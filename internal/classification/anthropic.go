@@ -0,0 +1,171 @@
+package classification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/cassette"
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+// anthropicVersion is the messages API version requested.
+const anthropicVersion = "2023-06-01"
+
+// AnthropicClient communicates with an Anthropic-style /v1/messages
+// endpoint. It implements Classifier.
+type AnthropicClient struct {
+	endpoint    string
+	apiKey      string
+	model       string
+	maxTokens   int
+	temperature float64
+	httpClient  *http.Client
+	categories  map[string]bool
+	enum        []string
+}
+
+var _ Classifier = (*AnthropicClient)(nil)
+
+// NewAnthropicClient creates a client for the messages API at
+// cfg.Endpoint (without the /v1 prefix). cfg.APIKey is sent as
+// x-api-key.
+func NewAnthropicClient(cfg config.ClassifierProviderConfig, categories []string) *AnthropicClient {
+	maxTokens := cfg.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = config.DefaultClassifierTokens
+	}
+	c := &AnthropicClient{
+		endpoint:  strings.TrimSuffix(cfg.Endpoint, "/"),
+		apiKey:    cfg.APIKey,
+		model:     cfg.Model,
+		maxTokens: maxTokens,
+		httpClient: &http.Client{
+			Timeout:   time.Duration(cfg.TimeoutMs) * time.Millisecond,
+			Transport: cassette.Transport(cassette.ServiceAnthropic),
+		},
+		categories: categorySet(categories),
+		enum:       categoryEnum(categories),
+	}
+	if cfg.Temperature != nil {
+		c.temperature = *cfg.Temperature
+	}
+	return c
+}
+
+// anthropicRequest is the /v1/messages request body. The API takes the
+// system prompt as a top-level field, not a message.
+type anthropicRequest struct {
+	Model       string        `json:"model"`
+	System      string        `json:"system"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens"`
+	Temperature float64       `json:"temperature"`
+
+	Tools      []anthropicTool     `json:"tools"`
+	ToolChoice anthropicToolChoice `json:"tool_choice"`
+}
+
+// anthropicToolName is the single tool the model is made to call; its
+// input is the classification, shaped by resultSchema.
+const anthropicToolName = "classification"
+
+// anthropicTool declares a tool the model may call. The messages API
+// has no response_format, so a forced tool call is how it returns JSON
+// matching a schema.
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// anthropicResponse is the relevant fields of the /v1/messages response.
+type anthropicResponse struct {
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+}
+
+// Classify implements Classifier. It degrades like OllamaClient.Classify.
+func (c *AnthropicClient) Classify(ctx context.Context, systemPrompt, userPrompt string) (*ClassificationResult, error) {
+	reqBody := anthropicRequest{
+		Model:       c.model,
+		System:      systemPrompt,
+		Messages:    []chatMessage{{Role: "user", Content: userPrompt}},
+		MaxTokens:   c.maxTokens,
+		Temperature: c.temperature,
+		Tools: []anthropicTool{{
+			Name:        anthropicToolName,
+			Description: "Record the repository classification.",
+			InputSchema: resultSchema(c.enum),
+		}},
+		ToolChoice: anthropicToolChoice{Type: "tool", Name: anthropicToolName},
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/v1/messages", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if c.apiKey != "" {
+		req.Header.Set("x-api-key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if isConnectionError(err) {
+			log.Printf("[classification] WARNING: messages API unreachable at %s: %v", c.endpoint, err)
+			return nil, ErrUnreachable
+		}
+		return nil, fmt.Errorf("messages request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("messages API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var msgResp anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&msgResp); err != nil {
+		log.Printf("[classification] WARNING: invalid messages API response body: %v", err)
+		return &ClassificationResult{Category: "other", Confidence: 0.0, Reasoning: "invalid response from LLM"}, nil
+	}
+
+	// The forced tool call carries the answer; servers that ignore
+	// tools answer in text, parsed the same way.
+	var text strings.Builder
+	for _, block := range msgResp.Content {
+		switch block.Type {
+		case "tool_use":
+			return parseClassification(string(block.Input), c.categories), nil
+		case "text":
+			text.WriteString(block.Text)
+		}
+	}
+	return parseClassification(text.String(), c.categories), nil
+}
+
+// Model returns the configured model name.
+func (c *AnthropicClient) Model() string {
+	return c.model
+}
@@ -0,0 +1,102 @@
+package classification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+func TestAnthropicClient_Classify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "key-test" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.System != "sys" || len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		if req.MaxTokens != config.DefaultClassifierTokens {
+			t.Errorf("max_tokens = %d, want the default", req.MaxTokens)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Here you go: {\"category\": \"observability\", \"confidence\": 0.9, \"reasoning\": \"tracing\"}"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(config.ClassifierProviderConfig{
+		Endpoint:  server.URL,
+		APIKey:    "key-test",
+		Model:     "claude-haiku",
+		TimeoutMs: 5000,
+	}, []string{"observability", "other"})
+	result, err := client.Classify(context.Background(), "sys", "usr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Category != "observability" || result.Confidence != 0.9 {
+		t.Errorf("Classify() = %+v", result)
+	}
+	if client.Model() != "claude-haiku" {
+		t.Errorf("Model() = %q", client.Model())
+	}
+}
+
+func TestAnthropicClient_ClassifyToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.ToolChoice.Type != "tool" || req.ToolChoice.Name != req.Tools[0].Name {
+			t.Fatalf("request does not force the classification tool: %+v %+v", req.Tools, req.ToolChoice)
+		}
+		items := req.Tools[0].InputSchema["properties"].(map[string]interface{})["secondary"].(map[string]interface{})["items"].(map[string]interface{})
+		if _, ok := items["properties"].(map[string]interface{})["subcategory"]; !ok {
+			t.Errorf("secondary items schema has no subcategory: %v", items)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content": [{"type": "tool_use", "name": "classification", "input": {"category": "observability", "confidence": 0.8, "reasoning": "r", "secondary": [{"category": "kubernetes", "subcategory": "service-mesh", "confidence": 0.6}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(config.ClassifierProviderConfig{Endpoint: server.URL, Model: "m", TimeoutMs: 5000},
+		[]string{"observability", "kubernetes", "other"})
+	result, err := client.Classify(context.Background(), "sys", "usr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Label{Category: "kubernetes", Subcategory: "service-mesh", Confidence: 0.6}
+	if result.Category != "observability" || len(result.Secondary) != 1 || result.Secondary[0] != want {
+		t.Errorf("Classify() = %+v", result)
+	}
+}
+
+func TestAnthropicClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"type": "error", "error": {"type": "overloaded_error"}}`, 529)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(config.ClassifierProviderConfig{Endpoint: server.URL, Model: "m", TimeoutMs: 5000}, []string{"other"})
+	if _, err := client.Classify(context.Background(), "sys", "usr"); err == nil {
+		t.Error("expected error for server error response")
+	}
+
+	client = NewAnthropicClient(config.ClassifierProviderConfig{Endpoint: "http://127.0.0.1:1", Model: "m", TimeoutMs: 1000}, []string{"other"})
+	if _, err := client.Classify(context.Background(), "sys", "usr"); err != ErrUnreachable {
+		t.Errorf("expected ErrUnreachable, got: %v", err)
+	}
+}
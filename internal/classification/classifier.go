@@ -0,0 +1,202 @@
+// Package classification provides LLM-based repository classification
+// over Ollama, OpenAI-compatible chat-completions servers and the
+// Anthropic messages API.
+package classification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+// Classifier sends a classification prompt to an LLM and parses its
+// answer.
+type Classifier interface {
+	// Classify sends the system and user prompts and returns the parsed
+	// result. An unreachable server returns ErrUnreachable; a response
+	// that is not valid classification JSON degrades to "other" with
+	// zero confidence rather than an error.
+	Classify(ctx context.Context, systemPrompt, userPrompt string) (*ClassificationResult, error)
+
+	// Model returns the model name recorded with each result.
+	Model() string
+}
+
+// ClassificationResult holds the parsed LLM classification output.
+// Category is the primary label; Secondary holds any further categories
+// the repo belongs to, most confident first. Agreement and Votes are
+// only set by an Ensemble.
+type ClassificationResult struct {
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning"`
+	Secondary  []Label `json:"secondary"`
+
+	Agreement float64 `json:"-"` // Share of members that voted for Category
+	Votes     []Vote  `json:"-"` // Each answering member's result, in member order
+}
+
+// Label is one secondary category with the model's confidence in it.
+// Subcategory is the v3 subcategory the model picked under Category's
+// v3 category, or empty when it gave none.
+type Label struct {
+	Category    string  `json:"category"`
+	Subcategory string  `json:"subcategory"`
+	Confidence  float64 `json:"confidence"`
+}
+
+// ErrUnreachable indicates the LLM server could not be reached.
+var ErrUnreachable = errors.New("llm server unreachable")
+
+// ErrOllamaUnreachable is the former name of ErrUnreachable.
+var ErrOllamaUnreachable = ErrUnreachable
+
+// NewClassifier builds the Classifier configured under
+// classification.provider, Ollama by default, or an Ensemble over
+// classification.ensemble.members when any are listed.
+func NewClassifier(cfg config.ClassificationConfig) (Classifier, error) {
+	members := cfg.EnsembleMembers()
+	if len(members) == 0 {
+		return newProviderClassifier(cfg.ResolvedProvider(), cfg.Categories)
+	}
+	classifiers := make([]Classifier, 0, len(members))
+	for _, m := range members {
+		c, err := newProviderClassifier(m, cfg.Categories)
+		if err != nil {
+			return nil, err
+		}
+		classifiers = append(classifiers, c)
+	}
+	return NewEnsemble(classifiers...), nil
+}
+
+// newProviderClassifier builds the client for one resolved provider.
+func newProviderClassifier(p config.ClassifierProviderConfig, categories []string) (Classifier, error) {
+	switch p.Type {
+	case config.ClassifierOllama:
+		c := NewOllamaClient(p.Endpoint, p.Model, p.TimeoutMs, categories)
+		c.temperature = p.Temperature
+		return c, nil
+	case config.ClassifierOpenAI:
+		return NewOpenAIClient(p, categories), nil
+	case config.ClassifierAnthropic:
+		return NewAnthropicClient(p, categories), nil
+	}
+	return nil, fmt.Errorf("unknown classification provider type %q", p.Type)
+}
+
+// categorySet returns the allowed categories as a set.
+func categorySet(categories []string) map[string]bool {
+	set := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		set[c] = true
+	}
+	return set
+}
+
+// categoryEnum returns the allowed categories sorted, for a JSON schema
+// enum.
+func categoryEnum(categories []string) []string {
+	enum := append([]string(nil), categories...)
+	sort.Strings(enum)
+	return enum
+}
+
+// parseClassification extracts {category, confidence, reasoning} from
+// the LLM content string, tolerating a Markdown code fence or prose
+// around the JSON object. Returns a fallback result on any parse
+// failure.
+func parseClassification(content string, categories map[string]bool) *ClassificationResult {
+	var result ClassificationResult
+	if err := json.Unmarshal([]byte(jsonObject(content)), &result); err != nil {
+		log.Printf("[classification] WARNING: could not parse LLM JSON: %v (raw: %s)", err, content)
+		return &ClassificationResult{Category: "other", Confidence: 0.0, Reasoning: "invalid JSON from LLM"}
+	}
+
+	// Normalize and validate category.
+	result.Category = strings.TrimSpace(strings.ToLower(result.Category))
+	if !categories[result.Category] {
+		log.Printf("[classification] WARNING: LLM returned unknown category %q, remapping to 'other'", result.Category)
+		result.Category = "other"
+	}
+
+	result.Confidence = clampConfidence(result.Confidence)
+	result.Secondary = normalizeSecondary(result.Secondary, result.Category, categories)
+
+	return &result
+}
+
+// normalizeSecondary lowercases secondary labels and drops unknown
+// categories, "other", repeats and the primary category without a
+// subcategory, then ranks the rest by confidence.
+func normalizeSecondary(labels []Label, primary string, categories map[string]bool) []Label {
+	seen := map[Label]bool{{Category: primary}: true}
+	out := labels[:0]
+	for _, l := range labels {
+		l.Category = strings.TrimSpace(strings.ToLower(l.Category))
+		l.Subcategory = strings.TrimSpace(strings.ToLower(l.Subcategory))
+		key := Label{Category: l.Category, Subcategory: l.Subcategory}
+		if l.Category == "other" || seen[key] || !categories[l.Category] {
+			continue
+		}
+		seen[key] = true
+		l.Confidence = clampConfidence(l.Confidence)
+		out = append(out, l)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Confidence > out[j].Confidence })
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// clampConfidence clamps a confidence to [0, 1].
+func clampConfidence(c float64) float64 {
+	if c < 0 {
+		return 0
+	}
+	if c > 1 {
+		return 1
+	}
+	return c
+}
+
+// jsonObject returns the outermost {...} span of s, or s unchanged when
+// it has none. Chat models without a JSON mode often wrap their answer
+// in a code fence or a sentence.
+func jsonObject(s string) string {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return s
+	}
+	return s[start : end+1]
+}
+
+// isConnectionError checks if the error indicates a connection failure (unreachable host).
+func isConnectionError(err error) bool {
+	var netErr *net.OpError
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	// Context deadline exceeded during dial also counts.
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false // timeout is a different degradation path
+	}
+	// Check for "connection refused" style errors.
+	if strings.Contains(err.Error(), "connection refused") {
+		return true
+	}
+	return false
+}
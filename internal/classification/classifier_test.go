@@ -0,0 +1,98 @@
+package classification
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+func TestNewClassifier(t *testing.T) {
+	base := config.ClassificationConfig{OllamaEndpoint: "http://ollama:11434", Model: "qwen3:1.7b", Categories: []string{"other"}}
+
+	tests := []struct {
+		provider config.ClassifierProviderConfig
+		want     string
+	}{
+		{config.ClassifierProviderConfig{}, "*classification.OllamaClient"},
+		{config.ClassifierProviderConfig{Type: config.ClassifierOpenAI, Endpoint: "http://vllm:8000/v1"}, "*classification.OpenAIClient"},
+		{config.ClassifierProviderConfig{Type: config.ClassifierAnthropic, APIKey: "k"}, "*classification.AnthropicClient"},
+	}
+	for _, tt := range tests {
+		cfg := base
+		cfg.Provider = tt.provider
+		c, err := NewClassifier(cfg)
+		if err != nil {
+			t.Fatalf("NewClassifier(%+v): %v", tt.provider, err)
+		}
+		if got := fmt.Sprintf("%T", c); got != tt.want {
+			t.Errorf("NewClassifier(%+v) = %s, want %s", tt.provider, got, tt.want)
+		}
+		if c.Model() != "qwen3:1.7b" {
+			t.Errorf("Model() = %q, want classification.model", c.Model())
+		}
+	}
+
+	base.Provider.Type = "bedrock"
+	if _, err := NewClassifier(base); err == nil {
+		t.Error("expected error for unknown provider type")
+	}
+
+	base.Provider = config.ClassifierProviderConfig{}
+	base.Ensemble.Members = []config.ClassifierProviderConfig{{}, {Model: "llama3.2:3b"}}
+	c, err := NewClassifier(base)
+	if err != nil {
+		t.Fatalf("NewClassifier(ensemble): %v", err)
+	}
+	if _, ok := c.(*Ensemble); !ok || c.Model() != "qwen3:1.7b+llama3.2:3b" {
+		t.Errorf("NewClassifier(ensemble) = %T %q, want an Ensemble over both models", c, c.Model())
+	}
+}
+
+func TestJSONObject(t *testing.T) {
+	tests := map[string]string{
+		`{"a": 1}`:                               `{"a": 1}`,
+		"```json\n{\"a\": 1}\n```":               `{"a": 1}`,
+		`Sure! {"a": {"b": 2}} Hope that helps.`: `{"a": {"b": 2}}`,
+		"no json here":                           "no json here",
+	}
+	for in, want := range tests {
+		if got := jsonObject(in); got != want {
+			t.Errorf("jsonObject(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseClassification_Secondary(t *testing.T) {
+	categories := map[string]bool{"kubernetes": true, "observability": true, "testing": true, "other": true}
+	content := `{"category": "kubernetes", "confidence": 0.9, "reasoning": "r",
+		"secondary": [
+			{"category": "testing", "confidence": 0.55},
+			{"category": " Observability ", "confidence": 0.8},
+			{"category": "kubernetes", "confidence": 0.7},
+			{"category": "kubernetes", "subcategory": " Service-Mesh ", "confidence": 0.65},
+			{"category": "other", "confidence": 0.6},
+			{"category": "made-up", "confidence": 0.9},
+			{"category": "testing", "confidence": 0.5},
+			{"category": "observability", "confidence": 1.7}
+		]}`
+
+	got := parseClassification(content, categories)
+	want := []Label{
+		{Category: "observability", Confidence: 0.8},
+		{Category: "kubernetes", Subcategory: "service-mesh", Confidence: 0.65},
+		{Category: "testing", Confidence: 0.55},
+	}
+	if len(got.Secondary) != len(want) {
+		t.Fatalf("Secondary = %+v, want %+v", got.Secondary, want)
+	}
+	for i := range want {
+		if got.Secondary[i] != want[i] {
+			t.Errorf("Secondary[%d] = %+v, want %+v", i, got.Secondary[i], want[i])
+		}
+	}
+
+	if got := parseClassification(`{"category": "kubernetes", "confidence": 0.9}`, categories); got.Secondary != nil {
+		t.Errorf("Secondary without field = %+v, want nil", got.Secondary)
+	}
+}
@@ -0,0 +1,287 @@
+package classification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/cassette"
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+// Embedder turns text into a vector for the embedding pre-classifier.
+type Embedder interface {
+	// Embed returns the embedding of text. An unreachable server
+	// returns ErrUnreachable.
+	Embed(ctx context.Context, text string) ([]float32, error)
+
+	// Model returns the embedding model name stored with each vector.
+	Model() string
+}
+
+// NewEmbedder builds the Embedder configured under
+// classification.embedding, or returns nil when no embedding model is
+// set.
+func NewEmbedder(cfg config.ClassificationConfig) (Embedder, error) {
+	p := cfg.ResolvedEmbedding()
+	if p.Model == "" {
+		return nil, nil
+	}
+	switch p.Type {
+	case config.ClassifierOllama:
+		return &OllamaEmbedder{embedClient: newEmbedClient(p, cassette.ServiceOllama)}, nil
+	case config.ClassifierOpenAI:
+		return &OpenAIEmbedder{embedClient: newEmbedClient(p, cassette.ServiceOpenAI)}, nil
+	}
+	return nil, fmt.Errorf("embedding provider type %q has no embeddings API", p.Type)
+}
+
+// embedClient holds what both embedders need to reach their server.
+type embedClient struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+func newEmbedClient(p config.ClassifierProviderConfig, service string) embedClient {
+	return embedClient{
+		endpoint: strings.TrimSuffix(p.Endpoint, "/"),
+		apiKey:   p.APIKey,
+		model:    p.Model,
+		httpClient: &http.Client{
+			Timeout:   time.Duration(p.TimeoutMs) * time.Millisecond,
+			Transport: cassette.Transport(service),
+		},
+	}
+}
+
+// Model returns the configured embedding model name.
+func (c embedClient) Model() string {
+	return c.model
+}
+
+// post sends body as JSON to path and decodes the response into out.
+func (c embedClient) post(ctx context.Context, path string, body, out interface{}) error {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshalling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if isConnectionError(err) {
+			log.Printf("[classification] WARNING: embeddings server unreachable at %s: %v", c.endpoint, err)
+			return ErrUnreachable
+		}
+		return fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("embeddings server returned status %d: %s", resp.StatusCode, string(msg))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding embeddings response: %w", err)
+	}
+	return nil
+}
+
+// OllamaEmbedder calls the Ollama /api/embed endpoint. It implements
+// Embedder.
+type OllamaEmbedder struct {
+	embedClient
+}
+
+var _ Embedder = (*OllamaEmbedder)(nil)
+
+// Embed implements Embedder.
+func (c *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := c.post(ctx, "/api/embed", map[string]string{"model": c.model, "input": text}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) == 0 || len(resp.Embeddings[0]) == 0 {
+		return nil, fmt.Errorf("embeddings response has no vector")
+	}
+	return resp.Embeddings[0], nil
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible /embeddings endpoint. It
+// implements Embedder.
+type OpenAIEmbedder struct {
+	embedClient
+}
+
+var _ Embedder = (*OpenAIEmbedder)(nil)
+
+// Embed implements Embedder.
+func (c *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	var resp struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := c.post(ctx, "/embeddings", map[string]string{"model": c.model, "input": text}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 || len(resp.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("embeddings response has no vector")
+	}
+	return resp.Data[0].Embedding, nil
+}
+
+// EmbeddingText is the text embedded for a repo: its description,
+// language and topics, then the README cut to maxReadmeChars runes.
+func EmbeddingText(description, language string, topics []string, readme string, maxReadmeChars int) string {
+	return fmt.Sprintf("%s\nLanguage: %s\nTopics: %s\n\n%s",
+		description, language, strings.Join(topics, ", "), TruncateReadme(readme, maxReadmeChars))
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 when their
+// lengths differ or either is all zeros.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// EmbeddingSimilarity is the cosine similarity of the two repos'
+// embeddings, falling back to TopicSimilarity when either has none.
+func EmbeddingSimilarity(a, b database.LabeledExample) float64 {
+	if len(a.Embedding) > 0 && len(a.Embedding) == len(b.Embedding) {
+		return Cosine(a.Embedding, b.Embedding)
+	}
+	return TopicSimilarity(a, b)
+}
+
+// Centroid is the mean embedding of the labeled repos of one v3 pair.
+// Answer is the classifier category that pair is reported as.
+type Centroid struct {
+	Category    string
+	Subcategory string
+	Answer      string
+	Vector      []float32
+	Examples    int
+}
+
+// BuildCentroids averages the unit-length embeddings of labeled per
+// (category, subcategory) pair. Pairs with fewer than minExamples
+// repos, or with no entry in categories to answer with, get no
+// centroid; so do vectors whose length differs from the first one.
+// Centroids are ordered by pair.
+func BuildCentroids(labeled []database.LabeledExample, categories []string, minExamples int) []Centroid {
+	type group struct {
+		sum []float64
+		n   int
+	}
+	groups := make(map[database.TaxonomyPair]*group)
+	dims := 0
+	for _, ex := range labeled {
+		if len(ex.Embedding) == 0 {
+			continue
+		}
+		if dims == 0 {
+			dims = len(ex.Embedding)
+		}
+		if len(ex.Embedding) != dims {
+			continue
+		}
+		norm := math.Sqrt(squaredNorm(ex.Embedding))
+		if norm == 0 {
+			continue
+		}
+		key := database.TaxonomyPair{Category: ex.Category, Subcategory: ex.Subcategory}
+		g := groups[key]
+		if g == nil {
+			g = &group{sum: make([]float64, dims)}
+			groups[key] = g
+		}
+		for i, v := range ex.Embedding {
+			g.sum[i] += float64(v) / norm
+		}
+		g.n++
+	}
+
+	var out []Centroid
+	for pair, g := range groups {
+		if g.n < minExamples {
+			continue
+		}
+		answer, ok := answerFor(database.LabeledExample{Category: pair.Category, Subcategory: pair.Subcategory}, categories)
+		if !ok {
+			continue
+		}
+		vec := make([]float32, dims)
+		for i, s := range g.sum {
+			vec[i] = float32(s / float64(g.n))
+		}
+		out = append(out, Centroid{Category: pair.Category, Subcategory: pair.Subcategory, Answer: answer, Vector: vec, Examples: g.n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Category != out[j].Category {
+			return out[i].Category < out[j].Category
+		}
+		return out[i].Subcategory < out[j].Subcategory
+	})
+	return out
+}
+
+// NearestCentroid returns the centroid most similar to vec, its cosine
+// similarity and the margin over the runner-up. ok is false with fewer
+// than two centroids, since a margin needs two.
+func NearestCentroid(centroids []Centroid, vec []float32) (best Centroid, similarity, margin float64, ok bool) {
+	if len(centroids) < 2 {
+		return Centroid{}, 0, 0, false
+	}
+	first, second := math.Inf(-1), math.Inf(-1)
+	for _, c := range centroids {
+		s := Cosine(c.Vector, vec)
+		switch {
+		case s > first:
+			second = first
+			first, best = s, c
+		case s > second:
+			second = s
+		}
+	}
+	return best, first, first - second, true
+}
+
+func squaredNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return sum
+}
@@ -0,0 +1,158 @@
+package classification
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+func TestNewEmbedder(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotReq map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotReq)
+		switch r.URL.Path {
+		case "/api/embed":
+			w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.5,-0.25]]}`))
+		case "/v1/embeddings":
+			w.Write([]byte(`{"data":[{"index":0,"embedding":[1,2,3]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		cfg      config.ClassificationConfig
+		wantPath string
+		wantAuth string
+		want     []float32
+	}{
+		{
+			name: "ollama inherits the provider endpoint",
+			cfg: config.ClassificationConfig{
+				OllamaEndpoint: server.URL,
+				Model:          "llama3",
+				Embedding:      config.EmbeddingConfig{Model: "nomic-embed-text"},
+			},
+			wantPath: "/api/embed",
+			want:     []float32{0.5, -0.25},
+		},
+		{
+			name: "openai",
+			cfg: config.ClassificationConfig{
+				Model:     "llama3",
+				Embedding: config.EmbeddingConfig{Type: "openai", Endpoint: server.URL + "/v1/", APIKey: "sk-test", Model: "text-embedding-3-small"},
+			},
+			wantPath: "/v1/embeddings",
+			wantAuth: "Bearer sk-test",
+			want:     []float32{1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEmbedder(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewEmbedder: %v", err)
+			}
+			got, err := e.Embed(context.Background(), "some repo")
+			if err != nil {
+				t.Fatalf("Embed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Embed = %v, want %v", got, tt.want)
+			}
+			if gotPath != tt.wantPath || gotAuth != tt.wantAuth {
+				t.Errorf("request = %s (auth %q), want %s (auth %q)", gotPath, gotAuth, tt.wantPath, tt.wantAuth)
+			}
+			if gotReq["model"] != tt.cfg.Embedding.Model || gotReq["input"] != "some repo" {
+				t.Errorf("request body = %v", gotReq)
+			}
+		})
+	}
+
+	if e, err := NewEmbedder(config.ClassificationConfig{Model: "llama3"}); e != nil || err != nil {
+		t.Errorf("NewEmbedder without a model = %v, %v; want nil, nil", e, err)
+	}
+	if _, err := NewEmbedder(config.ClassificationConfig{Embedding: config.EmbeddingConfig{Type: "anthropic", Model: "x"}}); err == nil {
+		t.Error("NewEmbedder(anthropic) succeeded, want an error")
+	}
+}
+
+func TestOllamaEmbedder_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	e, _ := NewEmbedder(config.ClassificationConfig{OllamaEndpoint: url, Embedding: config.EmbeddingConfig{Model: "m"}, TimeoutMs: 1000})
+	if _, err := e.Embed(context.Background(), "x"); err != ErrUnreachable {
+		t.Errorf("Embed error = %v, want ErrUnreachable", err)
+	}
+}
+
+func TestBuildCentroids(t *testing.T) {
+	labeled := []database.LabeledExample{
+		{FullName: "k1", Category: "cloud-native", Subcategory: "kubernetes", Embedding: []float32{2, 0}},
+		{FullName: "k2", Category: "cloud-native", Subcategory: "kubernetes", Embedding: []float32{0, 1}},
+		{FullName: "a1", Category: "ai", Subcategory: "agents", Embedding: []float32{0, 3}},
+		// Too few for a centroid at minExamples 2.
+		{FullName: "r1", Category: "ai", Subcategory: "rag", Embedding: []float32{1, 1}},
+		// Wrong length and no embedding: ignored.
+		{FullName: "a2", Category: "ai", Subcategory: "agents", Embedding: []float32{1, 1, 1}},
+		{FullName: "a3", Category: "ai", Subcategory: "agents"},
+		{FullName: "a4", Category: "ai", Subcategory: "agents", Embedding: []float32{0, 1}},
+	}
+	got := BuildCentroids(labeled, []string{"ai-agents", "kubernetes", "rag"}, 2)
+	want := []Centroid{
+		{Category: "ai", Subcategory: "agents", Answer: "ai-agents", Vector: []float32{0, 1}, Examples: 2},
+		{Category: "cloud-native", Subcategory: "kubernetes", Answer: "kubernetes", Vector: []float32{0.5, 0.5}, Examples: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildCentroids =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// A pair the configured categories cannot answer with is dropped.
+	if got := BuildCentroids(labeled, []string{"ai-agents"}, 2); len(got) != 1 || got[0].Answer != "ai-agents" {
+		t.Errorf("BuildCentroids(ai-agents only) = %+v", got)
+	}
+}
+
+func TestNearestCentroid(t *testing.T) {
+	centroids := []Centroid{
+		{Answer: "x", Vector: []float32{1, 0}},
+		{Answer: "y", Vector: []float32{0, 1}},
+	}
+	best, sim, margin, ok := NearestCentroid(centroids, []float32{3, 1})
+	if !ok || best.Answer != "x" {
+		t.Fatalf("NearestCentroid = %+v, %v", best, ok)
+	}
+	wantSim := 3 / math.Sqrt(10)
+	if math.Abs(sim-wantSim) > 1e-9 || math.Abs(margin-(wantSim-1/math.Sqrt(10))) > 1e-9 {
+		t.Errorf("similarity, margin = %.4f, %.4f", sim, margin)
+	}
+
+	if _, _, _, ok := NearestCentroid(centroids[:1], []float32{1, 0}); ok {
+		t.Error("NearestCentroid with one centroid reported ok")
+	}
+}
+
+func TestEmbeddingSimilarity(t *testing.T) {
+	a := database.LabeledExample{Topics: []string{"k8s"}, Embedding: []float32{1, 0}}
+	b := database.LabeledExample{Topics: []string{"k8s"}, Embedding: []float32{1, 1}}
+	if got := EmbeddingSimilarity(a, b); math.Abs(got-1/math.Sqrt2) > 1e-9 {
+		t.Errorf("EmbeddingSimilarity = %v, want cosine %v", got, 1/math.Sqrt2)
+	}
+	b.Embedding = nil
+	if got := EmbeddingSimilarity(a, b); got != 1 {
+		t.Errorf("EmbeddingSimilarity without a vector = %v, want topic similarity 1", got)
+	}
+}
@@ -0,0 +1,121 @@
+package classification
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Vote is one ensemble member's answer.
+type Vote struct {
+	Model      string
+	Category   string
+	Confidence float64
+	Reasoning  string
+}
+
+// Ensemble asks several classifiers the same question and takes a
+// confidence-weighted vote. It implements Classifier.
+//
+// Each category scores the summed confidence of the members that chose
+// it, and the highest score wins; ties go to the category with more
+// votes, then to the earlier member. The result's Confidence is the
+// winning score averaged over all answering members, so dissent lowers
+// it, and Agreement is the share of those members that chose the
+// winner. Reasoning and Secondary come from the most confident member
+// in the majority. Members that fail are left out of the vote; the
+// ensemble only fails when all of them do.
+type Ensemble struct {
+	members []Classifier
+}
+
+var _ Classifier = (*Ensemble)(nil)
+
+// NewEnsemble creates an ensemble over members, queried concurrently.
+func NewEnsemble(members ...Classifier) *Ensemble {
+	return &Ensemble{members: members}
+}
+
+// Classify implements Classifier.
+func (e *Ensemble) Classify(ctx context.Context, systemPrompt, userPrompt string) (*ClassificationResult, error) {
+	results := make([]*ClassificationResult, len(e.members))
+	errs := make([]error, len(e.members))
+	var wg sync.WaitGroup
+	for i, m := range e.members {
+		wg.Add(1)
+		go func(i int, m Classifier) {
+			defer wg.Done()
+			results[i], errs[i] = m.Classify(ctx, systemPrompt, userPrompt)
+		}(i, m)
+	}
+	wg.Wait()
+
+	var votes []Vote
+	var answers []*ClassificationResult
+	for i, r := range results {
+		if errs[i] != nil {
+			log.Printf("[classification] WARNING: ensemble member %s failed: %v", e.members[i].Model(), errs[i])
+			continue
+		}
+		votes = append(votes, Vote{Model: e.members[i].Model(), Category: r.Category, Confidence: r.Confidence, Reasoning: r.Reasoning})
+		answers = append(answers, r)
+	}
+	if len(votes) == 0 {
+		return nil, ensembleError(errs)
+	}
+
+	score := make(map[string]float64)
+	count := make(map[string]int)
+	for _, v := range votes {
+		score[v.Category] += v.Confidence
+		count[v.Category]++
+	}
+	winner := votes[0].Category
+	for _, v := range votes[1:] {
+		c := v.Category
+		if score[c] > score[winner] || (score[c] == score[winner] && count[c] > count[winner]) {
+			winner = c
+		}
+	}
+
+	var best *ClassificationResult
+	for _, r := range answers {
+		if r.Category == winner && (best == nil || r.Confidence > best.Confidence) {
+			best = r
+		}
+	}
+	return &ClassificationResult{
+		Category:   winner,
+		Confidence: score[winner] / float64(len(votes)),
+		Reasoning:  best.Reasoning,
+		Secondary:  best.Secondary,
+		Agreement:  float64(count[winner]) / float64(len(votes)),
+		Votes:      votes,
+	}, nil
+}
+
+// ensembleError reports why every member failed: ErrUnreachable when
+// none of them could be reached, otherwise the first other error.
+func ensembleError(errs []error) error {
+	for _, err := range errs {
+		if !errors.Is(err, ErrUnreachable) {
+			return err
+		}
+	}
+	return ErrUnreachable
+}
+
+// Model returns the distinct member models joined with "+".
+func (e *Ensemble) Model() string {
+	var models []string
+	seen := make(map[string]bool)
+	for _, m := range e.members {
+		if name := m.Model(); !seen[name] {
+			seen[name] = true
+			models = append(models, name)
+		}
+	}
+	return strings.Join(models, "+")
+}
@@ -0,0 +1,107 @@
+package classification
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeClassifier returns a fixed answer or error.
+type fakeClassifier struct {
+	model  string
+	result *ClassificationResult
+	err    error
+}
+
+func (f *fakeClassifier) Classify(context.Context, string, string) (*ClassificationResult, error) {
+	return f.result, f.err
+}
+
+func (f *fakeClassifier) Model() string { return f.model }
+
+func vote(model, category string, confidence float64) *fakeClassifier {
+	return &fakeClassifier{model: model, result: &ClassificationResult{Category: category, Confidence: confidence, Reasoning: model + " says " + category}}
+}
+
+func TestEnsemble_ConfidenceWeightedVote(t *testing.T) {
+	e := NewEnsemble(
+		vote("a", "kubernetes", 0.9),
+		vote("b", "observability", 0.95),
+		vote("c", "kubernetes", 0.6),
+	)
+	got, err := e.Classify(context.Background(), "sys", "usr")
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if got.Category != "kubernetes" {
+		t.Errorf("Category = %q, want kubernetes (1.5 vs 0.95)", got.Category)
+	}
+	if got.Confidence != 0.5 {
+		t.Errorf("Confidence = %v, want 0.5 (1.5 over 3 members)", got.Confidence)
+	}
+	if got.Agreement != 2.0/3.0 {
+		t.Errorf("Agreement = %v, want 2/3", got.Agreement)
+	}
+	if got.Reasoning != "a says kubernetes" {
+		t.Errorf("Reasoning = %q, want the most confident majority member's", got.Reasoning)
+	}
+	if len(got.Votes) != 3 || got.Votes[1].Model != "b" || got.Votes[1].Category != "observability" {
+		t.Errorf("Votes = %+v, want all three in member order", got.Votes)
+	}
+}
+
+func TestEnsemble_ConfidenceOutweighsCount(t *testing.T) {
+	e := NewEnsemble(
+		vote("a", "kubernetes", 0.3),
+		vote("b", "kubernetes", 0.3),
+		vote("c", "observability", 0.9),
+	)
+	got, err := e.Classify(context.Background(), "sys", "usr")
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if got.Category != "observability" || got.Agreement != 1.0/3.0 {
+		t.Errorf("got %s at agreement %v, want observability at 1/3", got.Category, got.Agreement)
+	}
+}
+
+func TestEnsemble_SkipsFailedMembers(t *testing.T) {
+	e := NewEnsemble(
+		&fakeClassifier{model: "down", err: ErrUnreachable},
+		vote("a", "kubernetes", 0.8),
+		vote("b", "kubernetes", 0.6),
+	)
+	got, err := e.Classify(context.Background(), "sys", "usr")
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if got.Agreement != 1 || len(got.Votes) != 2 || got.Confidence != 0.7 {
+		t.Errorf("got %+v, want a unanimous vote of the two answering members", got)
+	}
+}
+
+func TestEnsemble_AllMembersFail(t *testing.T) {
+	e := NewEnsemble(
+		&fakeClassifier{model: "a", err: ErrUnreachable},
+		&fakeClassifier{model: "b", err: ErrUnreachable},
+	)
+	if _, err := e.Classify(context.Background(), "sys", "usr"); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("err = %v, want ErrUnreachable", err)
+	}
+
+	boom := errors.New("status 500")
+	e = NewEnsemble(
+		&fakeClassifier{model: "a", err: ErrUnreachable},
+		&fakeClassifier{model: "b", err: boom},
+	)
+	if _, err := e.Classify(context.Background(), "sys", "usr"); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want the non-connection error", err)
+	}
+}
+
+func TestEnsemble_Model(t *testing.T) {
+	e := NewEnsemble(vote("qwen3:1.7b", "", 0), vote("qwen3:1.7b", "", 0), vote("llama3.2:3b", "", 0))
+	if got := e.Model(); got != "qwen3:1.7b+llama3.2:3b" {
+		t.Errorf("Model() = %q", got)
+	}
+}
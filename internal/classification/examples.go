@@ -0,0 +1,125 @@
+package classification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+// maxExampleDescription caps the description of one few-shot example,
+// in runes, so a single long description cannot use up the budget.
+const maxExampleDescription = 200
+
+// Similarity scores how alike two repos are for few-shot example
+// selection. Zero means unrelated.
+type Similarity func(a, b database.LabeledExample) float64
+
+// TopicSimilarity is the Jaccard index of the two topic sets, plus 0.25
+// when both repos share a primary language.
+func TopicSimilarity(a, b database.LabeledExample) float64 {
+	var score float64
+	if len(a.Topics) > 0 && len(b.Topics) > 0 {
+		set := make(map[string]bool, len(a.Topics))
+		for _, t := range a.Topics {
+			set[strings.ToLower(t)] = true
+		}
+		shared, union := 0, len(set)
+		seen := make(map[string]bool, len(b.Topics))
+		for _, t := range b.Topics {
+			t = strings.ToLower(t)
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			if set[t] {
+				shared++
+			} else {
+				union++
+			}
+		}
+		score = float64(shared) / float64(union)
+	}
+	if a.Language != "" && strings.EqualFold(a.Language, b.Language) {
+		score += 0.25
+	}
+	return score
+}
+
+// SelectExamples returns up to k repos from pool most similar to target
+// by sim, most similar first. The target itself and unrelated repos
+// (score 0) are never picked; ties go to the lower full_name.
+func SelectExamples(pool []database.LabeledExample, target database.LabeledExample, k int, sim Similarity) []database.LabeledExample {
+	if k <= 0 {
+		return nil
+	}
+	type scored struct {
+		ex    database.LabeledExample
+		score float64
+	}
+	var candidates []scored
+	for _, ex := range pool {
+		if ex.FullName == target.FullName {
+			continue
+		}
+		if s := sim(target, ex); s > 0 {
+			candidates = append(candidates, scored{ex, s})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].ex.FullName < candidates[j].ex.FullName
+	})
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+	out := make([]database.LabeledExample, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.ex
+	}
+	return out
+}
+
+// FormatExamples renders examples for the {{.Examples}} prompt field,
+// answering each with the entry of categories the model would have to
+// return for its pair. Examples with no such entry are left out, as is
+// every example that would take the text past budget runes; budget <= 0
+// means unlimited.
+func FormatExamples(examples []database.LabeledExample, categories []string, budget int) string {
+	var b strings.Builder
+	for _, ex := range examples {
+		answer, ok := answerFor(ex, categories)
+		if !ok {
+			continue
+		}
+		block := fmt.Sprintf("Repository: %s\nDescription: %s\nLanguage: %s\nTopics: %s\nCategory: %s\n\n",
+			ex.FullName, TruncateReadme(ex.Description, maxExampleDescription), ex.Language, strings.Join(ex.Topics, ","), answer)
+		if budget > 0 && utf8.RuneCountInString(b.String())+utf8.RuneCountInString(block) > budget {
+			break
+		}
+		b.WriteString(block)
+	}
+	return b.String()
+}
+
+// answerFor maps the v3 pair of ex back to the classifier category that
+// rolls up to it: a legacy slug from LegacyCategoryMap or, failing that,
+// the top-level category itself.
+func answerFor(ex database.LabeledExample, categories []string) (string, bool) {
+	pair := database.TaxonomyPair{Category: ex.Category, Subcategory: ex.Subcategory}
+	for _, c := range categories {
+		if database.LegacyCategoryMap[c] == pair {
+			return c, true
+		}
+	}
+	for _, c := range categories {
+		if c == ex.Category {
+			return c, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package classification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+func TestTopicSimilarity(t *testing.T) {
+	a := database.LabeledExample{Topics: []string{"kubernetes", "operator", "Go"}, Language: "Go"}
+	tests := []struct {
+		name string
+		b    database.LabeledExample
+		want float64
+	}{
+		{"same topics and language", database.LabeledExample{Topics: []string{"go", "operator", "kubernetes"}, Language: "go"}, 1.25},
+		{"half the topics", database.LabeledExample{Topics: []string{"kubernetes", "helm", "operator", "gitops"}}, 0.4},
+		{"language only", database.LabeledExample{Language: "Go"}, 0.25},
+		{"unrelated", database.LabeledExample{Topics: []string{"react"}, Language: "TypeScript"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TopicSimilarity(a, tt.b); got != tt.want {
+				t.Errorf("TopicSimilarity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectExamples(t *testing.T) {
+	pool := []database.LabeledExample{
+		{FullName: "x/lang-only", Language: "Go"},
+		{FullName: "x/target", Topics: []string{"kubernetes"}, Language: "Go"},
+		{FullName: "x/close", Topics: []string{"kubernetes", "operator"}, Language: "Go"},
+		{FullName: "x/closer", Topics: []string{"kubernetes", "operator"}, Language: "Go"},
+		{FullName: "x/unrelated", Topics: []string{"react"}},
+	}
+	target := database.LabeledExample{FullName: "x/target", Topics: []string{"kubernetes", "operator"}, Language: "Go"}
+
+	got := SelectExamples(pool, target, 2, TopicSimilarity)
+	if len(got) != 2 || got[0].FullName != "x/close" || got[1].FullName != "x/closer" {
+		t.Errorf("SelectExamples(k=2) = %+v, want [x/close x/closer]", got)
+	}
+	got = SelectExamples(pool, target, 10, TopicSimilarity)
+	if len(got) != 3 || got[2].FullName != "x/lang-only" {
+		t.Errorf("SelectExamples(k=10) = %+v, want the target and unrelated repos left out", got)
+	}
+	if got := SelectExamples(pool, target, 0, TopicSimilarity); got != nil {
+		t.Errorf("SelectExamples(k=0) = %+v, want nil", got)
+	}
+}
+
+func TestFormatExamples(t *testing.T) {
+	categories := []string{"kubernetes", "ai-agents", "other"}
+	examples := []database.LabeledExample{
+		{FullName: "x/op", Description: "An operator", Language: "Go", Topics: []string{"kubernetes", "operator"}, Category: "cloud-native", Subcategory: "kubernetes"},
+		{FullName: "x/web", Category: "web", Subcategory: "frameworks"},
+		{FullName: "x/agent", Description: strings.Repeat("a", 500), Category: "ai", Subcategory: "agents"},
+	}
+
+	got := FormatExamples(examples, categories, 0)
+	if !strings.Contains(got, "Repository: x/op\nDescription: An operator\nLanguage: Go\nTopics: kubernetes,operator\nCategory: kubernetes\n") {
+		t.Errorf("missing x/op block:\n%s", got)
+	}
+	if strings.Contains(got, "x/web") {
+		t.Errorf("example with no matching category was kept:\n%s", got)
+	}
+	if !strings.Contains(got, "Category: ai-agents") || strings.Contains(got, strings.Repeat("a", maxExampleDescription+1)) {
+		t.Errorf("x/agent should answer ai-agents with a truncated description:\n%s", got)
+	}
+
+	budgeted := FormatExamples(examples, categories, 150)
+	if !strings.Contains(budgeted, "x/op") || strings.Contains(budgeted, "x/agent") {
+		t.Errorf("budget 150 should keep only x/op:\n%s", budgeted)
+	}
+}
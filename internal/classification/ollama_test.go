@@ -221,3 +221,32 @@ func TestModel(t *testing.T) {
 		t.Errorf("expected model 'qwen3:1.7b', got %q", client.Model())
 	}
 }
+
+func TestClassify_Temperature(t *testing.T) {
+	var got chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = chatRequest{}
+		json.NewDecoder(r.Body).Decode(&got)
+		resp := chatResponse{}
+		resp.Message.Content = `{"category": "kubernetes", "confidence": 0.8, "reasoning": "test"}`
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "test-model", 5000, []string{"kubernetes", "other"})
+	if _, err := client.Classify(context.Background(), "sys", "usr"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Options != nil {
+		t.Errorf("options = %+v, want none without a temperature", got.Options)
+	}
+
+	temp := 0.7
+	client.temperature = &temp
+	if _, err := client.Classify(context.Background(), "sys", "usr"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Options == nil || got.Options.Temperature != 0.7 {
+		t.Errorf("options = %+v, want temperature 0.7", got.Options)
+	}
+}
@@ -0,0 +1,183 @@
+package classification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/cassette"
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+// OpenAIClient communicates with an OpenAI-compatible
+// /chat/completions endpoint: OpenAI itself, vLLM, the llama.cpp server
+// or LM Studio. It implements Classifier.
+type OpenAIClient struct {
+	endpoint    string
+	apiKey      string
+	model       string
+	maxTokens   int
+	temperature float64
+	httpClient  *http.Client
+	categories  map[string]bool
+	enum        []string
+}
+
+var _ Classifier = (*OpenAIClient)(nil)
+
+// NewOpenAIClient creates a client for the chat-completions API at
+// cfg.Endpoint (including the /v1 prefix). cfg.APIKey is sent as a
+// Bearer token when set; local servers usually need none.
+func NewOpenAIClient(cfg config.ClassifierProviderConfig, categories []string) *OpenAIClient {
+	c := &OpenAIClient{
+		endpoint:  strings.TrimSuffix(cfg.Endpoint, "/"),
+		apiKey:    cfg.APIKey,
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+		httpClient: &http.Client{
+			Timeout:   time.Duration(cfg.TimeoutMs) * time.Millisecond,
+			Transport: cassette.Transport(cassette.ServiceOpenAI),
+		},
+		categories: categorySet(categories),
+		enum:       categoryEnum(categories),
+	}
+	if cfg.Temperature != nil {
+		c.temperature = *cfg.Temperature
+	}
+	return c
+}
+
+// openAIRequest is the /chat/completions request body.
+type openAIRequest struct {
+	Model          string               `json:"model"`
+	Messages       []chatMessage        `json:"messages"`
+	Temperature    float64              `json:"temperature"`
+	MaxTokens      int                  `json:"max_tokens,omitempty"`
+	ResponseFormat openAIResponseFormat `json:"response_format"`
+}
+
+// openAIResponseFormat asks for output matching a JSON schema, which
+// OpenAI, vLLM, llama.cpp and LM Studio all enforce.
+type openAIResponseFormat struct {
+	Type       string           `json:"type"`
+	JSONSchema openAIJSONSchema `json:"json_schema"`
+}
+
+type openAIJSONSchema struct {
+	Name   string                 `json:"name"`
+	Strict bool                   `json:"strict"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// openAIResponse is the relevant fields of the /chat/completions response.
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// resultSchema is the JSON schema of a ClassificationResult, with the
+// category restricted to enum when it is not empty. A secondary label's
+// subcategory is free text, empty when the model has none; the pipeline
+// checks it against the v3 taxonomy.
+func resultSchema(enum []string) map[string]interface{} {
+	category := map[string]interface{}{"type": "string"}
+	if len(enum) > 0 {
+		category["enum"] = enum
+	}
+	confidence := map[string]interface{}{"type": "number"}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"category":   category,
+			"confidence": confidence,
+			"reasoning":  map[string]interface{}{"type": "string"},
+			"secondary": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"category":    category,
+						"subcategory": map[string]interface{}{"type": "string"},
+						"confidence":  confidence,
+					},
+					"required":             []string{"category", "subcategory", "confidence"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"category", "confidence", "reasoning", "secondary"},
+		"additionalProperties": false,
+	}
+}
+
+// Classify implements Classifier. It degrades like OllamaClient.Classify.
+func (c *OpenAIClient) Classify(ctx context.Context, systemPrompt, userPrompt string) (*ClassificationResult, error) {
+	reqBody := openAIRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature: c.temperature,
+		MaxTokens:   c.maxTokens,
+		ResponseFormat: openAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: openAIJSONSchema{
+				Name:   "classification",
+				Strict: true,
+				Schema: resultSchema(c.enum),
+			},
+		},
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/chat/completions", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if isConnectionError(err) {
+			log.Printf("[classification] WARNING: chat-completions server unreachable at %s: %v", c.endpoint, err)
+			return nil, ErrUnreachable
+		}
+		return nil, fmt.Errorf("chat-completions request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("chat-completions server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var chatResp openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil || len(chatResp.Choices) == 0 {
+		log.Printf("[classification] WARNING: invalid chat-completions response body: %v", err)
+		return &ClassificationResult{Category: "other", Confidence: 0.0, Reasoning: "invalid response from LLM"}, nil
+	}
+
+	return parseClassification(chatResp.Choices[0].Message.Content, c.categories), nil
+}
+
+// Model returns the configured model name.
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
@@ -0,0 +1,98 @@
+package classification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+// openAIStub serves /v1/chat/completions, answering with content.
+func openAIStub(t *testing.T, content string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.Model != "qwen2.5-7b-instruct" || len(req.Messages) != 2 || req.Messages[0].Role != "system" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		if req.ResponseFormat.Type != "json_schema" {
+			t.Errorf("response_format.type = %q, want json_schema", req.ResponseFormat.Type)
+		}
+
+		resp := openAIResponse{Choices: make([]struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		}, 1)}
+		resp.Choices[0].Message.Content = content
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestOpenAIClient_Classify(t *testing.T) {
+	server := openAIStub(t, `{"category": "Kubernetes", "confidence": 0.88, "reasoning": "operator"}`)
+	defer server.Close()
+
+	client := NewOpenAIClient(config.ClassifierProviderConfig{
+		Endpoint:  server.URL + "/v1/",
+		APIKey:    "sk-test",
+		Model:     "qwen2.5-7b-instruct",
+		TimeoutMs: 5000,
+	}, []string{"kubernetes", "other"})
+	result, err := client.Classify(context.Background(), "sys", "usr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Category != "kubernetes" || result.Confidence != 0.88 || result.Reasoning != "operator" {
+		t.Errorf("Classify() = %+v", result)
+	}
+	if client.Model() != "qwen2.5-7b-instruct" {
+		t.Errorf("Model() = %q", client.Model())
+	}
+}
+
+func TestOpenAIClient_FencedJSON(t *testing.T) {
+	server := openAIStub(t, "```json\n{\"category\": \"other\", \"confidence\": 0.4, \"reasoning\": \"unclear\"}\n```")
+	defer server.Close()
+
+	client := NewOpenAIClient(config.ClassifierProviderConfig{
+		Endpoint: server.URL + "/v1", APIKey: "sk-test", Model: "qwen2.5-7b-instruct", TimeoutMs: 5000,
+	}, []string{"kubernetes", "other"})
+	result, err := client.Classify(context.Background(), "sys", "usr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Category != "other" || result.Confidence != 0.4 {
+		t.Errorf("Classify() = %+v, want the fenced JSON parsed", result)
+	}
+}
+
+func TestOpenAIClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "model not loaded"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(config.ClassifierProviderConfig{Endpoint: server.URL, Model: "m", TimeoutMs: 5000}, []string{"other"})
+	if _, err := client.Classify(context.Background(), "sys", "usr"); err == nil {
+		t.Error("expected error for server error response")
+	}
+
+	client = NewOpenAIClient(config.ClassifierProviderConfig{Endpoint: "http://127.0.0.1:1", Model: "m", TimeoutMs: 1000}, []string{"other"})
+	if _, err := client.Classify(context.Background(), "sys", "usr"); err != ErrUnreachable {
+		t.Errorf("expected ErrUnreachable, got: %v", err)
+	}
+}
@@ -10,7 +10,9 @@ import (
 
 	"github.com/hrexed/github-radar/internal/config"
 	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/forge"
 	"github.com/hrexed/github-radar/internal/github"
+	"github.com/hrexed/github-radar/internal/repository"
 )
 
 // Result holds classification output for one repo.
@@ -18,11 +20,36 @@ type Result struct {
 	Category   string
 	Confidence float64
 	Reasoning  string
+	// Labels ranks the repo's categories as v3 (category, subcategory)
+	// pairs: the primary first, then secondary labels that pass
+	// max_secondary_labels and min_secondary_confidence.
+	Labels []database.CategoryLabel
+	// Agreement and Votes are set in ensemble mode: the share of
+	// members that chose Category, and every member's answer.
+	Agreement  float64
+	Votes      []Vote
 	ModelUsed  string
 	ReadmeHash string
 	Duration   time.Duration
 	Skipped    bool  // true if README unchanged
 	Error      error // non-nil if classification failed
+
+	// Description and Topics are what the classifier was given; they
+	// are stored so the repo can later serve as a few-shot example.
+	Description string
+	Topics      []string
+
+	// Embedding is the repo's vector from EmbeddingModel, when the
+	// embedding stage is on. PreClassified is true when the nearest
+	// centroid decided the category and the LLM was not called.
+	Embedding      []float32
+	EmbeddingModel string
+	PreClassified  bool
+
+	// RuleID is set when a classification rule decided the repo;
+	// Category and Subcategory are then a v3 pair.
+	RuleID      string
+	Subcategory string
 }
 
 // Summary holds batch classification results.
@@ -32,25 +59,98 @@ type Summary struct {
 	NeedsReview int
 	Skipped     int
 	Failed      int
+	Embedded    int // Classified by the embedding stage without the LLM
+	Ruled       int // Classified by a classification rule
 	Duration    time.Duration
 }
 
 // Pipeline orchestrates LLM-based repository classification.
 type Pipeline struct {
 	db     *database.DB
-	gh     *github.Client
-	ollama *OllamaClient
+	gh     forge.Provider
+	hosts  map[string]forge.Provider
+	forges map[string]forge.Provider
+	llm    Classifier
 	cfg    config.ClassificationConfig
+
+	embedder        Embedder
+	centroids       []Centroid
+	centroidsLoaded bool
+
+	// examplePool holds the labeled repos few-shot examples are drawn
+	// from, loaded once per ClassifyAllWithin run.
+	examplePool       []database.LabeledExample
+	examplePoolLoaded bool
+
+	rules *RuleSet
 }
 
 // NewPipeline creates a classification pipeline with the given dependencies.
-func NewPipeline(db *database.DB, gh *github.Client, ollama *OllamaClient, cfg config.ClassificationConfig) *Pipeline {
+func NewPipeline(db *database.DB, gh *github.Client, llm Classifier, cfg config.ClassificationConfig) *Pipeline {
 	return &Pipeline{
-		db:     db,
-		gh:     gh,
-		ollama: ollama,
-		cfg:    cfg,
+		db:  db,
+		gh:  github.NewForgeProvider(gh),
+		llm: llm,
+		cfg: cfg,
+	}
+}
+
+// SetHostClient registers the client for repos on a GitHub Enterprise
+// Server host, i.e. repos named host/owner/repo. Repos on a host with no
+// client fail to classify.
+func (p *Pipeline) SetHostClient(host string, gh *github.Client) {
+	if p.hosts == nil {
+		p.hosts = make(map[string]forge.Provider)
 	}
+	p.hosts[host] = github.NewForgeProvider(gh)
+}
+
+// SetForgeProvider registers the provider for repos on a non-GitHub
+// forge, i.e. repos named name:owner/repo. Repos on a forge with no
+// provider fail to classify.
+func (p *Pipeline) SetForgeProvider(name string, fp forge.Provider) {
+	if p.forges == nil {
+		p.forges = make(map[string]forge.Provider)
+	}
+	p.forges[name] = fp
+}
+
+// SetEmbedder turns on the embedding pre-classifier: each repo is
+// embedded and, when its nearest category centroid wins by at least
+// embedding.min_margin, classified without calling the LLM.
+func (p *Pipeline) SetEmbedder(e Embedder) {
+	p.embedder = e
+	p.centroidsLoaded = false
+}
+
+// SetRules sets the classification rules tried before the embedding
+// stage and the LLM.
+func (p *Pipeline) SetRules(rs *RuleSet) {
+	p.rules = rs
+}
+
+// repoClient returns the provider serving fullName and its owner and
+// name.
+func (p *Pipeline) repoClient(fullName string) (forge.Provider, string, string, error) {
+	r, ok := repository.ParseKey(fullName)
+	if !ok {
+		return nil, "", "", fmt.Errorf("invalid repo name %q", fullName)
+	}
+	if r.Provider != "" {
+		fp, ok := p.forges[r.Provider]
+		if !ok {
+			return nil, "", "", fmt.Errorf("no provider for forge %s", r.Provider)
+		}
+		return fp, r.Owner, r.Name, nil
+	}
+	if r.Host == "" {
+		return p.gh, r.Owner, r.Name, nil
+	}
+	gh, ok := p.hosts[r.Host]
+	if !ok {
+		return nil, "", "", fmt.Errorf("no client for host %s", r.Host)
+	}
+	return gh, r.Owner, r.Name, nil
 }
 
 // ClassifySingle classifies a single repository by fetching its README,
@@ -67,11 +167,18 @@ func (p *Pipeline) ClassifySingle(ctx context.Context, repo database.RepoRecord)
 	start := time.Now()
 
 	// Fetch README via GitHub API.
-	owner, name := splitFullName(repo.FullName)
-	readmeResp, err := p.gh.GetReadme(ctx, owner, name, "")
+	gh, owner, name, err := p.repoClient(repo.FullName)
 	if err != nil {
 		return &Result{
-			ModelUsed: p.ollama.Model(),
+			ModelUsed: p.llm.Model(),
+			Duration:  time.Since(start),
+			Error:     fmt.Errorf("fetching readme: %w", err),
+		}, nil
+	}
+	readmeResp, err := gh.GetReadme(ctx, owner, name)
+	if err != nil {
+		return &Result{
+			ModelUsed: p.llm.Model(),
 			Duration:  time.Since(start),
 			Error:     fmt.Errorf("fetching readme: %w", err),
 		}, nil
@@ -100,22 +207,57 @@ func (p *Pipeline) ClassifySingle(ctx context.Context, repo database.RepoRecord)
 	// we continue with empty values rather than aborting — the README is still
 	// the main classifier signal, and matches prior behavior where DB columns
 	// were effectively empty strings.
-	var description, topics string
-	if repoMeta, metaErr := p.gh.GetRepository(ctx, owner, name); metaErr == nil && repoMeta != nil {
+	var description string
+	var topics []string
+	if repoMeta, metaErr := gh.GetRepository(ctx, owner, name); metaErr == nil && repoMeta != nil {
 		description = repoMeta.Description
-		topics = strings.Join(repoMeta.Topics, ",")
+		topics = repoMeta.Topics
 	} else if metaErr != nil {
 		log.Printf("[classification] WARNING: live-fetch description/topics failed for %s: %v", repo.FullName, metaErr)
 	}
 
-	truncated := TruncateReadme(readmeContent, p.cfg.MaxReadmeChars)
+	if rule, ok := p.rules.Match(RuleInput{
+		Owner: owner, Name: name, Language: repo.Language, Description: description, Topics: topics,
+	}); ok {
+		return &Result{
+			Category:    rule.Category,
+			Subcategory: rule.Subcategory,
+			RuleID:      rule.ID,
+			Confidence:  1,
+			Reasoning:   "matched classification rule " + rule.ID,
+			Labels:      []database.CategoryLabel{{Category: rule.Category, Subcategory: rule.Subcategory, Confidence: 1}},
+			Description: description,
+			Topics:      topics,
+			ModelUsed:   RuleModelPrefix + rule.ID,
+			ReadmeHash:  readmeHash,
+			Duration:    time.Since(start),
+		}, nil
+	}
 
-	// Build prompts.
-	systemPrompt, err := BuildSystemPrompt(p.cfg.SystemPrompt, p.cfg.Categories)
-	if err != nil {
-		return nil, fmt.Errorf("building system prompt: %w", err)
+	target := database.LabeledExample{
+		FullName: repo.FullName, Language: repo.Language, Description: description, Topics: topics,
+	}
+	var embeddingModel string
+	if p.embedder != nil {
+		embeddingModel = p.embedder.Model()
+		vec, err := p.embedder.Embed(ctx, EmbeddingText(description, repo.Language, topics, readmeContent, p.cfg.MaxReadmeChars))
+		if err != nil {
+			// The LLM can still classify the repo; it just costs more.
+			log.Printf("[classification] WARNING: embedding %s: %v", repo.FullName, err)
+		} else {
+			target.Embedding = vec
+			if r := p.preClassify(vec); r != nil {
+				r.Description, r.Topics = description, topics
+				r.Embedding, r.EmbeddingModel = vec, embeddingModel
+				r.ReadmeHash = readmeHash
+				r.Duration = time.Since(start)
+				return r, nil
+			}
+		}
 	}
 
+	examples := p.fewShotExamples(target)
+
 	starTrend := "stable"
 	if repo.Stars > repo.StarsPrev {
 		starTrend = "rising"
@@ -123,40 +265,165 @@ func (p *Pipeline) ClassifySingle(ctx context.Context, repo database.RepoRecord)
 		starTrend = "declining"
 	}
 
-	userPrompt, err := BuildUserPrompt(p.cfg.UserPrompt, PromptData{
+	// Build prompts.
+	systemPrompt, userPrompt, err := BuildPrompts(p.cfg, PromptData{
 		RepoName:    repo.FullName,
 		Description: description,
 		Language:    repo.Language,
-		Topics:      topics,
+		Topics:      strings.Join(topics, ","),
 		Stars:       repo.Stars,
 		StarTrend:   starTrend,
-		Readme:      truncated,
-	})
+	}, readmeContent, examples)
 	if err != nil {
-		return nil, fmt.Errorf("building user prompt: %w", err)
+		return nil, err
 	}
 
-	// Call Ollama LLM.
-	llmResult, err := p.ollama.Classify(ctx, systemPrompt, userPrompt)
+	// Call the LLM.
+	llmResult, err := p.llm.Classify(ctx, systemPrompt, userPrompt)
 	if err != nil {
 		return &Result{
-			ModelUsed:  p.ollama.Model(),
+			ModelUsed:  p.llm.Model(),
 			ReadmeHash: readmeHash,
 			Duration:   time.Since(start),
-			Error:      fmt.Errorf("ollama classify: %w", err),
+			Error:      fmt.Errorf("llm classify: %w", err),
 		}, nil
 	}
 
 	return &Result{
-		Category:   llmResult.Category,
-		Confidence: llmResult.Confidence,
-		Reasoning:  llmResult.Reasoning,
-		ModelUsed:  p.ollama.Model(),
-		ReadmeHash: readmeHash,
-		Duration:   time.Since(start),
+		Category:    llmResult.Category,
+		Confidence:  llmResult.Confidence,
+		Reasoning:   llmResult.Reasoning,
+		Labels:      p.rankLabels(llmResult),
+		Agreement:   llmResult.Agreement,
+		Votes:       llmResult.Votes,
+		Description: description,
+		Topics:      topics,
+		ModelUsed:   p.llm.Model(),
+		ReadmeHash:  readmeHash,
+		Duration:    time.Since(start),
+
+		Embedding:      target.Embedding,
+		EmbeddingModel: embeddingModel,
 	}, nil
 }
 
+// preClassify assigns vec to its nearest category centroid, or returns
+// nil when the call is too close for the LLM to be skipped: fewer than
+// two centroids, a margin over the runner-up below
+// embedding.min_margin, or a similarity below min_confidence.
+func (p *Pipeline) preClassify(vec []float32) *Result {
+	if !p.centroidsLoaded {
+		p.centroidsLoaded = true
+		labeled, err := p.db.LabeledEmbeddings(p.embedder.Model(), p.cfg.MinConfidence)
+		if err != nil {
+			log.Printf("[classification] WARNING: loading labeled embeddings: %v", err)
+		}
+		p.centroids = BuildCentroids(labeled, p.cfg.Categories, p.cfg.Embedding.MinExamples)
+	}
+	best, similarity, margin, ok := NearestCentroid(p.centroids, vec)
+	if !ok || margin < p.cfg.Embedding.MinMargin || similarity < p.cfg.MinConfidence {
+		return nil
+	}
+	return &Result{
+		Category:   best.Answer,
+		Confidence: similarity,
+		Reasoning: fmt.Sprintf("nearest centroid %s/%s (%d labeled repos): similarity %.3f, margin %.3f over the runner-up",
+			best.Category, best.Subcategory, best.Examples, similarity, margin),
+		Labels:        []database.CategoryLabel{resolveLabel(best.Answer, similarity)},
+		ModelUsed:     database.EmbeddingModelPrefix + p.embedder.Model(),
+		PreClassified: true,
+	}
+}
+
+// fewShotExamples returns the labeled repos most similar to target, or
+// nil when few-shot prompting is off.
+func (p *Pipeline) fewShotExamples(target database.LabeledExample) []database.LabeledExample {
+	k := p.cfg.FewShot.Examples
+	if k <= 0 {
+		return nil
+	}
+	if !p.examplePoolLoaded {
+		p.examplePoolLoaded = true
+		pool, err := p.db.LabeledExamples(p.cfg.Embedding.Model)
+		if err != nil {
+			log.Printf("[classification] WARNING: loading few-shot examples: %v", err)
+		}
+		p.examplePool = pool
+	}
+	sim := TopicSimilarity
+	if len(target.Embedding) > 0 {
+		sim = EmbeddingSimilarity
+	}
+	return SelectExamples(p.examplePool, target, k, sim)
+}
+
+// Describe live-fetches the description and truncated README excerpt
+// the classifier sees for fullName, for showing to a reviewer.
+func (p *Pipeline) Describe(ctx context.Context, fullName string) (description, readme string, err error) {
+	gh, owner, name, err := p.repoClient(fullName)
+	if err != nil {
+		return "", "", err
+	}
+	if meta, err := gh.GetRepository(ctx, owner, name); err != nil {
+		return "", "", fmt.Errorf("fetching repository: %w", err)
+	} else if meta != nil {
+		description = meta.Description
+	}
+	resp, err := gh.GetReadme(ctx, owner, name)
+	if err != nil {
+		return "", "", fmt.Errorf("fetching readme: %w", err)
+	}
+	if resp.Found {
+		readme = TruncateReadme(resp.Content, p.cfg.MaxReadmeChars)
+	}
+	return description, readme, nil
+}
+
+// rankLabels resolves the primary and secondary categories of r to v3
+// (category, subcategory) pairs, primary first. A secondary label keeps
+// the subcategory the model gave it; one the v3 taxonomy does not allow
+// drops the label. Secondary labels below MinSecondaryConfidence,
+// beyond MaxSecondaryLabels or resolving to a pair already ranked are
+// dropped too.
+func (p *Pipeline) rankLabels(r *ClassificationResult) []database.CategoryLabel {
+	labels := []database.CategoryLabel{resolveLabel(r.Category, r.Confidence)}
+	for _, l := range r.Secondary {
+		if len(labels)-1 >= p.cfg.MaxSecondaryLabels {
+			break
+		}
+		if l.Confidence < p.cfg.MinSecondaryConfidence {
+			continue
+		}
+		label := resolveLabel(l.Category, l.Confidence)
+		if l.Subcategory != "" {
+			if !database.IsAllowedPair(label.Category, l.Subcategory) {
+				log.Printf("[classification] WARNING: dropping secondary label %s/%s: not an allowed pair", label.Category, l.Subcategory)
+				continue
+			}
+			label.Subcategory = l.Subcategory
+		}
+		dup := false
+		for _, ranked := range labels {
+			if ranked.Category == label.Category && ranked.Subcategory == label.Subcategory {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// resolveLabel maps a classifier category to its v3 pair the way
+// exported metrics see it.
+func resolveLabel(category string, confidence float64) database.CategoryLabel {
+	rec := database.RepoRecord{PrimaryCategory: category}
+	cat, sub, _ := rec.ResolveTaxonomy()
+	return database.CategoryLabel{Category: cat, Subcategory: sub, Confidence: confidence}
+}
+
 // CheckReadmeHashes checks all classified repos for README content changes.
 // For each repo whose README hash has changed, it marks the repo as needs_reclassify
 // via UpdateReadmeHash so it will be picked up by the next classification run.
@@ -181,8 +448,12 @@ func (p *Pipeline) CheckReadmeHashes(ctx context.Context) (int, error) {
 		default:
 		}
 
-		owner, name := splitFullName(repo.FullName)
-		readmeResp, err := p.gh.GetReadme(ctx, owner, name, "")
+		gh, owner, name, err := p.repoClient(repo.FullName)
+		if err != nil {
+			log.Printf("[classification] WARNING: failed to fetch README for %s: %v", repo.FullName, err)
+			continue
+		}
+		readmeResp, err := gh.GetReadme(ctx, owner, name)
 		if err != nil {
 			log.Printf("[classification] WARNING: failed to fetch README for %s: %v", repo.FullName, err)
 			continue
@@ -212,25 +483,50 @@ func (p *Pipeline) CheckReadmeHashes(ctx context.Context) (int, error) {
 	return changed, nil
 }
 
+// RunLimits bounds one ClassifyAllWithin run, so the daemon's budget
+// planner can defer part of the work to a later cycle.
+type RunLimits struct {
+	// MaxRepos caps how many repos are classified. 0 = no cap.
+	MaxRepos int
+	// SkipReadmeCheck skips the CheckReadmeHashes pre-step, e.g. because
+	// the caller ran it already or deferred it.
+	SkipReadmeCheck bool
+}
+
 // ClassifyAll queries the DB for repos needing classification and classifies each one.
 // It first checks all classified repos for README hash changes, marking changed ones
 // as needs_reclassify so they are included in this classification run.
 // Results are persisted to the database. Progress is written to stderr.
 func (p *Pipeline) ClassifyAll(ctx context.Context) (*Summary, error) {
+	return p.ClassifyAllWithin(ctx, RunLimits{})
+}
+
+// ClassifyAllWithin is ClassifyAll bounded by limits. Repos left out stay
+// in their current status and are picked up by a later run.
+func (p *Pipeline) ClassifyAllWithin(ctx context.Context, limits RunLimits) (*Summary, error) {
 	start := time.Now()
 
 	// Pre-step: detect README changes in already-classified repos.
-	if _, err := p.CheckReadmeHashes(ctx); err != nil {
-		log.Printf("[classification] WARNING: readme hash check failed: %v", err)
-		// Continue with classification even if hash check fails.
+	if !limits.SkipReadmeCheck {
+		if _, err := p.CheckReadmeHashes(ctx); err != nil {
+			log.Printf("[classification] WARNING: readme hash check failed: %v", err)
+			// Continue with classification even if hash check fails.
+		}
 	}
 
 	repos, err := p.db.ReposNeedingClassification()
 	if err != nil {
 		return nil, fmt.Errorf("querying repos needing classification: %w", err)
 	}
+	if limits.MaxRepos > 0 && len(repos) > limits.MaxRepos {
+		repos = repos[:limits.MaxRepos]
+	}
 
 	summary := &Summary{Total: len(repos)}
+	// Reload centroids and the few-shot pool so repos labeled since the
+	// last run count.
+	p.centroidsLoaded = false
+	p.examplePoolLoaded = false
 
 	for i, repo := range repos {
 		select {
@@ -264,20 +560,70 @@ func (p *Pipeline) ClassifyAll(ctx context.Context) (*Summary, error) {
 		// Persist result using the hash from ClassifySingle (no double fetch).
 		// Pass MinConfidence so the DB layer can set needs_review for low-confidence results.
 		if err := p.db.UpdateClassification(
-			repo.FullName, result.Category, result.Confidence, result.ReadmeHash, result.ModelUsed, p.cfg.MinConfidence,
+			repo.FullName, result.Category, result.Subcategory, result.Confidence, result.ReadmeHash, result.ModelUsed, p.cfg.MinConfidence,
 		); err != nil {
 			log.Printf("[classification] ERROR saving classification for %s: %v", repo.FullName, err)
 			summary.Failed++
 			continue
 		}
+		if err := p.db.SetClassificationReasoning(repo.FullName, result.Reasoning); err != nil {
+			log.Printf("[classification] WARNING: saving reasoning for %s: %v", repo.FullName, err)
+		}
+		if err := p.db.SetClassificationInputs(repo.FullName, result.Description, result.Topics); err != nil {
+			log.Printf("[classification] WARNING: saving classification inputs for %s: %v", repo.FullName, err)
+		}
+		if len(result.Embedding) > 0 {
+			if err := p.db.SetEmbedding(repo.FullName, result.EmbeddingModel, result.Embedding); err != nil {
+				log.Printf("[classification] WARNING: saving embedding for %s: %v", repo.FullName, err)
+			}
+		}
+		var secondary []database.CategoryLabel
+		if len(result.Labels) > 1 {
+			secondary = result.Labels[1:]
+		}
+		if err := p.db.SetSecondaryLabels(repo.FullName, secondary); err != nil {
+			log.Printf("[classification] WARNING: saving secondary labels for %s: %v", repo.FullName, err)
+		}
+		lowAgreement := false
+		if len(result.Votes) > 0 {
+			// Low agreement also lands in needs_review, with every
+			// candidate answer stored for the reviewer.
+			lowAgreement = result.Agreement < p.cfg.Ensemble.MinAgreement
+			if err := p.db.SetEnsembleVotes(repo.FullName, result.Agreement, ensembleVotes(result.Votes), p.cfg.Ensemble.MinAgreement); err != nil {
+				log.Printf("[classification] WARNING: saving ensemble votes for %s: %v", repo.FullName, err)
+			}
+		} else {
+			// A rule, the embedding pre-classifier or a single model
+			// decided; drop votes from an earlier ensemble run so the
+			// review queue does not show them.
+			if err := p.db.ClearEnsembleVotes(repo.FullName); err != nil {
+				log.Printf("[classification] WARNING: clearing ensemble votes for %s: %v", repo.FullName, err)
+			}
+		}
 
 		if result.Confidence < p.cfg.MinConfidence {
 			fmt.Fprintf(os.Stderr, " %s (%.0f%% < %.0f%% threshold → needs_review) [%s]\n",
 				result.Category, result.Confidence*100, p.cfg.MinConfidence*100, result.Duration.Round(time.Millisecond))
 			summary.NeedsReview++
+		} else if lowAgreement {
+			fmt.Fprintf(os.Stderr, " %s (agreement %.0f%% < %.0f%% → needs_review) [%s]\n",
+				result.Category, result.Agreement*100, p.cfg.Ensemble.MinAgreement*100, result.Duration.Round(time.Millisecond))
+			summary.NeedsReview++
 		} else {
-			fmt.Fprintf(os.Stderr, " %s (%.0f%%) [%s]\n",
-				result.Category, result.Confidence*100, result.Duration.Round(time.Millisecond))
+			category, via := result.Category, ""
+			if result.Subcategory != "" {
+				category += "/" + result.Subcategory
+			}
+			switch {
+			case result.RuleID != "":
+				via = " via rule " + result.RuleID
+				summary.Ruled++
+			case result.PreClassified:
+				via = " via embedding"
+				summary.Embedded++
+			}
+			fmt.Fprintf(os.Stderr, " %s (%.0f%%)%s%s [%s]\n",
+				category, result.Confidence*100, secondarySuffix(secondary), via, result.Duration.Round(time.Millisecond))
 			summary.Classified++
 		}
 	}
@@ -286,11 +632,23 @@ func (p *Pipeline) ClassifyAll(ctx context.Context) (*Summary, error) {
 	return summary, nil
 }
 
-// splitFullName splits "owner/repo" into owner and repo parts.
-func splitFullName(fullName string) (string, string) {
-	parts := strings.SplitN(fullName, "/", 2)
-	if len(parts) != 2 {
-		return fullName, ""
+// ensembleVotes converts member votes to their stored form.
+func ensembleVotes(votes []Vote) []database.EnsembleVote {
+	out := make([]database.EnsembleVote, len(votes))
+	for i, v := range votes {
+		out[i] = database.EnsembleVote{Model: v.Model, Category: v.Category, Confidence: v.Confidence, Reasoning: v.Reasoning}
+	}
+	return out
+}
+
+// secondarySuffix renders secondary labels for the progress line.
+func secondarySuffix(labels []database.CategoryLabel) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = fmt.Sprintf("%s/%s %.0f%%", l.Category, l.Subcategory, l.Confidence*100)
 	}
-	return parts[0], parts[1]
+	return " + " + strings.Join(parts, ", ")
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hrexed/github-radar/internal/config"
@@ -115,25 +116,49 @@ func mustJSON(v interface{}) string {
 	return string(b)
 }
 
-// --- splitFullName ---
+// --- repoClient ---
+
+func TestRepoClient(t *testing.T) {
+	public, err := github.NewClient("public-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal, err := github.NewClient("internal-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(nil, public, nil, config.ClassificationConfig{})
+	p.SetHostClient("ghe.corp", internal)
 
-func TestSplitFullName(t *testing.T) {
 	tests := []struct {
 		input     string
+		wantGH    *github.Client
 		wantOwner string
 		wantRepo  string
+		wantErr   bool
 	}{
-		{"owner/repo", "owner", "repo"},
-		{"org/my-project", "org", "my-project"},
-		{"noslash", "noslash", ""},
-		{"a/b/c", "a", "b/c"},
+		{input: "owner/repo", wantGH: public, wantOwner: "owner", wantRepo: "repo"},
+		{input: "github.com/org/my-project", wantGH: public, wantOwner: "org", wantRepo: "my-project"},
+		{input: "ghe.corp/team/svc", wantGH: internal, wantOwner: "team", wantRepo: "svc"},
+		{input: "ghe.other/team/svc", wantErr: true},
+		{input: "noslash", wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			owner, repo := splitFullName(tt.input)
-			if owner != tt.wantOwner || repo != tt.wantRepo {
-				t.Errorf("splitFullName(%q) = (%q, %q), want (%q, %q)",
-					tt.input, owner, repo, tt.wantOwner, tt.wantRepo)
+			gh, owner, repo, err := p.repoClient(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("repoClient(%q): expected error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("repoClient(%q): %v", tt.input, err)
+			}
+			fp, ok := gh.(*github.ForgeProvider)
+			if !ok || fp.Client() != tt.wantGH || owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("repoClient(%q) = (%v, %q, %q), want (%p, %q, %q)",
+					tt.input, gh, owner, repo, tt.wantGH, tt.wantOwner, tt.wantRepo)
 			}
 		})
 	}
@@ -389,6 +414,155 @@ func TestClassifyAll_BatchClassification(t *testing.T) {
 	}
 }
 
+func TestClassifyAll_PersistsSecondaryLabels(t *testing.T) {
+	readmes := map[string]string{"a/one": "# Agents with tracing"}
+	pipeline, deps := setupPipeline(t,
+		ghReadmeHandler(readmes),
+		func(w http.ResponseWriter, r *http.Request) {
+			resp := chatResponse{}
+			resp.Message.Content = mustJSON(map[string]interface{}{
+				"category":   "ai-agents",
+				"confidence": 0.9,
+				"reasoning":  "test reasoning",
+				"secondary": []map[string]interface{}{
+					{"category": "kubernetes", "subcategory": "agents", "confidence": 0.8},
+					{"category": "observability", "confidence": 0.7},
+					{"category": "kubernetes", "subcategory": "service-mesh", "confidence": 0.6},
+					{"category": "kubernetes", "confidence": 0.3},
+				},
+			})
+			json.NewEncoder(w).Encode(resp)
+		},
+	)
+	pipeline.cfg.MaxSecondaryLabels = 3
+	pipeline.cfg.MinSecondaryConfidence = 0.5
+
+	if err := deps.db.UpsertRepo(&database.RepoRecord{FullName: "a/one", Owner: "a", Name: "one", Status: "pending"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+
+	if _, err := pipeline.ClassifyAll(context.Background()); err != nil {
+		t.Fatalf("ClassifyAll error: %v", err)
+	}
+
+	got, err := deps.db.SecondaryLabels("a/one")
+	if err != nil {
+		t.Fatalf("SecondaryLabels: %v", err)
+	}
+	// cloud-native/agents is not an allowed pair and kubernetes alone
+	// is below min_secondary_confidence.
+	want := []database.CategoryLabel{
+		{Category: "cloud-native", Subcategory: "observability", Confidence: 0.7},
+		{Category: "cloud-native", Subcategory: "service-mesh", Confidence: 0.6},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("SecondaryLabels = %+v, want %+v", got, want)
+	}
+}
+
+func TestClassifyAll_EnsembleLowAgreementNeedsReview(t *testing.T) {
+	readmes := map[string]string{"a/one": "# Repo One"}
+	pipeline, deps := setupPipeline(t, ghReadmeHandler(readmes), ollamaSuccess("other", 0))
+	pipeline.llm = NewEnsemble(
+		vote("m1", "kubernetes", 0.9),
+		vote("m2", "observability", 0.8),
+		vote("m3", "ai-agents", 0.7),
+	)
+	pipeline.cfg.Ensemble.MinAgreement = 0.6
+
+	if err := deps.db.UpsertRepo(&database.RepoRecord{FullName: "a/one", Owner: "a", Name: "one", Status: "pending"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+
+	summary, err := pipeline.ClassifyAll(context.Background())
+	if err != nil {
+		t.Fatalf("ClassifyAll error: %v", err)
+	}
+	if summary.NeedsReview != 1 || summary.Classified != 0 {
+		t.Errorf("summary = %+v, want 1 needs_review", summary)
+	}
+
+	got, err := deps.db.GetRepo("a/one")
+	if err != nil {
+		t.Fatalf("GetRepo: %v", err)
+	}
+	if got.Status != "needs_review" {
+		t.Errorf("status = %q, want needs_review (agreement 1/3 < 0.6)", got.Status)
+	}
+	if got.ModelUsed != "m1+m2+m3" {
+		t.Errorf("model_used = %q, want m1+m2+m3", got.ModelUsed)
+	}
+
+	agreement, votes, err := deps.db.EnsembleVotes("a/one")
+	if err != nil {
+		t.Fatalf("EnsembleVotes: %v", err)
+	}
+	if agreement != 1.0/3.0 || len(votes) != 3 {
+		t.Fatalf("EnsembleVotes = %v, %+v; want 1/3 and all three candidates", agreement, votes)
+	}
+	if votes[2].Model != "m3" || votes[2].Category != "ai-agents" || votes[2].Reasoning != "m3 says ai-agents" {
+		t.Errorf("votes[2] = %+v", votes[2])
+	}
+
+	// Reclassified by a single model, the repo keeps no stale votes.
+	pipeline.llm = vote("m1", "kubernetes", 0.9)
+	readmes["a/one"] = "# Repo One, rewritten"
+	if _, err := pipeline.ClassifyAll(context.Background()); err != nil {
+		t.Fatalf("second ClassifyAll error: %v", err)
+	}
+	if agreement, votes, err := deps.db.EnsembleVotes("a/one"); err != nil || agreement != 0 || votes != nil {
+		t.Errorf("EnsembleVotes after single-model run = %v, %+v, %v; want none", agreement, votes, err)
+	}
+}
+
+func TestClassifyAllWithin_SkipsReadmeCheckAndCapsRepos(t *testing.T) {
+	readmes := map[string]string{
+		"a/one":  "# Repo One",
+		"b/two":  "# Repo Two",
+		"c/old":  "# Repo Old",
+		"d/done": "# Changed README",
+	}
+	pipeline, deps := setupPipeline(t,
+		ghReadmeHandler(readmes),
+		ollamaSuccess("kubernetes", 0.9),
+	)
+
+	repos := []*database.RepoRecord{
+		{FullName: "a/one", Owner: "a", Name: "one", Status: "pending"},
+		{FullName: "b/two", Owner: "b", Name: "two", Status: "pending"},
+		{FullName: "c/old", Owner: "c", Name: "old", Status: "needs_reclassify", PrimaryCategory: "observability"},
+		{FullName: "d/done", Owner: "d", Name: "done", Status: "active", PrimaryCategory: "observability", ReadmeHash: "stale"},
+	}
+	for _, r := range repos {
+		if err := deps.db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo(%s): %v", r.FullName, err)
+		}
+	}
+
+	summary, err := pipeline.ClassifyAllWithin(context.Background(), RunLimits{MaxRepos: 1, SkipReadmeCheck: true})
+	if err != nil {
+		t.Fatalf("ClassifyAllWithin error: %v", err)
+	}
+	if summary.Total != 1 || summary.Classified != 1 {
+		t.Errorf("summary = %+v, want one repo classified", summary)
+	}
+
+	for name, want := range map[string]string{
+		"a/one":  "active",
+		"b/two":  "pending",
+		"c/old":  "needs_reclassify", // past the cap
+		"d/done": "active",           // README hash check skipped
+	} {
+		got, err := deps.db.GetRepo(name)
+		if err != nil {
+			t.Fatalf("GetRepo(%s): %v", name, err)
+		}
+		if got.Status != want {
+			t.Errorf("%s status = %q, want %q", name, got.Status, want)
+		}
+	}
+}
+
 func TestClassifyAll_LowConfidenceNeedsReview(t *testing.T) {
 	readmes := map[string]string{"test/low": "# Low confidence"}
 	pipeline, deps := setupPipeline(t,
@@ -420,6 +594,10 @@ func TestClassifyAll_LowConfidenceNeedsReview(t *testing.T) {
 	if got.Status != "needs_review" {
 		t.Errorf("status = %q, want %q", got.Status, "needs_review")
 	}
+	// The reasoning is kept for the review queue.
+	if reasoning, _ := deps.db.ClassificationReasoning("test/low"); reasoning != "test reasoning" {
+		t.Errorf("reasoning = %q, want %q", reasoning, "test reasoning")
+	}
 }
 
 func TestClassifyAll_EmptyBatch(t *testing.T) {
@@ -617,3 +795,202 @@ func TestCheckReadmeHashes_GitHubError(t *testing.T) {
 		t.Errorf("changed = %d, want 0 (GitHub error should be skipped)", changed)
 	}
 }
+
+func TestClassifySingle_FewShotExamples(t *testing.T) {
+	gh := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/a/new/readme":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("r", 1000)))
+		case "/repos/a/new":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"new","full_name":"a/new","owner":{"login":"a"},"description":"new operator","topics":["kubernetes","operator"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}
+	var userPrompt string
+	ollama := func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		userPrompt = req.Messages[1].Content
+		ollamaSuccess("kubernetes", 0.9)(w, r)
+	}
+	pipeline, deps := setupPipeline(t, gh, ollama)
+	pipeline.cfg.MaxReadmeChars = 400
+	pipeline.cfg.FewShot = config.FewShotConfig{Examples: 2, MaxShare: 0.5}
+	pipeline.cfg.UserPrompt = `{{.Examples}}README: {{.Readme}}`
+
+	if err := deps.db.UpsertRepo(&database.RepoRecord{
+		FullName: "a/labeled", Owner: "a", Name: "labeled", Status: "active", ForceCategory: "cloud-native", ForceSubcategory: "kubernetes",
+	}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+	if err := deps.db.SetClassificationInputs("a/labeled", "an operator", []string{"operator"}); err != nil {
+		t.Fatalf("SetClassificationInputs: %v", err)
+	}
+
+	result, err := pipeline.ClassifySingle(context.Background(), database.RepoRecord{FullName: "a/new", Owner: "a", Name: "new"})
+	if err != nil || result.Error != nil {
+		t.Fatalf("ClassifySingle: %v / %v", err, result.Error)
+	}
+
+	examples, readme, _ := strings.Cut(userPrompt, "README: ")
+	if !strings.Contains(examples, "Repository: a/labeled") || !strings.Contains(examples, "Category: kubernetes") {
+		t.Errorf("user prompt lacks the labeled example:\n%s", userPrompt)
+	}
+	if n := len([]rune(examples)) + len([]rune(readme)); n > 400 {
+		t.Errorf("examples + README = %d chars, want <= max_readme_chars (400)", n)
+	}
+	if result.Description != "new operator" || len(result.Topics) != 2 {
+		t.Errorf("result inputs = %q %v", result.Description, result.Topics)
+	}
+}
+
+// stubEmbedder embeds text as the vector of the keyword it contains.
+type stubEmbedder map[string][]float32
+
+func (s stubEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	for kw, vec := range s {
+		if strings.Contains(text, kw) {
+			return vec, nil
+		}
+	}
+	return []float32{0, 0, 1}, nil
+}
+
+func (s stubEmbedder) Model() string { return "stub-embed" }
+
+func TestClassifyAll_EmbeddingPreClassifier(t *testing.T) {
+	readmes := map[string]string{
+		"a/clear": "a kubernetes operator",
+		"a/mixed": "an operator for agents",
+	}
+	llmCalls := 0
+	pipeline, deps := setupPipeline(t, ghReadmeHandler(readmes), func(w http.ResponseWriter, r *http.Request) {
+		llmCalls++
+		ollamaSuccess("ai-agents", 0.9)(w, r)
+	})
+	pipeline.cfg.Embedding = config.EmbeddingConfig{Model: "stub-embed", MinMargin: 0.1, MinExamples: 2}
+	pipeline.SetEmbedder(stubEmbedder{
+		"kubernetes":          {1, 0, 0},
+		"operator for agents": {0.7, 0.7, 0},
+	})
+
+	labeled := []struct {
+		name, category, subcategory string
+		vec                         []float32
+	}{
+		{"l/k1", "cloud-native", "kubernetes", []float32{1, 0.1, 0}},
+		{"l/k2", "cloud-native", "kubernetes", []float32{1, -0.1, 0}},
+		{"l/a1", "ai", "agents", []float32{0.1, 1, 0}},
+		{"l/a2", "ai", "agents", []float32{-0.1, 1, 0}},
+		// Decided by the embedding stage itself: never seeds a centroid.
+		{"l/e1", "", "", []float32{0, 0, 1}},
+	}
+	for _, l := range labeled {
+		rec := &database.RepoRecord{FullName: l.name, Owner: "l", Name: l.name[2:], Status: "active",
+			PrimaryCategory: "observability", ForceCategory: l.category, ForceSubcategory: l.subcategory}
+		if err := deps.db.UpsertRepo(rec); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+		if err := deps.db.SetEmbedding(l.name, "stub-embed", l.vec); err != nil {
+			t.Fatalf("SetEmbedding: %v", err)
+		}
+	}
+	if err := deps.db.UpdateClassification("l/e1", "observability", "", 0.99, "", database.EmbeddingModelPrefix+"stub-embed", 0.6); err != nil {
+		t.Fatalf("UpdateClassification: %v", err)
+	}
+	for _, name := range []string{"a/clear", "a/mixed"} {
+		if err := deps.db.UpsertRepo(&database.RepoRecord{FullName: name, Owner: "a", Name: name[2:], Status: "pending"}); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+
+	summary, err := pipeline.ClassifyAll(context.Background())
+	if err != nil {
+		t.Fatalf("ClassifyAll: %v", err)
+	}
+	if summary.Classified != 2 || summary.Embedded != 1 {
+		t.Errorf("Classified = %d, Embedded = %d, want 2 and 1", summary.Classified, summary.Embedded)
+	}
+	if llmCalls != 1 {
+		t.Errorf("LLM calls = %d, want 1 (only the close call)", llmCalls)
+	}
+
+	clear, _ := deps.db.GetRepo("a/clear")
+	if clear.PrimaryCategory != "kubernetes" || clear.ModelUsed != "embedding:stub-embed" || clear.Status != "active" {
+		t.Errorf("a/clear = %q by %q (%s), want kubernetes by embedding:stub-embed", clear.PrimaryCategory, clear.ModelUsed, clear.Status)
+	}
+	mixed, _ := deps.db.GetRepo("a/mixed")
+	if mixed.PrimaryCategory != "ai-agents" || mixed.ModelUsed != "test-model" {
+		t.Errorf("a/mixed = %q by %q, want ai-agents by test-model", mixed.PrimaryCategory, mixed.ModelUsed)
+	}
+
+	// Both vectors are stored; only the LLM answer may seed centroids.
+	seeds, err := deps.db.LabeledEmbeddings("stub-embed", 0.6)
+	if err != nil {
+		t.Fatalf("LabeledEmbeddings: %v", err)
+	}
+	var names []string
+	for _, s := range seeds {
+		names = append(names, s.FullName)
+	}
+	if got := strings.Join(names, ","); got != "a/mixed,l/a1,l/a2,l/k1,l/k2" {
+		t.Errorf("centroid seeds = %s", got)
+	}
+}
+
+func TestClassifyAll_RuleSkipsLLM(t *testing.T) {
+	gh := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/a/op/readme", "/repos/a/app/readme":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("# readme"))
+		case "/repos/a/op":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"op","full_name":"a/op","owner":{"login":"a"},"topics":["kubernetes-operator"]}`))
+		case "/repos/a/app":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"app","full_name":"a/app","owner":{"login":"a"},"topics":["web"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}
+	llmCalls := 0
+	pipeline, deps := setupPipeline(t, gh, func(w http.ResponseWriter, r *http.Request) {
+		llmCalls++
+		ollamaSuccess("observability", 0.9)(w, r)
+	})
+	rules, err := NewRuleSet([]config.ClassificationRule{
+		{ID: "k8s-operator", Topics: []string{"kubernetes-operator"}, Category: "cloud-native", Subcategory: "kubernetes"},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet: %v", err)
+	}
+	pipeline.SetRules(rules)
+
+	for _, name := range []string{"a/op", "a/app"} {
+		if err := deps.db.UpsertRepo(&database.RepoRecord{FullName: name, Owner: "a", Name: name[2:], Status: "pending"}); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+
+	summary, err := pipeline.ClassifyAll(context.Background())
+	if err != nil {
+		t.Fatalf("ClassifyAll: %v", err)
+	}
+	if summary.Classified != 2 || summary.Ruled != 1 || llmCalls != 1 {
+		t.Errorf("Classified = %d, Ruled = %d, LLM calls = %d; want 2, 1, 1", summary.Classified, summary.Ruled, llmCalls)
+	}
+
+	op, _ := deps.db.GetRepo("a/op")
+	category, subcategory, _ := op.ResolveTaxonomy()
+	if category != "cloud-native" || subcategory != "kubernetes" || op.ModelUsed != "rule:k8s-operator" || op.CategoryConfidence != 1 {
+		t.Errorf("a/op = %s/%s by %q (%.2f), want cloud-native/kubernetes by rule:k8s-operator", category, subcategory, op.ModelUsed, op.CategoryConfidence)
+	}
+	app, _ := deps.db.GetRepo("a/app")
+	if app.PrimaryCategory != "observability" || app.ModelUsed != "test-model" {
+		t.Errorf("a/app = %q by %q, want observability by test-model", app.PrimaryCategory, app.ModelUsed)
+	}
+}
@@ -2,13 +2,20 @@ package classification
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"text/template"
+	"text/template/parse"
+	"unicode/utf8"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
 )
 
 // SystemPromptData holds the data available to the system prompt template.
 type SystemPromptData struct {
-	Categories string // Comma-separated list of allowed categories.
+	Categories    string // Comma-separated list of allowed categories.
+	Subcategories string // v3 subcategories per category the allowed ones roll up to, "cat: a, b; cat2: c"
 }
 
 // PromptData holds the data available to the user prompt template.
@@ -20,6 +27,7 @@ type PromptData struct {
 	Stars       int
 	StarTrend   string
 	Readme      string
+	Examples    string // Few-shot examples rendered by FormatExamples; empty when none
 }
 
 // BuildSystemPrompt renders the system prompt Go template with the given categories.
@@ -31,7 +39,8 @@ func BuildSystemPrompt(systemTemplate string, categories []string) (string, erro
 	}
 
 	data := SystemPromptData{
-		Categories: strings.Join(categories, ", "),
+		Categories:    strings.Join(categories, ", "),
+		Subcategories: subcategoryList(categories),
 	}
 
 	var buf bytes.Buffer
@@ -41,6 +50,36 @@ func BuildSystemPrompt(systemTemplate string, categories []string) (string, erro
 	return buf.String(), nil
 }
 
+// subcategoryList renders the v3 subcategories of each v3 category the
+// given categories roll up to, in category order, as
+// "ai: agents, rag; cloud-native: kubernetes". The "other" sinks are
+// left out.
+func subcategoryList(categories []string) string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, c := range categories {
+		v3 := c
+		if pair, ok := database.LegacyCategoryMap[c]; ok {
+			v3 = pair.Category
+		}
+		subs, ok := database.TaxonomyV2[v3]
+		if !ok || seen[v3] {
+			continue
+		}
+		seen[v3] = true
+		var names []string
+		for _, s := range subs {
+			if s != "other" {
+				names = append(names, s)
+			}
+		}
+		if len(names) > 0 {
+			groups = append(groups, v3+": "+strings.Join(names, ", "))
+		}
+	}
+	return strings.Join(groups, "; ")
+}
+
 // BuildUserPrompt renders the user prompt Go template with the given PromptData.
 func BuildUserPrompt(userTemplate string, data PromptData) (string, error) {
 	tmpl, err := template.New("user").Parse(userTemplate)
@@ -54,3 +93,93 @@ func BuildUserPrompt(userTemplate string, data PromptData) (string, error) {
 	}
 	return buf.String(), nil
 }
+
+// BuildPrompts renders the system and user prompts cfg describes for one
+// repo. When the user template references .Examples, examples are
+// rendered into data.Examples within few_shot.max_share of
+// max_readme_chars and readme is truncated to what they leave of it;
+// otherwise they are ignored and the README keeps the whole budget.
+func BuildPrompts(cfg config.ClassificationConfig, data PromptData, readme string, examples []database.LabeledExample) (system, user string, err error) {
+	system, err = BuildSystemPrompt(cfg.SystemPrompt, cfg.Categories)
+	if err != nil {
+		return "", "", fmt.Errorf("building system prompt: %w", err)
+	}
+
+	tmpl, err := template.New("user").Parse(cfg.UserPrompt)
+	if err != nil {
+		return "", "", fmt.Errorf("building user prompt: %w", err)
+	}
+
+	readmeChars := cfg.MaxReadmeChars
+	budget := int(float64(cfg.MaxReadmeChars) * cfg.FewShot.MaxShare)
+	if len(examples) > 0 && (cfg.MaxReadmeChars <= 0 || budget > 0) && referencesField(tmpl, "Examples") {
+		data.Examples = FormatExamples(examples, cfg.Categories, budget)
+		if readmeChars > 0 {
+			readmeChars = max(readmeChars-utf8.RuneCountInString(data.Examples), 1)
+		}
+	}
+	data.Readme = TruncateReadme(readme, readmeChars)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("building user prompt: %w", err)
+	}
+	return system, buf.String(), nil
+}
+
+// referencesField reports whether any template in tmpl's set uses the
+// data field name, e.g. {{.Examples}} or {{if .Examples}}.
+func referencesField(tmpl *template.Template, name string) bool {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && nodeReferencesField(t.Tree.Root, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeReferencesField(node parse.Node, name string) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Nodes {
+			if nodeReferencesField(c, name) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return nodeReferencesField(n.Pipe, name)
+	case *parse.TemplateNode:
+		return nodeReferencesField(n.Pipe, name)
+	case *parse.IfNode:
+		return branchReferencesField(&n.BranchNode, name)
+	case *parse.RangeNode:
+		return branchReferencesField(&n.BranchNode, name)
+	case *parse.WithNode:
+		return branchReferencesField(&n.BranchNode, name)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if nodeReferencesField(arg, name) {
+					return true
+				}
+			}
+		}
+	case *parse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == name
+	case *parse.ChainNode:
+		return nodeReferencesField(n.Node, name)
+	}
+	return false
+}
+
+func branchReferencesField(b *parse.BranchNode, name string) bool {
+	return nodeReferencesField(b.Pipe, name) ||
+		nodeReferencesField(b.List, name) ||
+		nodeReferencesField(b.ElseList, name)
+}
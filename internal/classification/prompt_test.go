@@ -3,6 +3,9 @@ package classification
 import (
 	"strings"
 	"testing"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
 )
 
 func TestBuildSystemPrompt(t *testing.T) {
@@ -20,6 +23,18 @@ func TestBuildSystemPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildSystemPrompt_Subcategories(t *testing.T) {
+	result, err := BuildSystemPrompt(`{{.Subcategories}}`, []string{"rag", "ai-agents", "web", "made-up", "other"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "ai: agents, coding-assistants, llm-tooling, mcp-ecosystem, infrastructure, rag, vector-database, computer-vision, voice-and-audio, mlops; " +
+		"web: frameworks, frontend-ui, css-styling"
+	if result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
 func TestBuildSystemPrompt_DefaultTemplate(t *testing.T) {
 	categories := []string{"kubernetes", "observability", "other"}
 	tmpl := `You are a GitHub repository classifier for CNCF and cloud-native projects.
@@ -106,3 +121,32 @@ func TestBuildUserPrompt_InvalidTemplate(t *testing.T) {
 		t.Fatal("expected error for invalid template")
 	}
 }
+
+func TestBuildPrompts_ExamplesOnlyWhenReferenced(t *testing.T) {
+	cfg := config.ClassificationConfig{
+		SystemPrompt:   `Categories: {{.Categories}}`,
+		Categories:     []string{"kubernetes"},
+		MaxReadmeChars: 200,
+		FewShot:        config.FewShotConfig{Examples: 1, MaxShare: 0.5},
+	}
+	examples := []database.LabeledExample{{FullName: "x/op", Category: "cloud-native", Subcategory: "kubernetes"}}
+	readme := strings.Repeat("r", 200)
+
+	cfg.UserPrompt = `README: {{.Readme}}`
+	_, user, err := BuildPrompts(cfg, PromptData{}, readme, examples)
+	if err != nil {
+		t.Fatalf("BuildPrompts: %v", err)
+	}
+	if user != "README: "+readme {
+		t.Errorf("template without .Examples should keep the whole README budget, got %q", user)
+	}
+
+	cfg.UserPrompt = `{{if .Examples}}{{.Examples}}{{end}}README: {{.Readme}}`
+	_, user, err = BuildPrompts(cfg, PromptData{}, readme, examples)
+	if err != nil {
+		t.Fatalf("BuildPrompts: %v", err)
+	}
+	if !strings.Contains(user, "x/op") || strings.Contains(user, readme) {
+		t.Errorf("examples should be rendered and the README shortened, got %q", user)
+	}
+}
@@ -0,0 +1,121 @@
+package classification
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+// RuleModelPrefix marks model_used for repos a classification rule
+// decided; the rule ID follows it.
+const RuleModelPrefix = "rule:"
+
+// RuleInput is what classification rules match against.
+type RuleInput struct {
+	Owner       string
+	Name        string
+	Language    string
+	Description string
+	Topics      []string
+}
+
+// RuleSet holds the compiled classification.rules in config order.
+type RuleSet struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	config.ClassificationRule
+	name *regexp.Regexp
+}
+
+// NewRuleSet compiles rules, or returns nil when there are none. Each
+// rule's (category, subcategory) must be allowed by
+// database.IsAllowedPair.
+func NewRuleSet(rules []config.ClassificationRule) (*RuleSet, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	rs := &RuleSet{rules: make([]compiledRule, 0, len(rules))}
+	for _, r := range rules {
+		if !database.IsAllowedPair(r.Category, r.Subcategory) {
+			return nil, fmt.Errorf("rule %s: %s/%s is not an allowed pair", r.ID, r.Category, r.Subcategory)
+		}
+		c := compiledRule{ClassificationRule: r}
+		if r.Name != "" {
+			re, err := regexp.Compile(r.Name)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid name regexp: %w", r.ID, err)
+			}
+			c.name = re
+		}
+		rs.rules = append(rs.rules, c)
+	}
+	return rs, nil
+}
+
+// Len returns the number of rules; a nil RuleSet has none.
+func (rs *RuleSet) Len() int {
+	if rs == nil {
+		return 0
+	}
+	return len(rs.rules)
+}
+
+// Match returns the first rule matching in, in config order.
+func (rs *RuleSet) Match(in RuleInput) (config.ClassificationRule, bool) {
+	if rs == nil {
+		return config.ClassificationRule{}, false
+	}
+	for _, r := range rs.rules {
+		if r.matches(in) {
+			return r.ClassificationRule, true
+		}
+	}
+	return config.ClassificationRule{}, false
+}
+
+func (r compiledRule) matches(in RuleInput) bool {
+	if len(r.Topics) > 0 && !anyEqualFold(r.Topics, in.Topics) {
+		return false
+	}
+	if len(r.Languages) > 0 && !anyEqualFold(r.Languages, []string{in.Language}) {
+		return false
+	}
+	if r.name != nil && !r.name.MatchString(in.Name) {
+		return false
+	}
+	if len(r.Orgs) > 0 && !anyEqualFold(r.Orgs, []string{in.Owner}) {
+		return false
+	}
+	if len(r.Keywords) > 0 {
+		description := strings.ToLower(in.Description)
+		found := false
+		for _, k := range r.Keywords {
+			if strings.Contains(description, strings.ToLower(k)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// anyEqualFold reports whether any of want equals any of have,
+// ignoring case. Empty values never match.
+func anyEqualFold(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h != "" && strings.EqualFold(w, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
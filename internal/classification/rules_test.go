@@ -0,0 +1,57 @@
+package classification
+
+import (
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+func TestRuleSet_Match(t *testing.T) {
+	rs, err := NewRuleSet([]config.ClassificationRule{
+		{ID: "k8s-operator", Topics: []string{"kubernetes-operator"}, Category: "cloud-native", Subcategory: "kubernetes"},
+		{ID: "solidity", Languages: []string{"Solidity"}, Category: "crypto", Subcategory: "blockchain-web3"},
+		{ID: "awesome", Name: `^awesome-`, Keywords: []string{"curated list"}, Category: "devtools", Subcategory: "awesome-lists"},
+		{ID: "acme-ml", Orgs: []string{"acme-ml"}, Category: "ai", Subcategory: "mlops"},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		in   RuleInput
+		want string // rule ID; "" for no match
+	}{
+		{"topic, any case", RuleInput{Topics: []string{"go", "Kubernetes-Operator"}}, "k8s-operator"},
+		{"first rule wins", RuleInput{Language: "solidity", Topics: []string{"kubernetes-operator"}}, "k8s-operator"},
+		{"language", RuleInput{Language: "SOLIDITY"}, "solidity"},
+		{"name and keyword", RuleInput{Name: "awesome-go", Description: "A Curated List of Go tools"}, "awesome"},
+		{"name without keyword", RuleInput{Name: "awesome-go", Description: "Go tools"}, ""},
+		{"keyword without name", RuleInput{Name: "go-list", Description: "a curated list"}, ""},
+		{"org", RuleInput{Owner: "ACME-ML", Name: "anything"}, "acme-ml"},
+		{"nothing", RuleInput{Owner: "a", Name: "b", Language: "Go"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := rs.Match(tt.in)
+			if ok != (tt.want != "") || rule.ID != tt.want {
+				t.Errorf("Match = %q, %v; want %q", rule.ID, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRuleSet(t *testing.T) {
+	if rs, err := NewRuleSet(nil); rs != nil || err != nil || rs.Len() != 0 {
+		t.Errorf("NewRuleSet(nil) = %v, %v; want nil, nil", rs, err)
+	}
+	if _, ok := (*RuleSet)(nil).Match(RuleInput{Language: "Go"}); ok {
+		t.Error("nil RuleSet matched")
+	}
+	if _, err := NewRuleSet([]config.ClassificationRule{{ID: "bad", Languages: []string{"Go"}, Category: "ai", Subcategory: "kubernetes"}}); err == nil {
+		t.Error("NewRuleSet accepted a pair outside the taxonomy")
+	}
+	if _, err := NewRuleSet([]config.ClassificationRule{{ID: "bad", Name: "(", Category: "ai", Subcategory: "agents"}}); err == nil {
+		t.Error("NewRuleSet accepted an invalid name regexp")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/hrexed/github-radar/internal/audit"
+	"github.com/hrexed/github-radar/internal/daemon"
 	"github.com/hrexed/github-radar/internal/database"
 	"github.com/hrexed/github-radar/internal/github"
 	"github.com/hrexed/github-radar/internal/logging"
@@ -85,7 +86,7 @@ func (c *AuditCmd) runOtherDrift(args []string) int {
 	}
 	defer db.Close()
 
-	gh, err := github.NewClient(cfg.GitHub.Token)
+	gh, err := daemon.NewGitHubClient(cfg.GitHub)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
 		return 1
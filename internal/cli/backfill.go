@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/daemon"
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/discovery"
+)
+
+// BackfillCmd handles the backfill command: replaying a historical range
+// of gharchive hours into the activity history of tracked repos and the
+// gharchive discovery rollup.
+type BackfillCmd struct {
+	cli *CLI
+}
+
+// NewBackfillCmd creates a new backfill command handler.
+func NewBackfillCmd(cli *CLI) *BackfillCmd {
+	return &BackfillCmd{cli: cli}
+}
+
+// backfillDateLayouts are the accepted --from / --to formats: a whole
+// UTC day or a single gharchive hour.
+var backfillDateLayouts = []string{"2006-01-02-15", "2006-01-02"}
+
+// parseBackfillBound parses a --from / --to value. A bare date selects
+// its first hour, or its last hour when endOfDay is set, so
+// `--to 2026-03-01` includes the whole of March 1st.
+func parseBackfillBound(s string, endOfDay bool) (time.Time, error) {
+	for _, layout := range backfillDateLayouts {
+		t, err := time.ParseInLocation(layout, s, time.UTC)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" && endOfDay {
+			t = t.Add(23 * time.Hour)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD or YYYY-MM-DD-HH)", s)
+}
+
+// Run executes the backfill command.
+func (b *BackfillCmd) Run(args []string) int {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	var (
+		from     string
+		to       string
+		localDir string
+		baseURL  string
+		rate     time.Duration
+		restart  bool
+		offline  bool
+		workers  int
+	)
+
+	fs.StringVar(&from, "from", "", "First day or hour to process (YYYY-MM-DD or YYYY-MM-DD-HH, UTC)")
+	fs.StringVar(&to, "to", "", "Last day or hour to process, inclusive (default: last published hour)")
+	fs.StringVar(&localDir, "local-dir", "", "Directory of pre-downloaded <YYYY-MM-DD-HH>.json.gz archives, checked before downloading")
+	fs.StringVar(&baseURL, "base-url", "", "Archive origin (default: collector.gharchive.base_url, else https://data.gharchive.org)")
+	fs.DurationVar(&rate, "rate", discovery.DefaultGHArchiveBackfillInterval, "Minimum time between archive downloads (0 = no limit)")
+	fs.BoolVar(&restart, "restart", false, "Ignore the saved backfill cursor and start again at --from")
+	fs.BoolVar(&offline, "offline", false, "Never download: read archives from --local-dir and the archive cache only")
+	fs.IntVar(&workers, "concurrency", 0, "Archives downloaded and decoded at once (default: discovery.sources.gharchive.concurrency)")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if from == "" {
+		fmt.Fprintf(os.Stderr, "Error: --from is required\n")
+		return 1
+	}
+
+	start, err := parseBackfillBound(from, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --from: %v\n", err)
+		return 1
+	}
+	// The newest hour gharchive has published; later hours would only
+	// 404 and be reported as failures.
+	latest := time.Now().UTC().Add(-time.Hour - discovery.GHArchivePublishLag).Truncate(time.Hour)
+	end := latest
+	if to != "" {
+		if end, err = parseBackfillBound(to, true); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --to: %v\n", err)
+			return 1
+		}
+		if end.After(latest) {
+			end = latest
+		}
+	}
+	if end.Before(start) {
+		fmt.Fprintf(os.Stderr, "Error: --to is before --from\n")
+		return 1
+	}
+	if rate == 0 {
+		rate = -1 // Backfill treats zero as "use the default"
+	}
+	if workers < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --concurrency must be >= 0\n")
+		return 1
+	}
+
+	if err := b.cli.LoadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	cfg := b.cli.Config
+	if baseURL == "" {
+		baseURL = cfg.Collector.GHArchive.BaseURL
+	}
+
+	db, err := database.Open("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	tracked, err := trackedRepoNames(db, cfg.Repositories)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading tracked repos: %v\n", err)
+		return 1
+	}
+
+	ghaCfg := cfg.Discovery.Sources.GHArchive
+	window := time.Duration(ghaCfg.WindowHours) * time.Hour
+	if window <= 0 {
+		window = discovery.DefaultGHArchiveWindow
+	}
+	rollup, err := daemon.NewGHArchiveHistoryRollup(db, tracked, daemon.DiscoveryEventTypes(ghaCfg), end.Add(time.Hour-window))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cacheCfg := cfg.Collector.ArchiveCache
+	cacheCfg.Offline = cacheCfg.Offline || offline
+	cache, err := daemon.NewArchiveCache(cacheCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := cache.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save archive cache index: %v\n", err)
+		}
+	}()
+
+	if workers > 0 {
+		ghaCfg.Concurrency = workers
+	}
+	src := discovery.NewGHArchiveSource(
+		daemon.BackfillGHArchiveConfig(ghaCfg, baseURL, localDir),
+		discovery.NewMetadataCursorStoreWithKey(db, discovery.GHArchiveBackfillCursorMetadataKey),
+		rollup,
+		discovery.GHArchiveHooks{},
+	)
+	src.SetArchiveCache(cache)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	total := int(end.Sub(start)/time.Hour) + 1
+	done := 0
+	result, err := src.Backfill(ctx, discovery.GHArchiveBackfillOptions{
+		From:     start,
+		To:       end,
+		Interval: rate,
+		Restart:  restart,
+		OnArchive: func(archive string, aerr error) {
+			done++
+			if aerr != nil {
+				fmt.Fprintf(os.Stderr, "  %s: %v\n", archive, aerr)
+				return
+			}
+			if b.cli.Verbose || done%24 == 0 {
+				fmt.Printf("  %s done\n", archive)
+			}
+		},
+	})
+
+	fmt.Printf("Backfill %s .. %s: %d archives processed, %d failed (%d tracked repos)\n",
+		discovery.GHArchiveArchiveName(start), discovery.GHArchiveArchiveName(end),
+		result.Processed, len(result.Failed), len(tracked))
+	if !result.Start.IsZero() && result.Start.After(start) {
+		fmt.Printf("Resumed from saved cursor at %s (use --restart to start over)\n",
+			discovery.GHArchiveArchiveName(result.Start))
+	}
+	if result.Start.IsZero() && err == nil {
+		fmt.Printf("Nothing to do: range already backfilled (%d archives; use --restart to reprocess)\n", total)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Backfill interrupted: %v (re-run the same command to resume)\n", err)
+		return 1
+	}
+	return 0
+}
+
+// trackedRepoNames merges the repos in the database with the ones listed
+// in the config file.
+func trackedRepoNames(db *database.DB, configured []config.TrackedRepo) ([]string, error) {
+	records, err := db.AllRepos()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(records)+len(configured))
+	names := make([]string, 0, len(records)+len(configured))
+	for _, r := range records {
+		if !seen[r.FullName] {
+			seen[r.FullName] = true
+			names = append(names, r.FullName)
+		}
+	}
+	for _, r := range configured {
+		if r.Repo != "" && !seen[r.Repo] {
+			seen[r.Repo] = true
+			names = append(names, r.Repo)
+		}
+	}
+	return names, nil
+}
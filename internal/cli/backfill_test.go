@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBackfillBound(t *testing.T) {
+	cases := []struct {
+		in       string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"2026-01-01", false, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"2026-03-01", true, time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)},
+		{"2026-03-01-07", true, time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		got, err := parseBackfillBound(tc.in, tc.endOfDay)
+		if err != nil {
+			t.Fatalf("parseBackfillBound(%q): %v", tc.in, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("parseBackfillBound(%q, %v) = %v, want %v", tc.in, tc.endOfDay, got, tc.want)
+		}
+	}
+	if _, err := parseBackfillBound("01/02/2026", false); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestBackfill_RequiresFrom(t *testing.T) {
+	if code := NewBackfillCmd(New()).Run(nil); code != 1 {
+		t.Errorf("exit code = %d, want 1 without --from", code)
+	}
+}
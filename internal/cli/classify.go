@@ -2,16 +2,22 @@ package cli
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
 	"github.com/hrexed/github-radar/internal/classification"
 	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/daemon"
 	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/evaluation"
+	"github.com/hrexed/github-radar/internal/forge"
 	"github.com/hrexed/github-radar/internal/github"
 	"github.com/hrexed/github-radar/internal/logging"
+	"github.com/hrexed/github-radar/internal/repository"
 )
 
 // ClassifyCmd handles the classify command.
@@ -32,6 +38,10 @@ func (c *ClassifyCmd) Run(args []string) int {
 			return c.runTest(args[1:])
 		case "model":
 			return c.runModel(args[1:])
+		case "eval":
+			return c.runEval(args[1:])
+		case "rules":
+			return c.runRules(args[1:])
 		}
 	}
 
@@ -57,33 +67,60 @@ func (c *ClassifyCmd) runBatch(args []string) int {
 	defer db.Close()
 
 	// Create GitHub client
-	gh, err := github.NewClient(cfg.GitHub.Token)
+	gh, err := daemon.NewGitHubClient(cfg.GitHub)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
 		return 1
 	}
 
-	// Create Ollama client
+	// Create LLM client
 	clsCfg := cfg.Classification
-	ollama := classification.NewOllamaClient(
-		clsCfg.OllamaEndpoint,
-		clsCfg.Model,
-		clsCfg.TimeoutMs,
-		clsCfg.Categories,
-	)
+	llm, err := classification.NewClassifier(clsCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating classifier: %v\n", err)
+		return 1
+	}
+	provider := clsCfg.ResolvedProvider()
+	embedder, err := classification.NewEmbedder(clsCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating embedder: %v\n", err)
+		return 1
+	}
+	rules, err := classification.NewRuleSet(clsCfg.Rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in classification rules: %v\n", err)
+		return 1
+	}
 
 	// Dry-run mode: show repos that would be classified without calling LLM
 	if c.cli.DryRun {
 		return c.dryRun(db)
 	}
 
+	hostClients, err := daemon.NewGitHubHostClients(cfg.GitHub)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
+		return 1
+	}
+
 	// Create pipeline and run classification
-	pipeline := classification.NewPipeline(db, gh, ollama, clsCfg)
+	pipeline := classification.NewPipeline(db, gh, llm, clsCfg)
+	if embedder != nil {
+		pipeline.SetEmbedder(embedder)
+	}
+	pipeline.SetRules(rules)
+	for host, hc := range hostClients {
+		pipeline.SetHostClient(host, hc)
+	}
+	for name, fp := range daemon.NewForgeProviders(cfg) {
+		pipeline.SetForgeProvider(name, fp)
+	}
 	ctx := context.Background()
 
 	logging.Info("starting classification",
-		"model", clsCfg.Model,
-		"endpoint", clsCfg.OllamaEndpoint,
+		"provider", provider.Type,
+		"model", provider.Model,
+		"endpoint", provider.Endpoint,
 		"min_confidence", clsCfg.MinConfidence,
 	)
 
@@ -106,17 +143,16 @@ func (c *ClassifyCmd) runBatch(args []string) int {
 // Does NOT save results to the database.
 func (c *ClassifyCmd) runTest(args []string) int {
 	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, "Usage: github-radar classify test <owner/repo>\n")
+		fmt.Fprintf(os.Stderr, "Usage: github-radar classify test <owner/repo|provider:owner/repo>\n")
 		return 1
 	}
 
 	repoArg := args[0]
-	parts := strings.SplitN(repoArg, "/", 2)
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		fmt.Fprintf(os.Stderr, "Error: repository must be in owner/repo format\n")
+	target, ok := repository.ParseKey(repoArg)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: repository must be in owner/repo, host/owner/repo or provider:owner/repo format\n")
 		return 1
 	}
-	owner, repoName := parts[0], parts[1]
 
 	// Load config
 	if err := c.cli.LoadConfig(); err != nil {
@@ -127,31 +163,38 @@ func (c *ClassifyCmd) runTest(args []string) int {
 	cfg := c.cli.Config
 	clsCfg := cfg.Classification
 
-	// Create GitHub client
-	gh, err := github.NewClient(cfg.GitHub.Token)
+	owner, repoName := target.Owner, target.Name
+
+	// Create the client for the repo's forge or host
+	repoProvider, err := testProvider(cfg, target)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
 		return 1
 	}
 
-	// Create Ollama client
-	ollama := classification.NewOllamaClient(
-		clsCfg.OllamaEndpoint,
-		clsCfg.Model,
-		clsCfg.TimeoutMs,
-		clsCfg.Categories,
-	)
+	// Create LLM client
+	llm, err := classification.NewClassifier(clsCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating classifier: %v\n", err)
+		return 1
+	}
+	provider := clsCfg.ResolvedProvider()
 
 	ctx := context.Background()
 	start := time.Now()
 
 	fmt.Printf("=== Classification Test: %s ===\n\n", repoArg)
-	fmt.Printf("Model:    %s\n", clsCfg.Model)
-	fmt.Printf("Endpoint: %s\n\n", clsCfg.OllamaEndpoint)
+	fmt.Printf("Provider: %s\n", provider.Type)
+	fmt.Printf("Model:    %s\n", provider.Model)
+	fmt.Printf("Endpoint: %s\n", provider.Endpoint)
+	if members := clsCfg.EnsembleMembers(); len(members) > 0 {
+		fmt.Printf("Ensemble: %d members (%s)\n", len(members), llm.Model())
+	}
+	fmt.Println()
 
 	// Fetch README
 	fmt.Printf("Fetching README for %s/%s ...\n", owner, repoName)
-	readmeResp, err := gh.GetReadme(ctx, owner, repoName, "")
+	readmeResp, err := repoProvider.GetReadme(ctx, owner, repoName)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching README: %v\n", err)
 		return 1
@@ -196,15 +239,15 @@ func (c *ClassifyCmd) runTest(args []string) int {
 	fmt.Printf("--- System Prompt ---\n%s\n\n", systemPrompt)
 	fmt.Printf("--- User Prompt ---\n%s\n\n", userPrompt)
 
-	// Call Ollama
-	fmt.Printf("Calling Ollama (%s) ...\n", clsCfg.Model)
+	// Call the LLM
+	fmt.Printf("Calling %s (%s) ...\n", provider.Type, provider.Model)
 	llmStart := time.Now()
-	result, err := ollama.Classify(ctx, systemPrompt, userPrompt)
+	result, err := llm.Classify(ctx, systemPrompt, userPrompt)
 	llmDuration := time.Since(llmStart)
 	totalDuration := time.Since(start)
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error from Ollama: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error from %s: %v\n", provider.Type, err)
 		return 1
 	}
 
@@ -213,18 +256,184 @@ func (c *ClassifyCmd) runTest(args []string) int {
 	fmt.Printf("Category:   %s\n", result.Category)
 	fmt.Printf("Confidence: %.1f%%\n", result.Confidence*100)
 	fmt.Printf("Reasoning:  %s\n", result.Reasoning)
-	fmt.Printf("Model:      %s\n", clsCfg.Model)
+	fmt.Printf("Model:      %s\n", llm.Model())
 	fmt.Printf("LLM time:   %s\n", llmDuration.Round(time.Millisecond))
 	fmt.Printf("Total time: %s\n", totalDuration.Round(time.Millisecond))
 
+	if len(result.Votes) > 0 {
+		fmt.Printf("Agreement:  %.0f%%\n", result.Agreement*100)
+		fmt.Printf("\n--- Ensemble Votes ---\n")
+		for _, v := range result.Votes {
+			fmt.Printf("  %-24s %-24s %5.1f%%  %s\n", v.Model, v.Category, v.Confidence*100, v.Reasoning)
+		}
+	}
+
 	if result.Confidence < clsCfg.MinConfidence {
 		fmt.Printf("\n⚠ Confidence %.1f%% is below threshold %.1f%% (would be marked needs_review)\n",
 			result.Confidence*100, clsCfg.MinConfidence*100)
 	}
+	if len(result.Votes) > 0 && result.Agreement < clsCfg.Ensemble.MinAgreement {
+		fmt.Printf("\n⚠ Agreement %.0f%% is below threshold %.0f%% (would be marked needs_review)\n",
+			result.Agreement*100, clsCfg.Ensemble.MinAgreement*100)
+	}
 
 	return 0
 }
 
+// runEval scores the configured classifier against a golden dataset.
+// Nothing is read from or written to the database or the forge APIs.
+//
+// Flags:
+//
+//	--json FILE        Write the full report as JSON
+//	--markdown FILE    Write the report as Markdown
+//	--baseline FILE    Compare with a JSON report from an earlier run
+//	--examples N       Override classification.few_shot.examples
+//	--min-accuracy X   Exit 1 when accuracy is below X (0–1)
+func (c *ClassifyCmd) runEval(args []string) int {
+	fs := flag.NewFlagSet("classify eval", flag.ContinueOnError)
+	jsonPath := fs.String("json", "", "Write the full report as JSON")
+	markdownPath := fs.String("markdown", "", "Write the report as Markdown")
+	baselinePath := fs.String("baseline", "", "Compare with a JSON report from an earlier run")
+	examples := fs.Int("examples", -1, "Override classification.few_shot.examples")
+	minAccuracy := fs.Float64("min-accuracy", 0, "Exit 1 when accuracy is below this (0-1)")
+	if err := fs.Parse(reorderFlags(args)); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: github-radar classify eval <dataset.jsonl> [--json FILE] [--markdown FILE] [--baseline FILE] [--examples N] [--min-accuracy X]\n")
+		return 1
+	}
+	datasetPath := fs.Arg(0)
+
+	if err := c.cli.LoadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	clsCfg := c.cli.Config.Classification
+	if *examples >= 0 {
+		clsCfg.FewShot.Examples = *examples
+	}
+
+	cases, datasetHash, err := evaluation.LoadDataset(datasetPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading dataset: %v\n", err)
+		return 1
+	}
+	var baseline *evaluation.Report
+	if *baselinePath != "" {
+		if baseline, err = evaluation.LoadReport(*baselinePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading baseline: %v\n", err)
+			return 1
+		}
+	}
+
+	llm, err := classification.NewClassifier(clsCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating classifier: %v\n", err)
+		return 1
+	}
+	provider := clsCfg.ResolvedProvider().Type
+	if len(clsCfg.EnsembleMembers()) > 0 {
+		provider = "ensemble"
+	}
+
+	fmt.Printf("Evaluating %s (%s) on %d cases from %s ...\n", llm.Model(), provider, len(cases), datasetPath)
+	report, err := evaluation.Run(context.Background(), llm, clsCfg, cases)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error during evaluation: %v\n", err)
+		return 1
+	}
+	report.Provider = provider
+	report.Dataset = filepath.Base(datasetPath)
+	report.DatasetHash = datasetHash
+
+	fmt.Printf("\n=== Evaluation Summary ===\n")
+	fmt.Printf("Cases:             %d (%d failed)\n", report.Cases, report.Failed)
+	fmt.Printf("Accuracy:          %.1f%%\n", report.Accuracy*100)
+	fmt.Printf("Category accuracy: %.1f%%\n", report.CategoryAccuracy*100)
+	fmt.Printf("Macro-F1:          %.3f\n", report.MacroF1)
+	fmt.Printf("ECE:               %.3f\n", report.Calibration.ECE)
+	fmt.Printf("Latency p50/p95:   %.0f / %.0f ms\n", report.Latency.P50Ms, report.Latency.P95Ms)
+	fmt.Printf("Prompt hash:       %s\n", report.PromptHash)
+	if baseline != nil {
+		cmp := evaluation.Compare(baseline, report)
+		fmt.Printf("\nVs baseline (%s, prompt %s):\n", baseline.Model, baseline.PromptHash)
+		if !cmp.SameDataset {
+			fmt.Printf("  ⚠ different dataset; deltas are indicative only\n")
+		}
+		fmt.Printf("  Accuracy %+.1f pp, macro-F1 %+.3f, ECE %+.3f, p50 %+.0f ms\n",
+			cmp.Accuracy*100, cmp.MacroF1, cmp.ECE, cmp.P50Ms)
+		fmt.Printf("  %d now correct, %d now wrong\n", len(cmp.Fixed), len(cmp.Broken))
+	}
+
+	if *jsonPath != "" {
+		if err := writeReportFile(*jsonPath, report.WriteJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON report: %v\n", err)
+			return 1
+		}
+		fmt.Printf("\nJSON report:     %s\n", *jsonPath)
+	}
+	if *markdownPath != "" {
+		write := func(w io.Writer) error { return report.WriteMarkdown(w, baseline) }
+		if err := writeReportFile(*markdownPath, write); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing Markdown report: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Markdown report: %s\n", *markdownPath)
+	}
+
+	if report.Failed == report.Cases {
+		fmt.Fprintf(os.Stderr, "Error: every case failed; is the classifier reachable?\n")
+		return 1
+	}
+	if report.Accuracy < *minAccuracy {
+		fmt.Fprintf(os.Stderr, "Error: accuracy %.1f%% is below --min-accuracy %.1f%%\n", report.Accuracy*100, *minAccuracy*100)
+		return 1
+	}
+	return 0
+}
+
+// writeReportFile creates path and writes a report into it with write.
+func writeReportFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// testProvider returns the provider serving target: its forge, its
+// GitHub Enterprise host or github.com.
+func testProvider(cfg *config.Config, target repository.Repo) (forge.Provider, error) {
+	if target.Provider != "" {
+		if fp, ok := daemon.NewForgeProviders(cfg)[target.Provider]; ok {
+			return fp, nil
+		}
+		return nil, fmt.Errorf("forge %s is not configured under forges", target.Provider)
+	}
+	if target.Host == "" {
+		gh, err := daemon.NewGitHubClient(cfg.GitHub)
+		if err != nil {
+			return nil, err
+		}
+		return github.NewForgeProvider(gh), nil
+	}
+	hostCfg, found := cfg.GitHub.Host(target.Host)
+	if !found {
+		return nil, fmt.Errorf("host %s is not configured under github.hosts", target.Host)
+	}
+	gh, err := daemon.NewGitHubHostClient(hostCfg, cfg.GitHub.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	return github.NewForgeProvider(gh), nil
+}
+
 // runModel shows or sets the classification model.
 // No args: prints the current model from config.
 // With a model name: updates config, marks all classified repos as needs_reclassify.
@@ -236,14 +445,16 @@ func (c *ClassifyCmd) runModel(args []string) int {
 
 	cfg := c.cli.Config
 
+	provider := cfg.Classification.ResolvedProvider()
+
 	// No args: show current model
 	if len(args) == 0 {
-		fmt.Printf("Current classification model: %s\n", cfg.Classification.Model)
+		fmt.Printf("Current classification model: %s (%s)\n", provider.Model, provider.Type)
 		return 0
 	}
 
 	newModel := args[0]
-	oldModel := cfg.Classification.Model
+	oldModel := provider.Model
 
 	if newModel == oldModel {
 		fmt.Printf("Model is already set to %s\n", oldModel)
@@ -264,8 +475,13 @@ func (c *ClassifyCmd) runModel(args []string) int {
 		return 1
 	}
 
-	// Update model in config and save
-	cfg.Classification.Model = newModel
+	// Update model in config and save; a provider-level model wins over
+	// classification.model, so change whichever is in effect.
+	if cfg.Classification.Provider.Model != "" {
+		cfg.Classification.Provider.Model = newModel
+	} else {
+		cfg.Classification.Model = newModel
+	}
 	if err := config.SaveToPath(c.cli.ConfigPath, cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
 		return 1
@@ -276,6 +492,100 @@ func (c *ClassifyCmd) runModel(args []string) int {
 	return 0
 }
 
+// runRules handles "classify rules test": it matches classification.rules
+// against every tracked repo in the database, using the description and
+// topics stored at its last classification, and reports what the rules
+// would decide. Nothing is written.
+func (c *ClassifyCmd) runRules(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprintf(os.Stderr, "Usage: github-radar classify rules test [--changed]\n")
+		return 1
+	}
+	fs := flag.NewFlagSet("classify rules test", flag.ContinueOnError)
+	changedOnly := fs.Bool("changed", false, "Only list repos whose category a rule would change")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 1
+	}
+
+	if err := c.cli.LoadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	rules, err := classification.NewRuleSet(c.cli.Config.Classification.Rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in classification rules: %v\n", err)
+		return 1
+	}
+	if rules.Len() == 0 {
+		fmt.Println("No classification rules configured.")
+		return 0
+	}
+
+	db, err := database.Open("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	repos, err := db.AllRepos()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error querying repos: %v\n", err)
+		return 1
+	}
+	inputs, err := db.AllClassificationInputs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading classification inputs: %v\n", err)
+		return 1
+	}
+
+	perRule := make(map[string]int)
+	matched, changed, noInputs := 0, 0, 0
+	for _, repo := range repos {
+		key, _ := repository.ParseKey(repo.FullName)
+		in, ok := inputs[repo.FullName]
+		if !ok {
+			noInputs++
+		}
+		rule, ok := rules.Match(classification.RuleInput{
+			Owner: key.Owner, Name: key.Name, Language: repo.Language, Description: in.Description, Topics: in.Topics,
+		})
+		if !ok {
+			continue
+		}
+		matched++
+		perRule[rule.ID]++
+
+		current := "unclassified"
+		if repo.PrimaryCategory != "" || repo.ForceCategory != "" {
+			category, subcategory, _ := repo.ResolveTaxonomy()
+			current = category + "/" + subcategory
+		}
+		proposed := rule.Category + "/" + rule.Subcategory
+		note := "unchanged"
+		if current != proposed {
+			changed++
+			note = "was " + current
+		}
+		if repo.ForceCategory != "" {
+			note += ", pinned"
+		}
+		if *changedOnly && current == proposed {
+			continue
+		}
+		fmt.Printf("  %-40s %-20s %s (%s)\n", repo.FullName, rule.ID, proposed, note)
+	}
+
+	fmt.Printf("\n%d of %d repos match a rule, %d with a different category than now.\n", matched, len(repos), changed)
+	for _, r := range c.cli.Config.Classification.Rules {
+		fmt.Printf("  %-20s %d\n", r.ID, perRule[r.ID])
+	}
+	if noInputs > 0 {
+		fmt.Printf("%d repos have no stored description or topics yet; topic and keyword conditions cannot match them until they are classified.\n", noInputs)
+	}
+	return 0
+}
+
 // dryRun shows repos that would be classified without calling the LLM.
 func (c *ClassifyCmd) dryRun(db *database.DB) int {
 	repos, err := db.ReposNeedingClassification()
@@ -311,6 +621,12 @@ func (c *ClassifyCmd) printSummary(s *classification.Summary) {
 	fmt.Fprintf(os.Stderr, "\n--- Classification Summary ---\n")
 	fmt.Fprintf(os.Stderr, "Total:        %d\n", s.Total)
 	fmt.Fprintf(os.Stderr, "Classified:   %d\n", s.Classified)
+	if s.Ruled > 0 {
+		fmt.Fprintf(os.Stderr, "  by rule:      %d (LLM not called)\n", s.Ruled)
+	}
+	if s.Embedded > 0 {
+		fmt.Fprintf(os.Stderr, "  by embedding: %d (LLM not called)\n", s.Embedded)
+	}
 	fmt.Fprintf(os.Stderr, "Needs review: %d\n", s.NeedsReview)
 	fmt.Fprintf(os.Stderr, "Skipped:      %d\n", s.Skipped)
 	fmt.Fprintf(os.Stderr, "Failed:       %d\n", s.Failed)
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/evaluation"
+)
+
+func TestClassifyEval_LocalStub(t *testing.T) {
+	// A local Ollama stand-in that answers "kubernetes" for everything.
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":{"content":"{\"category\":\"kubernetes\",\"confidence\":0.9,\"reasoning\":\"stub\"}"}}`))
+	}))
+	defer ollama.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := "classification:\n  ollama_endpoint: " + ollama.URL + "\n  model: stub-model\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	datasetPath := filepath.Join(dir, "golden.jsonl")
+	dataset := `{"repo":"a/op","readme":"A Kubernetes operator","category":"cloud-native","subcategory":"kubernetes"}
+{"repo":"a/agent","readme":"An agent framework","category":"ai","subcategory":"agents"}
+`
+	if err := os.WriteFile(datasetPath, []byte(dataset), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "report.json")
+	markdownPath := filepath.Join(dir, "report.md")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = New().Run([]string{"--config", configPath, "classify", "eval", datasetPath, "--json", jsonPath, "--markdown", markdownPath})
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	if !strings.Contains(out, "Accuracy:          50.0%") {
+		t.Errorf("summary lacks accuracy:\n%s", out)
+	}
+
+	report, err := evaluation.LoadReport(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadReport: %v", err)
+	}
+	if report.Model != "stub-model" || report.Provider != "ollama" || report.Dataset != "golden.jsonl" || report.Cases != 2 {
+		t.Errorf("report = %+v", report)
+	}
+	md, _ := os.ReadFile(markdownPath)
+	if !strings.Contains(string(md), "| ai/agents | cloud-native/kubernetes | 1 |") {
+		t.Errorf("Markdown report:\n%s", md)
+	}
+
+	// The same run against itself as a baseline, gated on accuracy.
+	out = captureStdout(t, func() {
+		code = New().Run([]string{"--config", configPath, "classify", "eval", datasetPath, "--baseline", jsonPath, "--min-accuracy", "0.9"})
+	})
+	if code != 1 {
+		t.Errorf("--min-accuracy 0.9 at 50%%: exit code = %d, want 1", code)
+	}
+	if !strings.Contains(out, "Accuracy +0.0 pp") {
+		t.Errorf("baseline comparison missing:\n%s", out)
+	}
+	var raw map[string]any
+	data, _ := os.ReadFile(jsonPath)
+	if err := json.Unmarshal(data, &raw); err != nil || raw["confusion"] == nil || raw["calibration"] == nil {
+		t.Errorf("JSON report lacks confusion/calibration: %v", err)
+	}
+}
+
+func TestClassifyRulesTest(t *testing.T) {
+	dbPath := withTempDefaultDB(t)
+	db, err := database.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	repos := []*database.RepoRecord{
+		{FullName: "a/op", Owner: "a", Name: "op", Status: "active", PrimaryCategory: "kubernetes"},
+		{FullName: "b/token", Owner: "b", Name: "token", Status: "active", Language: "Solidity", PrimaryCategory: "developer-tools"},
+		{FullName: "c/app", Owner: "c", Name: "app", Status: "pending", Language: "Go"},
+	}
+	for _, r := range repos {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+	if err := db.SetClassificationInputs("a/op", "", []string{"Kubernetes-Operator"}); err != nil {
+		t.Fatalf("SetClassificationInputs: %v", err)
+	}
+	db.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := `classification:
+  rules:
+    - id: k8s-operator
+      topics: [kubernetes-operator]
+      category: cloud-native
+      subcategory: kubernetes
+    - id: solidity
+      languages: [solidity]
+      category: crypto
+      subcategory: blockchain-web3
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = New().Run([]string{"--config", configPath, "classify", "rules", "test"})
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	for _, want := range []string{
+		"cloud-native/kubernetes (unchanged)",
+		"crypto/blockchain-web3 (was devtools/general)",
+		"2 of 3 repos match a rule, 1 with a different category than now.",
+		"2 repos have no stored description or topics",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		code = New().Run([]string{"--config", configPath, "classify", "rules", "test", "--changed"})
+	})
+	if code != 0 || strings.Contains(out, "a/op") || !strings.Contains(out, "b/token") {
+		t.Errorf("--changed (exit %d) listed:\n%s", code, out)
+	}
+}
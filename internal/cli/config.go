@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/hrexed/github-radar/internal/config"
 )
@@ -68,8 +69,38 @@ func (c *ConfigCmd) Show() int {
 
 	fmt.Printf("Configuration loaded from: %s\n\n", path)
 	fmt.Printf("GitHub:\n")
+	if app := cfg.GitHub.App; app.Enabled() {
+		fmt.Printf("  App ID: %d\n", app.AppID)
+		fmt.Printf("  Installation ID: %s\n", valueOrDefault(installationLabel(app.InstallationID), "(only installation)"))
+		fmt.Printf("  Private Key File: %s\n", app.PrivateKeyFile)
+	}
 	fmt.Printf("  Token: %s\n", maskSecret(cfg.GitHub.Token))
+	if n := len(cfg.GitHub.AllTokens()); n > 1 {
+		fmt.Printf("  Token Pool: %d tokens\n", n)
+	}
 	fmt.Printf("  Rate Limit: %d\n", cfg.GitHub.RateLimit)
+	if cfg.GitHub.Webhooks.Enabled {
+		fmt.Printf("  Webhook Secret: %s\n", maskSecret(cfg.GitHub.Webhooks.Secret))
+	}
+	for _, h := range cfg.GitHub.Hosts {
+		fmt.Printf("  Host %s:\n", h.Name)
+		fmt.Printf("    API URL: %s\n", h.APIURL)
+		fmt.Printf("    GraphQL URL: %s\n", h.ResolvedGraphQLURL())
+		if h.App.Enabled() {
+			fmt.Printf("    App ID: %d\n", h.App.AppID)
+		} else {
+			fmt.Printf("    Token: %s\n", maskSecret(h.Token))
+		}
+		fmt.Printf("    Rate Limit: %d\n", h.Auth(cfg.GitHub.RateLimit).RateLimit)
+	}
+	if len(cfg.Forges) > 0 {
+		fmt.Printf("\nForges:\n")
+		for _, f := range cfg.Forges {
+			fmt.Printf("  %s (%s):\n", f.Name, f.Type)
+			fmt.Printf("    API URL: %s\n", valueOrDefault(f.APIURL, "(default)"))
+			fmt.Printf("    Token: %s\n", maskSecret(f.Token))
+		}
+	}
 	fmt.Printf("\nOTel:\n")
 	fmt.Printf("  Endpoint: %s\n", cfg.Otel.Endpoint)
 	fmt.Printf("  Service Name: %s\n", cfg.Otel.ServiceName)
@@ -78,6 +109,39 @@ func (c *ConfigCmd) Show() int {
 	fmt.Printf("  Min Stars: %d\n", cfg.Discovery.MinStars)
 	fmt.Printf("  Max Age Days: %d\n", cfg.Discovery.MaxAgeDays)
 	fmt.Printf("  Auto Track Threshold: %.1f\n", cfg.Discovery.AutoTrackThreshold)
+	if cfg.Classification.Enabled() {
+		provider := cfg.Classification.ResolvedProvider()
+		fmt.Printf("\nClassification:\n")
+		fmt.Printf("  Provider: %s\n", provider.Type)
+		fmt.Printf("  Endpoint: %s\n", provider.Endpoint)
+		fmt.Printf("  Model: %s\n", provider.Model)
+		if provider.Type != config.ClassifierOllama {
+			fmt.Printf("  API Key: %s\n", maskSecret(provider.APIKey))
+		}
+		if members := cfg.Classification.EnsembleMembers(); len(members) > 0 {
+			fmt.Printf("  Ensemble: %d members, min agreement %.0f%%\n", len(members), cfg.Classification.Ensemble.MinAgreement*100)
+			for _, m := range members {
+				temp := "default"
+				if m.Temperature != nil {
+					temp = fmt.Sprintf("%.2f", *m.Temperature)
+				}
+				fmt.Printf("    - %s %s (temperature %s)\n", m.Type, m.Model, temp)
+			}
+		}
+		if fs := cfg.Classification.FewShot; fs.Examples > 0 {
+			fmt.Printf("  Few-shot: %d examples, up to %.0f%% of max_readme_chars\n", fs.Examples, fs.MaxShare*100)
+		}
+		if rules := cfg.Classification.Rules; len(rules) > 0 {
+			fmt.Printf("  Rules: %d\n", len(rules))
+			for _, r := range rules {
+				fmt.Printf("    - %s -> %s/%s\n", r.ID, r.Category, r.Subcategory)
+			}
+		}
+		if e := cfg.Classification.ResolvedEmbedding(); e.Model != "" {
+			fmt.Printf("  Embedding: %s %s (min margin %.2f, min %d examples per category)\n",
+				e.Type, e.Model, cfg.Classification.Embedding.MinMargin, cfg.Classification.Embedding.MinExamples)
+		}
+	}
 	fmt.Printf("\nScoring Weights:\n")
 	fmt.Printf("  Star Velocity: %.2f\n", cfg.Scoring.Weights.StarVelocity)
 	fmt.Printf("  Star Acceleration: %.2f\n", cfg.Scoring.Weights.StarAcceleration)
@@ -112,6 +176,14 @@ func maskSecret(s string) string {
 	return s[:4] + "****" + s[len(s)-4:]
 }
 
+// installationLabel renders a GitHub App installation ID, empty for 0.
+func installationLabel(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
 // valueOrDefault returns the value or a default if empty.
 func valueOrDefault(value, def string) string {
 	if value == "" {
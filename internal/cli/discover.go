@@ -9,9 +9,10 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/hrexed/github-radar/internal/daemon"
 	"github.com/hrexed/github-radar/internal/discovery"
-	"github.com/hrexed/github-radar/internal/github"
 	"github.com/hrexed/github-radar/internal/logging"
+	"github.com/hrexed/github-radar/internal/repository"
 	"github.com/hrexed/github-radar/internal/state"
 )
 
@@ -103,7 +104,7 @@ func (d *DiscoverCmd) Run(args []string) int {
 	}
 
 	// Create GitHub client
-	client, err := github.NewClient(cfg.GitHub.Token)
+	client, err := daemon.NewGitHubClient(cfg.GitHub)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
 		return 1
@@ -119,15 +120,17 @@ func (d *DiscoverCmd) Run(args []string) int {
 
 	// Load tracked repos from config into state (for AlreadyTracked detection)
 	for _, tracked := range cfg.Repositories {
-		parts := strings.SplitN(tracked.Repo, "/", 2)
-		if len(parts) != 2 {
+		r, ok := repository.ParseKey(tracked.Repo)
+		if !ok {
 			logging.Warn("invalid repo format, skipping", "repo", tracked.Repo)
 			continue
 		}
-		if store.GetRepoState(tracked.Repo) == nil {
-			store.SetRepoState(tracked.Repo, state.RepoState{
-				Owner: parts[0],
-				Name:  parts[1],
+		if store.GetRepoState(r.FullName()) == nil {
+			store.SetRepoState(r.FullName(), state.RepoState{
+				Provider: r.Provider,
+				Host:     r.Host,
+				Owner:    r.Owner,
+				Name:     r.Name,
 			})
 		}
 	}
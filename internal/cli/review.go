@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hrexed/github-radar/internal/classification"
+	"github.com/hrexed/github-radar/internal/daemon"
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/review"
+)
+
+// ReviewCmd walks the classification review queue: repos left in
+// needs_review by a low confidence or a split ensemble vote.
+type ReviewCmd struct {
+	cli *CLI
+	in  io.Reader
+}
+
+// NewReviewCmd creates a new review command handler reading answers
+// from stdin.
+func NewReviewCmd(cli *CLI) *ReviewCmd {
+	return &ReviewCmd{cli: cli, in: os.Stdin}
+}
+
+// Run executes the review command.
+//
+// Flags:
+//
+//	--limit N          Review at most N repos (default 20, 0 = all)
+//	--reviewer NAME    Name recorded with each decision (default $USER)
+//
+// With the global --dry-run the queue is listed without prompting.
+func (c *ReviewCmd) Run(args []string) int {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "Review at most N repos (0 = all)")
+	reviewer := fs.String("reviewer", os.Getenv("USER"), "Name recorded with each decision")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if *reviewer == "" && !c.cli.DryRun {
+		fmt.Fprintln(os.Stderr, "Error: --reviewer is required when $USER is unset")
+		return 1
+	}
+
+	db, err := database.Open("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	queue := review.NewQueue(db, c.describer(db))
+	items, err := queue.Pending(*limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading review queue: %v\n", err)
+		return 1
+	}
+	if len(items) == 0 {
+		fmt.Println("Review queue is empty.")
+		return 0
+	}
+
+	if c.cli.DryRun {
+		fmt.Printf("%d repos awaiting review:\n", len(items))
+		for _, it := range items {
+			fmt.Printf("  %-40s %s/%s (%.0f%%)\n", it.FullName, it.Category, it.Subcategory, it.Confidence*100)
+		}
+		return 0
+	}
+
+	ctx := context.Background()
+	answers := bufio.NewScanner(c.in)
+	var accepted, overridden, skipped int
+	for i, queued := range items {
+		item, err := queue.Item(ctx, queued.FullName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", queued.FullName, err)
+			continue
+		}
+		fmt.Printf("\n[%d/%d] ", i+1, len(items))
+		printReviewItem(item)
+
+		decision, quit := c.decide(answers, queue, item, *reviewer)
+		if quit {
+			break
+		}
+		switch {
+		case decision == nil:
+			skipped++
+		case decision.Action == database.ReviewAccept:
+			accepted++
+		default:
+			overridden++
+		}
+		if decision != nil {
+			fmt.Printf("  → %s/%s (%s by %s at %s)\n",
+				decision.Category, decision.Subcategory, decision.Action, decision.Reviewer, decision.ReviewedAt)
+		}
+	}
+
+	fmt.Printf("\nReviewed: %d accepted, %d overridden, %d skipped\n", accepted, overridden, skipped)
+	return 0
+}
+
+// describer returns the pipeline used to fetch descriptions and READMEs,
+// or nil when no config or GitHub client is available; the queue is
+// still reviewable without them.
+func (c *ReviewCmd) describer(db *database.DB) review.Describer {
+	if err := c.cli.LoadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; descriptions and READMEs unavailable\n", err)
+		return nil
+	}
+	cfg := c.cli.Config
+	gh, err := daemon.NewGitHubClient(cfg.GitHub)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: creating GitHub client: %v; descriptions and READMEs unavailable\n", err)
+		return nil
+	}
+	pipeline := classification.NewPipeline(db, gh, nil, cfg.Classification)
+	if hostClients, err := daemon.NewGitHubHostClients(cfg.GitHub); err == nil {
+		for host, hc := range hostClients {
+			pipeline.SetHostClient(host, hc)
+		}
+	}
+	for name, fp := range daemon.NewForgeProviders(cfg) {
+		pipeline.SetForgeProvider(name, fp)
+	}
+	return pipeline
+}
+
+// decide prompts for one item until it is accepted, overridden or
+// skipped. It returns the recorded decision (nil when skipped) and
+// whether the reviewer quit.
+func (c *ReviewCmd) decide(answers *bufio.Scanner, queue *review.Queue, item *review.Item, reviewer string) (*review.Decision, bool) {
+	for {
+		answer, ok := prompt(answers, "[a]ccept, [o]verride, [s]kip, [q]uit? ")
+		if !ok {
+			return nil, true
+		}
+		switch answer {
+		case "a", "accept":
+			d, err := queue.Accept(item.FullName, reviewer)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+				if errors.Is(err, database.ErrPairNotAllowed) {
+					fmt.Fprintln(os.Stderr, "  The classifier's answer is not an allowed pair; override it instead.")
+				}
+				continue
+			}
+			return d, false
+		case "o", "override":
+			category, subcategory, ok := promptPair(answers)
+			if !ok {
+				continue
+			}
+			d, err := queue.Override(item.FullName, category, subcategory, reviewer)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+				continue
+			}
+			return d, false
+		case "s", "skip", "":
+			return nil, false
+		case "q", "quit":
+			return nil, true
+		}
+	}
+}
+
+// promptPair asks for a category/subcategory pair allowed by
+// database.IsAllowedPair. "?" lists the allowed pairs; an empty answer
+// goes back to the action prompt.
+func promptPair(answers *bufio.Scanner) (category, subcategory string, ok bool) {
+	for {
+		answer, ok := prompt(answers, "  category/subcategory (? lists them, empty to go back): ")
+		if !ok || answer == "" {
+			return "", "", false
+		}
+		if answer == "?" {
+			printAllowedPairs()
+			continue
+		}
+		category, subcategory, _ = strings.Cut(answer, "/")
+		if database.IsAllowedPair(category, subcategory) {
+			return category, subcategory, true
+		}
+		fmt.Fprintf(os.Stderr, "  %q is not an allowed pair\n", answer)
+	}
+}
+
+// prompt prints question and reads one trimmed, lowercased answer;
+// ok is false at end of input.
+func prompt(answers *bufio.Scanner, question string) (string, bool) {
+	fmt.Print(question)
+	if !answers.Scan() {
+		fmt.Println()
+		return "", false
+	}
+	return strings.ToLower(strings.TrimSpace(answers.Text())), true
+}
+
+// printReviewItem renders an item for the reviewer.
+func printReviewItem(item *review.Item) {
+	fmt.Printf("%s\n", item.FullName)
+	if item.Description != "" {
+		fmt.Printf("  Description: %s\n", item.Description)
+	}
+	fmt.Printf("  Classifier:  %s/%s (%.0f%% confidence, %s)\n", item.Category, item.Subcategory, item.Confidence*100, item.Model)
+	if item.Reasoning != "" {
+		fmt.Printf("  Reasoning:   %s\n", item.Reasoning)
+	}
+	if len(item.Votes) > 0 {
+		fmt.Printf("  Agreement:   %.0f%%\n", item.Agreement*100)
+		for _, v := range item.Votes {
+			fmt.Printf("    %-24s %-24s %5.1f%%  %s\n", v.Model, v.Category, v.Confidence*100, v.Reasoning)
+		}
+	}
+	if item.Readme != "" {
+		fmt.Println("  README excerpt:")
+		for _, line := range strings.Split(strings.TrimSpace(item.Readme), "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
+
+// printAllowedPairs lists every allowed category/subcategory pair.
+func printAllowedPairs() {
+	categories := make([]string, 0, len(database.TaxonomyV2))
+	for c := range database.TaxonomyV2 {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	for _, c := range categories {
+		var pairs []string
+		for _, s := range database.TaxonomyV2[c] {
+			if database.IsAllowedPair(c, s) {
+				pairs = append(pairs, c+"/"+s)
+			}
+		}
+		fmt.Printf("    %s\n", strings.Join(pairs, "  "))
+	}
+}
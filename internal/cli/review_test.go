@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+func seedReviewQueue(t *testing.T) {
+	t.Helper()
+	db, err := database.Open("")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	for _, r := range []*database.RepoRecord{
+		{FullName: "acme/agent", Owner: "acme", Name: "agent", Status: "needs_review", PrimaryCategory: "ai-agents", CategoryConfidence: 0.3, ModelUsed: "m"},
+		{FullName: "acme/mesh", Owner: "acme", Name: "mesh", Status: "needs_review", PrimaryCategory: "kubernetes", CategoryConfidence: 0.5, ModelUsed: "m"},
+	} {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+}
+
+func TestReviewCmd_Interactive(t *testing.T) {
+	withTempDefaultDB(t)
+	seedReviewQueue(t)
+
+	c := &CLI{ConfigPath: filepath.Join(t.TempDir(), "missing.yaml")}
+	cmd := NewReviewCmd(c)
+	// Override the least confident repo (acme/agent) after one rejected
+	// pair, then accept acme/mesh.
+	cmd.in = strings.NewReader("o\nai/bogus\nai/llm-tooling\na\n")
+
+	var code int
+	out := captureStdout(t, func() { code = cmd.Run([]string{"--reviewer", "alex"}) })
+	if code != 0 {
+		t.Fatalf("exit code = %d, output:\n%s", code, out)
+	}
+	if !strings.Contains(out, "Reviewed: 1 accepted, 1 overridden, 0 skipped") {
+		t.Errorf("missing summary in output:\n%s", out)
+	}
+
+	db, err := database.Open("")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	agent, _ := db.GetRepo("acme/agent")
+	if agent.ForceCategory != "ai" || agent.ForceSubcategory != "llm-tooling" || agent.Status != "active" {
+		t.Errorf("acme/agent = %+v, want forced to ai/llm-tooling", agent)
+	}
+	mesh, _ := db.GetRepo("acme/mesh")
+	if mesh.ForceCategory != "cloud-native" || mesh.ForceSubcategory != "kubernetes" {
+		t.Errorf("acme/mesh = %+v, want the accepted classifier pair", mesh)
+	}
+	history, _ := db.Reviews("acme/mesh")
+	if len(history) != 1 || history[0].Reviewer != "alex" || history[0].Action != database.ReviewAccept {
+		t.Errorf("acme/mesh history = %+v", history)
+	}
+}
+
+func TestReviewCmd_DryRunListsQueue(t *testing.T) {
+	withTempDefaultDB(t)
+	seedReviewQueue(t)
+
+	c := &CLI{DryRun: true, ConfigPath: filepath.Join(t.TempDir(), "missing.yaml")}
+	cmd := NewReviewCmd(c)
+	cmd.in = strings.NewReader("")
+
+	out := captureStdout(t, func() { cmd.Run(nil) })
+	if !strings.Contains(out, "2 repos awaiting review") || !strings.Contains(out, "acme/agent") {
+		t.Errorf("dry-run output:\n%s", out)
+	}
+
+	db, _ := database.Open("")
+	defer db.Close()
+	if items, _ := db.ReviewQueue(0); len(items) != 2 {
+		t.Errorf("dry run changed the queue: %d left", len(items))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/hrexed/github-radar/internal/cassette"
 	"github.com/hrexed/github-radar/internal/config"
 	"github.com/hrexed/github-radar/internal/logging"
 )
@@ -15,6 +16,8 @@ type CLI struct {
 	ConfigPath string
 	Verbose    bool
 	DryRun     bool
+	Record     string // cassette directory to record HTTP traffic to
+	Replay     string // cassette directory to replay HTTP traffic from
 	Config     *config.Config
 	args       []string // remaining args after flag parsing
 }
@@ -30,6 +33,8 @@ func (c *CLI) ParseFlags(args []string) error {
 	fs.StringVar(&c.ConfigPath, "config", "", "Path to configuration file")
 	fs.BoolVar(&c.Verbose, "verbose", false, "Enable verbose output")
 	fs.BoolVar(&c.DryRun, "dry-run", false, "Simulate without exporting metrics")
+	fs.StringVar(&c.Record, "record", "", "Record outbound HTTP traffic to a cassette directory")
+	fs.StringVar(&c.Replay, "replay", "", "Replay outbound HTTP traffic from a cassette directory")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -80,8 +85,31 @@ func (c *CLI) Run(args []string) int {
 	return 0
 }
 
-// extractGlobalFlags removes global flags (--verbose, --dry-run, --config)
-// from subcommand args and applies them to the CLI struct.
+// useCassette installs the cassette selected by --record or --replay
+// for every HTTP client created afterwards.
+func (c *CLI) useCassette() error {
+	if c.Record != "" && c.Replay != "" {
+		return fmt.Errorf("--record and --replay are mutually exclusive")
+	}
+	dir, mode := c.Record, cassette.Record
+	if c.Replay != "" {
+		dir, mode = c.Replay, cassette.Replay
+	}
+	if dir == "" {
+		cassette.Use(nil)
+		return nil
+	}
+	cas, err := cassette.Open(dir, mode)
+	if err != nil {
+		return err
+	}
+	cassette.Use(cas)
+	logging.Info("http cassette in use", "mode", mode.String(), "dir", dir)
+	return nil
+}
+
+// extractGlobalFlags removes global flags (--verbose, --dry-run, --config,
+// --record, --replay) from subcommand args and applies them to the CLI struct.
 // This allows users to place global flags after the subcommand name.
 func (c *CLI) extractGlobalFlags(args []string) []string {
 	var filtered []string
@@ -97,6 +125,16 @@ func (c *CLI) extractGlobalFlags(args []string) []string {
 				c.ConfigPath = args[i+1]
 				i++ // skip value
 			}
+		case "--record":
+			if i+1 < len(args) {
+				c.Record = args[i+1]
+				i++
+			}
+		case "--replay":
+			if i+1 < len(args) {
+				c.Replay = args[i+1]
+				i++
+			}
 		default:
 			filtered = append(filtered, args[i])
 		}
@@ -107,6 +145,10 @@ func (c *CLI) extractGlobalFlags(args []string) []string {
 // runCommand executes a subcommand.
 func (c *CLI) runCommand(cmd string, args []string) int {
 	args = c.extractGlobalFlags(args)
+	if err := c.useCassette(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
 	switch cmd {
 	case "config":
 		return c.runConfigCommand(args)
@@ -131,6 +173,9 @@ func (c *CLI) runCommand(cmd string, args []string) int {
 	case "classify":
 		classifyCmd := NewClassifyCmd(c)
 		return classifyCmd.Run(args)
+	case "review":
+		reviewCmd := NewReviewCmd(c)
+		return reviewCmd.Run(args)
 	case "status":
 		statusCmd := NewStatusCmd(c)
 		return statusCmd.Run(args)
@@ -140,6 +185,9 @@ func (c *CLI) runCommand(cmd string, args []string) int {
 	case "audit":
 		auditCmd := NewAuditCmd(c)
 		return auditCmd.Run(args)
+	case "backfill":
+		backfillCmd := NewBackfillCmd(c)
+		return backfillCmd.Run(args)
 	case "help":
 		c.printHelp()
 		return 0
@@ -187,16 +235,25 @@ Commands:
   discover           Discover trending repositories by topic
                      Options: --topics, --min-stars, --max-age, --threshold,
                               --auto-track, --format <table|json|csv>
-  classify           Classify pending repositories using Ollama LLM
+  classify           Classify pending repositories using an LLM
                      Options: --dry-run (show repos without calling LLM)
   classify test <repo>  Test classification for a single repo (verbose, no DB save)
+  classify eval <file>  Score the classifier against a golden dataset (JSON Lines)
+  classify rules test   Dry-run classification.rules against the database
+                     Options: --changed (only repos a rule would move)
   classify model     Show the current classification model
   classify model <name> Set classification model and queue all repos for reclassification
+  review             Accept or override classifications in needs_review
+                     Options: --limit <n>, --reviewer <name>
+                     (--dry-run lists the queue without prompting)
   serve              Start the daemon for scheduled scanning
                      Options: --interval <duration>, --http-addr <addr>,
                               --state <path>
   status             Show daemon status
                      Options: --addr <url>, --format <text|json>
+  backfill           Replay historical gharchive hours into activity history
+                     Options: --from <date>, --to <date>, --local-dir <dir>,
+                              --base-url <url>, --rate <duration>, --restart
   admin <action>     Operator interventions on the scanner DB
                      Actions:
                        drain-needs-reclassify [--dry-run] [--limit N]
@@ -207,5 +264,7 @@ Commands:
 Flags:
   --config <path>    Path to configuration file
   --verbose          Enable verbose output
-  --dry-run          Simulate without exporting metrics`)
+  --dry-run          Simulate without exporting metrics
+  --record <dir>     Record outbound HTTP traffic to a cassette directory
+  --replay <dir>     Serve outbound HTTP traffic from a recorded cassette`)
 }
@@ -42,6 +42,14 @@ func TestParseFlags_DryRun(t *testing.T) {
 	}
 }
 
+func TestRunCommand_RecordAndReplayExclusive(t *testing.T) {
+	c := New()
+	dir := t.TempDir()
+	if code := c.runCommand("help", []string{"--record", dir, "--replay", dir}); code != 1 {
+		t.Errorf("runCommand() = %d, want 1 when both --record and --replay are given", code)
+	}
+}
+
 func TestParseFlags_AllFlags(t *testing.T) {
 	c := New()
 	err := c.ParseFlags([]string{
@@ -3,6 +3,9 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
+	"unicode"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,11 +20,60 @@ type Config struct {
 	Collector      CollectorConfig      `yaml:"collector"`
 	Exclusions     []string             `yaml:"exclusions"`
 	Repositories   []TrackedRepo        `yaml:"repositories"`
+
+	// Forges adds GitLab and Gitea/Forgejo instances next to GitHub.
+	// Their repos are tracked as name:owner/repo.
+	Forges []ForgeConfig `yaml:"forges"`
+}
+
+// Forge types accepted by ForgeConfig.Type.
+const (
+	ForgeTypeGitLab = "gitlab"
+	ForgeTypeGitea  = "gitea"
+)
+
+// ForgeConfig is one non-GitHub forge.
+type ForgeConfig struct {
+	// Name qualifies the forge's repos (`codeberg:owner/repo`):
+	// lowercase letters, digits and dashes.
+	Name string `yaml:"name"`
+
+	// Type is gitlab, or gitea for Gitea and Forgejo.
+	Type string `yaml:"type"`
+
+	// APIURL is the REST base, e.g. https://codeberg.org/api/v1. Empty
+	// uses https://gitlab.com/api/v4 for gitlab; gitea requires it.
+	APIURL string `yaml:"api_url"`
+
+	// Token is an access token; empty for anonymous access.
+	Token string `yaml:"token"`
+}
+
+// Forge returns the configured forge called name.
+func (c *Config) Forge(name string) (ForgeConfig, bool) {
+	for _, f := range c.Forges {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return ForgeConfig{}, false
 }
 
 type CollectorConfig struct {
-	GHArchive            GHArchiveConfig `yaml:"gharchive"`
-	FallbackThresholdPct float64         `yaml:"fallback_threshold_pct"`
+	GHArchive            GHArchiveConfig    `yaml:"gharchive"`
+	FallbackThresholdPct float64            `yaml:"fallback_threshold_pct"`
+	ArchiveCache         ArchiveCacheConfig `yaml:"archive_cache"`
+}
+
+// ArchiveCacheConfig configures the on-disk cache of hourly gharchive
+// files shared by the metrics fallback collector, gharchive discovery
+// and `github-radar backfill`. An empty Dir disables the cache unless
+// Offline reads from SeedDirs only.
+type ArchiveCacheConfig struct {
+	Dir       string   `yaml:"dir"`
+	MaxSizeMB int      `yaml:"max_size_mb"`
+	Offline   bool     `yaml:"offline"`
+	SeedDirs  []string `yaml:"seed_dirs"`
 }
 
 type GHArchiveConfig struct {
@@ -60,8 +112,42 @@ func (t *TrackedRepo) UnmarshalYAML(value *yaml.Node) error {
 
 // GithubConfig contains GitHub API settings.
 type GithubConfig struct {
-	Token     string `yaml:"token"`
-	RateLimit int    `yaml:"rate_limit"`
+	Token string `yaml:"token"`
+
+	// Tokens adds more personal access tokens to the client's pool.
+	// Requests go to the token with the most remaining budget. Entries
+	// may hold several comma- or whitespace-separated tokens, so a
+	// single `${GITHUB_TOKENS}` reference can supply the whole pool.
+	// Token, when set, is the pool's first member.
+	Tokens []string `yaml:"tokens"`
+
+	// App authenticates as a GitHub App installation instead of with
+	// personal access tokens. When App.AppID is set, Token and Tokens
+	// are ignored.
+	App GithubAppConfig `yaml:"app"`
+
+	RateLimit int `yaml:"rate_limit"`
+
+	// Hosts adds GitHub Enterprise Server instances next to github.com.
+	// Repos on one are tracked as `host/owner/repo`, where host is the
+	// entry's Name.
+	Hosts []GithubHostConfig `yaml:"hosts"`
+
+	// HTTPCache stores REST responses on disk and revalidates them
+	// with conditional requests, which GitHub does not charge for.
+	HTTPCache HTTPCacheConfig `yaml:"http_cache"`
+
+	// Governor paces requests per rate-limit resource and pauses every
+	// request after a secondary rate limit response.
+	Governor GovernorConfig `yaml:"governor"`
+
+	// Planner estimates each daemon cycle's API cost up front and
+	// defers low-priority work that the remaining budget cannot cover.
+	Planner PlannerConfig `yaml:"planner"`
+
+	// Webhooks receives GitHub webhook deliveries on the daemon's
+	// /webhooks/github endpoint.
+	Webhooks WebhookConfig `yaml:"webhooks"`
 
 	// BulkFetchEnabled turns on GraphQL bulk metadata fetch and tiered
 	// refresh cadence (T5 / ISI-716). When false, behaviour is the
@@ -83,6 +169,180 @@ type GithubConfig struct {
 	Tiering TieringConfig `yaml:"tiering"`
 }
 
+// GovernorConfig configures the request governor. Zero limits use the
+// github package defaults.
+type GovernorConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	Core    GovernorResourceConfig `yaml:"core"`
+	Search  GovernorResourceConfig `yaml:"search"`
+	GraphQL GovernorResourceConfig `yaml:"graphql"`
+
+	// CooldownSec is the pause after a secondary rate limit response
+	// without Retry-After. 0 = 60.
+	CooldownSec int `yaml:"cooldown_sec"`
+}
+
+// PlannerConfig configures the per-cycle API budget planner.
+type PlannerConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// ReservePct is the share of each budget's limit the planner keeps
+	// back for work it does not plan, such as CLI runs. 0 = 10.
+	ReservePct int `yaml:"reserve_pct"`
+}
+
+// WebhookConfig configures the webhook receiver.
+type WebhookConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Secret is the webhook secret deliveries are signed with.
+	Secret string `yaml:"secret"`
+
+	// PromoteHours is how long a repo with webhook activity stays in
+	// the hot refresh tier. 0 = 6.
+	PromoteHours int `yaml:"promote_hours"`
+}
+
+// GovernorResourceConfig bounds one rate-limit resource. 0 keeps the
+// default for that field.
+type GovernorResourceConfig struct {
+	PerMinute   int `yaml:"per_minute"`
+	Burst       int `yaml:"burst"`
+	MaxInFlight int `yaml:"max_in_flight"`
+}
+
+// GithubHostConfig is one GitHub Enterprise Server instance. It carries
+// its own endpoints, credentials and rate limit.
+type GithubHostConfig struct {
+	// Name qualifies the host's repos (`ghe.corp/owner/repo`). Usually
+	// the server's hostname.
+	Name string `yaml:"name"`
+
+	// APIURL is the REST base, e.g. https://ghe.corp/api/v3.
+	APIURL string `yaml:"api_url"`
+
+	// GraphQLURL is the GraphQL endpoint. Empty derives it from APIURL:
+	// .../api/v3 becomes .../api/graphql.
+	GraphQLURL string `yaml:"graphql_url"`
+
+	Token  string          `yaml:"token"`
+	Tokens []string        `yaml:"tokens"`
+	App    GithubAppConfig `yaml:"app"`
+
+	// RateLimit is the backoff threshold for this host; 0 inherits
+	// github.rate_limit.
+	RateLimit int `yaml:"rate_limit"`
+}
+
+// Auth returns the host's credentials and rate limit as a GithubConfig,
+// falling back to defaultRateLimit when the host sets none.
+func (h GithubHostConfig) Auth(defaultRateLimit int) GithubConfig {
+	rl := h.RateLimit
+	if rl == 0 {
+		rl = defaultRateLimit
+	}
+	return GithubConfig{Token: h.Token, Tokens: h.Tokens, App: h.App, RateLimit: rl}
+}
+
+// ResolvedGraphQLURL returns GraphQLURL, or the endpoint GitHub
+// Enterprise Server serves next to APIURL.
+func (h GithubHostConfig) ResolvedGraphQLURL() string {
+	if h.GraphQLURL != "" {
+		return h.GraphQLURL
+	}
+	base := strings.TrimSuffix(h.APIURL, "/")
+	if trimmed, ok := strings.CutSuffix(base, "/api/v3"); ok {
+		return trimmed + "/api/graphql"
+	}
+	return base + "/graphql"
+}
+
+// Host returns the configured host called name (case-insensitive).
+func (g GithubConfig) Host(name string) (GithubHostConfig, bool) {
+	for _, h := range g.Hosts {
+		if strings.EqualFold(h.Name, name) {
+			return h, true
+		}
+	}
+	return GithubHostConfig{}, false
+}
+
+// HTTPCacheEndpoints are the endpoint names HTTPCacheConfig.TTLs
+// accepts. They match the github package's cache endpoint labels.
+var HTTPCacheEndpoints = []string{"repo", "pulls", "issues", "contributors", "releases", "readme", "search", "other"}
+
+// HTTPCacheConfig configures the on-disk cache of GitHub REST
+// responses. An empty Dir disables it.
+type HTTPCacheConfig struct {
+	Dir string `yaml:"dir"`
+
+	// MaxSizeMB caps the cache; least recently used responses are
+	// evicted past it. 0 = 256.
+	MaxSizeMB int `yaml:"max_size_mb"`
+
+	// MaxEntryKB is the largest response body stored. 0 = 1024.
+	MaxEntryKB int `yaml:"max_entry_kb"`
+
+	// TTLs maps an endpoint (HTTPCacheEndpoints) to a duration such as
+	// "30m" during which a stored response is reused without asking
+	// GitHub. Endpoints not listed are revalidated on every request.
+	TTLs map[string]string `yaml:"ttls"`
+}
+
+// Enabled reports whether the HTTP cache is configured.
+func (h HTTPCacheConfig) Enabled() bool {
+	return h.Dir != ""
+}
+
+// TTLDurations parses TTLs.
+func (h HTTPCacheConfig) TTLDurations() (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration, len(h.TTLs))
+	for endpoint, raw := range h.TTLs {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("ttl for %s: %w", endpoint, err)
+		}
+		ttls[endpoint] = d
+	}
+	return ttls, nil
+}
+
+// GithubAppConfig identifies a GitHub App installation.
+type GithubAppConfig struct {
+	AppID int64 `yaml:"app_id"`
+
+	// InstallationID selects the installation; 0 uses the App's only
+	// installation.
+	InstallationID int64 `yaml:"installation_id"`
+
+	// PrivateKeyFile is the path to the App's PEM private key.
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+
+// Enabled reports whether GitHub App authentication is configured.
+func (a GithubAppConfig) Enabled() bool {
+	return a.AppID != 0
+}
+
+// AllTokens returns Token followed by every entry of Tokens, split on
+// commas and whitespace, with blanks and duplicates removed.
+func (g GithubConfig) AllTokens() []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, entry := range append([]string{g.Token}, g.Tokens...) {
+		for _, t := range strings.FieldsFunc(entry, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		}) {
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
 // TieringConfig parameterises the refresh tier classifier.
 // All fields default to DefaultTierConfig when zero.
 type TieringConfig struct {
@@ -113,6 +373,12 @@ type DiscoveryConfig struct {
 	MaxAgeDays         int      `yaml:"max_age_days"`         // Maximum repo age in days (0 = no limit)
 	AutoTrackThreshold float64  `yaml:"auto_track_threshold"` // Growth score threshold for auto-tracking
 
+	// IncludeForks and IncludeMirrors admit forks and mirrors as
+	// discovery candidates. Off by default: discovery otherwise
+	// surfaces copies of popular projects instead of the projects.
+	IncludeForks   bool `yaml:"include_forks"`
+	IncludeMirrors bool `yaml:"include_mirrors"`
+
 	// Sources configures discovery sources beyond the default topic
 	// search. Each sub-source is feature-flagged and disabled by
 	// default; rollout is staged via config alone (no rebuild needed).
@@ -170,15 +436,26 @@ type DiscoveryGHArchiveConfig struct {
 	// TopNPerHour is the per-hour candidate cap surfaced to the
 	// classifier. Default 500. Bound: >= 1.
 	TopNPerHour int `yaml:"top_n_per_hour"`
-	// ActivityFloor is the minimum total event count over the window
-	// for a repo to be eligible as a candidate. Default 10.
+	// ActivityFloor is the minimum RankBy value over the window for
+	// a repo to be eligible as a candidate. Default 10.
 	// Bound: >= 0 (0 disables the floor — every tracked repo
 	// competes for top-N).
 	ActivityFloor int `yaml:"activity_floor"`
+	// RankBy selects what top-N ranking and ActivityFloor measure:
+	// "events" (raw event count), "stargazers" (distinct WatchEvent
+	// actors) or "contributors" (distinct push / pull-request
+	// actors). Distinct-actor counts are approximate (HyperLogLog).
+	// Default "events".
+	RankBy string `yaml:"rank_by"`
 	// EventTypes overrides the default GitHub event-type filter.
 	// Empty falls back to the canonical
 	// [WatchEvent, ForkEvent, PushEvent, PullRequestEvent] set.
 	EventTypes []string `yaml:"event_types"`
+	// BotPatterns lists case-insensitive substrings of actor logins
+	// whose events are ignored entirely, so automation neither
+	// inflates event counts nor distinct-actor counts. Empty falls
+	// back to [[bot], dependabot, renovate].
+	BotPatterns []string `yaml:"bot_patterns"`
 	// MinStarsGate is an optional hard floor on stargazer count for
 	// gharchive-discovered candidates. Default 0 — disabled, lets
 	// event volume be the sole signal initially per ISI-950 Q3.
@@ -205,6 +482,24 @@ type DiscoveryGHArchiveConfig struct {
 	// for the rest of the day to protect classifier capacity.
 	// Default 5000. Bound: > DailyCapWarn.
 	DailyCapHard int `yaml:"daily_cap_hard"`
+	// ReleaseSignals promotes repos that publish an initial version
+	// (0.0.1 / 0.1.0), a 1.0 graduation or a major version bump as
+	// candidates that bypass ActivityFloor, with the release tag
+	// recorded as provenance. Tracked repos get a "major release" event instead.
+	// Default true.
+	ReleaseSignals bool `yaml:"release_signals"`
+	// MaxReleaseCandidates caps the release-only candidates (release
+	// signals on repos outside the top-N list) admitted per cycle,
+	// oldest first; the rest wait for the next cycle. Default 50.
+	// Bound: >= 0 (0 = use default).
+	MaxReleaseCandidates int `yaml:"max_release_candidates"`
+	// Concurrency is how many hourly archives are downloaded and
+	// decoded at once when the collector (or `backfill`) has several
+	// hours to catch up on. Hours are still applied in order and the
+	// cursor only advances over contiguous completed hours. Drops to 1
+	// while the backpressure gate is paused. Default 4.
+	// Bound: >= 0 (0 = use default).
+	Concurrency int `yaml:"concurrency"`
 }
 
 // ScoringConfig contains growth scoring settings.
@@ -233,6 +528,210 @@ type ClassificationConfig struct {
 	Categories     []string `yaml:"categories"`       // Allowed classification categories
 	SystemPrompt   string   `yaml:"system_prompt"`    // Go template for system prompt
 	UserPrompt     string   `yaml:"user_prompt"`      // Go template for user prompt
+
+	// Secondary labels: further categories a repo belongs to, kept next
+	// to the primary one. MaxSecondaryLabels 0 disables them.
+	MaxSecondaryLabels     int     `yaml:"max_secondary_labels"`     // Secondary labels kept per repo
+	MinSecondaryConfidence float64 `yaml:"min_secondary_confidence"` // Confidence a secondary label needs
+
+	// Provider selects the LLM API. Empty means Ollama at OllamaEndpoint.
+	Provider ClassifierProviderConfig `yaml:"provider"`
+
+	// Ensemble, when it has members, replaces the single provider call
+	// with a confidence-weighted vote across several classifiers.
+	Ensemble EnsembleConfig `yaml:"ensemble"`
+
+	// Review configures the needs_review queue on the daemon's /review
+	// endpoints.
+	Review ReviewConfig `yaml:"review"`
+
+	// FewShot adds human-labeled repos similar to the one being
+	// classified to the user prompt as {{.Examples}}.
+	FewShot FewShotConfig `yaml:"few_shot"`
+
+	// Embedding, when it names a model, assigns repos to the nearest
+	// category centroid and only calls the LLM for close calls.
+	Embedding EmbeddingConfig `yaml:"embedding"`
+
+	// Rules are tried in order before any model; the first match
+	// decides the repo's category.
+	Rules []ClassificationRule `yaml:"rules,omitempty"`
+}
+
+// ClassificationRule maps matching repos straight to a v3 (category,
+// subcategory) pair. Every condition that is set must match; a list
+// matches when any entry does. Comparisons ignore case.
+type ClassificationRule struct {
+	ID          string   `yaml:"id"`                  // Recorded as model_used "rule:<id>"
+	Topics      []string `yaml:"topics,omitempty"`    // GitHub topics
+	Languages   []string `yaml:"languages,omitempty"` // Primary language
+	Name        string   `yaml:"name,omitempty"`      // Regexp matched against the repo name, without owner
+	Orgs        []string `yaml:"orgs,omitempty"`      // Owner or namespace
+	Keywords    []string `yaml:"keywords,omitempty"`  // Substrings of the description
+	Category    string   `yaml:"category"`
+	Subcategory string   `yaml:"subcategory"`
+}
+
+// EmbeddingConfig configures the embedding pre-classifier. Type,
+// endpoint, API key and timeout default to classification.provider's
+// when the type matches; the model never does.
+type EmbeddingConfig struct {
+	Type        string  `yaml:"type"`         // ollama or openai
+	Endpoint    string  `yaml:"endpoint"`     // Embeddings API base URL
+	APIKey      string  `yaml:"api_key"`      // Bearer token for openai
+	Model       string  `yaml:"model"`        // Embedding model; empty disables the stage
+	TimeoutMs   int     `yaml:"timeout_ms"`   // Request timeout in milliseconds
+	MinMargin   float64 `yaml:"min_margin"`   // Top-2 centroid similarity gap needed to skip the LLM
+	MinExamples int     `yaml:"min_examples"` // Labeled repos a category needs for a centroid
+}
+
+// FewShotConfig configures few-shot examples drawn from repos with a
+// pinned category (force_category or a review decision).
+type FewShotConfig struct {
+	Examples int     `yaml:"examples"`  // Examples per prompt (0, the default, disables)
+	MaxShare float64 `yaml:"max_share"` // Share of max_readme_chars the examples may use
+}
+
+// ReviewConfig configures the daemon's review API.
+type ReviewConfig struct {
+	// APIToken authorizes POST /review/decision as a bearer token.
+	// Empty leaves the queue read-only over HTTP.
+	APIToken string `yaml:"api_token"`
+}
+
+// Classifier provider types.
+const (
+	ClassifierOllama    = "ollama"
+	ClassifierOpenAI    = "openai"
+	ClassifierAnthropic = "anthropic"
+)
+
+// Default classifier settings.
+const (
+	DefaultOpenAIEndpoint    = "https://api.openai.com/v1"
+	DefaultAnthropicEndpoint = "https://api.anthropic.com"
+	DefaultClassifierTokens  = 1024
+)
+
+// ClassifierProviderConfig configures the LLM API classification calls.
+type ClassifierProviderConfig struct {
+	Type      string `yaml:"type"`       // ollama (default), openai (chat completions) or anthropic (messages)
+	Endpoint  string `yaml:"endpoint"`   // API base URL; defaults per type
+	APIKey    string `yaml:"api_key"`    // Bearer token (openai) or x-api-key (anthropic)
+	Model     string `yaml:"model"`      // Overrides classification.model
+	TimeoutMs int    `yaml:"timeout_ms"` // Overrides classification.timeout_ms
+	MaxTokens int    `yaml:"max_tokens"` // Response token cap (default: 1024)
+
+	// Temperature sets the sampling temperature. Unset leaves the
+	// provider default: 0 for openai and anthropic, the model's own
+	// setting for ollama.
+	Temperature *float64 `yaml:"temperature"`
+}
+
+// EnsembleConfig configures ensemble classification. Each member is a
+// classifier whose unset fields inherit from classification.provider, so
+// listing the same model at several temperatures samples it repeatedly.
+type EnsembleConfig struct {
+	Members      []ClassifierProviderConfig `yaml:"members"`       // Classifiers that vote
+	MinAgreement float64                    `yaml:"min_agreement"` // Share of members that must agree; below → needs_review
+}
+
+// ResolvedProvider returns Provider with defaults applied: Ollama at
+// OllamaEndpoint, and Model and TimeoutMs from the classification
+// section unless the provider overrides them.
+func (c ClassificationConfig) ResolvedProvider() ClassifierProviderConfig {
+	p := c.Provider
+	if p.Type == "" {
+		p.Type = ClassifierOllama
+	}
+	if p.Endpoint == "" {
+		switch p.Type {
+		case ClassifierOllama:
+			p.Endpoint = c.OllamaEndpoint
+		case ClassifierOpenAI:
+			p.Endpoint = DefaultOpenAIEndpoint
+		case ClassifierAnthropic:
+			p.Endpoint = DefaultAnthropicEndpoint
+		}
+	}
+	if p.Model == "" {
+		p.Model = c.Model
+	}
+	if p.TimeoutMs == 0 {
+		p.TimeoutMs = c.TimeoutMs
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = DefaultClassifierTokens
+	}
+	return p
+}
+
+// EnsembleMembers returns the ensemble members with defaults applied.
+// A member of the same type as the resolved provider (or with no type)
+// inherits its endpoint and API key; every member inherits its model,
+// timeout and temperature unless it overrides them.
+func (c ClassificationConfig) EnsembleMembers() []ClassifierProviderConfig {
+	if len(c.Ensemble.Members) == 0 {
+		return nil
+	}
+	base := c.ResolvedProvider()
+	members := make([]ClassifierProviderConfig, 0, len(c.Ensemble.Members))
+	for _, m := range c.Ensemble.Members {
+		if m.Type == "" || m.Type == base.Type {
+			m.Type = base.Type
+			if m.Endpoint == "" {
+				m.Endpoint = base.Endpoint
+			}
+			if m.APIKey == "" {
+				m.APIKey = base.APIKey
+			}
+		}
+		if m.Model == "" {
+			m.Model = base.Model
+		}
+		if m.TimeoutMs == 0 {
+			m.TimeoutMs = base.TimeoutMs
+		}
+		if m.Temperature == nil {
+			m.Temperature = base.Temperature
+		}
+		member := c
+		member.Provider = m
+		members = append(members, member.ResolvedProvider())
+	}
+	return members
+}
+
+// ResolvedEmbedding returns the embedding provider with defaults
+// applied, or the zero value when no embedding model is configured.
+func (c ClassificationConfig) ResolvedEmbedding() ClassifierProviderConfig {
+	e := c.Embedding
+	if e.Model == "" {
+		return ClassifierProviderConfig{}
+	}
+	p := ClassifierProviderConfig{Type: e.Type, Endpoint: e.Endpoint, APIKey: e.APIKey, Model: e.Model, TimeoutMs: e.TimeoutMs}
+	base := c.ResolvedProvider()
+	if p.Type == "" || p.Type == base.Type {
+		p.Type = base.Type
+		if p.Endpoint == "" {
+			p.Endpoint = base.Endpoint
+		}
+		if p.APIKey == "" {
+			p.APIKey = base.APIKey
+		}
+	}
+	if p.TimeoutMs == 0 {
+		p.TimeoutMs = base.TimeoutMs
+	}
+	stage := c
+	stage.Provider = p
+	return stage.ResolvedProvider()
+}
+
+// Enabled reports whether an LLM endpoint and model are configured.
+func (c ClassificationConfig) Enabled() bool {
+	p := c.ResolvedProvider()
+	return p.Endpoint != "" && p.Model != ""
 }
 
 // ConfigError wraps config-related errors with context and hints.
@@ -275,6 +774,8 @@ func DefaultConfig() *Config {
 	return &Config{
 		GitHub: GithubConfig{
 			RateLimit: 4000,
+			Governor:  GovernorConfig{Enabled: true},
+			Planner:   PlannerConfig{Enabled: true},
 		},
 		Otel: OtelConfig{
 			Endpoint:    "",
@@ -292,16 +793,25 @@ func DefaultConfig() *Config {
 					WindowHours:   24,
 					TopNPerHour:   500,
 					ActivityFloor: 10,
+					RankBy:        "events",
 					EventTypes: []string{
 						"WatchEvent",
 						"ForkEvent",
 						"PushEvent",
 						"PullRequestEvent",
 					},
+					BotPatterns: []string{
+						"[bot]",
+						"dependabot",
+						"renovate",
+					},
 					MinStarsGate:          0,
 					MinStarsCacheTTLHours: 168, // 7 days; matches DefaultGHArchiveMinStarsCacheTTL
 					DailyCapWarn:          4000,
 					DailyCapHard:          5000,
+					ReleaseSignals:        true,
+					MaxReleaseCandidates:  50,
+					Concurrency:           4,
 				},
 			},
 		},
@@ -322,6 +832,12 @@ func DefaultConfig() *Config {
 			TimeoutMs:      30000,
 			MaxReadmeChars: 2000,
 			MinConfidence:  0.6,
+
+			MaxSecondaryLabels:     2,
+			MinSecondaryConfidence: 0.5,
+			Ensemble:               EnsembleConfig{MinAgreement: 0.6},
+			FewShot:                FewShotConfig{MaxShare: 0.25},
+			Embedding:              EmbeddingConfig{MinMargin: 0.05, MinExamples: 3},
 			Categories: []string{
 				// AI & ML
 				"ai-agents",
@@ -382,10 +898,15 @@ func DefaultConfig() *Config {
 				"other",
 			},
 			SystemPrompt: `You are a GitHub repository classifier for trending open-source projects across all technology domains.
-Classify into exactly ONE category from: {{.Categories}}
+Classify into exactly ONE primary category from: {{.Categories}}
 Pick the most specific category that fits. Use "other" only if no category applies.
-Respond ONLY with JSON: {"category": "<name>", "confidence": <0.0-1.0>, "reasoning": "<one sentence>"}`,
-			UserPrompt: `Repository: {{.RepoName}}
+If the repository also clearly belongs to other categories from the list, list them under "secondary", most relevant first; otherwise leave it empty.
+Give each secondary label the subcategory that fits it, from: {{.Subcategories}}; use "" when none fits.
+Respond ONLY with JSON: {"category": "<name>", "confidence": <0.0-1.0>, "reasoning": "<one sentence>", "secondary": [{"category": "<name>", "subcategory": "<name>", "confidence": <0.0-1.0>}]}`,
+			UserPrompt: `{{if .Examples}}Labeled examples of similar repositories:
+
+{{.Examples}}Now classify:
+{{end}}Repository: {{.RepoName}}
 Description: {{.Description}}
 Language: {{.Language}}
 Topics: {{.Topics}}
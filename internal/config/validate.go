@@ -3,9 +3,16 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
+// forgeNamePattern matches a forge name usable as a repo key prefix.
+var forgeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
 // ValidationError contains a list of configuration validation issues.
 type ValidationError struct {
 	Issues []string
@@ -32,8 +39,10 @@ func (c *Config) Validate() error {
 	var issues []string
 
 	// Required fields
-	if c.GitHub.Token == "" {
-		issues = append(issues, "github.token: required field is empty")
+	if app := c.GitHub.App; app.Enabled() {
+		issues = append(issues, appIssues("github.app", app)...)
+	} else if len(c.GitHub.AllTokens()) == 0 {
+		issues = append(issues, "github.token: required field is empty (or set github.tokens or github.app)")
 	}
 
 	if c.Otel.Endpoint == "" {
@@ -55,6 +64,150 @@ func (c *Config) Validate() error {
 		issues = append(issues, fmt.Sprintf("github.rate_limit: must be greater than 0, got %d", c.GitHub.RateLimit))
 	}
 
+	// github.hosts: GitHub Enterprise Server instances. Each needs a
+	// unique name, a REST base URL and its own credentials.
+	hostNames := make(map[string]bool, len(c.GitHub.Hosts))
+	for i, h := range c.GitHub.Hosts {
+		prefix := fmt.Sprintf("github.hosts[%d]", i)
+		name := strings.ToLower(h.Name)
+		switch {
+		case h.Name == "":
+			issues = append(issues, prefix+".name: required field is empty")
+		case strings.ContainsAny(h.Name, "/ "):
+			issues = append(issues, fmt.Sprintf("%s.name: must be a bare hostname, got %q", prefix, h.Name))
+		case name == "github.com":
+			issues = append(issues, prefix+".name: github.com is configured under github itself")
+		case hostNames[name]:
+			issues = append(issues, fmt.Sprintf("%s.name: duplicate host %q", prefix, h.Name))
+		}
+		hostNames[name] = true
+
+		if h.APIURL == "" {
+			issues = append(issues, prefix+".api_url: required field is empty")
+		}
+		for _, f := range []struct{ field, raw string }{{"api_url", h.APIURL}, {"graphql_url", h.GraphQLURL}} {
+			if f.raw == "" {
+				continue
+			}
+			if u, err := url.Parse(f.raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				issues = append(issues, fmt.Sprintf("%s.%s: must be an http(s) URL, got %q", prefix, f.field, f.raw))
+			}
+		}
+
+		if h.App.Enabled() {
+			issues = append(issues, appIssues(prefix+".app", h.App)...)
+		} else if len(h.Auth(0).AllTokens()) == 0 {
+			issues = append(issues, prefix+".token: required field is empty (or set tokens or app)")
+		}
+		if h.RateLimit < 0 {
+			issues = append(issues, fmt.Sprintf("%s.rate_limit: must be >= 0 (0 = github.rate_limit), got %d", prefix, h.RateLimit))
+		}
+	}
+
+	hc := c.GitHub.HTTPCache
+	if hc.MaxSizeMB < 0 {
+		issues = append(issues, fmt.Sprintf("github.http_cache.max_size_mb: must be >= 0 (0 = use default 256), got %d", hc.MaxSizeMB))
+	}
+	if hc.MaxEntryKB < 0 {
+		issues = append(issues, fmt.Sprintf("github.http_cache.max_entry_kb: must be >= 0 (0 = use default 1024), got %d", hc.MaxEntryKB))
+	}
+	endpoints := make([]string, 0, len(hc.TTLs))
+	for endpoint := range hc.TTLs {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		if !slices.Contains(HTTPCacheEndpoints, endpoint) {
+			issues = append(issues, fmt.Sprintf("github.http_cache.ttls.%s: unknown endpoint (valid: %s)", endpoint, strings.Join(HTTPCacheEndpoints, ", ")))
+			continue
+		}
+		if d, err := time.ParseDuration(hc.TTLs[endpoint]); err != nil || d < 0 {
+			issues = append(issues, fmt.Sprintf("github.http_cache.ttls.%s: must be a non-negative duration such as \"30m\", got %q", endpoint, hc.TTLs[endpoint]))
+		}
+	}
+
+	gov := c.GitHub.Governor
+	for _, r := range []struct {
+		name string
+		cfg  GovernorResourceConfig
+	}{{"core", gov.Core}, {"search", gov.Search}, {"graphql", gov.GraphQL}} {
+		prefix := "github.governor." + r.name
+		if r.cfg.PerMinute < 0 {
+			issues = append(issues, fmt.Sprintf("%s.per_minute: must be >= 0 (0 = use default), got %d", prefix, r.cfg.PerMinute))
+		}
+		if r.cfg.Burst < 0 {
+			issues = append(issues, fmt.Sprintf("%s.burst: must be >= 0 (0 = use default), got %d", prefix, r.cfg.Burst))
+		}
+		if r.cfg.MaxInFlight < 0 {
+			issues = append(issues, fmt.Sprintf("%s.max_in_flight: must be >= 0 (0 = use default), got %d", prefix, r.cfg.MaxInFlight))
+		}
+	}
+	if gov.CooldownSec < 0 {
+		issues = append(issues, fmt.Sprintf("github.governor.cooldown_sec: must be >= 0 (0 = use default 60), got %d", gov.CooldownSec))
+	}
+
+	if p := c.GitHub.Planner.ReservePct; p < 0 || p > 90 {
+		issues = append(issues, fmt.Sprintf("github.planner.reserve_pct: must be between 0 and 90 (0 = use default 10), got %d", p))
+	}
+
+	if wh := c.GitHub.Webhooks; wh.Enabled && wh.Secret == "" {
+		issues = append(issues, "github.webhooks.secret: required when webhooks are enabled")
+	}
+	if h := c.GitHub.Webhooks.PromoteHours; h < 0 {
+		issues = append(issues, fmt.Sprintf("github.webhooks.promote_hours: must be >= 0 (0 = use default 6), got %d", h))
+	}
+
+	// forges: GitLab and Gitea/Forgejo instances, each with a unique
+	// name usable as a repo key prefix.
+	forgeNames := make(map[string]bool, len(c.Forges))
+	for i, f := range c.Forges {
+		prefix := fmt.Sprintf("forges[%d]", i)
+		switch {
+		case f.Name == "":
+			issues = append(issues, prefix+".name: required field is empty")
+		case !forgeNamePattern.MatchString(f.Name):
+			issues = append(issues, fmt.Sprintf("%s.name: must be lowercase letters, digits and dashes, got %q", prefix, f.Name))
+		case f.Name == "github":
+			issues = append(issues, prefix+".name: github is configured under github itself")
+		case forgeNames[f.Name]:
+			issues = append(issues, fmt.Sprintf("%s.name: duplicate forge %q", prefix, f.Name))
+		}
+		forgeNames[f.Name] = true
+
+		switch f.Type {
+		case ForgeTypeGitLab:
+		case ForgeTypeGitea:
+			if f.APIURL == "" {
+				issues = append(issues, prefix+".api_url: required for gitea forges")
+			}
+		default:
+			issues = append(issues, fmt.Sprintf("%s.type: must be %q or %q, got %q", prefix, ForgeTypeGitLab, ForgeTypeGitea, f.Type))
+		}
+		if f.APIURL != "" {
+			if u, err := url.Parse(f.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				issues = append(issues, fmt.Sprintf("%s.api_url: must be an http(s) URL, got %q", prefix, f.APIURL))
+			}
+		}
+	}
+
+	// Host-qualified repositories must name a configured host, and
+	// provider-qualified ones a configured forge.
+	for i, r := range c.Repositories {
+		if provider, _, ok := strings.Cut(r.Repo, ":"); ok && !strings.Contains(provider, "/") {
+			if provider != "github" && !forgeNames[provider] {
+				issues = append(issues, fmt.Sprintf("repositories[%d]: forge %q is not configured under forges", i, provider))
+			}
+			continue
+		}
+		parts := strings.Split(r.Repo, "/")
+		if len(parts) != 3 || strings.EqualFold(parts[0], "github.com") {
+			continue
+		}
+		if !hostNames[strings.ToLower(parts[0])] {
+			issues = append(issues, fmt.Sprintf("repositories[%d]: host %q is not configured under github.hosts", i, parts[0]))
+		}
+	}
+
 	if c.Discovery.MinStars < 0 {
 		issues = append(issues, fmt.Sprintf("discovery.min_stars: must be >= 0, got %d", c.Discovery.MinStars))
 	}
@@ -87,6 +240,11 @@ func (c *Config) Validate() error {
 	if ga.ActivityFloor < 0 {
 		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive.activity_floor: must be >= 0, got %d", ga.ActivityFloor))
 	}
+	switch ga.RankBy {
+	case "", "events", "stargazers", "contributors":
+	default:
+		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive.rank_by: must be one of events, stargazers, contributors, got %q", ga.RankBy))
+	}
 	if ga.MinStarsGate < 0 {
 		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive.min_stars_gate: must be >= 0, got %d", ga.MinStarsGate))
 	}
@@ -96,6 +254,12 @@ func (c *Config) Validate() error {
 	if ga.DailyCapWarn < 0 {
 		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive.daily_cap_warn: must be >= 0 (0 = use default 4000), got %d", ga.DailyCapWarn))
 	}
+	if ga.MaxReleaseCandidates < 0 {
+		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive.max_release_candidates: must be >= 0 (0 = use default 50), got %d", ga.MaxReleaseCandidates))
+	}
+	if ga.Concurrency < 0 {
+		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive.concurrency: must be >= 0 (0 = use default 4), got %d", ga.Concurrency))
+	}
 	if ga.DailyCapHard < 0 {
 		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive.daily_cap_hard: must be >= 0 (0 = use default 5000), got %d", ga.DailyCapHard))
 	}
@@ -118,6 +282,14 @@ func (c *Config) Validate() error {
 		issues = append(issues, fmt.Sprintf("discovery.sources.gharchive: daily_cap_warn (%d) must be less than daily_cap_hard (%d)", ga.DailyCapWarn, ga.DailyCapHard))
 	}
 
+	ac := c.Collector.ArchiveCache
+	if ac.MaxSizeMB < 0 {
+		issues = append(issues, fmt.Sprintf("collector.archive_cache.max_size_mb: must be >= 0 (0 = use default 20480), got %d", ac.MaxSizeMB))
+	}
+	if ac.Offline && ac.Dir == "" && len(ac.SeedDirs) == 0 {
+		issues = append(issues, "collector.archive_cache.offline: requires dir or seed_dirs to read archives from")
+	}
+
 	// Classification settings
 	if c.Classification.OllamaEndpoint != "" {
 		parsedURL, err := url.Parse(c.Classification.OllamaEndpoint)
@@ -130,6 +302,58 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	issues = append(issues, providerIssues("classification.provider", c.Classification.Provider)...)
+
+	if n := len(c.Classification.Ensemble.Members); n == 1 {
+		issues = append(issues, "classification.ensemble.members: need at least 2 members to vote, got 1")
+	}
+	resolved := c.Classification.EnsembleMembers()
+	for i, m := range c.Classification.Ensemble.Members {
+		m.APIKey = resolved[i].APIKey
+		issues = append(issues, providerIssues(fmt.Sprintf("classification.ensemble.members[%d]", i), m)...)
+	}
+	if a := c.Classification.Ensemble.MinAgreement; a < 0 || a > 1 {
+		issues = append(issues, fmt.Sprintf("classification.ensemble.min_agreement: must be between 0 and 1, got %.2f", a))
+	}
+
+	if e := c.Classification.Embedding; e.Model != "" {
+		resolved := c.Classification.ResolvedEmbedding()
+		if resolved.Type == ClassifierAnthropic {
+			issues = append(issues, "classification.embedding.type: anthropic has no embeddings API; use ollama or openai")
+		}
+		issues = append(issues, providerIssues("classification.embedding", ClassifierProviderConfig{
+			Type: e.Type, Endpoint: e.Endpoint, APIKey: resolved.APIKey, TimeoutMs: e.TimeoutMs,
+		})...)
+		if e.MinMargin < 0 || e.MinMargin > 1 {
+			issues = append(issues, fmt.Sprintf("classification.embedding.min_margin: must be between 0 and 1, got %.2f", e.MinMargin))
+		}
+		if e.MinExamples < 1 {
+			issues = append(issues, fmt.Sprintf("classification.embedding.min_examples: must be >= 1, got %d", e.MinExamples))
+		}
+	}
+
+	ruleIDs := make(map[string]bool, len(c.Classification.Rules))
+	for i, r := range c.Classification.Rules {
+		prefix := fmt.Sprintf("classification.rules[%d]", i)
+		if r.ID == "" {
+			issues = append(issues, prefix+".id: required")
+		} else if ruleIDs[r.ID] {
+			issues = append(issues, fmt.Sprintf("%s.id: duplicate rule id %q", prefix, r.ID))
+		}
+		ruleIDs[r.ID] = true
+		if r.Category == "" || r.Subcategory == "" {
+			issues = append(issues, prefix+": category and subcategory are required")
+		}
+		if len(r.Topics) == 0 && len(r.Languages) == 0 && r.Name == "" && len(r.Orgs) == 0 && len(r.Keywords) == 0 {
+			issues = append(issues, prefix+": needs at least one of topics, languages, name, orgs or keywords")
+		}
+		if r.Name != "" {
+			if _, err := regexp.Compile(r.Name); err != nil {
+				issues = append(issues, fmt.Sprintf("%s.name: invalid regexp: %v", prefix, err))
+			}
+		}
+	}
+
 	if c.Classification.TimeoutMs < 0 {
 		issues = append(issues, fmt.Sprintf("classification.timeout_ms: must be >= 0, got %d", c.Classification.TimeoutMs))
 	}
@@ -142,6 +366,22 @@ func (c *Config) Validate() error {
 		issues = append(issues, fmt.Sprintf("classification.min_confidence: must be between 0 and 1, got %.2f", c.Classification.MinConfidence))
 	}
 
+	if c.Classification.MaxSecondaryLabels < 0 {
+		issues = append(issues, fmt.Sprintf("classification.max_secondary_labels: must be >= 0, got %d", c.Classification.MaxSecondaryLabels))
+	}
+
+	if c.Classification.MinSecondaryConfidence < 0 || c.Classification.MinSecondaryConfidence > 1 {
+		issues = append(issues, fmt.Sprintf("classification.min_secondary_confidence: must be between 0 and 1, got %.2f", c.Classification.MinSecondaryConfidence))
+	}
+
+	if c.Classification.FewShot.Examples < 0 {
+		issues = append(issues, fmt.Sprintf("classification.few_shot.examples: must be >= 0, got %d", c.Classification.FewShot.Examples))
+	}
+
+	if c.Classification.FewShot.MaxShare < 0 || c.Classification.FewShot.MaxShare >= 1 {
+		issues = append(issues, fmt.Sprintf("classification.few_shot.max_share: must be >= 0 and < 1, got %.2f", c.Classification.FewShot.MaxShare))
+	}
+
 	// Scoring weights must be non-negative
 	if c.Scoring.Weights.StarVelocity < 0 {
 		issues = append(issues, fmt.Sprintf("scoring.weights.star_velocity: must be >= 0, got %f", c.Scoring.Weights.StarVelocity))
@@ -171,6 +411,54 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// appIssues validates a GitHub App block at prefix.
+func appIssues(prefix string, app GithubAppConfig) []string {
+	var issues []string
+	if app.AppID < 0 {
+		issues = append(issues, fmt.Sprintf("%s.app_id: must be positive, got %d", prefix, app.AppID))
+	}
+	if app.InstallationID < 0 {
+		issues = append(issues, fmt.Sprintf("%s.installation_id: must be >= 0, got %d", prefix, app.InstallationID))
+	}
+	if app.PrivateKeyFile == "" {
+		issues = append(issues, fmt.Sprintf("%s.private_key_file: required when %s.app_id is set", prefix, prefix))
+	}
+	return issues
+}
+
+// providerIssues validates a classifier provider block at prefix.
+func providerIssues(prefix string, cp ClassifierProviderConfig) []string {
+	var issues []string
+	switch cp.Type {
+	case "", ClassifierOllama, ClassifierOpenAI, ClassifierAnthropic:
+	default:
+		issues = append(issues, fmt.Sprintf("%s.type: must be %s, %s or %s, got %q", prefix, ClassifierOllama, ClassifierOpenAI, ClassifierAnthropic, cp.Type))
+	}
+	if cp.Endpoint != "" {
+		parsedURL, err := url.Parse(cp.Endpoint)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("%s.endpoint: invalid URL format: %v", prefix, err))
+		} else if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			issues = append(issues, fmt.Sprintf("%s.endpoint: must use http:// or https:// scheme, got %q", prefix, parsedURL.Scheme))
+		} else if parsedURL.Host == "" {
+			issues = append(issues, prefix+".endpoint: missing host")
+		}
+	}
+	if cp.Type == ClassifierAnthropic && cp.APIKey == "" {
+		issues = append(issues, prefix+".api_key: required for type anthropic")
+	}
+	if cp.TimeoutMs < 0 {
+		issues = append(issues, fmt.Sprintf("%s.timeout_ms: must be >= 0, got %d", prefix, cp.TimeoutMs))
+	}
+	if cp.MaxTokens < 0 {
+		issues = append(issues, fmt.Sprintf("%s.max_tokens: must be >= 0 (0 = use default %d), got %d", prefix, DefaultClassifierTokens, cp.MaxTokens))
+	}
+	if cp.Temperature != nil && (*cp.Temperature < 0 || *cp.Temperature > 2) {
+		issues = append(issues, fmt.Sprintf("%s.temperature: must be between 0 and 2, got %.2f", prefix, *cp.Temperature))
+	}
+	return issues
+}
+
 // ValidateAndLoad loads a config file, expands env vars, and validates.
 // This is the recommended function for application startup.
 func ValidateAndLoad(path string) (*Config, error) {
@@ -75,6 +75,100 @@ func TestValidate_MissingToken(t *testing.T) {
 	}
 }
 
+func TestValidate_TokenPoolSatisfiesToken(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.GitHub.Token = ""
+	cfg.GitHub.Tokens = []string{"tok-a, tok-b"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("github.tokens without github.token should validate: %v", err)
+	}
+}
+
+func TestValidate_GitHubApp(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.GitHub.Token = ""
+	cfg.GitHub.App = GithubAppConfig{AppID: 42, PrivateKeyFile: "/etc/radar/app.pem"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("github.app without a token should validate: %v", err)
+	}
+
+	cfg.GitHub.App = GithubAppConfig{AppID: 42, InstallationID: -1}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected errors for missing key file and negative installation id")
+	}
+	for _, want := range []string{"github.app.private_key_file", "github.app.installation_id"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s: %v", want, err)
+		}
+	}
+}
+
+func TestValidate_GitHubHosts(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.GitHub.Hosts = []GithubHostConfig{{
+		Name:   "ghe.corp",
+		APIURL: "https://ghe.corp/api/v3",
+		Token:  "ghe-token",
+	}}
+	cfg.Repositories = []TrackedRepo{{Repo: "ghe.corp/platform/deployer"}, {Repo: "owner/repo"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("configured host should validate: %v", err)
+	}
+
+	cfg.Repositories = append(cfg.Repositories, TrackedRepo{Repo: "ghe.other/team/svc"})
+	cfg.GitHub.Hosts = append(cfg.GitHub.Hosts,
+		GithubHostConfig{Name: "ghe.corp", APIURL: "ftp://ghe.corp", Token: "t"},
+		GithubHostConfig{Name: "github.com", APIURL: "https://api.github.com"},
+	)
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected errors for duplicate host, bad api_url, github.com host and unknown repo host")
+	}
+	for _, want := range []string{
+		"github.hosts[1].name",
+		"github.hosts[1].api_url",
+		"github.hosts[2].name",
+		"github.hosts[2].token",
+		`host "ghe.other" is not configured`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s: %v", want, err)
+		}
+	}
+}
+
+func TestGithubHostConfig_ResolvedGraphQLURL(t *testing.T) {
+	tests := []struct {
+		host GithubHostConfig
+		want string
+	}{
+		{GithubHostConfig{APIURL: "https://ghe.corp/api/v3"}, "https://ghe.corp/api/graphql"},
+		{GithubHostConfig{APIURL: "https://ghe.corp/api/v3/"}, "https://ghe.corp/api/graphql"},
+		{GithubHostConfig{APIURL: "https://api.ghe.corp"}, "https://api.ghe.corp/graphql"},
+		{GithubHostConfig{APIURL: "https://ghe.corp/api/v3", GraphQLURL: "https://gql.ghe.corp"}, "https://gql.ghe.corp"},
+	}
+	for _, tt := range tests {
+		if got := tt.host.ResolvedGraphQLURL(); got != tt.want {
+			t.Errorf("ResolvedGraphQLURL(%+v) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestGithubConfig_AllTokens(t *testing.T) {
+	g := GithubConfig{
+		Token:  "tok-a",
+		Tokens: []string{"tok-b,tok-c", " tok-a ", "tok-d tok-b", ""},
+	}
+	got := strings.Join(g.AllTokens(), ",")
+	if want := "tok-a,tok-b,tok-c,tok-d"; got != want {
+		t.Errorf("AllTokens() = %q, want %q", got, want)
+	}
+	if toks := (GithubConfig{}).AllTokens(); len(toks) != 0 {
+		t.Errorf("AllTokens() on empty config = %v, want none", toks)
+	}
+}
+
 func TestValidate_MissingEndpoint(t *testing.T) {
 	cfg := &Config{
 		GitHub: GithubConfig{
@@ -447,6 +541,9 @@ func TestValidate_GHArchiveDiscovery_NegativeValuesRejected(t *testing.T) {
 		"min_stars_gate": func(g *DiscoveryGHArchiveConfig) { g.MinStarsGate = -1 },
 		"daily_cap_warn": func(g *DiscoveryGHArchiveConfig) { g.DailyCapWarn = -1 },
 		"daily_cap_hard": func(g *DiscoveryGHArchiveConfig) { g.DailyCapHard = -1 },
+		"concurrency":    func(g *DiscoveryGHArchiveConfig) { g.Concurrency = -1 },
+
+		"max_release_candidates": func(g *DiscoveryGHArchiveConfig) { g.MaxReleaseCandidates = -1 },
 	}
 
 	for field, mutate := range cases {
@@ -464,6 +561,51 @@ func TestValidate_GHArchiveDiscovery_NegativeValuesRejected(t *testing.T) {
 	}
 }
 
+func TestValidate_GHArchiveDiscovery_RankBy(t *testing.T) {
+	for _, v := range []string{"", "events", "stargazers", "contributors"} {
+		cfg := validBaseConfig()
+		cfg.Discovery.Sources.GHArchive.RankBy = v
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("rank_by %q should validate: %v", v, err)
+		}
+	}
+
+	cfg := validBaseConfig()
+	cfg.Discovery.Sources.GHArchive.RankBy = "forks"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "gharchive.rank_by") {
+		t.Errorf("rank_by forks: err = %v, want gharchive.rank_by issue", err)
+	}
+}
+
+func TestValidate_ArchiveCache(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.Collector.ArchiveCache = ArchiveCacheConfig{Dir: "/var/cache/radar", MaxSizeMB: 1024, Offline: true}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("offline cache with dir should validate: %v", err)
+	}
+
+	cfg = validBaseConfig()
+	cfg.Collector.ArchiveCache = ArchiveCacheConfig{Offline: true, SeedDirs: []string{"/mnt/gharchive"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("offline cache over seed dirs should validate: %v", err)
+	}
+
+	cfg = validBaseConfig()
+	cfg.Collector.ArchiveCache = ArchiveCacheConfig{Offline: true}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "archive_cache.offline") {
+		t.Errorf("offline without a source: err = %v, want archive_cache.offline issue", err)
+	}
+
+	cfg = validBaseConfig()
+	cfg.Collector.ArchiveCache.MaxSizeMB = -1
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "archive_cache.max_size_mb") {
+		t.Errorf("negative max_size_mb: err = %v, want archive_cache.max_size_mb issue", err)
+	}
+}
+
 func TestValidate_GHArchiveDiscovery_EnabledRequiresPositives(t *testing.T) {
 	// Flag flip with zeros: every required positive int surfaces as a
 	// specific issue so the operator knows what to fix.
@@ -519,3 +661,341 @@ func TestValidate_GHArchiveDiscovery_WarnHardOrdering(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_HTTPCache(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.GitHub.HTTPCache = HTTPCacheConfig{
+		Dir:       "/var/cache/radar/http",
+		MaxSizeMB: 512,
+		TTLs:      map[string]string{"releases": "1h", "contributors": "6h"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("http cache with ttls should validate: %v", err)
+	}
+
+	cfg = validBaseConfig()
+	cfg.GitHub.HTTPCache = HTTPCacheConfig{
+		Dir:        "/var/cache/radar/http",
+		MaxSizeMB:  -1,
+		MaxEntryKB: -1,
+		TTLs:       map[string]string{"commits": "1h", "pulls": "soon", "issues": "-1m"},
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("invalid http cache settings should fail validation")
+	}
+	for _, want := range []string{
+		"http_cache.max_size_mb",
+		"http_cache.max_entry_kb",
+		"http_cache.ttls.commits",
+		"http_cache.ttls.pulls",
+		"http_cache.ttls.issues",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should call out %s: %v", want, err)
+		}
+	}
+}
+
+func TestValidate_Governor(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.GitHub.Governor = GovernorConfig{
+		Enabled:     true,
+		Search:      GovernorResourceConfig{PerMinute: 20, Burst: 1, MaxInFlight: 1},
+		CooldownSec: 120,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("governor overrides should validate: %v", err)
+	}
+
+	cfg = validBaseConfig()
+	cfg.GitHub.Governor = GovernorConfig{
+		Enabled:     true,
+		Core:        GovernorResourceConfig{PerMinute: -1},
+		Search:      GovernorResourceConfig{Burst: -1},
+		GraphQL:     GovernorResourceConfig{MaxInFlight: -2},
+		CooldownSec: -5,
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("negative governor settings should fail validation")
+	}
+	for _, want := range []string{
+		"github.governor.core.per_minute",
+		"github.governor.search.burst",
+		"github.governor.graphql.max_in_flight",
+		"github.governor.cooldown_sec",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should call out %s: %v", want, err)
+		}
+	}
+}
+
+func TestValidate_PlannerReservePct(t *testing.T) {
+	for _, pct := range []int{0, 10, 90} {
+		cfg := validBaseConfig()
+		cfg.GitHub.Planner = PlannerConfig{Enabled: true, ReservePct: pct}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("reserve_pct %d should validate: %v", pct, err)
+		}
+	}
+	for _, pct := range []int{-1, 91} {
+		cfg := validBaseConfig()
+		cfg.GitHub.Planner = PlannerConfig{Enabled: true, ReservePct: pct}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "github.planner.reserve_pct") {
+			t.Errorf("reserve_pct %d: err = %v, want a github.planner.reserve_pct issue", pct, err)
+		}
+	}
+}
+
+func TestValidate_Webhooks(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.GitHub.Webhooks = WebhookConfig{Enabled: true, Secret: "s3cret", PromoteHours: 12}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("webhooks with a secret should validate: %v", err)
+	}
+
+	cfg.GitHub.Webhooks = WebhookConfig{Enabled: true, PromoteHours: -1}
+	err := cfg.Validate()
+	for _, want := range []string{"github.webhooks.secret", "github.webhooks.promote_hours"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want a %s issue", err, want)
+		}
+	}
+}
+
+func TestValidate_Forges(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.Forges = []ForgeConfig{
+		{Name: "gitlab", Type: ForgeTypeGitLab},
+		{Name: "codeberg", Type: ForgeTypeGitea, APIURL: "https://codeberg.org/api/v1"},
+	}
+	cfg.Repositories = []TrackedRepo{{Repo: "gitlab:gnome/world/podcasts"}, {Repo: "codeberg:forgejo/forgejo"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid forges failed validation: %v", err)
+	}
+
+	cfg.Forges = append(cfg.Forges,
+		ForgeConfig{Name: "codeberg", Type: ForgeTypeGitea, APIURL: "https://codeberg.org/api/v1"},
+		ForgeConfig{Name: "Self Hosted", Type: ForgeTypeGitea},
+		ForgeConfig{Name: "github", Type: "bitbucket"},
+	)
+	cfg.Repositories = append(cfg.Repositories, TrackedRepo{Repo: "sourcehut:~sircmpwn/hare"})
+	err := cfg.Validate()
+	for _, want := range []string{
+		"forges[2].name",
+		"forges[3].name",
+		"forges[3].api_url",
+		"forges[4].name",
+		"forges[4].type",
+		`repositories[2]: forge "sourcehut"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want an issue for %s", err, want)
+		}
+	}
+}
+
+func TestValidate_ClassifierProvider(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.Classification.Provider = ClassifierProviderConfig{
+		Type:     ClassifierOpenAI,
+		Endpoint: "http://localhost:8000/v1",
+		Model:    "qwen2.5-7b-instruct",
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid provider failed validation: %v", err)
+	}
+
+	cfg.Classification.Provider = ClassifierProviderConfig{Type: "bedrock", Endpoint: "ftp://x", TimeoutMs: -1}
+	err := cfg.Validate()
+	for _, want := range []string{"provider.type", "provider.endpoint", "provider.timeout_ms"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want an issue for %s", err, want)
+		}
+	}
+
+	cfg.Classification.Provider = ClassifierProviderConfig{Type: ClassifierAnthropic}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "provider.api_key") {
+		t.Errorf("err = %v, want an issue for provider.api_key", err)
+	}
+}
+
+func TestValidate_SecondaryLabels(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.Classification.MaxSecondaryLabels = 2
+	cfg.Classification.MinSecondaryConfidence = 0.5
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid secondary label settings failed validation: %v", err)
+	}
+
+	cfg.Classification.MaxSecondaryLabels = -1
+	cfg.Classification.MinSecondaryConfidence = 1.5
+	err := cfg.Validate()
+	for _, want := range []string{"max_secondary_labels", "min_secondary_confidence"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want an issue for %s", err, want)
+		}
+	}
+}
+
+func TestValidate_FewShot(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.Classification.FewShot = FewShotConfig{Examples: 3, MaxShare: 0.25}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid few-shot settings failed validation: %v", err)
+	}
+
+	cfg.Classification.FewShot = FewShotConfig{Examples: -1, MaxShare: 1}
+	err := cfg.Validate()
+	for _, want := range []string{"few_shot.examples", "few_shot.max_share"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want an issue for %s", err, want)
+		}
+	}
+}
+
+func TestValidate_Embedding(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.Classification.Embedding = EmbeddingConfig{Model: "nomic-embed-text", MinMargin: 0.05, MinExamples: 3}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid embedding settings failed validation: %v", err)
+	}
+
+	cfg.Classification.Embedding = EmbeddingConfig{Type: ClassifierAnthropic, Model: "x", MinMargin: 2, MinExamples: 0}
+	err := cfg.Validate()
+	for _, want := range []string{"embedding.type", "embedding.min_margin", "embedding.min_examples"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want an issue for %s", err, want)
+		}
+	}
+
+	// Without a model the stage is off and its other settings unchecked.
+	cfg.Classification.Embedding = EmbeddingConfig{MinMargin: 2}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("disabled embedding stage failed validation: %v", err)
+	}
+}
+
+func TestValidate_Rules(t *testing.T) {
+	cfg := validBaseConfig()
+	cfg.Classification.Rules = []ClassificationRule{
+		{ID: "solidity", Languages: []string{"Solidity"}, Category: "crypto", Subcategory: "blockchain-web3"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid rules failed validation: %v", err)
+	}
+
+	cfg.Classification.Rules = []ClassificationRule{
+		{ID: "x", Name: "(", Category: "ai"},
+		{ID: "x", Topics: []string{"llm"}, Category: "ai", Subcategory: "llm-tooling"},
+		{Category: "ai", Subcategory: "agents"},
+	}
+	err := cfg.Validate()
+	for _, want := range []string{
+		"rules[0].name: invalid regexp", "rules[0]: category and subcategory are required",
+		`rules[1].id: duplicate rule id "x"`, "rules[2].id: required", "rules[2]: needs at least one of",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want an issue for %s", err, want)
+		}
+	}
+}
+
+func TestResolvedEmbedding(t *testing.T) {
+	c := ClassificationConfig{OllamaEndpoint: "http://ollama:11434", Model: "llama3", TimeoutMs: 3000}
+	if p := c.ResolvedEmbedding(); p != (ClassifierProviderConfig{}) {
+		t.Errorf("ResolvedEmbedding() without a model = %+v, want zero", p)
+	}
+
+	c.Embedding = EmbeddingConfig{Model: "nomic-embed-text"}
+	p := c.ResolvedEmbedding()
+	if p.Type != ClassifierOllama || p.Endpoint != "http://ollama:11434" || p.Model != "nomic-embed-text" || p.TimeoutMs != 3000 {
+		t.Errorf("ResolvedEmbedding() = %+v, want the provider endpoint with the embedding model", p)
+	}
+
+	c.Provider = ClassifierProviderConfig{Type: ClassifierAnthropic, APIKey: "sk-ant"}
+	c.Embedding = EmbeddingConfig{Type: ClassifierOpenAI, Model: "text-embedding-3-small"}
+	p = c.ResolvedEmbedding()
+	if p.Endpoint != DefaultOpenAIEndpoint || p.APIKey != "" {
+		t.Errorf("ResolvedEmbedding() = %+v, want the OpenAI default endpoint and no inherited key", p)
+	}
+}
+
+func TestValidate_Ensemble(t *testing.T) {
+	cfg := validBaseConfig()
+	hot := 0.8
+	cfg.Classification.Ensemble = EnsembleConfig{
+		Members:      []ClassifierProviderConfig{{}, {Temperature: &hot}, {Model: "llama3.2:3b"}},
+		MinAgreement: 0.6,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid ensemble failed validation: %v", err)
+	}
+
+	bad := 3.0
+	cfg.Classification.Ensemble = EnsembleConfig{
+		Members:      []ClassifierProviderConfig{{Type: ClassifierAnthropic}, {Temperature: &bad}},
+		MinAgreement: 1.5,
+	}
+	err := cfg.Validate()
+	for _, want := range []string{"ensemble.members[0].api_key", "ensemble.members[1].temperature", "ensemble.min_agreement"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want an issue for %s", err, want)
+		}
+	}
+
+	cfg.Classification.Ensemble = EnsembleConfig{Members: []ClassifierProviderConfig{{}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "at least 2 members") {
+		t.Errorf("err = %v, want an issue for a single-member ensemble", err)
+	}
+}
+
+func TestClassificationConfig_EnsembleMembers(t *testing.T) {
+	cold := 0.0
+	c := ClassificationConfig{
+		Model:     "qwen3:1.7b",
+		TimeoutMs: 30000,
+		Provider:  ClassifierProviderConfig{Type: ClassifierOpenAI, Endpoint: "http://vllm:8000/v1", APIKey: "k", Temperature: &cold},
+		Ensemble: EnsembleConfig{Members: []ClassifierProviderConfig{
+			{},
+			{Model: "llama3.2:3b", TimeoutMs: 5000},
+			{Type: ClassifierAnthropic, APIKey: "a"},
+		}},
+	}
+	got := c.EnsembleMembers()
+	if len(got) != 3 {
+		t.Fatalf("EnsembleMembers() = %d members, want 3", len(got))
+	}
+	if got[0].Type != ClassifierOpenAI || got[0].Endpoint != "http://vllm:8000/v1" || got[0].APIKey != "k" || got[0].Model != "qwen3:1.7b" || got[0].Temperature != &cold {
+		t.Errorf("members[0] = %+v, want the provider settings", got[0])
+	}
+	if got[1].Model != "llama3.2:3b" || got[1].TimeoutMs != 5000 || got[1].Endpoint != "http://vllm:8000/v1" {
+		t.Errorf("members[1] = %+v, want its overrides on the provider endpoint", got[1])
+	}
+	if got[2].Endpoint != DefaultAnthropicEndpoint || got[2].APIKey != "a" || got[2].Model != "qwen3:1.7b" {
+		t.Errorf("members[2] = %+v, want anthropic defaults with the inherited model", got[2])
+	}
+	if (ClassificationConfig{}).EnsembleMembers() != nil {
+		t.Error("EnsembleMembers() without members should be nil")
+	}
+}
+
+func TestClassificationConfig_ResolvedProvider(t *testing.T) {
+	c := ClassificationConfig{OllamaEndpoint: "http://ollama:11434", Model: "qwen3:1.7b", TimeoutMs: 30000}
+	p := c.ResolvedProvider()
+	if p.Type != ClassifierOllama || p.Endpoint != "http://ollama:11434" || p.Model != "qwen3:1.7b" || p.TimeoutMs != 30000 || p.MaxTokens != DefaultClassifierTokens {
+		t.Errorf("ResolvedProvider() = %+v, want the legacy Ollama settings", p)
+	}
+
+	c.Provider = ClassifierProviderConfig{Type: ClassifierAnthropic, Model: "claude-haiku", TimeoutMs: 5000}
+	p = c.ResolvedProvider()
+	if p.Endpoint != DefaultAnthropicEndpoint || p.Model != "claude-haiku" || p.TimeoutMs != 5000 {
+		t.Errorf("ResolvedProvider() = %+v, want the provider overrides", p)
+	}
+	if !c.Enabled() {
+		t.Error("Enabled() = false with an endpoint and model")
+	}
+}
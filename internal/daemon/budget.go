@@ -6,6 +6,7 @@ import (
 
 	"github.com/hrexed/github-radar/internal/config"
 	"github.com/hrexed/github-radar/internal/github"
+	"github.com/hrexed/github-radar/internal/logging"
 	"github.com/hrexed/github-radar/internal/metrics"
 )
 
@@ -33,6 +34,22 @@ func (o *apiObserver) ObserveCall(resource, result string) {
 	o.exporter.RecordAPICall(o.ctx, resource, result)
 }
 
+// ObserveHTTPCache counts one cacheable REST request.
+func (o *apiObserver) ObserveHTTPCache(endpoint, result string) {
+	if o == nil || o.exporter == nil {
+		return
+	}
+	o.exporter.RecordHTTPCache(o.ctx, endpoint, result)
+}
+
+// ObserveHTTPCacheSize emits the HTTP cache size gauges.
+func (o *apiObserver) ObserveHTTPCacheSize(entries int, bytes int64) {
+	if o == nil || o.exporter == nil {
+		return
+	}
+	o.exporter.RecordHTTPCacheSize(o.ctx, entries, bytes)
+}
+
 // ObserveRateLimit emits the rate-limit gauge set. GitHub sends these
 // headers on every response so this gets called per-request.
 func (o *apiObserver) ObserveRateLimit(limit, remaining int, resetAt time.Time) {
@@ -46,6 +63,58 @@ func (o *apiObserver) ObserveRateLimit(limit, remaining int, resetAt time.Time)
 	})
 }
 
+// ObserveGraphQLRateLimit emits the GraphQL point-budget gauges.
+func (o *apiObserver) ObserveGraphQLRateLimit(limit, remaining int, resetAt time.Time) {
+	if o == nil || o.exporter == nil {
+		return
+	}
+	o.exporter.RecordGraphQLRateLimit(o.ctx, metrics.RateLimitSnapshot{
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	})
+}
+
+// ObserveGraphQLBatch records one GraphQL bulk metadata query.
+func (o *apiObserver) ObserveGraphQLBatch(repos, cost int) {
+	if o == nil || o.exporter == nil {
+		return
+	}
+	o.exporter.RecordGraphQLBatch(o.ctx, repos, cost)
+}
+
+// ObserveTokenRateLimit emits the per-token budget gauges for the pooled
+// token that served the last response. tokenID is already redacted.
+func (o *apiObserver) ObserveTokenRateLimit(tokenID, resource string, limit, remaining int, _ time.Time) {
+	if o == nil || o.exporter == nil {
+		return
+	}
+	o.exporter.RecordTokenRateLimit(o.ctx, metrics.TokenRateLimitSnapshot{
+		TokenID:   tokenID,
+		Resource:  resource,
+		Limit:     limit,
+		Remaining: remaining,
+	})
+}
+
+// newGovernor builds the request governor for one GitHub server from
+// the github.governor config, logging every secondary rate limit
+// cooldown. host is empty for github.com. Nil when disabled.
+func newGovernor(cfg config.GovernorConfig, host string) *github.Governor {
+	if !cfg.Enabled {
+		return nil
+	}
+	g := github.NewGovernor(governorConfigFromYAML(cfg))
+	g.SetOnCooldown(func(resource string, d time.Duration) {
+		args := []any{"resource", resource, "cooldown", d.String()}
+		if host != "" {
+			args = append(args, "host", host)
+		}
+		logging.Warn("github secondary rate limit, pausing all requests", args...)
+	})
+	return g
+}
+
 // tierConfigFromYAML maps the YAML-surface TieringConfig into the
 // refresh-tier classifier config, filling zero values from
 // github.DefaultTierConfig.
@@ -77,8 +146,10 @@ func tierConfigFromYAML(t config.TieringConfig) github.TierConfig {
 
 // buildTierCandidates extracts the minimum per-repo inputs the tier
 // classifier needs, merging database "first_seen_at" (authoritative
-// for new-repo promotion) with the live in-memory store.
+// for new-repo promotion) and webhook promotions with the live
+// in-memory store.
 func (d *Daemon) buildTierCandidates() []github.TierCandidate {
+	d.webhooks.prune(time.Now())
 	all := d.store.AllRepoStates()
 	out := make([]github.TierCandidate, 0, len(all))
 
@@ -87,6 +158,7 @@ func (d *Daemon) buildTierCandidates() []github.TierCandidate {
 			FullName:        fullName,
 			GrowthScore:     rs.GrowthScore,
 			LastCollectedAt: rs.LastCollected,
+			PromotedUntil:   d.webhooks.promotedUntil(fullName),
 		}
 		if d.db != nil {
 			if rec, err := d.db.GetRepo(fullName); err == nil && rec != nil {
@@ -118,7 +190,8 @@ func (d *Daemon) publishTierHistogram(hist github.TierHistogram) {
 
 // publishRateLimit emits a fresh rate-limit snapshot even when no API
 // calls are in flight. Useful after idle ticker wakeups so dashboards
-// keep a current value.
+// keep a current value. The totals cover the whole token pool; each
+// token's last-seen budgets follow under its redacted ID.
 func (d *Daemon) publishRateLimit() {
 	if d.exporter == nil || d.client == nil {
 		return
@@ -129,4 +202,12 @@ func (d *Daemon) publishRateLimit() {
 		Remaining: rl.Remaining,
 		ResetAt:   rl.Reset,
 	})
+	for _, t := range d.client.TokenRateLimits() {
+		d.exporter.RecordTokenRateLimit(d.ctx, metrics.TokenRateLimitSnapshot{
+			TokenID:   t.TokenID,
+			Resource:  t.Resource,
+			Limit:     t.Limit,
+			Remaining: t.Remaining,
+		})
+	}
 }
@@ -56,4 +56,11 @@ func TestAPIObserver_NilExporter(t *testing.T) {
 	obs := newAPIObserver(context.TODO(), nil)
 	obs.ObserveCall("repo", "ok")
 	obs.ObserveRateLimit(5000, 4000, time.Now())
+	obs.ObserveHTTPCache("pulls", "revalidated")
+	obs.ObserveHTTPCacheSize(1, 512)
+	obs.ObserveGraphQLRateLimit(5000, 4900, time.Now())
+	obs.ObserveGraphQLBatch(25, 3)
+
+	var _ github.HTTPCacheObserver = obs
+	var _ github.GraphQLObserver = obs
 }
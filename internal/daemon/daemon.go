@@ -17,9 +17,12 @@ import (
 	"github.com/hrexed/github-radar/internal/config"
 	"github.com/hrexed/github-radar/internal/database"
 	"github.com/hrexed/github-radar/internal/discovery"
+	"github.com/hrexed/github-radar/internal/forge"
+	"github.com/hrexed/github-radar/internal/gharchive"
 	"github.com/hrexed/github-radar/internal/github"
 	"github.com/hrexed/github-radar/internal/logging"
 	"github.com/hrexed/github-radar/internal/metrics"
+	"github.com/hrexed/github-radar/internal/repository"
 	"github.com/hrexed/github-radar/internal/scoring"
 	"github.com/hrexed/github-radar/internal/state"
 )
@@ -63,20 +66,42 @@ func DefaultDaemonConfig() DaemonConfig {
 
 // Daemon manages the background scanner service.
 type Daemon struct {
-	cfg        *config.Config
-	daemonCfg  DaemonConfig
-	client     *github.Client
-	scanner    *github.Scanner
-	discoverer *discovery.Discoverer
-	classifier *classification.Pipeline
-	exporter   *metrics.Exporter
-	store      *state.Store
-	db         *database.DB
-	server     *http.Server
-	router     *metrics.Router
+	cfg       *config.Config
+	daemonCfg DaemonConfig
+	client    *github.Client
+	scanner   *github.Scanner
+	// hostScanners scan repos on GitHub Enterprise Server hosts, keyed
+	// by host name (see hosts.go).
+	hostScanners map[string]*github.Scanner
+	// forgeScanner scans repos on GitLab and Gitea/Forgejo forges; nil
+	// when none is configured (see forges.go).
+	forgeScanner *forge.Scanner
+	discoverer   *discovery.Discoverer
+	classifier   *classification.Pipeline
+	exporter     *metrics.Exporter
+	store        *state.Store
+	db           *database.DB
+	server       *http.Server
+	router       *metrics.Router
 
 	ghArchiveCollector *discovery.GHArchiveSource
 
+	// archiveCache is the on-disk gharchive archive cache shared by
+	// both gharchive readers; nil when neither is enabled.
+	archiveCache *gharchive.Cache
+
+	// planner budgets each scan cycle; nil when github.planner is
+	// disabled (planner.go).
+	planner *planner
+
+	// webhooks receives GitHub webhook deliveries; nil when
+	// github.webhooks is disabled (webhook.go).
+	webhooks *webhookReceiver
+
+	// review serves the needs_review queue; nil without a database
+	// (review.go).
+	review *reviewAPI
+
 	mu              sync.RWMutex
 	status          Status
 	lastScan        time.Time
@@ -91,6 +116,10 @@ type Daemon struct {
 	// scrollback. Empty when the last run succeeded. Guarded by mu (ISI-775).
 	classificationLastErr string
 
+	// plan is the current cycle's API budget plan, served on /status.
+	// Guarded by mu.
+	plan *CyclePlan
+
 	ctx        context.Context
 	cancel     context.CancelFunc
 	scanMu     sync.Mutex // prevents overlapping scans
@@ -100,7 +129,7 @@ type Daemon struct {
 // New creates a new daemon instance.
 func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 	// Create GitHub client
-	client, err := github.NewClient(cfg.GitHub.Token)
+	client, err := NewGitHubClient(cfg.GitHub)
 	if err != nil {
 		return nil, fmt.Errorf("creating github client: %w", err)
 	}
@@ -114,6 +143,30 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 				"reset", reset.Format(time.RFC3339))
 		},
 	})
+	client.SetGovernor(newGovernor(cfg.GitHub.Governor, ""))
+
+	hostClients, err := newHostClients(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// One REST response cache serves every client; entries are keyed
+	// by credentials and full URL, so hosts do not collide.
+	httpCache, err := newHTTPCache(cfg.GitHub.HTTPCache)
+	if err != nil {
+		return nil, err
+	}
+	if httpCache != nil {
+		client.SetHTTPCache(httpCache)
+		for _, hc := range hostClients {
+			hc.SetHTTPCache(httpCache)
+		}
+		stats := httpCache.Stats()
+		logging.Info("github http cache enabled",
+			"dir", cfg.GitHub.HTTPCache.Dir,
+			"entries", stats.Entries,
+			"bytes", stats.Bytes)
+	}
 
 	// Create state store
 	store := state.NewStore(daemonCfg.StatePath)
@@ -123,18 +176,13 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 
 	// Create scanner
 	scanner := github.NewScanner(client, store)
-	scanner.SetScoringWeights(scoring.Weights{
-		StarVelocity:      cfg.Scoring.Weights.StarVelocity,
-		StarAcceleration:  cfg.Scoring.Weights.StarAcceleration,
-		ForkVelocity:      cfg.Scoring.Weights.ForkVelocity,
-		ReleaseCadence:    cfg.Scoring.Weights.ReleaseCadence,
-		ContributorGrowth: cfg.Scoring.Weights.ContributorGrowth,
-		PRVelocity:        cfg.Scoring.Weights.PRVelocity,
-		IssueVelocity:     cfg.Scoring.Weights.IssueVelocity,
-	})
+	scanner.SetScoringWeights(scoringWeights(cfg))
 	scanner.SetLogger(func(level, msg string, args ...interface{}) {
 		logWithLevel(level, msg, args...)
 	})
+	hostScanners := newHostScanners(hostClients, store, cfg)
+	forgeProviders := NewForgeProviders(cfg)
+	forgeScanner := newForgeScanner(forgeProviders, store, cfg)
 
 	// Create discoverer if enabled
 	var disc *discovery.Discoverer
@@ -145,6 +193,8 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 			MaxAgeDays:         cfg.Discovery.MaxAgeDays,
 			AutoTrackThreshold: cfg.Discovery.AutoTrackThreshold,
 			Exclusions:         cfg.Exclusions,
+			IncludeForks:       cfg.Discovery.IncludeForks,
+			IncludeMirrors:     cfg.Discovery.IncludeMirrors,
 			Sources: discovery.SourcesConfig{
 				Orgs: discovery.OrgsSourceConfig{
 					Enabled:  cfg.Discovery.Sources.Orgs.Enabled,
@@ -169,6 +219,11 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 		disc.SetLogger(func(level, msg string, args ...interface{}) {
 			logWithLevel(level, msg, args...)
 		})
+		if cfg.GitHub.Governor.Enabled {
+			// The governor paces search for every caller of the
+			// client; a second throttle here would only slow it down.
+			disc.SetSearchThrottle(0)
+		}
 	}
 
 	// Create metrics exporter
@@ -206,38 +261,67 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 	if err != nil {
 		logging.Warn("could not open database, classification, DB-based categories, and refresh-tier first-seen disabled", "error", err)
 		classifyDB = nil
-	} else if cfg.Classification.OllamaEndpoint != "" && cfg.Classification.Model != "" {
+	} else if llm, err := classification.NewClassifier(cfg.Classification); err != nil {
+		logging.Warn("classification disabled", "error", err)
+	} else if cfg.Classification.Enabled() {
 		clsCfg := cfg.Classification
-		ollama := classification.NewOllamaClient(
-			clsCfg.OllamaEndpoint,
-			clsCfg.Model,
-			clsCfg.TimeoutMs,
-			clsCfg.Categories,
-		)
-		classifyPipeline = classification.NewPipeline(classifyDB, client, ollama, clsCfg)
+		classifyPipeline = classification.NewPipeline(classifyDB, client, llm, clsCfg)
+		for host, hc := range hostClients {
+			classifyPipeline.SetHostClient(host, hc)
+		}
+		for name, p := range forgeProviders {
+			classifyPipeline.SetForgeProvider(name, p)
+		}
+		provider := clsCfg.ResolvedProvider()
 		logging.Info("classification enabled",
-			"model", clsCfg.Model,
-			"endpoint", clsCfg.OllamaEndpoint)
+			"provider", provider.Type,
+			"model", provider.Model,
+			"endpoint", provider.Endpoint)
+		if rules, err := classification.NewRuleSet(clsCfg.Rules); err != nil {
+			logging.Warn("classification rules disabled", "error", err)
+		} else if rules != nil {
+			classifyPipeline.SetRules(rules)
+			logging.Info("classification rules loaded", "rules", rules.Len())
+		}
+		if embedder, err := classification.NewEmbedder(clsCfg); err != nil {
+			logging.Warn("embedding pre-classifier disabled", "error", err)
+		} else if embedder != nil {
+			classifyPipeline.SetEmbedder(embedder)
+			logging.Info("embedding pre-classifier enabled", "model", embedder.Model())
+		}
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	d := &Daemon{
-		cfg:        cfg,
-		daemonCfg:  daemonCfg,
-		client:     client,
-		scanner:    scanner,
-		discoverer: disc,
-		classifier: classifyPipeline,
-		exporter:   exp,
-		store:      store,
-		db:         classifyDB,
-		status:     StatusIdle,
-		startTime:  time.Now(),
-		ready:      false,
-		ctx:        ctx,
-		cancel:     cancel,
-		reloadChan: make(chan os.Signal, 1),
+		cfg:          cfg,
+		daemonCfg:    daemonCfg,
+		client:       client,
+		scanner:      scanner,
+		hostScanners: hostScanners,
+		forgeScanner: forgeScanner,
+		discoverer:   disc,
+		classifier:   classifyPipeline,
+		exporter:     exp,
+		store:        store,
+		db:           classifyDB,
+		status:       StatusIdle,
+		startTime:    time.Now(),
+		ready:        false,
+		ctx:          ctx,
+		cancel:       cancel,
+		reloadChan:   make(chan os.Signal, 1),
+		webhooks:     newWebhookReceiver(cfg.GitHub.Webhooks),
+	}
+
+	if cfg.GitHub.Planner.Enabled {
+		d.planner = newPlanner(cfg.GitHub.Planner.ReservePct)
+	}
+
+	// Persist fork / mirror / rename resolutions so discovery can
+	// canonicalize candidates before hydrating them.
+	if disc != nil && classifyDB != nil {
+		disc.SetAliasStore(classifyDB)
 	}
 
 	if exp != nil {
@@ -245,6 +329,25 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 		client.SetAPIObserver(obs)
 	}
 
+	// One on-disk archive cache serves both gharchive readers below, so
+	// an hour fetched by discovery is free for the metrics fallback and
+	// vice versa.
+	var archiveCache *gharchive.Cache
+	if cfg.Discovery.Sources.GHArchive.Enabled || cfg.Collector.GHArchive.Enabled {
+		archiveCache, err = NewArchiveCache(cfg.Collector.ArchiveCache)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		d.archiveCache = archiveCache
+		if archiveCache != nil {
+			logging.Info("gharchive archive cache enabled",
+				"dir", cfg.Collector.ArchiveCache.Dir,
+				"offline", archiveCache.Offline(),
+				"cached_archives", archiveCache.Stats().Archives)
+		}
+	}
+
 	// Wire the gharchive *discovery* source ([ISI-967], Path C epic
 	// gap fix). When discovery.sources.gharchive.enabled is true and
 	// the metadata DB is open, construct a *discovery.GHArchiveSource
@@ -280,7 +383,13 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 			if err != nil {
 				return nil, fmt.Errorf("wiring gharchive discovery source: %w", err)
 			}
+			ghArchiveSrc.SetArchiveCache(archiveCache)
 			d.ghArchiveCollector = ghArchiveSrc
+			window := time.Duration(cfg.Discovery.Sources.GHArchive.WindowHours) * time.Hour
+			if werr := warmGHArchiveWindow(ctx, ghArchiveSrc, classifyDB, cursorStore, window, time.Now()); werr != nil {
+				logging.Warn("gharchive discovery warm start from backfill rollup failed; starting cold",
+					"error", werr)
+			}
 			logging.Info("gharchive discovery source enabled",
 				"window_hours", cfg.Discovery.Sources.GHArchive.WindowHours,
 				"top_n_per_hour", cfg.Discovery.Sources.GHArchive.TopNPerHour,
@@ -305,6 +414,7 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 			GHArchiveEnabled:     true,
 			GHArchiveBaseURL:     cfg.Collector.GHArchive.BaseURL,
 			GHArchiveTimeout:     httpTimeout,
+			GHArchiveCache:       archiveCache,
 			FallbackThresholdPct: cfg.Collector.FallbackThresholdPct,
 		}
 		router := metrics.NewRouter(client, store, scoring.Weights{
@@ -317,6 +427,11 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 			IssueVelocity:     cfg.Scoring.Weights.IssueVelocity,
 		}, routerCfg, exp)
 		d.router = router
+		// Share the discovery engine's decode pass: archives it
+		// ingests feed the fallback's tracked-repo accumulator too.
+		if d.ghArchiveCollector != nil {
+			d.ghArchiveCollector.Engine().Register(router.ArchiveCollector())
+		}
 		logging.Info("gharchive fallback router enabled",
 			"threshold_pct", routerCfg.FallbackThresholdPct,
 			"base_url", routerCfg.GHArchiveBaseURL)
@@ -326,6 +441,15 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", d.handleHealth)
 	mux.HandleFunc("/status", d.handleStatus)
+	if d.webhooks != nil {
+		mux.HandleFunc("/webhooks/github", d.handleGitHubWebhook)
+	}
+	if d.db != nil {
+		d.review = newReviewAPI(d.db, classifyPipeline, cfg.Classification.Review)
+		mux.HandleFunc("/review", d.handleReviewQueue)
+		mux.HandleFunc("/review/item", d.handleReviewItem)
+		mux.HandleFunc("/review/decision", d.handleReviewDecision)
+	}
 
 	d.server = &http.Server{
 		Addr:              daemonCfg.HTTPAddr,
@@ -336,6 +460,26 @@ func New(cfg *config.Config, daemonCfg DaemonConfig) (*Daemon, error) {
 	return d, nil
 }
 
+// logAppInstallation exchanges the first GitHub App installation token
+// and logs which installation and permissions the daemon runs with. A
+// failure is only logged: every request retries the exchange.
+func (d *Daemon) logAppInstallation() {
+	ctx, cancel := context.WithTimeout(d.ctx, github.DefaultTimeout)
+	defer cancel()
+	if err := d.client.ValidateToken(ctx); err != nil {
+		logging.Warn("github app authentication failed", "error", err)
+		return
+	}
+	info, _ := d.client.AppInstallation()
+	logging.Info("github app authenticated",
+		"app_id", info.AppID,
+		"installation_id", info.InstallationID,
+		"account", info.Account,
+		"repository_selection", info.RepositorySelection,
+		"permissions", info.PermissionList(),
+		"rate_limit", d.client.RateLimitInfo().Limit)
+}
+
 // Run starts the daemon and blocks until shutdown.
 func (d *Daemon) Run() error {
 	d.setStatus(StatusStarting)
@@ -345,6 +489,10 @@ func (d *Daemon) Run() error {
 		"repos", len(d.cfg.Repositories),
 		"dry_run", d.daemonCfg.DryRun)
 
+	if d.client != nil && d.client.AuthMode() == "app" {
+		d.logAppInstallation()
+	}
+
 	// Start HTTP server
 	go func() {
 		if err := d.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -427,6 +575,9 @@ func (d *Daemon) shutdown() error {
 	if err := d.store.Save(); err != nil {
 		logging.Warn("state save error", "error", err)
 	}
+	if err := d.archiveCache.Close(); err != nil {
+		logging.Warn("archive cache index save error", "error", err)
+	}
 
 	// ISI-773 regression guard: surface the needs_reclassify backlog on the
 	// daemon's final log line so operators see drift the moment the scanner
@@ -482,17 +633,32 @@ func (d *Daemon) runScan() {
 	d.mu.RUnlock()
 
 	repos := make([]github.Repo, 0, len(repositories))
+	var forgeRepos []repository.Repo
 	seen := make(map[string]bool, len(repositories))
+	addRepo := func(fullName string) {
+		repo, ok := repository.ParseKey(fullName)
+		if !ok {
+			return
+		}
+		seen[fullName] = true
+		if repo.Provider != "" {
+			forgeRepos = append(forgeRepos, repo)
+			return
+		}
+		repos = append(repos, github.Repo{Host: repo.Host, Owner: repo.Owner, Name: repo.Name})
+	}
 	for _, tracked := range repositories {
 		// Skip excluded repos
 		if isExcluded(tracked.Repo, exclusions) {
 			continue
 		}
-		parts := strings.SplitN(tracked.Repo, "/", 2)
-		if len(parts) == 2 {
-			repos = append(repos, github.Repo{Owner: parts[0], Name: parts[1]})
-			seen[tracked.Repo] = true
+		// Config may still cite a repo by a name it was renamed away
+		// from; scan it under its canonical name.
+		fullName := d.canonicalRepoName(tracked.Repo)
+		if seen[fullName] {
+			continue
 		}
+		addRepo(fullName)
 	}
 
 	// Also include auto-discovered repos from state store
@@ -503,11 +669,18 @@ func (d *Daemon) runScan() {
 		if isExcluded(fullName, exclusions) {
 			continue
 		}
-		parts := strings.SplitN(fullName, "/", 2)
-		if len(parts) == 2 {
-			repos = append(repos, github.Repo{Owner: parts[0], Name: parts[1]})
-			seen[fullName] = true
-		}
+		addRepo(fullName)
+	}
+
+	// github.com repos take one of the paths below; enterprise-host
+	// repos are scanned afterwards by their host's scanner, and forge
+	// repos by the forge scanner.
+	repos, hostRepos := partitionByHost(repos)
+
+	// Keep the gharchive fallback's tracked set current so the hours
+	// discovery ingests between fallback cycles already cover it.
+	if d.router != nil {
+		d.router.TrackRepos(repoRefs(repos))
 	}
 
 	// Run scan — three paths:
@@ -518,21 +691,61 @@ func (d *Daemon) runScan() {
 	//   - bulk: bulk_fetch_enabled=true AND list empty → all repos on the
 	//     tiered/bulk path (Stage C / steady state).
 	//   - legacy: bulk_fetch_enabled=false → pre-T5 single-pass REST.
+	// The due tiers are worked out first so the budget planner can cost
+	// the whole cycle before any of it runs.
+	var tiers tierBatches
+	var legacy []github.Repo
+	canary := d.cfg.GitHub.BulkFetchEnabled && len(d.cfg.GitHub.BulkFetchCanaryFullNames) > 0
+	switch {
+	case canary:
+		var canaryRepos []github.Repo
+		canaryRepos, legacy = partitionByCanary(repos, d.cfg.GitHub.BulkFetchCanaryFullNames)
+		logging.Info("canary scan planning",
+			"canary_repos", len(canaryRepos),
+			"legacy_repos", len(legacy),
+			"canary_list_size", len(d.cfg.GitHub.BulkFetchCanaryFullNames))
+		if len(canaryRepos) > 0 {
+			tiers = d.planTiers(canaryRepos)
+		}
+	case d.cfg.GitHub.BulkFetchEnabled:
+		tiers = d.planTiers(repos)
+	default:
+		legacy = repos
+	}
+	d.planCycle(tiers, legacy)
+
 	var result *github.ScanResult
 	var err error
 	switch {
-	case d.cfg.GitHub.BulkFetchEnabled && len(d.cfg.GitHub.BulkFetchCanaryFullNames) > 0:
-		result, err = d.runCanaryScan(repos)
+	case canary:
+		result, err = d.runCanaryScan(tiers, legacy)
 	case d.cfg.GitHub.BulkFetchEnabled:
-		result, err = d.runTieredScan(repos)
+		result, err = d.runTieredScan(tiers)
 	default:
+		endJob := d.beginJob(jobScan)
 		result, err = d.scanner.Scan(d.ctx, repos)
+		endJob()
 	}
 	if err != nil && err != context.Canceled {
 		logging.Error("scan failed", "error", err)
 	}
+	if len(hostRepos) > 0 || len(forgeRepos) > 0 {
+		if result != nil {
+			d.runHostScans(result, hostRepos)
+			d.runForgeScans(result, forgeRepos)
+			result.EndTime = time.Now()
+		} else {
+			hostResult := &github.ScanResult{StartTime: time.Now()}
+			d.runHostScans(hostResult, hostRepos)
+			d.runForgeScans(hostResult, forgeRepos)
+			d.recordRenames(hostResult.Renamed)
+		}
+	}
 
 	if result != nil {
+		// Persist renames before anything reads the database.
+		d.recordRenames(result.Renamed)
+
 		// Normalize scores after scan
 		d.scanner.NormalizeAllScores()
 
@@ -576,9 +789,13 @@ func (d *Daemon) runScan() {
 		}
 	}
 
-	// Run discovery if enabled
+	// Run discovery if enabled and the plan left budget for it
 	if d.discoverer != nil {
-		d.runDiscovery()
+		if job, ok := d.plannedJob(jobDiscovery); ok && job.Deferred > 0 {
+			logging.Info("discovery deferred by budget planner", "steps", job.Deferred)
+		} else {
+			d.runDiscovery()
+		}
 	}
 
 	// Sync in-memory store to database so classification and metric export can find repos
@@ -673,7 +890,9 @@ func (d *Daemon) runDiscovery() {
 		}
 	}
 
+	endJob := d.beginJob(jobDiscovery)
 	results, err := d.discoverer.DiscoverAll(d.ctx)
+	endJob()
 	if err != nil && err != context.Canceled {
 		logging.Error("discovery failed", "error", err)
 		return
@@ -698,7 +917,33 @@ func (d *Daemon) runDiscovery() {
 func (d *Daemon) runClassification() {
 	logging.Info("starting classification scan")
 
-	summary, err := d.classifier.ClassifyAll(d.ctx)
+	// The README hash check is what finds repos to reclassify; run it
+	// on its own so the planner can defer it and measure it.
+	if job, ok := d.plannedJob(jobReclassify); ok && job.Deferred > 0 {
+		logging.Info("readme hash check deferred by budget planner", "repos", job.Deferred)
+	} else {
+		endJob := d.beginJob(jobReclassify)
+		if _, err := d.classifier.CheckReadmeHashes(d.ctx); err != nil {
+			logging.Warn("readme hash check failed", "error", err)
+		}
+		endJob()
+	}
+
+	limits := classification.RunLimits{SkipReadmeCheck: true}
+	if job, ok := d.plannedJob(jobClassify); ok && job.Deferred > 0 {
+		if job.Granted == 0 {
+			logging.Info("classification deferred by budget planner", "repos", job.Deferred)
+			return
+		}
+		limits.MaxRepos = job.Granted
+		logging.Info("classification capped by budget planner",
+			"repos", job.Granted,
+			"deferred", job.Deferred)
+	}
+
+	endJob := d.beginJob(jobClassify)
+	summary, err := d.classifier.ClassifyAllWithin(d.ctx, limits)
+	endJob()
 
 	// Record classification run outcome (ISI-775). A top-level error → "failed";
 	// per-repo failures only → "partial"; otherwise "success". context.Canceled
@@ -742,16 +987,22 @@ func (d *Daemon) runClassification() {
 	}
 }
 
+// repoRefs converts scanner repos to collector refs.
+func repoRefs(repos []github.Repo) []metrics.RepoRef {
+	refs := make([]metrics.RepoRef, len(repos))
+	for i, r := range repos {
+		refs[i] = metrics.RepoRef{Owner: r.Owner, Name: r.Name}
+	}
+	return refs
+}
+
 // runFallbackCollection runs the gharchive.org fallback collector when the
 // GitHub API budget headroom is below the configured threshold (ISI-815).
 // Callers should gate with IsFallbackActive() before invoking to restrict
 // the fallback to the gharchive path and avoid a redundant live-API sweep
 // when rate limit is healthy.
 func (d *Daemon) runFallbackCollection(repos []github.Repo) {
-	refs := make([]metrics.RepoRef, len(repos))
-	for i, r := range repos {
-		refs[i] = metrics.RepoRef{Owner: r.Owner, Name: r.Name}
-	}
+	refs := repoRefs(repos)
 
 	window := d.daemonCfg.Interval
 	if window == 0 {
@@ -787,8 +1038,8 @@ func (d *Daemon) exportMetrics() {
 	}
 
 	for fullName, repoState := range allStates {
-		parts := strings.SplitN(fullName, "/", 2)
-		if len(parts) != 2 {
+		repo, ok := repository.ParseKey(fullName)
+		if !ok {
 			continue
 		}
 
@@ -800,6 +1051,7 @@ func (d *Daemon) exportMetrics() {
 		// below can surface "pending" separately from "default" instead of
 		// conflating the two and hiding SQL Scan drift like the v3 incident.
 		var categories []string
+		var secondary []metrics.SecondaryLabel
 		var subcategory string
 		repoStatus := ""
 		if d.db != nil {
@@ -810,6 +1062,13 @@ func (d *Daemon) exportMetrics() {
 					categories = []string{cat}
 				}
 				subcategory = sub
+				labels, err := d.db.SecondaryLabels(fullName)
+				if err != nil {
+					logging.Debug("reading secondary labels failed", "repo", fullName, "error", err)
+				}
+				for _, l := range labels {
+					secondary = append(secondary, metrics.SecondaryLabel{Category: l.Category, Subcategory: l.Subcategory, Confidence: l.Confidence})
+				}
 			}
 		}
 		if len(categories) == 0 {
@@ -829,8 +1088,10 @@ func (d *Daemon) exportMetrics() {
 		}
 
 		repoMetrics := metrics.RepoMetrics{
-			Owner:             parts[0],
-			Name:              parts[1],
+			Provider:          repo.Provider,
+			Host:              repo.Host,
+			Owner:             repo.Owner,
+			Name:              repo.Name,
 			Language:          "", // Would need to store this in state
 			Categories:        categories,
 			Subcategory:       subcategory,
@@ -847,6 +1108,7 @@ func (d *Daemon) exportMetrics() {
 			IssueVelocity:     repoState.IssueVelocity,
 			ContributorGrowth: repoState.ContributorGrowth,
 		}
+		repoMetrics.SecondaryLabels = secondary
 
 		d.exporter.RecordRepoMetrics(d.ctx, repoMetrics)
 	}
@@ -914,15 +1176,17 @@ func (d *Daemon) syncStoreToDatabase() {
 	allStates := d.store.AllRepoStates()
 	synced := 0
 	for fullName, rs := range allStates {
-		parts := strings.SplitN(fullName, "/", 2)
-		if len(parts) != 2 {
+		repo, ok := repository.ParseKey(fullName)
+		if !ok {
 			continue
 		}
 
 		record := &database.RepoRecord{
 			FullName:              fullName,
-			Owner:                 parts[0],
-			Name:                  parts[1],
+			Provider:              repo.Provider,
+			Host:                  repo.Host,
+			Owner:                 repo.Owner,
+			Name:                  repo.Name,
 			Stars:                 rs.Stars,
 			StarsPrev:             rs.StarsPrev,
 			Forks:                 rs.Forks,
@@ -989,21 +1253,32 @@ func (d *Daemon) reloadConfig() {
 	d.mu.Unlock()
 
 	// Update scanner weights
-	d.scanner.SetScoringWeights(scoring.Weights{
-		StarVelocity:      newCfg.Scoring.Weights.StarVelocity,
-		StarAcceleration:  newCfg.Scoring.Weights.StarAcceleration,
-		ForkVelocity:      newCfg.Scoring.Weights.ForkVelocity,
-		ReleaseCadence:    newCfg.Scoring.Weights.ReleaseCadence,
-		ContributorGrowth: newCfg.Scoring.Weights.ContributorGrowth,
-		PRVelocity:        newCfg.Scoring.Weights.PRVelocity,
-		IssueVelocity:     newCfg.Scoring.Weights.IssueVelocity,
-	})
+	d.scanner.SetScoringWeights(scoringWeights(newCfg))
+	for _, hs := range d.hostScanners {
+		hs.SetScoringWeights(scoringWeights(newCfg))
+	}
+	if d.forgeScanner != nil {
+		d.forgeScanner.SetScoringWeights(scoringWeights(newCfg))
+	}
 
 	logging.Info("config reloaded",
 		"repos", len(newCfg.Repositories),
 		"topics", len(newCfg.Discovery.Topics))
 }
 
+// scoringWeights maps the configured scoring weights onto the scanner's.
+func scoringWeights(cfg *config.Config) scoring.Weights {
+	return scoring.Weights{
+		StarVelocity:      cfg.Scoring.Weights.StarVelocity,
+		StarAcceleration:  cfg.Scoring.Weights.StarAcceleration,
+		ForkVelocity:      cfg.Scoring.Weights.ForkVelocity,
+		ReleaseCadence:    cfg.Scoring.Weights.ReleaseCadence,
+		ContributorGrowth: cfg.Scoring.Weights.ContributorGrowth,
+		PRVelocity:        cfg.Scoring.Weights.PRVelocity,
+		IssueVelocity:     cfg.Scoring.Weights.IssueVelocity,
+	}
+}
+
 // isExcluded checks if a repo matches any exclusion pattern.
 func isExcluded(fullName string, exclusions []string) bool {
 	for _, pattern := range exclusions {
@@ -1021,11 +1296,20 @@ func isExcluded(fullName string, exclusions []string) bool {
 //   - Wildcard prefix: "*/repo" matches repo from any owner
 //   - Full wildcard: "*/*" matches everything
 //
-// Names must be valid "owner/repo" format (exactly one slash).
+// Names must be valid "owner/repo" format (exactly one slash), or
+// "host/owner/repo" for GitHub Enterprise repos, matched segment by
+// segment against a three-segment pattern ("ghe.corp/*/*"). Forge repos
+// ("gitlab:group/sub/repo") only match patterns with a provider prefix,
+// where the provider may be "*" and the owner segment covers the whole
+// namespace ("gitlab:group/sub/*", "*:*/repo").
 func MatchesPattern(name, pattern string) bool {
-	// Validate name format - must have exactly one slash
+	if provider, rest := repository.SplitProvider(name); provider != "" {
+		return matchesForgePattern(provider, rest, pattern)
+	}
+
+	// Validate name format - one slash, or two for a host-qualified name
 	nameParts := strings.Split(name, "/")
-	if len(nameParts) != 2 {
+	if len(nameParts) != 2 && len(nameParts) != 3 {
 		return false
 	}
 
@@ -1037,19 +1321,39 @@ func MatchesPattern(name, pattern string) bool {
 	// Handle wildcard patterns
 	if strings.Contains(pattern, "*") {
 		patternParts := strings.Split(pattern, "/")
-		if len(patternParts) != 2 {
+		if len(patternParts) != len(nameParts) {
 			return false
 		}
 
-		ownerMatch := patternParts[0] == "*" || patternParts[0] == nameParts[0]
-		repoMatch := patternParts[1] == "*" || patternParts[1] == nameParts[1]
-
-		return ownerMatch && repoMatch
+		for i, p := range patternParts {
+			if p != "*" && p != nameParts[i] {
+				return false
+			}
+		}
+		return true
 	}
 
 	return false
 }
 
+// matchesForgePattern matches the namespace/repo part of a forge repo
+// on provider against pattern.
+func matchesForgePattern(provider, rest, pattern string) bool {
+	patternProvider, patternRest := repository.SplitProvider(pattern)
+	if patternProvider != "*" && patternProvider != provider {
+		return false
+	}
+	i := strings.LastIndex(rest, "/")
+	j := strings.LastIndex(patternRest, "/")
+	if i <= 0 || j < 0 {
+		return false
+	}
+	owner, repo := rest[:i], rest[i+1:]
+	patternOwner, patternRepo := patternRest[:j], patternRest[j+1:]
+	return (patternOwner == "*" || patternOwner == owner) &&
+		(patternRepo == "*" || patternRepo == repo)
+}
+
 // setStatus updates the daemon status.
 func (d *Daemon) setStatus(s Status) {
 	d.mu.Lock()
@@ -1092,6 +1396,9 @@ type StatusResponse struct {
 	RateLimitRemaining int    `json:"rate_limit_remaining"`
 	Uptime             string `json:"uptime"`
 	CollectorBackend   string `json:"collector_backend,omitempty"`
+	// Plan is the latest cycle's API budget plan with each job's
+	// actual usage so far; absent when github.planner is disabled.
+	Plan *CyclePlan `json:"plan,omitempty"`
 }
 
 // handleStatus handles the /status endpoint.
@@ -1112,14 +1419,21 @@ func (d *Daemon) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if d.router != nil {
 		resp.CollectorBackend = d.router.Status()
 	}
+	resp.Plan = d.plan.clone()
 	d.mu.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
-// runTieredScan classifies the candidate repos into refresh tiers and
-// dispatches each bucket to the appropriate scanner path:
+// tierBatches are the repos due this cycle, split by scan path.
+type tierBatches struct {
+	bulk []github.Repo // hot, warm and new tiers
+	cold []github.Repo
+}
+
+// planTiers classifies the candidate repos into refresh tiers and
+// returns the requested ones that are due, by scan path:
 //   - TierHot / TierWarm / TierNew: GraphQL bulk fetch (≤1 call per 50
 //     repos for metadata), plus per-repo REST activity collection.
 //   - TierCold: existing REST path with conditional GET (preserves the
@@ -1128,7 +1442,7 @@ func (d *Daemon) handleStatus(w http.ResponseWriter, r *http.Request) {
 // Per the ISI-709 plan §5 this keeps steady-state calls ≤ ~1,000/hr
 // at 3,000 repos and well under the 5000/hr budget with headroom for
 // discovery bursts.
-func (d *Daemon) runTieredScan(requested []github.Repo) (*github.ScanResult, error) {
+func (d *Daemon) planTiers(requested []github.Repo) tierBatches {
 	tierCfg := tierConfigFromYAML(d.cfg.GitHub.Tiering)
 	candidates := d.buildTierCandidates()
 	assignments := github.ClassifyAll(candidates, time.Now(), tierCfg)
@@ -1149,25 +1463,40 @@ func (d *Daemon) runTieredScan(requested []github.Repo) (*github.ScanResult, err
 	}
 
 	// Split due repos by tier.
-	var bulkBatch []github.Repo
-	var coldBatch []github.Repo
+	var batches tierBatches
 	for _, a := range github.DueRepos(assignments) {
 		r, ok := requestedSet[a.FullName]
 		if !ok {
 			continue
 		}
 		if a.Tier == github.TierCold {
-			coldBatch = append(coldBatch, r)
+			batches.cold = append(batches.cold, r)
 		} else {
-			bulkBatch = append(bulkBatch, r)
+			batches.bulk = append(batches.bulk, r)
 		}
 	}
+	return batches
+}
+
+// runTieredScan scans the due tiers from planTiers, each over its own
+// path. Cold-tier repos the budget plan deferred stay due and are
+// picked up by a later cycle.
+func (d *Daemon) runTieredScan(batches tierBatches) (*github.ScanResult, error) {
+	coldBatch, bulkBatch := batches.cold, batches.bulk
+	if job, ok := d.plannedJob(jobScanCold); ok && job.Granted < len(coldBatch) {
+		logging.Info("cold-tier repos deferred by budget planner",
+			"scanned", job.Granted,
+			"deferred", len(coldBatch)-job.Granted)
+		coldBatch = coldBatch[:job.Granted]
+	}
 
 	combined := &github.ScanResult{StartTime: time.Now()}
 
 	// Cold tier via REST + conditional GET.
 	if len(coldBatch) > 0 {
+		endJob := d.beginJob(jobScanCold)
 		cr, err := d.scanner.Scan(d.ctx, coldBatch)
+		endJob()
 		if err != nil && err != context.Canceled {
 			logging.Warn("cold-tier scan returned error", "error", err)
 		}
@@ -1180,12 +1509,15 @@ func (d *Daemon) runTieredScan(requested []github.Repo) (*github.ScanResult, err
 			combined.RepoGone += cr.RepoGone
 			combined.FailedRepos = append(combined.FailedRepos, cr.FailedRepos...)
 			combined.GoneRepos = append(combined.GoneRepos, cr.GoneRepos...)
+			mergeRenamed(combined, cr.Renamed)
 		}
 	}
 
 	// Hot/warm/new tiers via GraphQL bulk fetch.
 	if len(bulkBatch) > 0 {
+		endJob := d.beginJob(jobScanBulk)
 		br, err := d.scanner.ScanBulk(d.ctx, bulkBatch)
+		endJob()
 		if err != nil && err != context.Canceled {
 			logging.Warn("bulk-tier scan returned error", "error", err)
 		}
@@ -1198,6 +1530,7 @@ func (d *Daemon) runTieredScan(requested []github.Repo) (*github.ScanResult, err
 			combined.RepoGone += br.RepoGone
 			combined.FailedRepos = append(combined.FailedRepos, br.FailedRepos...)
 			combined.GoneRepos = append(combined.GoneRepos, br.GoneRepos...)
+			mergeRenamed(combined, br.Renamed)
 		}
 	}
 
@@ -1209,29 +1542,22 @@ func (d *Daemon) runTieredScan(requested []github.Repo) (*github.ScanResult, err
 	return combined, nil
 }
 
-// runCanaryScan partitions the requested repos into a canary set
-// (matching d.cfg.GitHub.BulkFetchCanaryFullNames, case-insensitive) and
-// a legacy set, then dispatches each to the appropriate scan path:
-//   - canary set → runTieredScan (T5 GraphQL bulk + tiered cadence)
+// runCanaryScan scans the two halves of a canary rollout, which runScan
+// partitions by d.cfg.GitHub.BulkFetchCanaryFullNames (case-insensitive):
+//   - canary set → runTieredScan (T5 GraphQL bulk + tiered cadence),
+//     passed in as its due tiers
 //   - legacy set → scanner.Scan (pre-T5 REST single-pass)
 //
 // Used for staged prod canary rollout (ISI-792 / ISI-716): Stage A 10%,
 // Stage B 50%, Stage C 100% (Stage C uses an empty list + bulk_fetch
 // flag on, which routes through runTieredScan directly without this
 // partition step).
-func (d *Daemon) runCanaryScan(repos []github.Repo) (*github.ScanResult, error) {
-	canaryRepos, legacyRepos := partitionByCanary(repos, d.cfg.GitHub.BulkFetchCanaryFullNames)
-
-	logging.Info("canary scan planning",
-		"canary_repos", len(canaryRepos),
-		"legacy_repos", len(legacyRepos),
-		"canary_list_size", len(d.cfg.GitHub.BulkFetchCanaryFullNames))
-
+func (d *Daemon) runCanaryScan(canaryTiers tierBatches, legacyRepos []github.Repo) (*github.ScanResult, error) {
 	combined := &github.ScanResult{StartTime: time.Now()}
 
 	// Canary set → tiered/bulk path.
-	if len(canaryRepos) > 0 {
-		cr, err := d.runTieredScan(canaryRepos)
+	if len(canaryTiers.bulk) > 0 || len(canaryTiers.cold) > 0 {
+		cr, err := d.runTieredScan(canaryTiers)
 		if err != nil && err != context.Canceled {
 			logging.Warn("canary tiered scan returned error", "error", err)
 		}
@@ -1244,12 +1570,15 @@ func (d *Daemon) runCanaryScan(repos []github.Repo) (*github.ScanResult, error)
 			combined.RepoGone += cr.RepoGone
 			combined.FailedRepos = append(combined.FailedRepos, cr.FailedRepos...)
 			combined.GoneRepos = append(combined.GoneRepos, cr.GoneRepos...)
+			mergeRenamed(combined, cr.Renamed)
 		}
 	}
 
 	// Legacy set → pre-T5 REST + conditional path.
 	if len(legacyRepos) > 0 {
+		endJob := d.beginJob(jobScan)
 		lr, err := d.scanner.Scan(d.ctx, legacyRepos)
+		endJob()
 		if err != nil && err != context.Canceled {
 			logging.Warn("canary legacy scan returned error", "error", err)
 		}
@@ -1262,6 +1591,7 @@ func (d *Daemon) runCanaryScan(repos []github.Repo) (*github.ScanResult, error)
 			combined.RepoGone += lr.RepoGone
 			combined.FailedRepos = append(combined.FailedRepos, lr.FailedRepos...)
 			combined.GoneRepos = append(combined.GoneRepos, lr.GoneRepos...)
+			mergeRenamed(combined, lr.Renamed)
 		}
 	}
 
@@ -1269,6 +1599,19 @@ func (d *Daemon) runCanaryScan(repos []github.Repo) (*github.ScanResult, error)
 	return combined, nil
 }
 
+// mergeRenamed folds a sub-scan's rename map into the combined result.
+func mergeRenamed(dst *github.ScanResult, renamed map[string]string) {
+	if len(renamed) == 0 {
+		return
+	}
+	if dst.Renamed == nil {
+		dst.Renamed = make(map[string]string, len(renamed))
+	}
+	for from, to := range renamed {
+		dst.Renamed[from] = to
+	}
+}
+
 // scanPathLabel returns the "path" attribute value attached to the
 // github.scan.duration histogram for the cycle just completed. The
 // value mirrors the switch in runScan.
@@ -92,17 +92,23 @@ func newMockGitHubServer() *httptest.Server {
 			w.Write([]byte(`[]`))
 			return
 		}
-		w.Write([]byte(`{
-			"owner": {"login": "owner"},
-			"name": "repo",
-			"full_name": "owner/repo",
+		// Echo the requested name: a different full_name reads as a
+		// rename redirect and would fold every repo into one.
+		owner, name := "owner", "repo"
+		if parts := strings.Split(strings.TrimPrefix(path, "/repos/"), "/"); len(parts) == 2 {
+			owner, name = parts[0], parts[1]
+		}
+		fmt.Fprintf(w, `{
+			"owner": {"login": %q},
+			"name": %q,
+			"full_name": %q,
 			"stargazers_count": 100,
 			"forks_count": 10,
 			"open_issues_count": 5,
 			"language": "Go",
 			"topics": [],
 			"description": ""
-		}`))
+		}`, owner, name, owner+"/"+name)
 	}))
 }
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/hrexed/github-radar/internal/config"
 	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/github"
 )
 
 func TestDefaultDaemonConfig(t *testing.T) {
@@ -39,6 +40,17 @@ func TestMatchesPattern(t *testing.T) {
 		{"any/repo", "*/repo", true},
 		{"any/other", "*/repo", false},
 		{"any/thing", "*/*", true},
+		{"ghe.corp/team/svc", "ghe.corp/team/svc", true},
+		{"ghe.corp/team/svc", "ghe.corp/*/*", true},
+		{"ghe.corp/team/svc", "ghe.other/*/*", false},
+		{"ghe.corp/team/svc", "*/*", false},
+		{"gitlab:gnome/world/podcasts", "gitlab:gnome/world/podcasts", true},
+		{"gitlab:gnome/world/podcasts", "gitlab:gnome/world/*", true},
+		{"gitlab:gnome/world/podcasts", "gitlab:gnome/*", false},
+		{"gitlab:gnome/world/podcasts", "*:*/podcasts", true},
+		{"gitlab:gnome/world/podcasts", "codeberg:*/*", false},
+		{"gitlab:gnome/world/podcasts", "*/*", false},
+		{"codeberg:forgejo/forgejo", "codeberg:forgejo/*", true},
 	}
 
 	for _, tc := range tests {
@@ -49,6 +61,22 @@ func TestMatchesPattern(t *testing.T) {
 	}
 }
 
+func TestPartitionByHost(t *testing.T) {
+	repos := []github.Repo{
+		{Owner: "a", Name: "one"},
+		{Host: "ghe.corp", Owner: "team", Name: "svc"},
+		{Owner: "b", Name: "two"},
+		{Host: "ghe.corp", Owner: "team", Name: "lib"},
+	}
+	public, byHost := partitionByHost(repos)
+	if len(public) != 2 || public[0].Name != "one" || public[1].Name != "two" {
+		t.Errorf("public = %+v", public)
+	}
+	if got := byHost["ghe.corp"]; len(got) != 2 || got[0].Name != "svc" || got[1].Name != "lib" {
+		t.Errorf("byHost[ghe.corp] = %+v", got)
+	}
+}
+
 func TestHealthEndpoint(t *testing.T) {
 	cfg := &config.Config{
 		GitHub: config.GithubConfig{
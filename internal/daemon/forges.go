@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/forge"
+	"github.com/hrexed/github-radar/internal/gitea"
+	"github.com/hrexed/github-radar/internal/github"
+	"github.com/hrexed/github-radar/internal/gitlab"
+	"github.com/hrexed/github-radar/internal/logging"
+	"github.com/hrexed/github-radar/internal/repository"
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// forges.go scans repos on GitLab and Gitea/Forgejo forges (forges).
+// One forge.Scanner holds a provider per configured forge and shares the
+// daemon's state store; forge repos are keyed provider:owner/repo and
+// scored, normalized, stored and exported next to GitHub repos. Like
+// enterprise-host repos they take the plain per-repo path: tiering,
+// the gharchive fallback, webhooks and discovery only cover github.com.
+
+// NewForgeProviders builds a provider per configured forge, keyed by
+// forge name.
+func NewForgeProviders(cfg *config.Config) map[string]forge.Provider {
+	if len(cfg.Forges) == 0 {
+		return nil
+	}
+	providers := make(map[string]forge.Provider, len(cfg.Forges))
+	for _, f := range cfg.Forges {
+		switch f.Type {
+		case config.ForgeTypeGitLab:
+			providers[f.Name] = gitlab.NewClient(f.Name, f.APIURL, f.Token)
+		case config.ForgeTypeGitea:
+			providers[f.Name] = gitea.NewClient(f.Name, f.APIURL, f.Token)
+		}
+	}
+	return providers
+}
+
+// newForgeScanner wraps providers in a scanner over store; nil when no
+// forge is configured.
+func newForgeScanner(providers map[string]forge.Provider, store *state.Store, cfg *config.Config) *forge.Scanner {
+	if len(providers) == 0 {
+		return nil
+	}
+	s := forge.NewScanner(store)
+	for _, p := range providers {
+		s.AddProvider(p)
+	}
+	s.SetScoringWeights(scoringWeights(cfg))
+	s.SetLogger(func(level, msg string, args ...interface{}) {
+		logWithLevel(level, msg, args...)
+	})
+	return s
+}
+
+// runForgeScans scans repos on non-GitHub forges and folds the results
+// into combined. Without any forge configured they are skipped with a
+// warning.
+func (d *Daemon) runForgeScans(combined *github.ScanResult, repos []repository.Repo) {
+	if len(repos) == 0 {
+		return
+	}
+	if d.forgeScanner == nil {
+		logging.Warn("skipping repos on unconfigured forges",
+			"repos", len(repos),
+			"hint", "add the forge under forges")
+		return
+	}
+	fr, err := d.forgeScanner.Scan(d.ctx, repos)
+	if err != nil && err != context.Canceled {
+		logging.Warn("forge scan returned error", "error", err)
+	}
+	combined.Total += fr.Total
+	combined.Successful += fr.Successful
+	combined.Failed += fr.Failed
+	combined.Skipped += fr.Skipped
+	combined.Updated += fr.Successful
+	combined.RepoGone += fr.RepoGone
+	combined.FailedRepos = append(combined.FailedRepos, fr.FailedRepos...)
+	combined.GoneRepos = append(combined.GoneRepos, fr.GoneRepos...)
+}
@@ -53,10 +53,11 @@ func newGHArchiveDiscoveryHooks(ctx context.Context, dm *metrics.DiscoveryMeters
 // supplied meters ([ISI-1005]). A nil DiscoveryMeters produces empty
 // hooks (no-ops).
 //
-// Two closures are wired:
+// Three closures are wired:
 //   - OnCandidateAdmitted → DiscoveryMeters.AddCandidates(ctx, eventType, 1)
 //   - OnDedupComplete → computes ratio = dropped / total (guarding
 //     divide-by-zero), then DiscoveryMeters.RecordDedupRatio(ctx, ratio)
+//   - OnTrackedRelease → DiscoveryMeters.AddMajorRelease(ctx, kind)
 func newGHArchivePipelineHooks(ctx context.Context, dm *metrics.DiscoveryMeters) discovery.GHArchivePipelineHooks {
 	if dm == nil {
 		return discovery.GHArchivePipelineHooks{}
@@ -71,5 +72,8 @@ func newGHArchivePipelineHooks(ctx context.Context, dm *metrics.DiscoveryMeters)
 				dm.RecordDedupRatio(ctx, ratio)
 			}
 		},
+		OnTrackedRelease: func(sig discovery.GHArchiveReleaseSignal) {
+			dm.AddMajorRelease(ctx, string(sig.Kind))
+		},
 	}
 }
@@ -0,0 +1,216 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/discovery"
+	"github.com/hrexed/github-radar/internal/logging"
+)
+
+// gharchive_history.go persists what a gharchive backfill sees:
+//
+//   - per-hour star / fork / pull-request / issue event counts for
+//     tracked repos, into repo_activity_history (daily history is a
+//     GROUP BY over it);
+//   - the discovery rollup for the trailing window, into
+//     gharchive_hour_rollups, which warmGHArchiveWindow loads on the
+//     daemon's first start so discovery does not wait a full window
+//     for its first candidates.
+
+// historyEventTypes are the event types backfill must keep on top of
+// the discovery filter to fill repo_activity_history.
+var historyEventTypes = []string{"WatchEvent", "ForkEvent", "PullRequestEvent", "IssuesEvent"}
+
+// GHArchiveHistoryRollup is the discovery.GHArchiveRollupStore used by
+// `github-radar backfill`. Construct with NewGHArchiveHistoryRollup.
+type GHArchiveHistoryRollup struct {
+	db *database.DB
+	// tracked maps lower-cased repo names (including renamed aliases)
+	// to the canonical name history is recorded under.
+	tracked map[string]string
+	// rollupTypes are the discovery event types; rollup counts ignore
+	// the extra history-only types so they match what the live
+	// collector would have aggregated.
+	rollupTypes map[string]bool
+	// rollupSince is the first hour written to the discovery rollup.
+	rollupSince time.Time
+}
+
+// NewGHArchiveHistoryRollup builds the backfill rollup store. History
+// is recorded for tracked repos (and for their renamed aliases, under
+// the current name); rollup rows are written for hours at or after
+// rollupSince only, since older hours can never re-enter the window.
+func NewGHArchiveHistoryRollup(db *database.DB, tracked []string, discoveryTypes []string, rollupSince time.Time) (*GHArchiveHistoryRollup, error) {
+	r := &GHArchiveHistoryRollup{
+		db:          db,
+		tracked:     make(map[string]string, len(tracked)),
+		rollupTypes: make(map[string]bool, len(discoveryTypes)),
+		rollupSince: rollupSince.UTC().Truncate(time.Hour),
+	}
+	for _, name := range tracked {
+		r.tracked[strings.ToLower(name)] = name
+	}
+	for _, t := range discoveryTypes {
+		r.rollupTypes[t] = true
+	}
+
+	aliases, err := db.AllRepoAliases()
+	if err != nil {
+		return nil, fmt.Errorf("loading repo aliases: %w", err)
+	}
+	for _, a := range aliases {
+		if a.Reason != database.AliasReasonRenamed {
+			continue
+		}
+		if canonical, ok := r.tracked[strings.ToLower(a.Canonical)]; ok {
+			r.tracked[strings.ToLower(a.Alias)] = canonical
+		}
+	}
+	return r, nil
+}
+
+// WriteHourRollup implements discovery.GHArchiveRollupStore.
+func (r *GHArchiveHistoryRollup) WriteHourRollup(_ context.Context, archive string, aggs []discovery.GHArchiveHourAggregate) error {
+	var (
+		history []database.RepoActivityHour
+		rollup  []database.HourRollup
+	)
+	for _, a := range aggs {
+		if canonical, ok := r.tracked[strings.ToLower(a.RepoName)]; ok {
+			history = append(history, database.RepoActivityHour{
+				RepoName:     canonical,
+				Hour:         a.HourBucket,
+				Stars:        a.PerEventTyp["WatchEvent"],
+				Forks:        a.PerEventTyp["ForkEvent"],
+				PullRequests: a.PerEventTyp["PullRequestEvent"],
+				Issues:       a.PerEventTyp["IssuesEvent"],
+			})
+		}
+
+		if a.HourBucket.Before(r.rollupSince) {
+			continue
+		}
+		perType := make(map[string]int, len(a.PerEventTyp))
+		count := 0
+		for t, n := range a.PerEventTyp {
+			if r.rollupTypes[t] {
+				perType[t] = n
+				count += n
+			}
+		}
+		if count > 0 {
+			rollup = append(rollup, database.HourRollup{
+				RepoName:   a.RepoName,
+				Hour:       a.HourBucket,
+				EventCount: count,
+				PerType:    perType,
+			})
+		}
+	}
+
+	if err := r.db.UpsertRepoActivity(history); err != nil {
+		return fmt.Errorf("recording history for %s: %w", archive, err)
+	}
+	if err := r.db.WriteHourRollups(rollup); err != nil {
+		return fmt.Errorf("recording rollup for %s: %w", archive, err)
+	}
+	return nil
+}
+
+// BackfillGHArchiveConfig derives the collector config a backfill runs
+// with: the discovery collector's settings plus the history event types,
+// reading from localDir first and baseURL (empty = gharchive.org)
+// otherwise.
+func BackfillGHArchiveConfig(cfg config.DiscoveryGHArchiveConfig, baseURL, localDir string) discovery.GHArchiveConfig {
+	out := mapDiscoveryGHArchiveCollectorConfig(cfg)
+	out.BaseURL = baseURL
+	out.LocalDir = localDir
+
+	types := out.EventTypes
+	if len(types) == 0 {
+		types = discovery.DefaultGHArchiveEventTypes
+	}
+	seen := make(map[string]bool, len(types)+len(historyEventTypes))
+	merged := make([]string, 0, len(types)+len(historyEventTypes))
+	for _, t := range append(append([]string{}, types...), historyEventTypes...) {
+		if !seen[t] {
+			seen[t] = true
+			merged = append(merged, t)
+		}
+	}
+	out.EventTypes = merged
+	return out
+}
+
+// DiscoveryEventTypes returns the event types the live discovery
+// collector aggregates for cfg.
+func DiscoveryEventTypes(cfg config.DiscoveryGHArchiveConfig) []string {
+	if len(cfg.EventTypes) > 0 {
+		return cfg.EventTypes
+	}
+	return discovery.DefaultGHArchiveEventTypes
+}
+
+// warmGHArchiveWindow seeds a freshly wired live collector from the
+// discovery rollup a backfill left behind. It only acts on first start
+// (empty cursor): the window is restored from rollup rows younger than
+// window and the cursor is moved to the newest restored hour, so Run
+// continues from there instead of re-downloading the whole window.
+func warmGHArchiveWindow(ctx context.Context, src *discovery.GHArchiveSource, db *database.DB, cursor discovery.GHArchiveCursorStore, window time.Duration, now time.Time) error {
+	if src == nil || db == nil || cursor == nil {
+		return nil
+	}
+	c, err := cursor.GetCursor(ctx)
+	if err != nil {
+		return fmt.Errorf("loading gharchive cursor: %w", err)
+	}
+	if !c.IsZero() {
+		return nil
+	}
+	if window <= 0 {
+		window = discovery.DefaultGHArchiveWindow
+	}
+
+	rows, err := db.HourRollupsSince(now.Add(-window))
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	aggs := make([]discovery.GHArchiveHourAggregate, 0, len(rows))
+	var newest time.Time
+	for _, r := range rows {
+		aggs = append(aggs, discovery.GHArchiveHourAggregate{
+			RepoName:    r.RepoName,
+			HourBucket:  r.Hour,
+			EventCount:  r.EventCount,
+			PerEventTyp: r.PerType,
+		})
+		if r.Hour.After(newest) {
+			newest = r.Hour
+		}
+	}
+	src.RestoreWindow(aggs)
+
+	if err := cursor.SetCursor(ctx, discovery.GHArchiveCursor{
+		LastProcessedArchive: discovery.GHArchiveArchiveName(newest),
+		CompletedAt:          now.UTC(),
+	}); err != nil {
+		return fmt.Errorf("advancing gharchive cursor after warm start: %w", err)
+	}
+	logging.Info("gharchive discovery window restored from backfill rollup",
+		"rows", len(rows), "through", newest.Format(time.RFC3339))
+
+	// Rows older than the window can never be restored again.
+	if _, err := db.PruneHourRollups(now.Add(-window)); err != nil {
+		logging.Warn("pruning gharchive rollup failed", "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/discovery"
+)
+
+func openHistoryDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.Open(t.TempDir() + "/scanner.db")
+	if err != nil {
+		t.Fatalf("database.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// History goes to tracked repos (renamed aliases folded into the current
+// name); the rollup keeps only discovery event types inside the window.
+func TestGHArchiveHistoryRollup_WriteHourRollup(t *testing.T) {
+	db := openHistoryDB(t)
+	if err := db.SetRepoAlias("acme/old-name", "acme/app", database.AliasReasonRenamed); err != nil {
+		t.Fatal(err)
+	}
+	hour := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	r, err := NewGHArchiveHistoryRollup(db, []string{"acme/app"}, []string{"WatchEvent"}, hour)
+	if err != nil {
+		t.Fatalf("NewGHArchiveHistoryRollup: %v", err)
+	}
+	err = r.WriteHourRollup(context.Background(), "2026-02-01-10", []discovery.GHArchiveHourAggregate{
+		{RepoName: "acme/old-name", HourBucket: hour, EventCount: 5, PerEventTyp: map[string]int{"WatchEvent": 3, "IssuesEvent": 2}},
+		{RepoName: "someone/else", HourBucket: hour, EventCount: 1, PerEventTyp: map[string]int{"IssuesEvent": 1}},
+		{RepoName: "someone/starred", HourBucket: hour, EventCount: 2, PerEventTyp: map[string]int{"WatchEvent": 2}},
+		{RepoName: "acme/app", HourBucket: hour.Add(-time.Hour), EventCount: 1, PerEventTyp: map[string]int{"ForkEvent": 1}},
+	})
+	if err != nil {
+		t.Fatalf("WriteHourRollup: %v", err)
+	}
+
+	daily, err := db.RepoDailyActivityRange("acme/app", hour, hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daily) != 1 || daily[0].Stars != 3 || daily[0].Issues != 2 || daily[0].Forks != 1 {
+		t.Errorf("history = %+v, want stars=3 issues=2 forks=1", daily)
+	}
+
+	rollup, err := db.HourRollupsSince(hour.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollup) != 2 {
+		t.Fatalf("rollup = %+v, want acme/old-name and someone/starred only", rollup)
+	}
+	for _, row := range rollup {
+		if row.PerType["IssuesEvent"] != 0 {
+			t.Errorf("rollup row %+v kept a non-discovery event type", row)
+		}
+	}
+}
+
+func TestWarmGHArchiveWindow(t *testing.T) {
+	db := openHistoryDB(t)
+	now := time.Date(2026, 2, 2, 12, 30, 0, 0, time.UTC)
+	last := time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)
+	if err := db.WriteHourRollups([]database.HourRollup{
+		{RepoName: "acme/app", Hour: last.Add(-time.Hour), EventCount: 7, PerType: map[string]int{"WatchEvent": 7}},
+		{RepoName: "acme/app", Hour: last, EventCount: 8, PerType: map[string]int{"WatchEvent": 8}},
+		{RepoName: "stale/repo", Hour: now.Add(-72 * time.Hour), EventCount: 99, PerType: map[string]int{"WatchEvent": 99}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cursor := discovery.NewMemoryCursorStore()
+	src := discovery.NewGHArchiveSource(mapDiscoveryGHArchiveCollectorConfig(config.DiscoveryGHArchiveConfig{WindowHours: 24}), cursor, nil, discovery.GHArchiveHooks{})
+
+	if err := warmGHArchiveWindow(context.Background(), src, db, cursor, 24*time.Hour, now); err != nil {
+		t.Fatalf("warmGHArchiveWindow: %v", err)
+	}
+	act, ok := src.RepoActivity("acme/app")
+	if !ok || act.TotalEvents != 15 {
+		t.Errorf("acme/app = %+v, %v; want 15 restored events", act, ok)
+	}
+	if _, ok := src.RepoActivity("stale/repo"); ok {
+		t.Error("stale/repo is older than the window and must not be restored")
+	}
+	c, _ := cursor.GetCursor(context.Background())
+	if c.LastProcessedArchive != "2026-02-02-09" {
+		t.Errorf("cursor = %q, want 2026-02-02-09", c.LastProcessedArchive)
+	}
+
+	// An existing cursor means the live collector already owns the
+	// window; a second call is a no-op.
+	if err := db.WriteHourRollups([]database.HourRollup{{RepoName: "late/repo", Hour: last, EventCount: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := warmGHArchiveWindow(context.Background(), src, db, cursor, 24*time.Hour, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := src.RepoActivity("late/repo"); ok {
+		t.Error("warm start ran with a cursor already set")
+	}
+}
+
+func TestBackfillGHArchiveConfig_AddsHistoryTypes(t *testing.T) {
+	got := BackfillGHArchiveConfig(config.DiscoveryGHArchiveConfig{EventTypes: []string{"WatchEvent", "PushEvent"}}, "http://mirror", "/tmp/archives")
+	want := []string{"WatchEvent", "PushEvent", "ForkEvent", "PullRequestEvent", "IssuesEvent"}
+	if len(got.EventTypes) != len(want) {
+		t.Fatalf("EventTypes = %v, want %v", got.EventTypes, want)
+	}
+	for i := range want {
+		if got.EventTypes[i] != want[i] {
+			t.Errorf("EventTypes = %v, want %v", got.EventTypes, want)
+			break
+		}
+	}
+	if got.BaseURL != "http://mirror" || got.LocalDir != "/tmp/archives" {
+		t.Errorf("BaseURL/LocalDir = %q/%q", got.BaseURL, got.LocalDir)
+	}
+}
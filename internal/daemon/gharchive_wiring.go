@@ -7,6 +7,7 @@ import (
 
 	"github.com/hrexed/github-radar/internal/config"
 	"github.com/hrexed/github-radar/internal/discovery"
+	"github.com/hrexed/github-radar/internal/gharchive"
 	"github.com/hrexed/github-radar/internal/metrics"
 )
 
@@ -30,12 +31,12 @@ import (
 //
 //   - mapDiscoveryGHArchiveConfig produces the
 //     discovery.GHArchiveSourceConfig that gates Source (5) promotion
-//     in DiscoverAll (TopN, ActivityFloor, MinStarsGate). This is the
-//     shape that lands in the Discoverer's `Config.Sources.GHArchive`
+//     in DiscoverAll (TopN, ActivityFloor, RankBy, MinStarsGate, ReleaseSignals,
+//     MaxReleaseCandidates). This is the shape that lands in the Discoverer's `Config.Sources.GHArchive`
 //     block at NewDiscoverer time.
 //   - mapDiscoveryGHArchiveCollectorConfig produces the
 //     discovery.GHArchiveConfig consumed by NewGHArchiveSource
-//     (Window, EventTypes — every other knob falls through to
+//     (Window, EventTypes, BotPatterns, Concurrency — every other knob falls through to
 //     DefaultGHArchive*).
 //
 // wireDiscoveryGHArchive composes both: build the collector, register
@@ -59,11 +60,14 @@ func mapDiscoveryGHArchiveConfig(cfg config.DiscoveryGHArchiveConfig) discovery.
 		cacheTTL = time.Duration(cfg.MinStarsCacheTTLHours) * time.Hour
 	}
 	return discovery.GHArchiveSourceConfig{
-		Enabled:          cfg.Enabled,
-		TopN:             cfg.TopNPerHour,
-		ActivityFloor:    cfg.ActivityFloor,
-		MinStarsGate:     cfg.MinStarsGate,
-		MinStarsCacheTTL: cacheTTL,
+		Enabled:              cfg.Enabled,
+		TopN:                 cfg.TopNPerHour,
+		ActivityFloor:        cfg.ActivityFloor,
+		RankBy:               discovery.GHArchiveRankMetric(cfg.RankBy),
+		MinStarsGate:         cfg.MinStarsGate,
+		MinStarsCacheTTL:     cacheTTL,
+		ReleaseSignals:       cfg.ReleaseSignals,
+		MaxReleaseCandidates: cfg.MaxReleaseCandidates,
 	}
 }
 
@@ -75,8 +79,8 @@ func mapDiscoveryGHArchiveConfig(cfg config.DiscoveryGHArchiveConfig) discovery.
 // budget, and base URL fall through to the DefaultGHArchive* values
 // inside GHArchiveConfig.withDefaults().
 //
-// EventTypes is copied so a later mutation of the user config can't
-// race with the live collector.
+// EventTypes and BotPatterns are copied so a later mutation of the
+// user config can't race with the live collector.
 func mapDiscoveryGHArchiveCollectorConfig(cfg config.DiscoveryGHArchiveConfig) discovery.GHArchiveConfig {
 	var window time.Duration
 	if cfg.WindowHours > 0 {
@@ -86,9 +90,15 @@ func mapDiscoveryGHArchiveCollectorConfig(cfg config.DiscoveryGHArchiveConfig) d
 	if len(cfg.EventTypes) > 0 {
 		eventTypes = append(make([]string, 0, len(cfg.EventTypes)), cfg.EventTypes...)
 	}
+	var botPatterns []string
+	if len(cfg.BotPatterns) > 0 {
+		botPatterns = append(make([]string, 0, len(cfg.BotPatterns)), cfg.BotPatterns...)
+	}
 	return discovery.GHArchiveConfig{
-		Window:     window,
-		EventTypes: eventTypes,
+		Window:      window,
+		EventTypes:  eventTypes,
+		BotPatterns: botPatterns,
+		Concurrency: cfg.Concurrency,
 	}
 }
 
@@ -141,3 +151,23 @@ func wireDiscoveryGHArchive(
 	disc.SetGHArchivePipelineHooks(pipelineHooks)
 	return src, nil
 }
+
+// NewArchiveCache opens the gharchive archive cache described by
+// `collector.archive_cache`. It returns (nil, nil) when the block
+// configures neither a cache directory nor offline seed directories;
+// consumers treat a nil cache as "download every time".
+func NewArchiveCache(cfg config.ArchiveCacheConfig) (*gharchive.Cache, error) {
+	if cfg.Dir == "" && !cfg.Offline {
+		return nil, nil
+	}
+	cache, err := gharchive.NewCache(gharchive.CacheConfig{
+		Dir:      cfg.Dir,
+		MaxBytes: int64(cfg.MaxSizeMB) << 20,
+		Offline:  cfg.Offline,
+		SeedDirs: cfg.SeedDirs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening gharchive archive cache: %w", err)
+	}
+	return cache, nil
+}
@@ -37,22 +37,28 @@ func TestMapDiscoveryGHArchiveConfig_Defaults(t *testing.T) {
 // expected here — they go through mapDiscoveryGHArchiveCollectorConfig.
 func TestMapDiscoveryGHArchiveConfig_FullyPopulated(t *testing.T) {
 	in := config.DiscoveryGHArchiveConfig{
-		Enabled:       true,
-		WindowHours:   24,
-		TopNPerHour:   500,
-		ActivityFloor: 10,
-		EventTypes:    []string{"WatchEvent"},
-		MinStarsGate:  50,
-		DailyCapWarn:  4000,
-		DailyCapHard:  5000,
+		Enabled:              true,
+		WindowHours:          24,
+		TopNPerHour:          500,
+		ActivityFloor:        10,
+		RankBy:               "stargazers",
+		EventTypes:           []string{"WatchEvent"},
+		MinStarsGate:         50,
+		DailyCapWarn:         4000,
+		DailyCapHard:         5000,
+		ReleaseSignals:       true,
+		MaxReleaseCandidates: 20,
 	}
 	got := mapDiscoveryGHArchiveConfig(in)
 
 	want := discovery.GHArchiveSourceConfig{
-		Enabled:       true,
-		TopN:          500,
-		ActivityFloor: 10,
-		MinStarsGate:  50,
+		Enabled:              true,
+		TopN:                 500,
+		ActivityFloor:        10,
+		RankBy:               discovery.GHArchiveRankStargazers,
+		MinStarsGate:         50,
+		ReleaseSignals:       true,
+		MaxReleaseCandidates: 20,
 	}
 	if got != want {
 		t.Errorf("mapping = %+v, want %+v", got, want)
@@ -84,6 +90,8 @@ func TestMapDiscoveryGHArchiveCollectorConfig_PopulatedKnobs(t *testing.T) {
 		Enabled:     true,
 		WindowHours: 24,
 		EventTypes:  userTypes,
+		BotPatterns: []string{"ci-runner"},
+		Concurrency: 8,
 	}
 	got := mapDiscoveryGHArchiveCollectorConfig(in)
 
@@ -93,6 +101,12 @@ func TestMapDiscoveryGHArchiveCollectorConfig_PopulatedKnobs(t *testing.T) {
 	if len(got.EventTypes) != 2 || got.EventTypes[0] != "WatchEvent" || got.EventTypes[1] != "ForkEvent" {
 		t.Errorf("EventTypes = %+v, want [WatchEvent ForkEvent]", got.EventTypes)
 	}
+	if len(got.BotPatterns) != 1 || got.BotPatterns[0] != "ci-runner" {
+		t.Errorf("BotPatterns = %+v, want [ci-runner]", got.BotPatterns)
+	}
+	if got.Concurrency != 8 {
+		t.Errorf("Concurrency = %d, want 8", got.Concurrency)
+	}
 	// Mutating the source slice must not affect the mapped slice.
 	userTypes[0] = "MUTATED"
 	if got.EventTypes[0] != "WatchEvent" {
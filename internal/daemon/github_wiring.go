@@ -0,0 +1,109 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/github"
+)
+
+// github_wiring.go builds GitHub clients, the REST response cache and
+// the request governor from the YAML config. The github package takes
+// its own option structs and does not import config.
+
+// NewGitHubClient builds the client the github config section
+// describes: a GitHub App installation when github.app is set,
+// otherwise a pool of the configured personal access tokens.
+func NewGitHubClient(cfg config.GithubConfig) (*github.Client, error) {
+	if !cfg.App.Enabled() {
+		return github.NewPoolClient(cfg.AllTokens())
+	}
+	key, err := github.LoadAppPrivateKey(cfg.App.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return github.NewAppClient(github.AppConfig{
+		AppID:          cfg.App.AppID,
+		InstallationID: cfg.App.InstallationID,
+		PrivateKey:     key,
+	})
+}
+
+// NewGitHubHostClient builds the client for one GitHub Enterprise
+// Server host: its own credentials and endpoints, with repos keyed under
+// the host's name. defaultRateLimit applies when the host sets none.
+func NewGitHubHostClient(h config.GithubHostConfig, defaultRateLimit int) (*github.Client, error) {
+	c, err := NewGitHubClient(h.Auth(defaultRateLimit))
+	if err != nil {
+		return nil, fmt.Errorf("host %s: %w", h.Name, err)
+	}
+	c.SetBaseURL(h.APIURL)
+	c.SetGraphQLURL(h.ResolvedGraphQLURL())
+	c.SetHost(h.Name)
+	return c, nil
+}
+
+// NewGitHubHostClients builds a client for every host under cfg.Hosts,
+// keyed by host name.
+func NewGitHubHostClients(cfg config.GithubConfig) (map[string]*github.Client, error) {
+	clients := make(map[string]*github.Client, len(cfg.Hosts))
+	for _, h := range cfg.Hosts {
+		c, err := NewGitHubHostClient(h, cfg.RateLimit)
+		if err != nil {
+			return nil, err
+		}
+		clients[h.Name] = c
+	}
+	return clients, nil
+}
+
+// newHTTPCache opens the REST response cache described by cfg. It
+// returns nil when the cache is disabled.
+func newHTTPCache(cfg config.HTTPCacheConfig) (*github.HTTPCache, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	ttls, err := cfg.TTLDurations()
+	if err != nil {
+		return nil, fmt.Errorf("http cache: %w", err)
+	}
+	cache, err := github.NewHTTPCache(github.HTTPCacheConfig{
+		Dir:           cfg.Dir,
+		MaxBytes:      int64(cfg.MaxSizeMB) << 20,
+		MaxEntryBytes: int64(cfg.MaxEntryKB) << 10,
+		TTLs:          ttls,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening http cache: %w", err)
+	}
+	return cache, nil
+}
+
+// governorConfigFromYAML maps the github.governor config into the
+// governor's config, filling zero fields from
+// github.DefaultGovernorConfig.
+func governorConfigFromYAML(cfg config.GovernorConfig) github.GovernorConfig {
+	gc := github.DefaultGovernorConfig()
+	for resource, r := range map[string]config.GovernorResourceConfig{
+		github.ResourceCore:    cfg.Core,
+		github.ResourceSearch:  cfg.Search,
+		github.ResourceGraphQL: cfg.GraphQL,
+	} {
+		l := gc.Limits[resource]
+		if r.PerMinute > 0 {
+			l.PerMinute = r.PerMinute
+		}
+		if r.Burst > 0 {
+			l.Burst = r.Burst
+		}
+		if r.MaxInFlight > 0 {
+			l.MaxInFlight = r.MaxInFlight
+		}
+		gc.Limits[resource] = l
+	}
+	if cfg.CooldownSec > 0 {
+		gc.Cooldown = time.Duration(cfg.CooldownSec) * time.Second
+	}
+	return gc
+}
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/github"
+)
+
+func TestNewGitHubHostClient(t *testing.T) {
+	client, err := NewGitHubHostClient(config.GithubHostConfig{
+		Name:   "ghe.corp",
+		APIURL: "https://ghe.corp/api/v3",
+		Token:  "ghe-token",
+	}, 4000)
+	if err != nil {
+		t.Fatalf("NewGitHubHostClient: %v", err)
+	}
+	if got, want := client.GraphQLURL(), "https://ghe.corp/api/graphql"; got != want {
+		t.Errorf("GraphQLURL() = %q, want %q", got, want)
+	}
+
+	if _, err := NewGitHubHostClient(config.GithubHostConfig{Name: "ghe.corp", APIURL: "https://ghe.corp/api/v3"}, 4000); err == nil {
+		t.Error("host without credentials should fail")
+	}
+}
+
+func TestGovernorConfigFromYAML(t *testing.T) {
+	got := governorConfigFromYAML(config.GovernorConfig{
+		Enabled:     true,
+		Search:      config.GovernorResourceConfig{PerMinute: 10},
+		CooldownSec: 5,
+	})
+	def := github.DefaultGovernorConfig()
+	if l := got.Limits[github.ResourceSearch]; l.PerMinute != 10 || l.Burst != def.Limits[github.ResourceSearch].Burst {
+		t.Errorf("search limits = %+v, want PerMinute 10 over the defaults", l)
+	}
+	if got.Limits[github.ResourceCore] != def.Limits[github.ResourceCore] {
+		t.Errorf("core limits = %+v, want the defaults", got.Limits[github.ResourceCore])
+	}
+	if got.Cooldown != 5*time.Second {
+		t.Errorf("Cooldown = %v, want 5s", got.Cooldown)
+	}
+}
+
+func TestHTTPCacheEndpointsMatch(t *testing.T) {
+	if !slices.Equal(config.HTTPCacheEndpoints, github.CacheEndpoints) {
+		t.Errorf("config.HTTPCacheEndpoints %v != github.CacheEndpoints %v", config.HTTPCacheEndpoints, github.CacheEndpoints)
+	}
+}
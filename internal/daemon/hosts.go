@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/github"
+	"github.com/hrexed/github-radar/internal/logging"
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// hosts.go scans repos on GitHub Enterprise Server hosts
+// (github.hosts). Each host gets its own client and scanner sharing the
+// daemon's state store; its repos are keyed host/owner/repo so they sit
+// next to github.com repos without colliding. Host repos always take the
+// REST path: the bulk/tiered path, the gharchive fallback and discovery
+// only cover github.com.
+
+// newHostClients builds one client per configured host, with the same
+// rate-limit warning hook as the github.com client.
+func newHostClients(cfg *config.Config) (map[string]*github.Client, error) {
+	clients, err := NewGitHubHostClients(cfg.GitHub)
+	if err != nil {
+		return nil, fmt.Errorf("creating github client: %w", err)
+	}
+	for _, h := range cfg.GitHub.Hosts {
+		host := h.Name
+		clients[host].SetRateLimitOptions(github.RateLimitOptions{
+			Threshold: h.Auth(cfg.GitHub.RateLimit).RateLimit,
+			OnWarning: func(remaining int, reset time.Time) {
+				logging.Warn("rate limit warning",
+					"host", host,
+					"remaining", remaining,
+					"reset", reset.Format(time.RFC3339))
+			},
+		})
+		// Secondary limits are per server, so each host gets its own
+		// governor.
+		clients[host].SetGovernor(newGovernor(cfg.GitHub.Governor, host))
+	}
+	return clients, nil
+}
+
+// newHostScanners wraps each host client in a scanner over store.
+func newHostScanners(clients map[string]*github.Client, store *state.Store, cfg *config.Config) map[string]*github.Scanner {
+	if len(clients) == 0 {
+		return nil
+	}
+	scanners := make(map[string]*github.Scanner, len(clients))
+	for host, client := range clients {
+		s := github.NewScanner(client, store)
+		s.SetScoringWeights(scoringWeights(cfg))
+		s.SetLogger(func(level, msg string, args ...interface{}) {
+			logWithLevel(level, msg, append(args, "host", host)...)
+		})
+		scanners[host] = s
+	}
+	return scanners
+}
+
+// partitionByHost splits repos into github.com repos and repos per
+// enterprise host, preserving order within each group.
+func partitionByHost(repos []github.Repo) (public []github.Repo, byHost map[string][]github.Repo) {
+	for _, r := range repos {
+		if r.Host == "" {
+			public = append(public, r)
+			continue
+		}
+		if byHost == nil {
+			byHost = make(map[string][]github.Repo)
+		}
+		byHost[r.Host] = append(byHost[r.Host], r)
+	}
+	return public, byHost
+}
+
+// runHostScans scans each enterprise host's repos with that host's
+// scanner, in host-name order, and folds the results into combined.
+// Repos on a host that is not configured are skipped with a warning.
+func (d *Daemon) runHostScans(combined *github.ScanResult, byHost map[string][]github.Repo) {
+	hosts := make([]string, 0, len(byHost))
+	for host := range byHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		repos := byHost[host]
+		scanner, ok := d.hostScanners[host]
+		if !ok {
+			logging.Warn("skipping repos on unconfigured github host",
+				"host", host,
+				"repos", len(repos),
+				"hint", "add the host under github.hosts")
+			continue
+		}
+		hr, err := scanner.Scan(d.ctx, repos)
+		if err != nil && err != context.Canceled {
+			logging.Warn("host scan returned error", "host", host, "error", err)
+		}
+		if hr != nil {
+			addScanResult(combined, hr)
+		}
+	}
+}
+
+// addScanResult folds a sub-scan's counters into combined.
+func addScanResult(combined, r *github.ScanResult) {
+	combined.Total += r.Total
+	combined.Successful += r.Successful
+	combined.Failed += r.Failed
+	combined.Skipped += r.Skipped
+	combined.Updated += r.Updated
+	combined.RepoGone += r.RepoGone
+	combined.FailedRepos = append(combined.FailedRepos, r.FailedRepos...)
+	combined.GoneRepos = append(combined.GoneRepos, r.GoneRepos...)
+	mergeRenamed(combined, r.Renamed)
+}
@@ -0,0 +1,407 @@
+package daemon
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/github"
+	"github.com/hrexed/github-radar/internal/logging"
+)
+
+// planner.go budgets each scan cycle before it starts. Scanning,
+// discovery and classification all draw on the github.com client's
+// budgets, so the planner estimates every job's cost (units × per-unit
+// cost) against what the core and GraphQL budgets have left, grants jobs
+// in priority order and defers what does not fit: reclassification and
+// cold-tier repos go first. Each job's actual usage is recorded while it
+// runs, refines the per-unit costs for the next cycle and is served with
+// the plan on /status.
+
+// Jobs a cycle plans. Units are repos, except for discovery, whose
+// units are the steps of its search plan.
+const (
+	jobScan       = "scan"       // REST single pass (legacy path)
+	jobScanBulk   = "scan_bulk"  // hot, warm and new tiers over GraphQL
+	jobScanCold   = "scan_cold"  // cold tier over conditional REST
+	jobDiscovery  = "discovery"  // all sources, one unit per step
+	jobClassify   = "classify"   // repos waiting for a (re)classification
+	jobReclassify = "reclassify" // README hash check over classified repos
+)
+
+// Job priorities. High-priority jobs always run; the rest share what
+// the budgets have left, in plan order.
+const (
+	priorityHigh   = "high"
+	priorityNormal = "normal"
+	priorityLow    = "low"
+)
+
+// Job states reported on /status.
+const (
+	jobPlanned  = "planned"
+	jobRunning  = "running"
+	jobDone     = "done"
+	jobDeferred = "deferred"
+)
+
+// defaultPlannerReservePct is the share of each budget's limit kept out
+// of the plan when github.planner.reserve_pct is 0.
+const defaultPlannerReservePct = 10
+
+// plannerCostSmoothing is the weight of the latest cycle in the learned
+// per-unit costs.
+const plannerCostSmoothing = 0.3
+
+// defaultUnitCosts are the per-unit costs used until a job has run
+// once: a REST scan is the repo GET, the open-PR count and the four
+// activity calls; a bulk scan keeps the activity calls and moves the
+// rest to one GraphQL query per batch.
+var defaultUnitCosts = map[string]unitCost{
+	jobScan:       {core: 6},
+	jobScanBulk:   {core: 4, graphql: 1.0 / github.MaxGraphQLBatchSize},
+	jobScanCold:   {core: 6},
+	jobDiscovery:  {core: 1, search: 1},
+	jobClassify:   {core: 2},
+	jobReclassify: {core: 1},
+}
+
+// PlanCost is API usage split by rate-limit budget.
+type PlanCost struct {
+	Core    int `json:"core"`
+	GraphQL int `json:"graphql"`
+	Search  int `json:"search"`
+}
+
+// PlannedJob is one job of a cycle plan.
+type PlannedJob struct {
+	Name     string `json:"name"`
+	Priority string `json:"priority"`
+	// Units is the work due this cycle; Granted of them run and
+	// Deferred wait for a later cycle.
+	Units    int      `json:"units"`
+	Granted  int      `json:"granted"`
+	Deferred int      `json:"deferred"`
+	Estimate PlanCost `json:"estimate"`
+	Used     PlanCost `json:"used"`
+	State    string   `json:"state"`
+}
+
+// PlanBudget is one rate-limit budget as the planner saw it.
+type PlanBudget struct {
+	Resource  string `json:"resource"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	Reset     string `json:"reset,omitempty"`
+	Reserve   int    `json:"reserve"`
+	Planned   int    `json:"planned"`
+	Used      int    `json:"used"`
+}
+
+// CyclePlan is the API budget plan of one scan cycle.
+type CyclePlan struct {
+	CreatedAt string       `json:"created_at"`
+	Budgets   []PlanBudget `json:"budgets"`
+	Jobs      []PlannedJob `json:"jobs"`
+}
+
+// job returns the named job, or nil when the plan has none.
+func (p *CyclePlan) job(name string) *PlannedJob {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Jobs {
+		if p.Jobs[i].Name == name {
+			return &p.Jobs[i]
+		}
+	}
+	return nil
+}
+
+// clone copies p so it can be encoded outside the daemon lock.
+func (p *CyclePlan) clone() *CyclePlan {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Budgets = slices.Clone(p.Budgets)
+	c.Jobs = slices.Clone(p.Jobs)
+	return &c
+}
+
+// unitCost is the estimated API usage of one unit of a job.
+type unitCost struct {
+	core, graphql, search float64
+}
+
+func (u unitCost) times(n int) PlanCost {
+	return PlanCost{
+		Core:    int(math.Ceil(u.core * float64(n))),
+		GraphQL: int(math.Ceil(u.graphql * float64(n))),
+		Search:  int(math.Ceil(u.search * float64(n))),
+	}
+}
+
+// jobRequest is a job the cycle would run if the budgets allow.
+type jobRequest struct {
+	name     string
+	priority string
+	units    int
+	// divisible jobs may be granted part of their units; the others
+	// run in full or not at all.
+	divisible bool
+}
+
+// planner turns a cycle's job requests into a CyclePlan. It is only
+// used from runScan, which never runs concurrently with itself.
+type planner struct {
+	reservePct int
+	learned    map[string]unitCost
+	// graphqlPerRepo, when > 0, is the bulk sizer's current estimate of
+	// GraphQL points per repo and overrides the learned one.
+	graphqlPerRepo float64
+}
+
+func newPlanner(reservePct int) *planner {
+	if reservePct <= 0 {
+		reservePct = defaultPlannerReservePct
+	}
+	return &planner{reservePct: reservePct, learned: make(map[string]unitCost)}
+}
+
+// unitCost returns the per-unit estimate for job.
+func (p *planner) unitCost(job string) unitCost {
+	u, ok := p.learned[job]
+	if !ok {
+		u = defaultUnitCosts[job]
+	}
+	if job == jobScanBulk && p.graphqlPerRepo > 0 {
+		u.graphql = p.graphqlPerRepo
+	}
+	return u
+}
+
+// learn folds a finished job's usage into its per-unit cost.
+func (p *planner) learn(job string, units int, used PlanCost) {
+	if units <= 0 {
+		return
+	}
+	sample := unitCost{
+		core:    float64(used.Core) / float64(units),
+		graphql: float64(used.GraphQL) / float64(units),
+		search:  float64(used.Search) / float64(units),
+	}
+	prev, ok := p.learned[job]
+	if !ok {
+		p.learned[job] = sample
+		return
+	}
+	p.learned[job] = unitCost{
+		core:    prev.core + plannerCostSmoothing*(sample.core-prev.core),
+		graphql: prev.graphql + plannerCostSmoothing*(sample.graphql-prev.graphql),
+		search:  prev.search + plannerCostSmoothing*(sample.search-prev.search),
+	}
+}
+
+// plan grants jobs, in the order given, against the core and GraphQL
+// budgets. The search budget refills every minute and is paced by the
+// request governor, so it is reported but not allocated.
+func (p *planner) plan(jobs []jobRequest, core, graphql, search github.RateLimit, now time.Time) *CyclePlan {
+	plan := &CyclePlan{CreatedAt: now.Format(time.RFC3339)}
+	coreLeft, coreKnown := p.available(core, now)
+	gqlLeft, gqlKnown := p.available(graphql, now)
+
+	for _, j := range jobs {
+		u := p.unitCost(j.name)
+		granted := j.units
+		if j.priority != priorityHigh {
+			if coreKnown {
+				granted = min(granted, affordable(coreLeft, u.core))
+			}
+			if gqlKnown {
+				granted = min(granted, affordable(gqlLeft, u.graphql))
+			}
+			if !j.divisible && granted < j.units {
+				granted = 0
+			}
+		}
+		est := u.times(granted)
+		coreLeft -= est.Core
+		gqlLeft -= est.GraphQL
+
+		state := jobPlanned
+		if granted == 0 && j.units > 0 {
+			state = jobDeferred
+		}
+		plan.Jobs = append(plan.Jobs, PlannedJob{
+			Name:     j.name,
+			Priority: j.priority,
+			Units:    j.units,
+			Granted:  granted,
+			Deferred: j.units - granted,
+			Estimate: est,
+			State:    state,
+		})
+	}
+
+	for _, b := range []struct {
+		resource string
+		rl       github.RateLimit
+	}{{github.ResourceCore, core}, {github.ResourceGraphQL, graphql}, {github.ResourceSearch, search}} {
+		pb := PlanBudget{Resource: b.resource, Limit: b.rl.Limit, Remaining: b.rl.Remaining}
+		if !b.rl.Reset.IsZero() {
+			pb.Reset = b.rl.Reset.Format(time.RFC3339)
+		}
+		if b.resource != github.ResourceSearch {
+			pb.Reserve = b.rl.Limit * p.reservePct / 100
+		}
+		for _, j := range plan.Jobs {
+			pb.Planned += costFor(j.Estimate, b.resource)
+		}
+		plan.Budgets = append(plan.Budgets, pb)
+	}
+	return plan
+}
+
+// available is what the plan may spend of rl: the remaining budget less
+// the reserve. A window whose reset has passed counts as full. known is
+// false before the client has seen the budget at all.
+func (p *planner) available(rl github.RateLimit, now time.Time) (left int, known bool) {
+	if rl.Limit <= 0 {
+		return 0, false
+	}
+	remaining := rl.Remaining
+	if !rl.Reset.IsZero() && !rl.Reset.After(now) {
+		remaining = rl.Limit
+	}
+	return remaining - rl.Limit*p.reservePct/100, true
+}
+
+// affordable returns how many units at perUnit fit in left.
+func affordable(left int, perUnit float64) int {
+	if perUnit <= 0 {
+		return math.MaxInt
+	}
+	if left <= 0 {
+		return 0
+	}
+	return int(float64(left) / perUnit)
+}
+
+// costFor picks resource's share of c.
+func costFor(c PlanCost, resource string) int {
+	switch resource {
+	case github.ResourceCore:
+		return c.Core
+	case github.ResourceGraphQL:
+		return c.GraphQL
+	case github.ResourceSearch:
+		return c.Search
+	}
+	return 0
+}
+
+// planCycle plans this cycle's jobs against the github.com client's
+// budgets and publishes the plan on /status. It is a no-op when the
+// planner is disabled.
+func (d *Daemon) planCycle(tiers tierBatches, legacy []github.Repo) {
+	if d.planner == nil {
+		return
+	}
+
+	jobs := []jobRequest{
+		{name: jobScan, priority: priorityHigh, units: len(legacy)},
+		{name: jobScanBulk, priority: priorityHigh, units: len(tiers.bulk)},
+	}
+	if d.discoverer != nil {
+		steps, _ := d.discoverer.PlannedSteps()
+		jobs = append(jobs, jobRequest{name: jobDiscovery, priority: priorityNormal, units: steps})
+	}
+	if d.classifier != nil && d.db != nil {
+		if statuses, err := d.db.CountByStatus(); err == nil {
+			jobs = append(jobs,
+				jobRequest{
+					name: jobClassify, priority: priorityNormal, divisible: true,
+					units: statuses["pending"] + statuses["needs_review"] + statuses["needs_reclassify"],
+				},
+				jobRequest{name: jobReclassify, priority: priorityLow, units: statuses["active"]})
+		} else {
+			logging.Warn("budget planner could not count repos for classification", "error", err)
+		}
+	}
+	jobs = append(jobs, jobRequest{name: jobScanCold, priority: priorityLow, units: len(tiers.cold), divisible: true})
+
+	d.planner.graphqlPerRepo = d.client.GraphQLBatchStats().CostPerRepo
+	plan := d.planner.plan(jobs,
+		d.client.ResourceRateLimitInfo(github.ResourceCore),
+		d.client.ResourceRateLimitInfo(github.ResourceGraphQL),
+		d.client.ResourceRateLimitInfo(github.ResourceSearch),
+		time.Now())
+
+	d.mu.Lock()
+	d.plan = plan
+	d.mu.Unlock()
+
+	args := []any{}
+	for _, b := range plan.Budgets {
+		args = append(args, b.Resource+"_remaining", b.Remaining, b.Resource+"_planned", b.Planned)
+	}
+	for _, j := range plan.Jobs {
+		if j.Units > 0 {
+			args = append(args, j.Name, j.Granted)
+		}
+		if j.Deferred > 0 {
+			args = append(args, j.Name+"_deferred", j.Deferred)
+		}
+	}
+	logging.Info("cycle budget plan", args...)
+}
+
+// plannedJob returns job as this cycle's plan has it. ok is false when
+// nothing was planned, in which case the job runs in full.
+func (d *Daemon) plannedJob(job string) (PlannedJob, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	j := d.plan.job(job)
+	if j == nil {
+		return PlannedJob{}, false
+	}
+	return *j, true
+}
+
+// beginJob marks job running and returns the func that records its
+// actual usage once it is done.
+func (d *Daemon) beginJob(job string) (end func()) {
+	d.mu.Lock()
+	j := d.plan.job(job)
+	if j == nil {
+		d.mu.Unlock()
+		return func() {}
+	}
+	j.State = jobRunning
+	d.mu.Unlock()
+
+	before := d.client.APIUsage()
+	return func() {
+		delta := d.client.APIUsage().Sub(before)
+		used := PlanCost{Core: delta.Core, GraphQL: delta.GraphQL, Search: delta.Search}
+
+		d.mu.Lock()
+		j := d.plan.job(job)
+		if j == nil {
+			d.mu.Unlock()
+			return
+		}
+		j.State = jobDone
+		j.Used = used
+		granted := j.Granted
+		for i := range d.plan.Budgets {
+			d.plan.Budgets[i].Used += costFor(used, d.plan.Budgets[i].Resource)
+		}
+		d.mu.Unlock()
+
+		if d.ctx.Err() == nil {
+			d.planner.learn(job, granted, used)
+		}
+	}
+}
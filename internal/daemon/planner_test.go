@@ -0,0 +1,163 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/github"
+)
+
+func TestPlanner_DefersLowPriorityWork(t *testing.T) {
+	now := time.Now()
+	p := newPlanner(10)
+	// 400 left of 1000, less the 100 reserve: 300 core requests to plan.
+	core := github.RateLimit{Limit: 1000, Remaining: 400, Reset: now.Add(30 * time.Minute)}
+
+	plan := p.plan([]jobRequest{
+		{name: jobScanBulk, priority: priorityHigh, units: 50},                    // 200
+		{name: jobDiscovery, priority: priorityNormal, units: 5},                  // 5
+		{name: jobClassify, priority: priorityNormal, units: 30, divisible: true}, // 60
+		{name: jobReclassify, priority: priorityLow, units: 100},                  // 100 > 35 left
+		{name: jobScanCold, priority: priorityLow, units: 20, divisible: true},    // 5 × 6 fit
+	}, core, github.RateLimit{}, github.RateLimit{}, now)
+
+	want := map[string][2]int{ // granted, deferred
+		jobScanBulk:   {50, 0},
+		jobDiscovery:  {5, 0},
+		jobClassify:   {30, 0},
+		jobReclassify: {0, 100},
+		jobScanCold:   {5, 15},
+	}
+	for name, w := range want {
+		j := plan.job(name)
+		if j == nil {
+			t.Fatalf("plan has no %s job", name)
+		}
+		if j.Granted != w[0] || j.Deferred != w[1] {
+			t.Errorf("%s: granted %d deferred %d, want %d and %d", name, j.Granted, j.Deferred, w[0], w[1])
+		}
+	}
+	if j := plan.job(jobReclassify); j.State != jobDeferred {
+		t.Errorf("reclassify state = %q, want deferred", j.State)
+	}
+	if b := plan.Budgets[0]; b.Resource != github.ResourceCore || b.Reserve != 100 || b.Planned != 295 {
+		t.Errorf("core budget = %+v, want reserve 100 and 295 planned", b)
+	}
+}
+
+func TestPlanner_HighPriorityAlwaysRuns(t *testing.T) {
+	now := time.Now()
+	p := newPlanner(0)
+	core := github.RateLimit{Limit: 5000, Remaining: 100, Reset: now.Add(time.Hour)}
+
+	plan := p.plan([]jobRequest{
+		{name: jobScan, priority: priorityHigh, units: 500},
+		{name: jobDiscovery, priority: priorityNormal, units: 3},
+	}, core, github.RateLimit{}, github.RateLimit{}, now)
+
+	if j := plan.job(jobScan); j.Granted != 500 || j.Estimate.Core != 3000 {
+		t.Errorf("scan = %+v, want all 500 repos granted at 6 requests each", j)
+	}
+	if j := plan.job(jobDiscovery); j.Granted != 0 || j.State != jobDeferred {
+		t.Errorf("discovery = %+v, want deferred", j)
+	}
+}
+
+func TestPlanner_UnknownOrResetBudget(t *testing.T) {
+	now := time.Now()
+	p := newPlanner(0)
+	jobs := []jobRequest{{name: jobScanCold, priority: priorityLow, units: 1000, divisible: true}}
+
+	// Before the client has seen a budget nothing is held back.
+	if j := p.plan(jobs, github.RateLimit{}, github.RateLimit{}, github.RateLimit{}, now).job(jobScanCold); j.Granted != 1000 {
+		t.Errorf("unknown budget: granted %d, want 1000", j.Granted)
+	}
+
+	// A window that has already reset counts as full: 5000 less the 500
+	// reserve buys 750 repos at 6 requests.
+	stale := github.RateLimit{Limit: 5000, Remaining: 0, Reset: now.Add(-time.Minute)}
+	if j := p.plan(jobs, stale, github.RateLimit{}, github.RateLimit{}, now).job(jobScanCold); j.Granted != 750 {
+		t.Errorf("reset budget: granted %d, want 750", j.Granted)
+	}
+}
+
+func TestPlanner_LearnsUnitCosts(t *testing.T) {
+	p := newPlanner(0)
+	p.learn(jobScanCold, 10, PlanCost{Core: 20})
+	if got := p.unitCost(jobScanCold).core; got != 2 {
+		t.Errorf("after first cycle: %v requests per repo, want 2", got)
+	}
+	p.learn(jobScanCold, 10, PlanCost{Core: 40})
+	if got := p.unitCost(jobScanCold).core; got < 2.59 || got > 2.61 {
+		t.Errorf("after second cycle: %v requests per repo, want 2.6", got)
+	}
+
+	// The bulk sizer's GraphQL estimate wins over the learned one.
+	p.learn(jobScanBulk, 25, PlanCost{Core: 100, GraphQL: 25})
+	p.graphqlPerRepo = 0.2
+	if got := p.unitCost(jobScanBulk); got.core != 4 || got.graphql != 0.2 {
+		t.Errorf("bulk unit cost = %+v, want 4 core and 0.2 graphql", got)
+	}
+}
+
+func TestDaemon_PlanTracksUsageOnStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := github.NewClient("tkn")
+	client.SetBaseURL(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := &Daemon{client: client, planner: newPlanner(0), ctx: ctx, startTime: time.Now()}
+
+	resp, err := client.Get(ctx, "/rate_limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	d.planCycle(tierBatches{cold: make([]github.Repo, 2)}, nil)
+	endJob := d.beginJob(jobScanCold)
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ctx, "/repos/o/r")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	endJob()
+
+	w := httptest.NewRecorder()
+	d.handleStatus(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status StatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Plan == nil {
+		t.Fatal("status has no plan")
+	}
+	var cold *PlannedJob
+	for i := range status.Plan.Jobs {
+		if status.Plan.Jobs[i].Name == jobScanCold {
+			cold = &status.Plan.Jobs[i]
+		}
+	}
+	if cold == nil || cold.Granted != 2 || cold.Estimate.Core != 12 || cold.Used.Core != 3 || cold.State != jobDone {
+		t.Errorf("scan_cold = %+v, want 2 repos granted, 12 estimated, 3 used, done", cold)
+	}
+	if b := status.Plan.Budgets[0]; b.Remaining != 4000 || b.Used != 3 {
+		t.Errorf("core budget = %+v, want 4000 remaining and 3 used", b)
+	}
+	if got := d.planner.unitCost(jobScanCold).core; got != 1.5 {
+		t.Errorf("learned %v requests per cold repo, want 1.5", got)
+	}
+}
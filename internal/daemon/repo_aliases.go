@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"strings"
+
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/logging"
+)
+
+// canonicalRepoName maps a configured or stored repo name to the name
+// it was last seen under. Only renames are followed — a tracked fork
+// stays tracked as itself. Without a database, or on lookup failure,
+// the name is returned unchanged and the scanner re-detects the rename
+// from the redirect.
+func (d *Daemon) canonicalRepoName(fullName string) string {
+	if d.db == nil {
+		return fullName
+	}
+	canonical, reason, err := d.db.ResolveRepoAlias(fullName)
+	if err != nil {
+		logging.Debug("repo alias lookup failed", "repo", fullName, "error", err)
+		return fullName
+	}
+	if reason != database.AliasReasonRenamed || !strings.Contains(canonical, "/") {
+		return fullName
+	}
+	return canonical
+}
+
+// recordRenames persists the renames a scan observed: the alias row so
+// the next cycle scans the new name directly, and the repos row moved
+// under the new name so classification carries over. The in-memory
+// store was already merged by the scanner.
+func (d *Daemon) recordRenames(renamed map[string]string) {
+	for from, to := range renamed {
+		logging.Info("tracked repo was renamed", "from", from, "to", to)
+		if d.db == nil {
+			continue
+		}
+		if err := d.db.SetRepoAlias(from, to, database.AliasReasonRenamed); err != nil {
+			logging.Warn("failed to record repo alias", "from", from, "to", to, "error", err)
+		}
+		if err := d.db.MergeRepo(from, to); err != nil {
+			logging.Warn("failed to merge renamed repo", "from", from, "to", to, "error", err)
+		}
+	}
+}
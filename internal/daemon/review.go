@@ -0,0 +1,172 @@
+package daemon
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hrexed/github-radar/internal/classification"
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/logging"
+	"github.com/hrexed/github-radar/internal/review"
+)
+
+// review.go serves the classification review queue (internal/review)
+// next to /status:
+//
+//	GET  /review?limit=N        queued repos, least confident first
+//	GET  /review/item?repo=KEY  one queued repo with its live description
+//	                            and README excerpt
+//	POST /review/decision       {"repo", "action": "accept"|"override",
+//	                            "category", "subcategory", "reviewer"}
+//
+// Reads are open like /status. Decisions need
+// classification.review.api_token as a bearer token and are refused
+// while it is unset.
+
+// defaultReviewLimit caps GET /review without a limit parameter.
+const defaultReviewLimit = 50
+
+// reviewAPI holds the queue and the token decisions are checked against.
+type reviewAPI struct {
+	queue *review.Queue
+	token string
+}
+
+// newReviewAPI creates the review endpoints' state. pipeline, when
+// non-nil, supplies the live description and README of an item.
+func newReviewAPI(db *database.DB, pipeline *classification.Pipeline, cfg config.ReviewConfig) *reviewAPI {
+	var describer review.Describer
+	if pipeline != nil {
+		describer = pipeline
+	}
+	return &reviewAPI{queue: review.NewQueue(db, describer), token: cfg.APIToken}
+}
+
+// reviewDecisionRequest is the POST /review/decision body.
+type reviewDecisionRequest struct {
+	Repo        string `json:"repo"`
+	Action      string `json:"action"`
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+	Reviewer    string `json:"reviewer"`
+}
+
+// reviewQueueResponse is the GET /review body.
+type reviewQueueResponse struct {
+	Items []review.Item `json:"items"`
+}
+
+func (d *Daemon) handleReviewQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	limit := defaultReviewLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	items, err := d.review.queue.Pending(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeReviewJSON(w, http.StatusOK, reviewQueueResponse{Items: items})
+}
+
+func (d *Daemon) handleReviewItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	repo := r.URL.Query().Get("repo")
+	if repo == "" {
+		http.Error(w, "repo parameter required", http.StatusBadRequest)
+		return
+	}
+	item, err := d.review.queue.Item(r.Context(), repo)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	writeReviewJSON(w, http.StatusOK, item)
+}
+
+func (d *Daemon) handleReviewDecision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if d.review.token == "" {
+		http.Error(w, "review decisions are disabled: classification.review.api_token is not set", http.StatusForbidden)
+		return
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(d.review.token)) != 1 {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	var req reviewDecisionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.Repo == "" || req.Reviewer == "" {
+		http.Error(w, "repo and reviewer are required", http.StatusBadRequest)
+		return
+	}
+
+	var decision *review.Decision
+	var err error
+	switch req.Action {
+	case database.ReviewAccept:
+		decision, err = d.review.queue.Accept(req.Repo, req.Reviewer)
+	case database.ReviewOverride:
+		decision, err = d.review.queue.Override(req.Repo, req.Category, req.Subcategory, req.Reviewer)
+	default:
+		http.Error(w, `action must be "accept" or "override"`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	logging.Info("classification reviewed",
+		"repo", decision.FullName,
+		"action", decision.Action,
+		"category", decision.Category,
+		"subcategory", decision.Subcategory,
+		"reviewer", decision.Reviewer)
+	writeReviewJSON(w, http.StatusOK, decision)
+}
+
+// writeReviewError maps a queue error to its HTTP status.
+func writeReviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, review.ErrNotQueued):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, database.ErrPairNotAllowed):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeReviewJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
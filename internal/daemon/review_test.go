@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+	"github.com/hrexed/github-radar/internal/review"
+)
+
+func postDecision(t *testing.T, d *Daemon, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/review/decision", bytes.NewBufferString(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	d.handleReviewDecision(w, req)
+	return w
+}
+
+func TestReviewEndpoints(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	if err := db.UpsertRepo(&database.RepoRecord{
+		FullName: "acme/agent", Owner: "acme", Name: "agent", Status: "needs_review",
+		PrimaryCategory: "ai-agents", CategoryConfidence: 0.4, ModelUsed: "m",
+	}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+
+	d := &Daemon{db: db, review: newReviewAPI(db, nil, config.ReviewConfig{APIToken: "t0ken"})}
+
+	w := httptest.NewRecorder()
+	d.handleReviewQueue(w, httptest.NewRequest(http.MethodGet, "/review", nil))
+	var queue reviewQueueResponse
+	if err := json.NewDecoder(w.Body).Decode(&queue); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET /review: status %d, %v", w.Code, err)
+	}
+	if len(queue.Items) != 1 || queue.Items[0].Category != "ai" || queue.Items[0].Subcategory != "agents" {
+		t.Errorf("queue = %+v", queue.Items)
+	}
+
+	w = httptest.NewRecorder()
+	d.handleReviewItem(w, httptest.NewRequest(http.MethodGet, "/review/item?repo=acme/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown item: status %d, want 404", w.Code)
+	}
+
+	override := `{"repo":"acme/agent","action":"override","category":"ai","subcategory":"llm-tooling","reviewer":"alex"}`
+	if w := postDecision(t, d, "", override); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status %d, want 401", w.Code)
+	}
+	if w := postDecision(t, d, "wrong", override); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: status %d, want 401", w.Code)
+	}
+	bad := `{"repo":"acme/agent","action":"override","category":"ai","subcategory":"bogus","reviewer":"alex"}`
+	if w := postDecision(t, d, "t0ken", bad); w.Code != http.StatusBadRequest {
+		t.Errorf("disallowed pair: status %d, want 400", w.Code)
+	}
+
+	w = postDecision(t, d, "t0ken", override)
+	if w.Code != http.StatusOK {
+		t.Fatalf("override: status %d: %s", w.Code, w.Body)
+	}
+	var decision review.Decision
+	if err := json.NewDecoder(w.Body).Decode(&decision); err != nil {
+		t.Fatalf("decoding decision: %v", err)
+	}
+	if decision.Subcategory != "llm-tooling" || decision.Reviewer != "alex" {
+		t.Errorf("decision = %+v", decision)
+	}
+	if w := postDecision(t, d, "t0ken", override); w.Code != http.StatusNotFound {
+		t.Errorf("already reviewed: status %d, want 404", w.Code)
+	}
+
+	disabled := &Daemon{db: db, review: newReviewAPI(db, nil, config.ReviewConfig{})}
+	if w := postDecision(t, disabled, "", override); w.Code != http.StatusForbidden {
+		t.Errorf("no api_token configured: status %d, want 403", w.Code)
+	}
+}
@@ -0,0 +1,208 @@
+package daemon
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/github"
+	"github.com/hrexed/github-radar/internal/logging"
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// webhook.go receives GitHub webhook deliveries on /webhooks/github
+// (github.webhooks). A signed star, fork, release, pull_request or
+// issues delivery for a tracked repo updates its stored counters
+// between scans and keeps it in the hot refresh tier for promote_hours,
+// so the next tiered cycle refreshes it instead of waiting out the cold
+// interval. Every github.com delivery also counts toward the gharchive
+// discovery window before its archive is published.
+//
+// Deliveries from a GitHub Enterprise Server carry
+// X-GitHub-Enterprise-Host and are matched against host-qualified
+// repos (host/owner/repo).
+
+const (
+	// defaultWebhookPromote is how long webhook activity keeps a repo
+	// hot when github.webhooks.promote_hours is 0.
+	defaultWebhookPromote = 6 * time.Hour
+
+	// maxWebhookBody is GitHub's cap on delivery payloads.
+	maxWebhookBody = 25 << 20
+
+	// maxRecentReleases bounds RepoState.RecentReleaseDates, as the
+	// collectors do.
+	maxRecentReleases = 10
+)
+
+// webhookArchiveTypes maps webhook events to the gharchive event type
+// the same activity appears as in the archives.
+var webhookArchiveTypes = map[string]string{
+	github.WebhookStar:        "WatchEvent",
+	github.WebhookFork:        "ForkEvent",
+	github.WebhookRelease:     "ReleaseEvent",
+	github.WebhookPullRequest: "PullRequestEvent",
+	github.WebhookIssues:      "IssuesEvent",
+}
+
+// webhookReceiver holds the webhook settings and the repos webhook
+// activity has promoted to the hot tier.
+type webhookReceiver struct {
+	secret  string
+	promote time.Duration
+
+	mu       sync.Mutex
+	promoted map[string]time.Time // store key -> hot until
+}
+
+// newWebhookReceiver returns the receiver for cfg, or nil when
+// webhooks are disabled.
+func newWebhookReceiver(cfg config.WebhookConfig) *webhookReceiver {
+	if !cfg.Enabled {
+		return nil
+	}
+	promote := defaultWebhookPromote
+	if cfg.PromoteHours > 0 {
+		promote = time.Duration(cfg.PromoteHours) * time.Hour
+	}
+	return &webhookReceiver{
+		secret:   cfg.Secret,
+		promote:  promote,
+		promoted: make(map[string]time.Time),
+	}
+}
+
+// promoteRepo keeps key hot for the promotion period from now.
+func (wr *webhookReceiver) promoteRepo(key string, now time.Time) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.promoted[key] = now.Add(wr.promote)
+}
+
+// promotedUntil returns when key's promotion ends; zero when it has
+// none. Safe on a nil receiver.
+func (wr *webhookReceiver) promotedUntil(key string) time.Time {
+	if wr == nil {
+		return time.Time{}
+	}
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return wr.promoted[key]
+}
+
+// prune forgets promotions that ended before now.
+func (wr *webhookReceiver) prune(now time.Time) {
+	if wr == nil {
+		return
+	}
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	for key, until := range wr.promoted {
+		if !until.After(now) {
+			delete(wr.promoted, key)
+		}
+	}
+}
+
+// handleGitHubWebhook serves /webhooks/github.
+func (d *Daemon) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "reading payload", http.StatusBadRequest)
+		return
+	}
+	if err := github.VerifyWebhookSignature(d.webhooks.secret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		logging.Warn("rejected webhook delivery",
+			"delivery", r.Header.Get("X-GitHub-Delivery"),
+			"error", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event == github.WebhookPing {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	e, err := github.ParseWebhookEvent(event, body)
+	if errors.Is(err, github.ErrUnsupportedWebhook) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	d.applyWebhookEvent(e, r.Header.Get("X-GitHub-Enterprise-Host"), time.Now())
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// applyWebhookEvent folds one delivery into the store, the refresh-tier
+// promotions and the discovery window. host is empty for github.com.
+func (d *Daemon) applyWebhookEvent(e github.WebhookEvent, host string, now time.Time) {
+	key := e.FullName
+	if host != "" {
+		key = host + "/" + e.FullName
+	}
+
+	storeKey, tracked := d.store.UpdateRepoState(key, func(rs *state.RepoState) {
+		if e.HasCounts {
+			rs.Stars, rs.Forks = e.Stars, e.Forks
+		}
+		if !e.ReleasePublishedAt.IsZero() {
+			addRelease(rs, e.ReleasePublishedAt)
+		}
+		switch {
+		case e.Event == github.WebhookPullRequest && e.Action == "closed" && e.Merged:
+			rs.MergedPRs7d++
+		case e.Event == github.WebhookIssues && e.Action == "opened":
+			rs.NewIssues7d++
+		}
+	})
+
+	activity := e.IsActivity()
+	if tracked && activity {
+		d.webhooks.promoteRepo(storeKey, now)
+	}
+	counted := false
+	if activity && host == "" && d.ghArchiveCollector != nil {
+		counted = d.ghArchiveCollector.RecordLiveEvent(e.FullName, webhookArchiveTypes[e.Event], e.Sender, now)
+	}
+
+	logging.Debug("webhook delivery applied",
+		logging.AttrRepoFull, key,
+		"event", e.Event,
+		"action", e.Action,
+		"tracked", tracked,
+		"promoted", tracked && activity,
+		"discovery_counted", counted)
+}
+
+// addRelease records a release published at t on rs, newest first.
+func addRelease(rs *state.RepoState, t time.Time) {
+	if t.After(rs.LatestReleaseAt) {
+		rs.LatestReleaseAt = t
+	}
+	for _, existing := range rs.RecentReleaseDates {
+		if existing.Equal(t) {
+			return
+		}
+	}
+	rs.RecentReleaseDates = append([]time.Time{t}, rs.RecentReleaseDates...)
+	sort.Slice(rs.RecentReleaseDates, func(i, j int) bool {
+		return rs.RecentReleaseDates[i].After(rs.RecentReleaseDates[j])
+	})
+	if len(rs.RecentReleaseDates) > maxRecentReleases {
+		rs.RecentReleaseDates = rs.RecentReleaseDates[:maxRecentReleases]
+	}
+}
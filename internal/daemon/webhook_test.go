@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/discovery"
+	"github.com/hrexed/github-radar/internal/github"
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+func deliverWebhook(t *testing.T, d *Daemon, event, secret, body string) int {
+	t.Helper()
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/github", bytes.NewBufferString(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	w := httptest.NewRecorder()
+	d.handleGitHubWebhook(w, req)
+	return w.Code
+}
+
+func TestWebhook_UpdatesStoreAndPromotes(t *testing.T) {
+	store := state.NewStore(t.TempDir() + "/state.json")
+	store.SetRepoState("acme/radar", state.RepoState{
+		Owner: "acme", Name: "radar", Stars: 100, StarsPrev: 90,
+		LastCollected: time.Now().Add(-3 * time.Hour),
+	})
+	src := discovery.NewGHArchiveSource(mapDiscoveryGHArchiveCollectorConfig(config.DiscoveryGHArchiveConfig{WindowHours: 24}),
+		discovery.NewMemoryCursorStore(), nil, discovery.GHArchiveHooks{})
+	d := &Daemon{
+		cfg:                &config.Config{GitHub: config.GithubConfig{Tiering: config.TieringConfig{HotN: 1, WarmN: 1}}},
+		store:              store,
+		ghArchiveCollector: src,
+		webhooks:           newWebhookReceiver(config.WebhookConfig{Enabled: true, Secret: "s3cret"}),
+	}
+	// Two busier repos keep acme/radar in the cold tier by rank.
+	store.SetRepoState("big/one", state.RepoState{GrowthScore: 100, LastCollected: time.Now()})
+	store.SetRepoState("big/two", state.RepoState{GrowthScore: 50, LastCollected: time.Now()})
+
+	if code := deliverWebhook(t, d, "star", "wrong", `{"action":"created"}`); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d, want 401", code)
+	}
+	if code := deliverWebhook(t, d, "push", "s3cret", `{}`); code != http.StatusNoContent {
+		t.Errorf("push event: status %d, want 204", code)
+	}
+
+	star := `{"action":"created","repository":{"full_name":"acme/radar","stargazers_count":101,"forks_count":7},"sender":{"login":"octocat"}}`
+	if code := deliverWebhook(t, d, "star", "s3cret", star); code != http.StatusAccepted {
+		t.Fatalf("star event: status %d, want 202", code)
+	}
+	release := `{"action":"published","release":{"published_at":"2026-10-18T09:00:00Z"},"repository":{"full_name":"acme/radar","stargazers_count":101,"forks_count":7}}`
+	if code := deliverWebhook(t, d, "release", "s3cret", release); code != http.StatusAccepted {
+		t.Fatalf("release event: status %d, want 202", code)
+	}
+
+	rs := store.GetRepoState("acme/radar")
+	if rs.Stars != 101 || rs.StarsPrev != 90 || rs.Forks != 7 {
+		t.Errorf("state = %+v, want stars 101 (prev untouched) and forks 7", rs)
+	}
+	if want := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC); !rs.LatestReleaseAt.Equal(want) || len(rs.RecentReleaseDates) != 1 {
+		t.Errorf("release not recorded: latest %v, history %v", rs.LatestReleaseAt, rs.RecentReleaseDates)
+	}
+
+	var tier github.RefreshTier = -1
+	for _, a := range github.ClassifyAll(d.buildTierCandidates(), time.Now(), tierConfigFromYAML(d.cfg.GitHub.Tiering)) {
+		if a.FullName == "acme/radar" {
+			tier = a.Tier
+			if !a.IsDue {
+				t.Error("promoted repo collected 3h ago is not due")
+			}
+		}
+	}
+	if tier != github.TierHot {
+		t.Errorf("acme/radar tier = %s, want hot after webhook activity", tier)
+	}
+
+	// The star counts toward discovery; the release is outside the
+	// default type filter.
+	top := src.TopActiveRepos(0, 1)
+	if len(top) != 1 || top[0].RepoName != "acme/radar" || top[0].TotalEvents != 1 {
+		t.Errorf("discovery window = %+v, want one acme/radar event", top)
+	}
+
+	// Untracked repos still count toward discovery but are not stored.
+	fork := `{"action":"created","repository":{"full_name":"new/thing","stargazers_count":3,"forks_count":1}}`
+	if code := deliverWebhook(t, d, "fork", "s3cret", fork); code != http.StatusAccepted {
+		t.Fatalf("fork event: status %d, want 202", code)
+	}
+	if store.GetRepoState("new/thing") != nil {
+		t.Error("untracked repo added to the store")
+	}
+	if len(src.TopActiveRepos(0, 1)) != 2 {
+		t.Error("untracked fork not counted toward discovery")
+	}
+}
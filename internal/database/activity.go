@@ -0,0 +1,196 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// activityHourLayout is the storage format of the hour columns in
+// gharchive_hour_rollups and repo_activity_history. Lexical order
+// matches chronological order, so range scans compare strings.
+const activityHourLayout = "2006-01-02T15"
+
+// HourRollup is one (repo, hour) row of the gharchive discovery rollup.
+type HourRollup struct {
+	RepoName   string
+	Hour       time.Time // UTC, hour-aligned
+	EventCount int
+	PerType    map[string]int
+}
+
+// RepoActivityHour is one hour of event counts for a tracked repo.
+type RepoActivityHour struct {
+	RepoName     string
+	Hour         time.Time // UTC, hour-aligned
+	Stars        int
+	Forks        int
+	PullRequests int
+	Issues       int
+}
+
+// RepoDailyActivity is one UTC day of a repo's activity history.
+type RepoDailyActivity struct {
+	Day          string // YYYY-MM-DD
+	Stars        int
+	Forks        int
+	PullRequests int
+	Issues       int
+}
+
+func formatActivityHour(t time.Time) string {
+	return t.UTC().Truncate(time.Hour).Format(activityHourLayout)
+}
+
+// WriteHourRollups upserts rollup rows. Rewriting a (repo, hour) row
+// replaces it, so replaying an archive is idempotent.
+func (d *DB) WriteHourRollups(rows []HourRollup) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("writing hour rollups: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO gharchive_hour_rollups (repo_name, hour, event_count, per_type) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(repo_name, hour) DO UPDATE SET event_count = excluded.event_count, per_type = excluded.per_type`,
+	)
+	if err != nil {
+		return fmt.Errorf("writing hour rollups: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		perType, err := json.Marshal(r.PerType)
+		if err != nil {
+			return fmt.Errorf("encoding rollup types for %s: %w", r.RepoName, err)
+		}
+		if _, err := stmt.Exec(r.RepoName, formatActivityHour(r.Hour), r.EventCount, string(perType)); err != nil {
+			return fmt.Errorf("writing hour rollup for %s: %w", r.RepoName, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// HourRollupsSince returns every rollup row at or after since, oldest
+// hour first.
+func (d *DB) HourRollupsSince(since time.Time) ([]HourRollup, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(
+		"SELECT repo_name, hour, event_count, per_type FROM gharchive_hour_rollups WHERE hour >= ? ORDER BY hour, repo_name",
+		formatActivityHour(since),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing hour rollups: %w", err)
+	}
+	defer rows.Close()
+
+	var out []HourRollup
+	for rows.Next() {
+		var (
+			r       HourRollup
+			hour    string
+			perType string
+		)
+		if err := rows.Scan(&r.RepoName, &hour, &r.EventCount, &perType); err != nil {
+			return nil, fmt.Errorf("scanning hour rollup: %w", err)
+		}
+		if r.Hour, err = time.ParseInLocation(activityHourLayout, hour, time.UTC); err != nil {
+			return nil, fmt.Errorf("parsing rollup hour %q: %w", hour, err)
+		}
+		if err := json.Unmarshal([]byte(perType), &r.PerType); err != nil {
+			return nil, fmt.Errorf("decoding rollup types for %s: %w", r.RepoName, err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// PruneHourRollups deletes rollup rows older than before and returns
+// how many were removed.
+func (d *DB) PruneHourRollups(before time.Time) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	res, err := d.db.Exec("DELETE FROM gharchive_hour_rollups WHERE hour < ?", formatActivityHour(before))
+	if err != nil {
+		return 0, fmt.Errorf("pruning hour rollups: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// UpsertRepoActivity records hourly activity for tracked repos.
+// Rewriting a (repo, hour) row replaces it.
+func (d *DB) UpsertRepoActivity(rows []RepoActivityHour) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("writing repo activity: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO repo_activity_history (repo_name, hour, stars, forks, pull_requests, issues) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(repo_name, hour) DO UPDATE SET
+		   stars = excluded.stars, forks = excluded.forks,
+		   pull_requests = excluded.pull_requests, issues = excluded.issues`,
+	)
+	if err != nil {
+		return fmt.Errorf("writing repo activity: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.Exec(r.RepoName, formatActivityHour(r.Hour), r.Stars, r.Forks, r.PullRequests, r.Issues); err != nil {
+			return fmt.Errorf("writing repo activity for %s: %w", r.RepoName, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// RepoDailyActivityRange returns repo's activity history summed per UTC
+// day for days in [from, to], oldest first. Days without activity are
+// omitted.
+func (d *DB) RepoDailyActivityRange(repo string, from, to time.Time) ([]RepoDailyActivity, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(
+		`SELECT substr(hour, 1, 10) AS day, SUM(stars), SUM(forks), SUM(pull_requests), SUM(issues)
+		 FROM repo_activity_history
+		 WHERE repo_name = ? AND hour >= ? AND hour < ?
+		 GROUP BY day ORDER BY day`,
+		repo,
+		formatActivityHour(from.UTC().Truncate(24*time.Hour)),
+		formatActivityHour(to.UTC().Truncate(24*time.Hour).Add(24*time.Hour)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing activity for %s: %w", repo, err)
+	}
+	defer rows.Close()
+
+	var out []RepoDailyActivity
+	for rows.Next() {
+		var a RepoDailyActivity
+		if err := rows.Scan(&a.Day, &a.Stars, &a.Forks, &a.PullRequests, &a.Issues); err != nil {
+			return nil, fmt.Errorf("scanning activity for %s: %w", repo, err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
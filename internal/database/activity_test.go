@@ -0,0 +1,83 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHourRollups_RoundtripAndPrune(t *testing.T) {
+	db := mustOpen(t)
+	h0 := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	h1 := h0.Add(time.Hour)
+
+	if err := db.WriteHourRollups([]HourRollup{
+		{RepoName: "acme/app", Hour: h0, EventCount: 3, PerType: map[string]int{"WatchEvent": 3}},
+		{RepoName: "acme/app", Hour: h1, EventCount: 1, PerType: map[string]int{"ForkEvent": 1}},
+	}); err != nil {
+		t.Fatalf("WriteHourRollups: %v", err)
+	}
+	// Replaying an hour replaces the row.
+	if err := db.WriteHourRollups([]HourRollup{
+		{RepoName: "acme/app", Hour: h1, EventCount: 2, PerType: map[string]int{"ForkEvent": 2}},
+	}); err != nil {
+		t.Fatalf("WriteHourRollups replay: %v", err)
+	}
+
+	got, err := db.HourRollupsSince(h0)
+	if err != nil {
+		t.Fatalf("HourRollupsSince: %v", err)
+	}
+	want := []HourRollup{
+		{RepoName: "acme/app", Hour: h0, EventCount: 3, PerType: map[string]int{"WatchEvent": 3}},
+		{RepoName: "acme/app", Hour: h1, EventCount: 2, PerType: map[string]int{"ForkEvent": 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rollups = %+v, want %+v", got, want)
+	}
+
+	n, err := db.PruneHourRollups(h1)
+	if err != nil || n != 1 {
+		t.Fatalf("PruneHourRollups = %d, %v; want 1 row", n, err)
+	}
+	if got, _ := db.HourRollupsSince(h0); len(got) != 1 || !got[0].Hour.Equal(h1) {
+		t.Errorf("after prune = %+v, want only %v", got, h1)
+	}
+}
+
+func TestRepoDailyActivityRange(t *testing.T) {
+	db := mustOpen(t)
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	rows := []RepoActivityHour{
+		{RepoName: "acme/app", Hour: day1.Add(2 * time.Hour), Stars: 5, Forks: 1},
+		{RepoName: "acme/app", Hour: day1.Add(9 * time.Hour), Stars: 2, PullRequests: 3},
+		{RepoName: "acme/app", Hour: day2.Add(1 * time.Hour), Issues: 4},
+		{RepoName: "other/repo", Hour: day1, Stars: 100},
+	}
+	if err := db.UpsertRepoActivity(rows); err != nil {
+		t.Fatalf("UpsertRepoActivity: %v", err)
+	}
+	// Replaying an hour must not double-count.
+	if err := db.UpsertRepoActivity(rows[:1]); err != nil {
+		t.Fatalf("UpsertRepoActivity replay: %v", err)
+	}
+
+	got, err := db.RepoDailyActivityRange("ACME/app", day1, day2)
+	if err != nil {
+		t.Fatalf("RepoDailyActivityRange: %v", err)
+	}
+	want := []RepoDailyActivity{
+		{Day: "2026-03-01", Stars: 7, Forks: 1, PullRequests: 3},
+		{Day: "2026-03-02", Issues: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("daily = %+v, want %+v", got, want)
+	}
+
+	got, err = db.RepoDailyActivityRange("acme/app", day2, day2)
+	if err != nil || len(got) != 1 || got[0].Day != "2026-03-02" {
+		t.Errorf("single-day range = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Alias reasons recorded in repo_aliases.
+const (
+	// AliasReasonRenamed maps a repo's old name to its new one after
+	// GitHub answered the old name with a redirect.
+	AliasReasonRenamed = "renamed"
+	// AliasReasonFork maps a fork to the root of its fork network.
+	AliasReasonFork = "fork"
+	// AliasReasonMirror marks a mirror of an external repository. The
+	// canonical name is the mirror itself — there is no GitHub-side
+	// upstream — so the row only records that the repo is a mirror.
+	AliasReasonMirror = "mirror"
+)
+
+// RepoAlias is one row of the repo_aliases table.
+type RepoAlias struct {
+	Alias     string
+	Canonical string
+	Reason    string
+	CreatedAt string
+}
+
+// SetRepoAlias records that alias resolves to canonical. Existing
+// aliases pointing at alias are re-pointed at canonical so chains of
+// renames (a → b → c) always resolve in one lookup.
+func (d *DB) SetRepoAlias(alias, canonical, reason string) error {
+	if alias == "" || canonical == "" {
+		return fmt.Errorf("setting repo alias: empty name")
+	}
+	if strings.EqualFold(alias, canonical) && reason != AliasReasonMirror {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("setting repo alias %s: %w", alias, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO repo_aliases (alias, canonical, reason) VALUES (?, ?, ?)
+		 ON CONFLICT(alias) DO UPDATE SET canonical = excluded.canonical, reason = excluded.reason`,
+		alias, canonical, reason,
+	); err != nil {
+		return fmt.Errorf("setting repo alias %s: %w", alias, err)
+	}
+	if _, err := tx.Exec(
+		"UPDATE repo_aliases SET canonical = ? WHERE canonical = ? COLLATE NOCASE AND alias <> ?",
+		canonical, alias, canonical,
+	); err != nil {
+		return fmt.Errorf("collapsing alias chain for %s: %w", alias, err)
+	}
+	// The new canonical name may itself have been an alias (a rename
+	// back to an old name); drop that row so it cannot loop.
+	if _, err := tx.Exec("DELETE FROM repo_aliases WHERE alias = ? AND reason = ?", canonical, AliasReasonRenamed); err != nil {
+		return fmt.Errorf("clearing stale alias %s: %w", canonical, err)
+	}
+	return tx.Commit()
+}
+
+// ResolveRepoAlias returns the canonical name and reason recorded for
+// name. Both are empty when name has no alias. Lookups are
+// case-insensitive.
+func (d *DB) ResolveRepoAlias(name string) (canonical, reason string, err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	err = d.db.QueryRow(
+		"SELECT canonical, reason FROM repo_aliases WHERE alias = ?",
+		name,
+	).Scan(&canonical, &reason)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("resolving repo alias %s: %w", name, err)
+	}
+	return canonical, reason, nil
+}
+
+// AllRepoAliases returns every recorded alias, ordered by alias.
+func (d *DB) AllRepoAliases() ([]RepoAlias, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query("SELECT alias, canonical, reason, created_at FROM repo_aliases ORDER BY alias")
+	if err != nil {
+		return nil, fmt.Errorf("listing repo aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var out []RepoAlias
+	for rows.Next() {
+		var a RepoAlias
+		if err := rows.Scan(&a.Alias, &a.Canonical, &a.Reason, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning repo alias: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// MergeRepo moves the repos row stored under alias to canonical. When
+// canonical already has a row it is kept (it carries the current
+// classification) and the alias row is dropped; otherwise the alias row
+// is renamed in place so its classification and first_seen_at survive.
+// Secondary labels follow the surviving classification, and known-repo
+// markers move with it.
+func (d *DB) MergeRepo(alias, canonical string) error {
+	if alias == "" || canonical == "" || alias == canonical {
+		return nil
+	}
+	owner, name, ok := strings.Cut(canonical, "/")
+	if !ok {
+		return fmt.Errorf("merging repo %s: malformed canonical name %q", alias, canonical)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("merging repo %s: %w", alias, err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM repos WHERE full_name = ?", canonical).Scan(&exists); err != nil {
+		return fmt.Errorf("merging repo %s: %w", alias, err)
+	}
+	if exists > 0 {
+		_, err = tx.Exec("DELETE FROM repos WHERE full_name = ?", alias)
+		for _, table := range repoChildTables {
+			if err != nil {
+				break
+			}
+			_, err = tx.Exec("DELETE FROM "+table+" WHERE full_name = ?", alias)
+		}
+	} else {
+		_, err = tx.Exec(
+			"UPDATE repos SET full_name = ?, owner = ?, name = ? WHERE full_name = ?",
+			canonical, owner, name, alias,
+		)
+		for _, table := range repoChildTables {
+			if err != nil {
+				break
+			}
+			_, err = tx.Exec("UPDATE "+table+" SET full_name = ? WHERE full_name = ?", canonical, alias)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("merging repo %s into %s: %w", alias, canonical, err)
+	}
+
+	if _, err := tx.Exec("INSERT OR IGNORE INTO discovery_known_repos (full_name) SELECT ? WHERE EXISTS (SELECT 1 FROM discovery_known_repos WHERE full_name = ?)", canonical, alias); err != nil {
+		return fmt.Errorf("merging known repo %s: %w", alias, err)
+	}
+	if _, err := tx.Exec("DELETE FROM discovery_known_repos WHERE full_name = ?", alias); err != nil {
+		return fmt.Errorf("merging known repo %s: %w", alias, err)
+	}
+	return tx.Commit()
+}
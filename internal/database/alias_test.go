@@ -0,0 +1,87 @@
+package database
+
+import "testing"
+
+func TestRepoAliases(t *testing.T) {
+	db := mustOpen(t)
+
+	canonical, reason, err := db.ResolveRepoAlias("a/old")
+	if err != nil || canonical != "" || reason != "" {
+		t.Fatalf("ResolveRepoAlias(unknown) = (%q, %q, %v), want empty", canonical, reason, err)
+	}
+
+	if err := db.SetRepoAlias("a/old", "a/mid", AliasReasonRenamed); err != nil {
+		t.Fatalf("SetRepoAlias: %v", err)
+	}
+	// A second rename re-points the first alias so chains resolve in
+	// one hop.
+	if err := db.SetRepoAlias("a/mid", "b/new", AliasReasonRenamed); err != nil {
+		t.Fatalf("SetRepoAlias: %v", err)
+	}
+	canonical, reason, err = db.ResolveRepoAlias("A/Old")
+	if err != nil {
+		t.Fatalf("ResolveRepoAlias: %v", err)
+	}
+	if canonical != "b/new" || reason != AliasReasonRenamed {
+		t.Errorf("ResolveRepoAlias(A/Old) = (%q, %q), want (b/new, renamed)", canonical, reason)
+	}
+
+	if err := db.SetRepoAlias("me/kubernetes", "kubernetes/kubernetes", AliasReasonFork); err != nil {
+		t.Fatalf("SetRepoAlias(fork): %v", err)
+	}
+	if err := db.SetRepoAlias("x/mirror", "x/mirror", AliasReasonMirror); err != nil {
+		t.Fatalf("SetRepoAlias(mirror): %v", err)
+	}
+
+	all, err := db.AllRepoAliases()
+	if err != nil {
+		t.Fatalf("AllRepoAliases: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("AllRepoAliases returned %d rows, want 4: %+v", len(all), all)
+	}
+}
+
+func TestMergeRepo(t *testing.T) {
+	db := mustOpen(t)
+
+	if err := db.UpsertRepo(&RepoRecord{FullName: "a/old", Owner: "a", Name: "old", Stars: 10, Status: "active", PrimaryCategory: "observability"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+	if err := db.MarkKnownRepo("a/old"); err != nil {
+		t.Fatalf("MarkKnownRepo: %v", err)
+	}
+
+	// No canonical row yet: the alias row is renamed in place and
+	// keeps its classification.
+	if err := db.MergeRepo("a/old", "b/new"); err != nil {
+		t.Fatalf("MergeRepo: %v", err)
+	}
+	if got, _ := db.GetRepo("a/old"); got != nil {
+		t.Error("alias row still present")
+	}
+	got, err := db.GetRepo("b/new")
+	if err != nil || got == nil {
+		t.Fatalf("GetRepo(b/new) = %v, %v", got, err)
+	}
+	if got.Owner != "b" || got.Name != "new" || got.PrimaryCategory != "observability" {
+		t.Errorf("moved row = %+v, want owner b, name new, category kept", got)
+	}
+	if known, _ := db.IsKnownRepo("b/new"); !known {
+		t.Error("known marker not moved")
+	}
+
+	// Canonical row exists: the alias row is dropped.
+	if err := db.UpsertRepo(&RepoRecord{FullName: "c/dup", Owner: "c", Name: "dup", Status: "pending"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+	if err := db.MergeRepo("c/dup", "b/new"); err != nil {
+		t.Fatalf("MergeRepo: %v", err)
+	}
+	if got, _ := db.GetRepo("c/dup"); got != nil {
+		t.Error("duplicate alias row not dropped")
+	}
+	if got, _ := db.GetRepo("b/new"); got == nil || got.PrimaryCategory != "observability" {
+		t.Errorf("canonical row = %+v, want untouched", got)
+	}
+}
@@ -166,6 +166,106 @@ func (d *DB) initSchema() error {
 		topic    TEXT PRIMARY KEY,
 		scanned_at TEXT NOT NULL
 	);
+
+	-- Repo canonicalization: renamed repos, forks and mirrors mapped
+	-- to the name their history lives under.
+	CREATE TABLE IF NOT EXISTS repo_aliases (
+		alias      TEXT PRIMARY KEY COLLATE NOCASE,
+		canonical  TEXT NOT NULL,
+		reason     TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (datetime('now'))
+	);
+	CREATE INDEX IF NOT EXISTS idx_repo_aliases_canonical ON repo_aliases(canonical);
+
+	-- Secondary classification labels, ranked from 1 after the primary
+	-- category held on the repos row.
+	CREATE TABLE IF NOT EXISTS repo_category_labels (
+		full_name   TEXT    NOT NULL,
+		rank        INTEGER NOT NULL,
+		category    TEXT    NOT NULL,
+		subcategory TEXT    NOT NULL DEFAULT '',
+		confidence  REAL    NOT NULL DEFAULT 0,
+		PRIMARY KEY (full_name, rank)
+	);
+	CREATE INDEX IF NOT EXISTS idx_repo_category_labels_cat ON repo_category_labels(category, subcategory);
+
+	-- Ensemble classification: the agreement of the last vote and each
+	-- member's answer, in member order.
+	CREATE TABLE IF NOT EXISTS repo_ensemble_runs (
+		full_name TEXT PRIMARY KEY,
+		agreement REAL NOT NULL,
+		voted_at  TEXT NOT NULL DEFAULT (datetime('now'))
+	);
+	CREATE TABLE IF NOT EXISTS repo_ensemble_votes (
+		full_name  TEXT    NOT NULL,
+		member     INTEGER NOT NULL,
+		model      TEXT    NOT NULL,
+		category   TEXT    NOT NULL,
+		confidence REAL    NOT NULL DEFAULT 0,
+		reasoning  TEXT    NOT NULL DEFAULT '',
+		PRIMARY KEY (full_name, member)
+	);
+
+	-- Classifier reasoning behind the current primary category, shown
+	-- to reviewers.
+	CREATE TABLE IF NOT EXISTS repo_classification_reasoning (
+		full_name TEXT PRIMARY KEY,
+		reasoning TEXT NOT NULL DEFAULT ''
+	);
+
+	-- Description and topics the classifier last saw for a repo, kept so
+	-- labeled repos can serve as few-shot examples without a refetch.
+	CREATE TABLE IF NOT EXISTS repo_classification_inputs (
+		full_name   TEXT PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT '',
+		topics      TEXT NOT NULL DEFAULT ''
+	);
+
+	-- Embedding vector of a repo's classifier inputs, little-endian
+	-- float32, for the pre-classifier and similarity search.
+	CREATE TABLE IF NOT EXISTS repo_embeddings (
+		full_name   TEXT PRIMARY KEY,
+		model       TEXT    NOT NULL,
+		dims        INTEGER NOT NULL,
+		vector      BLOB    NOT NULL,
+		embedded_at TEXT    NOT NULL DEFAULT (datetime('now'))
+	);
+
+	-- Human review decisions on classifications, oldest first.
+	CREATE TABLE IF NOT EXISTS classification_reviews (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		full_name   TEXT NOT NULL,
+		action      TEXT NOT NULL,
+		category    TEXT NOT NULL,
+		subcategory TEXT NOT NULL,
+		reviewer    TEXT NOT NULL,
+		reviewed_at TEXT NOT NULL DEFAULT (datetime('now'))
+	);
+	CREATE INDEX IF NOT EXISTS idx_classification_reviews_repo ON classification_reviews(full_name);
+
+	-- gharchive discovery rollup: per-repo per-hour event counts for the
+	-- trailing discovery window, written by backfill and read back to
+	-- warm the in-memory window on daemon start.
+	CREATE TABLE IF NOT EXISTS gharchive_hour_rollups (
+		repo_name   TEXT    NOT NULL,
+		hour        TEXT    NOT NULL,
+		event_count INTEGER NOT NULL,
+		per_type    TEXT    NOT NULL DEFAULT '{}',
+		PRIMARY KEY (repo_name, hour)
+	);
+	CREATE INDEX IF NOT EXISTS idx_gharchive_hour_rollups_hour ON gharchive_hour_rollups(hour);
+
+	-- Activity history for tracked repos, one row per repo-hour so
+	-- replaying an archive overwrites instead of double-counting.
+	CREATE TABLE IF NOT EXISTS repo_activity_history (
+		repo_name     TEXT    NOT NULL COLLATE NOCASE,
+		hour          TEXT    NOT NULL,
+		stars         INTEGER NOT NULL DEFAULT 0,
+		forks         INTEGER NOT NULL DEFAULT 0,
+		pull_requests INTEGER NOT NULL DEFAULT 0,
+		issues        INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (repo_name, hour)
+	);
 	`
 
 	if _, err := d.db.Exec(schema); err != nil {
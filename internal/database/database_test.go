@@ -184,6 +184,39 @@ func TestUpsertRepo_Update(t *testing.T) {
 	}
 }
 
+func TestGetRepo_HostDerivedFromFullName(t *testing.T) {
+	db := mustOpen(t)
+
+	for _, r := range []*RepoRecord{
+		{FullName: "team/svc", Owner: "team", Name: "svc", Stars: 10, Status: "pending"},
+		{FullName: "ghe.corp/team/svc", Owner: "team", Name: "svc", Stars: 20, Status: "pending"},
+	} {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo(%s): %v", r.FullName, err)
+		}
+	}
+
+	got, err := db.GetRepo("ghe.corp/team/svc")
+	if err != nil || got == nil {
+		t.Fatalf("GetRepo: %v, %v", got, err)
+	}
+	if got.Host != "ghe.corp" || got.Stars != 20 {
+		t.Errorf("enterprise repo = host %q stars %d, want ghe.corp / 20", got.Host, got.Stars)
+	}
+
+	all, err := db.AllReposIncludeExcluded()
+	if err != nil {
+		t.Fatalf("AllReposIncludeExcluded: %v", err)
+	}
+	hosts := map[string]string{}
+	for _, r := range all {
+		hosts[r.FullName] = r.Host
+	}
+	if len(hosts) != 2 || hosts["team/svc"] != "" || hosts["ghe.corp/team/svc"] != "ghe.corp" {
+		t.Errorf("hosts by full_name = %v", hosts)
+	}
+}
+
 func TestGetRepo_NotFound(t *testing.T) {
 	db := mustOpen(t)
 
@@ -1054,7 +1087,7 @@ func TestUpdateClassification_HighConfidence(t *testing.T) {
 		t.Fatalf("UpsertRepo: %v", err)
 	}
 
-	err := db.UpdateClassification("test/repo", "kubernetes", 0.92, "hash123", "qwen3:1.7b", 0.6)
+	err := db.UpdateClassification("test/repo", "kubernetes", "", 0.92, "hash123", "qwen3:1.7b", 0.6)
 	if err != nil {
 		t.Fatalf("UpdateClassification: %v", err)
 	}
@@ -1095,7 +1128,7 @@ func TestUpdateClassification_LowConfidence_NeedsReview(t *testing.T) {
 	}
 
 	// Confidence 0.3 is below minConfidence 0.6
-	err := db.UpdateClassification("test/repo", "other", 0.3, "hash456", "qwen3:1.7b", 0.6)
+	err := db.UpdateClassification("test/repo", "other", "", 0.3, "hash456", "qwen3:1.7b", 0.6)
 	if err != nil {
 		t.Fatalf("UpdateClassification: %v", err)
 	}
@@ -1124,7 +1157,7 @@ func TestUpdateClassification_ExactThreshold(t *testing.T) {
 	}
 
 	// Confidence exactly at threshold → should be "active" (not < threshold)
-	err := db.UpdateClassification("test/repo", "kubernetes", 0.6, "hash789", "qwen3:1.7b", 0.6)
+	err := db.UpdateClassification("test/repo", "kubernetes", "", 0.6, "hash789", "qwen3:1.7b", 0.6)
 	if err != nil {
 		t.Fatalf("UpdateClassification: %v", err)
 	}
@@ -1152,7 +1185,7 @@ func TestUpdateClassification_Reclassify(t *testing.T) {
 	}
 
 	// Re-classify with new category
-	err := db.UpdateClassification("test/repo", "kubernetes", 0.95, "newhash", "new-model", 0.6)
+	err := db.UpdateClassification("test/repo", "kubernetes", "", 0.95, "newhash", "new-model", 0.6)
 	if err != nil {
 		t.Fatalf("UpdateClassification: %v", err)
 	}
@@ -1170,4 +1203,19 @@ func TestUpdateClassification_Reclassify(t *testing.T) {
 	if got.ReadmeHash != "newhash" {
 		t.Errorf("ReadmeHash = %q, want %q", got.ReadmeHash, "newhash")
 	}
+
+	// A v3 pair sets the subcategory; a later classifier category
+	// clears it rather than leaving the old one behind.
+	if err := db.UpdateClassification("test/repo", "cloud-native", "gitops", 1, "newhash", "rule:r1", 0.6); err != nil {
+		t.Fatalf("UpdateClassification(pair): %v", err)
+	}
+	if got, _ := db.GetRepo("test/repo"); got.PrimaryCategory != "cloud-native" || got.PrimarySubcategory != "gitops" {
+		t.Errorf("after pair: %q/%q, want cloud-native/gitops", got.PrimaryCategory, got.PrimarySubcategory)
+	}
+	if err := db.UpdateClassification("test/repo", "kubernetes", "", 0.9, "newhash", "new-model", 0.6); err != nil {
+		t.Fatalf("UpdateClassification: %v", err)
+	}
+	if got, _ := db.GetRepo("test/repo"); got.PrimarySubcategory != "" {
+		t.Errorf("PrimarySubcategory = %q after reclassifying to a classifier category, want empty", got.PrimarySubcategory)
+	}
 }
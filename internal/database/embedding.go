@@ -0,0 +1,89 @@
+package database
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// EmbeddingModelPrefix marks model_used for repos the embedding
+// pre-classifier decided without calling the LLM.
+const EmbeddingModelPrefix = "embedding:"
+
+// SetEmbedding stores the embedding of fullName computed by model,
+// replacing any earlier one.
+func (d *DB) SetEmbedding(fullName, model string, vec []float32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(`
+		INSERT INTO repo_embeddings (full_name, model, dims, vector) VALUES (?, ?, ?, ?)
+		ON CONFLICT(full_name) DO UPDATE SET
+			model = excluded.model, dims = excluded.dims, vector = excluded.vector,
+			embedded_at = datetime('now')`,
+		fullName, model, len(vec), encodeVector(vec),
+	)
+	if err != nil {
+		return fmt.Errorf("storing embedding for %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// LabeledEmbeddings returns the repos with an embedding from model that
+// can seed category centroids: those with a pinned category, and active
+// repos the LLM classified with at least minConfidence. Repos decided by
+// the pre-classifier itself are left out so centroids do not drift
+// toward their own answers. Ordered by full_name.
+func (d *DB) LabeledEmbeddings(model string, minConfidence float64) ([]LabeledExample, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(`
+		SELECT r.full_name, r.language, r.primary_category, r.primary_subcategory,
+		       r.force_category, r.force_subcategory, e.vector
+		FROM repos r
+		JOIN repo_embeddings e ON e.full_name = r.full_name AND e.model = ?
+		WHERE r.excluded = 0
+		  AND (r.force_category != ''
+		       OR (r.status = 'active' AND r.primary_category != ''
+		           AND r.category_confidence >= ? AND r.model_used NOT LIKE ?))
+		ORDER BY r.full_name`,
+		model, minConfidence, EmbeddingModelPrefix+"%")
+	if err != nil {
+		return nil, fmt.Errorf("querying labeled embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var out []LabeledExample
+	for rows.Next() {
+		var ex LabeledExample
+		var rec RepoRecord
+		var blob []byte
+		if err := rows.Scan(&ex.FullName, &ex.Language, &rec.PrimaryCategory, &rec.PrimarySubcategory,
+			&rec.ForceCategory, &rec.ForceSubcategory, &blob); err != nil {
+			return nil, fmt.Errorf("scanning labeled embedding: %w", err)
+		}
+		ex.Category, ex.Subcategory, _ = rec.ResolveTaxonomy()
+		ex.Embedding = decodeVector(blob)
+		out = append(out, ex)
+	}
+	return out, rows.Err()
+}
+
+// encodeVector packs vec as little-endian float32s.
+func encodeVector(vec []float32) []byte {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// decodeVector is the inverse of encodeVector.
+func decodeVector(buf []byte) []float32 {
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vec
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabeledEmbeddings(t *testing.T) {
+	db := mustOpen(t)
+
+	repos := []*RepoRecord{
+		{FullName: "a/forced", Owner: "a", Name: "forced", Status: "active", ForceCategory: "ai", ForceSubcategory: "agents"},
+		{FullName: "a/llm", Owner: "a", Name: "llm", Status: "active"},
+		{FullName: "a/unsure", Owner: "a", Name: "unsure", Status: "active"},
+		{FullName: "a/embedded", Owner: "a", Name: "embedded", Status: "active"},
+		{FullName: "a/excluded", Owner: "a", Name: "excluded", Status: "active", ForceCategory: "ai", ForceSubcategory: "rag", Excluded: 1},
+		{FullName: "a/othermodel", Owner: "a", Name: "othermodel", Status: "active", ForceCategory: "ai", ForceSubcategory: "rag"},
+	}
+	for _, r := range repos {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+	classify := map[string][2]interface{}{
+		"a/llm":      {0.9, "llama3"},
+		"a/unsure":   {0.7, "llama3"},
+		"a/embedded": {0.95, EmbeddingModelPrefix + "m"},
+	}
+	for name, c := range classify {
+		if err := db.UpdateClassification(name, "kubernetes", "", c[0].(float64), "", c[1].(string), 0.6); err != nil {
+			t.Fatalf("UpdateClassification: %v", err)
+		}
+	}
+	for _, r := range repos {
+		model := "m"
+		if r.FullName == "a/othermodel" {
+			model = "old"
+		}
+		if err := db.SetEmbedding(r.FullName, model, []float32{0, 1}); err != nil {
+			t.Fatalf("SetEmbedding: %v", err)
+		}
+	}
+	// A second write replaces the first.
+	if err := db.SetEmbedding("a/forced", "m", []float32{0.5, -1.25}); err != nil {
+		t.Fatalf("SetEmbedding: %v", err)
+	}
+
+	got, err := db.LabeledEmbeddings("m", 0.8)
+	if err != nil {
+		t.Fatalf("LabeledEmbeddings: %v", err)
+	}
+	want := []LabeledExample{
+		{FullName: "a/forced", Category: "ai", Subcategory: "agents", Embedding: []float32{0.5, -1.25}},
+		{FullName: "a/llm", Category: "cloud-native", Subcategory: "kubernetes", Embedding: []float32{0, 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LabeledEmbeddings =\n%+v\nwant\n%+v", got, want)
+	}
+
+	examples, err := db.LabeledExamples("m")
+	if err != nil {
+		t.Fatalf("LabeledExamples: %v", err)
+	}
+	if len(examples) != 2 || examples[0].Embedding == nil || examples[1].Embedding != nil {
+		t.Errorf("LabeledExamples(m) = %+v, want a/forced with its vector and a/othermodel without", examples)
+	}
+
+	if err := db.DeleteRepo("a/llm"); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	var n int
+	db.SQL().QueryRow("SELECT COUNT(*) FROM repo_embeddings WHERE full_name = 'a/llm'").Scan(&n)
+	if n != 0 {
+		t.Errorf("embedding left after DeleteRepo")
+	}
+}
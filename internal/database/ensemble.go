@@ -0,0 +1,110 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// EnsembleVote is one ensemble member's answer for a repo.
+type EnsembleVote struct {
+	Model      string
+	Category   string
+	Confidence float64
+	Reasoning  string
+}
+
+// SetEnsembleVotes replaces the recorded ensemble vote of fullName with
+// agreement and votes, in member order. If agreement is below
+// minAgreement, the repo status is set to 'needs_review' so the
+// candidates can be looked at by hand.
+func (d *DB) SetEnsembleVotes(fullName string, agreement float64, votes []EnsembleVote, minAgreement float64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("setting ensemble votes for %s: %w", fullName, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM repo_ensemble_votes WHERE full_name = ?", fullName); err != nil {
+		return fmt.Errorf("clearing ensemble votes for %s: %w", fullName, err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO repo_ensemble_runs (full_name, agreement) VALUES (?, ?)
+		ON CONFLICT(full_name) DO UPDATE SET agreement = excluded.agreement, voted_at = datetime('now')`,
+		fullName, agreement,
+	); err != nil {
+		return fmt.Errorf("recording agreement for %s: %w", fullName, err)
+	}
+	for i, v := range votes {
+		if _, err := tx.Exec(
+			"INSERT INTO repo_ensemble_votes (full_name, member, model, category, confidence, reasoning) VALUES (?, ?, ?, ?, ?, ?)",
+			fullName, i+1, v.Model, v.Category, v.Confidence, v.Reasoning,
+		); err != nil {
+			return fmt.Errorf("inserting ensemble vote for %s: %w", fullName, err)
+		}
+	}
+	if agreement < minAgreement {
+		if _, err := tx.Exec("UPDATE repos SET status = 'needs_review' WHERE full_name = ?", fullName); err != nil {
+			return fmt.Errorf("flagging %s for review: %w", fullName, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ClearEnsembleVotes drops the recorded ensemble vote of fullName, for
+// a classification that was not decided by an ensemble.
+func (d *DB) ClearEnsembleVotes(fullName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("clearing ensemble votes for %s: %w", fullName, err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"repo_ensemble_votes", "repo_ensemble_runs"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE full_name = ?", fullName); err != nil {
+			return fmt.Errorf("clearing %s for %s: %w", table, fullName, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// EnsembleVotes returns the agreement and member votes recorded for
+// fullName by its last ensemble classification; nil votes when it has
+// none.
+func (d *DB) EnsembleVotes(fullName string) (float64, []EnsembleVote, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var agreement float64
+	err := d.db.QueryRow("SELECT agreement FROM repo_ensemble_runs WHERE full_name = ?", fullName).Scan(&agreement)
+	if err == sql.ErrNoRows {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("querying agreement for %s: %w", fullName, err)
+	}
+
+	rows, err := d.db.Query(
+		"SELECT model, category, confidence, reasoning FROM repo_ensemble_votes WHERE full_name = ? ORDER BY member",
+		fullName,
+	)
+	if err != nil {
+		return 0, nil, fmt.Errorf("querying ensemble votes for %s: %w", fullName, err)
+	}
+	defer rows.Close()
+
+	var votes []EnsembleVote
+	for rows.Next() {
+		var v EnsembleVote
+		if err := rows.Scan(&v.Model, &v.Category, &v.Confidence, &v.Reasoning); err != nil {
+			return 0, nil, fmt.Errorf("scanning ensemble vote: %w", err)
+		}
+		votes = append(votes, v)
+	}
+	return agreement, votes, rows.Err()
+}
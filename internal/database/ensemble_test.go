@@ -0,0 +1,69 @@
+package database
+
+import "testing"
+
+func TestEnsembleVotes_RoundTrip(t *testing.T) {
+	db := mustOpen(t)
+
+	if err := db.UpsertRepo(&RepoRecord{FullName: "a/a", Owner: "a", Name: "a", Status: "active"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+
+	if agreement, votes, err := db.EnsembleVotes("a/a"); err != nil || agreement != 0 || votes != nil {
+		t.Fatalf("EnsembleVotes before any vote = %v, %v, %v; want nothing", agreement, votes, err)
+	}
+
+	votes := []EnsembleVote{
+		{Model: "m1", Category: "kubernetes", Confidence: 0.9, Reasoning: "r1"},
+		{Model: "m2", Category: "kubernetes", Confidence: 0.7, Reasoning: "r2"},
+	}
+	if err := db.SetEnsembleVotes("a/a", 1, votes, 0.6); err != nil {
+		t.Fatalf("SetEnsembleVotes: %v", err)
+	}
+	agreement, got, err := db.EnsembleVotes("a/a")
+	if err != nil {
+		t.Fatalf("EnsembleVotes: %v", err)
+	}
+	if agreement != 1 || len(got) != 2 || got[0] != votes[0] || got[1] != votes[1] {
+		t.Errorf("EnsembleVotes = %v, %+v; want 1, %+v", agreement, got, votes)
+	}
+	if r, _ := db.GetRepo("a/a"); r.Status != "active" {
+		t.Errorf("status = %q, want active at full agreement", r.Status)
+	}
+
+	// A split vote replaces the candidates and flags the repo for review.
+	split := []EnsembleVote{
+		{Model: "m1", Category: "kubernetes", Confidence: 0.9},
+		{Model: "m2", Category: "networking", Confidence: 0.8},
+	}
+	if err := db.SetEnsembleVotes("a/a", 0.5, split, 0.6); err != nil {
+		t.Fatalf("SetEnsembleVotes: %v", err)
+	}
+	agreement, got, err = db.EnsembleVotes("a/a")
+	if err != nil {
+		t.Fatalf("EnsembleVotes: %v", err)
+	}
+	if agreement != 0.5 || len(got) != 2 || got[1].Category != "networking" {
+		t.Errorf("EnsembleVotes = %v, %+v; want the split vote", agreement, got)
+	}
+	if r, _ := db.GetRepo("a/a"); r.Status != "needs_review" {
+		t.Errorf("status = %q, want needs_review below min agreement", r.Status)
+	}
+
+	if err := db.ClearEnsembleVotes("a/a"); err != nil {
+		t.Fatalf("ClearEnsembleVotes: %v", err)
+	}
+	if agreement, got, err := db.EnsembleVotes("a/a"); err != nil || agreement != 0 || got != nil {
+		t.Errorf("EnsembleVotes after clear = %v, %+v, %v; want nothing", agreement, got, err)
+	}
+
+	if err := db.SetEnsembleVotes("a/a", 1, votes, 0.6); err != nil {
+		t.Fatalf("SetEnsembleVotes: %v", err)
+	}
+	if err := db.DeleteRepo("a/a"); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	if _, got, _ := db.EnsembleVotes("a/a"); got != nil {
+		t.Errorf("votes survived DeleteRepo: %+v", got)
+	}
+}
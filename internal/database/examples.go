@@ -0,0 +1,117 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabeledExample is a repo whose category a human has pinned, either
+// by force_category or through the review queue, with the inputs the
+// classifier last saw for it. The pipeline uses them as few-shot
+// examples.
+type LabeledExample struct {
+	FullName    string
+	Language    string
+	Description string
+	Topics      []string
+	Category    string // v3 category
+	Subcategory string
+
+	// Embedding is the repo's stored embedding, when one was requested
+	// and exists.
+	Embedding []float32
+}
+
+// SetClassificationInputs records the description and topics the
+// classifier was given for fullName.
+func (d *DB) SetClassificationInputs(fullName, description string, topics []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(`
+		INSERT INTO repo_classification_inputs (full_name, description, topics) VALUES (?, ?, ?)
+		ON CONFLICT(full_name) DO UPDATE SET description = excluded.description, topics = excluded.topics`,
+		fullName, description, strings.Join(topics, ","),
+	)
+	if err != nil {
+		return fmt.Errorf("recording classification inputs for %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// LabeledExamples returns every repo with a pinned category that is not
+// excluded, resolved to its v3 pair, ordered by full_name. Repos never
+// classified have an empty description and no topics. When
+// embeddingModel is set, each example carries its embedding from that
+// model, if stored.
+func (d *DB) LabeledExamples(embeddingModel string) ([]LabeledExample, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(`
+		SELECT r.full_name, r.language, r.force_category, r.force_subcategory,
+		       COALESCE(i.description, ''), COALESCE(i.topics, ''), e.vector
+		FROM repos r
+		LEFT JOIN repo_classification_inputs i ON i.full_name = r.full_name
+		LEFT JOIN repo_embeddings e ON e.full_name = r.full_name AND e.model = ?
+		WHERE r.force_category != '' AND r.excluded = 0
+		ORDER BY r.full_name`, embeddingModel)
+	if err != nil {
+		return nil, fmt.Errorf("querying labeled examples: %w", err)
+	}
+	defer rows.Close()
+
+	var out []LabeledExample
+	for rows.Next() {
+		var ex LabeledExample
+		var rec RepoRecord
+		var topics string
+		var vector []byte
+		if err := rows.Scan(&ex.FullName, &ex.Language, &rec.ForceCategory, &rec.ForceSubcategory, &ex.Description, &topics, &vector); err != nil {
+			return nil, fmt.Errorf("scanning labeled example: %w", err)
+		}
+		ex.Category, ex.Subcategory, _ = rec.ResolveTaxonomy()
+		if topics != "" {
+			ex.Topics = strings.Split(topics, ",")
+		}
+		if vector != nil {
+			ex.Embedding = decodeVector(vector)
+		}
+		out = append(out, ex)
+	}
+	return out, rows.Err()
+}
+
+// ClassificationInputs is the description and topics the classifier
+// last saw for a repo.
+type ClassificationInputs struct {
+	Description string
+	Topics      []string
+}
+
+// AllClassificationInputs returns the stored classifier inputs of every
+// repo that has them, keyed by full_name.
+func (d *DB) AllClassificationInputs() (map[string]ClassificationInputs, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(`SELECT full_name, description, topics FROM repo_classification_inputs`)
+	if err != nil {
+		return nil, fmt.Errorf("querying classification inputs: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]ClassificationInputs)
+	for rows.Next() {
+		var name, topics string
+		var in ClassificationInputs
+		if err := rows.Scan(&name, &in.Description, &topics); err != nil {
+			return nil, fmt.Errorf("scanning classification inputs: %w", err)
+		}
+		if topics != "" {
+			in.Topics = strings.Split(topics, ",")
+		}
+		out[name] = in
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabeledExamples(t *testing.T) {
+	db := mustOpen(t)
+
+	repos := []*RepoRecord{
+		{FullName: "a/forced", Owner: "a", Name: "forced", Status: "active", Language: "Go", PrimaryCategory: "other", ForceCategory: "kubernetes"},
+		{FullName: "a/reviewed", Owner: "a", Name: "reviewed", Status: "active", Language: "Python", ForceCategory: "ai", ForceSubcategory: "agents"},
+		{FullName: "a/excluded", Owner: "a", Name: "excluded", Status: "active", ForceCategory: "ai", ForceSubcategory: "rag", Excluded: 1},
+		{FullName: "a/model", Owner: "a", Name: "model", Status: "active", PrimaryCategory: "kubernetes"},
+	}
+	for _, r := range repos {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+	if err := db.SetClassificationInputs("a/reviewed", "agent toolkit", []string{"llm", "agents"}); err != nil {
+		t.Fatalf("SetClassificationInputs: %v", err)
+	}
+	// A second write replaces the first.
+	if err := db.SetClassificationInputs("a/reviewed", "agent framework", []string{"agents"}); err != nil {
+		t.Fatalf("SetClassificationInputs: %v", err)
+	}
+
+	got, err := db.LabeledExamples("")
+	if err != nil {
+		t.Fatalf("LabeledExamples: %v", err)
+	}
+	want := []LabeledExample{
+		{FullName: "a/forced", Language: "Go", Category: "cloud-native", Subcategory: "kubernetes"},
+		{FullName: "a/reviewed", Language: "Python", Description: "agent framework", Topics: []string{"agents"}, Category: "ai", Subcategory: "agents"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LabeledExamples =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if err := db.DeleteRepo("a/reviewed"); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	var n int
+	db.SQL().QueryRow("SELECT COUNT(*) FROM repo_classification_inputs").Scan(&n)
+	if n != 0 {
+		t.Errorf("inputs left after DeleteRepo: %d", n)
+	}
+}
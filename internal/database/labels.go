@@ -0,0 +1,69 @@
+package database
+
+import (
+	"fmt"
+)
+
+// CategoryLabel is one secondary classification label: a v3
+// (category, subcategory) pair with the classifier's confidence. The
+// primary label stays on the repos row (primary_category,
+// primary_subcategory, category_confidence); use it wherever a single
+// value is required.
+type CategoryLabel struct {
+	Category    string
+	Subcategory string
+	Confidence  float64
+}
+
+// SetSecondaryLabels replaces the secondary labels of fullName with
+// labels, ranked in the given order starting at 1. An empty slice clears
+// them.
+func (d *DB) SetSecondaryLabels(fullName string, labels []CategoryLabel) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("setting labels for %s: %w", fullName, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM repo_category_labels WHERE full_name = ?", fullName); err != nil {
+		return fmt.Errorf("clearing labels for %s: %w", fullName, err)
+	}
+	for i, l := range labels {
+		if _, err := tx.Exec(
+			"INSERT INTO repo_category_labels (full_name, rank, category, subcategory, confidence) VALUES (?, ?, ?, ?, ?)",
+			fullName, i+1, l.Category, l.Subcategory, l.Confidence,
+		); err != nil {
+			return fmt.Errorf("inserting label for %s: %w", fullName, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SecondaryLabels returns the secondary labels of fullName in rank
+// order; nil when it has none.
+func (d *DB) SecondaryLabels(fullName string) ([]CategoryLabel, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(
+		"SELECT category, subcategory, confidence FROM repo_category_labels WHERE full_name = ? ORDER BY rank",
+		fullName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying labels for %s: %w", fullName, err)
+	}
+	defer rows.Close()
+
+	var out []CategoryLabel
+	for rows.Next() {
+		var l CategoryLabel
+		if err := rows.Scan(&l.Category, &l.Subcategory, &l.Confidence); err != nil {
+			return nil, fmt.Errorf("scanning label: %w", err)
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
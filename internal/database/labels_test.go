@@ -0,0 +1,190 @@
+package database
+
+import "testing"
+
+func TestSecondaryLabels_RoundTrip(t *testing.T) {
+	db := mustOpen(t)
+
+	if err := db.UpsertRepo(&RepoRecord{FullName: "a/a", Owner: "a", Name: "a", Status: "active", PrimaryCategory: "ai"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+
+	labels := []CategoryLabel{
+		{Category: "cloud-native", Subcategory: "observability", Confidence: 0.8},
+		{Category: "devtools", Subcategory: "testing", Confidence: 0.6},
+	}
+	if err := db.SetSecondaryLabels("a/a", labels); err != nil {
+		t.Fatalf("SetSecondaryLabels: %v", err)
+	}
+
+	got, err := db.SecondaryLabels("a/a")
+	if err != nil {
+		t.Fatalf("SecondaryLabels: %v", err)
+	}
+	if len(got) != 2 || got[0] != labels[0] || got[1] != labels[1] {
+		t.Errorf("SecondaryLabels = %+v, want %+v", got, labels)
+	}
+
+	// Replacing with a shorter set drops the stale ranks.
+	if err := db.SetSecondaryLabels("a/a", labels[1:]); err != nil {
+		t.Fatalf("SetSecondaryLabels: %v", err)
+	}
+	got, err = db.SecondaryLabels("a/a")
+	if err != nil {
+		t.Fatalf("SecondaryLabels: %v", err)
+	}
+	if len(got) != 1 || got[0] != labels[1] {
+		t.Errorf("after replace = %+v, want %+v", got, labels[1:])
+	}
+
+	if err := db.SetSecondaryLabels("a/a", nil); err != nil {
+		t.Fatalf("SetSecondaryLabels(nil): %v", err)
+	}
+	got, err = db.SecondaryLabels("a/a")
+	if err != nil {
+		t.Fatalf("SecondaryLabels: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("after clear = %+v, want none", got)
+	}
+}
+
+func TestReposByCategory_SecondaryLabels(t *testing.T) {
+	db := mustOpen(t)
+
+	repos := []*RepoRecord{
+		{FullName: "a/agents", Owner: "a", Name: "agents", Status: "active", PrimaryCategory: "ai"},
+		{FullName: "k/otel", Owner: "k", Name: "otel", Status: "active", PrimaryCategory: "cloud-native"},
+	}
+	for _, r := range repos {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+	if _, err := db.db.Exec(`UPDATE repos SET primary_subcategory = 'agents' WHERE full_name = 'a/agents'`); err != nil {
+		t.Fatalf("set subcategory: %v", err)
+	}
+	if _, err := db.db.Exec(`UPDATE repos SET primary_subcategory = 'observability' WHERE full_name = 'k/otel'`); err != nil {
+		t.Fatalf("set subcategory: %v", err)
+	}
+	if err := db.SetSecondaryLabels("a/agents", []CategoryLabel{{Category: "cloud-native", Subcategory: "observability", Confidence: 0.7}}); err != nil {
+		t.Fatalf("SetSecondaryLabels: %v", err)
+	}
+
+	byCat, err := db.ReposByCategory("cloud-native")
+	if err != nil {
+		t.Fatalf("ReposByCategory: %v", err)
+	}
+	if len(byCat) != 2 {
+		t.Errorf("ReposByCategory(cloud-native) = %d repos, want 2 (primary + secondary)", len(byCat))
+	}
+
+	byPair, err := db.ReposByCategoryPair("cloud-native", "observability")
+	if err != nil {
+		t.Fatalf("ReposByCategoryPair: %v", err)
+	}
+	if len(byPair) != 2 || byPair[0].FullName != "a/agents" || byPair[1].FullName != "k/otel" {
+		t.Errorf("ReposByCategoryPair(cloud-native, observability) = %v, want [a/agents k/otel]", byPair)
+	}
+
+	// The secondary label never changes the primary read path.
+	ai, err := db.ReposByCategoryPair("ai", "agents")
+	if err != nil {
+		t.Fatalf("ReposByCategoryPair: %v", err)
+	}
+	if len(ai) != 1 || ai[0].FullName != "a/agents" {
+		t.Errorf("ReposByCategoryPair(ai, agents) = %v, want [a/agents]", ai)
+	}
+}
+
+// TestAuditOtherDriftCandidates_SkipsConcreteSecondary verifies that a repo
+// parked in <cat>/other is not an audit candidate once a secondary label
+// already places it in a concrete subcategory.
+func TestAuditOtherDriftCandidates_SkipsConcreteSecondary(t *testing.T) {
+	db := mustOpen(t)
+
+	repos := []*RepoRecord{
+		{FullName: "a/drift", Owner: "a", Name: "drift", Status: "active", PrimaryCategory: "ai"},
+		{FullName: "a/placed", Owner: "a", Name: "placed", Status: "active", PrimaryCategory: "ai"},
+		{FullName: "a/other-only", Owner: "a", Name: "other-only", Status: "active", PrimaryCategory: "ai"},
+	}
+	for _, r := range repos {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+	if _, err := db.db.Exec(`UPDATE repos SET primary_subcategory = 'other'`); err != nil {
+		t.Fatalf("set subcategory: %v", err)
+	}
+	if err := db.SetSecondaryLabels("a/placed", []CategoryLabel{{Category: "devtools", Subcategory: "testing", Confidence: 0.7}}); err != nil {
+		t.Fatalf("SetSecondaryLabels: %v", err)
+	}
+	if err := db.SetSecondaryLabels("a/other-only", []CategoryLabel{{Category: "devtools", Subcategory: "other", Confidence: 0.7}}); err != nil {
+		t.Fatalf("SetSecondaryLabels: %v", err)
+	}
+
+	got, err := db.AuditOtherDriftCandidates()
+	if err != nil {
+		t.Fatalf("AuditOtherDriftCandidates: %v", err)
+	}
+	var names []string
+	for _, r := range got {
+		names = append(names, r.FullName)
+	}
+	if len(names) != 2 || names[0] != "a/drift" || names[1] != "a/other-only" {
+		t.Errorf("candidates = %v, want [a/drift a/other-only]", names)
+	}
+}
+
+func TestMergeRepo_MovesSecondaryLabels(t *testing.T) {
+	db := mustOpen(t)
+
+	if err := db.UpsertRepo(&RepoRecord{FullName: "old/name", Owner: "old", Name: "name", Status: "active"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+	label := CategoryLabel{Category: "devtools", Subcategory: "testing", Confidence: 0.6}
+	if err := db.SetSecondaryLabels("old/name", []CategoryLabel{label}); err != nil {
+		t.Fatalf("SetSecondaryLabels: %v", err)
+	}
+
+	if err := db.MergeRepo("old/name", "new/name"); err != nil {
+		t.Fatalf("MergeRepo: %v", err)
+	}
+
+	moved, err := db.SecondaryLabels("new/name")
+	if err != nil {
+		t.Fatalf("SecondaryLabels: %v", err)
+	}
+	if len(moved) != 1 || moved[0] != label {
+		t.Errorf("canonical labels = %+v, want [%+v]", moved, label)
+	}
+	stale, err := db.SecondaryLabels("old/name")
+	if err != nil {
+		t.Fatalf("SecondaryLabels: %v", err)
+	}
+	if len(stale) != 0 {
+		t.Errorf("alias labels = %+v, want none", stale)
+	}
+}
+
+func TestDeleteRepo_DropsSecondaryLabels(t *testing.T) {
+	db := mustOpen(t)
+
+	if err := db.UpsertRepo(&RepoRecord{FullName: "a/a", Owner: "a", Name: "a", Status: "active"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+	if err := db.SetSecondaryLabels("a/a", []CategoryLabel{{Category: "devtools", Subcategory: "testing", Confidence: 0.6}}); err != nil {
+		t.Fatalf("SetSecondaryLabels: %v", err)
+	}
+
+	if err := db.DeleteRepo("a/a"); err != nil {
+		t.Fatalf("DeleteRepo: %v", err)
+	}
+	got, err := db.SecondaryLabels("a/a")
+	if err != nil {
+		t.Fatalf("SecondaryLabels: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("labels survived DeleteRepo: %+v", got)
+	}
+}
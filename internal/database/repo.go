@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/hrexed/github-radar/internal/repository"
 )
 
 // RepoRecord represents a repository row in the database.
@@ -16,6 +18,8 @@ import (
 type RepoRecord struct {
 	ID                    int64
 	FullName              string
+	Provider              string // non-GitHub forge; empty for GitHub, derived from FullName
+	Host                  string // GitHub Enterprise host; empty for github.com, derived from FullName
 	Owner                 string
 	Name                  string
 	Language              string
@@ -105,9 +109,19 @@ func (d *DB) GetRepo(fullName string) (*RepoRecord, error) {
 	if err != nil {
 		return nil, fmt.Errorf("querying repo %s: %w", fullName, err)
 	}
+	r.Provider, r.Host = repoOrigin(r.FullName)
 	return r, nil
 }
 
+// repoOrigin returns the forge provider encoded in a provider-qualified
+// full_name (gitlab:owner/repo) and the enterprise host encoded in a
+// host-qualified one (host/owner/repo); both are "" for github.com
+// repos. Neither is a column of its own: full_name already carries it.
+func repoOrigin(fullName string) (provider, host string) {
+	repo, _ := repository.ParseKey(fullName)
+	return repo.Provider, repo.Host
+}
+
 // UpsertRepo inserts or updates a repository record.
 func (d *DB) UpsertRepo(r *RepoRecord) error {
 	d.mu.Lock()
@@ -265,16 +279,35 @@ func (d *DB) SyncScanData(r *RepoRecord) error {
 	return nil
 }
 
-// DeleteRepo removes a repository by full_name.
+// DeleteRepo removes a repository by full_name, together with its rows
+// in repoChildTables, in one transaction.
 func (d *DB) DeleteRepo(fullName string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	_, err := d.db.Exec("DELETE FROM repos WHERE full_name = ?", fullName)
+	tx, err := d.db.Begin()
 	if err != nil {
 		return fmt.Errorf("deleting repo %s: %w", fullName, err)
 	}
-	return nil
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM repos WHERE full_name = ?", fullName); err != nil {
+		return fmt.Errorf("deleting repo %s: %w", fullName, err)
+	}
+	for _, table := range repoChildTables {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE full_name = ?", fullName); err != nil {
+			return fmt.Errorf("deleting %s of %s: %w", table, fullName, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// repoChildTables hold per-repo classification detail keyed by
+// full_name; rows follow the repo through deletes and renames.
+var repoChildTables = []string{
+	"repo_category_labels", "repo_ensemble_runs", "repo_ensemble_votes",
+	"repo_classification_reasoning", "repo_classification_inputs",
+	"repo_embeddings", "classification_reviews",
 }
 
 // repoSelectColumns is the explicit column list used by queryRepos. It pins
@@ -311,7 +344,8 @@ func (d *DB) AllReposIncludeExcluded() ([]RepoRecord, error) {
 	return d.queryRepos("SELECT " + repoSelectColumns + " FROM repos ORDER BY full_name")
 }
 
-// ReposByCategory returns repos matching a given primary_category.
+// ReposByCategory returns repos whose primary_category, or one of whose
+// secondary labels, matches category.
 //
 // Deprecated: post-v3 taxonomy migration (ISI-714), primary_category holds the
 // top-level domain (e.g. "ai") rather than the legacy flat value (e.g.
@@ -331,15 +365,19 @@ func (d *DB) ReposByCategory(category string) ([]RepoRecord, error) {
 	defer d.mu.RUnlock()
 
 	return d.queryRepos(
-		"SELECT "+repoSelectColumns+" FROM repos WHERE primary_category = ? AND excluded = 0 ORDER BY full_name",
-		category,
+		`SELECT `+repoSelectColumns+` FROM repos
+		WHERE (primary_category = ?
+		       OR full_name IN (SELECT full_name FROM repo_category_labels WHERE category = ?))
+		  AND excluded = 0
+		ORDER BY full_name`,
+		category, category,
 	)
 }
 
 // ReposByCategoryPair returns non-excluded repos matching the given
 // (primary_category, primary_subcategory) tuple under the v3 taxonomy schema
-// (ISI-714). This is the preferred read path for code that knows the new
-// 2-level taxonomy.
+// (ISI-714), or carrying it as a secondary label. This is the preferred read
+// path for code that knows the new 2-level taxonomy.
 //
 // Both arguments are matched exactly; pass the top-level domain (e.g. "ai")
 // and the subcategory token (e.g. "agents"). To enumerate repos under a
@@ -349,9 +387,12 @@ func (d *DB) ReposByCategoryPair(category, subcategory string) ([]RepoRecord, er
 	defer d.mu.RUnlock()
 
 	return d.queryRepos(
-		"SELECT "+repoSelectColumns+" FROM repos WHERE primary_category = ? AND primary_subcategory = ? AND excluded = 0 ORDER BY full_name",
-		category,
-		subcategory,
+		`SELECT `+repoSelectColumns+` FROM repos
+		WHERE ((primary_category = ? AND primary_subcategory = ?)
+		       OR full_name IN (SELECT full_name FROM repo_category_labels WHERE category = ? AND subcategory = ?))
+		  AND excluded = 0
+		ORDER BY full_name`,
+		category, subcategory, category, subcategory,
 	)
 }
 
@@ -448,6 +489,7 @@ func (d *DB) queryRepos(query string, args ...interface{}) ([]RepoRecord, error)
 		); err != nil {
 			return nil, fmt.Errorf("scanning repo row: %w", err)
 		}
+		r.Provider, r.Host = repoOrigin(r.FullName)
 		repos = append(repos, r)
 	}
 	return repos, rows.Err()
@@ -477,9 +519,12 @@ func (d *DB) ReposNeedingClassification() ([]RepoRecord, error) {
 }
 
 // UpdateClassification stores LLM classification results for a repo.
+// subcategory is the v3 subcategory when category is a v3 category, or
+// empty for a classifier category that resolves through
+// LegacyCategoryMap; either way it replaces the stored one.
 // If confidence is below minConfidence, the repo status is set to 'needs_review'
 // instead of being marked as fully classified.
-func (d *DB) UpdateClassification(fullName, category string, confidence float64, readmeHash, modelUsed string, minConfidence float64) error {
+func (d *DB) UpdateClassification(fullName, category, subcategory string, confidence float64, readmeHash, modelUsed string, minConfidence float64) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -491,13 +536,14 @@ func (d *DB) UpdateClassification(fullName, category string, confidence float64,
 	_, err := d.db.Exec(`
 		UPDATE repos SET
 			primary_category = ?,
+			primary_subcategory = ?,
 			category_confidence = ?,
 			readme_hash = ?,
 			model_used = ?,
 			classified_at = datetime('now'),
 			status = ?
 		WHERE full_name = ?`,
-		category, confidence, readmeHash, modelUsed, newStatus, fullName)
+		category, subcategory, confidence, readmeHash, modelUsed, newStatus, fullName)
 	if err != nil {
 		return fmt.Errorf("updating classification for %s: %w", fullName, err)
 	}
@@ -1063,7 +1109,9 @@ type AuditOtherCandidate struct {
 // AuditOtherDriftCandidates returns rows where the v3-taxonomy
 // classifier has parked the repo in `<cat>/other`, scoped per the
 // audit denominator rules (plan §2 + §7): non-curated, currently
-// active. Returned in deterministic order by full_name.
+// active. Repos whose secondary label places them in a concrete
+// subcategory are not drift — the taxonomy already has a home for
+// them — and are left out. Returned in deterministic order by full_name.
 //
 // Excludes are NOT explicitly filtered — the schema's `excluded` flag
 // applies elsewhere (e.g. taxonomy-rule classification overrides) and
@@ -1079,6 +1127,10 @@ func (d *DB) AuditOtherDriftCandidates() ([]AuditOtherCandidate, error) {
 		WHERE primary_subcategory = 'other'
 		  AND is_curated_list = 0
 		  AND status = 'active'
+		  AND NOT EXISTS (
+			SELECT 1 FROM repo_category_labels l
+			WHERE l.full_name = repos.full_name AND l.subcategory NOT IN ('', 'other')
+		  )
 		ORDER BY full_name`)
 	if err != nil {
 		return nil, fmt.Errorf("querying audit other-drift candidates: %w", err)
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrPairNotAllowed is returned for a review decision whose
+// (category, subcategory) pair fails IsAllowedPair.
+var ErrPairNotAllowed = errors.New("category pair not allowed")
+
+// Review actions.
+const (
+	ReviewAccept   = "accept"   // the classifier's answer was confirmed
+	ReviewOverride = "override" // the reviewer picked another pair
+)
+
+// Review is one human decision on a repo's classification.
+type Review struct {
+	FullName    string
+	Action      string // ReviewAccept or ReviewOverride
+	Category    string
+	Subcategory string
+	Reviewer    string
+	ReviewedAt  string // set by the database
+}
+
+// SetClassificationReasoning records the classifier's reasoning for the
+// current primary category of fullName.
+func (d *DB) SetClassificationReasoning(fullName, reasoning string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := d.db.Exec(`
+		INSERT INTO repo_classification_reasoning (full_name, reasoning) VALUES (?, ?)
+		ON CONFLICT(full_name) DO UPDATE SET reasoning = excluded.reasoning`,
+		fullName, reasoning,
+	)
+	if err != nil {
+		return fmt.Errorf("recording reasoning for %s: %w", fullName, err)
+	}
+	return nil
+}
+
+// ClassificationReasoning returns the recorded classifier reasoning for
+// fullName; empty when none was recorded.
+func (d *DB) ClassificationReasoning(fullName string) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var reasoning string
+	err := d.db.QueryRow("SELECT reasoning FROM repo_classification_reasoning WHERE full_name = ?", fullName).Scan(&reasoning)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("querying reasoning for %s: %w", fullName, err)
+	}
+	return reasoning, nil
+}
+
+// ReviewQueue returns non-excluded repos in needs_review without a
+// force_category override, least confident first. limit <= 0 returns
+// them all.
+func (d *DB) ReviewQueue(limit int) ([]RepoRecord, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = -1
+	}
+	return d.queryRepos(`
+		SELECT `+repoSelectColumns+` FROM repos
+		WHERE status = 'needs_review'
+		  AND excluded = 0
+		  AND force_category = ''
+		ORDER BY category_confidence, full_name
+		LIMIT ?`, limit)
+}
+
+// RecordReview applies a review decision: the pair is pinned as
+// force_category / force_subcategory, the repo leaves the review queue
+// and the decision is appended to its review history. The pair must
+// satisfy IsAllowedPair.
+func (d *DB) RecordReview(r Review) error {
+	if !IsAllowedPair(r.Category, r.Subcategory) {
+		return fmt.Errorf("(%s, %s): %w", r.Category, r.Subcategory, ErrPairNotAllowed)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("recording review for %s: %w", r.FullName, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE repos SET
+			force_category = ?,
+			force_subcategory = ?,
+			classification_override_reason = ?,
+			needs_review = 0,
+			status = 'active'
+		WHERE full_name = ?`,
+		r.Category, r.Subcategory, fmt.Sprintf("review %s by %s", r.Action, r.Reviewer), r.FullName,
+	)
+	if err != nil {
+		return fmt.Errorf("recording review for %s: %w", r.FullName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("repo %s not found", r.FullName)
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO classification_reviews (full_name, action, category, subcategory, reviewer) VALUES (?, ?, ?, ?, ?)",
+		r.FullName, r.Action, r.Category, r.Subcategory, r.Reviewer,
+	); err != nil {
+		return fmt.Errorf("logging review for %s: %w", r.FullName, err)
+	}
+	return tx.Commit()
+}
+
+// Reviews returns the review history of fullName, oldest first.
+func (d *DB) Reviews(fullName string) ([]Review, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rows, err := d.db.Query(
+		"SELECT full_name, action, category, subcategory, reviewer, reviewed_at FROM classification_reviews WHERE full_name = ? ORDER BY id",
+		fullName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying reviews for %s: %w", fullName, err)
+	}
+	defer rows.Close()
+
+	var out []Review
+	for rows.Next() {
+		var r Review
+		if err := rows.Scan(&r.FullName, &r.Action, &r.Category, &r.Subcategory, &r.Reviewer, &r.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("scanning review: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReviewQueue(t *testing.T) {
+	db := mustOpen(t)
+
+	repos := []*RepoRecord{
+		{FullName: "a/high", Owner: "a", Name: "high", Status: "needs_review", PrimaryCategory: "ai", CategoryConfidence: 0.5},
+		{FullName: "a/low", Owner: "a", Name: "low", Status: "needs_review", PrimaryCategory: "ai", CategoryConfidence: 0.2},
+		{FullName: "a/active", Owner: "a", Name: "active", Status: "active", PrimaryCategory: "ai", CategoryConfidence: 0.1},
+		{FullName: "a/excluded", Owner: "a", Name: "excluded", Status: "needs_review", Excluded: 1},
+		{FullName: "a/pinned", Owner: "a", Name: "pinned", Status: "needs_review", ForceCategory: "ai", ForceSubcategory: "agents"},
+	}
+	for _, r := range repos {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+
+	got, err := db.ReviewQueue(0)
+	if err != nil {
+		t.Fatalf("ReviewQueue: %v", err)
+	}
+	if len(got) != 2 || got[0].FullName != "a/low" || got[1].FullName != "a/high" {
+		t.Errorf("ReviewQueue(0) = %v, want [a/low a/high]", got)
+	}
+	if got, _ := db.ReviewQueue(1); len(got) != 1 || got[0].FullName != "a/low" {
+		t.Errorf("ReviewQueue(1) = %v, want [a/low]", got)
+	}
+}
+
+func TestRecordReview(t *testing.T) {
+	db := mustOpen(t)
+
+	if err := db.UpsertRepo(&RepoRecord{FullName: "a/a", Owner: "a", Name: "a", Status: "needs_review", PrimaryCategory: "ai"}); err != nil {
+		t.Fatalf("UpsertRepo: %v", err)
+	}
+
+	err := db.RecordReview(Review{FullName: "a/a", Action: ReviewOverride, Category: "ai", Subcategory: "nope", Reviewer: "alex"})
+	if !errors.Is(err, ErrPairNotAllowed) {
+		t.Errorf("disallowed pair: err = %v, want ErrPairNotAllowed", err)
+	}
+	if err := db.RecordReview(Review{FullName: "x/missing", Action: ReviewAccept, Category: "ai", Subcategory: "agents", Reviewer: "alex"}); err == nil {
+		t.Error("missing repo: want an error")
+	}
+
+	if err := db.RecordReview(Review{FullName: "a/a", Action: ReviewOverride, Category: "devtools", Subcategory: "testing", Reviewer: "alex"}); err != nil {
+		t.Fatalf("RecordReview: %v", err)
+	}
+
+	r, err := db.GetRepo("a/a")
+	if err != nil {
+		t.Fatalf("GetRepo: %v", err)
+	}
+	if r.ForceCategory != "devtools" || r.ForceSubcategory != "testing" || r.Status != "active" {
+		t.Errorf("repo = force %s/%s status %s, want devtools/testing active", r.ForceCategory, r.ForceSubcategory, r.Status)
+	}
+	if cat, sub, _ := r.ResolveTaxonomy(); cat != "devtools" || sub != "testing" {
+		t.Errorf("ResolveTaxonomy = %s/%s, want the reviewed pair", cat, sub)
+	}
+
+	history, err := db.Reviews("a/a")
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	if len(history) != 1 || history[0].Reviewer != "alex" || history[0].Action != ReviewOverride || history[0].ReviewedAt == "" {
+		t.Errorf("Reviews = %+v, want one override by alex with a timestamp", history)
+	}
+}
+
+func TestClassificationReasoning(t *testing.T) {
+	db := mustOpen(t)
+
+	if got, err := db.ClassificationReasoning("a/a"); err != nil || got != "" {
+		t.Fatalf("ClassificationReasoning before any = %q, %v", got, err)
+	}
+	for _, want := range []string{"first", "second"} {
+		if err := db.SetClassificationReasoning("a/a", want); err != nil {
+			t.Fatalf("SetClassificationReasoning: %v", err)
+		}
+		if got, _ := db.ClassificationReasoning("a/a"); got != want {
+			t.Errorf("ClassificationReasoning = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package discovery
+
+// canonical.go collapses non-canonical copies of a repository before
+// they reach the candidate set. Discovery otherwise surfaces forks and
+// mirrors of popular projects, and a renamed repo shows up twice — once
+// under the name still cited in the firehose and once under its new
+// name.
+//
+// Identity comes from the repo API: RepoMetrics carries fork / parent /
+// source / mirror, and a renamed repo answers its old name with a 301
+// that the HTTP client follows, so the payload's full_name differs from
+// the requested one. Every resolution is written to an AliasStore
+// (`repo_aliases` in the database) so later cycles can dedup or reject
+// a known alias before paying for REST hydration.
+
+// Alias reasons. Values match the database.AliasReason* constants; the
+// discovery package keeps its own copy so it stays free of SQL deps.
+const (
+	aliasReasonRenamed = "renamed"
+	aliasReasonFork    = "fork"
+	aliasReasonMirror  = "mirror"
+)
+
+// AliasStore is the minimal interface satisfied by `*database.DB` for
+// persisting repo alias mappings. ResolveRepoAlias returns empty
+// strings (and no error) for names without an alias.
+type AliasStore interface {
+	ResolveRepoAlias(name string) (canonical, reason string, err error)
+	SetRepoAlias(alias, canonical, reason string) error
+}
+
+// SetAliasStore wires persistent alias storage. Without one the
+// Discoverer still canonicalizes hydrated repos but cannot skip known
+// aliases before hydration on later cycles.
+func (d *Discoverer) SetAliasStore(s AliasStore) {
+	d.aliases = s
+}
+
+// lookupAlias resolves fullName against the alias store. Lookup
+// failures degrade to "no alias" — canonicalization is an optimization
+// on top of hydration, which re-derives the same answer.
+func (d *Discoverer) lookupAlias(fullName string) (canonical, reason string) {
+	if d.aliases == nil {
+		return "", ""
+	}
+	canonical, reason, err := d.aliases.ResolveRepoAlias(fullName)
+	if err != nil {
+		d.log("debug", "alias lookup failed", "repo", fullName, "error", err)
+		return "", ""
+	}
+	return canonical, reason
+}
+
+// recordAlias persists alias → canonical. Errors are logged, not
+// returned, for the same reason as lookupAlias.
+func (d *Discoverer) recordAlias(alias, canonical, reason string) {
+	if d.aliases == nil || canonical == "" {
+		return
+	}
+	if err := d.aliases.SetRepoAlias(alias, canonical, reason); err != nil {
+		d.log("warn", "failed to record repo alias",
+			"alias", alias, "canonical", canonical, "reason", reason, "error", err)
+	}
+}
+
+// allowsAliasReason reports whether repos recorded under reason may
+// still become candidates. Renames always pass — they resolve to the
+// canonical repo, which is filtered on its own merits.
+func (d *Discoverer) allowsAliasReason(reason string) bool {
+	switch reason {
+	case aliasReasonFork:
+		return d.config.IncludeForks
+	case aliasReasonMirror:
+		return d.config.IncludeMirrors
+	}
+	return true
+}
+
+// passesIdentityFilters rejects forks and mirrors unless the config
+// opts in. Shared by passesFilters and the gharchive pipeline, which
+// bypasses passesFilters' star floor.
+func (d *Discoverer) passesIdentityFilters(repo DiscoveredRepo) bool {
+	if repo.Fork && !d.config.IncludeForks {
+		return false
+	}
+	if repo.Mirror && !d.config.IncludeMirrors {
+		return false
+	}
+	return true
+}
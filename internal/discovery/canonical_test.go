@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// memoryAliasStore is an in-memory AliasStore for tests.
+type memoryAliasStore struct {
+	rows map[string][2]string // alias → {canonical, reason}
+}
+
+func newMemoryAliasStore() *memoryAliasStore {
+	return &memoryAliasStore{rows: make(map[string][2]string)}
+}
+
+func (m *memoryAliasStore) ResolveRepoAlias(name string) (string, string, error) {
+	row := m.rows[strings.ToLower(name)]
+	return row[0], row[1], nil
+}
+
+func (m *memoryAliasStore) SetRepoAlias(alias, canonical, reason string) error {
+	m.rows[strings.ToLower(alias)] = [2]string{canonical, reason}
+	return nil
+}
+
+// watchEvents returns n WatchEvents on each repo.
+func watchEvents(n int, repos ...string) []map[string]any {
+	var out []map[string]any
+	for _, repo := range repos {
+		for i := 0; i < n; i++ {
+			out = append(out, map[string]any{"type": "WatchEvent", "repo": map[string]any{"name": repo}})
+		}
+	}
+	return out
+}
+
+func TestPassesFilters_ForksAndMirrors(t *testing.T) {
+	base := DiscoveredRepo{Stars: 500}
+	fork := base
+	fork.Fork = true
+	mirror := base
+	mirror.Mirror = true
+
+	d := NewDiscoverer(nil, state.NewStore(""), Config{MinStars: 100})
+	if !d.passesFilters(base) {
+		t.Error("plain repo rejected")
+	}
+	if d.passesFilters(fork) || d.passesFilters(mirror) {
+		t.Error("fork or mirror admitted with default config")
+	}
+
+	d = NewDiscoverer(nil, state.NewStore(""), Config{MinStars: 100, IncludeForks: true, IncludeMirrors: true})
+	if !d.passesFilters(fork) || !d.passesFilters(mirror) {
+		t.Error("fork or mirror rejected with include_forks / include_mirrors set")
+	}
+}
+
+// TestDiscoverFromGHArchive_CanonicalizesCandidates — a renamed repo is
+// deduped against the tracked new name, a fork is rejected and its
+// alias recorded, and the next cycle skips the fork without hydrating.
+func TestDiscoverFromGHArchive_CanonicalizesCandidates(t *testing.T) {
+	src := releaseTestSource(t, watchEvents(20, "a/old", "me/fork", "a/fresh"))
+
+	renamed := repoEntry("a/new", 500)
+	fork := repoEntry("me/fork", 300)
+	fork.Fork = true
+	fork.Source = map[string]string{"full_name": "up/stream"}
+	rest := fakeRESTServer(t, map[string]repoMetricsResponse{
+		"a/old":   renamed, // served after GitHub's rename redirect
+		"me/fork": fork,
+		"a/fresh": repoEntry("a/fresh", 200),
+	})
+	t.Cleanup(rest.Close)
+
+	d := newPipelineDiscoverer(t, rest.URL, Config{
+		Sources: SourcesConfig{GHArchive: GHArchiveSourceConfig{Enabled: true, TopN: 10, ActivityFloor: 5}},
+	})
+	d.SetGHArchiveSource(src)
+	aliases := newMemoryAliasStore()
+	d.SetAliasStore(aliases)
+	d.store.SetRepoState("a/new", state.RepoState{Owner: "a", Name: "new", LastCollected: time.Now()})
+
+	result, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverFromGHArchive: %v", err)
+	}
+	if result.NewRepos != 1 || result.Repos[0].FullName != "a/fresh" {
+		t.Errorf("emitted %+v, want only a/fresh", result.Repos)
+	}
+	if result.Renamed != 1 || result.AlreadyTracked != 1 || result.ForksOrMirrors != 1 {
+		t.Errorf("Renamed = %d, AlreadyTracked = %d, ForksOrMirrors = %d, want 1/1/1",
+			result.Renamed, result.AlreadyTracked, result.ForksOrMirrors)
+	}
+	if c, r, _ := aliases.ResolveRepoAlias("a/old"); c != "a/new" || r != aliasReasonRenamed {
+		t.Errorf("a/old alias = (%q, %q), want (a/new, renamed)", c, r)
+	}
+	if c, r, _ := aliases.ResolveRepoAlias("me/fork"); c != "up/stream" || r != aliasReasonFork {
+		t.Errorf("me/fork alias = (%q, %q), want (up/stream, fork)", c, r)
+	}
+
+	// Second cycle: both aliases resolve before hydration, so the
+	// REST endpoint is never needed for them.
+	d.client.SetBaseURL("http://127.0.0.1:0")
+	again, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("second DiscoverFromGHArchive: %v", err)
+	}
+	if again.ForksOrMirrors != 1 || again.AlreadyTracked != 1 || again.Renamed != 1 {
+		t.Errorf("second cycle ForksOrMirrors = %d, AlreadyTracked = %d, Renamed = %d, want 1/1/1",
+			again.ForksOrMirrors, again.AlreadyTracked, again.Renamed)
+	}
+}
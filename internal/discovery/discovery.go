@@ -29,6 +29,13 @@ type Config struct {
 	// Exclusions is a list of repo patterns to exclude
 	Exclusions []string
 
+	// IncludeForks and IncludeMirrors admit forks and mirrors as
+	// candidates. Both default to false: discovery otherwise surfaces
+	// copies of popular projects rather than the projects themselves.
+	// See canonical.go.
+	IncludeForks   bool
+	IncludeMirrors bool
+
 	// Sources controls which discovery sources beyond the default topic
 	// search are active. Each source is feature-flagged and disabled by
 	// default so rollout can be staged via config alone.
@@ -75,10 +82,15 @@ type GHArchiveSourceConfig struct {
 	// TopN caps the number of candidates promoted per discovery
 	// cycle. Zero falls back to DefaultGHArchiveTopN.
 	TopN int `yaml:"top_n"`
-	// ActivityFloor is the minimum total event count over the
-	// collector's sliding window for a repo to be considered. Zero
-	// falls back to DefaultGHArchiveActivityFloor.
+	// ActivityFloor is the minimum RankBy value over the collector's
+	// sliding window for a repo to be considered (total events by
+	// default). Zero falls back to DefaultGHArchiveActivityFloor.
 	ActivityFloor int `yaml:"activity_floor"`
+	// RankBy selects what top-N ranking and ActivityFloor measure:
+	// raw event count ("events", the default), or approximate
+	// distinct stargazers / contributors, which a single busy bot or
+	// script cannot inflate. See gharchive_actors.go.
+	RankBy GHArchiveRankMetric `yaml:"rank_by"`
 	// MinStarsGate is an optional star floor applied to gharchive-
 	// discovered candidates. Zero (the default) disables the gate so
 	// event volume is the sole filter.
@@ -102,6 +114,18 @@ type GHArchiveSourceConfig struct {
 	// DefaultGHArchiveMinStarsCacheTTL (7 days, matches the
 	// collector window's order of magnitude).
 	MinStarsCacheTTL time.Duration `yaml:"min_stars_cache_ttl"`
+	// ReleaseSignals turns on release-driven discovery: initial
+	// versions, 1.0 graduations and major version bumps seen in the
+	// firehose promote untracked repos as candidates regardless of
+	// ActivityFloor / TopN, and fire OnTrackedRelease for repos that
+	// are already tracked. See gharchive_release.go.
+	ReleaseSignals bool `yaml:"release_signals"`
+	// MaxReleaseCandidates caps how many release-only candidates
+	// (release signals on repos outside the top-N list) are added per
+	// cycle, oldest signal first. Signals over the cap stay pending for
+	// the next cycle. Zero falls back to
+	// DefaultGHArchiveMaxReleaseCandidates.
+	MaxReleaseCandidates int `yaml:"max_release_candidates"`
 
 	// Backpressure tunes the Story 4 ([ISI-954]) gates that protect
 	// classifier capacity from a runaway gharchive firehose. A zero
@@ -135,6 +159,9 @@ const (
 	// repeat hydration of obviously-small repos" against "don't
 	// permanently shadow a repo that has since gone viral".
 	DefaultGHArchiveMinStarsCacheTTL = 7 * 24 * time.Hour
+	// DefaultGHArchiveMaxReleaseCandidates is the per-cycle cap on
+	// release-only candidates appended after the top-N list.
+	DefaultGHArchiveMaxReleaseCandidates = 50
 )
 
 // OrgsSourceConfig configures Source (3): per-org repository search.
@@ -194,6 +221,18 @@ type DiscoveredRepo struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 
+	// ReleaseTag and ReleaseKind record the provenance of
+	// release-driven gharchive candidates: the tag that promoted the
+	// repo and how it was classified. Empty for every other source.
+	ReleaseTag  string
+	ReleaseKind ReleaseKind
+
+	// Fork and Mirror flag non-canonical copies; Upstream is the fork
+	// network root (or parent) a fork canonicalizes to, when known.
+	Fork     bool
+	Mirror   bool
+	Upstream string
+
 	// Calculated scores
 	GrowthScore     float64
 	NormalizedScore float64
@@ -219,7 +258,18 @@ type Result struct {
 	// emitted by the gharchive pipeline (ISI-982); search-API
 	// sources leave it at zero.
 	MinStarsPrefiltered int
-	Repos               []DiscoveredRepo
+	// ReleaseCandidates counts candidates admitted by a gharchive
+	// release signal alone, bypassing the activity floor. Only
+	// emitted by the gharchive pipeline.
+	ReleaseCandidates int
+	// ForksOrMirrors counts gharchive candidates rejected as forks or
+	// mirrors, before or after hydration. Search-API sources drop them
+	// in passesFilters without counting.
+	ForksOrMirrors int
+	// Renamed counts gharchive candidates that resolved to a new name
+	// via a recorded alias or a redirect during hydration.
+	Renamed int
+	Repos   []DiscoveredRepo
 }
 
 // DefaultSearchAPIThrottle is the minimum delay between successive
@@ -263,6 +313,11 @@ type Discoverer struct {
 	// don't exercise backpressure) — in that case DiscoverFromGHArchive
 	// runs without gating, preserving Story 2 behaviour.
 	ghArchiveBackpressure *GHArchiveBackpressureGate
+
+	// aliases persists rename / fork / mirror resolutions so later
+	// cycles can canonicalize without hydrating. Nil when the daemon
+	// has no database; see canonical.go.
+	aliases AliasStore
 }
 
 // NewDiscoverer creates a new discoverer.
@@ -295,6 +350,9 @@ func (d *Discoverer) SetSearchThrottle(t time.Duration) {
 // flag.
 func (d *Discoverer) SetGHArchiveSource(src *GHArchiveSource) {
 	d.ghArchive = src
+	if src != nil && d.ghArchiveBackpressure != nil {
+		src.SetBackpressureGate(d.ghArchiveBackpressure)
+	}
 }
 
 // SetGHArchivePipelineHooks wires the pipeline-level telemetry callbacks
@@ -311,10 +369,16 @@ func (d *Discoverer) SetGHArchivePipelineHooks(hooks GHArchivePipelineHooks) {
 // the gate with live signals — pending-classification queue depth and
 // GitHub core REST consumption percent — then hands it here.
 //
+// The gate also throttles the wired gharchive source to one archive at
+// a time while paused (see GHArchiveSource.SetBackpressureGate).
+//
 // Set this before calling DiscoverAll; mutating it concurrently with
 // discovery is not safe.
 func (d *Discoverer) SetGHArchiveBackpressure(g *GHArchiveBackpressureGate) {
 	d.ghArchiveBackpressure = g
+	if d.ghArchive != nil {
+		d.ghArchive.SetBackpressureGate(g)
+	}
 }
 
 // SetLogger sets a logging callback.
@@ -468,6 +532,18 @@ func (d *Discoverer) DiscoverAll(ctx context.Context) ([]*Result, error) {
 	return results, nil
 }
 
+// PlannedSteps returns how many steps the next DiscoverAll will run and
+// how many of them call the Search API, for budget planning.
+func (d *Discoverer) PlannedSteps() (steps, searches int) {
+	for _, step := range d.buildSearchPlan() {
+		steps++
+		if step.consumesSearchAPI {
+			searches++
+		}
+	}
+	return steps, searches
+}
+
 // searchStep is one discovery step within a DiscoverAll cycle.
 //
 // Most steps issue Search API calls and share the 30 req/min quota,
@@ -779,6 +855,8 @@ func (d *Discoverer) processRepo(repo github.SearchResult) DiscoveredRepo {
 		Forks:       repo.Forks,
 		CreatedAt:   repo.CreatedAt,
 		UpdatedAt:   repo.UpdatedAt,
+		Fork:        repo.Fork,
+		Mirror:      repo.Mirror,
 	}
 
 	// Calculate a simple growth score based on available data
@@ -848,6 +926,11 @@ func (d *Discoverer) normalizeScores(result *Result) {
 
 // passesFilters checks if a repo passes all configured filters.
 func (d *Discoverer) passesFilters(repo DiscoveredRepo) bool {
+	// Forks and mirrors are rejected unless explicitly admitted
+	if !d.passesIdentityFilters(repo) {
+		return false
+	}
+
 	// Min stars filter
 	if repo.Stars < d.config.MinStars {
 		return false
@@ -0,0 +1,193 @@
+package discovery
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// gharchive_actors.go adds distinct-actor counting to the gharchive
+// activity window. Each (repo, hour) cell keeps two hllSketch sets next
+// to its event counts — who starred, who contributed — and
+// TopActiveReposBy can rank and floor on their window-wide union
+// instead of raw volume. Events from bots (GHArchiveConfig.BotPatterns)
+// are dropped before they reach either the counts or the sketches.
+
+// GHArchiveRankMetric selects what TopActiveReposBy ranks and floors on.
+type GHArchiveRankMetric string
+
+const (
+	// GHArchiveRankEvents ranks by raw event count (the default).
+	GHArchiveRankEvents GHArchiveRankMetric = "events"
+	// GHArchiveRankStargazers ranks by distinct WatchEvent actors.
+	GHArchiveRankStargazers GHArchiveRankMetric = "stargazers"
+	// GHArchiveRankContributors ranks by distinct actors of the
+	// contribution event types (gharchiveContributorEventTypes).
+	GHArchiveRankContributors GHArchiveRankMetric = "contributors"
+)
+
+// Valid reports whether m is a known metric. The empty string is
+// valid and means GHArchiveRankEvents.
+func (m GHArchiveRankMetric) Valid() bool {
+	switch m {
+	case "", GHArchiveRankEvents, GHArchiveRankStargazers, GHArchiveRankContributors:
+		return true
+	}
+	return false
+}
+
+// DefaultGHArchiveBotPatterns is the bot filter applied when
+// GHArchiveConfig.BotPatterns is empty. Matching is a case-insensitive
+// substring test on the actor login: "[bot]" covers GitHub App
+// accounts (dependabot[bot], github-actions[bot]), the others cover
+// bots running under plain user accounts.
+var DefaultGHArchiveBotPatterns = []string{
+	"[bot]",
+	"dependabot",
+	"renovate",
+}
+
+// gharchiveStarEventType is the event type whose actors count as
+// stargazers.
+const gharchiveStarEventType = "WatchEvent"
+
+// gharchiveContributorEventTypes are the event types whose actors count
+// as contributors. Only types kept by the type filter are observed, so
+// ranking by contributors needs at least one of these in EventTypes.
+var gharchiveContributorEventTypes = map[string]bool{
+	"PushEvent":                     true,
+	"PullRequestEvent":              true,
+	"PullRequestReviewEvent":        true,
+	"PullRequestReviewCommentEvent": true,
+}
+
+// isBotActor reports whether login matches one of the lower-cased
+// patterns.
+func isBotActor(login string, patterns []string) bool {
+	if login == "" || len(patterns) == 0 {
+		return false
+	}
+	lower := strings.ToLower(login)
+	for _, p := range patterns {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// gharchiveHourActors is the distinct-actor state of one (repo, hour)
+// cell. Either sketch may be nil when the hour saw no such actor.
+type gharchiveHourActors struct {
+	stargazers   *hllSketch
+	contributors *hllSketch
+}
+
+// observe records actor hash for an event of the given type.
+func (a *gharchiveHourActors) observe(eventType string, hash uint64) {
+	switch {
+	case eventType == gharchiveStarEventType:
+		if a.stargazers == nil {
+			a.stargazers = &hllSketch{}
+		}
+		a.stargazers.add(hash)
+	case gharchiveContributorEventTypes[eventType]:
+		if a.contributors == nil {
+			a.contributors = &hllSketch{}
+		}
+		a.contributors.add(hash)
+	}
+}
+
+// setActors stores the actor sketches for hourBucket. Must follow the
+// matching set() call so the slot exists; hours outside the window are
+// dropped the same way set() drops them.
+func (b *ringBucket) setActors(hourBucket time.Time, actors gharchiveHourActors) {
+	idx, ok := b.slotIndex(hourBucket)
+	if !ok {
+		return
+	}
+	b.actors[idx] = actors
+}
+
+// uniqueActors unions the hourly sketches across the window and returns
+// the approximate distinct stargazer and contributor counts.
+func (b *ringBucket) uniqueActors() (stargazers, contributors int) {
+	var stars, contribs hllSketch
+	for _, a := range b.actors {
+		stars.merge(a.stargazers)
+		contribs.merge(a.contributors)
+	}
+	return stars.estimate(), contribs.estimate()
+}
+
+// Value returns the activity's figure for metric.
+func (a GHArchiveRepoActivity) Value(metric GHArchiveRankMetric) int {
+	switch metric {
+	case GHArchiveRankStargazers:
+		return a.UniqueStargazers
+	case GHArchiveRankContributors:
+		return a.UniqueContributors
+	}
+	return a.TotalEvents
+}
+
+// TopActiveReposBy returns at most n repos sorted by metric over the
+// sliding window, descending, keeping only repos whose metric is at
+// least floor. TopActiveRepos is the events-ranked shorthand.
+//
+// A repo's distinct-actor count never exceeds its event count, so repos
+// whose event total is already below floor are skipped before any
+// sketch merge.
+func (s *GHArchiveSource) TopActiveReposBy(metric GHArchiveRankMetric, n int, floor int) []GHArchiveRepoActivity {
+	if metric == "" {
+		metric = GHArchiveRankEvents
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]GHArchiveRepoActivity, 0, len(s.buckets))
+	for repo, bucket := range s.buckets {
+		total := bucket.total()
+		if total < floor {
+			continue
+		}
+		act := GHArchiveRepoActivity{RepoName: repo, TotalEvents: total}
+		if metric != GHArchiveRankEvents {
+			act.UniqueStargazers, act.UniqueContributors = bucket.uniqueActors()
+			if act.Value(metric) < floor {
+				continue
+			}
+		}
+		out = append(out, act)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		vi, vj := out[i].Value(metric), out[j].Value(metric)
+		if vi != vj {
+			return vi > vj
+		}
+		if out[i].TotalEvents != out[j].TotalEvents {
+			return out[i].TotalEvents > out[j].TotalEvents
+		}
+		return out[i].RepoName < out[j].RepoName
+	})
+
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+
+	// Fill in the per-hour detail (and, for events ranking, the actor
+	// counts) only for the repos that made the cut.
+	for i := range out {
+		bucket := s.buckets[out[i].RepoName]
+		out[i].HourCounts = bucket.hourCountsOldestFirst()
+		out[i].PerEventType = bucket.perTypeAggregate()
+		out[i].PeakHourEvents = bucket.peak()
+		if metric == GHArchiveRankEvents {
+			out[i].UniqueStargazers, out[i].UniqueContributors = bucket.uniqueActors()
+		}
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func actorEvent(eventType, actor, repo string) map[string]any {
+	return map[string]any{
+		"type":  eventType,
+		"actor": map[string]any{"login": actor},
+		"repo":  map[string]any{"name": repo},
+	}
+}
+
+// actorTestSource processes one archive of events at 2026-05-10 12:00
+// UTC and returns the loaded source.
+func actorTestSource(t *testing.T, events []map[string]any) *GHArchiveSource {
+	t.Helper()
+	hour := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	archive := hour.Format(gharchiveArchiveLayout)
+	srv := fakeArchiveServer(t, map[string][]byte{archive: gzipNDJSON(t, events)})
+	t.Cleanup(srv.Close)
+
+	src := newTestSource(t, srv.URL, hour.Add(2*time.Hour), NewMemoryCursorStore(), nil, GHArchiveHooks{})
+	if err := src.ProcessArchive(context.Background(), archive); err != nil {
+		t.Fatalf("ProcessArchive: %v", err)
+	}
+	return src
+}
+
+// A script pushing hundreds of times from one account out-ranks a repo
+// with many distinct stargazers on raw events, but not on distinct
+// actors.
+func TestTopActiveReposBy_DistinctActors(t *testing.T) {
+	var events []map[string]any
+	for i := 0; i < 200; i++ {
+		events = append(events, actorEvent("PushEvent", "busy-script", "noisy/repo"))
+	}
+	for i := 0; i < 40; i++ {
+		events = append(events, actorEvent("WatchEvent", fmt.Sprintf("fan-%d", i), "loved/repo"))
+	}
+	for i := 0; i < 3; i++ {
+		events = append(events, actorEvent("PushEvent", fmt.Sprintf("dev-%d", i), "loved/repo"))
+	}
+	src := actorTestSource(t, events)
+
+	byEvents := src.TopActiveReposBy(GHArchiveRankEvents, 0, 1)
+	if len(byEvents) != 2 || byEvents[0].RepoName != "noisy/repo" {
+		t.Fatalf("by events = %+v, want noisy/repo first", byEvents)
+	}
+	if byEvents[0].UniqueContributors != 1 || byEvents[1].UniqueStargazers != 40 {
+		t.Errorf("unique counts not filled in events mode: %+v", byEvents)
+	}
+
+	byStars := src.TopActiveReposBy(GHArchiveRankStargazers, 0, 10)
+	if len(byStars) != 1 || byStars[0].RepoName != "loved/repo" || byStars[0].UniqueStargazers != 40 {
+		t.Errorf("by stargazers (floor 10) = %+v, want only loved/repo=40", byStars)
+	}
+
+	byContribs := src.TopActiveReposBy(GHArchiveRankContributors, 0, 1)
+	if len(byContribs) != 2 || byContribs[0].RepoName != "loved/repo" || byContribs[0].UniqueContributors != 3 {
+		t.Errorf("by contributors = %+v, want loved/repo=3 first", byContribs)
+	}
+
+	act, ok := src.RepoActivity("loved/repo")
+	if !ok || act.UniqueStargazers != 40 || act.UniqueContributors != 3 {
+		t.Errorf("RepoActivity = %+v, %v", act, ok)
+	}
+}
+
+func TestProcessArchive_ExcludesBotActors(t *testing.T) {
+	var discarded int64
+	hour := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	archive := hour.Format(gharchiveArchiveLayout)
+	srv := fakeArchiveServer(t, map[string][]byte{archive: gzipNDJSON(t, []map[string]any{
+		actorEvent("PushEvent", "dependabot[bot]", "acme/app"),
+		actorEvent("PullRequestEvent", "Renovate-Runner", "acme/app"),
+		actorEvent("PushEvent", "github-actions[bot]", "acme/app"),
+		actorEvent("WatchEvent", "alice", "acme/app"),
+	})})
+	t.Cleanup(srv.Close)
+
+	src := newTestSource(t, srv.URL, hour.Add(2*time.Hour), NewMemoryCursorStore(), nil, GHArchiveHooks{
+		OnEventsProcessed: func(_ string, _ map[string]int64, d int64) { discarded = d },
+	})
+	if err := src.ProcessArchive(context.Background(), archive); err != nil {
+		t.Fatalf("ProcessArchive: %v", err)
+	}
+
+	act, ok := src.RepoActivity("acme/app")
+	if !ok {
+		t.Fatal("acme/app missing")
+	}
+	if act.TotalEvents != 1 || act.UniqueStargazers != 1 || act.UniqueContributors != 0 {
+		t.Errorf("activity = %+v, want only alice's WatchEvent", act)
+	}
+	if discarded != 3 {
+		t.Errorf("discarded = %d, want 3 bot events", discarded)
+	}
+}
+
+func TestIsBotActor(t *testing.T) {
+	patterns := []string{"[bot]", "dependabot"}
+	cases := map[string]bool{
+		"dependabot[bot]":    true,
+		"Dependabot-Preview": true,
+		"some-app[bot]":      true,
+		"alice":              false,
+		"":                   false,
+	}
+	for login, want := range cases {
+		if got := isBotActor(login, patterns); got != want {
+			t.Errorf("isBotActor(%q) = %v, want %v", login, got, want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/gharchive"
+	"github.com/hrexed/github-radar/internal/logging"
+)
+
+// gharchive_backfill.go replays a historical range of gharchive hours
+// through ProcessArchive. A fresh database, or a newly added topic, has
+// no activity history; backfill fills it from the archive so growth
+// signals and the discovery window start warm instead of empty.
+//
+// Backfill runs against its own GHArchiveSource (built with a cursor
+// store keyed on GHArchiveBackfillCursorMetadataKey) so it never moves
+// the live collector's cursor. Persisting what was seen is the rollup
+// store's job, exactly as for the live collector.
+
+// GHArchiveBackfillCursorMetadataKey is the metadata key holding the
+// backfill cursor. Distinct from GHArchiveCursorMetadataKey so a
+// backfill and the daemon's live collector can share one database.
+const GHArchiveBackfillCursorMetadataKey = "gharchive_backfill_cursor"
+
+// DefaultGHArchiveBackfillInterval is the minimum spacing between
+// archive downloads during backfill — one archive per second keeps a
+// months-long backfill polite towards data.gharchive.org.
+const DefaultGHArchiveBackfillInterval = time.Second
+
+// NewMetadataCursorStoreWithKey is NewMetadataCursorStore with a custom
+// metadata key, for collectors that must not share the live cursor.
+func NewMetadataCursorStoreWithKey(store MetadataKVStore, key string) *MetadataCursorStore {
+	return &MetadataCursorStore{store: store, key: key}
+}
+
+// GHArchiveArchiveName returns the archive (and cursor) name of the
+// hour containing t.
+func GHArchiveArchiveName(t time.Time) string {
+	return t.UTC().Truncate(time.Hour).Format(gharchiveArchiveLayout)
+}
+
+// GHArchiveBackfillOptions bounds one backfill run.
+type GHArchiveBackfillOptions struct {
+	// From and To are the first and last hour processed, inclusive.
+	// Both are truncated to the hour (UTC).
+	From time.Time
+	To   time.Time
+
+	// Interval is the minimum time between two archive downloads.
+	// Archives served from GHArchiveConfig.LocalDir or the archive
+	// cache are not paced. Zero means DefaultGHArchiveBackfillInterval; negative disables
+	// pacing.
+	Interval time.Duration
+
+	// Restart ignores a saved cursor and starts again at From.
+	Restart bool
+
+	// OnArchive, when set, is called after every archive attempt with
+	// the archive name and its error (nil on success).
+	OnArchive func(archive string, err error)
+}
+
+// GHArchiveBackfillResult summarises a backfill run.
+type GHArchiveBackfillResult struct {
+	// Start is the first hour processed this run; after Start the
+	// saved cursor was honoured. Zero when nothing was left to do.
+	Start time.Time
+	// Processed counts archives aggregated successfully.
+	Processed int
+	// Failed lists archives that failed after the retry budget, e.g.
+	// the hours gharchive itself is missing. Backfill moves past them.
+	Failed []string
+}
+
+// Backfill processes every archive in [opts.From, opts.To], committing
+// them in order.
+// It resumes after the saved cursor when the cursor falls inside the
+// range, so an interrupted run picks up where it stopped. A failed
+// archive is recorded in the result and skipped; ctx cancellation
+// stops the run and returns ctx.Err() together with the partial
+// result.
+func (s *GHArchiveSource) Backfill(ctx context.Context, opts GHArchiveBackfillOptions) (GHArchiveBackfillResult, error) {
+	var result GHArchiveBackfillResult
+
+	from := opts.From.UTC().Truncate(time.Hour)
+	to := opts.To.UTC().Truncate(time.Hour)
+	if to.Before(from) {
+		return result, fmt.Errorf("backfill range: to (%s) is before from (%s)",
+			to.Format(gharchiveArchiveLayout), from.Format(gharchiveArchiveLayout))
+	}
+	interval := opts.Interval
+	if interval == 0 {
+		interval = DefaultGHArchiveBackfillInterval
+	}
+
+	start := from
+	if !opts.Restart {
+		cursor, err := s.engine.Cursor().GetCursor(ctx)
+		if err != nil {
+			return result, fmt.Errorf("loading backfill cursor: %w", err)
+		}
+		if h := cursor.Hour(); !h.IsZero() && !h.Before(from) && !h.After(to) {
+			start = h.Add(time.Hour)
+		}
+	}
+	if start.After(to) {
+		return result, nil
+	}
+	result.Start = start
+
+	logging.Info("gharchive backfill starting",
+		"from", start.Format(gharchiveArchiveLayout),
+		"to", to.Format(gharchiveArchiveLayout),
+		"archives", int(to.Sub(start)/time.Hour)+1)
+
+	archives := make([]string, 0, int(to.Sub(start)/time.Hour)+1)
+	for h := start; !h.After(to); h = h.Add(time.Hour) {
+		archives = append(archives, h.Format(gharchiveArchiveLayout))
+	}
+
+	// Archives download and decode concurrently (GHArchiveConfig.
+	// Concurrency); the interval paces download starts, so the request
+	// rate towards the origin is unchanged.
+	var lastFetch time.Time
+	err := s.engine.ProcessArchives(ctx, archives, gharchive.ProcessOptions{
+		BeforeStart: func(ctx context.Context, archive string) error {
+			if interval <= 0 || s.engine.Cache().Offline() || s.hasLocalArchive(archive) {
+				return nil
+			}
+			if wait := interval - time.Since(lastFetch); wait > 0 && !lastFetch.IsZero() {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+			lastFetch = time.Now()
+			return nil
+		},
+		OnDone: func(archive string, err error) bool {
+			if opts.OnArchive != nil {
+				opts.OnArchive(archive, err)
+			}
+			if err != nil {
+				logging.Warn("gharchive backfill: archive failed; skipping",
+					"archive", archive, "error", err)
+				result.Failed = append(result.Failed, archive)
+				return true
+			}
+			result.Processed++
+			return true
+		},
+	})
+	return result, err
+}
+
+// hasLocalArchive reports whether archive can be served from
+// cfg.LocalDir or the archive cache without a download.
+func (s *GHArchiveSource) hasLocalArchive(archive string) bool {
+	if s.engine.Cache().Contains(archive) {
+		return true
+	}
+	path := s.engine.LocalArchivePath(archive)
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// RestoreWindow loads persisted hour aggregates into the in-memory
+// window, e.g. rollup rows written by a backfill, so the collector
+// starts warm. Aggregates older than the window relative to the newest
+// one are dropped. Distinct-actor sketches are not persisted, so
+// restored hours contribute to event counts only.
+func (s *GHArchiveSource) RestoreWindow(aggs []GHArchiveHourAggregate) {
+	if len(aggs) == 0 {
+		return
+	}
+	var newest time.Time
+	for _, a := range aggs {
+		if a.HourBucket.After(newest) {
+			newest = a.HourBucket
+		}
+	}
+	newest = newest.UTC().Truncate(time.Hour)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	windowHours := int(s.cfg.Window / time.Hour)
+	for _, a := range aggs {
+		if a.RepoName == "" || a.EventCount <= 0 {
+			continue
+		}
+		bucket, ok := s.buckets[a.RepoName]
+		if !ok {
+			bucket = newRingBucket(newest, windowHours)
+			s.buckets[a.RepoName] = bucket
+		}
+		bucket.set(a.HourBucket, a.EventCount, a.PerEventTyp)
+	}
+
+	newRightEdge := newest.Add(time.Hour)
+	for _, bucket := range s.buckets {
+		bucket.slideTo(newRightEdge)
+	}
+	s.gcEmptyBuckets()
+}
@@ -0,0 +1,178 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/gharchive"
+)
+
+// TestBackfill_ProcessesRangeAndResumes walks a 3-hour range with one
+// missing archive, then re-runs over a wider range and checks that the
+// saved cursor is honoured.
+func TestBackfill_ProcessesRangeAndResumes(t *testing.T) {
+	h0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	archives := map[string][]byte{
+		GHArchiveArchiveName(h0): gzipNDJSON(t, []map[string]any{
+			{"type": "WatchEvent", "repo": map[string]any{"name": "acme/app"}},
+		}),
+		// h0+1h is missing upstream.
+		GHArchiveArchiveName(h0.Add(2 * time.Hour)): gzipNDJSON(t, []map[string]any{
+			{"type": "ForkEvent", "repo": map[string]any{"name": "acme/app"}},
+		}),
+		GHArchiveArchiveName(h0.Add(3 * time.Hour)): gzipNDJSON(t, []map[string]any{
+			{"type": "WatchEvent", "repo": map[string]any{"name": "acme/app"}},
+		}),
+	}
+	srv := fakeArchiveServer(t, archives)
+	t.Cleanup(srv.Close)
+
+	cursor := NewMemoryCursorStore()
+	rollup := newCapturingRollup()
+	src := newTestSource(t, srv.URL, h0.Add(30*24*time.Hour), cursor, rollup, GHArchiveHooks{})
+
+	var seen []string
+	res, err := src.Backfill(context.Background(), GHArchiveBackfillOptions{
+		From:      h0,
+		To:        h0.Add(2 * time.Hour),
+		Interval:  -1,
+		OnArchive: func(archive string, _ error) { seen = append(seen, archive) },
+	})
+	if err != nil {
+		t.Fatalf("Backfill: %v", err)
+	}
+	if res.Processed != 2 || !reflect.DeepEqual(res.Failed, []string{GHArchiveArchiveName(h0.Add(time.Hour))}) {
+		t.Errorf("result = %+v, want 2 processed and h0+1h failed", res)
+	}
+	if len(seen) != 3 {
+		t.Errorf("OnArchive saw %v, want 3 archives", seen)
+	}
+	if got := len(rollup.snapshot()); got != 2 {
+		t.Errorf("rollup archives = %d, want 2", got)
+	}
+
+	// Widening the range resumes after the saved cursor.
+	res, err = src.Backfill(context.Background(), GHArchiveBackfillOptions{
+		From:     h0,
+		To:       h0.Add(3 * time.Hour),
+		Interval: -1,
+	})
+	if err != nil {
+		t.Fatalf("Backfill resume: %v", err)
+	}
+	if !res.Start.Equal(h0.Add(3*time.Hour)) || res.Processed != 1 {
+		t.Errorf("resume = %+v, want start at h0+3h with 1 processed", res)
+	}
+
+	// Nothing left to do for the same range.
+	res, err = src.Backfill(context.Background(), GHArchiveBackfillOptions{From: h0, To: h0.Add(3 * time.Hour), Interval: -1})
+	if err != nil || !res.Start.IsZero() || res.Processed != 0 {
+		t.Errorf("re-run = %+v, %v; want no-op", res, err)
+	}
+
+	if _, err := src.Backfill(context.Background(), GHArchiveBackfillOptions{From: h0, To: h0.Add(-time.Hour)}); err == nil {
+		t.Error("expected error for inverted range")
+	}
+}
+
+func TestBackfill_ReadsLocalDirFirst(t *testing.T) {
+	h0 := time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	local := gzipNDJSON(t, []map[string]any{
+		{"type": "WatchEvent", "repo": map[string]any{"name": "local/repo"}},
+	})
+	if err := os.WriteFile(filepath.Join(dir, GHArchiveArchiveName(h0)+".json.gz"), local, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The origin only has the second hour.
+	srv := fakeArchiveServer(t, map[string][]byte{
+		GHArchiveArchiveName(h0.Add(time.Hour)): gzipNDJSON(t, []map[string]any{
+			{"type": "WatchEvent", "repo": map[string]any{"name": "remote/repo"}},
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	src := NewGHArchiveSource(GHArchiveConfig{
+		BaseURL:        srv.URL,
+		LocalDir:       dir,
+		Window:         24 * time.Hour,
+		MaxRetries:     1,
+		InitialBackoff: time.Millisecond,
+	}, NewMemoryCursorStore(), nil, GHArchiveHooks{})
+	src.SetClock(freezeClock(h0.Add(48 * time.Hour)))
+
+	res, err := src.Backfill(context.Background(), GHArchiveBackfillOptions{From: h0, To: h0.Add(time.Hour), Interval: -1})
+	if err != nil || res.Processed != 2 {
+		t.Fatalf("Backfill = %+v, %v; want 2 processed", res, err)
+	}
+	for _, repo := range []string{"local/repo", "remote/repo"} {
+		if _, ok := src.RepoActivity(repo); !ok {
+			t.Errorf("%s missing from window", repo)
+		}
+	}
+}
+
+// TestBackfill_ArchiveCacheReplaysOffline fills the archive cache from
+// the origin once, then replays the range offline after the origin is
+// gone.
+func TestBackfill_ArchiveCacheReplaysOffline(t *testing.T) {
+	h0 := time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC)
+	srv := fakeArchiveServer(t, map[string][]byte{
+		GHArchiveArchiveName(h0): gzipNDJSON(t, []map[string]any{
+			{"type": "WatchEvent", "repo": map[string]any{"name": "acme/app"}},
+		}),
+	})
+
+	dir := t.TempDir()
+	cache, err := gharchive.NewCache(gharchive.CacheConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newTestSource(t, srv.URL, h0.Add(48*time.Hour), NewMemoryCursorStore(), nil, GHArchiveHooks{})
+	src.SetArchiveCache(cache)
+	if res, err := src.Backfill(context.Background(), GHArchiveBackfillOptions{From: h0, To: h0, Interval: -1}); err != nil || res.Processed != 1 {
+		t.Fatalf("online Backfill = %+v, %v; want 1 processed", res, err)
+	}
+	srv.Close()
+
+	offline, err := gharchive.NewCache(gharchive.CacheConfig{Dir: dir, Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := newTestSource(t, srv.URL, h0.Add(48*time.Hour), NewMemoryCursorStore(), nil, GHArchiveHooks{})
+	replay.SetArchiveCache(offline)
+	res, err := replay.Backfill(context.Background(), GHArchiveBackfillOptions{From: h0, To: h0.Add(time.Hour), Interval: -1})
+	if err != nil {
+		t.Fatalf("offline Backfill: %v", err)
+	}
+	if res.Processed != 1 || len(res.Failed) != 1 {
+		t.Errorf("offline result = %+v, want cached hour processed and uncached hour failed", res)
+	}
+	if _, ok := replay.RepoActivity("acme/app"); !ok {
+		t.Error("acme/app missing from replayed window")
+	}
+}
+
+func TestRestoreWindow(t *testing.T) {
+	src := NewGHArchiveSource(GHArchiveConfig{Window: 3 * time.Hour}, NewMemoryCursorStore(), nil, GHArchiveHooks{})
+	h := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	src.RestoreWindow([]GHArchiveHourAggregate{
+		{RepoName: "acme/app", HourBucket: h.Add(-5 * time.Hour), EventCount: 50}, // outside window
+		{RepoName: "acme/app", HourBucket: h.Add(-time.Hour), EventCount: 4, PerEventTyp: map[string]int{"WatchEvent": 4}},
+		{RepoName: "acme/app", HourBucket: h, EventCount: 6, PerEventTyp: map[string]int{"WatchEvent": 6}},
+		{RepoName: "old/repo", HourBucket: h.Add(-10 * time.Hour), EventCount: 9},
+	})
+
+	act, ok := src.RepoActivity("acme/app")
+	if !ok || act.TotalEvents != 10 || act.PerEventType["WatchEvent"] != 10 {
+		t.Errorf("acme/app = %+v, %v; want 10 events in window", act, ok)
+	}
+	if _, ok := src.RepoActivity("old/repo"); ok {
+		t.Error("old/repo outside the window should not be restored")
+	}
+}
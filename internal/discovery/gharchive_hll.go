@@ -0,0 +1,139 @@
+package discovery
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// gharchive_hll.go is the distinct-actor counter behind the gharchive
+// activity window. Raw event counts let one bot pushing 500 times look
+// like a viral repo; ranking by distinct stargazers / contributors
+// needs a per-(repo, hour) set of actors that is cheap enough to keep
+// for every repo in the firehose.
+//
+// hllSketch is a HyperLogLog with a sparse front end. The vast majority
+// of repo-hours see a handful of actors, so a sketch starts as an exact
+// list of actor hashes and only switches to 2^hllPrecision dense
+// registers once it holds more than hllSparseMax of them. Sketches for
+// the same repo merge losslessly, which is how hourly cells roll up to
+// a window-wide distinct count.
+
+const (
+	// hllPrecision sets the dense register count (2^10 = 1024 bytes
+	// per sketch) for a standard error of ~3.25%.
+	hllPrecision = 10
+	hllRegisters = 1 << hllPrecision
+
+	// hllSparseMax is the number of exact hashes kept before a sketch
+	// goes dense. 64 hashes (512 bytes) is half the dense footprint.
+	hllSparseMax = 64
+)
+
+// hllSketch approximates the number of distinct values added to it.
+// The zero value is an empty sketch ready for use.
+type hllSketch struct {
+	sparse    []uint64 // distinct hashes while the sketch is small
+	registers []uint8  // dense registers; nil while sparse
+}
+
+// hashActor maps an actor login to a well-mixed 64-bit hash. FNV-1a
+// alone clusters short, similar strings in the high bits that HLL
+// indexes on, so the result goes through the splitmix64 finalizer.
+func hashActor(login string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(login))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// add records one hashed value.
+func (h *hllSketch) add(hash uint64) {
+	if h.registers != nil {
+		h.addDense(hash)
+		return
+	}
+	for _, v := range h.sparse {
+		if v == hash {
+			return
+		}
+	}
+	h.sparse = append(h.sparse, hash)
+	if len(h.sparse) > hllSparseMax {
+		h.densify()
+	}
+}
+
+// addDense updates the register the hash selects.
+func (h *hllSketch) addDense(hash uint64) {
+	idx := hash >> (64 - hllPrecision)
+	// Rank of the first set bit in the remaining 64-p bits; the OR'd
+	// sentinel bounds it when those bits are all zero.
+	w := hash<<hllPrecision | 1<<(hllPrecision-1)
+	rank := uint8(bits.LeadingZeros64(w) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// densify converts the sparse hash list into registers.
+func (h *hllSketch) densify() {
+	h.registers = make([]uint8, hllRegisters)
+	for _, v := range h.sparse {
+		h.addDense(v)
+	}
+	h.sparse = nil
+}
+
+// merge folds o into h (set union).
+func (h *hllSketch) merge(o *hllSketch) {
+	if o == nil {
+		return
+	}
+	if o.registers == nil {
+		for _, v := range o.sparse {
+			h.add(v)
+		}
+		return
+	}
+	if h.registers == nil {
+		h.densify()
+	}
+	for i, r := range o.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+}
+
+// estimate returns the approximate distinct count. Exact while sparse;
+// dense sketches use the HLL estimator with linear counting for the
+// small-range correction.
+func (h *hllSketch) estimate() int {
+	if h == nil {
+		return 0
+	}
+	if h.registers == nil {
+		return len(h.sparse)
+	}
+	m := float64(hllRegisters)
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	est := alpha * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(m/float64(zeros))
+	}
+	return int(est + 0.5)
+}
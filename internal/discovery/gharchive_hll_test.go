@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHLLSketch_ExactWhileSparse(t *testing.T) {
+	var h hllSketch
+	for i := 0; i < 3; i++ {
+		for j := 0; j < hllSparseMax; j++ {
+			h.add(hashActor(fmt.Sprintf("user-%d", j)))
+		}
+	}
+	if h.registers != nil {
+		t.Fatal("sketch densified with only hllSparseMax distinct values")
+	}
+	if got := h.estimate(); got != hllSparseMax {
+		t.Errorf("estimate = %d, want %d", got, hllSparseMax)
+	}
+}
+
+func TestHLLSketch_DenseEstimateWithinTolerance(t *testing.T) {
+	for _, n := range []int{500, 10000, 100000} {
+		var h hllSketch
+		for i := 0; i < n; i++ {
+			h.add(hashActor(fmt.Sprintf("user-%d", i)))
+		}
+		got := h.estimate()
+		if rel := math.Abs(float64(got-n)) / float64(n); rel > 0.08 {
+			t.Errorf("n=%d: estimate = %d (%.1f%% off), want within 8%%", n, got, rel*100)
+		}
+	}
+}
+
+// Merging two overlapping sketches approximates the union, for both
+// sparse+sparse and dense+dense inputs.
+func TestHLLSketch_MergeIsUnion(t *testing.T) {
+	build := func(from, to int) *hllSketch {
+		h := &hllSketch{}
+		for i := from; i < to; i++ {
+			h.add(hashActor(fmt.Sprintf("user-%d", i)))
+		}
+		return h
+	}
+
+	small := build(0, 20)
+	small.merge(build(10, 30))
+	if got := small.estimate(); got != 30 {
+		t.Errorf("sparse union = %d, want 30", got)
+	}
+
+	large := build(0, 6000)
+	large.merge(build(4000, 10000))
+	got := large.estimate()
+	if rel := math.Abs(float64(got-10000)) / 10000; rel > 0.08 {
+		t.Errorf("dense union = %d, want ~10000", got)
+	}
+
+	var empty hllSketch
+	empty.merge(nil)
+	if empty.estimate() != 0 {
+		t.Errorf("empty merge(nil) = %d, want 0", empty.estimate())
+	}
+}
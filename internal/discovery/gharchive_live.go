@@ -0,0 +1,60 @@
+package discovery
+
+import (
+	"time"
+)
+
+// gharchive_live.go lets events that reach the daemon before their
+// archive is published — GitHub webhook deliveries — count toward the
+// sliding window right away, so a repo's burst shows up in
+// TopActiveRepos an hour or two earlier than the archive alone allows.
+//
+// Archives stay authoritative. A live event lands in its hour's slot
+// only while no archive at or after that hour has been committed; when
+// the hour's archive arrives commitHour overwrites the slot for every
+// repo the archive saw, so a public event is never counted twice. Live
+// events for repos the archive never mentions (private repos) are kept
+// until they age out of the window.
+
+// RecordLiveEvent counts one event of eventType (a gharchive type such
+// as "WatchEvent") for repo at the given time. Events outside the type
+// filter, from bot actors, older than the window, or in an hour an
+// archive has already covered are dropped. It reports whether the event
+// was counted.
+func (s *GHArchiveSource) RecordLiveEvent(repo, eventType, actor string, at time.Time) bool {
+	if repo == "" || !s.eventTypes[eventType] || isBotActor(actor, s.botPatterns) {
+		return false
+	}
+	hour := at.UTC().Truncate(time.Hour)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.archivedThrough.IsZero() && !hour.After(s.archivedThrough) {
+		return false
+	}
+	bucket, ok := s.buckets[repo]
+	if !ok {
+		bucket = newRingBucket(hour, int(s.cfg.Window/time.Hour))
+		s.buckets[repo] = bucket
+	}
+	return bucket.add(hour, eventType)
+}
+
+// add counts one eventType event in hourBucket, rotating the ring
+// forward when the hour is past its right edge. Unlike set it
+// accumulates and leaves the slot's actor sketches alone.
+func (b *ringBucket) add(hourBucket time.Time, eventType string) bool {
+	hourBucket = hourBucket.Truncate(time.Hour).UTC()
+	b.slideTo(hourBucket.Add(time.Hour))
+	idx, ok := b.slotIndex(hourBucket)
+	if !ok {
+		return false
+	}
+	b.counts[idx]++
+	if b.perType[idx] == nil {
+		b.perType[idx] = make(map[string]int, 1)
+	}
+	b.perType[idx][eventType]++
+	return true
+}
@@ -0,0 +1,67 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecordLiveEvent_ArchiveReplacesLiveCounts(t *testing.T) {
+	hour := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	archive := hour.Format(gharchiveArchiveLayout)
+
+	body := gzipNDJSON(t, []map[string]any{
+		{"type": "WatchEvent", "repo": map[string]any{"name": "alice/repo"}},
+		{"type": "WatchEvent", "repo": map[string]any{"name": "alice/repo"}},
+		{"type": "WatchEvent", "repo": map[string]any{"name": "alice/repo"}},
+	})
+	srv := fakeArchiveServer(t, map[string][]byte{archive: body})
+	t.Cleanup(srv.Close)
+
+	src := newTestSource(t, srv.URL, hour.Add(3*time.Hour), NewMemoryCursorStore(), nil, GHArchiveHooks{})
+
+	// Webhooks arrive before the archive is published.
+	for i := 0; i < 2; i++ {
+		if !src.RecordLiveEvent("alice/repo", "WatchEvent", "bob", hour.Add(10*time.Minute)) {
+			t.Fatal("live WatchEvent not counted")
+		}
+	}
+	src.RecordLiveEvent("alice/repo", "ForkEvent", "carol", hour.Add(70*time.Minute))
+	src.RecordLiveEvent("acme/private", "PullRequestEvent", "dave", hour.Add(5*time.Minute))
+	if src.RecordLiveEvent("alice/repo", "WatchEvent", "dependabot[bot]", hour) {
+		t.Error("bot event counted")
+	}
+	if src.RecordLiveEvent("alice/repo", "IssuesEvent", "bob", hour) {
+		t.Error("event outside the type filter counted")
+	}
+
+	if got := totalEvents(src, "alice/repo"); got != 3 {
+		t.Fatalf("before archive: %d events, want 3 live", got)
+	}
+
+	if err := src.ProcessArchive(context.Background(), archive); err != nil {
+		t.Fatal(err)
+	}
+	// The archive's 3 stars replace the 2 live ones; the fork in the
+	// next hour and the private repo's pull request are kept.
+	if got := totalEvents(src, "alice/repo"); got != 4 {
+		t.Errorf("after archive: %d events, want 3 archived + 1 live", got)
+	}
+	if got := totalEvents(src, "acme/private"); got != 1 {
+		t.Errorf("private repo: %d events, want 1", got)
+	}
+
+	// A late delivery for an archived hour is already counted.
+	if src.RecordLiveEvent("alice/repo", "WatchEvent", "erin", hour.Add(30*time.Minute)) {
+		t.Error("live event for an archived hour counted")
+	}
+}
+
+func totalEvents(src *GHArchiveSource, repo string) int {
+	for _, a := range src.TopActiveRepos(0, 1) {
+		if a.RepoName == repo {
+			return a.TotalEvents
+		}
+	}
+	return 0
+}
@@ -41,9 +41,33 @@ import (
 //   - OnDedupComplete fires once after the dedup pass completes, carrying
 //     the total candidates admitted and how many were dropped as
 //     already-tracked.
+//   - OnTrackedRelease fires once per release signal (initial version,
+//     1.0 graduation, major bump) on a repo that is already tracked.
+//     Only emitted when Sources.GHArchive.ReleaseSignals is on.
 type GHArchivePipelineHooks struct {
 	OnCandidateAdmitted func(eventType string)
 	OnDedupComplete     func(total, dropped int)
+	OnTrackedRelease    func(sig GHArchiveReleaseSignal)
+}
+
+// gharchiveCandidate is one entry in the gharchive admission list: the
+// repo's window snapshot plus the release signal attached to it, if
+// any. releaseOnly marks repos admitted by the release signal alone
+// (below the activity floor or outside top-N).
+type gharchiveCandidate struct {
+	activity    GHArchiveRepoActivity
+	release     *GHArchiveReleaseSignal
+	releaseOnly bool
+}
+
+// eventType returns the event type the candidate is attributed to in
+// candidates_total: ReleaseEvent for release-only admissions, the
+// dominant window event type otherwise.
+func (c gharchiveCandidate) eventType() string {
+	if c.releaseOnly {
+		return gharchiveReleaseEventType
+	}
+	return dominantEventType(c.activity.PerEventType)
 }
 
 // dominantEventType returns the event type with the highest count in
@@ -77,11 +101,14 @@ func dominantEventType(perType map[string]int) string {
 //
 // AC mapping ([ISI-952]):
 //
-//   - Top-N + activity floor: TopActiveRepos(TopN, ActivityFloor).
+//   - Top-N + activity floor: TopActiveReposBy(RankBy, TopN, ActivityFloor).
 //   - Dedup: state.Store.GetRepoState skip.
 //   - Classifier handoff: returns *Result with []DiscoveredRepo —
 //     same shape as DiscoverTopic / DiscoverOrg / DiscoverLanguage.
 //   - min_stars gate: Sources.GHArchive.MinStarsGate (0 = off).
+//   - Release signals: Sources.GHArchive.ReleaseSignals appends up to
+//     MaxReleaseCandidates pending release candidates after the top-N
+//     list, bypassing the activity floor.
 func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error) {
 	if !d.config.Sources.GHArchive.Enabled || d.ghArchive == nil {
 		return nil, nil
@@ -96,6 +123,17 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 	if floor <= 0 {
 		floor = DefaultGHArchiveActivityFloor
 	}
+	rankBy := cfg.RankBy
+	if rankBy == "" {
+		rankBy = GHArchiveRankEvents
+	}
+	maxReleases := 0
+	if cfg.ReleaseSignals {
+		maxReleases = cfg.MaxReleaseCandidates
+		if maxReleases <= 0 {
+			maxReleases = DefaultGHArchiveMaxReleaseCandidates
+		}
+	}
 	// MinStarsCacheTTL governs the per-repo stargazer cache used by
 	// the pre-hydration prefilter (ISI-982). Zero falls back to the
 	// package default so an operator who only sets MinStarsGate still
@@ -131,19 +169,18 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 	}
 
 	d.log("info", "Starting gharchive discovery",
-		"top_n", topN, "activity_floor", floor,
+		"top_n", topN, "activity_floor", floor, "rank_by", string(rankBy),
 		"min_stars_gate", cfg.MinStarsGate,
 		"min_stars_cache_ttl", cacheTTL,
 		"tracked_repos", d.ghArchive.TrackedRepoCount())
 
-	// TopActiveRepos applies the activity floor and N cap. The
-	// collector returns repos sorted by total events descending.
-	candidates := d.ghArchive.TopActiveRepos(topN, floor)
+	candidates := d.gharchiveCandidates(rankBy, topN, floor, maxReleases)
 	result.TotalFound = len(candidates)
 
-	for _, act := range candidates {
+	for _, cand := range candidates {
+		act := cand.activity
 		if d.ghArchivePipelineHooks.OnCandidateAdmitted != nil {
-			d.ghArchivePipelineHooks.OnCandidateAdmitted(dominantEventType(act.PerEventType))
+			d.ghArchivePipelineHooks.OnCandidateAdmitted(cand.eventType())
 		}
 
 		select {
@@ -153,21 +190,47 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 		default:
 		}
 
+		// The release signal is acked at every terminal decision —
+		// emitted, OnTrackedRelease fired, or the repo filtered out —
+		// so a rejected release-only repo is not hydrated again every
+		// cycle. A hydration error is not a decision: the signal stays
+		// pending and the next cycle retries it.
 		owner, name, ok := splitRepoName(act.RepoName)
 		if !ok {
 			// gharchive should always produce "owner/name", but be
 			// defensive against malformed entries rather than
 			// failing the whole step.
 			d.log("debug", "gharchive: skipping malformed repo name", "name", act.RepoName)
+			d.ackRelease(cand)
 			continue
 		}
 		fullName := owner + "/" + name
 
+		// Canonicalize against recorded aliases (canonical.go) before
+		// any REST call: a known rename is deduped under its new name
+		// and a known fork/mirror is rejected outright.
+		switch canonical, reason := d.lookupAlias(fullName); reason {
+		case aliasReasonRenamed:
+			if o, n, ok := splitRepoName(canonical); ok {
+				owner, name, fullName = o, n, canonical
+				result.Renamed++
+			}
+		case aliasReasonFork, aliasReasonMirror:
+			if !d.allowsAliasReason(reason) {
+				result.ForksOrMirrors++
+				d.ackRelease(cand)
+				continue
+			}
+		}
+
 		// Tracked-repo dedup — AC: "Dedup against currently tracked
 		// repos (no duplicate ingest)." Stop here before paying the
 		// REST hydration cost.
 		if d.store.GetRepoState(fullName) != nil {
 			result.AlreadyTracked++
+			if cand.release != nil {
+				d.notifyTrackedRelease(*cand.release)
+			}
 			continue
 		}
 
@@ -175,6 +238,7 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 		// sources.
 		if d.isExcluded(fullName) {
 			result.Excluded++
+			d.ackRelease(cand)
 			continue
 		}
 
@@ -194,6 +258,7 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 						"cached_stars", obs.Stars,
 						"gate", cfg.MinStarsGate,
 						"age", age)
+					d.ackRelease(cand)
 					continue
 				}
 			}
@@ -209,6 +274,30 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 			continue
 		}
 
+		// GitHub redirected the request: the firehose still cites the
+		// old name. Record the alias, fold any state kept under the old
+		// name into the new one, and redo the dedup checks.
+		if metrics.RenamedFrom(owner, name) {
+			d.recordAlias(fullName, metrics.FullName, aliasReasonRenamed)
+			d.store.MergeRepo(fullName, metrics.FullName)
+			d.log("info", "gharchive: candidate was renamed",
+				"from", fullName, "to", metrics.FullName)
+			fullName = metrics.FullName
+			result.Renamed++
+			if d.store.GetRepoState(fullName) != nil {
+				result.AlreadyTracked++
+				if cand.release != nil {
+					d.notifyTrackedRelease(*cand.release)
+				}
+				continue
+			}
+			if d.isExcluded(fullName) {
+				result.Excluded++
+				d.ackRelease(cand)
+				continue
+			}
+		}
+
 		// Cache the freshly-observed stargazer count so the next
 		// cycle's prefilter can skip this repo when MinStarsGate is
 		// in effect. Written unconditionally — even when the gate is
@@ -226,10 +315,29 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 		// branch handles cold-cache / stale-cache fallthroughs and
 		// the always-on post-filter semantics.
 		if cfg.MinStarsGate > 0 && metrics.Stars < cfg.MinStarsGate {
+			d.ackRelease(cand)
 			continue
 		}
 
-		discovered := buildDiscoveredFromGHArchive(metrics, act)
+		discovered := buildDiscoveredFromGHArchive(metrics, act, rankBy)
+		if cand.release != nil {
+			discovered.ReleaseTag = cand.release.Tag
+			discovered.ReleaseKind = cand.release.Kind
+		}
+
+		// Remember forks and mirrors so the next cycle rejects them
+		// before hydration, then apply the fork/mirror switch.
+		switch {
+		case discovered.Fork:
+			d.recordAlias(fullName, discovered.Upstream, aliasReasonFork)
+		case discovered.Mirror:
+			d.recordAlias(fullName, fullName, aliasReasonMirror)
+		}
+		if !d.passesIdentityFilters(discovered) {
+			result.ForksOrMirrors++
+			d.ackRelease(cand)
+			continue
+		}
 
 		// MaxAgeDays from the parent Config still applies as a
 		// safety net for hobby/inactive repos that bursted once.
@@ -246,6 +354,7 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 		if d.config.MaxAgeDays > 0 && !discovered.UpdatedAt.IsZero() {
 			ageDays := time.Since(discovered.UpdatedAt).Hours() / 24
 			if ageDays > float64(d.config.MaxAgeDays) {
+				d.ackRelease(cand)
 				continue
 			}
 		}
@@ -257,6 +366,10 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 			result.AutoTracked++
 		}
 		result.Repos = append(result.Repos, discovered)
+		d.ackRelease(cand)
+		if cand.releaseOnly {
+			result.ReleaseCandidates++
+		}
 
 		// Daily-cap counter ([ISI-954]). Counted after the candidate
 		// is committed to the result so the metric tracks "candidates
@@ -288,11 +401,73 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 		"new", result.NewRepos,
 		"already_tracked", result.AlreadyTracked,
 		"excluded", result.Excluded,
-		"min_stars_prefiltered", result.MinStarsPrefiltered)
+		"min_stars_prefiltered", result.MinStarsPrefiltered,
+		"release_candidates", result.ReleaseCandidates,
+		"forks_or_mirrors", result.ForksOrMirrors,
+		"renamed", result.Renamed)
 
 	return result, nil
 }
 
+// gharchiveCandidates builds the admission list for one
+// DiscoverFromGHArchive cycle. TopActiveReposBy applies the activity
+// floor and N cap and returns repos sorted by rankBy descending.
+// When maxReleases is positive, pending release signals are attached
+// to matching top-N entries and up to maxReleases of the rest, oldest
+// first, are appended after them as release-only candidates, so a
+// quiet repo cutting v1.0.0 still reaches hydration. Signals over the
+// cap stay pending for the next cycle; zero disables release signals.
+func (d *Discoverer) gharchiveCandidates(rankBy GHArchiveRankMetric, topN, floor, maxReleases int) []gharchiveCandidate {
+	top := d.ghArchive.TopActiveReposBy(rankBy, topN, floor)
+	out := make([]gharchiveCandidate, 0, len(top))
+	index := make(map[string]int, len(top))
+	for i, act := range top {
+		out = append(out, gharchiveCandidate{activity: act})
+		index[act.RepoName] = i
+	}
+	if maxReleases <= 0 {
+		return out
+	}
+
+	releaseOnly := 0
+	for _, sig := range d.ghArchive.PendingReleaseSignals() {
+		sig := sig
+		if i, ok := index[sig.RepoName]; ok {
+			out[i].release = &sig
+			continue
+		}
+		if releaseOnly >= maxReleases {
+			continue
+		}
+		releaseOnly++
+		act, ok := d.ghArchive.RepoActivity(sig.RepoName)
+		if !ok {
+			act = GHArchiveRepoActivity{RepoName: sig.RepoName}
+		}
+		out = append(out, gharchiveCandidate{activity: act, release: &sig, releaseOnly: true})
+	}
+	return out
+}
+
+// ackRelease acks cand's release signal, if it carries one, once the
+// pipeline has reached a terminal decision on the repo.
+func (d *Discoverer) ackRelease(cand gharchiveCandidate) {
+	if cand.release != nil {
+		d.ghArchive.AckReleaseSignal(cand.release.RepoName, cand.release.Tag)
+	}
+}
+
+// notifyTrackedRelease surfaces a release signal on an already-tracked
+// repo through OnTrackedRelease and acks it so it fires once per tag.
+func (d *Discoverer) notifyTrackedRelease(sig GHArchiveReleaseSignal) {
+	d.log("info", "gharchive: tracked repo published a major release",
+		"repo", sig.RepoName, "tag", sig.Tag, "kind", string(sig.Kind))
+	if d.ghArchivePipelineHooks.OnTrackedRelease != nil {
+		d.ghArchivePipelineHooks.OnTrackedRelease(sig)
+	}
+	d.ghArchive.AckReleaseSignal(sig.RepoName, sig.Tag)
+}
+
 // buildDiscoveredFromGHArchive converts a hydrated REST repo + the
 // collector's activity snapshot into a DiscoveredRepo.
 //
@@ -301,7 +476,8 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 // (avg stars/day × 7 vs current stars). For gharchive candidates,
 // event volume IS the velocity signal — using the heuristic on a
 // gharchive-discovered repo would discard the very signal that
-// surfaced it. We seed GrowthScore from TotalEvents directly and let
+// surfaced it. We seed GrowthScore from the ranked activity value
+// (TotalEvents, or distinct actors under RankBy) directly and let
 // d.normalizeScores apply the same per-result NormalizedScore pass
 // that the other sources use.
 //
@@ -309,7 +485,7 @@ func (d *Discoverer) DiscoverFromGHArchive(ctx context.Context) (*Result, error)
 // based filtering in DiscoverFromGHArchive is best-effort. Extending
 // RepoMetrics to carry timestamps is a small follow-up — tracked
 // inline TODO below — but it does not block AC for Story 2.
-func buildDiscoveredFromGHArchive(metrics *github.RepoMetrics, act GHArchiveRepoActivity) DiscoveredRepo {
+func buildDiscoveredFromGHArchive(metrics *github.RepoMetrics, act GHArchiveRepoActivity, rankBy GHArchiveRankMetric) DiscoveredRepo {
 	owner := metrics.Owner
 	name := metrics.Name
 	fullName := metrics.FullName
@@ -326,13 +502,16 @@ func buildDiscoveredFromGHArchive(metrics *github.RepoMetrics, act GHArchiveRepo
 		Topics:      metrics.Topics,
 		Stars:       metrics.Stars,
 		Forks:       metrics.Forks,
+		Fork:        metrics.Fork,
+		Mirror:      metrics.Mirror,
+		Upstream:    metrics.Upstream(),
 		// TODO(ISI-952 follow-up): RepoMetrics doesn't expose
 		// CreatedAt/UpdatedAt yet. Extending the GraphQL/REST
 		// fragments to surface them is a tiny change but lives
 		// outside Story 2's AC. Leaving zero values until then —
 		// MaxAgeDays filter degrades to "always pass" which is
 		// safe (gharchive's window is itself a recency floor).
-		GrowthScore: float64(act.TotalEvents),
+		GrowthScore: float64(act.Value(rankBy)),
 	}
 }
 
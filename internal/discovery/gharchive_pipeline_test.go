@@ -57,6 +57,9 @@ type repoMetricsResponse struct {
 	Language    string            `json:"language"`
 	Topics      []string          `json:"topics"`
 	Description string            `json:"description"`
+	Fork        bool              `json:"fork,omitempty"`
+	Source      map[string]string `json:"source,omitempty"`
+	MirrorURL   string            `json:"mirror_url,omitempty"`
 }
 
 // fakeRESTServer serves a minimal /repos/{owner}/{repo} endpoint backed
@@ -669,6 +672,10 @@ func TestDiscoverFromGHArchive_GHArchiveStepInBuildSearchPlan(t *testing.T) {
 	if last := plan[len(plan)-1]; last.source != "gharchive" {
 		t.Errorf("last step = %q, want gharchive", last.source)
 	}
+
+	if steps, searches := d.PlannedSteps(); steps != 2 || searches != 1 {
+		t.Errorf("PlannedSteps = %d, %d; want 2 steps, 1 search", steps, searches)
+	}
 }
 
 // TestDiscoverFromGHArchive_NotInPlanWhenDisabled — confirm the
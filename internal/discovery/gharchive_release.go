@@ -0,0 +1,248 @@
+package discovery
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gharchive_release.go adds release-driven discovery on top of the
+// gharchive firehose. Initial versions and 1.0 graduations are inflection
+// points that event volume alone misses — a repo cutting v1.0.0 often
+// does so on a quiet day — so the collector inspects ReleaseEvent
+// payloads during decode and keeps one release signal per repo for the
+// length of the sliding window.
+//
+// DiscoverFromGHArchive consumes the signals in two ways:
+//
+//   - Untracked repos become candidates that bypass the activity floor,
+//     with the release tag recorded on the DiscoveredRepo.
+//   - Tracked repos fire GHArchivePipelineHooks.OnTrackedRelease so the
+//     daemon can log / count a "major release" event.
+//
+// Detection runs regardless of GHArchiveConfig.EventTypes: a
+// ReleaseEvent is inspected for its tag even when the type filter
+// drops it from the activity counts.
+
+// ReleaseKind classifies a release tag as a discovery inflection point.
+type ReleaseKind string
+
+const (
+	// ReleaseKindInitialVersion is an initial-version tag (0.0.0,
+	// 0.0.1 or 0.1.0). It is judged from the tag shape alone: the
+	// release history is not consulted, so a project whose first tag
+	// is v0.3.0 is missed and a re-published 0.1.0 is reported again.
+	ReleaseKindInitialVersion ReleaseKind = "initial_version"
+	// ReleaseKindGraduation is the pre-1.0 → 1.0 graduation (1.0.0).
+	ReleaseKindGraduation ReleaseKind = "graduation"
+	// ReleaseKindMajor is a major version bump past 1.x (2.0.0, 3.0.0, …).
+	ReleaseKindMajor ReleaseKind = "major"
+)
+
+// gharchiveReleaseEventType is the gharchive event type carrying
+// release payloads.
+const gharchiveReleaseEventType = "ReleaseEvent"
+
+// gharchiveReleaseEvent is the envelope decoded for ReleaseEvent lines
+// only. The payload shape mirrors releasePayload in
+// internal/metrics/gharchive.go plus the prerelease / draft flags we
+// need to skip release candidates.
+type gharchiveReleaseEvent struct {
+	Repo struct {
+		Name string `json:"name"`
+	} `json:"repo"`
+	Payload struct {
+		Action  string `json:"action"`
+		Release struct {
+			TagName     string `json:"tag_name"`
+			PublishedAt string `json:"published_at"`
+			Prerelease  bool   `json:"prerelease"`
+			Draft       bool   `json:"draft"`
+		} `json:"release"`
+	} `json:"payload"`
+}
+
+// GHArchiveReleaseSignal is one repo's most recent release inflection
+// point inside the sliding window.
+type GHArchiveReleaseSignal struct {
+	RepoName   string
+	Tag        string
+	Kind       ReleaseKind
+	HourBucket time.Time // UTC, hour-aligned archive the release landed in
+
+	// acked is set once the pipeline has reached a decision on the
+	// signal — emitted, fired OnTrackedRelease for, or filtered out
+	// the repo. A hydration error leaves it pending for a retry.
+	acked bool
+}
+
+// parseReleaseEvent decodes a ReleaseEvent line and returns the
+// release signal it carries. ok=false for drafts, prereleases,
+// non-publish actions and tags that are not an inflection point.
+func parseReleaseEvent(line []byte, hourBucket time.Time) (GHArchiveReleaseSignal, bool) {
+	var evt gharchiveReleaseEvent
+	if err := json.Unmarshal(line, &evt); err != nil {
+		return GHArchiveReleaseSignal{}, false
+	}
+	if evt.Repo.Name == "" {
+		return GHArchiveReleaseSignal{}, false
+	}
+	// gharchive records "published" for ReleaseEvent; older archives
+	// omit the action entirely.
+	if a := evt.Payload.Action; a != "" && a != "published" {
+		return GHArchiveReleaseSignal{}, false
+	}
+	rel := evt.Payload.Release
+	if rel.Draft || rel.Prerelease {
+		return GHArchiveReleaseSignal{}, false
+	}
+	kind, ok := classifyReleaseTag(rel.TagName)
+	if !ok {
+		return GHArchiveReleaseSignal{}, false
+	}
+	return GHArchiveReleaseSignal{
+		RepoName:   evt.Repo.Name,
+		Tag:        rel.TagName,
+		Kind:       kind,
+		HourBucket: hourBucket,
+	}, true
+}
+
+// classifyReleaseTag maps a release tag to its ReleaseKind from the
+// tag's version alone. Returns
+// ok=false for tags that are not semver-shaped, carry a prerelease
+// suffix, or are ordinary minor/patch releases.
+func classifyReleaseTag(tag string) (ReleaseKind, bool) {
+	major, minor, patch, ok := parseReleaseVersion(tag)
+	if !ok {
+		return "", false
+	}
+	switch {
+	case major == 0 && minor == 0 && patch <= 1:
+		return ReleaseKindInitialVersion, true
+	case major == 0 && minor == 1 && patch == 0:
+		return ReleaseKindInitialVersion, true
+	case major == 1 && minor == 0 && patch == 0:
+		return ReleaseKindGraduation, true
+	case major >= 2 && minor == 0 && patch == 0:
+		return ReleaseKindMajor, true
+	}
+	return "", false
+}
+
+// parseReleaseVersion extracts major.minor.patch from a release tag.
+// Accepts an optional "v" prefix, monorepo-style "component/v1.2.3"
+// tags, and two-component versions ("1.0" → 1.0.0). Tags with a
+// prerelease or build suffix ("1.0.0-rc1", "2.0.0+build") are
+// rejected so release candidates never count as a graduation.
+func parseReleaseVersion(tag string) (major, minor, patch int, ok bool) {
+	tag = strings.TrimSpace(tag)
+	if i := strings.LastIndexByte(tag, '/'); i >= 0 {
+		tag = tag[i+1:]
+	}
+	tag = strings.TrimPrefix(strings.TrimPrefix(tag, "v"), "V")
+	if tag == "" || strings.ContainsAny(tag, "-+") {
+		return 0, 0, 0, false
+	}
+	parts := strings.Split(tag, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, 0, false
+	}
+	nums := [3]int{}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, 0, 0, false
+		}
+		nums[i] = n
+	}
+	return nums[0], nums[1], nums[2], true
+}
+
+// recordReleaseSignals merges this archive's release signals into the
+// per-repo signal map. A replay of the same tag keeps the existing
+// entry (and its acked flag) so reprocessing stays idempotent; a new
+// tag replaces the old signal and is surfaced again.
+//
+// Safe to call only with s.mu held.
+func (s *GHArchiveSource) recordReleaseSignals(signals []GHArchiveReleaseSignal) {
+	for _, sig := range signals {
+		if prev, ok := s.releases[sig.RepoName]; ok {
+			if prev.Tag == sig.Tag || prev.HourBucket.After(sig.HourBucket) {
+				continue
+			}
+		}
+		sig := sig
+		s.releases[sig.RepoName] = &sig
+	}
+}
+
+// gcReleaseSignals drops release signals whose archive hour has slid
+// out of the window ending at rightEdge.
+//
+// Safe to call only with s.mu held.
+func (s *GHArchiveSource) gcReleaseSignals(rightEdge time.Time) {
+	cutoff := rightEdge.Add(-s.cfg.Window)
+	for repo, sig := range s.releases {
+		if sig.HourBucket.Before(cutoff) {
+			delete(s.releases, repo)
+		}
+	}
+}
+
+// PendingReleaseSignals returns the release signals inside the window
+// that the pipeline has not yet surfaced, oldest first (ties broken by
+// repo name). The returned values are copies.
+func (s *GHArchiveSource) PendingReleaseSignals() []GHArchiveReleaseSignal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]GHArchiveReleaseSignal, 0, len(s.releases))
+	for _, sig := range s.releases {
+		if sig.acked {
+			continue
+		}
+		out = append(out, *sig)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].HourBucket.Equal(out[j].HourBucket) {
+			return out[i].HourBucket.Before(out[j].HourBucket)
+		}
+		return out[i].RepoName < out[j].RepoName
+	})
+	return out
+}
+
+// AckReleaseSignal marks the signal for (repo, tag) as processed so
+// PendingReleaseSignals stops returning it. A no-op when the repo's
+// current signal carries a different tag.
+func (s *GHArchiveSource) AckReleaseSignal(repo, tag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sig, ok := s.releases[repo]; ok && sig.Tag == tag {
+		sig.acked = true
+	}
+}
+
+// RepoActivity returns the window snapshot for one repo. ok=false when
+// the repo has no events in the current window.
+func (s *GHArchiveSource) RepoActivity(repo string) (GHArchiveRepoActivity, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bucket, ok := s.buckets[repo]
+	if !ok {
+		return GHArchiveRepoActivity{}, false
+	}
+	stars, contribs := bucket.uniqueActors()
+	return GHArchiveRepoActivity{
+		RepoName:           repo,
+		TotalEvents:        bucket.total(),
+		HourCounts:         bucket.hourCountsOldestFirst(),
+		PerEventType:       bucket.perTypeAggregate(),
+		PeakHourEvents:     bucket.peak(),
+		UniqueStargazers:   stars,
+		UniqueContributors: contribs,
+	}, true
+}
@@ -0,0 +1,292 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// releaseEvent builds a gharchive ReleaseEvent record for tests.
+func releaseEvent(repo, tag string, prerelease bool) map[string]any {
+	return map[string]any{
+		"type": "ReleaseEvent",
+		"repo": map[string]any{"name": repo},
+		"payload": map[string]any{
+			"action": "published",
+			"release": map[string]any{
+				"tag_name":   tag,
+				"prerelease": prerelease,
+			},
+		},
+	}
+}
+
+// releaseTestSource seeds a collector from one archive holding the
+// given events.
+func releaseTestSource(t *testing.T, events []map[string]any) *GHArchiveSource {
+	t.Helper()
+	hour := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	archive := hour.Format(gharchiveArchiveLayout)
+	srv := fakeArchiveServer(t, map[string][]byte{archive: gzipNDJSON(t, events)})
+	t.Cleanup(srv.Close)
+
+	src := newTestSource(t, srv.URL, hour.Add(2*time.Hour), NewMemoryCursorStore(), nil, GHArchiveHooks{})
+	if err := src.ProcessArchive(context.Background(), archive); err != nil {
+		t.Fatalf("seed ProcessArchive: %v", err)
+	}
+	return src
+}
+
+func TestClassifyReleaseTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   ReleaseKind
+		wantOK bool
+	}{
+		{"v0.1.0", ReleaseKindInitialVersion, true},
+		{"0.0.1", ReleaseKindInitialVersion, true},
+		{"v1.0.0", ReleaseKindGraduation, true},
+		{"1.0", ReleaseKindGraduation, true},
+		{"cli/v1.0.0", ReleaseKindGraduation, true},
+		{"v2.0.0", ReleaseKindMajor, true},
+		{"V10.0", ReleaseKindMajor, true},
+		{"v1.0.0-rc1", "", false},
+		{"v2.0.0+build5", "", false},
+		{"v1.2.3", "", false},
+		{"v0.2.0", "", false},
+		{"nightly", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := classifyReleaseTag(tt.tag)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("classifyReleaseTag(%q) = (%q, %v), want (%q, %v)", tt.tag, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestProcessArchive_DetectsReleaseSignals — ReleaseEvent payloads are
+// inspected even though the default type filter drops ReleaseEvent
+// from the activity counts. Prereleases and plain patch releases do
+// not produce signals.
+func TestProcessArchive_DetectsReleaseSignals(t *testing.T) {
+	src := releaseTestSource(t, []map[string]any{
+		releaseEvent("a/graduate", "v1.0.0", false),
+		releaseEvent("a/rc", "v1.0.0-rc1", true),
+		releaseEvent("a/patch", "v1.4.2", false),
+		releaseEvent("a/major", "v3.0.0", false),
+	})
+
+	got := src.PendingReleaseSignals()
+	if len(got) != 2 {
+		t.Fatalf("got %d signals, want 2: %+v", len(got), got)
+	}
+	if got[0].RepoName != "a/graduate" || got[0].Kind != ReleaseKindGraduation || got[0].Tag != "v1.0.0" {
+		t.Errorf("got[0] = %+v, want a/graduate graduation v1.0.0", got[0])
+	}
+	if got[1].RepoName != "a/major" || got[1].Kind != ReleaseKindMajor {
+		t.Errorf("got[1] = %+v, want a/major major", got[1])
+	}
+	if n := src.TrackedRepoCount(); n != 0 {
+		t.Errorf("TrackedRepoCount = %d, want 0 (ReleaseEvent not in type filter)", n)
+	}
+
+	src.AckReleaseSignal("a/graduate", "v1.0.0")
+	if got := src.PendingReleaseSignals(); len(got) != 1 || got[0].RepoName != "a/major" {
+		t.Errorf("after ack = %+v, want only a/major", got)
+	}
+}
+
+// TestDiscoverFromGHArchive_ReleaseCandidateBypassesFloor — a repo
+// below the activity floor is still promoted when it publishes a 1.0,
+// with the tag recorded as provenance, and is not re-emitted on the
+// next cycle.
+func TestDiscoverFromGHArchive_ReleaseCandidateBypassesFloor(t *testing.T) {
+	events := []map[string]any{releaseEvent("a/quiet", "v1.0.0", false)}
+	for i := 0; i < 30; i++ {
+		events = append(events, map[string]any{"type": "WatchEvent", "repo": map[string]any{"name": "a/busy"}})
+	}
+	src := releaseTestSource(t, events)
+
+	rest := fakeRESTServer(t, map[string]repoMetricsResponse{
+		"a/busy":  repoEntry("a/busy", 100),
+		"a/quiet": repoEntry("a/quiet", 10),
+	})
+	t.Cleanup(rest.Close)
+
+	d := newPipelineDiscoverer(t, rest.URL, Config{
+		Sources: SourcesConfig{GHArchive: GHArchiveSourceConfig{
+			Enabled: true, TopN: 10, ActivityFloor: 20, ReleaseSignals: true,
+		}},
+		AutoTrackThreshold: 1000,
+	})
+	d.SetGHArchiveSource(src)
+
+	result, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverFromGHArchive: %v", err)
+	}
+	if result.TotalFound != 2 || result.ReleaseCandidates != 1 {
+		t.Errorf("TotalFound = %d, ReleaseCandidates = %d, want 2 and 1", result.TotalFound, result.ReleaseCandidates)
+	}
+	var quiet *DiscoveredRepo
+	for i := range result.Repos {
+		if result.Repos[i].FullName == "a/quiet" {
+			quiet = &result.Repos[i]
+		}
+	}
+	if quiet == nil {
+		t.Fatalf("a/quiet not promoted: %+v", result.Repos)
+	}
+	if quiet.ReleaseTag != "v1.0.0" || quiet.ReleaseKind != ReleaseKindGraduation {
+		t.Errorf("provenance = (%q, %q), want (v1.0.0, graduation)", quiet.ReleaseTag, quiet.ReleaseKind)
+	}
+
+	again, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("second DiscoverFromGHArchive: %v", err)
+	}
+	if again.ReleaseCandidates != 0 {
+		t.Errorf("second cycle ReleaseCandidates = %d, want 0 (signal acked)", again.ReleaseCandidates)
+	}
+}
+
+// TestDiscoverFromGHArchive_RejectedReleaseCandidateNotRetried — a
+// release-only repo the filters reject (here the min_stars gate) is
+// acked, so the next cycle does not hydrate it again; one whose
+// hydration failed stays pending and is retried.
+func TestDiscoverFromGHArchive_RejectedReleaseCandidateNotRetried(t *testing.T) {
+	src := releaseTestSource(t, []map[string]any{
+		releaseEvent("a/gone", "v1.0.0", false),
+		releaseEvent("a/small", "v2.0.0", false),
+	})
+	rest := fakeRESTServer(t, map[string]repoMetricsResponse{"a/small": repoEntry("a/small", 10)})
+	t.Cleanup(rest.Close)
+
+	d := newPipelineDiscoverer(t, rest.URL, Config{
+		Sources: SourcesConfig{GHArchive: GHArchiveSourceConfig{
+			Enabled: true, TopN: 10, ActivityFloor: 20, MinStarsGate: 50, ReleaseSignals: true,
+		}},
+	})
+	d.SetGHArchiveSource(src)
+
+	first, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverFromGHArchive: %v", err)
+	}
+	if first.TotalFound != 2 || len(first.Repos) != 0 {
+		t.Errorf("first cycle TotalFound = %d, Repos = %d, want 2 and 0", first.TotalFound, len(first.Repos))
+	}
+	if pending := src.PendingReleaseSignals(); len(pending) != 1 || pending[0].RepoName != "a/gone" {
+		t.Errorf("pending after first cycle = %+v, want only a/gone (hydration failed)", pending)
+	}
+
+	again, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("second DiscoverFromGHArchive: %v", err)
+	}
+	if again.TotalFound != 1 {
+		t.Errorf("second cycle TotalFound = %d, want 1 (a/gone retried, a/small acked)", again.TotalFound)
+	}
+}
+
+// TestDiscoverFromGHArchive_MaxReleaseCandidates — release-only
+// candidates past the per-cycle cap stay pending and are admitted on a
+// later cycle.
+func TestDiscoverFromGHArchive_MaxReleaseCandidates(t *testing.T) {
+	src := releaseTestSource(t, []map[string]any{
+		releaseEvent("a/one", "v1.0.0", false),
+		releaseEvent("a/two", "v1.0.0", false),
+		releaseEvent("a/three", "v1.0.0", false),
+	})
+	rest := fakeRESTServer(t, map[string]repoMetricsResponse{
+		"a/one":   repoEntry("a/one", 100),
+		"a/two":   repoEntry("a/two", 100),
+		"a/three": repoEntry("a/three", 100),
+	})
+	t.Cleanup(rest.Close)
+
+	d := newPipelineDiscoverer(t, rest.URL, Config{
+		Sources: SourcesConfig{GHArchive: GHArchiveSourceConfig{
+			Enabled: true, TopN: 10, ActivityFloor: 20, ReleaseSignals: true, MaxReleaseCandidates: 2,
+		}},
+	})
+	d.SetGHArchiveSource(src)
+
+	first, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverFromGHArchive: %v", err)
+	}
+	if first.ReleaseCandidates != 2 {
+		t.Errorf("first cycle ReleaseCandidates = %d, want 2", first.ReleaseCandidates)
+	}
+	if pending := src.PendingReleaseSignals(); len(pending) != 1 || pending[0].RepoName != "a/two" {
+		t.Errorf("pending after first cycle = %+v, want a/two", pending)
+	}
+
+	again, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("second DiscoverFromGHArchive: %v", err)
+	}
+	if again.ReleaseCandidates != 1 || len(again.Repos) != 1 || again.Repos[0].FullName != "a/two" {
+		t.Errorf("second cycle = %d release candidates, repos %+v; want a/two", again.ReleaseCandidates, again.Repos)
+	}
+}
+
+// TestDiscoverFromGHArchive_ReleaseSignalsOff — without the flag the
+// release-only repo stays below the floor.
+func TestDiscoverFromGHArchive_ReleaseSignalsOff(t *testing.T) {
+	src := releaseTestSource(t, []map[string]any{releaseEvent("a/quiet", "v1.0.0", false)})
+	rest := fakeRESTServer(t, map[string]repoMetricsResponse{"a/quiet": repoEntry("a/quiet", 10)})
+	t.Cleanup(rest.Close)
+
+	d := newPipelineDiscoverer(t, rest.URL, Config{
+		Sources: SourcesConfig{GHArchive: GHArchiveSourceConfig{
+			Enabled: true, TopN: 10, ActivityFloor: 20,
+		}},
+	})
+	d.SetGHArchiveSource(src)
+
+	result, err := d.DiscoverFromGHArchive(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverFromGHArchive: %v", err)
+	}
+	if result.TotalFound != 0 {
+		t.Errorf("TotalFound = %d, want 0", result.TotalFound)
+	}
+}
+
+// TestDiscoverFromGHArchive_TrackedReleaseFiresOnce — a tracked repo's
+// major release fires OnTrackedRelease once and is never hydrated.
+func TestDiscoverFromGHArchive_TrackedReleaseFiresOnce(t *testing.T) {
+	src := releaseTestSource(t, []map[string]any{releaseEvent("a/tracked", "v2.0.0", false)})
+	rest := fakeRESTServer(t, map[string]repoMetricsResponse{})
+	t.Cleanup(rest.Close)
+
+	d := newPipelineDiscoverer(t, rest.URL, Config{
+		Sources: SourcesConfig{GHArchive: GHArchiveSourceConfig{
+			Enabled: true, ReleaseSignals: true,
+		}},
+	})
+	d.SetGHArchiveSource(src)
+	d.store.SetRepoState("a/tracked", state.RepoState{Owner: "a", Name: "tracked", LastCollected: time.Now()})
+
+	var fired []GHArchiveReleaseSignal
+	d.SetGHArchivePipelineHooks(GHArchivePipelineHooks{
+		OnTrackedRelease: func(sig GHArchiveReleaseSignal) { fired = append(fired, sig) },
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := d.DiscoverFromGHArchive(context.Background()); err != nil {
+			t.Fatalf("DiscoverFromGHArchive: %v", err)
+		}
+	}
+	if len(fired) != 1 {
+		t.Fatalf("OnTrackedRelease fired %d times, want 1", len(fired))
+	}
+	if fired[0].RepoName != "a/tracked" || fired[0].Kind != ReleaseKindMajor || fired[0].Tag != "v2.0.0" {
+		t.Errorf("fired = %+v, want a/tracked major v2.0.0", fired[0])
+	}
+}
@@ -227,42 +227,6 @@ func TestProcessArchive_HonorsRetryAfterSeconds(t *testing.T) {
 	}
 }
 
-// TestParseRetryAfter exercises the header parser directly.
-func TestParseRetryAfter(t *testing.T) {
-	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
-	cases := []struct {
-		name   string
-		header string
-		want   time.Duration
-	}{
-		{"empty", "", 0},
-		{"whitespace", "   ", 0},
-		{"zero seconds", "0", 0},
-		{"negative seconds", "-5", 0},
-		{"positive seconds", "30", 30 * time.Second},
-		{"padded seconds", "  120  ", 120 * time.Second},
-		{"future http-date", now.Add(45 * time.Second).UTC().Format(http.TimeFormat), 45 * time.Second},
-		{"past http-date", now.Add(-1 * time.Hour).UTC().Format(http.TimeFormat), 0},
-		{"garbage", "definitely not a number or date", 0},
-	}
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			got := parseRetryAfter(tc.header, now)
-			// HTTP date parsing has 1-second granularity; allow 1s slack
-			// in either direction for the future-date case.
-			if tc.name == "future http-date" {
-				if got < tc.want-time.Second || got > tc.want+time.Second {
-					t.Errorf("parseRetryAfter(%q) = %v, want ~%v", tc.header, got, tc.want)
-				}
-				return
-			}
-			if got != tc.want {
-				t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.header, got, tc.want)
-			}
-		})
-	}
-}
-
 // TestRun_SkipsPoisonArchiveAfterThreshold covers ISI-960 M3: when an
 // archive permanently fails (here: server always returns 503), Run
 // tracks consecutive Run-cycle failures and after PoisonFailureThreshold
@@ -1,21 +1,14 @@
 package discovery
 
 import (
-	"bufio"
-	"compress/gzip"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"math/rand"
 	"net/http"
-	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/hrexed/github-radar/internal/gharchive"
 	"github.com/hrexed/github-radar/internal/logging"
 )
 
@@ -37,7 +30,7 @@ import (
 // Default knobs.
 const (
 	// DefaultGHArchiveBaseURL is the canonical archive host.
-	DefaultGHArchiveBaseURL = "https://data.gharchive.org"
+	DefaultGHArchiveBaseURL = gharchive.DefaultBaseURL
 
 	// DefaultGHArchiveWindow is the sliding window over which per-repo
 	// event counts are retained. 24h matches Stage C acceptance per
@@ -47,19 +40,19 @@ const (
 	// DefaultGHArchiveHTTPTimeout is the per-archive download timeout.
 	// Each archive is ~60-90 MB compressed; 60s leaves headroom on
 	// slow links.
-	DefaultGHArchiveHTTPTimeout = 60 * time.Second
+	DefaultGHArchiveHTTPTimeout = gharchive.DefaultHTTPTimeout
 
 	// DefaultGHArchiveMaxRetries caps transient-error retries per
 	// archive. Three attempts with exponential backoff covers the
 	// observed gharchive S3 origin blip rate.
-	DefaultGHArchiveMaxRetries = 3
+	DefaultGHArchiveMaxRetries = gharchive.DefaultMaxRetries
 
 	// DefaultGHArchiveInitialBackoff is the first retry delay; each
 	// retry doubles up to GHArchiveMaxBackoff.
-	DefaultGHArchiveInitialBackoff = 1 * time.Second
+	DefaultGHArchiveInitialBackoff = gharchive.DefaultInitialBackoff
 
 	// GHArchiveMaxBackoff is the cap on per-retry backoff.
-	GHArchiveMaxBackoff = 30 * time.Second
+	GHArchiveMaxBackoff = gharchive.MaxBackoff
 
 	// GHArchivePublishLag is the safety margin applied past the end of
 	// each hour before its archive is considered ready for fetch.
@@ -68,7 +61,7 @@ const (
 	// the implicit +1h (an archive at hour H covers events during
 	// [H, H+1h) so isn't published until at least H+1h), an archive
 	// is ready when `now >= archiveHour + 1h + GHArchivePublishLag`.
-	GHArchivePublishLag = 30 * time.Minute
+	GHArchivePublishLag = gharchive.PublishLag
 
 	// DefaultGHArchivePoisonFailureThreshold caps how many consecutive
 	// Run() cycles a single archive is allowed to fail before Run skips
@@ -76,10 +69,14 @@ const (
 	// to 5 in [ISI-960](/ISI/issues/ISI-960): high enough to ride out a
 	// gharchive S3 origin outage, low enough to bound the stall to a
 	// few hours under default heartbeat cadence.
-	DefaultGHArchivePoisonFailureThreshold = 5
+	DefaultGHArchivePoisonFailureThreshold = gharchive.DefaultPoisonFailureThreshold
+
+	// DefaultGHArchiveConcurrency is how many archives Run downloads and
+	// decodes at once while catching up.
+	DefaultGHArchiveConcurrency = gharchive.DefaultConcurrency
 
 	// gharchiveArchiveLayout is the gharchive filename / cursor format.
-	gharchiveArchiveLayout = "2006-01-02-15"
+	gharchiveArchiveLayout = gharchive.ArchiveLayout
 )
 
 // DefaultGHArchiveEventTypes is the canonical filter list per
@@ -125,6 +122,23 @@ type GHArchiveConfig struct {
 	// Zero falls back to DefaultGHArchivePoisonFailureThreshold.
 	// Negative is clamped to 1 (skip after the first failure).
 	PoisonFailureThreshold int
+
+	// BotPatterns lists case-insensitive substrings of actor logins
+	// whose events are dropped before aggregation, so automated
+	// traffic neither inflates event counts nor distinct-actor
+	// counts. Empty falls back to DefaultGHArchiveBotPatterns.
+	BotPatterns []string
+
+	// LocalDir, when set, is checked for `<archive>.json.gz` before
+	// BaseURL is hit. Archives missing locally are downloaded as
+	// usual. Used by backfill to replay a pre-downloaded range.
+	LocalDir string
+
+	// Concurrency bounds how many archives are downloaded and decoded
+	// at once when Run (or Backfill) has several hours to process.
+	// Hours are still folded into the window in order. Zero falls back
+	// to DefaultGHArchiveConcurrency.
+	Concurrency int
 }
 
 // withDefaults returns a copy of cfg with empty fields populated.
@@ -160,6 +174,12 @@ func (c GHArchiveConfig) withDefaults() GHArchiveConfig {
 	if c.PoisonFailureThreshold < 1 {
 		c.PoisonFailureThreshold = 1
 	}
+	if len(c.BotPatterns) == 0 {
+		c.BotPatterns = DefaultGHArchiveBotPatterns
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = DefaultGHArchiveConcurrency
+	}
 	return c
 }
 
@@ -168,41 +188,15 @@ func (c GHArchiveConfig) withDefaults() GHArchiveConfig {
 // rollup store is configured) snapshotted — never on download start.
 // Mid-archive crash → re-process from scratch. Aggregation is
 // idempotent on (repo, hour-bucket) so reprocessing is safe.
-type GHArchiveCursor struct {
-	// LastProcessedArchive is in YYYY-MM-DD-HH format (UTC). Empty
-	// when no archive has ever been processed.
-	LastProcessedArchive string
-
-	// CompletedAt is the wall-clock time the archive finished
-	// processing.
-	CompletedAt time.Time
-}
-
-// IsZero reports whether the cursor is empty (no archive processed yet).
-func (c GHArchiveCursor) IsZero() bool {
-	return c.LastProcessedArchive == "" && c.CompletedAt.IsZero()
-}
-
-// Hour returns the parsed UTC hour represented by the cursor, or zero
-// time when the cursor is empty / malformed.
-func (c GHArchiveCursor) Hour() time.Time {
-	if c.LastProcessedArchive == "" {
-		return time.Time{}
-	}
-	t, err := time.ParseInLocation(gharchiveArchiveLayout, c.LastProcessedArchive, time.UTC)
-	if err != nil {
-		return time.Time{}
-	}
-	return t
-}
+//
+// The cursor belongs to the ingestion engine and is shared by every
+// consumer registered on it (see gharchive.Engine).
+type GHArchiveCursor = gharchive.Cursor
 
 // GHArchiveCursorStore persists the discovery cursor across restarts.
 // The default binding (NewMetadataCursorStore) maps to a single key in
 // the SQLite metadata table; tests pass an in-memory implementation.
-type GHArchiveCursorStore interface {
-	GetCursor(ctx context.Context) (GHArchiveCursor, error)
-	SetCursor(ctx context.Context, c GHArchiveCursor) error
-}
+type GHArchiveCursorStore = gharchive.CursorStore
 
 // GHArchiveHourAggregate is one (repo, hour-bucket) cell of the rollup
 // snapshot. RollupStore implementations persist one record per cell at
@@ -237,8 +231,8 @@ type GHArchiveRollupStore interface {
 //     the type filter for tracked repos (here: all repos seen in the
 //     firehose). The map is non-nil but may be empty when no events
 //     survived the filter; total kept = sum of values. `discarded`
-//     counts events that didn't match the type filter or had no usable
-//     repo name. The map is owned by the caller after the hook returns
+//     counts events that didn't match the type filter, had no usable
+//     repo name, or came from an actor matching BotPatterns. The map is owned by the caller after the hook returns
 //     (no further mutation by the collector).
 //   - OnLagSeconds fires once per archive on entry; lag is wall-clock
 //     minus archive-hour, useful for dashboards to spot cursor stall.
@@ -257,38 +251,59 @@ type GHArchiveHooks struct {
 // GHArchiveSource is the gharchive discovery collector. It is safe for
 // concurrent reads against the in-memory aggregate via a mutex; the
 // archive-processing loop assumes a single writer goroutine.
+//
+// Downloading, decoding, the cursor and the archive-level hooks live in
+// a gharchive.Engine; the source is one gharchive.Consumer registered on
+// it. Other consumers (the tracked-repo metrics accumulator) register on
+// the same engine via Engine() and see every archive from the same
+// single decode pass.
 type GHArchiveSource struct {
 	cfg    GHArchiveConfig
-	client *http.Client
-	cursor GHArchiveCursorStore
+	engine *gharchive.Engine
 	rollup GHArchiveRollupStore
 	hooks  GHArchiveHooks
 
-	// nowFn is a clock indirection so tests can pin "current" time
-	// without time.Now drift.
-	nowFn func() time.Time
-
-	// jitterFn produces a random duration in [0, max). Indirected so
-	// tests can pin retry timing.
-	jitterFn func(max time.Duration) time.Duration
-
 	// eventTypes is the indexed filter set built from cfg.EventTypes.
 	eventTypes map[string]bool
 
+	// botPatterns is cfg.BotPatterns lower-cased for isBotActor.
+	botPatterns []string
+
 	mu      sync.RWMutex
 	buckets map[string]*ringBucket
 
-	// poisonMu guards consecutiveFailures. Held only briefly during Run
-	// — Run is the single writer goroutine for ProcessArchive but the
-	// hooks fire under callers' goroutines, so a dedicated mutex keeps
-	// the failure tracker thread-safe under -race.
-	poisonMu            sync.Mutex
-	consecutiveFailures map[string]int
+	// releases holds the latest release inflection point per repo
+	// inside the window (see gharchive_release.go). Guarded by mu.
+	releases map[string]*GHArchiveReleaseSignal
+
+	// archivedThrough is the latest hour committed from an archive;
+	// live events at or before it are already counted (see
+	// gharchive_live.go). Guarded by mu.
+	archivedThrough time.Time
+}
+
+// gharchiveHourScratch accumulates one archive before FinishHour folds
+// it into the window under the write lock. The engine may fill several
+// of these at once from different goroutines; each is only touched by
+// the goroutine decoding its archive until FinishHour.
+type gharchiveHourScratch struct {
+	src    *GHArchiveSource
+	bucket time.Time
+	counts map[string]int // repo -> events kept this archive
+	types  map[string]map[string]int
+	actors map[string]*gharchiveHourActors
+	// keptByType is the flat per-event-type tally across all repos in
+	// this archive, surfaced through OnEventsProcessed so Story 5
+	// observability ([ISI-955](/ISI/issues/ISI-955)) can attribute the
+	// counter without re-scanning the firehose.
+	keptByType map[string]int64
+	releases   []GHArchiveReleaseSignal
+	discarded  int64
 }
 
-// NewGHArchiveSource constructs a collector. cursorStore must be
-// non-nil; rollupStore is optional (nil → no rollup writes); hooks
-// fields are individually optional.
+// NewGHArchiveSource constructs a collector with its own ingestion
+// engine. cursorStore must be non-nil; rollupStore is optional (nil →
+// no rollup writes); hooks fields are individually optional.
 func NewGHArchiveSource(
 	cfg GHArchiveConfig,
 	cursorStore GHArchiveCursorStore,
@@ -305,51 +320,92 @@ func NewGHArchiveSource(
 	for _, t := range cfg.EventTypes {
 		idx[t] = true
 	}
+	bots := make([]string, 0, len(cfg.BotPatterns))
+	for _, p := range cfg.BotPatterns {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			bots = append(bots, p)
+		}
+	}
+
+	engine := gharchive.NewEngine(gharchive.EngineConfig{
+		BaseURL:                cfg.BaseURL,
+		HTTPTimeout:            cfg.HTTPTimeout,
+		MaxRetries:             cfg.MaxRetries,
+		InitialBackoff:         cfg.InitialBackoff,
+		PoisonFailureThreshold: cfg.PoisonFailureThreshold,
+		ColdStartWindow:        cfg.Window,
+		LocalDir:               cfg.LocalDir,
+		Concurrency:            cfg.Concurrency,
+	}, cursorStore, gharchive.Hooks{
+		OnLagSeconds:      hooks.OnLagSeconds,
+		OnArchiveStart:    hooks.OnArchiveStart,
+		OnArchiveComplete: hooks.OnArchiveComplete,
+		OnArchiveError:    hooks.OnArchiveError,
+	})
 
-	return &GHArchiveSource{
-		cfg:                 cfg,
-		client:              &http.Client{Timeout: cfg.HTTPTimeout},
-		cursor:              cursorStore,
-		rollup:              rollupStore,
-		hooks:               hooks,
-		nowFn:               func() time.Time { return time.Now().UTC() },
-		jitterFn:            func(max time.Duration) time.Duration { return time.Duration(rand.Int63n(int64(max + 1))) }, //nolint:gosec
-		eventTypes:          idx,
-		buckets:             make(map[string]*ringBucket),
-		consecutiveFailures: make(map[string]int),
+	s := &GHArchiveSource{
+		cfg:         cfg,
+		engine:      engine,
+		rollup:      rollupStore,
+		hooks:       hooks,
+		eventTypes:  idx,
+		botPatterns: bots,
+		buckets:     make(map[string]*ringBucket),
+		releases:    make(map[string]*GHArchiveReleaseSignal),
 	}
+	engine.Register(s)
+	return s
+}
+
+// Engine returns the ingestion engine feeding this source. Register
+// further consumers on it to share the download, decode and cursor.
+func (s *GHArchiveSource) Engine() *gharchive.Engine {
+	return s.engine
 }
 
 // SetClock overrides the wall-clock used to compute archive freshness.
 // Tests pin this to a deterministic value; production callers don't
 // need it.
 func (s *GHArchiveSource) SetClock(now func() time.Time) {
-	if now != nil {
-		s.nowFn = now
-	}
+	s.engine.SetClock(now)
 }
 
 // SetHTTPClient overrides the HTTP client. Tests pass a client wired
 // to httptest.Server; production code can pre-build a client with
 // custom transport (e.g. with proxy settings) and inject it here.
 func (s *GHArchiveSource) SetHTTPClient(c *http.Client) {
-	if c != nil {
-		s.client = c
-	}
+	s.engine.SetHTTPClient(c)
 }
 
 // SetJitter overrides the retry-jitter function. Tests use a constant
 // 0 to make backoff deterministic.
 func (s *GHArchiveSource) SetJitter(jit func(max time.Duration) time.Duration) {
-	if jit != nil {
-		s.jitterFn = jit
+	s.engine.SetJitter(jit)
+}
+
+// SetArchiveCache routes archive downloads through c, the on-disk
+// archive cache shared with the metrics fallback collector. nil
+// disables caching.
+func (s *GHArchiveSource) SetArchiveCache(c *gharchive.Cache) {
+	s.engine.SetArchiveCache(c)
+}
+
+// SetBackpressureGate drops Run to one archive at a time while g is
+// paused, so a backed-up classifier or a drained rate limit is not met
+// with a burst of concurrent downloads. nil removes the throttle.
+func (s *GHArchiveSource) SetBackpressureGate(g *GHArchiveBackpressureGate) {
+	if g == nil {
+		s.engine.SetThrottle(nil)
+		return
 	}
+	s.engine.SetThrottle(g.IsPaused)
 }
 
 // Run advances the cursor through every archive that is at least
 // GHArchivePublishLag old, in chronological order. Returns when ctx is
 // cancelled or no further archives are available. Errors are logged
-// per-archive; one bad archive does not fail the whole run.
+// per-archive; one bad archive does not fail the whole run, and an
+// archive failing PoisonFailureThreshold consecutive runs is skipped.
 //
 // The starting point is:
 //   - cursor's LastProcessedArchive + 1h, when the cursor is set
@@ -359,427 +415,135 @@ func (s *GHArchiveSource) SetJitter(jit func(max time.Duration) time.Duration) {
 // optional rollup store gets the hour snapshot, and only then does
 // the cursor advance — so a crash mid-archive replays that archive.
 func (s *GHArchiveSource) Run(ctx context.Context) error {
-	cursor, err := s.cursor.GetCursor(ctx)
-	if err != nil {
-		return fmt.Errorf("loading gharchive cursor: %w", err)
-	}
-
-	startHour := s.nextStartHour(cursor)
-	// An archive at hour H is "ready" iff now >= H + 1h + lag, so the
-	// latest processable archive is at hour floor((now - 1h - lag)/1h).
-	endHour := s.nowFn().UTC().Add(-time.Hour - GHArchivePublishLag).Truncate(time.Hour)
-
-	if startHour.After(endHour) {
-		// Cursor already at the leading edge; nothing to do.
-		return nil
-	}
-
-	for h := startHour; !h.After(endHour); h = h.Add(time.Hour) {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-		archive := h.Format(gharchiveArchiveLayout)
-		if err := s.ProcessArchive(ctx, archive); err != nil {
-			// ProcessArchive logs; continue with the next archive
-			// rather than stalling the whole run on one bad hour.
-			// Cursor advance for the failed archive depends on
-			// the poison-skip path below.
-			logging.Warn("gharchive_source: archive failed; continuing",
-				"archive", archive, "error", err)
-			s.handleArchiveFailure(ctx, archive, err)
-			continue
-		}
-		// Success: clear any prior failure tally for this archive so
-		// transient blips never accumulate toward the poison threshold.
-		s.poisonMu.Lock()
-		delete(s.consecutiveFailures, archive)
-		s.poisonMu.Unlock()
-	}
+	return s.engine.Run(ctx)
+}
 
-	return nil
+// ProcessArchive downloads one archive, aggregates filtered events,
+// snapshots the rollup, and advances the cursor. Every consumer
+// registered on the engine sees the archive. Errors are returned after
+// the retry budget is exhausted. Idempotent on (repo, hour) so safe to
+// call repeatedly for the same archive.
+func (s *GHArchiveSource) ProcessArchive(ctx context.Context, archive string) error {
+	return s.engine.ProcessArchive(ctx, archive)
 }
 
-// handleArchiveFailure tracks consecutive Run-cycle failures per archive
-// and skips past a poison archive once the threshold is reached. Without
-// the skip, a permanently undecodable archive (truncated upstream,
-// persistent gzip CRC error, persistent 5xx) would stall the cursor at
-// h-1 forever and the firehose would never catch up to today.
-//
-// Skip semantics: advance the cursor as if the archive succeeded
-// (LastProcessedArchive = archive) and emit OnArchiveError with a
-// distinguishing "poison archive" message + attempt=0 so dashboards can
-// count skips separately from in-fetch errors.
-func (s *GHArchiveSource) handleArchiveFailure(ctx context.Context, archive string, cause error) {
-	s.poisonMu.Lock()
-	s.consecutiveFailures[archive]++
-	failures := s.consecutiveFailures[archive]
-	s.poisonMu.Unlock()
-
-	if failures < s.cfg.PoisonFailureThreshold {
-		return
+// BeginHour implements gharchive.Consumer.
+func (s *GHArchiveSource) BeginHour(hour time.Time) gharchive.HourConsumer {
+	return &gharchiveHourScratch{
+		src:        s,
+		bucket:     hour.Truncate(time.Hour).UTC(),
+		counts:     make(map[string]int),
+		types:      make(map[string]map[string]int),
+		actors:     make(map[string]*gharchiveHourActors),
+		keptByType: make(map[string]int64, len(s.eventTypes)),
 	}
+}
 
-	skipErr := fmt.Errorf("poison archive: skipping after %d consecutive failures: %w", failures, cause)
-	logging.Warn("gharchive_source: skipping poison archive",
-		"archive", archive,
-		"consecutive_failures", failures,
-		"threshold", s.cfg.PoisonFailureThreshold,
-		"cause", cause)
+// ConsumeEvent implements gharchive.HourConsumer: it filters events to
+// the configured type set and tallies them per repo for the hour.
+func (h *gharchiveHourScratch) ConsumeEvent(evt *gharchive.Event) {
+	s := h.src
 
-	if s.hooks.OnArchiveError != nil {
-		s.hooks.OnArchiveError(archive, 0, skipErr)
+	// Release detection runs ahead of the type filter so a
+	// ReleaseEvent surfaces its tag even when the filter drops it
+	// from the activity counts. Only ReleaseEvent lines pay the
+	// second payload decode.
+	if evt.Type == gharchiveReleaseEventType {
+		if sig, ok := parseReleaseEvent(evt.Raw(), h.bucket); ok {
+			h.releases = append(h.releases, sig)
+		}
 	}
 
-	if err := s.cursor.SetCursor(ctx, GHArchiveCursor{
-		LastProcessedArchive: archive,
-		CompletedAt:          s.nowFn().UTC(),
-	}); err != nil {
-		// Cursor write failed: leave the failure count in place so
-		// the next cycle retries the skip. Do not delete the entry.
-		logging.Warn("gharchive_source: failed to advance cursor past poison archive",
-			"archive", archive, "error", err)
+	if evt.Repo.Name == "" || !s.eventTypes[evt.Type] || isBotActor(evt.Actor.Login, s.botPatterns) {
+		h.discarded++
 		return
 	}
 
-	s.poisonMu.Lock()
-	delete(s.consecutiveFailures, archive)
-	s.poisonMu.Unlock()
-}
-
-// nextStartHour returns the first hour Run should attempt to process.
-func (s *GHArchiveSource) nextStartHour(cursor GHArchiveCursor) time.Time {
-	if !cursor.IsZero() {
-		return cursor.Hour().Add(time.Hour)
+	h.counts[evt.Repo.Name]++
+	typeMap := h.types[evt.Repo.Name]
+	if typeMap == nil {
+		typeMap = make(map[string]int, len(s.eventTypes))
+		h.types[evt.Repo.Name] = typeMap
 	}
-	return s.nowFn().UTC().Add(-s.cfg.Window).Truncate(time.Hour)
-}
+	typeMap[evt.Type]++
+	h.keptByType[evt.Type]++
 
-// ProcessArchive downloads one archive, aggregates filtered events,
-// snapshots the rollup, and advances the cursor. Errors are returned
-// after the retry budget is exhausted. Idempotent on (repo, hour) so
-// safe to call repeatedly for the same archive.
-func (s *GHArchiveSource) ProcessArchive(ctx context.Context, archive string) error {
-	hour, err := time.ParseInLocation(gharchiveArchiveLayout, archive, time.UTC)
-	if err != nil {
-		return fmt.Errorf("parsing archive name %q: %w", archive, err)
+	if evt.Actor.Login != "" {
+		actors := h.actors[evt.Repo.Name]
+		if actors == nil {
+			actors = &gharchiveHourActors{}
+			h.actors[evt.Repo.Name] = actors
+		}
+		actors.observe(evt.Type, hashActor(evt.Actor.Login))
 	}
+}
 
-	if s.hooks.OnArchiveStart != nil {
-		s.hooks.OnArchiveStart(archive)
-	}
-	if s.hooks.OnLagSeconds != nil {
-		lag := s.nowFn().UTC().Sub(hour).Seconds()
-		s.hooks.OnLagSeconds(lag)
-	}
+// FinishHour implements gharchive.HourConsumer: it folds the hour into
+// the sliding window, fires OnEventsProcessed, and writes the rollup. A
+// rollup error keeps the engine from advancing the cursor.
+func (h *gharchiveHourScratch) FinishHour(ctx context.Context, sum gharchive.HourSummary) error {
+	s := h.src
+	// Malformed lines never reached ConsumeEvent; count them here.
+	discarded := h.discarded + sum.Malformed
 
-	start := time.Now()
-	body, err := s.fetchArchiveWithRetry(ctx, archive)
-	if err != nil {
-		return err
-	}
-	defer body.Close()
-
-	keptByType, discarded, hourAggregates, err := s.consume(ctx, hour, body)
-	if err != nil {
-		return fmt.Errorf("processing archive %s: %w", archive, err)
-	}
+	aggregates := s.commitHour(*h)
 
 	if s.hooks.OnEventsProcessed != nil {
-		s.hooks.OnEventsProcessed(archive, keptByType, discarded)
+		s.hooks.OnEventsProcessed(sum.Archive, h.keptByType, discarded)
 	}
 
-	if s.rollup != nil && len(hourAggregates) > 0 {
-		if err := s.rollup.WriteHourRollup(ctx, archive, hourAggregates); err != nil {
+	if s.rollup != nil && len(aggregates) > 0 {
+		if err := s.rollup.WriteHourRollup(ctx, sum.Archive, aggregates); err != nil {
 			// Rollup write failure is fatal for cursor advance:
 			// the architect's gate is "advance only after full
 			// archive aggregation + rollup write succeeds".
-			return fmt.Errorf("writing rollup for %s: %w", archive, err)
+			return fmt.Errorf("writing rollup for %s: %w", sum.Archive, err)
 		}
 	}
 
-	if err := s.cursor.SetCursor(ctx, GHArchiveCursor{
-		LastProcessedArchive: archive,
-		CompletedAt:          s.nowFn().UTC(),
-	}); err != nil {
-		return fmt.Errorf("advancing cursor to %s: %w", archive, err)
-	}
-
-	if s.hooks.OnArchiveComplete != nil {
-		s.hooks.OnArchiveComplete(archive, time.Since(start))
-	}
-
 	var kept int64
-	for _, c := range keptByType {
+	for _, c := range h.keptByType {
 		kept += c
 	}
-	logging.Debug("gharchive_source: archive complete",
-		"archive", archive, "kept", kept, "discarded", discarded,
-		"unique_repos", len(hourAggregates))
+	logging.Debug("gharchive_source: archive aggregated",
+		"archive", sum.Archive, "kept", kept, "discarded", discarded,
+		"unique_repos", len(aggregates))
 	return nil
 }
 
-// fetchArchiveWithRetry hits the gharchive origin with bounded
-// exponential backoff for transient failures (network errors, 5xx, plus
-// the retry-friendly 4xx status codes 408 and 429). Other 4xx responses
-// (notably 404 for not-yet-published archives, 403 for permission
-// issues) are treated as terminal — there is no benefit to retrying.
-//
-// When the origin sends `Retry-After` on a retryable response, that
-// value overrides the computed exponential-backoff sleep for the next
-// attempt (capped at GHArchiveMaxBackoff). This honors RFC 9110/9111 so
-// we don't hammer a struggling S3 origin that already told us when to
-// come back.
-func (s *GHArchiveSource) fetchArchiveWithRetry(ctx context.Context, archive string) (io.ReadCloser, error) {
-	url := fmt.Sprintf("%s/%s.json.gz", s.cfg.BaseURL, archive)
-
-	var lastErr error
-	backoff := s.cfg.InitialBackoff
-
-	for attempt := 1; attempt <= s.cfg.MaxRetries; attempt++ {
-		// Reset per-attempt retry-after override; only set when the
-		// current response carries a parseable Retry-After header.
-		var retryAfter time.Duration
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("building request for %s: %w", url, err)
-		}
-
-		resp, err := s.client.Do(req)
-		switch {
-		case err != nil:
-			lastErr = err
-			if s.hooks.OnArchiveError != nil {
-				s.hooks.OnArchiveError(archive, attempt, err)
-			}
-		case resp.StatusCode >= 200 && resp.StatusCode < 300:
-			return resp.Body, nil
-		case isRetryableHTTPStatus(resp.StatusCode):
-			// 408 / 429 / 5xx: transient under burst load on the
-			// gharchive S3 origin. Honor Retry-After when present.
-			lastErr = fmt.Errorf("gharchive %s: %s", url, resp.Status)
-			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), s.nowFn())
-			resp.Body.Close()
-			if s.hooks.OnArchiveError != nil {
-				s.hooks.OnArchiveError(archive, attempt, lastErr)
-			}
-		case resp.StatusCode >= 400 && resp.StatusCode < 500:
-			// Other 4xx is terminal: 404 for not-yet-published
-			// archives and 403 for permission issues won't fix on
-			// retry, so fail fast and let Run decide whether to
-			// skip-past via the poison threshold.
-			lastErr = fmt.Errorf("gharchive %s: %s", url, resp.Status)
-			resp.Body.Close()
-			if s.hooks.OnArchiveError != nil {
-				s.hooks.OnArchiveError(archive, attempt, lastErr)
-			}
-			return nil, lastErr
-		default:
-			// Non-2xx, non-4xx (i.e. 1xx/3xx unexpected here, or
-			// any future >=600 oddity): treat as transient.
-			lastErr = fmt.Errorf("gharchive %s: %s", url, resp.Status)
-			resp.Body.Close()
-			if s.hooks.OnArchiveError != nil {
-				s.hooks.OnArchiveError(archive, attempt, lastErr)
-			}
-		}
-
-		if attempt == s.cfg.MaxRetries {
-			break
-		}
-
-		// Exponential backoff with jitter — capped at GHArchiveMaxBackoff.
-		// Retry-After (when present and positive) overrides the computed
-		// sleep so the origin's hint wins.
-		var sleep time.Duration
-		if retryAfter > 0 {
-			sleep = retryAfter
-		} else {
-			sleep = backoff + s.jitterFn(backoff/2)
-		}
-		if sleep > GHArchiveMaxBackoff {
-			sleep = GHArchiveMaxBackoff
-		}
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(sleep):
-		}
-		backoff *= 2
-		if backoff > GHArchiveMaxBackoff {
-			backoff = GHArchiveMaxBackoff
-		}
-	}
-
-	if lastErr == nil {
-		lastErr = errors.New("retries exhausted")
-	}
-	return nil, fmt.Errorf("gharchive fetch %s: %w", url, lastErr)
-}
-
-// isRetryableHTTPStatus returns true for HTTP responses that are worth
-// retrying with backoff: all 5xx plus the retry-friendly 4xx codes 408
-// (Request Timeout) and 429 (Too Many Requests). Both 408 and 429 are
-// retry-friendly per RFC 9110/9111 and are observed from gharchive's S3
-// origin under burst load.
-func isRetryableHTTPStatus(code int) bool {
-	switch code {
-	case http.StatusRequestTimeout, http.StatusTooManyRequests:
-		return true
-	}
-	return code >= 500 && code < 600
-}
-
-// parseRetryAfter interprets the Retry-After header per RFC 9110 §10.2.3.
-// Returns 0 (caller should fall back to exponential backoff) when the
-// header is empty, malformed, or names a past instant.
-//
-// Two forms are accepted:
-//   - delta-seconds: integer seconds to wait (e.g. "120")
-//   - HTTP-date: RFC 1123 / RFC 850 / asctime instant (e.g.
-//     "Fri, 31 Dec 1999 23:59:59 GMT")
-func parseRetryAfter(header string, now time.Time) time.Duration {
-	header = strings.TrimSpace(header)
-	if header == "" {
-		return 0
-	}
-	if secs, err := strconv.Atoi(header); err == nil {
-		if secs <= 0 {
-			return 0
-		}
-		return time.Duration(secs) * time.Second
-	}
-	if t, err := http.ParseTime(header); err == nil {
-		d := t.Sub(now)
-		if d <= 0 {
-			return 0
-		}
-		return d
-	}
-	return 0
-}
-
-// gharchiveEvent is the minimal envelope we need from each archive line.
-// gharchive uses NDJSON (newline-delimited JSON) inside the gzip; we
-// stream-decode rather than buffer the whole archive (~80MB compressed,
-// ~600MB uncompressed) into memory.
-type gharchiveEvent struct {
-	Type string `json:"type"`
-	Repo struct {
-		Name string `json:"name"`
-	} `json:"repo"`
-}
-
-// consume reads the gzipped archive, filters events to the configured
-// type set, aggregates per-repo counts into the in-memory ring under
-// the matching hour-bucket, and returns the per-repo snapshot for the
-// rollup store. Aggregation is idempotent on (repo, hour-bucket): the
-// ring overwrites the matching slot when called twice for the same
-// archive, so reprocessing yields the same final state.
+// commitHour aggregates one archive's per-repo counts into the
+// in-memory ring under the matching hour-bucket and returns the per-repo
+// snapshot for the rollup store. Aggregation is idempotent on (repo,
+// hour-bucket): the ring overwrites the matching slot when called twice
+// for the same archive, so reprocessing yields the same final state.
 //
 // Decode runs without the source lock held — ~80 MB compressed
 // archives take seconds to decode, and we don't want to block readers
-// like TopActiveRepos that whole time. The bucket update + slide
-// happens under the write lock at the end.
-func (s *GHArchiveSource) consume(ctx context.Context, hour time.Time, body io.Reader) (map[string]int64, int64, []GHArchiveHourAggregate, error) {
-	gz, err := gzip.NewReader(body)
-	if err != nil {
-		return nil, 0, nil, fmt.Errorf("gzip reader: %w", err)
-	}
-	defer gz.Close()
-
-	// Wrap the gzip stream in a 1 MiB buffered reader: ~600 MB
-	// uncompressed per archive, so larger reads cut syscall overhead.
-	br := bufio.NewReaderSize(gz, 1<<20)
-	// Scan one NDJSON line at a time. A per-line bufio.Scanner is
-	// safer than json.Decoder here: json.Decoder keeps internal state,
-	// so a single malformed token mid-archive can silently drop the
-	// rest of the stream while only one event is counted as discarded.
-	// Scanner + json.Unmarshal makes each line independent.
-	scanner := bufio.NewScanner(br)
-	// Default 64 KiB token cap is too small for some PullRequestEvent
-	// payloads (commit lists routinely run a few hundred KiB). Allow
-	// growth up to 8 MiB per line; anything larger is treated as a
-	// poison record (see scanner.Err handling below).
-	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
-	hourBucket := hour.Truncate(time.Hour).UTC()
-
-	thisArchive := make(map[string]int) // repo -> events kept this archive
-	thisArchiveTypes := make(map[string]map[string]int)
-	// keptByType is the flat per-event-type tally across all repos in
-	// this archive, surfaced through OnEventsProcessed so Story 5
-	// observability ([ISI-955](/ISI/issues/ISI-955)) can attribute the
-	// counter without re-scanning the firehose. Total kept = sum of
-	// keptByType values.
-	keptByType := make(map[string]int64, len(s.eventTypes))
-
-	var discarded int64
-	for {
-		select {
-		case <-ctx.Done():
-			return keptByType, discarded, nil, ctx.Err()
-		default:
-		}
-
-		if !scanner.Scan() {
-			break
-		}
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-
-		var evt gharchiveEvent
-		if err := json.Unmarshal(line, &evt); err != nil {
-			// A single malformed line is independent of the rest:
-			// count it as discarded and move on.
-			discarded++
-			continue
-		}
-
-		if evt.Repo.Name == "" || !s.eventTypes[evt.Type] {
-			discarded++
-			continue
-		}
-
-		thisArchive[evt.Repo.Name]++
-		typeMap := thisArchiveTypes[evt.Repo.Name]
-		if typeMap == nil {
-			typeMap = make(map[string]int, len(s.eventTypes))
-			thisArchiveTypes[evt.Repo.Name] = typeMap
-		}
-		typeMap[evt.Type]++
-		keptByType[evt.Type]++
-	}
-	if err := scanner.Err(); err != nil {
-		if errors.Is(err, bufio.ErrTooLong) {
-			logging.Warn("gharchive_source: dropping archive tail past oversized line",
-				"hour", hour, "discarded", discarded+1)
-			discarded++
-		} else {
-			return keptByType, discarded, nil, fmt.Errorf("scan archive: %w", err)
-		}
-	}
-
+// like TopActiveRepos that whole time. Only this fold takes the write
+// lock.
+func (s *GHArchiveSource) commitHour(h gharchiveHourScratch) []GHArchiveHourAggregate {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	aggregates := make([]GHArchiveHourAggregate, 0, len(thisArchive))
-	for repo, count := range thisArchive {
+	aggregates := make([]GHArchiveHourAggregate, 0, len(h.counts))
+	for repo, count := range h.counts {
 		bucket, ok := s.buckets[repo]
 		if !ok {
-			bucket = newRingBucket(hourBucket, int(s.cfg.Window/time.Hour))
+			bucket = newRingBucket(h.bucket, int(s.cfg.Window/time.Hour))
 			s.buckets[repo] = bucket
 		}
-		bucket.set(hourBucket, count, thisArchiveTypes[repo])
+		bucket.set(h.bucket, count, h.types[repo])
+		var actors gharchiveHourActors
+		if a := h.actors[repo]; a != nil {
+			actors = *a
+		}
+		bucket.setActors(h.bucket, actors)
 
 		aggregates = append(aggregates, GHArchiveHourAggregate{
 			RepoName:    repo,
-			HourBucket:  hourBucket,
+			HourBucket:  h.bucket,
 			EventCount:  count,
-			PerEventTyp: cloneTypeMap(thisArchiveTypes[repo]),
+			PerEventTyp: cloneTypeMap(h.types[repo]),
 		})
 	}
 
@@ -787,16 +551,22 @@ func (s *GHArchiveSource) consume(ctx context.Context, hour time.Time, body io.R
 	// repos with no events this archive. Without this, a repo that
 	// went cold would keep reporting its last-seen counts forever
 	// because its private hourEnd would never advance.
-	newRightEdge := hourBucket.Add(time.Hour)
+	newRightEdge := h.bucket.Add(time.Hour)
+	if h.bucket.After(s.archivedThrough) {
+		s.archivedThrough = h.bucket
+	}
 	for _, bucket := range s.buckets {
 		bucket.slideTo(newRightEdge)
 	}
 
+	s.recordReleaseSignals(h.releases)
+	s.gcReleaseSignals(newRightEdge)
+
 	// Drop bucket entries that have no events anywhere in the window
 	// after rotation, so memory stays bounded as repos go cold.
 	s.gcEmptyBuckets()
 
-	return keptByType, discarded, aggregates, nil
+	return aggregates
 }
 
 // cloneTypeMap returns a defensive copy so callers can mutate the
@@ -836,6 +606,11 @@ type GHArchiveRepoActivity struct {
 	HourCounts     []int          // length = window in hours, oldest first
 	PerEventType   map[string]int // aggregated across the window
 	PeakHourEvents int
+
+	// UniqueStargazers and UniqueContributors are approximate distinct
+	// actor counts over the window (see gharchive_actors.go).
+	UniqueStargazers   int
+	UniqueContributors int
 }
 
 // TopActiveRepos returns at most n repos sorted by total events over
@@ -844,40 +619,11 @@ type GHArchiveRepoActivity struct {
 // pick top-N candidates for promotion to the discovery known set.
 //
 // Safe under concurrent ProcessArchive: holds the read lock for the
-// duration of the snapshot copy.
+// duration of the snapshot copy. We sort the snapshot rather than
+// maintaining a heap online — N is bounded (Story 2 uses N=500) and
+// snapshot frequency is at most once per hour.
 func (s *GHArchiveSource) TopActiveRepos(n int, minEventsTotal int) []GHArchiveRepoActivity {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// Snapshot all repos meeting the floor. We sort the snapshot
-	// rather than maintaining a heap online — N is bounded (Story 2
-	// uses N=500) and snapshot frequency is at most once per hour.
-	out := make([]GHArchiveRepoActivity, 0, len(s.buckets))
-	for repo, bucket := range s.buckets {
-		total := bucket.total()
-		if total < minEventsTotal {
-			continue
-		}
-		out = append(out, GHArchiveRepoActivity{
-			RepoName:       repo,
-			TotalEvents:    total,
-			HourCounts:     bucket.hourCountsOldestFirst(),
-			PerEventType:   bucket.perTypeAggregate(),
-			PeakHourEvents: bucket.peak(),
-		})
-	}
-
-	sort.Slice(out, func(i, j int) bool {
-		if out[i].TotalEvents != out[j].TotalEvents {
-			return out[i].TotalEvents > out[j].TotalEvents
-		}
-		return out[i].RepoName < out[j].RepoName
-	})
-
-	if n > 0 && len(out) > n {
-		out = out[:n]
-	}
-	return out
+	return s.TopActiveReposBy(GHArchiveRankEvents, n, minEventsTotal)
 }
 
 // WindowSize returns the number of hour buckets in the sliding window.
@@ -907,6 +653,7 @@ type ringBucket struct {
 
 	counts  []int
 	perType []map[string]int
+	actors  []gharchiveHourActors
 }
 
 // newRingBucket constructs a ring sized to windowHours with the given
@@ -919,6 +666,7 @@ func newRingBucket(initialHour time.Time, windowHours int) *ringBucket {
 		hourEnd: initialHour.Add(time.Hour),
 		counts:  make([]int, windowHours),
 		perType: make([]map[string]int, windowHours),
+		actors:  make([]gharchiveHourActors, windowHours),
 	}
 	return b
 }
@@ -944,22 +692,33 @@ func (b *ringBucket) set(hourBucket time.Time, count int, perType map[string]int
 		idx := len(b.counts) - 1
 		b.counts[idx] = count
 		b.perType[idx] = cloneTypeMap(perType)
+		b.actors[idx] = gharchiveHourActors{}
 		return
 	}
 
-	// hourBucket is somewhere inside the existing window or older.
-	// hoursAgo is the offset from mostRecent (slot len-1) backwards.
-	hoursAgo := int(rightEdge.Sub(hourBucket.Add(time.Hour)) / time.Hour)
-	if hoursAgo < 0 || hoursAgo >= len(b.counts) {
+	idx, ok := b.slotIndex(hourBucket)
+	if !ok {
 		// Outside the window — silently drop. The cursor would
 		// only feed us this hour if Run() asked for it, but a
 		// caller passing ProcessArchive() out-of-order would
 		// land here.
 		return
 	}
-	idx := len(b.counts) - 1 - hoursAgo
 	b.counts[idx] = count
 	b.perType[idx] = cloneTypeMap(perType)
+	b.actors[idx] = gharchiveHourActors{}
+}
+
+// slotIndex returns the ring slot holding hourBucket. ok=false when the
+// hour is at/after the right edge or older than the window.
+func (b *ringBucket) slotIndex(hourBucket time.Time) (int, bool) {
+	hourBucket = hourBucket.Truncate(time.Hour).UTC()
+	// hoursAgo is the offset from mostRecent (slot len-1) backwards.
+	hoursAgo := int(b.hourEnd.Sub(hourBucket.Add(time.Hour)) / time.Hour)
+	if !hourBucket.Before(b.hourEnd) || hoursAgo < 0 || hoursAgo >= len(b.counts) {
+		return 0, false
+	}
+	return len(b.counts) - 1 - hoursAgo, true
 }
 
 // slideTo advances the bucket's right edge to newRightEdge by rotating
@@ -989,15 +748,18 @@ func (b *ringBucket) rotate(steps int) {
 		for i := range b.counts {
 			b.counts[i] = 0
 			b.perType[i] = nil
+			b.actors[i] = gharchiveHourActors{}
 		}
 		return
 	}
 	// Shift left by `steps`; tail becomes zero.
 	copy(b.counts, b.counts[steps:])
 	copy(b.perType, b.perType[steps:])
+	copy(b.actors, b.actors[steps:])
 	for i := n - steps; i < n; i++ {
 		b.counts[i] = 0
 		b.perType[i] = nil
+		b.actors[i] = gharchiveHourActors{}
 	}
 }
 
@@ -0,0 +1,172 @@
+// Package evaluation measures classifier quality against a golden
+// dataset of hand-labeled repos. It renders prompts exactly like the
+// classification pipeline, but from the dataset instead of the forge
+// APIs, so runs are repeatable offline against a local model or a stub.
+package evaluation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/classification"
+	"github.com/hrexed/github-radar/internal/config"
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+// Case is one golden dataset entry: what the classifier is shown for a
+// repo and the (category, subcategory) pair it should resolve to.
+type Case struct {
+	Repo        string   `json:"repo"`
+	Description string   `json:"description,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Topics      []string `json:"topics,omitempty"`
+	Readme      string   `json:"readme,omitempty"`
+	Category    string   `json:"category"`
+	Subcategory string   `json:"subcategory"`
+}
+
+// pair returns the expected label as "category/subcategory".
+func (c Case) pair() string {
+	return c.Category + "/" + c.Subcategory
+}
+
+// LoadDataset reads a golden dataset in JSON Lines form, one Case per
+// line; blank lines are skipped. Every case needs a unique repo and an
+// expected pair allowed by database.IsAllowedPair. It also returns the
+// SHA-256 of the file so reports can tell datasets apart.
+func LoadDataset(path string) ([]Case, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading dataset: %w", err)
+	}
+
+	var cases []Case
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, "", fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		switch {
+		case c.Repo == "":
+			return nil, "", fmt.Errorf("%s:%d: repo is required", path, line)
+		case seen[c.Repo]:
+			return nil, "", fmt.Errorf("%s:%d: duplicate repo %s", path, line, c.Repo)
+		case !database.IsAllowedPair(c.Category, c.Subcategory):
+			return nil, "", fmt.Errorf("%s:%d: %s is not an allowed pair", path, line, c.pair())
+		}
+		seen[c.Repo] = true
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", fmt.Errorf("reading dataset: %w", err)
+	}
+	if len(cases) == 0 {
+		return nil, "", fmt.Errorf("%s: dataset is empty", path)
+	}
+	sum := sha256.Sum256(data)
+	return cases, hex.EncodeToString(sum[:]), nil
+}
+
+// Run classifies every case with llm using the prompts, categories and
+// few-shot settings of cfg and scores the answers. Few-shot examples
+// are drawn from the other cases of the dataset (leave-one-out), so
+// few_shot.examples can be compared on and off over the same data. A
+// case the classifier fails on counts as a miss.
+func Run(ctx context.Context, llm classification.Classifier, cfg config.ClassificationConfig, cases []Case) (*Report, error) {
+	pool := make([]database.LabeledExample, len(cases))
+	for i, c := range cases {
+		pool[i] = labeledExample(c)
+	}
+
+	report := &Report{
+		Model:      llm.Model(),
+		PromptHash: PromptHash(cfg),
+		FewShot:    cfg.FewShot.Examples,
+		StartedAt:  time.Now().UTC(),
+	}
+	results := make([]CaseResult, 0, len(cases))
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var examples []database.LabeledExample
+		if cfg.FewShot.Examples > 0 {
+			examples = classification.SelectExamples(pool, labeledExample(c), cfg.FewShot.Examples, classification.TopicSimilarity)
+		}
+		system, user, err := classification.BuildPrompts(cfg, classification.PromptData{
+			RepoName:    c.Repo,
+			Description: c.Description,
+			Language:    c.Language,
+			Topics:      strings.Join(c.Topics, ","),
+			StarTrend:   "unknown",
+		}, c.Readme, examples)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		answer, err := llm.Classify(ctx, system, user)
+		r := CaseResult{Repo: c.Repo, Expected: c.pair(), LatencyMs: msSince(start)}
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			category, subcategory := resolve(answer.Category)
+			r.Predicted = category + "/" + subcategory
+			r.Confidence = answer.Confidence
+			r.Correct = r.Predicted == r.Expected
+			r.CategoryCorrect = category == c.Category
+		}
+		results = append(results, r)
+	}
+	report.score(results)
+	return report, nil
+}
+
+// PromptHash identifies the prompt setup of cfg: both templates, the
+// category list, max_readme_chars and the few-shot settings. Runs with
+// the same hash differ only in the model.
+func PromptHash(cfg config.ClassificationConfig) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%d\x00%d\x00%g",
+		cfg.SystemPrompt, cfg.UserPrompt, strings.Join(cfg.Categories, ","),
+		cfg.MaxReadmeChars, cfg.FewShot.Examples, cfg.FewShot.MaxShare)
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// resolve maps a classifier category to its v3 pair the way the
+// pipeline stores it.
+func resolve(category string) (string, string) {
+	rec := database.RepoRecord{PrimaryCategory: category}
+	c, s, _ := rec.ResolveTaxonomy()
+	return c, s
+}
+
+func labeledExample(c Case) database.LabeledExample {
+	return database.LabeledExample{
+		FullName:    c.Repo,
+		Language:    c.Language,
+		Description: c.Description,
+		Topics:      c.Topics,
+		Category:    c.Category,
+		Subcategory: c.Subcategory,
+	}
+}
+
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,216 @@
+package evaluation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/classification"
+	"github.com/hrexed/github-radar/internal/config"
+)
+
+// stubClassifier answers from a fixed repo → answer table, keyed by the
+// repo name found in the user prompt.
+type stubClassifier struct {
+	answers map[string]classification.ClassificationResult
+	prompts []string
+}
+
+func (s *stubClassifier) Classify(_ context.Context, _, user string) (*classification.ClassificationResult, error) {
+	s.prompts = append(s.prompts, user)
+	for repo, answer := range s.answers {
+		if strings.Contains(user, "Repo: "+repo+"\n") {
+			a := answer
+			return &a, nil
+		}
+	}
+	return nil, errors.New("model unavailable")
+}
+
+func (s *stubClassifier) Model() string { return "stub" }
+
+func writeDataset(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "golden.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+var golden = []string{
+	`{"repo":"a/op","topics":["kubernetes","operator"],"language":"Go","readme":"An operator","category":"cloud-native","subcategory":"kubernetes"}`,
+	``,
+	`{"repo":"a/agent","topics":["agents"],"readme":"Agents","category":"ai","subcategory":"agents"}`,
+	`{"repo":"a/rag","topics":["rag"],"category":"ai","subcategory":"rag"}`,
+	`{"repo":"a/mesh","topics":["kubernetes","mesh"],"language":"Go","category":"cloud-native","subcategory":"networking"}`,
+}
+
+func evalConfig() config.ClassificationConfig {
+	return config.ClassificationConfig{
+		MaxReadmeChars: 2000,
+		Categories:     []string{"kubernetes", "networking", "ai-agents", "rag", "other"},
+		SystemPrompt:   `Categories: {{.Categories}}`,
+		UserPrompt:     "{{.Examples}}Repo: {{.RepoName}}\nREADME: {{.Readme}}",
+	}
+}
+
+func TestLoadDataset(t *testing.T) {
+	cases, hash, err := LoadDataset(writeDataset(t, golden...))
+	if err != nil {
+		t.Fatalf("LoadDataset: %v", err)
+	}
+	if len(cases) != 4 || cases[0].Repo != "a/op" || cases[0].Topics[1] != "operator" || len(hash) != 64 {
+		t.Errorf("LoadDataset = %+v, %q", cases, hash)
+	}
+
+	bad := map[string][]string{
+		"not json":      {`{"repo":`},
+		"repo required": {`{"category":"ai","subcategory":"agents"}`},
+		"duplicate":     {golden[2], golden[2]},
+		"not allowed":   {`{"repo":"a/x","category":"ai","subcategory":"kubernetes"}`},
+		"empty":         {``},
+	}
+	for name, lines := range bad {
+		if _, _, err := LoadDataset(writeDataset(t, lines...)); err == nil {
+			t.Errorf("%s: LoadDataset succeeded, want an error", name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases, _, err := LoadDataset(writeDataset(t, golden...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := &stubClassifier{answers: map[string]classification.ClassificationResult{
+		"a/op":    {Category: "kubernetes", Confidence: 0.95},
+		"a/agent": {Category: "ai-agents", Confidence: 0.85},
+		"a/mesh":  {Category: "kubernetes", Confidence: 0.75}, // wrong subcategory
+		// a/rag fails
+	}}
+
+	r, err := Run(context.Background(), llm, evalConfig(), cases)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if r.Cases != 4 || r.Failed != 1 || r.Model != "stub" || r.PromptHash == "" {
+		t.Errorf("report header = %+v", r)
+	}
+	if r.Accuracy != 0.5 || r.CategoryAccuracy != 0.75 {
+		t.Errorf("accuracy = %v / %v, want 0.5 / 0.75", r.Accuracy, r.CategoryAccuracy)
+	}
+	// Labels: ai/agents F1 1, ai/rag 0, cloud-native/kubernetes
+	// P 0.5 R 1 F1 2/3, cloud-native/networking 0.
+	if want := (1 + 2.0/3) / 4; math.Abs(r.MacroF1-want) > 1e-9 {
+		t.Errorf("MacroF1 = %v, want %v", r.MacroF1, want)
+	}
+	if r.Confusion["cloud-native/networking"]["cloud-native/kubernetes"] != 1 {
+		t.Errorf("Confusion = %v", r.Confusion)
+	}
+	// Bins 0.9 (1 right), 0.8 (1 right), 0.7 (1 wrong):
+	// ECE = (0.05 + 0.15 + 0.75) / 3.
+	if want := 0.95 / 3; math.Abs(r.Calibration.ECE-want) > 1e-9 {
+		t.Errorf("ECE = %v, want %v", r.Calibration.ECE, want)
+	}
+	if r.Calibration.Bins[7].Cases != 1 || r.Calibration.Bins[7].Accuracy != 0 {
+		t.Errorf("bin 0.7 = %+v", r.Calibration.Bins[7])
+	}
+	if r.Latency.MaxMs < r.Latency.P50Ms {
+		t.Errorf("Latency = %+v", r.Latency)
+	}
+	for _, p := range llm.prompts {
+		if strings.Contains(p, "Category:") {
+			t.Errorf("few-shot examples rendered with few_shot.examples = 0:\n%s", p)
+		}
+	}
+}
+
+func TestRun_FewShotLeaveOneOut(t *testing.T) {
+	cases, _, _ := LoadDataset(writeDataset(t, golden...))
+	llm := &stubClassifier{answers: map[string]classification.ClassificationResult{
+		"a/op": {Category: "kubernetes", Confidence: 0.9},
+	}}
+	cfg := evalConfig()
+	cfg.FewShot = config.FewShotConfig{Examples: 1, MaxShare: 0.5}
+
+	r, err := Run(context.Background(), llm, cfg, cases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.FewShot != 1 || r.PromptHash == PromptHash(evalConfig()) {
+		t.Errorf("few-shot run not told apart: %+v", r)
+	}
+	// a/op is most like a/mesh and must never be its own example.
+	if p := llm.prompts[0]; !strings.Contains(p, "Repository: a/mesh") || strings.Contains(p, "Repository: a/op") {
+		t.Errorf("a/op prompt:\n%s", p)
+	}
+}
+
+func TestCompareAndMarkdown(t *testing.T) {
+	cases, hash, _ := LoadDataset(writeDataset(t, golden...))
+	base, _ := Run(context.Background(), &stubClassifier{answers: map[string]classification.ClassificationResult{
+		"a/op":   {Category: "kubernetes", Confidence: 0.9},
+		"a/rag":  {Category: "rag", Confidence: 0.9},
+		"a/mesh": {Category: "networking", Confidence: 0.9},
+	}}, evalConfig(), cases)
+	run, _ := Run(context.Background(), &stubClassifier{answers: map[string]classification.ClassificationResult{
+		"a/op":    {Category: "kubernetes", Confidence: 0.9},
+		"a/agent": {Category: "ai-agents", Confidence: 0.9},
+		"a/rag":   {Category: "rag", Confidence: 0.9},
+		"a/mesh":  {Category: "kubernetes", Confidence: 0.9},
+	}}, evalConfig(), cases)
+	base.DatasetHash, run.DatasetHash = hash, hash
+
+	cmp := Compare(base, run)
+	if !cmp.SameDataset || !cmp.SamePrompt || cmp.Accuracy != 0 {
+		t.Errorf("Compare = %+v", cmp)
+	}
+	if len(cmp.Fixed) != 1 || cmp.Fixed[0] != "a/agent" || len(cmp.Broken) != 1 || cmp.Broken[0] != "a/mesh" {
+		t.Errorf("Fixed %v Broken %v", cmp.Fixed, cmp.Broken)
+	}
+
+	var buf bytes.Buffer
+	if err := run.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "run.json")
+	os.WriteFile(path, buf.Bytes(), 0o644)
+	loaded, err := LoadReport(path)
+	if err != nil || loaded.Accuracy != run.Accuracy || len(loaded.Results) != 4 {
+		t.Fatalf("LoadReport = %+v, %v", loaded, err)
+	}
+
+	buf.Reset()
+	if err := run.WriteMarkdown(&buf, base); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	for _, want := range []string{
+		"| accuracy | 75.0% |",
+		"## Compared to baseline",
+		"Now correct: a/agent",
+		"Now wrong: a/mesh",
+		"| cloud-native/networking | cloud-native/kubernetes | 1 |",
+		"## Calibration",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown lacks %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestLoadDataset_Example(t *testing.T) {
+	cases, _, err := LoadDataset("../../configs/classification-golden.example.jsonl")
+	if err != nil {
+		t.Fatalf("example dataset: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Error("example dataset is empty")
+	}
+}
@@ -0,0 +1,378 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// calibrationBins is the number of equal-width confidence bins.
+const calibrationBins = 10
+
+// Report is the outcome of one evaluation run. It is written as JSON
+// for later comparison and rendered as Markdown for people.
+type Report struct {
+	Provider    string    `json:"provider,omitempty"`
+	Model       string    `json:"model"`
+	PromptHash  string    `json:"prompt_hash"`
+	FewShot     int       `json:"few_shot"`
+	Dataset     string    `json:"dataset,omitempty"`
+	DatasetHash string    `json:"dataset_hash,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+
+	Cases  int `json:"cases"`
+	Failed int `json:"failed"`
+	// Accuracy is the share of cases whose predicted pair matches the
+	// expected one; CategoryAccuracy only compares the top-level
+	// category.
+	Accuracy         float64 `json:"accuracy"`
+	CategoryAccuracy float64 `json:"category_accuracy"`
+	MacroF1          float64 `json:"macro_f1"`
+
+	Labels      []LabelStats              `json:"labels"`
+	Confusion   map[string]map[string]int `json:"confusion"` // expected → predicted → cases
+	Calibration Calibration               `json:"calibration"`
+	Latency     Latency                   `json:"latency"`
+	Results     []CaseResult              `json:"results"`
+}
+
+// CaseResult is the classifier's answer for one case.
+type CaseResult struct {
+	Repo            string  `json:"repo"`
+	Expected        string  `json:"expected"`
+	Predicted       string  `json:"predicted,omitempty"`
+	Confidence      float64 `json:"confidence"`
+	Correct         bool    `json:"correct"`
+	CategoryCorrect bool    `json:"category_correct"`
+	LatencyMs       float64 `json:"latency_ms"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// LabelStats scores one category/subcategory pair. Support is the
+// number of cases expecting it.
+type LabelStats struct {
+	Label     string  `json:"label"`
+	Support   int     `json:"support"`
+	TP        int     `json:"tp"`
+	FP        int     `json:"fp"`
+	FN        int     `json:"fn"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// Calibration compares the reported confidence with accuracy. ECE is
+// the expected calibration error: the case-weighted mean gap between
+// confidence and accuracy over the bins.
+type Calibration struct {
+	ECE  float64          `json:"ece"`
+	Bins []CalibrationBin `json:"bins"`
+}
+
+// CalibrationBin holds the answers with a confidence in [Lower, Upper).
+type CalibrationBin struct {
+	Lower          float64 `json:"lower"`
+	Upper          float64 `json:"upper"`
+	Cases          int     `json:"cases"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	Accuracy       float64 `json:"accuracy"`
+}
+
+// Latency summarizes classifier call times in milliseconds, failed
+// calls included.
+type Latency struct {
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+// score fills the metrics of r from results.
+func (r *Report) score(results []CaseResult) {
+	r.Results = results
+	r.Cases = len(results)
+	r.Confusion = make(map[string]map[string]int)
+
+	stats := make(map[string]*LabelStats)
+	label := func(name string) *LabelStats {
+		if stats[name] == nil {
+			stats[name] = &LabelStats{Label: name}
+		}
+		return stats[name]
+	}
+	var correct, categoryCorrect int
+	for _, c := range results {
+		expected := label(c.Expected)
+		expected.Support++
+		if c.Error != "" {
+			r.Failed++
+			expected.FN++
+			continue
+		}
+		if r.Confusion[c.Expected] == nil {
+			r.Confusion[c.Expected] = make(map[string]int)
+		}
+		r.Confusion[c.Expected][c.Predicted]++
+		if c.Correct {
+			correct++
+			expected.TP++
+		} else {
+			expected.FN++
+			label(c.Predicted).FP++
+		}
+		if c.CategoryCorrect {
+			categoryCorrect++
+		}
+	}
+	if r.Cases == 0 {
+		return
+	}
+	r.Accuracy = float64(correct) / float64(r.Cases)
+	r.CategoryAccuracy = float64(categoryCorrect) / float64(r.Cases)
+
+	// Macro-F1 averages over every label expected or predicted, so a
+	// label the classifier invents counts against it.
+	r.Labels = make([]LabelStats, 0, len(stats))
+	var f1Sum float64
+	for _, s := range stats {
+		s.Precision = ratio(s.TP, s.TP+s.FP)
+		s.Recall = ratio(s.TP, s.TP+s.FN)
+		if s.Precision+s.Recall > 0 {
+			s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+		}
+		f1Sum += s.F1
+		r.Labels = append(r.Labels, *s)
+	}
+	sort.Slice(r.Labels, func(i, j int) bool { return r.Labels[i].Label < r.Labels[j].Label })
+	r.MacroF1 = f1Sum / float64(len(r.Labels))
+
+	r.Calibration = calibrate(results)
+	r.Latency = latency(results)
+}
+
+// calibrate bins the answered cases by confidence.
+func calibrate(results []CaseResult) Calibration {
+	bins := make([]CalibrationBin, calibrationBins)
+	var confSum, hits [calibrationBins]float64
+	answered := 0
+	for _, c := range results {
+		if c.Error != "" {
+			continue
+		}
+		answered++
+		i := min(max(int(c.Confidence*calibrationBins), 0), calibrationBins-1)
+		bins[i].Cases++
+		confSum[i] += c.Confidence
+		if c.Correct {
+			hits[i]++
+		}
+	}
+	var cal Calibration
+	for i := range bins {
+		bins[i].Lower = float64(i) / calibrationBins
+		bins[i].Upper = float64(i+1) / calibrationBins
+		if n := bins[i].Cases; n > 0 {
+			bins[i].MeanConfidence = confSum[i] / float64(n)
+			bins[i].Accuracy = hits[i] / float64(n)
+			cal.ECE += float64(n) / float64(answered) * math.Abs(bins[i].Accuracy-bins[i].MeanConfidence)
+		}
+	}
+	cal.Bins = bins
+	return cal
+}
+
+// latency summarizes call times with nearest-rank percentiles.
+func latency(results []CaseResult) Latency {
+	ms := make([]float64, len(results))
+	var sum float64
+	for i, c := range results {
+		ms[i] = c.LatencyMs
+		sum += c.LatencyMs
+	}
+	sort.Float64s(ms)
+	rank := func(p float64) float64 {
+		return ms[int(math.Ceil(p*float64(len(ms))))-1]
+	}
+	return Latency{
+		MeanMs: sum / float64(len(ms)),
+		P50Ms:  rank(0.5),
+		P95Ms:  rank(0.95),
+		MaxMs:  ms[len(ms)-1],
+	}
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// WriteJSON writes r as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// LoadReport reads a report written by WriteJSON.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading report: %w", err)
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing report %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Comparison is the difference between a run and a baseline run.
+type Comparison struct {
+	SameDataset bool
+	SamePrompt  bool
+	Accuracy    float64 // run minus baseline
+	MacroF1     float64
+	ECE         float64
+	P50Ms       float64
+	// Fixed and Broken list the repos the run now gets right or wrong.
+	Fixed  []string
+	Broken []string
+}
+
+// Compare returns how r differs from base. Cases only one of the runs
+// has are ignored.
+func Compare(base, r *Report) Comparison {
+	cmp := Comparison{
+		SameDataset: base.DatasetHash != "" && base.DatasetHash == r.DatasetHash,
+		SamePrompt:  base.PromptHash == r.PromptHash,
+		Accuracy:    r.Accuracy - base.Accuracy,
+		MacroF1:     r.MacroF1 - base.MacroF1,
+		ECE:         r.Calibration.ECE - base.Calibration.ECE,
+		P50Ms:       r.Latency.P50Ms - base.Latency.P50Ms,
+	}
+	before := make(map[string]bool, len(base.Results))
+	for _, c := range base.Results {
+		before[c.Repo] = c.Correct
+	}
+	for _, c := range r.Results {
+		was, ok := before[c.Repo]
+		switch {
+		case !ok || was == c.Correct:
+		case c.Correct:
+			cmp.Fixed = append(cmp.Fixed, c.Repo)
+		default:
+			cmp.Broken = append(cmp.Broken, c.Repo)
+		}
+	}
+	return cmp
+}
+
+// WriteMarkdown renders r as a Markdown report. With a non-nil base it
+// adds a comparison with that baseline run.
+func (r *Report) WriteMarkdown(w io.Writer, base *Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Classification evaluation — %s\n\n", r.StartedAt.Format("2006-01-02 15:04 UTC"))
+	fmt.Fprintf(&b, "**Model:** %s", r.Model)
+	if r.Provider != "" {
+		fmt.Fprintf(&b, " (%s)", r.Provider)
+	}
+	fmt.Fprintf(&b, "  \n**Prompt:** `%s`, %d few-shot examples  \n", r.PromptHash, r.FewShot)
+	if r.Dataset != "" {
+		fmt.Fprintf(&b, "**Dataset:** %s (`%.12s`)  \n", r.Dataset, r.DatasetHash)
+	}
+	fmt.Fprintf(&b, "**Cases:** %d (%d failed)\n\n", r.Cases, r.Failed)
+
+	b.WriteString("| metric | value |\n|--------|-------|\n")
+	fmt.Fprintf(&b, "| accuracy | %.1f%% |\n", r.Accuracy*100)
+	fmt.Fprintf(&b, "| category accuracy | %.1f%% |\n", r.CategoryAccuracy*100)
+	fmt.Fprintf(&b, "| macro-F1 | %.3f |\n", r.MacroF1)
+	fmt.Fprintf(&b, "| ECE | %.3f |\n", r.Calibration.ECE)
+	fmt.Fprintf(&b, "| latency p50 / p95 / max | %.0f / %.0f / %.0f ms |\n", r.Latency.P50Ms, r.Latency.P95Ms, r.Latency.MaxMs)
+
+	if base != nil {
+		cmp := Compare(base, r)
+		fmt.Fprintf(&b, "\n## Compared to baseline (%s, `%s`)\n\n", base.Model, base.PromptHash)
+		if !cmp.SameDataset {
+			b.WriteString("> The baseline ran on a different dataset; deltas are indicative only.\n\n")
+		}
+		fmt.Fprintf(&b, "| metric | delta |\n|--------|-------|\n")
+		fmt.Fprintf(&b, "| accuracy | %+.1f pp |\n", cmp.Accuracy*100)
+		fmt.Fprintf(&b, "| macro-F1 | %+.3f |\n", cmp.MacroF1)
+		fmt.Fprintf(&b, "| ECE | %+.3f |\n", cmp.ECE)
+		fmt.Fprintf(&b, "| latency p50 | %+.0f ms |\n", cmp.P50Ms)
+		if len(cmp.Fixed) > 0 {
+			fmt.Fprintf(&b, "\nNow correct: %s\n", strings.Join(cmp.Fixed, ", "))
+		}
+		if len(cmp.Broken) > 0 {
+			fmt.Fprintf(&b, "\nNow wrong: %s\n", strings.Join(cmp.Broken, ", "))
+		}
+	}
+
+	b.WriteString("\n## Per label\n\n")
+	b.WriteString("| label | support | precision | recall | F1 |\n|-------|---------|-----------|--------|----|\n")
+	for _, s := range r.Labels {
+		fmt.Fprintf(&b, "| %s | %d | %.2f | %.2f | %.2f |\n", s.Label, s.Support, s.Precision, s.Recall, s.F1)
+	}
+
+	b.WriteString("\n## Confusions\n\n")
+	type confusion struct {
+		expected, predicted string
+		n                   int
+	}
+	var confusions []confusion
+	for expected, row := range r.Confusion {
+		for predicted, n := range row {
+			if predicted != expected {
+				confusions = append(confusions, confusion{expected, predicted, n})
+			}
+		}
+	}
+	sort.Slice(confusions, func(i, j int) bool {
+		if confusions[i].n != confusions[j].n {
+			return confusions[i].n > confusions[j].n
+		}
+		if confusions[i].expected != confusions[j].expected {
+			return confusions[i].expected < confusions[j].expected
+		}
+		return confusions[i].predicted < confusions[j].predicted
+	})
+	if len(confusions) == 0 {
+		b.WriteString("_None._\n")
+	} else {
+		b.WriteString("| expected | predicted | cases |\n|----------|-----------|-------|\n")
+		for _, c := range confusions {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", c.expected, c.predicted, c.n)
+		}
+	}
+
+	b.WriteString("\n## Calibration\n\n")
+	b.WriteString("| confidence | cases | mean confidence | accuracy |\n|------------|-------|-----------------|----------|\n")
+	for _, bin := range r.Calibration.Bins {
+		if bin.Cases > 0 {
+			fmt.Fprintf(&b, "| %.1f–%.1f | %d | %.2f | %.2f |\n", bin.Lower, bin.Upper, bin.Cases, bin.MeanConfidence, bin.Accuracy)
+		}
+	}
+
+	var failed []CaseResult
+	for _, c := range r.Results {
+		if c.Error != "" {
+			failed = append(failed, c)
+		}
+	}
+	if len(failed) > 0 {
+		b.WriteString("\n## Failed cases\n\n")
+		for _, c := range failed {
+			fmt.Fprintf(&b, "- %s: %s\n", c.Repo, c.Error)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
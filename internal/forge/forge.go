@@ -0,0 +1,112 @@
+// Package forge abstracts the code-hosting services github-radar tracks
+// repositories on. GitHub implements Provider in the github package;
+// GitLab and Gitea/Forgejo in the gitlab and gitea packages.
+//
+// Repos on a forge other than GitHub are keyed provider:owner/repo
+// (see repository.ParseKey), where provider is the forge's configured
+// name, so they share the state store, database, scoring and exporter
+// with GitHub repos without colliding.
+package forge
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Provider kinds, as configured under forges[].type. GitHub is
+// configured under github itself.
+const (
+	KindGitHub = "github"
+	KindGitLab = "gitlab"
+	KindGitea  = "gitea"
+)
+
+// ActivityWindow is the window Activity counts pull requests and issues
+// over, matching the GitHub collector.
+const ActivityWindow = 7 * 24 * time.Hour
+
+// ErrNotFound is wrapped by Provider methods when the repository does
+// not exist or is not visible to the configured credentials.
+var ErrNotFound = errors.New("repository not found")
+
+// Provider is one forge's API.
+type Provider interface {
+	// Name is the name repos on this forge are keyed with
+	// (gitlab:owner/repo); KindGitHub for GitHub.
+	Name() string
+
+	// GetRepository fetches a repository's metadata and counters.
+	// owner is the full namespace path on forges with nested groups.
+	GetRepository(ctx context.Context, owner, name string) (*Repository, error)
+
+	// GetActivity fetches activity over the last ActivityWindow. On a
+	// partial failure it returns what it collected with the error.
+	GetActivity(ctx context.Context, owner, name string) (*Activity, error)
+
+	// GetReadme fetches the repository's README from its default branch.
+	GetReadme(ctx context.Context, owner, name string) (*Readme, error)
+
+	// SearchRepositories returns up to limit repositories matching query,
+	// most starred first. Query syntax is the forge's own.
+	SearchRepositories(ctx context.Context, query string, limit int) ([]Repository, error)
+
+	// RateLimit returns the budget reported by the last response.
+	RateLimit() RateLimit
+}
+
+// Repository is a forge repository's metadata.
+type Repository struct {
+	Owner string
+	Name  string
+	// FullName is the owner/name path the forge reports.
+	FullName    string
+	Description string
+	Language    string
+	Topics      []string
+	Stars       int
+	Forks       int
+	OpenIssues  int
+
+	// Fork is true for forks; Upstream names the repo forked from when
+	// the forge reports it. Mirror is true for pull mirrors.
+	Fork     bool
+	Upstream string
+	Mirror   bool
+	Archived bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	URL       string
+}
+
+// Activity is a repository's recent activity.
+type Activity struct {
+	MergedPRs7d  int
+	NewIssues7d  int
+	Contributors int
+	// LatestRelease is when the newest release was published; zero
+	// when the repository has none.
+	LatestRelease time.Time
+}
+
+// Readme is a repository's README.
+type Readme struct {
+	// Content is the raw README text; empty when not Found.
+	Content string
+	// Found is false when the repository has no README.
+	Found bool
+}
+
+// RateLimit is a forge's request budget. Limit is 0 when the forge does
+// not report one.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Exhausted reports whether the budget is spent until Reset.
+func (r RateLimit) Exhausted(now time.Time) bool {
+	return r.Limit > 0 && r.Remaining <= 0 && now.Before(r.Reset)
+}
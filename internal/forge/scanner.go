@@ -0,0 +1,137 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/repository"
+	"github.com/hrexed/github-radar/internal/scoring"
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// Scanner collects repos on non-GitHub forges into the state store,
+// scoring them the way the GitHub scanner does.
+type Scanner struct {
+	providers  map[string]Provider
+	store      *state.Store
+	calculator *scoring.Calculator
+	onLog      func(level, msg string, args ...interface{})
+	now        func() time.Time
+}
+
+// ScanResult counts the outcome of a forge scan.
+type ScanResult struct {
+	Total      int
+	Successful int
+	Failed     int
+	Skipped    int
+	RepoGone   int
+
+	FailedRepos []string
+	GoneRepos   []string
+}
+
+// NewScanner creates a scanner over store with no providers.
+func NewScanner(store *state.Store) *Scanner {
+	return &Scanner{
+		providers:  make(map[string]Provider),
+		store:      store,
+		calculator: scoring.NewCalculatorWithDefaults(),
+		now:        time.Now,
+	}
+}
+
+// AddProvider registers p for repos keyed with p.Name().
+func (s *Scanner) AddProvider(p Provider) {
+	s.providers[p.Name()] = p
+}
+
+// Provider returns the provider registered under name.
+func (s *Scanner) Provider(name string) (Provider, bool) {
+	p, ok := s.providers[name]
+	return p, ok
+}
+
+// SetScoringWeights sets custom scoring weights.
+func (s *Scanner) SetScoringWeights(weights scoring.Weights) {
+	s.calculator = scoring.NewCalculator(weights)
+}
+
+// SetLogger sets a logging callback.
+func (s *Scanner) SetLogger(fn func(level, msg string, args ...interface{})) {
+	s.onLog = fn
+}
+
+func (s *Scanner) log(level, msg string, args ...interface{}) {
+	if s.onLog != nil {
+		s.onLog(level, msg, args...)
+	}
+}
+
+// Scan collects each repo from its provider and updates its state.
+// Repos whose provider is not registered fail; once a provider's rate
+// limit is exhausted its remaining repos are skipped until the next
+// scan.
+func (s *Scanner) Scan(ctx context.Context, repos []repository.Repo) (*ScanResult, error) {
+	result := &ScanResult{}
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Total++
+		key := repo.FullName()
+
+		p, ok := s.providers[repo.Provider]
+		if !ok {
+			result.Failed++
+			result.FailedRepos = append(result.FailedRepos, key)
+			s.log("warn", "No forge configured for repo", "repo", key, "provider", repo.Provider)
+			continue
+		}
+		if rl := p.RateLimit(); rl.Exhausted(s.now()) {
+			result.Skipped++
+			s.log("debug", "Forge rate limit exhausted, skipping repo", "repo", key, "reset", rl.Reset)
+			continue
+		}
+
+		meta, err := p.GetRepository(ctx, repo.Owner, repo.Name)
+		if errors.Is(err, ErrNotFound) {
+			result.RepoGone++
+			result.GoneRepos = append(result.GoneRepos, key)
+			s.log("info", "Repo not found on forge, classified as repo_gone", "repo", key)
+			continue
+		}
+		if err != nil {
+			result.Failed++
+			result.FailedRepos = append(result.FailedRepos, key)
+			s.log("warn", "Failed to collect repo", "repo", key, "error", err)
+			continue
+		}
+
+		activity, err := p.GetActivity(ctx, repo.Owner, repo.Name)
+		if err != nil {
+			s.log("warn", "Partial activity for repo", "repo", key, "error", err)
+		}
+
+		snap := Snapshot{
+			Stars:     meta.Stars,
+			Forks:     meta.Forks,
+			Collected: s.now(),
+		}
+		if activity != nil {
+			snap.Contributors = activity.Contributors
+			snap.MergedPRs7d = activity.MergedPRs7d
+			snap.NewIssues7d = activity.NewIssues7d
+			snap.LatestRelease = activity.LatestRelease
+		}
+
+		next := NextState(s.calculator, s.store.GetRepoState(key), snap)
+		next.Provider = repo.Provider
+		next.Owner = repo.Owner
+		next.Name = repo.Name
+		s.store.SetRepoState(key, next)
+		result.Successful++
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/repository"
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// fakeProvider serves repos from a map keyed owner/name.
+type fakeProvider struct {
+	name      string
+	repos     map[string]Repository
+	activity  Activity
+	rateLimit RateLimit
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) GetRepository(_ context.Context, owner, name string) (*Repository, error) {
+	r, ok := f.repos[owner+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, owner, name)
+	}
+	return &r, nil
+}
+
+func (f *fakeProvider) GetActivity(context.Context, string, string) (*Activity, error) {
+	a := f.activity
+	return &a, nil
+}
+
+func (f *fakeProvider) GetReadme(context.Context, string, string) (*Readme, error) {
+	return &Readme{}, nil
+}
+
+func (f *fakeProvider) SearchRepositories(context.Context, string, int) ([]Repository, error) {
+	return nil, nil
+}
+
+func (f *fakeProvider) RateLimit() RateLimit { return f.rateLimit }
+
+func TestScanner_Scan(t *testing.T) {
+	now := time.Now()
+	store := state.NewStore(t.TempDir() + "/state.json")
+	store.SetRepoState("gitlab:gnome/world/podcasts", state.RepoState{
+		Provider: "gitlab", Owner: "gnome/world", Name: "podcasts",
+		Stars: 100, LastCollected: now.Add(-24 * time.Hour),
+	})
+
+	s := NewScanner(store)
+	s.now = func() time.Time { return now }
+	s.AddProvider(&fakeProvider{
+		name:     "gitlab",
+		repos:    map[string]Repository{"gnome/world/podcasts": {Stars: 120, Forks: 30}},
+		activity: Activity{MergedPRs7d: 5, LatestRelease: now.Add(-time.Hour)},
+	})
+
+	result, err := s.Scan(context.Background(), []repository.Repo{
+		{Provider: "gitlab", Owner: "gnome/world", Name: "podcasts"},
+		{Provider: "gitlab", Owner: "gnome", Name: "gone"},
+		{Provider: "codeberg", Owner: "forgejo", Name: "forgejo"},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if result.Total != 3 || result.Successful != 1 || result.RepoGone != 1 || result.Failed != 1 {
+		t.Errorf("Scan() = %+v", result)
+	}
+	if len(result.GoneRepos) != 1 || result.GoneRepos[0] != "gitlab:gnome/gone" {
+		t.Errorf("GoneRepos = %v", result.GoneRepos)
+	}
+
+	rs := store.GetRepoState("gitlab:gnome/world/podcasts")
+	if rs == nil {
+		t.Fatal("scanned repo has no state")
+	}
+	if rs.Provider != "gitlab" || rs.Stars != 120 || rs.StarsPrev != 100 || rs.MergedPRs7d != 5 {
+		t.Errorf("state = %+v", rs)
+	}
+	if rs.StarVelocity <= 0 || rs.GrowthScore <= 0 || len(rs.RecentReleaseDates) != 1 {
+		t.Errorf("state was not scored: %+v", rs)
+	}
+}
+
+func TestScanner_SkipsExhaustedProvider(t *testing.T) {
+	store := state.NewStore(t.TempDir() + "/state.json")
+	s := NewScanner(store)
+	s.AddProvider(&fakeProvider{
+		name:      "codeberg",
+		repos:     map[string]Repository{"forgejo/forgejo": {Stars: 900}},
+		rateLimit: RateLimit{Limit: 100, Remaining: 0, Reset: time.Now().Add(time.Minute)},
+	})
+
+	result, _ := s.Scan(context.Background(), []repository.Repo{{Provider: "codeberg", Owner: "forgejo", Name: "forgejo"}})
+	if result.Skipped != 1 || result.Successful != 0 {
+		t.Errorf("Scan() = %+v, want the repo skipped", result)
+	}
+	if store.GetRepoState("codeberg:forgejo/forgejo") != nil {
+		t.Error("skipped repo was written to the store")
+	}
+}
@@ -0,0 +1,89 @@
+package forge
+
+import (
+	"time"
+
+	"github.com/hrexed/github-radar/internal/scoring"
+	"github.com/hrexed/github-radar/internal/state"
+)
+
+// maxRecentReleases bounds RepoState.RecentReleaseDates.
+const maxRecentReleases = 10
+
+// Snapshot is one observation of a repository's counters.
+type Snapshot struct {
+	Stars        int
+	Forks        int
+	Contributors int
+	MergedPRs7d  int
+	NewIssues7d  int
+	// LatestRelease is zero when no release was observed.
+	LatestRelease time.Time
+	Collected     time.Time
+}
+
+// NextState folds snap into the repository's previous state (nil for a
+// new repo): it carries the release history forward, derives the
+// velocities against prev and scores them with calc. Identity and
+// conditional-request fields are left for the caller to set.
+func NextState(calc *scoring.Calculator, prev *state.RepoState, snap Snapshot) state.RepoState {
+	next := state.RepoState{
+		Stars:         snap.Stars,
+		Forks:         snap.Forks,
+		Contributors:  snap.Contributors,
+		MergedPRs7d:   snap.MergedPRs7d,
+		NewIssues7d:   snap.NewIssues7d,
+		LastCollected: snap.Collected,
+	}
+
+	// Carry forward release history, then prepend a newly observed
+	// release.
+	if prev != nil {
+		next.LatestReleaseAt = prev.LatestReleaseAt
+		if len(prev.RecentReleaseDates) > 0 {
+			next.RecentReleaseDates = append(next.RecentReleaseDates, prev.RecentReleaseDates...)
+		}
+	}
+	if latest := snap.LatestRelease; !latest.IsZero() && !latest.Equal(next.LatestReleaseAt) {
+		next.RecentReleaseDates = append([]time.Time{latest}, next.RecentReleaseDates...)
+		if len(next.RecentReleaseDates) > maxRecentReleases {
+			next.RecentReleaseDates = next.RecentReleaseDates[:maxRecentReleases]
+		}
+		next.LatestReleaseAt = latest
+	}
+
+	metrics := scoring.RepoMetrics{
+		Stars:              next.Stars,
+		Forks:              next.Forks,
+		Contributors:       next.Contributors,
+		MergedPRs7d:        next.MergedPRs7d,
+		NewIssues7d:        next.NewIssues7d,
+		RecentReleaseDates: next.RecentReleaseDates,
+		Now:                snap.Collected,
+	}
+
+	// Include previous state for velocity calculations
+	if prev != nil && !prev.LastCollected.IsZero() {
+		metrics.StarsPrev = prev.Stars
+		metrics.ForksPrev = prev.Forks
+		metrics.ContributorsPrev = prev.Contributors
+		metrics.DaysElapsed = snap.Collected.Sub(prev.LastCollected).Hours() / 24
+		metrics.PrevStarVelocity = prev.StarVelocity
+
+		next.StarsPrev = prev.Stars
+		next.ForksPrev = prev.Forks
+		next.ContributorsPrev = prev.Contributors
+	}
+
+	velocities := calc.CalculateVelocities(metrics)
+	next.StarVelocity = velocities.StarVelocity
+	next.StarAcceleration = velocities.StarAcceleration
+	next.ForkVelocity = velocities.ForkVelocity
+	next.ReleaseCadence = velocities.ReleaseCadence
+	next.PRVelocity = velocities.PRVelocity
+	next.IssueVelocity = velocities.IssueVelocity
+	next.ContributorGrowth = velocities.ContributorGrowth
+
+	next.GrowthScore = calc.CalculateRawScore(velocities)
+	return next
+}
@@ -0,0 +1,491 @@
+// Package gharchive holds code shared by everything that reads the
+// hourly gharchive.org archives: the metrics fallback collector
+// (metrics.HourlyArchiveCollector) and the discovery firehose
+// (discovery.GHArchiveSource).
+//
+// Cache is a content-addressed on-disk store of archive files with a
+// size cap and LRU eviction. Both readers fetch through one Cache, so an
+// hour downloaded by one is free for the other, and reprocessing,
+// backfills and tests can run without network access (Offline).
+package gharchive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxBytes caps the cache at 20 GiB — roughly a week of
+// hourly archives — when CacheConfig.MaxBytes is zero.
+const DefaultCacheMaxBytes int64 = 20 << 30
+
+// indexFlushInterval bounds how long access times recorded by cache
+// hits stay in memory only. Stores and evictions save the index at
+// once; hits alone save it at most this often, and Close saves the rest.
+const indexFlushInterval = time.Minute
+
+// ErrNotCached is returned in offline mode for an archive that is in
+// neither the cache nor a seed directory.
+var ErrNotCached = errors.New("gharchive: archive not available offline")
+
+// FetchFunc downloads one archive from the origin. The cache calls it
+// on a miss and stores what it returns.
+type FetchFunc func(ctx context.Context) (io.ReadCloser, error)
+
+// CacheConfig configures a Cache.
+type CacheConfig struct {
+	// Dir is the cache directory. Empty runs the cache read-only over
+	// SeedDirs (only useful together with Offline).
+	Dir string
+
+	// MaxBytes is the size cap. Least recently used archives are
+	// evicted once the cache grows past it. Zero falls back to
+	// DefaultCacheMaxBytes.
+	MaxBytes int64
+
+	// Offline forbids downloads: archives come from the cache or
+	// SeedDirs only, and anything else fails with ErrNotCached.
+	Offline bool
+
+	// SeedDirs are pre-populated directories of `<archive>.json.gz`
+	// files (e.g. a mirror or a test fixture tree). They are read in
+	// place, never copied into or evicted by the cache.
+	SeedDirs []string
+}
+
+// CacheStats is a point-in-time view of cache activity.
+type CacheStats struct {
+	Archives  int
+	Bytes     int64
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+// cacheEntry is one archive in the index. Several archives can share
+// a digest (identical bytes) and therefore one object file.
+type cacheEntry struct {
+	Digest     string    `json:"digest"`
+	Size       int64     `json:"size"`
+	LastAccess time.Time `json:"last_access"`
+}
+
+// cacheIndex is the on-disk index format.
+type cacheIndex struct {
+	Archives map[string]*cacheEntry `json:"archives"`
+}
+
+// Cache is a content-addressed archive store. Safe for concurrent use;
+// concurrent misses on the same archive download it once. Close it on
+// shutdown so the latest access times reach the index.
+//
+// Layout under Dir:
+//
+//	index.json                  archive name → digest, size, last access
+//	objects/ab/<sha256>.json.gz archive bytes, named by content digest
+//	tmp/                        in-flight downloads
+type Cache struct {
+	cfg   CacheConfig
+	nowFn func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*cacheEntry
+	refs     map[string]int // digest → number of archives using it
+	size     int64          // bytes of distinct objects
+	inflight map[string]chan struct{}
+	stats    CacheStats
+
+	// dirty is set when entries hold access times index.json does not;
+	// savedAt is when index.json was last written.
+	dirty   bool
+	savedAt time.Time
+}
+
+// NewCache opens (or creates) the cache described by cfg. Index entries
+// whose object file has gone missing are dropped, and objects no entry
+// refers to are deleted.
+func NewCache(cfg CacheConfig) (*Cache, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultCacheMaxBytes
+	}
+	c := &Cache{
+		cfg:      cfg,
+		nowFn:    func() time.Time { return time.Now().UTC() },
+		entries:  make(map[string]*cacheEntry),
+		refs:     make(map[string]int),
+		inflight: make(map[string]chan struct{}),
+	}
+	if cfg.Dir == "" {
+		return c, nil
+	}
+
+	for _, sub := range []string{"objects", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(cfg.Dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("creating archive cache dir: %w", err)
+		}
+	}
+	// Leftovers from an interrupted download are never valid.
+	if tmp, err := os.ReadDir(filepath.Join(cfg.Dir, "tmp")); err == nil {
+		for _, e := range tmp {
+			_ = os.Remove(filepath.Join(cfg.Dir, "tmp", e.Name()))
+		}
+	}
+
+	raw, err := os.ReadFile(c.indexPath())
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("reading archive cache index: %w", err)
+	default:
+		var idx cacheIndex
+		if err := json.Unmarshal(raw, &idx); err != nil {
+			return nil, fmt.Errorf("decoding archive cache index: %w", err)
+		}
+		for name, e := range idx.Archives {
+			if e == nil || e.Digest == "" {
+				continue
+			}
+			if _, err := os.Stat(c.objectPath(e.Digest)); err != nil {
+				continue
+			}
+			c.entries[name] = e
+			if c.refs[e.Digest] == 0 {
+				c.size += e.Size
+			}
+			c.refs[e.Digest]++
+		}
+	}
+	c.removeOrphans()
+	c.mu.Lock()
+	c.evictLocked("")
+	err = c.saveIndexLocked()
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Offline reports whether the cache refuses downloads.
+func (c *Cache) Offline() bool {
+	return c != nil && c.cfg.Offline
+}
+
+// Contains reports whether archive can be served without a download.
+func (c *Cache) Contains(archive string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	_, ok := c.entries[archive]
+	c.mu.Unlock()
+	if ok {
+		return true
+	}
+	for _, dir := range c.cfg.SeedDirs {
+		if dir == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, archive+".json.gz")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns the current cache statistics.
+func (c *Cache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Archives = len(c.entries)
+	s.Bytes = c.size
+	return s
+}
+
+// Open returns the body of archive (e.g. "2026-05-10-12"). It is served
+// from the cache or a seed directory when possible (cached=true);
+// otherwise fetch downloads it, the bytes are stored, and the stored
+// copy is returned. A nil Cache simply calls fetch.
+func (c *Cache) Open(ctx context.Context, archive string, fetch FetchFunc) (rc io.ReadCloser, cached bool, err error) {
+	if c == nil {
+		rc, err = fetch(ctx)
+		return rc, false, err
+	}
+
+	for {
+		if rc, ok := c.lookup(archive); ok {
+			return rc, true, nil
+		}
+		if rc, ok := c.openSeed(archive); ok {
+			c.mu.Lock()
+			c.stats.Hits++
+			c.mu.Unlock()
+			return rc, true, nil
+		}
+		if c.cfg.Offline || fetch == nil {
+			return nil, false, fmt.Errorf("%s: %w", archive, ErrNotCached)
+		}
+
+		c.mu.Lock()
+		wait, busy := c.inflight[archive]
+		if !busy {
+			done := make(chan struct{})
+			c.inflight[archive] = done
+			c.stats.Misses++
+			c.mu.Unlock()
+
+			rc, err := c.download(ctx, archive, fetch)
+
+			c.mu.Lock()
+			delete(c.inflight, archive)
+			c.mu.Unlock()
+			close(done)
+			return rc, false, err
+		}
+		c.mu.Unlock()
+
+		// Another caller is downloading this archive; wait and look
+		// again. If its download failed we try ourselves.
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// lookup opens archive from the cache and marks it used. The new
+// access time reaches index.json with the next save, not on every hit.
+func (c *Cache) lookup(archive string) (io.ReadCloser, bool) {
+	if c.cfg.Dir == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[archive]
+	if !ok {
+		return nil, false
+	}
+	f, err := os.Open(c.objectPath(e.Digest))
+	if err != nil {
+		// Deleted behind our back; forget it and fall through to a
+		// fresh download.
+		c.dropLocked(archive)
+		_ = c.saveIndexLocked()
+		return nil, false
+	}
+	e.LastAccess = c.nowFn()
+	c.stats.Hits++
+	c.dirty = true
+	if e.LastAccess.Sub(c.savedAt) >= indexFlushInterval {
+		_ = c.saveIndexLocked()
+	}
+	return f, true
+}
+
+// Close writes access times still held in memory to the index. The
+// cache stays usable; a nil Cache has nothing to write.
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	return c.saveIndexLocked()
+}
+
+// openSeed opens archive from the first seed directory holding it.
+func (c *Cache) openSeed(archive string) (io.ReadCloser, bool) {
+	for _, dir := range c.cfg.SeedDirs {
+		if dir == "" {
+			continue
+		}
+		if f, err := os.Open(filepath.Join(dir, archive+".json.gz")); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// download fetches archive into tmp/ while hashing it, then moves it to
+// its content address and records it.
+func (c *Cache) download(ctx context.Context, archive string, fetch FetchFunc) (io.ReadCloser, error) {
+	body, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.Dir == "" {
+		// Read-only cache (seed directories only): nothing to store.
+		return body, nil
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Join(c.cfg.Dir, "tmp"), archive+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("caching %s: %w", archive, err)
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("downloading %s: %w", archive, err)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	if size > c.cfg.MaxBytes {
+		// Larger than the whole cache: serve it once and let it go.
+		f, err := os.Open(tmp.Name())
+		_ = os.Remove(tmp.Name())
+		if err != nil {
+			return nil, fmt.Errorf("caching %s: %w", archive, err)
+		}
+		return f, nil
+	}
+
+	obj := c.objectPath(digest)
+	if err := os.MkdirAll(filepath.Dir(obj), 0o755); err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("caching %s: %w", archive, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refs[digest] > 0 {
+		// Identical bytes already stored under another name.
+		_ = os.Remove(tmp.Name())
+	} else if err := os.Rename(tmp.Name(), obj); err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("caching %s: %w", archive, err)
+	}
+
+	c.dropLocked(archive)
+	c.entries[archive] = &cacheEntry{Digest: digest, Size: size, LastAccess: c.nowFn()}
+	if c.refs[digest] == 0 {
+		c.size += size
+	}
+	c.refs[digest]++
+
+	// Open before evicting so the new object is pinned even if it is
+	// the only thing left to evict.
+	f, err := os.Open(obj)
+	if err != nil {
+		return nil, fmt.Errorf("caching %s: %w", archive, err)
+	}
+	c.evictLocked(archive)
+	if err := c.saveIndexLocked(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// dropLocked removes archive from the index, deleting its object when
+// no other archive shares it. Caller holds c.mu.
+func (c *Cache) dropLocked(archive string) {
+	e, ok := c.entries[archive]
+	if !ok {
+		return
+	}
+	delete(c.entries, archive)
+	c.refs[e.Digest]--
+	if c.refs[e.Digest] <= 0 {
+		delete(c.refs, e.Digest)
+		c.size -= e.Size
+		_ = os.Remove(c.objectPath(e.Digest))
+	}
+}
+
+// evictLocked drops least recently used archives until the cache fits
+// MaxBytes. keep is never evicted. Caller holds c.mu.
+func (c *Cache) evictLocked(keep string) {
+	if c.size <= c.cfg.MaxBytes {
+		return
+	}
+	names := make([]string, 0, len(c.entries))
+	for name := range c.entries {
+		if name != keep {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ai, aj := c.entries[names[i]].LastAccess, c.entries[names[j]].LastAccess
+		if !ai.Equal(aj) {
+			return ai.Before(aj)
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		if c.size <= c.cfg.MaxBytes {
+			return
+		}
+		c.dropLocked(name)
+		c.stats.Evictions++
+	}
+}
+
+// removeOrphans deletes object files no index entry refers to.
+func (c *Cache) removeOrphans() {
+	root := filepath.Join(c.cfg.Dir, "objects")
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		digest := strings.TrimSuffix(d.Name(), ".json.gz")
+		if c.refs[digest] == 0 {
+			_ = os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// saveIndexLocked writes index.json atomically. Caller holds c.mu.
+func (c *Cache) saveIndexLocked() error {
+	if c.cfg.Dir == "" {
+		return nil
+	}
+	raw, err := json.Marshal(cacheIndex{Archives: c.entries})
+	if err != nil {
+		return fmt.Errorf("encoding archive cache index: %w", err)
+	}
+	tmp := c.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("writing archive cache index: %w", err)
+	}
+	if err := os.Rename(tmp, c.indexPath()); err != nil {
+		return fmt.Errorf("writing archive cache index: %w", err)
+	}
+	c.dirty = false
+	c.savedAt = c.nowFn()
+	return nil
+}
+
+func (c *Cache) indexPath() string {
+	return filepath.Join(c.cfg.Dir, "index.json")
+}
+
+func (c *Cache) objectPath(digest string) string {
+	return filepath.Join(c.cfg.Dir, "objects", digest[:2], digest+".json.gz")
+}
@@ -0,0 +1,312 @@
+package gharchive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingFetch returns a FetchFunc serving body and counting calls.
+func countingFetch(body string, calls *int32) FetchFunc {
+	return func(context.Context) (io.ReadCloser, error) {
+		atomic.AddInt32(calls, 1)
+		return io.NopCloser(strings.NewReader(body)), nil
+	}
+}
+
+func readAll(t *testing.T, rc io.ReadCloser) string {
+	t.Helper()
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(b)
+}
+
+func mustCache(t *testing.T, cfg CacheConfig) *Cache {
+	t.Helper()
+	c, err := NewCache(cfg)
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	return c
+}
+
+func TestCache_MissThenHit(t *testing.T) {
+	c := mustCache(t, CacheConfig{Dir: t.TempDir()})
+	var calls int32
+	fetch := countingFetch("archive-bytes", &calls)
+
+	rc, cached, err := c.Open(context.Background(), "2026-05-10-12", fetch)
+	if err != nil || cached {
+		t.Fatalf("first Open: cached=%v err=%v, want miss", cached, err)
+	}
+	if got := readAll(t, rc); got != "archive-bytes" {
+		t.Errorf("first body = %q", got)
+	}
+
+	rc, cached, err = c.Open(context.Background(), "2026-05-10-12", fetch)
+	if err != nil || !cached {
+		t.Fatalf("second Open: cached=%v err=%v, want hit", cached, err)
+	}
+	if got := readAll(t, rc); got != "archive-bytes" {
+		t.Errorf("second body = %q", got)
+	}
+	if calls != 1 {
+		t.Errorf("fetch calls = %d, want 1", calls)
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 1 || s.Archives != 1 || s.Bytes != int64(len("archive-bytes")) {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestCache_ContentAddressedDedupe(t *testing.T) {
+	dir := t.TempDir()
+	c := mustCache(t, CacheConfig{Dir: dir})
+	var calls int32
+	for _, name := range []string{"2026-05-10-12", "2026-05-10-13"} {
+		rc, _, err := c.Open(context.Background(), name, countingFetch("same", &calls))
+		if err != nil {
+			t.Fatalf("Open %s: %v", name, err)
+		}
+		rc.Close()
+	}
+	if s := c.Stats(); s.Archives != 2 || s.Bytes != 4 {
+		t.Errorf("stats = %+v, want 2 archives sharing 4 bytes", s)
+	}
+
+	var objects int
+	filepath.Walk(filepath.Join(dir, "objects"), func(_ string, info os.FileInfo, _ error) error {
+		if info != nil && !info.IsDir() {
+			objects++
+		}
+		return nil
+	})
+	if objects != 1 {
+		t.Errorf("object files = %d, want 1", objects)
+	}
+}
+
+func TestCache_LRUEviction(t *testing.T) {
+	c := mustCache(t, CacheConfig{Dir: t.TempDir(), MaxBytes: 10})
+	now := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	c.nowFn = func() time.Time { now = now.Add(time.Minute); return now }
+
+	var calls int32
+	open := func(name, body string) {
+		t.Helper()
+		rc, _, err := c.Open(context.Background(), name, countingFetch(body, &calls))
+		if err != nil {
+			t.Fatalf("Open %s: %v", name, err)
+		}
+		rc.Close()
+	}
+
+	open("a", "aaaa")
+	open("b", "bbbb")
+	open("a", "aaaa") // touch a: b is now least recently used
+	open("c", "cccc") // 12 bytes > 10: evict b
+
+	if c.Contains("b") {
+		t.Error("b should have been evicted")
+	}
+	if !c.Contains("a") || !c.Contains("c") {
+		t.Error("a and c should remain cached")
+	}
+	if s := c.Stats(); s.Evictions != 1 || s.Bytes != 8 {
+		t.Errorf("stats = %+v, want 1 eviction and 8 bytes", s)
+	}
+}
+
+func TestCache_OversizedArchiveServedNotStored(t *testing.T) {
+	c := mustCache(t, CacheConfig{Dir: t.TempDir(), MaxBytes: 2})
+	var calls int32
+	rc, cached, err := c.Open(context.Background(), "big", countingFetch("too large", &calls))
+	if err != nil || cached {
+		t.Fatalf("Open: cached=%v err=%v", cached, err)
+	}
+	if got := readAll(t, rc); got != "too large" {
+		t.Errorf("body = %q", got)
+	}
+	if c.Contains("big") {
+		t.Error("oversized archive should not be cached")
+	}
+}
+
+func TestCache_PersistsAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+	var calls int32
+	c := mustCache(t, CacheConfig{Dir: dir})
+	rc, _, err := c.Open(context.Background(), "2026-05-10-12", countingFetch("kept", &calls))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	rc.Close()
+
+	// An orphan object and a stale temp file are cleaned up on open.
+	orphan := filepath.Join(dir, "objects", "ff", "ff00.json.gz")
+	os.MkdirAll(filepath.Dir(orphan), 0o755)
+	os.WriteFile(orphan, []byte("x"), 0o644)
+	os.WriteFile(filepath.Join(dir, "tmp", "partial"), []byte("x"), 0o644)
+
+	c2 := mustCache(t, CacheConfig{Dir: dir, Offline: true})
+	rc, cached, err := c2.Open(context.Background(), "2026-05-10-12", nil)
+	if err != nil || !cached {
+		t.Fatalf("reopened Open: cached=%v err=%v", cached, err)
+	}
+	if got := readAll(t, rc); got != "kept" {
+		t.Errorf("body = %q", got)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Error("orphan object should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tmp", "partial")); !os.IsNotExist(err) {
+		t.Error("stale temp file should be removed")
+	}
+}
+
+func TestCache_OfflineSeedDirs(t *testing.T) {
+	seed := t.TempDir()
+	os.WriteFile(filepath.Join(seed, "2026-05-10-12.json.gz"), []byte("seeded"), 0o644)
+
+	c := mustCache(t, CacheConfig{Offline: true, SeedDirs: []string{seed}})
+	var calls int32
+	fetch := countingFetch("network", &calls)
+
+	rc, cached, err := c.Open(context.Background(), "2026-05-10-12", fetch)
+	if err != nil || !cached {
+		t.Fatalf("seeded Open: cached=%v err=%v", cached, err)
+	}
+	if got := readAll(t, rc); got != "seeded" {
+		t.Errorf("body = %q", got)
+	}
+
+	_, _, err = c.Open(context.Background(), "2026-05-10-13", fetch)
+	if !errors.Is(err, ErrNotCached) {
+		t.Errorf("missing offline archive: err = %v, want ErrNotCached", err)
+	}
+	if calls != 0 {
+		t.Errorf("offline cache fetched %d times", calls)
+	}
+}
+
+func TestCache_ConcurrentMissesFetchOnce(t *testing.T) {
+	c := mustCache(t, CacheConfig{Dir: t.TempDir()})
+	var calls int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (io.ReadCloser, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return io.NopCloser(strings.NewReader("shared")), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rc, _, err := c.Open(context.Background(), "2026-05-10-12", fetch)
+			if err != nil {
+				t.Errorf("Open: %v", err)
+				return
+			}
+			if got := readAll(t, rc); got != "shared" {
+				t.Errorf("body = %q", got)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("fetch calls = %d, want 1", calls)
+	}
+}
+
+func TestCache_FetchErrorNotStored(t *testing.T) {
+	c := mustCache(t, CacheConfig{Dir: t.TempDir()})
+	boom := errors.New("404")
+	_, _, err := c.Open(context.Background(), "x", func(context.Context) (io.ReadCloser, error) { return nil, boom })
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want fetch error", err)
+	}
+	if c.Contains("x") {
+		t.Error("failed fetch should not be cached")
+	}
+}
+
+func TestCache_NilPassesThrough(t *testing.T) {
+	var c *Cache
+	var calls int32
+	rc, cached, err := c.Open(context.Background(), "x", countingFetch("direct", &calls))
+	if err != nil || cached {
+		t.Fatalf("nil cache Open: cached=%v err=%v", cached, err)
+	}
+	if got := readAll(t, rc); got != "direct" {
+		t.Errorf("body = %q", got)
+	}
+	if c.Offline() || c.Contains("x") {
+		t.Error("nil cache should be online and empty")
+	}
+}
+
+func TestCache_HitsBatchIndexWrites(t *testing.T) {
+	dir := t.TempDir()
+	c := mustCache(t, CacheConfig{Dir: dir})
+	now := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	c.nowFn = func() time.Time { return now }
+
+	var calls int32
+	open := func() {
+		t.Helper()
+		rc, _, err := c.Open(context.Background(), "2026-05-10-12", countingFetch("bytes", &calls))
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		rc.Close()
+	}
+	stored := func() time.Time {
+		t.Helper()
+		raw, err := os.ReadFile(filepath.Join(dir, "index.json"))
+		if err != nil {
+			t.Fatalf("reading index: %v", err)
+		}
+		var ci cacheIndex
+		if err := json.Unmarshal(raw, &ci); err != nil {
+			t.Fatalf("decoding index: %v", err)
+		}
+		return ci.Archives["2026-05-10-12"].LastAccess
+	}
+
+	open() // miss: stored and saved at once
+	stored0 := stored()
+
+	now = now.Add(10 * time.Second)
+	open() // hit within the flush interval: kept in memory
+	if got := stored(); !got.Equal(stored0) {
+		t.Errorf("index written on a hit: last_access %v, want %v", got, stored0)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := stored(); !got.Equal(now) {
+		t.Errorf("after Close last_access = %v, want %v", got, now)
+	}
+
+	now = now.Add(indexFlushInterval)
+	open() // a hit past the interval saves
+	if got := stored(); !got.Equal(now) {
+		t.Errorf("after interval last_access = %v, want %v", got, now)
+	}
+}
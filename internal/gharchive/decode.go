@@ -0,0 +1,125 @@
+package gharchive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/logging"
+)
+
+// ArchiveLayout is the gharchive filename (and cursor) format.
+const ArchiveLayout = "2006-01-02-15"
+
+// ArchiveName returns the name of the archive covering the hour that
+// contains t.
+func ArchiveName(t time.Time) string {
+	return t.UTC().Truncate(time.Hour).Format(ArchiveLayout)
+}
+
+// ParseArchiveName parses an archive name back to its UTC hour.
+func ParseArchiveName(archive string) (time.Time, error) {
+	return time.ParseInLocation(ArchiveLayout, archive, time.UTC)
+}
+
+// Event is the envelope decoded once per archive line and shown to every
+// consumer. Only the fields every consumer needs are decoded eagerly;
+// payloads are decoded on demand with Unmarshal, so the cost is paid
+// only by consumers that want them and only for the events they keep.
+type Event struct {
+	Type  string `json:"type"`
+	Actor struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+	Repo struct {
+		Name string `json:"name"`
+	} `json:"repo"`
+	CreatedAt string `json:"created_at"`
+
+	line []byte
+}
+
+// Raw returns the event's original JSON line. It is only valid for the
+// duration of the ConsumeEvent call; copy it to keep it.
+func (e *Event) Raw() []byte {
+	return e.line
+}
+
+// Unmarshal decodes the full JSON line into v, typically a struct
+// carrying the `payload` fields a consumer cares about.
+func (e *Event) Unmarshal(v any) error {
+	return json.Unmarshal(e.line, v)
+}
+
+// DecodeStats counts what one Decode call saw.
+type DecodeStats struct {
+	// Events is the number of lines decoded into an Event.
+	Events int64
+	// Malformed counts lines that could not be decoded, plus the tail
+	// of an archive dropped after an oversized line.
+	Malformed int64
+}
+
+// Decode streams a gzipped NDJSON archive and calls fn for every event.
+// The Event passed to fn is reused between calls.
+//
+// A per-line bufio.Scanner is safer than json.Decoder here: json.Decoder
+// keeps internal state, so a single malformed token mid-archive can
+// silently drop the rest of the stream. Scanner + json.Unmarshal makes
+// each line independent.
+func Decode(ctx context.Context, body io.Reader, fn func(*Event)) (DecodeStats, error) {
+	var stats DecodeStats
+
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return stats, fmt.Errorf("gzip reader: %w", err)
+	}
+	defer gz.Close()
+
+	// Wrap the gzip stream in a 1 MiB buffered reader: ~600 MB
+	// uncompressed per archive, so larger reads cut syscall overhead.
+	scanner := bufio.NewScanner(bufio.NewReaderSize(gz, 1<<20))
+	// Default 64 KiB token cap is too small for some PullRequestEvent
+	// payloads (commit lists routinely run a few hundred KiB). Allow
+	// growth up to 8 MiB per line; anything larger is treated as a
+	// poison record (see scanner.Err handling below).
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+	var evt Event
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		evt = Event{}
+		if err := json.Unmarshal(line, &evt); err != nil {
+			// A single malformed line is independent of the rest.
+			stats.Malformed++
+			continue
+		}
+		evt.line = line
+		stats.Events++
+		fn(&evt)
+	}
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, bufio.ErrTooLong) {
+			return stats, fmt.Errorf("scan archive: %w", err)
+		}
+		logging.Warn("gharchive: dropping archive tail past oversized line",
+			"events", stats.Events, "malformed", stats.Malformed+1)
+		stats.Malformed++
+	}
+	return stats, nil
+}
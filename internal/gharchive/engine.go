@@ -0,0 +1,758 @@
+package gharchive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/cassette"
+	"github.com/hrexed/github-radar/internal/logging"
+)
+
+// engine.go is the single gharchive ingestion path. An Engine downloads
+// each hourly archive once, decodes it once, and fans every event out to
+// the registered consumers — the discovery window, the tracked-repo
+// metrics accumulator, and whatever detector comes next. The cursor and
+// the archive-level hooks belong to the engine, so they are shared by
+// all consumers instead of being duplicated per code path.
+
+// Engine defaults.
+const (
+	// DefaultBaseURL is the canonical archive host.
+	DefaultBaseURL = "https://data.gharchive.org"
+
+	// DefaultHTTPTimeout is the per-archive download timeout. Each
+	// archive is ~60-90 MB compressed; 60s leaves headroom on slow
+	// links.
+	DefaultHTTPTimeout = 60 * time.Second
+
+	// DefaultMaxRetries caps transient-error retries per archive.
+	DefaultMaxRetries = 3
+
+	// DefaultInitialBackoff is the first retry delay; each retry
+	// doubles up to MaxBackoff.
+	DefaultInitialBackoff = 1 * time.Second
+
+	// MaxBackoff is the cap on per-retry backoff.
+	MaxBackoff = 30 * time.Second
+
+	// PublishLag is the safety margin applied past the end of each hour
+	// before its archive is considered ready for fetch. An archive at
+	// hour H covers [H, H+1h) and is ready once
+	// `now >= H + 1h + PublishLag`.
+	PublishLag = 30 * time.Minute
+
+	// DefaultPoisonFailureThreshold caps how many consecutive Run
+	// cycles a single archive may fail before Run skips past it.
+	DefaultPoisonFailureThreshold = 5
+
+	// DefaultColdStartWindow is how far back Run starts when the cursor
+	// is empty.
+	DefaultColdStartWindow = 24 * time.Hour
+
+	// DefaultConcurrency is how many archives Run fetches and decodes
+	// at once. Each in-flight archive holds one hour of consumer
+	// scratch state until it is committed.
+	DefaultConcurrency = 4
+)
+
+// Cursor records the last fully processed archive. It advances only
+// after every consumer has finished the archive — never on download
+// start — so a crash mid-archive replays that archive.
+type Cursor struct {
+	// LastProcessedArchive is in YYYY-MM-DD-HH format (UTC). Empty
+	// when no archive has ever been processed.
+	LastProcessedArchive string
+
+	// CompletedAt is the wall-clock time the archive finished
+	// processing.
+	CompletedAt time.Time
+}
+
+// IsZero reports whether the cursor is empty (no archive processed yet).
+func (c Cursor) IsZero() bool {
+	return c.LastProcessedArchive == "" && c.CompletedAt.IsZero()
+}
+
+// Hour returns the parsed UTC hour represented by the cursor, or zero
+// time when the cursor is empty / malformed.
+func (c Cursor) Hour() time.Time {
+	if c.LastProcessedArchive == "" {
+		return time.Time{}
+	}
+	t, err := ParseArchiveName(c.LastProcessedArchive)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// CursorStore persists the engine cursor across restarts.
+type CursorStore interface {
+	GetCursor(ctx context.Context) (Cursor, error)
+	SetCursor(ctx context.Context, c Cursor) error
+}
+
+// Hooks is the archive-level callback surface. All hooks are optional;
+// a nil callback is a no-op.
+//
+//   - OnLagSeconds fires once per archive on entry; lag is wall-clock
+//     minus archive hour.
+//   - OnArchiveStart / OnArchiveComplete bracket per-archive work.
+//   - OnArchiveError fires per failed download attempt (1-based), and
+//     with attempt=0 when Run skips a poison archive.
+//
+// OnLagSeconds, OnArchiveStart and OnArchiveError may fire from several
+// goroutines at once when Run decodes archives concurrently.
+type Hooks struct {
+	OnLagSeconds      func(seconds float64)
+	OnArchiveStart    func(archive string)
+	OnArchiveComplete func(archive string, dur time.Duration)
+	OnArchiveError    func(archive string, attempt int, err error)
+}
+
+// HourSummary describes one decoded archive to HourConsumer.FinishHour.
+type HourSummary struct {
+	Archive string
+	Hour    time.Time
+	DecodeStats
+}
+
+// Consumer receives the events of every archive the engine processes.
+// BeginHour returns the accumulator for one archive. Run decodes
+// several archives at once, so BeginHour may be called concurrently and
+// for several hours before the first of them is finished; keep
+// per-hour state in the returned HourConsumer, not in the Consumer.
+type Consumer interface {
+	BeginHour(hour time.Time) HourConsumer
+}
+
+// HourConsumer accumulates one archive. ConsumeEvent is called for each
+// event on the goroutine decoding that archive. FinishHour is called
+// afterwards, on the engine's goroutine, strictly in hour order across
+// archives, so it is the place to merge into shared state. When
+// decoding fails part-way, or an earlier hour fails, FinishHour is not
+// called and the HourConsumer is dropped.
+type HourConsumer interface {
+	ConsumeEvent(evt *Event)
+	// FinishHour commits the archive. An error stops the cursor from
+	// advancing, so the archive is replayed for every consumer; make
+	// commits idempotent on (repo, hour).
+	FinishHour(ctx context.Context, sum HourSummary) error
+}
+
+// EngineConfig contains the engine knobs. Zero values fall back to the
+// Default* constants.
+type EngineConfig struct {
+	// BaseURL overrides the gharchive origin. Tests use
+	// httptest.Server.URL here.
+	BaseURL string
+
+	// HTTPTimeout is the per-archive download timeout.
+	HTTPTimeout time.Duration
+
+	// MaxRetries is the retry budget per archive across transient
+	// errors.
+	MaxRetries int
+
+	// InitialBackoff is the first retry delay.
+	InitialBackoff time.Duration
+
+	// PoisonFailureThreshold caps consecutive Run failures of one
+	// archive before Run advances past it. Negative is clamped to 1.
+	PoisonFailureThreshold int
+
+	// ColdStartWindow is how far back Run starts on an empty cursor.
+	ColdStartWindow time.Duration
+
+	// LocalDir, when set, is checked for `<archive>.json.gz` before
+	// the cache and BaseURL.
+	LocalDir string
+
+	// Concurrency bounds how many archives Run fetches and decodes at
+	// once. Archives are still committed one at a time, in order.
+	Concurrency int
+}
+
+func (c EngineConfig) withDefaults() EngineConfig {
+	if c.BaseURL == "" {
+		c.BaseURL = DefaultBaseURL
+	}
+	c.BaseURL = strings.TrimSuffix(c.BaseURL, "/")
+	if c.HTTPTimeout <= 0 {
+		c.HTTPTimeout = DefaultHTTPTimeout
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = DefaultMaxRetries
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultInitialBackoff
+	}
+	if c.PoisonFailureThreshold == 0 {
+		c.PoisonFailureThreshold = DefaultPoisonFailureThreshold
+	}
+	if c.PoisonFailureThreshold < 1 {
+		c.PoisonFailureThreshold = 1
+	}
+	if c.ColdStartWindow <= 0 {
+		c.ColdStartWindow = DefaultColdStartWindow
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = DefaultConcurrency
+	}
+	return c
+}
+
+// Engine downloads, decodes and fans out gharchive archives. Run and
+// ProcessArchive assume a single caller goroutine; Register may be
+// called at any time. Run fans the download and decode of consecutive
+// archives out to a bounded set of goroutines but commits them from
+// its own goroutine, in order.
+type Engine struct {
+	cfg    EngineConfig
+	client *http.Client
+	cursor CursorStore
+	hooks  Hooks
+	cache  *Cache
+
+	// nowFn is a clock indirection so tests can pin "current" time.
+	nowFn func() time.Time
+
+	// jitterFn produces a random duration in [0, max). Indirected so
+	// tests can pin retry timing.
+	jitterFn func(max time.Duration) time.Duration
+
+	// throttle, when it reports true, drops Run to one archive at a
+	// time. Consulted before each archive is started.
+	throttle func() bool
+
+	consumersMu sync.RWMutex
+	consumers   []Consumer
+
+	// poisonMu guards consecutiveFailures; hooks fire under callers'
+	// goroutines, so the tracker needs its own lock under -race.
+	poisonMu            sync.Mutex
+	consecutiveFailures map[string]int
+}
+
+// NewEngine constructs an engine. cursor must be non-nil.
+func NewEngine(cfg EngineConfig, cursor CursorStore, hooks Hooks) *Engine {
+	if cursor == nil {
+		panic("gharchive: NewEngine requires a non-nil cursor store")
+	}
+	cfg = cfg.withDefaults()
+	return &Engine{
+		cfg:                 cfg,
+		client:              &http.Client{Timeout: cfg.HTTPTimeout, Transport: cassette.Transport(cassette.ServiceGHArchive)},
+		cursor:              cursor,
+		hooks:               hooks,
+		nowFn:               func() time.Time { return time.Now().UTC() },
+		jitterFn:            func(max time.Duration) time.Duration { return time.Duration(rand.Int63n(int64(max + 1))) }, //nolint:gosec
+		consecutiveFailures: make(map[string]int),
+	}
+}
+
+// Register adds c to the consumers fed by every subsequent archive.
+func (e *Engine) Register(c Consumer) {
+	if c == nil {
+		return
+	}
+	e.consumersMu.Lock()
+	e.consumers = append(e.consumers, c)
+	e.consumersMu.Unlock()
+}
+
+// Cursor returns the engine's cursor store.
+func (e *Engine) Cursor() CursorStore {
+	return e.cursor
+}
+
+// Cache returns the archive cache, or nil.
+func (e *Engine) Cache() *Cache {
+	return e.cache
+}
+
+// SetClock overrides the wall clock.
+func (e *Engine) SetClock(now func() time.Time) {
+	if now != nil {
+		e.nowFn = now
+	}
+}
+
+// SetHTTPClient overrides the HTTP client.
+func (e *Engine) SetHTTPClient(c *http.Client) {
+	if c != nil {
+		e.client = c
+	}
+}
+
+// SetJitter overrides the retry-jitter function.
+func (e *Engine) SetJitter(jit func(max time.Duration) time.Duration) {
+	if jit != nil {
+		e.jitterFn = jit
+	}
+}
+
+// SetArchiveCache routes downloads through c. nil disables caching.
+func (e *Engine) SetArchiveCache(c *Cache) {
+	e.cache = c
+}
+
+// SetThrottle installs a check that, while it reports true, limits Run
+// to one archive in flight — e.g. the discovery backpressure gate, so a
+// paused pipeline does not also get a burst of concurrent downloads.
+// nil removes it. Set it before calling Run.
+func (e *Engine) SetThrottle(throttled func() bool) {
+	e.throttle = throttled
+}
+
+// concurrency is the number of archives Run may have in flight right
+// now.
+func (e *Engine) concurrency() int {
+	if e.throttle != nil && e.throttle() {
+		return 1
+	}
+	return e.cfg.Concurrency
+}
+
+// Run advances the cursor through every published archive, in order.
+// It starts at the cursor + 1h, or ColdStartWindow ago on an empty
+// cursor, and returns when ctx is cancelled or it reaches the leading
+// edge.
+//
+// Up to Concurrency archives are fetched and decoded at once, but they
+// are committed strictly in hour order, so the cursor only ever
+// advances over a contiguous run of completed hours. A failed archive
+// ends the run there: the hours after it are dropped and replayed by
+// the next Run. It is skipped after PoisonFailureThreshold consecutive
+// failing runs.
+func (e *Engine) Run(ctx context.Context) error {
+	cursor, err := e.cursor.GetCursor(ctx)
+	if err != nil {
+		return fmt.Errorf("loading gharchive cursor: %w", err)
+	}
+
+	startHour := e.nowFn().UTC().Add(-e.cfg.ColdStartWindow).Truncate(time.Hour)
+	if !cursor.IsZero() {
+		startHour = cursor.Hour().Add(time.Hour)
+	}
+	endHour := e.LatestPublishedHour()
+
+	var archives []string
+	for h := startHour; !h.After(endHour); h = h.Add(time.Hour) {
+		archives = append(archives, h.Format(ArchiveLayout))
+	}
+
+	return e.ProcessArchives(ctx, archives, ProcessOptions{
+		OnDone: func(archive string, err error) bool {
+			if err != nil {
+				logging.Warn("gharchive: archive failed; stopping run at the gap",
+					"archive", archive, "error", err)
+				return e.handleArchiveFailure(ctx, archive, err)
+			}
+			// Success clears any prior failure tally so transient
+			// blips never accumulate toward the poison threshold.
+			e.poisonMu.Lock()
+			delete(e.consecutiveFailures, archive)
+			e.poisonMu.Unlock()
+			return true
+		},
+	})
+}
+
+// ProcessOptions customises ProcessArchives. Both callbacks run on the
+// caller's goroutine.
+type ProcessOptions struct {
+	// BeforeStart is called before each archive's download is
+	// started, in order. A non-nil error aborts the run and is
+	// returned. Backfill uses it to pace downloads.
+	BeforeStart func(ctx context.Context, archive string) error
+
+	// OnDone receives every archive's outcome in order, after it was
+	// committed (err == nil) or failed. Returning false stops the run
+	// and drops the archives decoded ahead of it. A nil OnDone stops
+	// at the first failure.
+	OnDone func(archive string, err error) bool
+}
+
+// ProcessArchives processes archives, which must be in hour order, with
+// up to Concurrency of them downloading and decoding at once. Each is
+// committed — consumers finished, cursor advanced — strictly in order,
+// so the cursor never passes an archive that has not completed. It
+// returns ctx.Err() when ctx is cancelled.
+func (e *Engine) ProcessArchives(ctx context.Context, archives []string, opts ProcessOptions) error {
+	if len(archives) == 0 {
+		return nil
+	}
+	onDone := opts.OnDone
+	if onDone == nil {
+		onDone = func(_ string, err error) bool { return err == nil }
+	}
+
+	// Workers still running on an early return (failure, stop or
+	// cancellation) are stopped through the derived context and
+	// waited for, so no download outlives the call.
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	results := make([]chan decodeResult, len(archives))
+	started := 0
+	for i, archive := range archives {
+		// Keep at most concurrency() archives decoded or decoding
+		// ahead of the commit point. The window is re-evaluated per
+		// archive so a throttle takes effect mid-run.
+		for started < len(archives) && started-i < e.concurrency() {
+			if opts.BeforeStart != nil {
+				if err := opts.BeforeStart(ctx, archives[started]); err != nil {
+					return err
+				}
+			}
+			results[started] = make(chan decodeResult, 1)
+			wg.Add(1)
+			go func(archive string, out chan<- decodeResult) {
+				defer wg.Done()
+				d, err := e.decodeArchive(ctx, archive)
+				out <- decodeResult{decoded: d, err: err}
+			}(archives[started], results[started])
+			started++
+		}
+
+		res := <-results[i]
+		err := res.err
+		if err == nil {
+			err = e.commitArchive(ctx, res.decoded)
+		}
+		if err != nil && parent.Err() != nil {
+			return parent.Err()
+		}
+		if !onDone(archive, err) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// decodeResult carries one worker's output back to Run.
+type decodeResult struct {
+	decoded *decodedArchive
+	err     error
+}
+
+// decodedArchive is an archive fed to every consumer but not yet
+// committed.
+type decodedArchive struct {
+	archive string
+	hour    time.Time
+	start   time.Time
+	stats   DecodeStats
+	hours   []HourConsumer
+}
+
+// LatestPublishedHour is the newest archive hour that is safe to fetch.
+func (e *Engine) LatestPublishedHour() time.Time {
+	return e.nowFn().UTC().Add(-time.Hour - PublishLag).Truncate(time.Hour)
+}
+
+// handleArchiveFailure tracks consecutive Run failures per archive and
+// skips past a poison archive once the threshold is reached, so a
+// permanently undecodable archive cannot stall the cursor forever. It
+// reports whether the cursor now sits on archive.
+func (e *Engine) handleArchiveFailure(ctx context.Context, archive string, cause error) bool {
+	e.poisonMu.Lock()
+	e.consecutiveFailures[archive]++
+	failures := e.consecutiveFailures[archive]
+	e.poisonMu.Unlock()
+
+	if failures < e.cfg.PoisonFailureThreshold {
+		return false
+	}
+
+	skipErr := fmt.Errorf("poison archive: skipping after %d consecutive failures: %w", failures, cause)
+	logging.Warn("gharchive: skipping poison archive",
+		"archive", archive,
+		"consecutive_failures", failures,
+		"threshold", e.cfg.PoisonFailureThreshold,
+		"cause", cause)
+
+	if e.hooks.OnArchiveError != nil {
+		e.hooks.OnArchiveError(archive, 0, skipErr)
+	}
+
+	if err := e.cursor.SetCursor(ctx, Cursor{
+		LastProcessedArchive: archive,
+		CompletedAt:          e.nowFn().UTC(),
+	}); err != nil {
+		// Leave the failure count in place so the next cycle retries
+		// the skip.
+		logging.Warn("gharchive: failed to advance cursor past poison archive",
+			"archive", archive, "error", err)
+		return false
+	}
+
+	e.poisonMu.Lock()
+	delete(e.consecutiveFailures, archive)
+	e.poisonMu.Unlock()
+	return true
+}
+
+// ProcessArchive downloads one archive, decodes it once, feeds every
+// consumer, and advances the cursor once all of them have finished.
+// Idempotent on (repo, hour) so safe to call repeatedly.
+func (e *Engine) ProcessArchive(ctx context.Context, archive string) error {
+	d, err := e.decodeArchive(ctx, archive)
+	if err != nil {
+		return err
+	}
+	return e.commitArchive(ctx, d)
+}
+
+// decodeArchive downloads and decodes one archive into a fresh
+// HourConsumer per registered consumer. It touches no shared state
+// beyond the hooks, so Run calls it from several goroutines.
+func (e *Engine) decodeArchive(ctx context.Context, archive string) (*decodedArchive, error) {
+	hour, err := ParseArchiveName(archive)
+	if err != nil {
+		return nil, fmt.Errorf("parsing archive name %q: %w", archive, err)
+	}
+
+	if e.hooks.OnArchiveStart != nil {
+		e.hooks.OnArchiveStart(archive)
+	}
+	if e.hooks.OnLagSeconds != nil {
+		e.hooks.OnLagSeconds(e.nowFn().UTC().Sub(hour).Seconds())
+	}
+
+	start := time.Now()
+	body, err := e.OpenArchive(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	e.consumersMu.RLock()
+	consumers := append([]Consumer(nil), e.consumers...)
+	e.consumersMu.RUnlock()
+
+	hours := make([]HourConsumer, 0, len(consumers))
+	for _, c := range consumers {
+		if hc := c.BeginHour(hour); hc != nil {
+			hours = append(hours, hc)
+		}
+	}
+	stats, err := Decode(ctx, body, func(evt *Event) {
+		for _, hc := range hours {
+			hc.ConsumeEvent(evt)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("processing archive %s: %w", archive, err)
+	}
+	return &decodedArchive{archive: archive, hour: hour, start: start, stats: stats, hours: hours}, nil
+}
+
+// commitArchive finishes every consumer's hour and then advances the
+// cursor. Callers must commit archives in hour order.
+func (e *Engine) commitArchive(ctx context.Context, d *decodedArchive) error {
+	sum := HourSummary{Archive: d.archive, Hour: d.hour, DecodeStats: d.stats}
+	for _, hc := range d.hours {
+		if err := hc.FinishHour(ctx, sum); err != nil {
+			return err
+		}
+	}
+
+	if err := e.cursor.SetCursor(ctx, Cursor{
+		LastProcessedArchive: d.archive,
+		CompletedAt:          e.nowFn().UTC(),
+	}); err != nil {
+		return fmt.Errorf("advancing cursor to %s: %w", d.archive, err)
+	}
+
+	if e.hooks.OnArchiveComplete != nil {
+		e.hooks.OnArchiveComplete(d.archive, time.Since(d.start))
+	}
+	logging.Debug("gharchive: archive complete",
+		"archive", d.archive, "events", d.stats.Events,
+		"malformed", d.stats.Malformed, "consumers", len(d.hours))
+	return nil
+}
+
+// OpenArchive returns the archive body from LocalDir when the file
+// exists there, and otherwise from the archive cache, which falls back
+// to the origin on a miss.
+func (e *Engine) OpenArchive(ctx context.Context, archive string) (io.ReadCloser, error) {
+	if path := e.LocalArchivePath(archive); path != "" {
+		f, err := os.Open(path)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("opening local archive %s: %w", path, err)
+		}
+	}
+	body, _, err := e.cache.Open(ctx, archive, func(ctx context.Context) (io.ReadCloser, error) {
+		return e.fetchArchiveWithRetry(ctx, archive)
+	})
+	return body, err
+}
+
+// LocalArchivePath is the path archive would have under LocalDir, or ""
+// when no local directory is configured.
+func (e *Engine) LocalArchivePath(archive string) string {
+	if e.cfg.LocalDir == "" {
+		return ""
+	}
+	return filepath.Join(e.cfg.LocalDir, archive+".json.gz")
+}
+
+// fetchArchiveWithRetry hits the gharchive origin with bounded
+// exponential backoff for transient failures (network errors, 5xx, plus
+// the retry-friendly 4xx status codes 408 and 429). Other 4xx responses
+// (notably 404 for not-yet-published archives, 403 for permission
+// issues) are treated as terminal — there is no benefit to retrying.
+//
+// When the origin sends `Retry-After` on a retryable response, that
+// value overrides the computed exponential-backoff sleep for the next
+// attempt (capped at MaxBackoff). This honors RFC 9110/9111 so we don't
+// hammer a struggling S3 origin that already told us when to come back.
+func (e *Engine) fetchArchiveWithRetry(ctx context.Context, archive string) (io.ReadCloser, error) {
+	url := fmt.Sprintf("%s/%s.json.gz", e.cfg.BaseURL, archive)
+
+	var lastErr error
+	backoff := e.cfg.InitialBackoff
+
+	for attempt := 1; attempt <= e.cfg.MaxRetries; attempt++ {
+		// Reset per-attempt retry-after override; only set when the
+		// current response carries a parseable Retry-After header.
+		var retryAfter time.Duration
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("building request for %s: %w", url, err)
+		}
+
+		resp, err := e.client.Do(req)
+		switch {
+		case err != nil:
+			lastErr = err
+			if e.hooks.OnArchiveError != nil {
+				e.hooks.OnArchiveError(archive, attempt, err)
+			}
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return resp.Body, nil
+		case isRetryableHTTPStatus(resp.StatusCode):
+			// 408 / 429 / 5xx: transient under burst load on the
+			// gharchive S3 origin. Honor Retry-After when present.
+			lastErr = fmt.Errorf("gharchive %s: %s", url, resp.Status)
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), e.nowFn())
+			resp.Body.Close()
+			if e.hooks.OnArchiveError != nil {
+				e.hooks.OnArchiveError(archive, attempt, lastErr)
+			}
+		case resp.StatusCode >= 400 && resp.StatusCode < 500:
+			// Other 4xx is terminal: 404 for not-yet-published
+			// archives and 403 for permission issues won't fix on
+			// retry, so fail fast and let Run decide whether to
+			// skip-past via the poison threshold.
+			lastErr = fmt.Errorf("gharchive %s: %s", url, resp.Status)
+			resp.Body.Close()
+			if e.hooks.OnArchiveError != nil {
+				e.hooks.OnArchiveError(archive, attempt, lastErr)
+			}
+			return nil, lastErr
+		default:
+			// Non-2xx, non-4xx (i.e. 1xx/3xx unexpected here, or
+			// any future >=600 oddity): treat as transient.
+			lastErr = fmt.Errorf("gharchive %s: %s", url, resp.Status)
+			resp.Body.Close()
+			if e.hooks.OnArchiveError != nil {
+				e.hooks.OnArchiveError(archive, attempt, lastErr)
+			}
+		}
+
+		if attempt == e.cfg.MaxRetries {
+			break
+		}
+
+		// Exponential backoff with jitter — capped at MaxBackoff.
+		// Retry-After (when present and positive) overrides the
+		// computed sleep so the origin's hint wins.
+		var sleep time.Duration
+		if retryAfter > 0 {
+			sleep = retryAfter
+		} else {
+			sleep = backoff + e.jitterFn(backoff/2)
+		}
+		if sleep > MaxBackoff {
+			sleep = MaxBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sleep):
+		}
+		backoff *= 2
+		if backoff > MaxBackoff {
+			backoff = MaxBackoff
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("retries exhausted")
+	}
+	return nil, fmt.Errorf("gharchive fetch %s: %w", url, lastErr)
+}
+
+// isRetryableHTTPStatus returns true for HTTP responses that are worth
+// retrying with backoff: all 5xx plus the retry-friendly 4xx codes 408
+// (Request Timeout) and 429 (Too Many Requests).
+func isRetryableHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return code >= 500 && code < 600
+}
+
+// parseRetryAfter interprets the Retry-After header per RFC 9110 §10.2.3.
+// Returns 0 (caller should fall back to exponential backoff) when the
+// header is empty, malformed, or names a past instant.
+//
+// Two forms are accepted:
+//   - delta-seconds: integer seconds to wait (e.g. "120")
+//   - HTTP-date: RFC 1123 / RFC 850 / asctime instant (e.g.
+//     "Fri, 31 Dec 1999 23:59:59 GMT")
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		d := t.Sub(now)
+		if d <= 0 {
+			return 0
+		}
+		return d
+	}
+	return 0
+}
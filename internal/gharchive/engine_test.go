@@ -0,0 +1,292 @@
+package gharchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memCursor is an in-memory CursorStore.
+type memCursor struct {
+	mu sync.Mutex
+	c  Cursor
+}
+
+func (m *memCursor) GetCursor(context.Context) (Cursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.c, nil
+}
+
+func (m *memCursor) SetCursor(_ context.Context, c Cursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.c = c
+	return nil
+}
+
+// recordingConsumer records what the engine fed it. It is safe for the
+// concurrent BeginHour / ConsumeEvent calls Run makes.
+type recordingConsumer struct {
+	mu       sync.Mutex
+	begun    []time.Time
+	repos    []string
+	finished []HourSummary
+	err      error
+}
+
+func (r *recordingConsumer) BeginHour(hour time.Time) HourConsumer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.begun = append(r.begun, hour)
+	return &recordingHour{r: r}
+}
+
+type recordingHour struct{ r *recordingConsumer }
+
+func (h *recordingHour) ConsumeEvent(evt *Event) {
+	h.r.mu.Lock()
+	defer h.r.mu.Unlock()
+	h.r.repos = append(h.r.repos, evt.Repo.Name)
+}
+
+func (h *recordingHour) FinishHour(_ context.Context, sum HourSummary) error {
+	h.r.mu.Lock()
+	defer h.r.mu.Unlock()
+	if h.r.err != nil {
+		return h.r.err
+	}
+	h.r.finished = append(h.r.finished, sum)
+	return nil
+}
+
+func gzipLines(t *testing.T, lines ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, l := range lines {
+		gz.Write([]byte(l + "\n"))
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEngine_FansOutSingleDecode(t *testing.T) {
+	body := gzipLines(t,
+		`{"type":"WatchEvent","repo":{"name":"acme/app"},"actor":{"login":"a"}}`,
+		`not json`,
+		`{"type":"ForkEvent","repo":{"name":"acme/lib"},"actor":{"login":"b"}}`,
+	)
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	cursor := &memCursor{}
+	e := NewEngine(EngineConfig{BaseURL: srv.URL}, cursor, Hooks{})
+	a, b := &recordingConsumer{}, &recordingConsumer{}
+	e.Register(a)
+	e.Register(b)
+
+	if err := e.ProcessArchive(context.Background(), "2026-05-10-12"); err != nil {
+		t.Fatalf("ProcessArchive: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("origin requests = %d, want 1", requests)
+	}
+	hour := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	for name, c := range map[string]*recordingConsumer{"a": a, "b": b} {
+		if len(c.begun) != 1 || !c.begun[0].Equal(hour) {
+			t.Errorf("%s begun = %v", name, c.begun)
+		}
+		if len(c.repos) != 2 || c.repos[0] != "acme/app" || c.repos[1] != "acme/lib" {
+			t.Errorf("%s repos = %v", name, c.repos)
+		}
+		if len(c.finished) != 1 || c.finished[0].Events != 2 || c.finished[0].Malformed != 1 {
+			t.Errorf("%s finished = %+v", name, c.finished)
+		}
+	}
+	if got, _ := cursor.GetCursor(context.Background()); got.LastProcessedArchive != "2026-05-10-12" {
+		t.Errorf("cursor = %+v", got)
+	}
+}
+
+func TestEngine_ConsumerErrorBlocksCursor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(gzipLines(t, `{"type":"WatchEvent","repo":{"name":"acme/app"}}`))
+	}))
+	defer srv.Close()
+
+	cursor := &memCursor{}
+	e := NewEngine(EngineConfig{BaseURL: srv.URL}, cursor, Hooks{})
+	e.Register(&recordingConsumer{err: errors.New("disk full")})
+
+	if err := e.ProcessArchive(context.Background(), "2026-05-10-12"); err == nil {
+		t.Fatal("expected consumer error")
+	}
+	if got, _ := cursor.GetCursor(context.Background()); !got.IsZero() {
+		t.Errorf("cursor advanced to %+v despite consumer error", got)
+	}
+}
+
+// hourServer serves one small archive per hour. Earlier hours respond
+// more slowly so concurrent decodes finish out of order, and statuses
+// overrides the response code per archive. It tracks the peak number of
+// requests in flight.
+func hourServer(t *testing.T, statuses map[string]int) (srv *httptest.Server, peak *int32) {
+	t.Helper()
+	var inFlight int32
+	peak = new(int32)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(peak)
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+		archive := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".json.gz")
+		hour, _ := ParseArchiveName(archive)
+		time.Sleep(time.Duration(24-hour.Hour()) * 5 * time.Millisecond)
+		if code := statuses[archive]; code != 0 {
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+		w.Write(gzipLines(t, `{"type":"WatchEvent","repo":{"name":"acme/`+archive+`"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, peak
+}
+
+// runEngine runs an engine over the hours 00..05 of 2026-05-10.
+func runEngine(t *testing.T, cfg EngineConfig, throttle func() bool) (*recordingConsumer, *memCursor) {
+	t.Helper()
+	cursor := &memCursor{c: Cursor{LastProcessedArchive: "2026-05-09-23"}}
+	e := NewEngine(cfg, cursor, Hooks{})
+	e.SetClock(func() time.Time { return time.Date(2026, 5, 10, 7, 0, 0, 0, time.UTC) })
+	e.SetJitter(func(time.Duration) time.Duration { return 0 })
+	e.SetThrottle(throttle)
+	rec := &recordingConsumer{}
+	e.Register(rec)
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return rec, cursor
+}
+
+func finishedArchives(rec *recordingConsumer) []string {
+	var out []string
+	for _, sum := range rec.finished {
+		out = append(out, sum.Archive)
+	}
+	return out
+}
+
+func TestEngine_RunDecodesConcurrentlyCommitsInOrder(t *testing.T) {
+	srv, peak := hourServer(t, nil)
+	rec, cursor := runEngine(t, EngineConfig{BaseURL: srv.URL, Concurrency: 3}, nil)
+
+	want := []string{"2026-05-10-00", "2026-05-10-01", "2026-05-10-02", "2026-05-10-03", "2026-05-10-04", "2026-05-10-05"}
+	if got := finishedArchives(rec); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("finished = %v, want %v", got, want)
+	}
+	if p := atomic.LoadInt32(peak); p < 2 || p > 3 {
+		t.Errorf("peak in-flight downloads = %d, want 2..3", p)
+	}
+	if got, _ := cursor.GetCursor(context.Background()); got.LastProcessedArchive != "2026-05-10-05" {
+		t.Errorf("cursor = %+v", got)
+	}
+}
+
+func TestEngine_RunStopsCursorAtGap(t *testing.T) {
+	srv, _ := hourServer(t, map[string]int{"2026-05-10-02": http.StatusNotFound})
+	rec, cursor := runEngine(t, EngineConfig{BaseURL: srv.URL, Concurrency: 4, MaxRetries: 1}, nil)
+
+	if got := finishedArchives(rec); strings.Join(got, ",") != "2026-05-10-00,2026-05-10-01" {
+		t.Errorf("finished = %v, want only the hours before the gap", got)
+	}
+	if got, _ := cursor.GetCursor(context.Background()); got.LastProcessedArchive != "2026-05-10-01" {
+		t.Errorf("cursor = %q, want 2026-05-10-01", got.LastProcessedArchive)
+	}
+}
+
+func TestEngine_ThrottleLimitsToOneArchive(t *testing.T) {
+	srv, peak := hourServer(t, nil)
+	rec, _ := runEngine(t, EngineConfig{BaseURL: srv.URL, Concurrency: 4}, func() bool { return true })
+
+	if len(rec.finished) != 6 {
+		t.Errorf("finished %d archives, want 6", len(rec.finished))
+	}
+	if p := atomic.LoadInt32(peak); p != 1 {
+		t.Errorf("peak in-flight downloads = %d while throttled, want 1", p)
+	}
+}
+
+func TestEvent_UnmarshalPayload(t *testing.T) {
+	var got struct {
+		Payload struct {
+			Action string `json:"action"`
+		} `json:"payload"`
+	}
+	ctx := context.Background()
+	body := gzipLines(t, `{"type":"IssuesEvent","repo":{"name":"acme/app"},"payload":{"action":"opened"}}`)
+	if _, err := Decode(ctx, bytes.NewReader(body), func(evt *Event) {
+		if err := evt.Unmarshal(&got); err != nil {
+			t.Errorf("Unmarshal: %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.Payload.Action != "opened" {
+		t.Errorf("payload action = %q", got.Payload.Action)
+	}
+}
+
+// TestParseRetryAfter exercises the header parser directly.
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"empty", "", 0},
+		{"whitespace", "   ", 0},
+		{"zero seconds", "0", 0},
+		{"negative seconds", "-5", 0},
+		{"positive seconds", "30", 30 * time.Second},
+		{"padded seconds", "  120  ", 120 * time.Second},
+		{"future http-date", now.Add(45 * time.Second).UTC().Format(http.TimeFormat), 45 * time.Second},
+		{"past http-date", now.Add(-1 * time.Hour).UTC().Format(http.TimeFormat), 0},
+		{"garbage", "definitely not a number or date", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseRetryAfter(tc.header, now)
+			// HTTP date parsing has 1-second granularity; allow 1s slack
+			// in either direction for the future-date case.
+			if tc.name == "future http-date" {
+				if got < tc.want-time.Second || got > tc.want+time.Second {
+					t.Errorf("parseRetryAfter(%q) = %v, want ~%v", tc.header, got, tc.want)
+				}
+				return
+			}
+			if got != tc.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.header, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package gitea implements forge.Provider for Gitea and Forgejo
+// instances such as Codeberg, over the REST API v1.
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/forge"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	userAgent      = "github-radar"
+
+	// maxErrorBody bounds how much of an error response is kept.
+	maxErrorBody = 512
+)
+
+// Client is a Gitea/Forgejo REST client. It implements forge.Provider.
+type Client struct {
+	name       string
+	baseURL    string
+	token      string
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	rateLimit forge.RateLimit
+}
+
+var _ forge.Provider = (*Client)(nil)
+
+// NewClient creates a client for the instance whose API is at baseURL
+// (e.g. https://codeberg.org/api/v1) and whose repos are keyed
+// name:owner/repo. token is an access token; empty for anonymous
+// access.
+func NewClient(name, baseURL, token string) *Client {
+	return &Client{
+		name:       name,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// SetHTTPClient sets a custom HTTP client (useful for testing).
+func (c *Client) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// Name implements forge.Provider.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// RateLimit implements forge.Provider. Gitea itself sends no rate-limit
+// headers; instances behind a limiter that sends X-RateLimit-* report
+// them here, the rest report a zero Limit.
+func (c *Client) RateLimit() forge.RateLimit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rateLimit
+}
+
+// APIError is a non-2xx Gitea response. A 404 matches forge.ErrNotFound.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gitea: GET %s: status %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+// Is reports a 404 as forge.ErrNotFound.
+func (e *APIError) Is(target error) bool {
+	return target == forge.ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// repoPath returns the API path of a repository.
+func repoPath(owner, name string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+}
+
+// get issues a GET for path and returns the successful response, whose
+// body the caller must close.
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gitea: GET %s: %w", path, err)
+	}
+	c.updateRateLimit(resp.Header)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Path: path, Message: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// getJSON issues a GET for path and decodes the JSON body into v.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	resp, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("gitea: decoding %s: %w", path, err)
+	}
+	return nil
+}
+
+// updateRateLimit records X-RateLimit-* headers when the instance sends
+// them.
+func (c *Client) updateRateLimit(h http.Header) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, _ := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	reset, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = forge.RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+}
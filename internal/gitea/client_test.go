@@ -0,0 +1,116 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/forge"
+)
+
+// newStub serves one repository, forgejo/forgejo, under /api/v1. Only
+// README.rst exists, so GetReadme has to try more than one name.
+func newStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	const repo = "/api/v1/repos/forgejo/forgejo"
+	recent := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token cb-test" {
+			t.Errorf("%s: missing token", r.URL.Path)
+		}
+		switch r.URL.Path {
+		case repo:
+			fmt.Fprint(w, `{
+				"owner": {"login": "forgejo"},
+				"name": "forgejo",
+				"full_name": "forgejo/forgejo",
+				"description": "Beyond coding. We forge.",
+				"language": "Go",
+				"topics": ["forge", "git"],
+				"stars_count": 900,
+				"forks_count": 400,
+				"open_issues_count": 1200,
+				"html_url": "https://codeberg.example/forgejo/forgejo"
+			}`)
+		case repo + "/pulls":
+			fmt.Fprintf(w, `[
+				{"merged": true, "merged_at": %[1]q, "updated_at": %[1]q},
+				{"merged": false, "updated_at": %[1]q},
+				{"merged": true, "merged_at": %[2]q, "updated_at": %[2]q}
+			]`, recent, old)
+		case repo + "/issues":
+			if r.URL.Query().Get("type") != "issues" {
+				t.Errorf("issues type = %q", r.URL.Query().Get("type"))
+			}
+			fmt.Fprintf(w, `[{"created_at": %q}, {"created_at": %q}]`, recent, old)
+		case repo + "/releases/latest":
+			fmt.Fprintf(w, `{"published_at": %q}`, recent)
+		case repo + "/raw/README.rst":
+			fmt.Fprint(w, "Forgejo\n=======")
+		case "/api/v1/repos/search":
+			if r.URL.Query().Get("q") != "forge" || r.URL.Query().Get("sort") != "stars" {
+				t.Errorf("search query = %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"ok": true, "data": [{"owner": {"login": "forgejo"}, "name": "forgejo", "full_name": "forgejo/forgejo", "stars_count": 900}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "not found"}`)
+		}
+	}))
+}
+
+func TestClient_Provider(t *testing.T) {
+	server := newStub(t)
+	defer server.Close()
+	c := NewClient("codeberg", server.URL+"/api/v1", "cb-test")
+	ctx := context.Background()
+
+	repo, err := c.GetRepository(ctx, "forgejo", "forgejo")
+	if err != nil {
+		t.Fatalf("GetRepository() error: %v", err)
+	}
+	if repo.FullName != "forgejo/forgejo" || repo.Stars != 900 || repo.Forks != 400 || repo.Language != "Go" {
+		t.Errorf("GetRepository() = %+v", repo)
+	}
+
+	a, err := c.GetActivity(ctx, "forgejo", "forgejo")
+	if err != nil {
+		t.Fatalf("GetActivity() error: %v", err)
+	}
+	if a.MergedPRs7d != 1 || a.NewIssues7d != 1 || a.Contributors != 0 || a.LatestRelease.IsZero() {
+		t.Errorf("GetActivity() = %+v", a)
+	}
+
+	readme, err := c.GetReadme(ctx, "forgejo", "forgejo")
+	if err != nil || !readme.Found || readme.Content != "Forgejo\n=======" {
+		t.Errorf("GetReadme() = %+v, %v", readme, err)
+	}
+
+	results, err := c.SearchRepositories(ctx, "forge", 5)
+	if err != nil || len(results) != 1 || results[0].Stars != 900 {
+		t.Errorf("SearchRepositories() = %+v, %v", results, err)
+	}
+
+	if rl := c.RateLimit(); rl.Limit != 0 {
+		t.Errorf("RateLimit() = %+v, want none reported", rl)
+	}
+}
+
+func TestClient_NotFound(t *testing.T) {
+	server := newStub(t)
+	defer server.Close()
+	c := NewClient("codeberg", server.URL+"/api/v1", "cb-test")
+
+	if _, err := c.GetRepository(context.Background(), "forgejo", "missing"); !errors.Is(err, forge.ErrNotFound) {
+		t.Fatalf("GetRepository() error = %v, want forge.ErrNotFound", err)
+	}
+	if _, err := c.GetActivity(context.Background(), "forgejo", "missing"); err == nil {
+		t.Error("GetActivity() on a missing repo returned no error")
+	}
+}
@@ -0,0 +1,234 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/forge"
+)
+
+const (
+	// maxActivityPages bounds how many pages GetActivity walks per
+	// list; activity beyond it is counted as the cap.
+	maxActivityPages = 10
+
+	// activityPageSize is the limit used when walking lists. Gitea
+	// caps it at its MAX_RESPONSE_ITEMS, 50 by default.
+	activityPageSize = 50
+)
+
+// readmeNames are tried in order; Gitea has no "the README" endpoint.
+var readmeNames = []string{"README.md", "README", "README.rst", "README.txt", "readme.md"}
+
+// repoResponse is the part of a Gitea repository the provider reads.
+type repoResponse struct {
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Name            string    `json:"name"`
+	FullName        string    `json:"full_name"`
+	Description     string    `json:"description"`
+	Language        string    `json:"language"`
+	Topics          []string  `json:"topics"`
+	StarsCount      int       `json:"stars_count"`
+	ForksCount      int       `json:"forks_count"`
+	OpenIssuesCount int       `json:"open_issues_count"`
+	Fork            bool      `json:"fork"`
+	Mirror          bool      `json:"mirror"`
+	Archived        bool      `json:"archived"`
+	HTMLURL         string    `json:"html_url"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Parent          *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+}
+
+func (r *repoResponse) toRepository() *forge.Repository {
+	repo := &forge.Repository{
+		Owner:       r.Owner.Login,
+		Name:        r.Name,
+		FullName:    r.FullName,
+		Description: r.Description,
+		Language:    r.Language,
+		Topics:      r.Topics,
+		Stars:       r.StarsCount,
+		Forks:       r.ForksCount,
+		OpenIssues:  r.OpenIssuesCount,
+		Fork:        r.Fork,
+		Mirror:      r.Mirror,
+		Archived:    r.Archived,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		URL:         r.HTMLURL,
+	}
+	if r.Parent != nil {
+		repo.Upstream = r.Parent.FullName
+	}
+	return repo
+}
+
+// GetRepository implements forge.Provider.
+func (c *Client) GetRepository(ctx context.Context, owner, name string) (*forge.Repository, error) {
+	var r repoResponse
+	if err := c.getJSON(ctx, repoPath(owner, name), nil, &r); err != nil {
+		return nil, fmt.Errorf("fetching repository %s/%s: %w", owner, name, err)
+	}
+	return r.toRepository(), nil
+}
+
+// GetActivity implements forge.Provider: pull requests merged and
+// issues opened in the last week and the latest release. Gitea has no
+// contributors API, so Contributors is always 0.
+func (c *Client) GetActivity(ctx context.Context, owner, name string) (*forge.Activity, error) {
+	since := time.Now().Add(-forge.ActivityWindow)
+	a := &forge.Activity{}
+	var errs []error
+
+	merged, err := c.mergedSince(ctx, owner, name, since)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("pull requests: %w", err))
+	}
+	a.MergedPRs7d = merged
+
+	issues, err := c.issuesSince(ctx, owner, name, since)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("issues: %w", err))
+	}
+	a.NewIssues7d = issues
+
+	var release struct {
+		PublishedAt time.Time `json:"published_at"`
+	}
+	err = c.getJSON(ctx, repoPath(owner, name)+"/releases/latest", nil, &release)
+	switch {
+	case errors.Is(err, forge.ErrNotFound):
+		// No releases.
+	case err != nil:
+		errs = append(errs, fmt.Errorf("releases: %w", err))
+	default:
+		a.LatestRelease = release.PublishedAt.UTC()
+	}
+
+	if len(errs) > 0 {
+		return a, fmt.Errorf("partial activity for %s/%s: %w", owner, name, errors.Join(errs...))
+	}
+	return a, nil
+}
+
+// mergedSince counts pull requests merged at or after since, walking
+// closed pull requests most recently updated first until they predate
+// since.
+func (c *Client) mergedSince(ctx context.Context, owner, name string, since time.Time) (int, error) {
+	query := url.Values{
+		"state": {"closed"},
+		"sort":  {"recentupdate"},
+		"limit": {strconv.Itoa(activityPageSize)},
+	}
+	count := 0
+	for page := 1; page <= maxActivityPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var pulls []struct {
+			Merged    bool       `json:"merged"`
+			MergedAt  *time.Time `json:"merged_at"`
+			UpdatedAt time.Time  `json:"updated_at"`
+		}
+		if err := c.getJSON(ctx, repoPath(owner, name)+"/pulls", query, &pulls); err != nil {
+			return count, err
+		}
+		for _, pr := range pulls {
+			if pr.Merged && pr.MergedAt != nil && !pr.MergedAt.Before(since) {
+				count++
+			}
+		}
+		if len(pulls) < activityPageSize || pulls[len(pulls)-1].UpdatedAt.Before(since) {
+			break
+		}
+	}
+	return count, nil
+}
+
+// issuesSince counts issues created at or after since. The API's since
+// filters on update time, so creation is checked per issue.
+func (c *Client) issuesSince(ctx context.Context, owner, name string, since time.Time) (int, error) {
+	query := url.Values{
+		"state": {"all"},
+		"type":  {"issues"},
+		"since": {since.UTC().Format(time.RFC3339)},
+		"limit": {strconv.Itoa(activityPageSize)},
+	}
+	count := 0
+	for page := 1; page <= maxActivityPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var issues []struct {
+			CreatedAt time.Time `json:"created_at"`
+		}
+		if err := c.getJSON(ctx, repoPath(owner, name)+"/issues", query, &issues); err != nil {
+			return count, err
+		}
+		for _, issue := range issues {
+			if !issue.CreatedAt.Before(since) {
+				count++
+			}
+		}
+		if len(issues) < activityPageSize {
+			break
+		}
+	}
+	return count, nil
+}
+
+// GetReadme implements forge.Provider, trying the usual README names on
+// the default branch.
+func (c *Client) GetReadme(ctx context.Context, owner, name string) (*forge.Readme, error) {
+	for _, file := range readmeNames {
+		resp, err := c.get(ctx, repoPath(owner, name)+"/raw/"+url.PathEscape(file), nil)
+		if errors.Is(err, forge.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fetching readme for %s/%s: %w", owner, name, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading readme for %s/%s: %w", owner, name, err)
+		}
+		return &forge.Readme{Content: string(body), Found: true}, nil
+	}
+	return &forge.Readme{}, nil
+}
+
+// SearchRepositories implements forge.Provider over GET /repos/search,
+// which matches repository names and, with includeDesc, descriptions.
+func (c *Client) SearchRepositories(ctx context.Context, query string, limit int) ([]forge.Repository, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > activityPageSize {
+		limit = activityPageSize
+	}
+	var resp struct {
+		OK   bool           `json:"ok"`
+		Data []repoResponse `json:"data"`
+	}
+	if err := c.getJSON(ctx, "/repos/search", url.Values{
+		"q":           {query},
+		"includeDesc": {"true"},
+		"sort":        {"stars"},
+		"order":       {"desc"},
+		"limit":       {strconv.Itoa(limit)},
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("searching repositories: %w", err)
+	}
+	out := make([]forge.Repository, 0, len(resp.Data))
+	for i := range resp.Data {
+		out = append(out, *resp.Data[i].toRepository())
+	}
+	return out, nil
+}
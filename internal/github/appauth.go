@@ -0,0 +1,349 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/cassette"
+)
+
+// appauth.go authenticates as a GitHub App installation instead of with
+// a personal access token. The client signs a short-lived JWT with the
+// App's private key, exchanges it for an installation access token, and
+// refreshes that token shortly before it expires. Installation tokens
+// carry the App rate limits (up to 12,500 or 15,000 requests per hour
+// instead of 5,000), which show up in RateLimitInfo through the usual
+// X-RateLimit-* headers.
+
+const (
+	// appJWTLifetime is how long a signed App JWT is valid. GitHub caps
+	// it at 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+
+	// appJWTClockSkew backdates iat to tolerate clock drift between us
+	// and GitHub, as GitHub recommends.
+	appJWTClockSkew = 60 * time.Second
+
+	// appTokenRefreshMargin is how long before expiry an installation
+	// token is replaced. Installation tokens live for one hour.
+	appTokenRefreshMargin = 5 * time.Minute
+)
+
+// AppConfig identifies a GitHub App installation to authenticate as.
+type AppConfig struct {
+	// AppID is the numeric App ID from the App's settings page.
+	AppID int64
+
+	// InstallationID selects the installation. Zero means the App's
+	// only installation; it is an error if the App has several.
+	InstallationID int64
+
+	// PrivateKey is the PEM-encoded RSA private key generated for the
+	// App (PKCS#1 or PKCS#8).
+	PrivateKey []byte
+}
+
+// InstallationInfo describes the installation token in use, as reported
+// by the token exchange.
+type InstallationInfo struct {
+	AppID               int64
+	InstallationID      int64
+	Account             string
+	RepositorySelection string
+	Permissions         map[string]string
+	ExpiresAt           time.Time
+}
+
+// PermissionList renders Permissions as sorted "name=level" pairs for
+// logs, e.g. "contents=read,metadata=read".
+func (i InstallationInfo) PermissionList() string {
+	perms := make([]string, 0, len(i.Permissions))
+	for name, level := range i.Permissions {
+		perms = append(perms, name+"="+level)
+	}
+	sort.Strings(perms)
+	return strings.Join(perms, ",")
+}
+
+// appInstallation mints and caches installation tokens for one App
+// installation. mu serialises refreshes, so concurrent requests that
+// find the token stale wait for a single exchange.
+type appInstallation struct {
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+
+	mu             sync.Mutex
+	installationID int64
+	token          string
+	prev           string
+	info           InstallationInfo
+}
+
+// LoadAppPrivateKey reads a PEM private key file for AppConfig.
+func LoadAppPrivateKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading github app private key: %w", err)
+	}
+	return data, nil
+}
+
+// NewAppClient creates a client that authenticates as a GitHub App
+// installation. No request is made until the first call; use
+// ValidateToken to exchange the first token eagerly.
+func NewAppClient(cfg AppConfig) (*Client, error) {
+	if cfg.AppID <= 0 {
+		return nil, fmt.Errorf("github app id must be positive")
+	}
+	key, err := parseAppPrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &appInstallation{
+		appID:          cfg.AppID,
+		key:            key,
+		now:            time.Now,
+		installationID: cfg.InstallationID,
+	}
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: cassette.Transport(cassette.ServiceGitHub),
+		},
+		tokens: []*poolToken{{
+			id:     fmt.Sprintf("app_%d", cfg.AppID),
+			app:    app,
+			limits: make(map[string]RateLimit),
+		}},
+		baseURL: DefaultBaseURL,
+	}, nil
+}
+
+// AppInstallation returns the installation the client authenticates
+// as. ok is false for token-based clients and before the first token
+// exchange.
+func (c *Client) AppInstallation() (info InstallationInfo, ok bool) {
+	for _, t := range c.tokens {
+		if t.app == nil {
+			continue
+		}
+		t.app.mu.Lock()
+		defer t.app.mu.Unlock()
+		if t.app.token == "" {
+			return InstallationInfo{}, false
+		}
+		return t.app.info, true
+	}
+	return InstallationInfo{}, false
+}
+
+// AuthMode returns "app" for a GitHub App client and "token" for a
+// personal-access-token pool.
+func (c *Client) AuthMode() string {
+	for _, t := range c.tokens {
+		if t.app != nil {
+			return "app"
+		}
+	}
+	return "token"
+}
+
+// parseAppPrivateKey decodes a PEM RSA key in PKCS#1 or PKCS#8 form.
+func parseAppPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("github app private key: no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("github app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("github app private key: want RSA, got %T", parsed)
+	}
+	return key, nil
+}
+
+// signJWT returns an RS256 JWT asserting the App's identity.
+func (a *appInstallation) signJWT() (string, error) {
+	now := a.now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(a.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing github app jwt: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// currentToken returns a valid installation token, exchanging a new
+// one when there is none or the cached one is about to expire.
+func (a *appInstallation) currentToken(ctx context.Context, c *Client) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && a.now().Before(a.info.ExpiresAt.Add(-appTokenRefreshMargin)) {
+		return a.token, nil
+	}
+	if err := a.exchangeLocked(ctx, c); err != nil {
+		return "", err
+	}
+	return a.token, nil
+}
+
+// invalidate drops the cached token so the next request exchanges a
+// new one. Called when GitHub rejects the token before its expiry,
+// e.g. after the installation's permissions changed.
+func (a *appInstallation) invalidate(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == token {
+		a.prev, a.token = token, ""
+	}
+}
+
+// issued reports whether token is the current or the previous
+// installation token, i.e. an Authorization value Do may replace.
+func (a *appInstallation) issued(token string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return token != "" && (token == a.token || token == a.prev)
+}
+
+// exchangeLocked trades an App JWT for an installation token. Caller
+// must hold a.mu.
+func (a *appInstallation) exchangeLocked(ctx context.Context, c *Client) error {
+	jwt, err := a.signJWT()
+	if err != nil {
+		return err
+	}
+	if a.installationID == 0 {
+		id, err := a.findInstallation(ctx, c, jwt)
+		if err != nil {
+			return err
+		}
+		a.installationID = id
+	}
+
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", a.installationID)
+	var body struct {
+		Token               string            `json:"token"`
+		ExpiresAt           time.Time         `json:"expires_at"`
+		Permissions         map[string]string `json:"permissions"`
+		RepositorySelection string            `json:"repository_selection"`
+	}
+	if err := a.appRequest(ctx, c, jwt, http.MethodPost, path, http.StatusCreated, &body); err != nil {
+		return fmt.Errorf("exchanging github app installation %d token: %w", a.installationID, err)
+	}
+	if body.Token == "" {
+		return fmt.Errorf("exchanging github app installation %d token: empty token in response", a.installationID)
+	}
+
+	account := a.info.Account
+	if account == "" {
+		account = a.lookupAccount(ctx, c, jwt)
+	}
+	if a.token != "" {
+		a.prev = a.token
+	}
+	a.token = body.Token
+	a.info = InstallationInfo{
+		AppID:               a.appID,
+		InstallationID:      a.installationID,
+		Account:             account,
+		RepositorySelection: body.RepositorySelection,
+		Permissions:         body.Permissions,
+		ExpiresAt:           body.ExpiresAt,
+	}
+	return nil
+}
+
+// findInstallation returns the App's only installation ID.
+func (a *appInstallation) findInstallation(ctx context.Context, c *Client, jwt string) (int64, error) {
+	var installs []struct {
+		ID int64 `json:"id"`
+	}
+	if err := a.appRequest(ctx, c, jwt, http.MethodGet, "/app/installations", http.StatusOK, &installs); err != nil {
+		return 0, fmt.Errorf("listing github app installations: %w", err)
+	}
+	switch len(installs) {
+	case 0:
+		return 0, fmt.Errorf("github app %d has no installations", a.appID)
+	case 1:
+		return installs[0].ID, nil
+	}
+	return 0, fmt.Errorf("github app %d has %d installations; set the installation id", a.appID, len(installs))
+}
+
+// lookupAccount returns the login the installation belongs to. Best
+// effort: the account only labels InstallationInfo.
+func (a *appInstallation) lookupAccount(ctx context.Context, c *Client, jwt string) string {
+	var inst struct {
+		Account struct {
+			Login string `json:"login"`
+		} `json:"account"`
+	}
+	path := fmt.Sprintf("/app/installations/%d", a.installationID)
+	if err := a.appRequest(ctx, c, jwt, http.MethodGet, path, http.StatusOK, &inst); err != nil {
+		return ""
+	}
+	return inst.Account.Login
+}
+
+// appRequest makes one JWT-authenticated call to the /app endpoints.
+// These bypass Do: they are not charged to the installation budget and
+// must not recurse into token selection.
+func (a *appInstallation) appRequest(ctx context.Context, c *Client, jwt, method, path string, want int, v interface{}) error {
+	req, err := c.newRequest(ctx, method, path)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
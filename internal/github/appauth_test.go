@@ -0,0 +1,143 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/testutil/ghstub"
+)
+
+// testClock is a settable clock shared by the stub and the client.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func newAppStubClient(t *testing.T, clock *testClock) (*Client, *ghstub.Stub) {
+	t.Helper()
+	key, pemKey := newAppKey(t)
+	stub := ghstub.New(ghstub.Config{
+		RateLimit:         12500,
+		Now:               clock.Now,
+		AppID:             42,
+		AppPublicKey:      &key.PublicKey,
+		AppInstallationID: 7,
+	})
+	t.Cleanup(stub.Close)
+
+	c, err := NewAppClient(AppConfig{AppID: 42, PrivateKey: pemKey})
+	if err != nil {
+		t.Fatalf("NewAppClient: %v", err)
+	}
+	c.SetBaseURL(stub.URL())
+	c.tokens[0].app.now = clock.Now
+	return c, stub
+}
+
+func TestAppClient_ValidateTokenReportsInstallation(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	c, stub := newAppStubClient(t, clock)
+
+	if _, ok := c.AppInstallation(); ok {
+		t.Error("AppInstallation reported before the first exchange")
+	}
+	if err := c.ValidateToken(context.Background()); err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+
+	info, ok := c.AppInstallation()
+	if !ok {
+		t.Fatal("AppInstallation not reported after ValidateToken")
+	}
+	if info.AppID != 42 || info.InstallationID != 7 || info.Account != "stub-org" {
+		t.Errorf("installation = %+v", info)
+	}
+	if got := info.PermissionList(); got != "contents=read,metadata=read" {
+		t.Errorf("PermissionList = %q", got)
+	}
+	if rl := c.RateLimitInfo(); rl.Limit != 12500 {
+		t.Errorf("RateLimitInfo().Limit = %d, want the App limit 12500", rl.Limit)
+	}
+	if c.AuthMode() != "app" {
+		t.Errorf("AuthMode = %q, want app", c.AuthMode())
+	}
+	if n := stub.Snapshot().TokenExchanges; n != 1 {
+		t.Errorf("token exchanges = %d, want 1", n)
+	}
+}
+
+func TestAppClient_RefreshesBeforeExpiry(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	c, stub := newAppStubClient(t, clock)
+	ctx := context.Background()
+
+	get := func() {
+		t.Helper()
+		resp, err := c.Get(ctx, "/repos/acme/app")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+	}
+
+	get()
+	get()
+	if n := stub.Snapshot().TokenExchanges; n != 1 {
+		t.Fatalf("token exchanges = %d, want 1 while the token is fresh", n)
+	}
+
+	// Inside the refresh margin: the token still works, but is replaced.
+	clock.Advance(time.Hour - appTokenRefreshMargin + time.Minute)
+	get()
+	snap := stub.Snapshot()
+	if snap.TokenExchanges != 2 {
+		t.Errorf("token exchanges = %d, want 2 after nearing expiry", snap.TokenExchanges)
+	}
+	if snap.Unauthorized != 0 {
+		t.Errorf("unauthorized responses = %d, want 0", snap.Unauthorized)
+	}
+}
+
+func TestNewAppClient_RejectsBadConfig(t *testing.T) {
+	_, pemKey := newAppKey(t)
+	if _, err := NewAppClient(AppConfig{PrivateKey: pemKey}); err == nil {
+		t.Error("expected error for missing app id")
+	}
+	if _, err := NewAppClient(AppConfig{AppID: 1, PrivateKey: []byte("not a key")}); err == nil {
+		t.Error("expected error for a key without a PEM block")
+	}
+}
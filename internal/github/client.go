@@ -8,10 +8,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/hrexed/github-radar/internal/repository"
 )
 
 const (
@@ -49,50 +50,78 @@ type APIObserver interface {
 	ObserveRateLimit(limit, remaining int, resetAt time.Time)
 }
 
-// Client is a GitHub API client with rate limit tracking.
+// Client is a GitHub API client with rate limit tracking. It may hold
+// several tokens; see NewPoolClient.
 type Client struct {
-	httpClient    *http.Client
-	token         string
-	baseURL       string
+	httpClient *http.Client
+	tokens     []*poolToken
+	baseURL    string
+	// graphqlURL overrides baseURL+graphqlEndpoint, for GitHub
+	// Enterprise Server where GraphQL is not under the REST base.
+	graphqlURL string
+	// host is the name repos on this client's server are qualified
+	// with; empty for github.com.
+	host string
+	// rateLimit is the pool-wide REST ("core") budget, refreshed from
+	// the per-token budgets on every response.
 	rateLimit     RateLimit
 	rateLimitOpts RateLimitOptions
 	retryConfig   RetryConfig
 	observer      APIObserver
-	mu            sync.RWMutex
+	// cache, when set, stores and revalidates REST GETs (httpcache.go).
+	cache *HTTPCache
+	// batchSizer adapts the GraphQL bulk batch size (graphql_batch.go).
+	batchSizer graphqlBatchSizer
+	// governor, when set, paces requests and enforces secondary rate
+	// limit cooldowns (governor.go).
+	governor *Governor
+	// usage counts the budget charged to this client (usage.go).
+	usage usageCounter
+	mu    sync.RWMutex
 }
 
 // NewClient creates a new GitHub API client.
 // The token must not be empty.
 func NewClient(token string) (*Client, error) {
-	token = strings.TrimSpace(token)
-	if token == "" {
-		return nil, fmt.Errorf("github token cannot be empty")
-	}
-
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
-		},
-		token:   token,
-		baseURL: DefaultBaseURL,
-	}, nil
+	return NewPoolClient([]string{token})
 }
 
-// RateLimitInfo returns the current rate limit information.
-// This is safe for concurrent use.
+// RateLimitInfo returns the current REST rate limit information, summed
+// across the token pool. This is safe for concurrent use.
 func (c *Client) RateLimitInfo() RateLimit {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.rateLimit
 }
 
-// ValidateToken verifies the token works by calling the rate_limit endpoint.
-// It also populates the initial rate limit information.
+// ValidateToken verifies every token in the pool works by calling the
+// rate_limit endpoint. It also populates the initial per-token budgets.
+// For a GitHub App client it exchanges the first installation token;
+// AppInstallation then reports the installation and its permissions.
 func (c *Client) ValidateToken(ctx context.Context) error {
+	for _, tok := range c.tokens {
+		if err := c.validatePoolToken(ctx, tok); err != nil {
+			if len(c.tokens) > 1 {
+				return fmt.Errorf("token %s: %w", tok.id, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePoolToken checks one token and records the budgets the
+// rate_limit endpoint reports for it.
+func (c *Client) validatePoolToken(ctx context.Context, tok *poolToken) error {
 	req, err := c.newRequest(ctx, http.MethodGet, "/rate_limit")
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
+	bearer, err := c.bearer(ctx, tok)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+bearer)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -101,7 +130,7 @@ func (c *Client) ValidateToken(ctx context.Context) error {
 	defer resp.Body.Close()
 
 	// Update rate limits from response headers
-	c.updateRateLimitFromHeaders(resp.Header)
+	c.updateRateLimitFromHeaders(tok, ResourceCore, resp.Header)
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		return fmt.Errorf("invalid or expired token")
@@ -112,19 +141,76 @@ func (c *Client) ValidateToken(ctx context.Context) error {
 		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
 	}
 
+	// The body also carries the GraphQL and search budgets, which no
+	// header on this response reports. Best effort: a body we cannot
+	// parse leaves them to be learned from the first real call.
+	var body struct {
+		Resources map[string]struct {
+			Limit     int   `json:"limit"`
+			Remaining int   `json:"remaining"`
+			Reset     int64 `json:"reset"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+		for _, res := range []string{ResourceGraphQL, ResourceSearch} {
+			r, ok := body.Resources[res]
+			if !ok || r.Limit == 0 {
+				continue
+			}
+			c.mu.Lock()
+			tok.limits[res] = RateLimit{Limit: r.Limit, Remaining: r.Remaining, Reset: time.Unix(r.Reset, 0)}
+			c.mu.Unlock()
+		}
+	}
 	return nil
 }
 
 // Do executes an HTTP request with authentication and rate limit tracking.
+// The request is sent with the pool token that has the most budget left
+// for the resource it will be charged to.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	resource := requestResource(req)
+
+	// A cached response within its TTL needs no request, no budget and
+	// no token.
+	key, cacheable := c.cacheable(req)
+	endpoint := cacheEndpoint(req.URL.Path)
+	if cacheable {
+		if resp, ok := c.cache.fresh(key, endpoint, req); ok {
+			return resp, nil
+		}
+	}
+
 	// Check rate limit before making request
-	if err := c.checkRateLimit(req.Context()); err != nil {
+	if err := c.checkResourceRateLimit(req.Context(), resource); err != nil {
 		return nil, err
 	}
 
-	// Add authentication header if not already present
-	if req.Header.Get("Authorization") == "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
+	// Wait for the governor; the request counts as in flight until
+	// its response headers are back.
+	c.mu.RLock()
+	governor := c.governor
+	c.mu.RUnlock()
+	if governor != nil {
+		release, err := governor.acquire(req.Context(), resource)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	// Add authentication header unless the caller supplied its own.
+	// A header set by an earlier attempt is replaced so a retry can
+	// move to a token with more budget.
+	var tok *poolToken
+	var bearer string
+	if auth := req.Header.Get("Authorization"); auth == "" || c.poolAuthorization(auth) {
+		tok = c.pickToken(resource)
+		var err error
+		if bearer, err = c.bearer(req.Context(), tok); err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+bearer)
 	}
 
 	// Add standard headers
@@ -135,18 +221,74 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		req.Header.Set("User-Agent", UserAgent)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	// Revalidate a cached response on a copy, so the caller's request
+	// (and any retry of it) stays unconditional.
+	send := req
+	revalidating := false
+	if cacheable {
+		if etag, lastModified, ok := c.cache.validators(key); ok {
+			send = req.Clone(req.Context())
+			if etag != "" {
+				send.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				send.Header.Set("If-Modified-Since", lastModified)
+			}
+			revalidating = true
+		}
+	}
+
+	resp, err := c.httpClient.Do(send)
 	if err != nil {
 		return nil, err
 	}
+	c.usage.request(resource, resp.StatusCode)
 
 	// Update rate limits from response
-	c.updateRateLimitFromHeaders(resp.Header)
-	c.notifyRateLimit()
+	if tok != nil {
+		if res, rl, ok := c.updateRateLimitFromHeaders(tok, resource, resp.Header); ok {
+			c.notifyRateLimit(tok, res, rl)
+		}
+		// A revoked installation token is re-exchanged on the next
+		// request rather than sent until it expires.
+		if tok.app != nil && resp.StatusCode == http.StatusUnauthorized {
+			tok.app.invalidate(bearer)
+		}
+	}
+	if governor != nil {
+		governor.observe(resource, resp)
+	}
+
+	if cacheable {
+		if revalidating && resp.StatusCode == http.StatusNotModified {
+			if cached, ok := c.cache.revalidated(key, endpoint, req, resp); ok {
+				resp.Body.Close()
+				return cached, nil
+			}
+		}
+		resp = c.cache.fetched(key, endpoint, resp)
+	}
 
 	return resp, nil
 }
 
+// cacheable returns req's HTTP cache key when the client has a cache
+// and req may use it. Requests carrying a caller-supplied Authorization
+// are not cached: their credentials are outside the client's scope.
+func (c *Client) cacheable(req *http.Request) (string, bool) {
+	if c.cache == nil {
+		return "", false
+	}
+	if auth := req.Header.Get("Authorization"); auth != "" && !c.poolAuthorization(auth) {
+		return "", false
+	}
+	key, ok := cacheKey(req)
+	if !ok {
+		return "", false
+	}
+	return c.credentialScope() + " " + key, true
+}
+
 // Get performs a GET request to the GitHub API.
 func (c *Client) Get(ctx context.Context, path string) (*http.Response, error) {
 	req, err := c.newRequest(ctx, http.MethodGet, path)
@@ -201,7 +343,8 @@ func IsAPINotFound(err error) bool {
 	return false
 }
 
-// newRequest creates a new HTTP request with authentication headers.
+// newRequest creates a new HTTP request with the standard headers. Do
+// adds the Authorization header for the token it picks.
 func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
 	url := c.baseURL + path
 
@@ -210,37 +353,12 @@ func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Req
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", UserAgent)
 
 	return req, nil
 }
 
-// updateRateLimitFromHeaders parses rate limit headers from the response.
-func (c *Client) updateRateLimitFromHeaders(headers http.Header) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if limit := headers.Get("X-RateLimit-Limit"); limit != "" {
-		if v, err := strconv.Atoi(limit); err == nil {
-			c.rateLimit.Limit = v
-		}
-	}
-
-	if remaining := headers.Get("X-RateLimit-Remaining"); remaining != "" {
-		if v, err := strconv.Atoi(remaining); err == nil {
-			c.rateLimit.Remaining = v
-		}
-	}
-
-	if reset := headers.Get("X-RateLimit-Reset"); reset != "" {
-		if v, err := strconv.ParseInt(reset, 10, 64); err == nil {
-			c.rateLimit.Reset = time.Unix(v, 0)
-		}
-	}
-}
-
 // BaseURL returns the configured base URL.
 func (c *Client) BaseURL() string {
 	return c.baseURL
@@ -251,16 +369,79 @@ func (c *Client) SetBaseURL(url string) {
 	c.baseURL = strings.TrimSuffix(url, "/")
 }
 
+// GraphQLURL returns the GraphQL endpoint: the one set with
+// SetGraphQLURL, else the REST base URL plus /graphql.
+func (c *Client) GraphQLURL() string {
+	if c.graphqlURL != "" {
+		return c.graphqlURL
+	}
+	return c.baseURL + graphqlEndpoint
+}
+
+// SetGraphQLURL sets the GraphQL endpoint. GitHub Enterprise Server
+// serves REST under /api/v3 and GraphQL at /api/graphql.
+func (c *Client) SetGraphQLURL(url string) {
+	c.graphqlURL = strings.TrimSuffix(url, "/")
+}
+
+// Host returns the host repos on this client's server are qualified
+// with, empty for github.com.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// SetHost sets the host name repos on this client's server are
+// qualified with (see repository.JoinFullName).
+func (c *Client) SetHost(host string) {
+	c.host = host
+}
+
+// WebURL returns the web page of the repo fullName (owner/name) on this
+// client's host, for when the API response carries no html_url.
+func (c *Client) WebURL(fullName string) string {
+	host := c.host
+	if host == "" {
+		host = "github.com"
+	}
+	return "https://" + host + "/" + fullName
+}
+
+// RepoKey returns the state and database key for owner/name on this
+// client's host.
+func (c *Client) RepoKey(owner, name string) string {
+	return repository.JoinFullName(c.host, owner, name)
+}
+
 // SetHTTPClient sets a custom HTTP client (useful for testing).
 func (c *Client) SetHTTPClient(client *http.Client) {
 	c.httpClient = client
 }
 
 // SetAPIObserver installs a telemetry observer. Pass nil to remove.
+// An observer that implements HTTPCacheObserver also receives the
+// activity of the client's HTTP cache.
 func (c *Client) SetAPIObserver(obs APIObserver) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.observer = obs
+	cache := c.cache
+	c.mu.Unlock()
+	if cacheObs, ok := obs.(HTTPCacheObserver); ok {
+		cache.SetObserver(cacheObs)
+	}
+}
+
+// SetHTTPCache installs an on-disk cache for REST GETs. One cache may
+// be shared by several clients; entries are keyed by the client's
+// credentials as well as the full URL, so clients only hit their own
+// entries. Call before the client is used.
+func (c *Client) SetHTTPCache(cache *HTTPCache) {
+	c.mu.Lock()
+	c.cache = cache
+	obs := c.observer
+	c.mu.Unlock()
+	if cacheObs, ok := obs.(HTTPCacheObserver); ok {
+		cache.SetObserver(cacheObs)
+	}
 }
 
 // notifyCall fires the observer's ObserveCall hook if installed.
@@ -275,14 +456,37 @@ func (c *Client) notifyCall(resource, result string) {
 	}
 }
 
-// notifyRateLimit fires the ObserveRateLimit hook with the current snapshot.
-func (c *Client) notifyRateLimit() {
+// notifyRateLimit fires the ObserveRateLimit hook with the pool-wide
+// snapshot and, when tok is set and the observer wants it, the
+// ObserveTokenRateLimit hook with tok's budget for resource. A GraphQL
+// response also fires ObserveGraphQLRateLimit with the pool-wide
+// GraphQL budget.
+func (c *Client) notifyRateLimit(tok *poolToken, resource string, tokRL RateLimit) {
 	c.mu.RLock()
 	obs := c.observer
 	rl := c.rateLimit
 	c.mu.RUnlock()
-	if obs != nil {
-		obs.ObserveRateLimit(rl.Limit, rl.Remaining, rl.Reset)
+	if obs == nil {
+		return
+	}
+	obs.ObserveRateLimit(rl.Limit, rl.Remaining, rl.Reset)
+	if tokObs, ok := obs.(TokenRateLimitObserver); ok && tok != nil {
+		tokObs.ObserveTokenRateLimit(tok.id, resource, tokRL.Limit, tokRL.Remaining, tokRL.Reset)
+	}
+	if gqlObs, ok := obs.(GraphQLObserver); ok && resource == ResourceGraphQL {
+		gql := c.ResourceRateLimitInfo(ResourceGraphQL)
+		gqlObs.ObserveGraphQLRateLimit(gql.Limit, gql.Remaining, gql.Reset)
+	}
+}
+
+// notifyGraphQLBatch fires the ObserveGraphQLBatch hook if the observer
+// implements GraphQLObserver.
+func (c *Client) notifyGraphQLBatch(repos, cost int) {
+	c.mu.RLock()
+	obs := c.observer
+	c.mu.RUnlock()
+	if gqlObs, ok := obs.(GraphQLObserver); ok {
+		gqlObs.ObserveGraphQLBatch(repos, cost)
 	}
 }
 
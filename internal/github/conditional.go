@@ -103,17 +103,5 @@ func (c *Client) GetRepositoryConditional(ctx context.Context, owner, repo strin
 		return nil, false, nil, fmt.Errorf("decoding response: %w", err)
 	}
 
-	metrics := &RepoMetrics{
-		Owner:       resp.Owner.Login,
-		Name:        resp.Name,
-		FullName:    resp.FullName,
-		Stars:       resp.StargazersCount,
-		Forks:       resp.ForksCount,
-		OpenIssues:  resp.OpenIssuesCount,
-		Language:    resp.Language,
-		Topics:      resp.Topics,
-		Description: resp.Description,
-	}
-
-	return metrics, false, &condResp.Info, nil
+	return resp.toRepoMetrics(), false, &condResp.Info, nil
 }
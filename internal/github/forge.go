@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hrexed/github-radar/internal/forge"
+)
+
+// ForgeProvider exposes a Client as a forge.Provider, so code that
+// works across forges can treat GitHub like GitLab or Gitea. The
+// scanner keeps using the Client directly for conditional requests and
+// the GraphQL bulk path.
+type ForgeProvider struct {
+	client *Client
+}
+
+var _ forge.Provider = (*ForgeProvider)(nil)
+
+// NewForgeProvider wraps client.
+func NewForgeProvider(client *Client) *ForgeProvider {
+	return &ForgeProvider{client: client}
+}
+
+// Client returns the wrapped client.
+func (p *ForgeProvider) Client() *Client {
+	return p.client
+}
+
+// Name implements forge.Provider.
+func (p *ForgeProvider) Name() string {
+	return forge.KindGitHub
+}
+
+// GetRepository implements forge.Provider.
+func (p *ForgeProvider) GetRepository(ctx context.Context, owner, name string) (*forge.Repository, error) {
+	m, err := p.client.GetRepository(ctx, owner, name)
+	if err != nil {
+		if IsAPINotFound(err) || IsNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %v", forge.ErrNotFound, err)
+		}
+		return nil, err
+	}
+	return &forge.Repository{
+		Owner:       m.Owner,
+		Name:        m.Name,
+		FullName:    m.FullName,
+		Description: m.Description,
+		Language:    m.Language,
+		Topics:      m.Topics,
+		Stars:       m.Stars,
+		Forks:       m.Forks,
+		OpenIssues:  m.OpenIssues,
+		Fork:        m.Fork,
+		Upstream:    m.Upstream(),
+		Mirror:      m.Mirror,
+		URL:         p.webURL(m.HTMLURL, m.FullName),
+	}, nil
+}
+
+// GetActivity implements forge.Provider.
+func (p *ForgeProvider) GetActivity(ctx context.Context, owner, name string) (*forge.Activity, error) {
+	m, err := p.client.GetActivityMetrics(ctx, owner, name)
+	if m == nil {
+		return nil, err
+	}
+	a := &forge.Activity{
+		MergedPRs7d:  m.MergedPRs7d,
+		NewIssues7d:  m.NewIssues7d,
+		Contributors: m.Contributors,
+	}
+	if m.LatestRelease != nil {
+		a.LatestRelease = m.LatestRelease.PublishedAt
+	}
+	return a, err
+}
+
+// GetReadme implements forge.Provider.
+func (p *ForgeProvider) GetReadme(ctx context.Context, owner, name string) (*forge.Readme, error) {
+	r, err := p.client.GetReadme(ctx, owner, name, "")
+	if err != nil {
+		return nil, err
+	}
+	return &forge.Readme{Content: r.Content, Found: r.Found}, nil
+}
+
+// SearchRepositories implements forge.Provider.
+func (p *ForgeProvider) SearchRepositories(ctx context.Context, query string, limit int) ([]forge.Repository, error) {
+	results, err := p.client.SearchRepositories(ctx, query, "stars", "desc", limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]forge.Repository, 0, len(results))
+	for _, r := range results {
+		out = append(out, forge.Repository{
+			Owner:       r.Owner,
+			Name:        r.Name,
+			FullName:    r.FullName,
+			Description: r.Description,
+			Language:    r.Language,
+			Topics:      r.Topics,
+			Stars:       r.Stars,
+			Forks:       r.Forks,
+			Fork:        r.Fork,
+			Mirror:      r.Mirror,
+			CreatedAt:   r.CreatedAt,
+			UpdatedAt:   r.UpdatedAt,
+			URL:         p.webURL(r.HTMLURL, r.FullName),
+		})
+	}
+	return out, nil
+}
+
+// webURL prefers the html_url the API returned, which is right for
+// any host, and falls back to one built from the client's host.
+func (p *ForgeProvider) webURL(htmlURL, fullName string) string {
+	if htmlURL != "" {
+		return htmlURL
+	}
+	return p.client.WebURL(fullName)
+}
+
+// RateLimit implements forge.Provider with the pool-wide REST budget.
+func (p *ForgeProvider) RateLimit() forge.RateLimit {
+	rl := p.client.RateLimitInfo()
+	return forge.RateLimit{Limit: rl.Limit, Remaining: rl.Remaining, Reset: rl.Reset}
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/forge"
+	"github.com/hrexed/github-radar/internal/testutil/ghstub"
+)
+
+func TestForgeProvider_Stub(t *testing.T) {
+	stub := ghstub.New(ghstub.Config{})
+	defer stub.Close()
+	client, _ := NewClient("test-token")
+	client.SetBaseURL(stub.URL())
+	p := NewForgeProvider(client)
+	ctx := context.Background()
+
+	repo, err := p.GetRepository(ctx, "acme", "radar")
+	if err != nil {
+		t.Fatalf("GetRepository() error: %v", err)
+	}
+	if repo.FullName != "acme/radar" || repo.Stars != 100 || repo.Forks != 10 || repo.Language != "Go" {
+		t.Errorf("GetRepository() = %+v", repo)
+	}
+
+	a, err := p.GetActivity(ctx, "acme", "radar")
+	if err != nil {
+		t.Fatalf("GetActivity() error: %v", err)
+	}
+	if a.Contributors != 1 || !a.LatestRelease.IsZero() {
+		t.Errorf("GetActivity() = %+v", a)
+	}
+
+	if rl := p.RateLimit(); rl.Limit != 5000 || rl.Remaining >= 5000 {
+		t.Errorf("RateLimit() = %+v, want the stub's budget", rl)
+	}
+}
+
+func TestForgeProvider_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+	client, _ := NewClient("test-token")
+	client.SetBaseURL(server.URL)
+
+	if _, err := NewForgeProvider(client).GetRepository(context.Background(), "acme", "gone"); !errors.Is(err, forge.ErrNotFound) {
+		t.Errorf("GetRepository() error = %v, want forge.ErrNotFound", err)
+	}
+}
+
+func TestForgeProvider_EnterpriseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/radar":
+			w.Write([]byte(`{"owner": {"login": "acme"}, "name": "radar", "full_name": "acme/radar", "html_url": "https://ghe.example.com/acme/radar"}`))
+		case "/search/repositories":
+			w.Write([]byte(`{"total_count": 1, "items": [{"owner": {"login": "acme"}, "name": "tool", "full_name": "acme/tool"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient("test-token")
+	client.SetBaseURL(server.URL)
+	client.SetHost("ghe.example.com")
+	p := NewForgeProvider(client)
+
+	repo, err := p.GetRepository(context.Background(), "acme", "radar")
+	if err != nil {
+		t.Fatalf("GetRepository() error: %v", err)
+	}
+	if repo.URL != "https://ghe.example.com/acme/radar" {
+		t.Errorf("GetRepository() URL = %q, want the API's html_url", repo.URL)
+	}
+
+	// No html_url in the response: built from the client's host.
+	found, err := p.SearchRepositories(context.Background(), "radar", 10)
+	if err != nil {
+		t.Fatalf("SearchRepositories() error: %v", err)
+	}
+	if len(found) != 1 || found[0].URL != "https://ghe.example.com/acme/tool" {
+		t.Errorf("SearchRepositories() = %+v, want a ghe.example.com URL", found)
+	}
+}
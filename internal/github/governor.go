@@ -0,0 +1,230 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// governor.go paces every request a Client sends. Each rate-limit
+// resource (core, search, graphql) gets its own token bucket and cap on
+// requests in flight, set below GitHub's secondary limits. When GitHub
+// answers with a secondary rate limit anyway, the governor holds back
+// every resource until the cooldown is over: the secondary limits are
+// per user, so discovery, scanning and classification sharing a client
+// all have to stop, not just the caller that tripped it.
+
+// DefaultSecondaryCooldown is how long the governor pauses after a
+// secondary rate limit response that carries no Retry-After, as GitHub
+// recommends.
+const DefaultSecondaryCooldown = time.Minute
+
+// secondaryLimitPeekBytes bounds how much of a 403/429 body is read to
+// look for GitHub's secondary rate limit message.
+const secondaryLimitPeekBytes = 4 << 10
+
+// ResourceLimits bounds the requests of one rate-limit resource.
+type ResourceLimits struct {
+	// PerMinute is the sustained request rate. 0 = unlimited.
+	PerMinute int
+	// Burst is how many requests may go back to back after a quiet
+	// period. 0 = 1.
+	Burst int
+	// MaxInFlight caps concurrent requests. 0 = unlimited.
+	MaxInFlight int
+}
+
+// GovernorConfig configures a Governor.
+type GovernorConfig struct {
+	// Limits is keyed by resource (ResourceCore, ResourceSearch,
+	// ResourceGraphQL). A resource without an entry is not paced, but
+	// still waits out cooldowns.
+	Limits map[string]ResourceLimits
+	// Cooldown is the pause after a secondary rate limit response
+	// without Retry-After. 0 = DefaultSecondaryCooldown.
+	Cooldown time.Duration
+}
+
+// DefaultGovernorConfig returns limits that keep one client under
+// GitHub's secondary limits (100 concurrent requests, 900 REST points
+// per minute, 30 searches per minute) with room to spare.
+func DefaultGovernorConfig() GovernorConfig {
+	return GovernorConfig{
+		Limits: map[string]ResourceLimits{
+			ResourceCore:    {PerMinute: 600, Burst: 20, MaxInFlight: 8},
+			ResourceGraphQL: {PerMinute: 120, Burst: 4, MaxInFlight: 2},
+			ResourceSearch:  {PerMinute: 30, Burst: 1, MaxInFlight: 1},
+		},
+		Cooldown: DefaultSecondaryCooldown,
+	}
+}
+
+// Governor paces requests per resource and enforces secondary rate
+// limit cooldowns. It is safe for concurrent use; install it with
+// Client.SetGovernor.
+type Governor struct {
+	cooldown   time.Duration
+	classes    map[string]*governedResource
+	nowFn      func() time.Time
+	onCooldown func(resource string, d time.Duration)
+
+	mu            sync.Mutex
+	cooldownUntil time.Time
+}
+
+// governedResource is one resource's bucket and in-flight slots.
+// tokens and last are guarded by Governor.mu.
+type governedResource struct {
+	perSecond float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+	slots     chan struct{} // nil when in-flight is unlimited
+}
+
+// NewGovernor creates a governor from cfg.
+func NewGovernor(cfg GovernorConfig) *Governor {
+	g := &Governor{
+		cooldown: cfg.Cooldown,
+		classes:  make(map[string]*governedResource, len(cfg.Limits)),
+		nowFn:    time.Now,
+	}
+	if g.cooldown <= 0 {
+		g.cooldown = DefaultSecondaryCooldown
+	}
+	for resource, l := range cfg.Limits {
+		r := &governedResource{perSecond: float64(l.PerMinute) / 60, burst: float64(max(l.Burst, 1))}
+		r.tokens = r.burst
+		if l.MaxInFlight > 0 {
+			r.slots = make(chan struct{}, l.MaxInFlight)
+		}
+		g.classes[resource] = r
+	}
+	return g
+}
+
+// SetOnCooldown installs a callback fired when a secondary rate limit
+// response starts or extends a cooldown. Call before the governor is
+// used.
+func (g *Governor) SetOnCooldown(fn func(resource string, d time.Duration)) {
+	g.onCooldown = fn
+}
+
+// CooldownUntil returns when the current cooldown ends; zero or past
+// when there is none.
+func (g *Governor) CooldownUntil() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.cooldownUntil
+}
+
+// acquire waits for an in-flight slot, the end of any cooldown and a
+// token from resource's bucket. The returned func frees the slot.
+func (g *Governor) acquire(ctx context.Context, resource string) (func(), error) {
+	r := g.classes[resource]
+	release := func() {}
+	if r != nil && r.slots != nil {
+		select {
+		case r.slots <- struct{}{}:
+			release = func() { <-r.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for {
+		wait := g.reserve(r)
+		if wait <= 0 {
+			return release, nil
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token from r when no cooldown is running and one is
+// available, returning 0. Otherwise it returns how long to wait before
+// trying again.
+func (g *Governor) reserve(r *governedResource) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.nowFn()
+	if now.Before(g.cooldownUntil) {
+		return g.cooldownUntil.Sub(now)
+	}
+	if r == nil || r.perSecond <= 0 {
+		return 0
+	}
+	if !r.last.IsZero() {
+		r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.perSecond)
+	}
+	r.last = now
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0
+	}
+	return time.Duration((1 - r.tokens) / r.perSecond * float64(time.Second))
+}
+
+// observe starts a cooldown when resp is a secondary rate limit.
+func (g *Governor) observe(resource string, resp *http.Response) {
+	d, ok := secondaryRateLimit(resp)
+	if !ok {
+		return
+	}
+	if d <= 0 {
+		d = g.cooldown
+	}
+	g.mu.Lock()
+	until := g.nowFn().Add(d)
+	extended := until.After(g.cooldownUntil)
+	if extended {
+		g.cooldownUntil = until
+	}
+	g.mu.Unlock()
+	if extended && g.onCooldown != nil {
+		g.onCooldown(resource, d)
+	}
+}
+
+// secondaryRateLimit reports whether resp is one of GitHub's secondary
+// rate limit responses, and the Retry-After it asked for (0 when none).
+// Those are a 429, or a 403 that carries Retry-After or says "secondary
+// rate limit", while the primary budget is not exhausted. Part of the
+// body may be read; resp.Body is replaced so callers still see all of
+// it.
+func secondaryRateLimit(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return 0, false // primary limit; the token pool handles it
+	}
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if resp.StatusCode == http.StatusTooManyRequests || hasRetryAfter {
+		return retryAfter, true
+	}
+
+	peek, _ := io.ReadAll(io.LimitReader(resp.Body, secondaryLimitPeekBytes))
+	resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(peek), resp.Body), Closer: resp.Body}
+	msg := strings.ToLower(string(peek))
+	return 0, strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse detection")
+}
+
+// SetGovernor installs a request governor. One governor should be
+// shared by every client that uses the same credentials against the
+// same server. Call before the client is used.
+func (c *Client) SetGovernor(g *Governor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.governor = g
+}
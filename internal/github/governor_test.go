@@ -0,0 +1,177 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGovernor_PacesEachResource(t *testing.T) {
+	g := NewGovernor(GovernorConfig{Limits: map[string]ResourceLimits{
+		ResourceSearch: {PerMinute: 1200, Burst: 1}, // one every 50ms
+	}})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := g.acquire(ctx, ResourceSearch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 searches took %v, want >= 150ms at 20/s with burst 1", elapsed)
+	}
+
+	// Core has no entry and is not held back by search's bucket.
+	start = time.Now()
+	for i := 0; i < 50; i++ {
+		release, _ := g.acquire(ctx, ResourceCore)
+		release()
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("unpaced core requests took %v", elapsed)
+	}
+}
+
+func TestGovernor_MaxInFlight(t *testing.T) {
+	g := NewGovernor(GovernorConfig{Limits: map[string]ResourceLimits{
+		ResourceGraphQL: {MaxInFlight: 1},
+	}})
+
+	release, err := g.acquire(context.Background(), ResourceGraphQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := g.acquire(ctx, ResourceGraphQL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second in-flight request: err = %v, want deadline exceeded", err)
+	}
+
+	release()
+	release, err = g.acquire(context.Background(), ResourceGraphQL)
+	if err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+	release()
+}
+
+func TestClient_SecondaryRateLimitPausesEveryResource(t *testing.T) {
+	var searches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/search/") && searches.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "25")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("tkn")
+	client.SetBaseURL(server.URL)
+	g := NewGovernor(GovernorConfig{Cooldown: 150 * time.Millisecond})
+	var cooled []string
+	g.SetOnCooldown(func(resource string, d time.Duration) {
+		cooled = append(cooled, resource+" "+d.String())
+	})
+	client.SetGovernor(g)
+	ctx := context.Background()
+
+	resp, err := client.Get(ctx, "/search/repositories?q=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "secondary rate limit") {
+		t.Errorf("caller lost the response body: %q", body)
+	}
+	if len(cooled) != 1 || cooled[0] != "search 150ms" {
+		t.Errorf("cooldowns = %v, want one 150ms search cooldown", cooled)
+	}
+
+	// A REST call from another part of the daemon waits it out too.
+	start := time.Now()
+	resp, err = client.Get(ctx, "/repos/o/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("core request sent %v into the cooldown, want it held until the end", elapsed)
+	}
+}
+
+func TestDoWithRetry_RetriesSecondaryRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("tkn")
+	client.SetBaseURL(server.URL)
+	client.SetRetryConfig(RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond})
+
+	resp, err := client.GetWithRetry(context.Background(), "/repos/o/r")
+	if err != nil {
+		t.Fatalf("GetWithRetry: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("status %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestSecondaryRateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     map[string]string
+		body       string
+		want       bool
+		retryAfter time.Duration
+	}{
+		{"429 with retry-after", 429, map[string]string{"Retry-After": "7"}, "", true, 7 * time.Second},
+		{"403 with retry-after", 403, map[string]string{"Retry-After": "30"}, "", true, 30 * time.Second},
+		{"403 secondary message", 403, nil, `{"message":"You have exceeded a secondary rate limit"}`, true, 0},
+		{"403 abuse message", 403, nil, `{"message":"You have triggered an abuse detection mechanism"}`, true, 0},
+		{"403 primary exhausted", 403, map[string]string{"X-RateLimit-Remaining": "0", "Retry-After": "60"}, "", false, 0},
+		{"403 forbidden", 403, nil, `{"message":"Resource not accessible by integration"}`, false, 0},
+		{"500", 500, map[string]string{"Retry-After": "1"}, "", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			for k, v := range tt.header {
+				resp.Header.Set(k, v)
+			}
+			d, ok := secondaryRateLimit(resp)
+			if ok != tt.want || d != tt.retryAfter {
+				t.Errorf("secondaryRateLimit = %v, %v; want %v, %v", d, ok, tt.retryAfter, tt.want)
+			}
+			if body, _ := io.ReadAll(resp.Body); string(body) != tt.body {
+				t.Errorf("body after peek = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
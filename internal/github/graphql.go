@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,10 +13,12 @@ import (
 )
 
 // MaxGraphQLBatchSize is the maximum number of repos queried in a single
-// GraphQL request. GitHub enforces a per-query resource budget that is
-// independent of the rate-limit point bucket; once it trips, the server
-// returns HTTP 200 with partial `data` and per-alias
-// `RESOURCE_LIMITS_EXCEEDED` errors for the abandoned tail.
+// GraphQL request, and the size BulkFetchMetadata starts from before the
+// batch sizer (graphql_batch.go) has seen any query costs. GitHub
+// enforces a per-query resource budget that is independent of the
+// rate-limit point bucket; once it trips, the server returns HTTP 200
+// with partial `data` and per-alias `RESOURCE_LIMITS_EXCEEDED` errors for
+// the abandoned tail.
 //
 // After T5b (ISI-765) folded `recentMergedPRs(first: 100)`,
 // `recentIssues(first: 100)`, `mentionableUsers`, and `latestReleases`
@@ -32,12 +35,14 @@ import (
 // probe before raising this constant.
 const MaxGraphQLBatchSize = 25
 
-// graphqlEndpoint is the path relative to the configured base URL.
+// graphqlEndpoint is the path relative to the configured base URL,
+// unless SetGraphQLURL overrides it.
 const graphqlEndpoint = "/graphql"
 
 // BulkFetchResult contains the outcome of a GraphQL bulk metadata fetch.
 type BulkFetchResult struct {
-	// Metrics is keyed by "owner/name".
+	// Metrics is keyed by the requested "owner/name"; FullName on the
+	// value carries the current name when the repo has been renamed.
 	Metrics map[string]*RepoMetrics
 	// NotFound contains repos whose alias resolved to null (deleted/renamed).
 	NotFound []string
@@ -46,8 +51,11 @@ type BulkFetchResult struct {
 	// batches still persist; callers can inspect this slice to decide
 	// whether to escalate or rely on the next refresh tick.
 	FailedBatches []BatchFailure
-	// QueryCount is the number of HTTP requests issued (one per batch).
+	// QueryCount is the number of HTTP requests issued (one per batch,
+	// including the smaller batches an oversized one was split into).
 	QueryCount int
+	// Cost is the total GraphQL rate-limit points GitHub charged.
+	Cost int
 }
 
 // BatchFailure describes one batch that failed all retry attempts.
@@ -65,6 +73,11 @@ const MaxConsecutiveBatchFailures = 3
 
 // BulkFetchMetadata fetches repository metadata for the given repos in
 // batches of up to MaxGraphQLBatchSize, using GraphQL with aliased fields.
+// The batch size adapts to the cost GitHub reports for each query (see
+// graphql_batch.go). A batch that trips GitHub's per-query resource limit
+// is split, and the repos it did not return are fetched in smaller
+// batches; only what still fails at one repo per query reaches
+// FailedBatches.
 //
 // Each GraphQL request counts as ONE against the REST-equivalent rate
 // limit bucket shared with REST (GitHub's v4 GraphQL rate limit uses a
@@ -84,11 +97,8 @@ func (c *Client) BulkFetchMetadata(ctx context.Context, repos []Repo) (*BulkFetc
 	}
 
 	consecutiveFailures := 0
-	for start := 0; start < len(repos); start += MaxGraphQLBatchSize {
-		end := start + MaxGraphQLBatchSize
-		if end > len(repos) {
-			end = len(repos)
-		}
+	for start, end := 0, 0; start < len(repos); start = end {
+		end = min(start+c.batchSizer.next(), len(repos))
 		batch := repos[start:end]
 
 		// Honour caller cancellation between batches so a late ctx
@@ -97,8 +107,12 @@ func (c *Client) BulkFetchMetadata(ctx context.Context, repos []Repo) (*BulkFetc
 			return result, err
 		}
 
-		err := c.bulkFetchBatch(ctx, batch, result)
-		result.QueryCount++
+		err := c.bulkFetchSplitting(ctx, batch, result)
+		if isGatewayTimeout(err) {
+			// Retries at the same size did not help; assume the
+			// batch ran too long and shrink the ones that follow.
+			c.batchSizer.tooHeavy(len(batch))
+		}
 		if err != nil {
 			result.FailedBatches = append(result.FailedBatches, BatchFailure{
 				Start: start,
@@ -120,6 +134,51 @@ func (c *Client) BulkFetchMetadata(ctx context.Context, repos []Repo) (*BulkFetc
 	return result, nil
 }
 
+// bulkFetchSplitting fetches batch and, when GitHub abandons part of it
+// under the per-query resource limit, halves the batch size and fetches
+// the repos it did not return in smaller batches. It returns the first
+// error that splitting cannot get around.
+func (c *Client) bulkFetchSplitting(ctx context.Context, batch []Repo, out *BulkFetchResult) error {
+	err := c.bulkFetchBatch(ctx, batch, out)
+	out.QueryCount++
+	var rle *resourceLimitError
+	if !errors.As(err, &rle) || len(batch) == 1 {
+		return err
+	}
+	c.batchSizer.tooHeavy(len(batch))
+
+	pending := unresolvedRepos(batch, out)
+	half := max(1, len(batch)/2)
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := min(c.batchSizer.next(), half, len(pending))
+		if err := c.bulkFetchSplitting(ctx, pending[:n], out); err != nil {
+			return err
+		}
+		pending = pending[n:]
+	}
+	return nil
+}
+
+// unresolvedRepos returns the repos of batch that have neither metrics
+// nor a NotFound entry in out.
+func unresolvedRepos(batch []Repo, out *BulkFetchResult) []Repo {
+	gone := make(map[string]bool, len(out.NotFound))
+	for _, fn := range out.NotFound {
+		gone[fn] = true
+	}
+	var pending []Repo
+	for _, r := range batch {
+		fn := fmt.Sprintf("%s/%s", r.Owner, r.Name)
+		if out.Metrics[fn] == nil && !gone[fn] {
+			pending = append(pending, r)
+		}
+	}
+	return pending
+}
+
 // graphqlActivityNodePage bounds how many merged-PR / recent-issue nodes
 // we request per repo for the 7-day activity-window count (ISI-765 T5b).
 // 100 is GitHub's per-connection page maximum; repos that merge or open
@@ -143,6 +202,10 @@ const graphqlRepoFragment = `fragment RepoFields on Repository {
   primaryLanguage { name }
   repositoryTopics(first: 20) { nodes { topic { name } } }
   description
+  isFork
+  isMirror
+  mirrorUrl
+  parent { nameWithOwner }
   recentMergedPRs: pullRequests(states: MERGED, first: 100, orderBy: {field: UPDATED_AT, direction: DESC}) {
     nodes { mergedAt updatedAt }
     pageInfo { hasNextPage }
@@ -171,7 +234,7 @@ func (c *Client) bulkFetchBatch(ctx context.Context, batch []Repo, out *BulkFetc
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
-		c.baseURL+graphqlEndpoint,
+		c.GraphQLURL(),
 		bytes.NewReader(reqBody),
 	)
 	if err != nil {
@@ -185,6 +248,7 @@ func (c *Client) bulkFetchBatch(ctx context.Context, batch []Repo, out *BulkFetc
 		return io.NopCloser(bytes.NewReader(reqBody)), nil
 	}
 
+	started := time.Now()
 	resp, err := c.DoWithRetry(req)
 	if err != nil {
 		c.notifyCall("graphql", "error")
@@ -216,6 +280,11 @@ func (c *Client) bulkFetchBatch(ctx context.Context, batch []Repo, out *BulkFetc
 		return fmt.Errorf("graphql errors: %s", formatGraphQLErrors(envelope.Errors))
 	}
 
+	cost := decodeGraphQLRateLimit(envelope.Data)
+	out.Cost += cost.Cost
+	c.usage.graphqlPoints(cost.Cost)
+	c.notifyGraphQLBatch(len(batch), cost.Cost)
+
 	// ISI-983: GitHub aborts the per-query resource budget mid-document by
 	// returning HTTP 200 with the consumed-up-to-that-point aliases
 	// populated, the remainder set to `null`, and one or more
@@ -266,20 +335,36 @@ func (c *Client) bulkFetchBatch(ctx context.Context, batch []Repo, out *BulkFetc
 			return fmt.Errorf("decode alias %s: %w", alias, err)
 		}
 
+		// Results are keyed by the requested name so callers can look
+		// them up by what they asked for. `repository(owner:, name:)`
+		// resolves renamed repos to their new location, in which case
+		// nameWithOwner (and thus FullName) differs from the key —
+		// callers detect that via RepoMetrics.RenamedFrom.
+		requested := fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
 		fullName := node.NameWithOwner
 		if fullName == "" {
-			fullName = fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
+			fullName = requested
+		}
+		owner, name := repo.Owner, repo.Name
+		if i := strings.IndexByte(fullName, '/'); i > 0 {
+			owner, name = fullName[:i], fullName[i+1:]
 		}
 
 		metrics := &RepoMetrics{
-			Owner:       repo.Owner,
-			Name:        repo.Name,
+			Owner:       owner,
+			Name:        name,
 			FullName:    fullName,
 			Stars:       node.StargazerCount,
 			Forks:       node.ForkCount,
 			OpenIssues:  node.Issues.TotalCount,
 			OpenPRs:     node.PullRequests.TotalCount,
 			Description: node.Description,
+			Fork:        node.IsFork,
+			Mirror:      node.IsMirror,
+			MirrorURL:   node.MirrorURL,
+		}
+		if node.Parent != nil {
+			metrics.Parent = node.Parent.NameWithOwner
 		}
 		if node.PrimaryLanguage != nil {
 			metrics.Language = node.PrimaryLanguage.Name
@@ -292,20 +377,19 @@ func (c *Client) bulkFetchBatch(ctx context.Context, batch []Repo, out *BulkFetc
 		metrics.Activity = activity
 		metrics.ActivityTruncated = truncated
 
-		out.Metrics[fullName] = metrics
+		out.Metrics[requested] = metrics
 	}
 
 	// Surface RESOURCE_LIMITS_EXCEEDED at the batch level so the outer
-	// loop records this in FailedBatches and the operator sees the real
-	// failure mode (batch too heavy for the fragment) instead of a healthy
-	// stream of phantom NotFound entries. ASCII `--` (not U+2014) keeps the
-	// error string greppable by log-shippers and Dynatrace DQL `contains`
-	// matchers that don't normalize Unicode dashes.
+	// loop splits the batch, or records it in FailedBatches when it is a
+	// single repo, and the operator sees the real failure mode (batch too
+	// heavy for the fragment) instead of a healthy stream of phantom
+	// NotFound entries.
 	if hadResourceLimit {
-		return fmt.Errorf("graphql RESOURCE_LIMITS_EXCEEDED on %d/%d aliases -- batch too heavy for fragment (ISI-983)",
-			len(resourceLimitedAliases), len(batch))
+		return &resourceLimitError{limited: len(resourceLimitedAliases), total: len(batch)}
 	}
 
+	c.batchSizer.observe(len(batch), cost, time.Since(started))
 	return nil
 }
 
@@ -325,6 +409,7 @@ func buildBulkQuery(batch []Repo) (string, map[string]Repo) {
 			alias, r.Owner, r.Name,
 		))
 	}
+	sb.WriteString(graphqlRateLimitQuery)
 	sb.WriteString("}\n")
 	sb.WriteString(graphqlRepoFragment)
 	return sb.String(), aliasMap
@@ -360,12 +445,20 @@ type graphqlRepoNode struct {
 	PrimaryLanguage  *graphqlLanguage      `json:"primaryLanguage"`
 	RepositoryTopics graphqlTopicResults   `json:"repositoryTopics"`
 	Description      string                `json:"description"`
+	IsFork           bool                  `json:"isFork"`
+	IsMirror         bool                  `json:"isMirror"`
+	MirrorURL        string                `json:"mirrorUrl"`
+	Parent           *graphqlRepoRef       `json:"parent"`
 	RecentMergedPRs  graphqlMergedPRsPage  `json:"recentMergedPRs"`
 	RecentIssues     graphqlIssuesPage     `json:"recentIssues"`
 	MentionableUsers graphqlTotalCount     `json:"mentionableUsers"`
 	LatestReleases   graphqlReleasesResult `json:"latestReleases"`
 }
 
+type graphqlRepoRef struct {
+	NameWithOwner string `json:"nameWithOwner"`
+}
+
 type graphqlTotalCount struct {
 	TotalCount int `json:"totalCount"`
 }
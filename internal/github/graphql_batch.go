@@ -0,0 +1,197 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// graphql_batch.go sizes BulkFetchMetadata batches from what GitHub
+// reports about each query. Every bulk query also selects
+// `rateLimit { cost limit nodeCount remaining resetAt }`; the sizer keeps
+// a moving average of the points, nodes and server time each repo costs
+// and picks the largest batch that stays under GitHub's node and time
+// limits, never above MaxGraphQLBatchSize. A batch that trips the
+// per-query resource limit halves the size, and the repos it did not
+// return are re-queried in smaller batches instead of being left to the
+// REST fallback.
+
+const (
+	// graphqlNodeLimit is GitHub's per-query node limit. Batches are
+	// sized to use at most half of it.
+	graphqlNodeLimit = 500_000
+
+	// graphqlBatchTimeTarget is the server time a batch is sized for.
+	// GitHub abandons queries after 10s, and the proxy in front of it
+	// answered 502 well before that in the ISI-983 probe.
+	graphqlBatchTimeTarget = 4 * time.Second
+
+	// graphqlCostSmoothing is the weight of the newest batch in the
+	// per-repo moving averages.
+	graphqlCostSmoothing = 0.3
+)
+
+// graphqlRateLimitQuery is selected next to the aliased repos in every
+// bulk query.
+const graphqlRateLimitQuery = "  rateLimit { cost limit nodeCount remaining resetAt }\n"
+
+// graphqlRateLimit is the decoded `rateLimit` object of a bulk query.
+type graphqlRateLimit struct {
+	Cost      int       `json:"cost"`
+	Limit     int       `json:"limit"`
+	NodeCount int       `json:"nodeCount"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// decodeGraphQLRateLimit reads the `rateLimit` object out of a bulk
+// response. It is zero when the server did not return one.
+func decodeGraphQLRateLimit(data map[string]json.RawMessage) graphqlRateLimit {
+	var rl graphqlRateLimit
+	if raw, ok := data["rateLimit"]; ok {
+		_ = json.Unmarshal(raw, &rl)
+	}
+	return rl
+}
+
+// GraphQLObserver is optionally implemented by an APIObserver that
+// wants the GraphQL point budget, which GitHub keeps separately from
+// the REST ("core") budget reported through ObserveRateLimit.
+type GraphQLObserver interface {
+	// ObserveGraphQLRateLimit receives the pool-wide GraphQL budget
+	// after each GraphQL response.
+	ObserveGraphQLRateLimit(limit, remaining int, resetAt time.Time)
+
+	// ObserveGraphQLBatch is invoked once per bulk query with the
+	// number of repos it asked for and the points GitHub charged.
+	ObserveGraphQLBatch(repos, cost int)
+}
+
+// GraphQLBatchStats is the bulk-fetch sizer's current view.
+type GraphQLBatchStats struct {
+	// BatchSize is the number of repos the next bulk query will ask for.
+	BatchSize int
+	// CostPerRepo is the moving average of rate-limit points per repo.
+	CostPerRepo float64
+	// NodesPerRepo is the moving average of query nodes per repo.
+	NodesPerRepo float64
+	// TimePerRepo is the moving average of request time per repo.
+	TimePerRepo time.Duration
+}
+
+// graphqlBatchSizer adapts the bulk batch size. The zero value starts at
+// MaxGraphQLBatchSize.
+type graphqlBatchSizer struct {
+	mu           sync.Mutex
+	size         int
+	costPerRepo  float64
+	nodesPerRepo float64
+	secsPerRepo  float64
+}
+
+// next returns the size of the next batch.
+func (s *graphqlBatchSizer) next() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sizeLocked()
+}
+
+func (s *graphqlBatchSizer) sizeLocked() int {
+	if s.size <= 0 {
+		return MaxGraphQLBatchSize
+	}
+	return s.size
+}
+
+// observe folds one successful batch into the averages and moves the
+// size towards the largest batch the node and time limits allow,
+// growing by at most one repo per batch.
+func (s *graphqlBatchSizer) observe(repos int, rl graphqlRateLimit, elapsed time.Duration) {
+	if repos <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rl.Cost > 0 {
+		s.costPerRepo = smooth(s.costPerRepo, float64(rl.Cost)/float64(repos))
+	}
+	if rl.NodeCount > 0 {
+		s.nodesPerRepo = smooth(s.nodesPerRepo, float64(rl.NodeCount)/float64(repos))
+	}
+	s.secsPerRepo = smooth(s.secsPerRepo, elapsed.Seconds()/float64(repos))
+
+	limit := MaxGraphQLBatchSize
+	if s.nodesPerRepo > 0 {
+		limit = min(limit, int(graphqlNodeLimit/2/s.nodesPerRepo))
+	}
+	if s.secsPerRepo > 0 {
+		limit = min(limit, int(graphqlBatchTimeTarget.Seconds()/s.secsPerRepo))
+	}
+	s.size = max(1, min(s.sizeLocked()+1, limit))
+}
+
+// tooHeavy records that a batch of repos exceeded GitHub's per-query
+// limits: the next batch is at most half as large.
+func (s *graphqlBatchSizer) tooHeavy(repos int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = max(1, min(s.sizeLocked(), repos/2))
+}
+
+func (s *graphqlBatchSizer) stats() GraphQLBatchStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return GraphQLBatchStats{
+		BatchSize:    s.sizeLocked(),
+		CostPerRepo:  s.costPerRepo,
+		NodesPerRepo: s.nodesPerRepo,
+		TimePerRepo:  time.Duration(s.secsPerRepo * float64(time.Second)),
+	}
+}
+
+// smooth is one step of an exponentially weighted moving average; the
+// first sample is taken as is.
+func smooth(avg, sample float64) float64 {
+	if avg == 0 {
+		return sample
+	}
+	return avg + graphqlCostSmoothing*(sample-avg)
+}
+
+// GraphQLBatchStats returns the bulk-fetch sizer's current batch size
+// and per-repo cost estimates.
+func (c *Client) GraphQLBatchStats() GraphQLBatchStats {
+	return c.batchSizer.stats()
+}
+
+// resourceLimitError is returned by bulkFetchBatch when GitHub abandoned
+// part of the query under its per-query resource limit.
+type resourceLimitError struct {
+	limited, total int
+}
+
+// ASCII `--` (not U+2014) keeps the error string greppable by
+// log-shippers and Dynatrace DQL `contains` matchers that don't
+// normalize Unicode dashes.
+func (e *resourceLimitError) Error() string {
+	return fmt.Sprintf("graphql RESOURCE_LIMITS_EXCEEDED on %d/%d aliases -- batch too heavy for fragment (ISI-983)",
+		e.limited, e.total)
+}
+
+// isGatewayTimeout reports whether err is a 502/504 that survived the
+// retry budget, which for a bulk query usually means it ran too long.
+func isGatewayTimeout(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusBadGateway || httpErr.StatusCode == http.StatusGatewayTimeout
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusBadGateway || apiErr.StatusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// graphqlObserver records GraphQL budget and batch events.
+type graphqlObserver struct {
+	mu        sync.Mutex
+	batches   []int
+	cost      int
+	remaining int
+	coreCalls int
+}
+
+func (o *graphqlObserver) ObserveCall(string, string) {}
+func (o *graphqlObserver) ObserveRateLimit(int, int, time.Time) {
+	o.mu.Lock()
+	o.coreCalls++
+	o.mu.Unlock()
+}
+func (o *graphqlObserver) ObserveGraphQLRateLimit(_, remaining int, _ time.Time) {
+	o.mu.Lock()
+	o.remaining = remaining
+	o.mu.Unlock()
+}
+func (o *graphqlObserver) ObserveGraphQLBatch(repos, cost int) {
+	o.mu.Lock()
+	o.batches = append(o.batches, repos)
+	o.cost += cost
+	o.mu.Unlock()
+}
+
+func TestBulkFetchMetadata_SizesBatchesFromNodeCount(t *testing.T) {
+	// Each repo costs 25,000 nodes, so only 10 fit in half of GitHub's
+	// 500,000-node limit.
+	remaining := 5000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var env struct {
+			Query string `json:"query"`
+		}
+		_ = json.Unmarshal(body, &env)
+		if !strings.Contains(env.Query, "rateLimit { cost") {
+			t.Errorf("bulk query does not select rateLimit:\n%s", env.Query)
+		}
+		n := strings.Count(env.Query, "repository(owner")
+		remaining -= n
+
+		data := map[string]interface{}{
+			"rateLimit": map[string]interface{}{
+				"cost": n, "limit": 5000, "nodeCount": n * 25000,
+				"remaining": remaining, "resetAt": "2100-01-01T00:00:00Z",
+			},
+		}
+		for i := 0; i < n; i++ {
+			data["r"+itoa(i)] = map[string]interface{}{"stargazerCount": i}
+		}
+		w.Header().Set("X-RateLimit-Resource", "graphql")
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		payload, _ := json.Marshal(map[string]interface{}{"data": data})
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("tkn")
+	client.SetBaseURL(server.URL)
+	obs := &graphqlObserver{}
+	client.SetAPIObserver(obs)
+
+	repos := make([]Repo, 60)
+	for i := range repos {
+		repos[i] = Repo{Owner: "o", Name: "r" + itoa(i)}
+	}
+	out, err := client.BulkFetchMetadata(context.Background(), repos)
+	if err != nil {
+		t.Fatalf("BulkFetchMetadata: %v", err)
+	}
+	if len(out.Metrics) != len(repos) {
+		t.Errorf("Metrics = %d, want %d", len(out.Metrics), len(repos))
+	}
+	if want := []int{25, 10, 10, 10, 5}; !slices.Equal(obs.batches, want) {
+		t.Errorf("batch sizes = %v, want %v", obs.batches, want)
+	}
+	if out.Cost != 60 || obs.cost != 60 {
+		t.Errorf("cost = %d (observer %d), want 60", out.Cost, obs.cost)
+	}
+	stats := client.GraphQLBatchStats()
+	if stats.CostPerRepo != 1 || stats.NodesPerRepo != 25000 || stats.BatchSize != 10 {
+		t.Errorf("stats = %+v, want 1 point and 25000 nodes per repo, batch 10", stats)
+	}
+
+	// The GraphQL budget is reported separately from the REST one.
+	if obs.remaining != 4940 {
+		t.Errorf("GraphQL remaining = %d, want 4940", obs.remaining)
+	}
+	if rl := client.RateLimitInfo(); rl.Limit != 0 {
+		t.Errorf("REST budget = %+v, want untouched by GraphQL responses", rl)
+	}
+}
+
+func TestGraphQLBatchSizer(t *testing.T) {
+	var s graphqlBatchSizer
+	if got := s.next(); got != MaxGraphQLBatchSize {
+		t.Fatalf("zero sizer = %d, want %d", got, MaxGraphQLBatchSize)
+	}
+
+	// Slow batches shrink the size to what fits the time target.
+	s.observe(25, graphqlRateLimit{}, 25*time.Second)
+	if got := s.next(); got != 4 {
+		t.Errorf("after 1s/repo: size = %d, want 4", got)
+	}
+
+	// Fast batches grow it back one repo at a time.
+	s = graphqlBatchSizer{size: 4}
+	s.observe(4, graphqlRateLimit{Cost: 2}, 40*time.Millisecond)
+	if got := s.next(); got != 5 {
+		t.Errorf("after a fast batch: size = %d, want 5", got)
+	}
+	for i := 0; i < 100; i++ {
+		s.observe(s.next(), graphqlRateLimit{}, time.Millisecond)
+	}
+	if got := s.next(); got != MaxGraphQLBatchSize {
+		t.Errorf("size grew to %d, want capped at %d", got, MaxGraphQLBatchSize)
+	}
+
+	s.tooHeavy(9)
+	if got := s.next(); got != 4 {
+		t.Errorf("after a too-heavy batch of 9: size = %d, want 4", got)
+	}
+	s.tooHeavy(1)
+	if got := s.next(); got != 1 {
+		t.Errorf("size = %d, want floor of 1", got)
+	}
+}
+
+func TestBulkFetchMetadata_GatewayTimeoutShrinksBatches(t *testing.T) {
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		n := strings.Count(string(body), "repository(owner")
+		sizes = append(sizes, n)
+		if n > 12 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		data := map[string]interface{}{}
+		for i := 0; i < n; i++ {
+			data["r"+itoa(i)] = map[string]interface{}{"stargazerCount": i}
+		}
+		payload, _ := json.Marshal(map[string]interface{}{"data": data})
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("tkn")
+	client.SetBaseURL(server.URL)
+	client.SetRetryConfig(RetryConfig{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond})
+
+	repos := make([]Repo, 40)
+	for i := range repos {
+		repos[i] = Repo{Owner: "o", Name: "r" + itoa(i)}
+	}
+	out, err := client.BulkFetchMetadata(context.Background(), repos)
+	if err != nil {
+		t.Fatalf("BulkFetchMetadata: %v", err)
+	}
+	// The first batch of 25 times out (after its retry) and is left to
+	// the REST fallback; the rest of the sweep runs at 12.
+	if len(out.FailedBatches) != 1 || out.FailedBatches[0].End != 25 {
+		t.Errorf("FailedBatches = %+v, want the first batch only", out.FailedBatches)
+	}
+	if want := []int{25, 25, 12, 3}; !slices.Equal(sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", sizes, want)
+	}
+}
@@ -397,96 +397,102 @@ func TestBulkFetchMetadata_RetryReplaysBody(t *testing.T) {
 // the scanner logs as "Repo not found via graphql") silently mis-classifies
 // healthy public repos and corrupts the drain/exclude pipeline downstream.
 //
-// The batch must surface as a FailedBatches entry so the caller can retry
-// at smaller fan-out instead of poisoning per-repo state.
+// The abandoned aliases must instead be re-queried in smaller batches, and
+// the batch size must shrink for the rest of the sweep.
 func TestBulkFetchMetadata_ResourceLimitsExceeded(t *testing.T) {
-	// Build a 5-repo batch and let GitHub-fixture mimic the production wire
-	// shape: r0/r1 with real data, r2/r3/r4 as `null` with matching
-	// RESOURCE_LIMITS_EXCEEDED errors keyed by `path`.
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	// The fixture evaluates at most two aliases per query: anything past
+	// r1 comes back `null` with a RESOURCE_LIMITS_EXCEEDED error keyed by
+	// `path`, as in production.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{
-			"data": {
-				"r0": {
-					"nameWithOwner": "o/r0",
-					"stargazerCount": 1, "forkCount": 0,
-					"issues": {"totalCount": 0},
-					"pullRequests": {"totalCount": 0},
-					"primaryLanguage": null,
-					"repositoryTopics": {"nodes": []},
-					"description": ""
-				},
-				"r1": {
-					"nameWithOwner": "o/r1",
-					"stargazerCount": 2, "forkCount": 0,
-					"issues": {"totalCount": 0},
-					"pullRequests": {"totalCount": 0},
-					"primaryLanguage": null,
-					"repositoryTopics": {"nodes": []},
-					"description": ""
-				},
-				"r2": null,
-				"r3": null,
-				"r4": null
-			},
-			"errors": [
-				{"type": "RESOURCE_LIMITS_EXCEEDED", "message": "Resource limits for this query exceeded.", "path": ["r2", "nameWithOwner"]},
-				{"type": "RESOURCE_LIMITS_EXCEEDED", "message": "Resource limits for this query exceeded.", "path": ["r3", "nameWithOwner"]},
-				{"type": "RESOURCE_LIMITS_EXCEEDED", "message": "Resource limits for this query exceeded.", "path": ["r4", "mentionableUsers", "totalCount"]}
-			]
-		}`))
+		w.Write(partialBulkResponse(t, r, 2))
 	}))
 	defer server.Close()
 
 	client, _ := NewClient("tkn")
 	client.SetBaseURL(server.URL)
 
-	repos := []Repo{
-		{Owner: "o", Name: "r0"}, // populated
-		{Owner: "o", Name: "r1"}, // populated
-		{Owner: "o", Name: "r2"}, // null + RESOURCE_LIMITS_EXCEEDED
-		{Owner: "o", Name: "r3"}, // null + RESOURCE_LIMITS_EXCEEDED
-		{Owner: "o", Name: "r4"}, // null + RESOURCE_LIMITS_EXCEEDED
+	repos := make([]Repo, 5)
+	for i := range repos {
+		repos[i] = Repo{Owner: "o", Name: "r" + itoa(i)}
 	}
 	out, err := client.BulkFetchMetadata(context.Background(), repos)
 	if err != nil {
 		t.Fatalf("BulkFetchMetadata: %v", err)
 	}
 
-	// The populated aliases must still surface as metrics — partial data is
-	// strictly more useful than nothing, and matches existing partial-error
-	// semantics.
-	if _, ok := out.Metrics["o/r0"]; !ok {
-		t.Errorf("expected partial metrics for o/r0 (it had a populated `data` slot)")
-	}
-	if _, ok := out.Metrics["o/r1"]; !ok {
-		t.Errorf("expected partial metrics for o/r1")
+	// Every repo must surface with its own data once the batch is split.
+	for _, r := range repos {
+		fn := r.Owner + "/" + r.Name
+		if m := out.Metrics[fn]; m == nil || m.FullName != fn {
+			t.Errorf("Metrics[%s] = %+v, want the repo's own metrics", fn, m)
+		}
 	}
 
 	// The resource-limited aliases must NOT be reported as NotFound — they
 	// were never evaluated. NotFound triggers the scanner's
 	// "Repo not found via graphql" log line which is reserved for repos that
 	// actually disappeared from GitHub (deleted, renamed, made private).
-	for _, gone := range out.NotFound {
-		if gone == "o/r2" || gone == "o/r3" || gone == "o/r4" {
-			t.Errorf("alias hit by RESOURCE_LIMITS_EXCEEDED was mis-reported as NotFound: %s", gone)
-		}
+	if len(out.NotFound) != 0 {
+		t.Errorf("aliases hit by RESOURCE_LIMITS_EXCEEDED were mis-reported as NotFound: %v", out.NotFound)
 	}
 
-	// The batch must surface in FailedBatches so the caller can retry it
-	// (typically at a smaller fan-out on the next refresh tick) and so
-	// observability shows the real failure mode instead of a healthy stream
-	// of phantom NotFound entries.
-	if len(out.FailedBatches) != 1 {
-		t.Fatalf("expected exactly 1 FailedBatches entry (the resource-limited batch), got %d: %+v",
-			len(out.FailedBatches), out.FailedBatches)
+	// Splitting recovered everything, so nothing is left for the REST
+	// fallback.
+	if len(out.FailedBatches) != 0 {
+		t.Errorf("FailedBatches = %+v, want none after splitting", out.FailedBatches)
 	}
-	if !strings.Contains(out.FailedBatches[0].Err.Error(), "RESOURCE_LIMITS_EXCEEDED") {
-		t.Errorf("FailedBatches[0].Err = %v, want one referencing RESOURCE_LIMITS_EXCEEDED",
-			out.FailedBatches[0].Err)
+	// [r0..r4] → r0, r1; then the three abandoned repos at half the size:
+	// [r2, r3], [r4].
+	if out.QueryCount != 3 {
+		t.Errorf("QueryCount = %d, want 3 (1 oversized + 2 split batches)", out.QueryCount)
+	}
+	if got := client.GraphQLBatchStats().BatchSize; got >= len(repos) {
+		t.Errorf("BatchSize = %d after a resource-limited batch of %d, want smaller", got, len(repos))
 	}
 }
 
+// partialBulkResponse answers a bulk query the way GitHub does when the
+// per-query resource budget runs out after `evaluated` aliases.
+func partialBulkResponse(t *testing.T, r *http.Request, evaluated int) []byte {
+	t.Helper()
+	body, _ := io.ReadAll(r.Body)
+	var env struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+
+	data := map[string]interface{}{}
+	var errs []map[string]interface{}
+	for i, line := range strings.Split(env.Query, "\n") {
+		var alias, owner, name string
+		if _, err := fmt.Sscanf(strings.TrimSpace(line), "%s repository(owner: %q, name: %q)", &alias, &owner, &name); err != nil {
+			continue
+		}
+		alias = strings.TrimSuffix(alias, ":")
+		if len(data) >= evaluated {
+			data[alias] = nil
+			errs = append(errs, map[string]interface{}{
+				"type":    "RESOURCE_LIMITS_EXCEEDED",
+				"message": "Resource limits for this query exceeded.",
+				"path":    []string{alias, "nameWithOwner"},
+			})
+			continue
+		}
+		data[alias] = map[string]interface{}{
+			"nameWithOwner":    owner + "/" + name,
+			"stargazerCount":   i,
+			"issues":           map[string]int{"totalCount": 0},
+			"pullRequests":     map[string]int{"totalCount": 0},
+			"repositoryTopics": map[string]interface{}{"nodes": []interface{}{}},
+		}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{"data": data, "errors": errs})
+	return payload
+}
+
 // TestBulkFetchMetadata_ResourceLimitsExceeded_NoPath is the defensive-sentinel
 // regression for ISI-983 review-L1: GitHub today always emits
 // `RESOURCE_LIMITS_EXCEEDED` errors keyed by `path[0]=alias`, but if it ever
@@ -691,3 +697,48 @@ func itoa(n int) string {
 	}
 	return string(buf[i:])
 }
+
+// TestBulkFetchMetadata_RenamedAndFork — results are keyed by the
+// requested name even when GraphQL resolves a rename, and fork / mirror
+// identity is decoded from the fragment.
+func TestBulkFetchMetadata_RenamedAndFork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "isFork") || !strings.Contains(string(body), "parent { nameWithOwner }") {
+			t.Errorf("fragment missing fork fields: %s", body)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data": {
+			"r0": {"nameWithOwner": "new/name", "stargazerCount": 10},
+			"r1": {"nameWithOwner": "me/fork", "isFork": true, "parent": {"nameWithOwner": "up/stream"}},
+			"r2": {"nameWithOwner": "m/mirror", "isMirror": true, "mirrorUrl": "https://example.org/x.git"}
+		}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("tkn")
+	client.SetBaseURL(server.URL)
+
+	out, err := client.BulkFetchMetadata(context.Background(), []Repo{
+		{Owner: "old", Name: "name"},
+		{Owner: "me", Name: "fork"},
+		{Owner: "m", Name: "mirror"},
+	})
+	if err != nil {
+		t.Fatalf("BulkFetchMetadata: %v", err)
+	}
+
+	renamed := out.Metrics["old/name"]
+	if renamed == nil {
+		t.Fatalf("renamed repo not keyed by requested name: %v", out.Metrics)
+	}
+	if renamed.FullName != "new/name" || renamed.Owner != "new" || !renamed.RenamedFrom("old", "name") {
+		t.Errorf("renamed = %+v, want FullName new/name", renamed)
+	}
+	if f := out.Metrics["me/fork"]; f == nil || !f.Fork || f.Upstream() != "up/stream" {
+		t.Errorf("fork = %+v, want Fork with upstream up/stream", f)
+	}
+	if m := out.Metrics["m/mirror"]; m == nil || !m.Mirror || m.MirrorURL == "" {
+		t.Errorf("mirror = %+v, want Mirror with MirrorURL", m)
+	}
+}
@@ -0,0 +1,553 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// httpcache.go is an on-disk HTTP cache for the REST GETs the client
+// makes. Every cacheable 200 is stored with its ETag / Last-Modified;
+// the next request for the same URL is sent conditionally and a 304 is
+// answered from the stored body, so callers always see a 200. GitHub
+// does not charge 304s against the rate limit. Within an endpoint's TTL
+// the stored response is served without any request at all.
+//
+// Requests that carry their own If-None-Match / If-Modified-Since (the
+// scanner's repo and README fetches) are passed through untouched: those
+// callers want to see the 304 themselves.
+
+// Default HTTP cache limits, used when HTTPCacheConfig leaves them zero.
+const (
+	DefaultHTTPCacheMaxBytes      int64 = 256 << 20
+	DefaultHTTPCacheMaxEntryBytes int64 = 1 << 20
+)
+
+// HTTP cache endpoint labels, used for per-endpoint TTLs and as the
+// "endpoint" metric attribute.
+const (
+	CacheEndpointRepo         = "repo"
+	CacheEndpointPulls        = "pulls"
+	CacheEndpointIssues       = "issues"
+	CacheEndpointContributors = "contributors"
+	CacheEndpointReleases     = "releases"
+	CacheEndpointReadme       = "readme"
+	CacheEndpointSearch       = "search"
+	CacheEndpointOther        = "other"
+)
+
+// CacheEndpoints lists every endpoint label, for config validation.
+var CacheEndpoints = []string{
+	CacheEndpointRepo, CacheEndpointPulls, CacheEndpointIssues,
+	CacheEndpointContributors, CacheEndpointReleases, CacheEndpointReadme,
+	CacheEndpointSearch, CacheEndpointOther,
+}
+
+// HTTP cache results reported to an HTTPCacheObserver.
+const (
+	CacheResultHit         = "hit"         // served from disk, no request
+	CacheResultRevalidated = "revalidated" // 304 answered from disk
+	CacheResultMiss        = "miss"        // full response fetched
+)
+
+// cacheHeader marks responses answered from the cache with the result.
+const cacheHeader = "X-Radar-Cache"
+
+// HTTPCacheObserver is optionally implemented by an APIObserver that
+// wants HTTP cache activity.
+type HTTPCacheObserver interface {
+	// ObserveHTTPCache is called once per cacheable request with its
+	// endpoint label and one of the CacheResult* values.
+	ObserveHTTPCache(endpoint, result string)
+
+	// ObserveHTTPCacheSize is called whenever the cache contents change.
+	ObserveHTTPCacheSize(entries int, bytes int64)
+}
+
+// HTTPCacheConfig configures an HTTPCache.
+type HTTPCacheConfig struct {
+	// Dir is the cache directory. Required.
+	Dir string
+
+	// MaxBytes caps the stored bodies. Least recently used entries are
+	// evicted past it. Zero means DefaultHTTPCacheMaxBytes.
+	MaxBytes int64
+
+	// MaxEntryBytes is the largest body stored. Larger responses are
+	// passed through uncached. Zero means DefaultHTTPCacheMaxEntryBytes.
+	MaxEntryBytes int64
+
+	// TTLs maps an endpoint label (CacheEndpoints) to how long a stored
+	// response is served without revalidation. Endpoints not listed are
+	// revalidated on every request.
+	TTLs map[string]time.Duration
+}
+
+// HTTPCacheStats is a point-in-time view of cache activity.
+type HTTPCacheStats struct {
+	Entries     int
+	Bytes       int64
+	Hits        int64
+	Revalidated int64
+	Misses      int64
+	Evictions   int64
+}
+
+// httpCacheEntry is the metadata stored next to each body.
+type httpCacheEntry struct {
+	Key      string      `json:"key"`
+	Header   http.Header `json:"header"`
+	Size     int64       `json:"size"`
+	StoredAt time.Time   `json:"stored_at"` // last 200 or 304 from origin
+
+	lastAccess time.Time
+}
+
+// HTTPCache is an on-disk response cache shared by any number of
+// clients. Safe for concurrent use.
+//
+// Layout under Dir:
+//
+//	entries/<sha256>.json  metadata: key, response headers, stored_at
+//	entries/<sha256>.body  response body
+type HTTPCache struct {
+	cfg   HTTPCacheConfig
+	nowFn func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*httpCacheEntry
+	size     int64
+	stats    HTTPCacheStats
+	observer HTTPCacheObserver
+}
+
+// NewHTTPCache opens (or creates) the cache in cfg.Dir. Entries whose
+// body is missing or truncated are dropped.
+func NewHTTPCache(cfg HTTPCacheConfig) (*HTTPCache, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("http cache dir is required")
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultHTTPCacheMaxBytes
+	}
+	if cfg.MaxEntryBytes <= 0 {
+		cfg.MaxEntryBytes = DefaultHTTPCacheMaxEntryBytes
+	}
+	c := &HTTPCache{
+		cfg:     cfg,
+		nowFn:   time.Now,
+		entries: make(map[string]*httpCacheEntry),
+	}
+	if err := os.MkdirAll(c.entriesDir(), 0o755); err != nil {
+		return nil, fmt.Errorf("creating http cache dir: %w", err)
+	}
+
+	files, err := os.ReadDir(c.entriesDir())
+	if err != nil {
+		return nil, fmt.Errorf("reading http cache dir: %w", err)
+	}
+	for _, f := range files {
+		name := f.Name()
+		hash, ok := strings.CutSuffix(name, ".json")
+		if !ok {
+			if !strings.HasSuffix(name, ".body") {
+				// Leftover temp file from an interrupted write.
+				_ = os.Remove(filepath.Join(c.entriesDir(), name))
+			}
+			continue
+		}
+		e, ok := c.loadEntry(hash)
+		if !ok {
+			_ = os.Remove(c.metaPath(hash))
+			_ = os.Remove(c.bodyPath(hash))
+			continue
+		}
+		c.entries[e.Key] = e
+		c.size += e.Size
+	}
+	c.removeOrphanBodies(files)
+
+	c.mu.Lock()
+	c.evictLocked("")
+	c.mu.Unlock()
+	return c, nil
+}
+
+// loadEntry reads one metadata file and checks its body.
+func (c *HTTPCache) loadEntry(hash string) (*httpCacheEntry, bool) {
+	raw, err := os.ReadFile(c.metaPath(hash))
+	if err != nil {
+		return nil, false
+	}
+	var e httpCacheEntry
+	if err := json.Unmarshal(raw, &e); err != nil || e.Key == "" || cacheHash(e.Key) != hash {
+		return nil, false
+	}
+	info, err := os.Stat(c.bodyPath(hash))
+	if err != nil || info.Size() != e.Size {
+		return nil, false
+	}
+	e.lastAccess = info.ModTime()
+	return &e, true
+}
+
+// removeOrphanBodies deletes bodies without a loaded entry.
+func (c *HTTPCache) removeOrphanBodies(files []os.DirEntry) {
+	live := make(map[string]bool, len(c.entries))
+	for key := range c.entries {
+		live[cacheHash(key)] = true
+	}
+	for _, f := range files {
+		if hash, ok := strings.CutSuffix(f.Name(), ".body"); ok && !live[hash] {
+			_ = os.Remove(c.bodyPath(hash))
+		}
+	}
+}
+
+// SetObserver installs the hook that receives cache activity.
+func (c *HTTPCache) SetObserver(obs HTTPCacheObserver) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.observer = obs
+	c.mu.Unlock()
+	if obs != nil {
+		s := c.Stats()
+		obs.ObserveHTTPCacheSize(s.Entries, s.Bytes)
+	}
+}
+
+// Stats returns the current cache statistics.
+func (c *HTTPCache) Stats() HTTPCacheStats {
+	if c == nil {
+		return HTTPCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = len(c.entries)
+	s.Bytes = c.size
+	return s
+}
+
+// cacheKey returns the cache key for req and whether req is cacheable:
+// a GET without caller-supplied validators or a Range. Authorization is
+// not part of the key: responses are shared across a token pool, and
+// Client.cacheable prefixes the pool's credential scope instead.
+func cacheKey(req *http.Request) (string, bool) {
+	if req.Method != http.MethodGet {
+		return "", false
+	}
+	h := req.Header
+	if h.Get("If-None-Match") != "" || h.Get("If-Modified-Since") != "" || h.Get("Range") != "" {
+		return "", false
+	}
+	return req.URL.String() + " " + h.Get("Accept"), true
+}
+
+// cacheEndpoint labels a request path, e.g. /repos/o/r/pulls → "pulls".
+// Enterprise prefixes such as /api/v3 are ignored.
+func cacheEndpoint(path string) string {
+	if strings.Contains(path, "/search/") {
+		return CacheEndpointSearch
+	}
+	_, rest, ok := strings.Cut(path, "/repos/")
+	if !ok {
+		return CacheEndpointOther
+	}
+	parts := strings.SplitN(rest, "/", 4)
+	if len(parts) < 3 {
+		return CacheEndpointRepo
+	}
+	switch parts[2] {
+	case CacheEndpointPulls, CacheEndpointIssues, CacheEndpointContributors, CacheEndpointReleases, CacheEndpointReadme:
+		return parts[2]
+	}
+	return CacheEndpointOther
+}
+
+// fresh returns the stored response for key if it is within the
+// endpoint's TTL.
+func (c *HTTPCache) fresh(key, endpoint string, req *http.Request) (*http.Response, bool) {
+	ttl := c.cfg.TTLs[endpoint]
+	if ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok || c.nowFn().Sub(e.StoredAt) >= ttl {
+		c.mu.Unlock()
+		return nil, false
+	}
+	header := e.Header.Clone()
+	c.mu.Unlock()
+
+	body, ok := c.readBody(key)
+	if !ok {
+		return nil, false
+	}
+	c.record(endpoint, CacheResultHit)
+	return cachedResponse(req, header, body, CacheResultHit), true
+}
+
+// validators returns the conditional headers to revalidate key with.
+func (c *HTTPCache) validators(key string) (etag, lastModified string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, found := c.entries[key]
+	if !found {
+		return "", "", false
+	}
+	etag, lastModified = e.Header.Get("ETag"), e.Header.Get("Last-Modified")
+	return etag, lastModified, etag != "" || lastModified != ""
+}
+
+// revalidated answers a 304 for key from the stored body. The 304's
+// headers (fresh rate-limit values, a new ETag) override the stored
+// ones. ok is false if the entry vanished meanwhile.
+func (c *HTTPCache) revalidated(key, endpoint string, req *http.Request, notModified *http.Response) (*http.Response, bool) {
+	body, ok := c.readBody(key)
+	if !ok {
+		return nil, false
+	}
+	c.mu.Lock()
+	e, found := c.entries[key]
+	if !found {
+		c.mu.Unlock()
+		return nil, false
+	}
+	for name, values := range notModified.Header {
+		if storedHeader(name) && name != "Content-Length" {
+			e.Header[name] = values
+		}
+	}
+	e.StoredAt = c.nowFn()
+	header := e.Header.Clone()
+	meta, _ := json.Marshal(e)
+	c.mu.Unlock()
+
+	_ = writeFileAtomic(c.metaPath(cacheHash(key)), meta)
+	c.record(endpoint, CacheResultRevalidated)
+	return cachedResponse(req, header, body, CacheResultRevalidated), true
+}
+
+// fetched handles a response that went to the origin: 200s with a
+// validator (or a TTL) are stored, and the body is handed on intact.
+func (c *HTTPCache) fetched(key, endpoint string, resp *http.Response) *http.Response {
+	c.record(endpoint, CacheResultMiss)
+	if resp.StatusCode != http.StatusOK {
+		return resp
+	}
+	storable := resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" || c.cfg.TTLs[endpoint] > 0
+	if !storable || resp.ContentLength > c.cfg.MaxEntryBytes {
+		return resp
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(resp.Body, c.cfg.MaxEntryBytes+1))
+	if err != nil || int64(len(buf)) > c.cfg.MaxEntryBytes {
+		// Too large (or failed mid-read): replay what was read and let
+		// the caller consume the rest from the network.
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(buf), resp.Body), resp.Body}
+		return resp
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(buf))
+
+	c.store(key, resp.Header, buf)
+	return resp
+}
+
+// store writes an entry, then indexes it and evicts down to MaxBytes.
+func (c *HTTPCache) store(key string, header http.Header, body []byte) {
+	now := c.nowFn()
+	stored := make(http.Header, len(header))
+	for name, values := range header {
+		if storedHeader(name) {
+			stored[name] = values
+		}
+	}
+	e := &httpCacheEntry{Key: key, Header: stored, Size: int64(len(body)), StoredAt: now, lastAccess: now}
+	meta, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	hash := cacheHash(key)
+
+	// Write outside c.mu so disk latency does not stall other requests'
+	// lookups. A concurrent store of the same key may interleave files;
+	// readBody's size check drops such an entry.
+	if writeFileAtomic(c.bodyPath(hash), body) != nil || writeFileAtomic(c.metaPath(hash), meta) != nil {
+		c.mu.Lock()
+		c.dropLocked(key)
+		c.mu.Unlock()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.entries[key]; ok {
+		c.size -= old.Size
+	}
+	c.entries[key] = e
+	c.size += e.Size
+	c.evictLocked(key)
+	c.notifySizeLocked()
+}
+
+// readBody reads key's body and marks the entry used.
+func (c *HTTPCache) readBody(key string) ([]byte, bool) {
+	body, err := os.ReadFile(c.bodyPath(cacheHash(key)))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if err != nil || int64(len(body)) != e.Size {
+		c.dropLocked(key)
+		c.notifySizeLocked()
+		return nil, false
+	}
+	e.lastAccess = c.nowFn()
+	return body, true
+}
+
+// record counts a cache result and reports it.
+func (c *HTTPCache) record(endpoint, result string) {
+	c.mu.Lock()
+	switch result {
+	case CacheResultHit:
+		c.stats.Hits++
+	case CacheResultRevalidated:
+		c.stats.Revalidated++
+	case CacheResultMiss:
+		c.stats.Misses++
+	}
+	obs := c.observer
+	c.mu.Unlock()
+	if obs != nil {
+		obs.ObserveHTTPCache(endpoint, result)
+	}
+}
+
+// dropLocked removes key and its files. Caller holds c.mu.
+func (c *HTTPCache) dropLocked(key string) {
+	hash := cacheHash(key)
+	_ = os.Remove(c.metaPath(hash))
+	_ = os.Remove(c.bodyPath(hash))
+	if e, ok := c.entries[key]; ok {
+		c.size -= e.Size
+		delete(c.entries, key)
+	}
+}
+
+// evictLocked drops least recently used entries until the cache fits
+// MaxBytes. keep is never evicted. Caller holds c.mu.
+func (c *HTTPCache) evictLocked(keep string) {
+	if c.size <= c.cfg.MaxBytes {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		if key != keep {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ai, aj := c.entries[keys[i]].lastAccess, c.entries[keys[j]].lastAccess
+		if !ai.Equal(aj) {
+			return ai.Before(aj)
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		if c.size <= c.cfg.MaxBytes {
+			return
+		}
+		c.dropLocked(key)
+		c.stats.Evictions++
+	}
+}
+
+// notifySizeLocked reports the cache size. Caller holds c.mu.
+func (c *HTTPCache) notifySizeLocked() {
+	if c.observer != nil {
+		c.observer.ObserveHTTPCacheSize(len(c.entries), c.size)
+	}
+}
+
+func (c *HTTPCache) entriesDir() string { return filepath.Join(c.cfg.Dir, "entries") }
+
+func (c *HTTPCache) metaPath(hash string) string {
+	return filepath.Join(c.entriesDir(), hash+".json")
+}
+
+func (c *HTTPCache) bodyPath(hash string) string {
+	return filepath.Join(c.entriesDir(), hash+".body")
+}
+
+// storedHeader reports whether a response header is kept with an
+// entry. Rate-limit headers describe the moment of the response and
+// would be stale on replay.
+func storedHeader(name string) bool {
+	return !strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Ratelimit-")
+}
+
+// cacheHash names an entry's files.
+func cacheHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedResponse builds the 200 a cached entry is replayed as.
+func cachedResponse(req *http.Request, header http.Header, body []byte, result string) *http.Response {
+	header.Set(cacheHeader, result)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// writeFileAtomic writes data to path via a temp file and rename, so a
+// crash never leaves a half-written entry behind.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// readCloser pairs a reader with the closer of the body it wraps.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,267 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// etagServer serves body under a fixed ETag and answers matching
+// If-None-Match requests with a 304, counting both.
+type etagServer struct {
+	full, notModified atomic.Int32
+}
+
+func (s *etagServer) handler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			s.notModified.Add(1)
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.full.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "4990")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Link", `<https://api.github.com/x?page=2>; rel="last"`)
+		w.Write([]byte(body))
+	}
+}
+
+func newCachedClient(t *testing.T, url string, cfg HTTPCacheConfig) (*Client, *HTTPCache) {
+	t.Helper()
+	if cfg.Dir == "" {
+		cfg.Dir = t.TempDir()
+	}
+	cache, err := NewHTTPCache(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPCache: %v", err)
+	}
+	client, _ := NewClient("test-token")
+	client.SetBaseURL(url)
+	client.SetHTTPCache(cache)
+	return client, cache
+}
+
+func TestHTTPCache_Revalidates304AsCachedBody(t *testing.T) {
+	srv := &etagServer{}
+	server := httptest.NewServer(srv.handler(`[{"number": 7}]`))
+	defer server.Close()
+
+	client, cache := newCachedClient(t, server.URL, HTTPCacheConfig{})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ctx, "/repos/o/r/pulls?state=closed")
+		if err != nil {
+			t.Fatalf("Get #%d: %v", i, err)
+		}
+		var pulls []struct{ Number int }
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Get #%d status = %d, want 200", i, resp.StatusCode)
+		}
+		if err := decodeJSON(resp.Body, &pulls); err != nil || len(pulls) != 1 || pulls[0].Number != 7 {
+			t.Fatalf("Get #%d body = %+v, %v", i, pulls, err)
+		}
+		if !strings.Contains(resp.Header.Get("Link"), `rel="last"`) {
+			t.Errorf("Get #%d lost the Link header", i)
+		}
+		resp.Body.Close()
+	}
+
+	if full, nm := srv.full.Load(), srv.notModified.Load(); full != 1 || nm != 2 {
+		t.Errorf("server saw %d full and %d conditional responses, want 1 and 2", full, nm)
+	}
+	if s := cache.Stats(); s.Misses != 1 || s.Revalidated != 2 || s.Entries != 1 {
+		t.Errorf("stats = %+v, want 1 miss, 2 revalidated, 1 entry", s)
+	}
+	// The 304's budget, not the stored one, is what the client tracks.
+	if rl := client.RateLimitInfo(); rl.Remaining != 4999 {
+		t.Errorf("Remaining = %d, want 4999 from the last 304", rl.Remaining)
+	}
+}
+
+func TestHTTPCache_TTLServesWithoutRequest(t *testing.T) {
+	srv := &etagServer{}
+	server := httptest.NewServer(srv.handler(`{"tag_name": "v1.0.0"}`))
+	defer server.Close()
+
+	client, cache := newCachedClient(t, server.URL, HTTPCacheConfig{
+		TTLs: map[string]time.Duration{CacheEndpointReleases: time.Hour},
+	})
+	now := time.Now()
+	cache.nowFn = func() time.Time { return now }
+
+	var rel struct {
+		TagName string `json:"tag_name"`
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.GetJSON(context.Background(), "/repos/o/r/releases/latest", &rel); err != nil {
+			t.Fatalf("GetJSON #%d: %v", i, err)
+		}
+	}
+	if srv.full.Load()+srv.notModified.Load() != 1 {
+		t.Errorf("second read inside the TTL reached the server")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := client.GetJSON(context.Background(), "/repos/o/r/releases/latest", &rel); err != nil {
+		t.Fatalf("GetJSON after TTL: %v", err)
+	}
+	if rel.TagName != "v1.0.0" || srv.notModified.Load() != 1 {
+		t.Errorf("expired entry should be revalidated: tag %q, 304s %d", rel.TagName, srv.notModified.Load())
+	}
+	if s := cache.Stats(); s.Hits != 1 || s.Revalidated != 1 || s.Misses != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestHTTPCache_ScopedByCredentials(t *testing.T) {
+	srv := &etagServer{}
+	server := httptest.NewServer(srv.handler(`{"tag_name": "v1.0.0"}`))
+	defer server.Close()
+
+	cache, err := NewHTTPCache(HTTPCacheConfig{
+		Dir:  t.TempDir(),
+		TTLs: map[string]time.Duration{CacheEndpointReleases: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPCache: %v", err)
+	}
+	get := func(token string) {
+		t.Helper()
+		client, _ := NewClient(token)
+		client.SetBaseURL(server.URL)
+		client.SetHTTPCache(cache)
+		var rel struct {
+			TagName string `json:"tag_name"`
+		}
+		if err := client.GetJSON(context.Background(), "/repos/o/r/releases/latest", &rel); err != nil {
+			t.Fatalf("GetJSON with %s: %v", token, err)
+		}
+	}
+
+	get("token-a")
+	get("token-b")
+	if n := srv.full.Load(); n != 2 {
+		t.Errorf("server saw %d full responses, want 2: token-b must not reuse token-a's entry", n)
+	}
+	get("token-a")
+	if n := srv.full.Load() + srv.notModified.Load(); n != 2 {
+		t.Errorf("token-a's second read reached the server (%d requests)", n)
+	}
+	if s := cache.Stats(); s.Entries != 2 {
+		t.Errorf("entries = %d, want one per credential scope", s.Entries)
+	}
+}
+
+func TestHTTPCache_CallerConditionalPassesThrough(t *testing.T) {
+	srv := &etagServer{}
+	server := httptest.NewServer(srv.handler(`{}`))
+	defer server.Close()
+
+	client, _ := newCachedClient(t, server.URL, HTTPCacheConfig{})
+	ctx := context.Background()
+
+	// Prime the cache, then make the request the scanner makes.
+	resp, err := client.Get(ctx, "/repos/o/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	req, _ := client.newRequest(ctx, http.MethodGet, "/repos/o/r")
+	req.Header.Set("If-None-Match", `"v1"`)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("caller-conditional request got %d, want the raw 304", resp.StatusCode)
+	}
+
+	// Revalidation must not leak validators into the caller's request,
+	// or a retry of it would be mistaken for a caller-conditional one.
+	req, _ = client.newRequest(ctx, http.MethodGet, "/repos/o/r")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if req.Header.Get("If-None-Match") != "" {
+		t.Error("Do added If-None-Match to the caller's request")
+	}
+}
+
+func TestHTTPCache_PersistsAndEvicts(t *testing.T) {
+	srv := &etagServer{}
+	server := httptest.NewServer(srv.handler(strings.Repeat("x", 600)))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cfg := HTTPCacheConfig{Dir: dir, MaxBytes: 1000, MaxEntryBytes: 800}
+	client, cache := newCachedClient(t, server.URL, cfg)
+	ctx := context.Background()
+	for _, path := range []string{"/repos/o/a/issues", "/repos/o/b/issues"} {
+		resp, err := client.Get(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if s := cache.Stats(); s.Entries != 1 || s.Evictions != 1 || s.Bytes != 600 {
+		t.Fatalf("stats = %+v, want the older entry evicted", s)
+	}
+
+	reopened, err := NewHTTPCache(cfg)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if s := reopened.Stats(); s.Entries != 1 || s.Bytes != 600 {
+		t.Errorf("reopened stats = %+v, want the surviving entry", s)
+	}
+
+	// Bodies over MaxEntryBytes are passed through whole but not stored.
+	big := httptest.NewServer(srv.handler(strings.Repeat("y", 900)))
+	defer big.Close()
+	client.SetBaseURL(big.URL)
+	resp, err := client.Get(ctx, "/repos/o/c/issues")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || len(body) != 900 {
+		t.Errorf("oversized body read %d bytes (%v), want 900", len(body), err)
+	}
+	resp.Body.Close()
+	if s := cache.Stats(); s.Entries != 1 {
+		t.Errorf("oversized body was stored: %+v", s)
+	}
+}
+
+func TestCacheEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/repos/o/r":                 CacheEndpointRepo,
+		"/repos/o/r/pulls":           CacheEndpointPulls,
+		"/api/v3/repos/o/r/issues":   CacheEndpointIssues,
+		"/repos/o/r/contributors":    CacheEndpointContributors,
+		"/repos/o/r/releases/latest": CacheEndpointReleases,
+		"/repos/o/r/readme":          CacheEndpointReadme,
+		"/search/repositories":       CacheEndpointSearch,
+		"/repos/o/r/commits":         CacheEndpointOther,
+		"/rate_limit":                CacheEndpointOther,
+	}
+	for path, want := range tests {
+		if got := cacheEndpoint(path); got != want {
+			t.Errorf("cacheEndpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
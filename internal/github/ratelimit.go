@@ -41,7 +41,9 @@ func (c *Client) SetRateLimitOptions(opts RateLimitOptions) {
 	c.rateLimitOpts = opts
 }
 
-// ShouldBackoff returns true if the client should slow down due to rate limits.
+// ShouldBackoff returns true if the client should slow down due to rate
+// limits: when the REST budget left across the whole token pool is
+// below the threshold.
 func (c *Client) ShouldBackoff() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -152,6 +154,7 @@ func (c *Client) WaitForRateLimit(ctx context.Context) error {
 			c.mu.Lock()
 			c.rateLimit.Remaining = 0
 			c.rateLimit.Limit = 0
+			c.clearTokenBudgetsLocked(ResourceCore)
 			c.mu.Unlock()
 			return nil
 		}
@@ -203,6 +206,21 @@ func (c *Client) checkRateLimit(ctx context.Context) error {
 	return nil
 }
 
+// checkResourceRateLimit is checkRateLimit for a request charged to
+// resource. GraphQL and search have budgets of their own, so they are
+// only refused when every token has exhausted that budget; they never
+// wait, the caller's retry policy decides.
+func (c *Client) checkResourceRateLimit(ctx context.Context, resource string) error {
+	if resource == ResourceCore {
+		return c.checkRateLimit(ctx)
+	}
+	rl := c.ResourceRateLimitInfo(resource)
+	if rl.Limit > 0 && rl.Remaining == 0 && rl.Reset.After(time.Now()) {
+		return &RateLimitError{Reset: rl.Reset}
+	}
+	return nil
+}
+
 // RateLimitError is returned when rate limit is exhausted.
 type RateLimitError struct {
 	Reset time.Time
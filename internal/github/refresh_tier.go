@@ -71,6 +71,9 @@ type TierCandidate struct {
 	GrowthScore     float64
 	FirstSeenAt     time.Time
 	LastCollectedAt time.Time
+	// PromotedUntil keeps a warm or cold repo in TierHot until then,
+	// e.g. after a webhook reported activity.
+	PromotedUntil time.Time
 }
 
 // TierAssignment is the output of ClassifyTier for one repo.
@@ -100,6 +103,9 @@ func ClassifyAll(candidates []TierCandidate, now time.Time, cfg TierConfig) []Ti
 	for i, c := range ranked {
 		tier := tierForRank(i, cfg)
 
+		if tier > TierHot && now.Before(c.PromotedUntil) {
+			tier = TierHot
+		}
 		// New-repo promotion overrides the rank-based tier.
 		if !c.FirstSeenAt.IsZero() && now.Sub(c.FirstSeenAt) < cfg.NewRepoWindow {
 			tier = TierNew
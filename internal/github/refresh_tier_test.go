@@ -57,6 +57,27 @@ func TestClassifyAll_NewRepoOverridesRank(t *testing.T) {
 	}
 }
 
+func TestClassifyAll_PromotedRepoIsHot(t *testing.T) {
+	cfg := DefaultTierConfig()
+	cfg.HotN, cfg.WarmN = 1, 0
+	now := time.Now()
+
+	candidates := []TierCandidate{
+		{FullName: "top", GrowthScore: 10, LastCollectedAt: now.Add(-2 * time.Hour)},
+		{FullName: "promoted", GrowthScore: 1, LastCollectedAt: now.Add(-2 * time.Hour), PromotedUntil: now.Add(time.Hour)},
+		{FullName: "expired", GrowthScore: 1, LastCollectedAt: now.Add(-2 * time.Hour), PromotedUntil: now.Add(-time.Minute)},
+	}
+	want := map[string]RefreshTier{"top": TierHot, "promoted": TierHot, "expired": TierCold}
+	for _, a := range ClassifyAll(candidates, now, cfg) {
+		if a.Tier != want[a.FullName] {
+			t.Errorf("%s: got tier %s, want %s", a.FullName, a.Tier, want[a.FullName])
+		}
+		if a.FullName == "promoted" && !a.IsDue {
+			t.Error("promoted repo collected 2h ago is not due at the hot interval")
+		}
+	}
+}
+
 func TestClassifyAll_NewRepoWindowBoundary(t *testing.T) {
 	cfg := DefaultTierConfig()
 	now := time.Now()
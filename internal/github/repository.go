@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // RepoMetrics contains core metrics collected from a GitHub repository.
@@ -19,6 +20,23 @@ type RepoMetrics struct {
 	Topics      []string `json:"topics"`
 	Description string   `json:"description"`
 
+	// Fork, Parent and Source describe the repo's place in a fork
+	// network: Parent is the repo it was forked from, Source the root
+	// of the network. Both are empty for non-forks. The GraphQL path
+	// only resolves Parent; Source is REST-only.
+	Fork   bool   `json:"fork"`
+	Parent string `json:"parent,omitempty"`
+	Source string `json:"source,omitempty"`
+
+	// Mirror is true for repos GitHub mirrors from an external URL
+	// (MirrorURL). Mirrors carry no GitHub-side upstream name.
+	Mirror    bool   `json:"mirror"`
+	MirrorURL string `json:"mirror_url,omitempty"`
+
+	// HTMLURL is the repo's web page as the REST API reports it, so it
+	// points at the Enterprise host for GHES repos. Empty from GraphQL.
+	HTMLURL string `json:"html_url,omitempty"`
+
 	// Activity carries the 7-day PR/issue counts, contributor proxy, and
 	// latest release info when populated by the GraphQL bulk-fetch path
 	// (T5b — ISI-765). Nil means activity was not requested or the
@@ -49,6 +67,63 @@ type githubRepoResponse struct {
 	Language        string   `json:"language"`
 	Topics          []string `json:"topics"`
 	Description     string   `json:"description"`
+	Fork            bool     `json:"fork"`
+	MirrorURL       string   `json:"mirror_url"`
+	HTMLURL         string   `json:"html_url"`
+	Parent          *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	Source *struct {
+		FullName string `json:"full_name"`
+	} `json:"source"`
+}
+
+// toRepoMetrics converts the REST payload into RepoMetrics. Shared by
+// GetRepository and GetRepositoryConditional.
+func (r *githubRepoResponse) toRepoMetrics() *RepoMetrics {
+	m := &RepoMetrics{
+		Owner:       r.Owner.Login,
+		Name:        r.Name,
+		FullName:    r.FullName,
+		Stars:       r.StargazersCount,
+		Forks:       r.ForksCount,
+		OpenIssues:  r.OpenIssuesCount,
+		Language:    r.Language,
+		Topics:      r.Topics,
+		Description: r.Description,
+		Fork:        r.Fork,
+		Mirror:      r.MirrorURL != "",
+		MirrorURL:   r.MirrorURL,
+		HTMLURL:     r.HTMLURL,
+	}
+	if r.Parent != nil {
+		m.Parent = r.Parent.FullName
+	}
+	if r.Source != nil {
+		m.Source = r.Source.FullName
+	}
+	return m
+}
+
+// Upstream returns the name a fork canonicalizes to: the root of its
+// fork network when known, else its direct parent. Empty for non-forks.
+func (m *RepoMetrics) Upstream() string {
+	if !m.Fork {
+		return ""
+	}
+	if m.Source != "" {
+		return m.Source
+	}
+	return m.Parent
+}
+
+// RenamedFrom reports whether the repo requested as owner/name now
+// lives under a different name. GitHub answers requests for a renamed
+// or transferred repo with a 301 to the new location; the HTTP client
+// follows it and the payload carries the new full_name. Case-only
+// differences are not renames — GitHub names are case-insensitive.
+func (m *RepoMetrics) RenamedFrom(owner, name string) bool {
+	return m.FullName != "" && !strings.EqualFold(m.FullName, owner+"/"+name)
 }
 
 // GetRepository fetches core metrics for a repository.
@@ -60,17 +135,7 @@ func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*RepoMe
 		return nil, fmt.Errorf("fetching repository %s/%s: %w", owner, repo, err)
 	}
 
-	return &RepoMetrics{
-		Owner:       resp.Owner.Login,
-		Name:        resp.Name,
-		FullName:    resp.FullName,
-		Stars:       resp.StargazersCount,
-		Forks:       resp.ForksCount,
-		OpenIssues:  resp.OpenIssuesCount,
-		Language:    resp.Language,
-		Topics:      resp.Topics,
-		Description: resp.Description,
-	}, nil
+	return resp.toRepoMetrics(), nil
 }
 
 // GetOpenPRCount returns the count of open pull requests for a repository.
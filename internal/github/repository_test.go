@@ -235,3 +235,90 @@ func TestClient_GetRepository_RateLimitTracking(t *testing.T) {
 		t.Errorf("RateLimit.Remaining = %d, want 4500", rl.Remaining)
 	}
 }
+
+func TestClient_GetRepository_ForkAndMirror(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		switch r.URL.Path {
+		case "/repos/someone/kubernetes":
+			w.Write([]byte(`{
+				"owner": {"login": "someone"},
+				"name": "kubernetes",
+				"full_name": "someone/kubernetes",
+				"fork": true,
+				"parent": {"full_name": "middle/kubernetes"},
+				"source": {"full_name": "kubernetes/kubernetes"}
+			}`))
+		case "/repos/mirrors/linux":
+			w.Write([]byte(`{
+				"owner": {"login": "mirrors"},
+				"name": "linux",
+				"full_name": "mirrors/linux",
+				"mirror_url": "https://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git"
+			}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-token")
+	client.SetBaseURL(server.URL)
+
+	fork, err := client.GetRepository(context.Background(), "someone", "kubernetes")
+	if err != nil {
+		t.Fatalf("GetRepository(fork): %v", err)
+	}
+	if !fork.Fork || fork.Parent != "middle/kubernetes" || fork.Source != "kubernetes/kubernetes" {
+		t.Errorf("fork identity = (%v, %q, %q)", fork.Fork, fork.Parent, fork.Source)
+	}
+	if got := fork.Upstream(); got != "kubernetes/kubernetes" {
+		t.Errorf("Upstream() = %q, want kubernetes/kubernetes (source wins over parent)", got)
+	}
+
+	mirror, err := client.GetRepository(context.Background(), "mirrors", "linux")
+	if err != nil {
+		t.Fatalf("GetRepository(mirror): %v", err)
+	}
+	if !mirror.Mirror || mirror.Fork || mirror.Upstream() != "" {
+		t.Errorf("mirror identity = (mirror=%v, fork=%v, upstream=%q)", mirror.Mirror, mirror.Fork, mirror.Upstream())
+	}
+}
+
+// TestClient_GetRepository_FollowsRenameRedirect — GitHub answers a
+// renamed repo's old name with a 301; the client follows it and the
+// payload's full_name exposes the rename.
+func TestClient_GetRepository_FollowsRenameRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/old/name":
+			http.Redirect(w, r, "/repositories/42", http.StatusMovedPermanently)
+		case "/repositories/42":
+			if r.Header.Get("Authorization") == "" {
+				t.Error("Authorization header dropped on same-host redirect")
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"owner": {"login": "new"}, "name": "name", "full_name": "new/name", "stargazers_count": 7}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-token")
+	client.SetBaseURL(server.URL)
+
+	metrics, err := client.GetRepository(context.Background(), "old", "name")
+	if err != nil {
+		t.Fatalf("GetRepository: %v", err)
+	}
+	if metrics.FullName != "new/name" || metrics.Stars != 7 {
+		t.Errorf("metrics = %+v, want new/name with 7 stars", metrics)
+	}
+	if !metrics.RenamedFrom("old", "name") {
+		t.Error("RenamedFrom(old, name) = false, want true")
+	}
+	if metrics.RenamedFrom("NEW", "Name") {
+		t.Error("RenamedFrom is case-sensitive, want case-insensitive")
+	}
+}
@@ -124,10 +124,14 @@ func (c *Client) DoWithRetry(req *http.Request) (*http.Response, error) {
 		}
 
 		// Check if response indicates a retryable error
-		if isRetryableStatusCode(resp.StatusCode) || isRateLimitedExhaustion(resp) {
-			// GitHub's secondary rate limit returns 429 + Retry-After.
-			// The primary rate limit returns 403 + X-RateLimit-Remaining: 0,
-			// often with Retry-After as well. Honour the header in both cases.
+		_, secondary := secondaryRateLimit(resp)
+		if isRetryableStatusCode(resp.StatusCode) || isRateLimitedExhaustion(resp) || secondary {
+			// GitHub's secondary rate limit returns 429 + Retry-After,
+			// or a 403 saying so. The primary rate limit returns 403 +
+			// X-RateLimit-Remaining: 0, often with Retry-After as well.
+			// Honour the header in all cases. A governor, when set,
+			// also holds the retry (and every other request) until its
+			// cooldown is over.
 			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
 				if d > config.MaxDelay {
 					d = config.MaxDelay
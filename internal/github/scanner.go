@@ -3,8 +3,10 @@ package github
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/hrexed/github-radar/internal/forge"
 	"github.com/hrexed/github-radar/internal/scoring"
 	"github.com/hrexed/github-radar/internal/state"
 )
@@ -63,10 +65,17 @@ type ScanResult struct {
 	RepoGone    int
 	FailedRepos []string
 	GoneRepos   []string
+	// Renamed maps each requested name GitHub answered under a new name
+	// (a 301 on REST, a resolved nameWithOwner on GraphQL) to that new
+	// name. State under the old name has already been merged into the
+	// new one; callers persist the alias.
+	Renamed map[string]string
 }
 
-// Repo represents a repository to scan.
+// Repo represents a repository to scan. Host is empty for github.com;
+// a Scanner only scans repos on its client's host.
 type Repo struct {
+	Host  string
 	Owner string
 	Name  string
 }
@@ -79,6 +88,10 @@ func (s *Scanner) Scan(ctx context.Context, repos []Repo) (*ScanResult, error) {
 
 	s.log("info", "Starting scan", "repo_count", len(repos))
 
+	// updated guards against refreshing one repo twice in a cycle when
+	// both its old and new names are in the list.
+	updated := make(map[string]bool, len(repos))
+
 	for _, repo := range repos {
 		select {
 		case <-ctx.Done():
@@ -90,8 +103,13 @@ func (s *Scanner) Scan(ctx context.Context, repos []Repo) (*ScanResult, error) {
 
 		result.Total++
 
+		if updated[s.client.RepoKey(repo.Owner, repo.Name)] {
+			result.Skipped++
+			continue
+		}
+
 		// Get previous state for conditional requests and velocity calculation
-		prevState := s.store.GetRepoState(fmt.Sprintf("%s/%s", repo.Owner, repo.Name))
+		prevState := s.store.GetRepoState(s.client.RepoKey(repo.Owner, repo.Name))
 		var cond *ConditionalInfo
 		if prevState != nil {
 			cond = &ConditionalInfo{
@@ -124,8 +142,18 @@ func (s *Scanner) Scan(ctx context.Context, repos []Repo) (*ScanResult, error) {
 
 		result.Successful++
 
+		target := repo
+		if collResult.Metrics != nil && collResult.Metrics.RenamedFrom(repo.Owner, repo.Name) {
+			var done bool
+			target, prevState, done = s.followRename(result, repo, collResult.Metrics.FullName, updated)
+			if done {
+				continue
+			}
+		}
+
 		// Update state with new data
-		s.updateRepoState(repo.Owner, repo.Name, &collResult, prevState)
+		s.updateRepoState(target.Owner, target.Name, &collResult, prevState)
+		updated[s.client.RepoKey(target.Owner, target.Name)] = true
 		result.Updated++
 	}
 
@@ -148,90 +176,60 @@ func (s *Scanner) Scan(ctx context.Context, repos []Repo) (*ScanResult, error) {
 	return result, nil
 }
 
-// updateRepoState updates the state store with collection results.
-func (s *Scanner) updateRepoState(owner, name string, result *CollectionResult, prev *state.RepoState) {
-	fullName := fmt.Sprintf("%s/%s", owner, name)
-
-	newState := state.RepoState{
-		Owner:         owner,
-		Name:          name,
-		LastCollected: result.Collected,
+// followRename re-keys a repo GitHub reported under a new name: state
+// recorded under the requested name is merged into canonical and the
+// mapping is added to result.Renamed. Returns the canonical repo and
+// its merged previous state. done is true when canonical was already
+// refreshed earlier in this scan, in which case the caller must not
+// apply a second update. canonical is the owner/name GitHub reported;
+// the rename is recorded under host-qualified keys.
+func (s *Scanner) followRename(result *ScanResult, requested Repo, canonical string, updated map[string]bool) (Repo, *state.RepoState, bool) {
+	oldName := s.client.RepoKey(requested.Owner, requested.Name)
+	owner, name, ok := strings.Cut(canonical, "/")
+	if !ok {
+		return requested, s.store.GetRepoState(oldName), false
 	}
+	newName := s.client.RepoKey(owner, name)
 
-	// Store conditional request info for future requests
-	if result.ConditionInfo != nil {
-		newState.ETag = result.ConditionInfo.ETag
-		newState.LastModified = result.ConditionInfo.LastModified
+	s.store.MergeRepo(oldName, newName)
+	if result.Renamed == nil {
+		result.Renamed = make(map[string]string)
 	}
+	result.Renamed[oldName] = newName
+	s.log("info", "Repo renamed, merging state under new name", "from", oldName, "to", newName)
 
-	if result.Metrics != nil {
-		newState.Stars = result.Metrics.Stars
-		newState.Forks = result.Metrics.Forks
-	}
+	return Repo{Host: requested.Host, Owner: owner, Name: name}, s.store.GetRepoState(newName), updated[newName]
+}
 
-	if result.Activity != nil {
-		newState.Contributors = result.Activity.Contributors
-		newState.MergedPRs7d = result.Activity.MergedPRs7d
-		newState.NewIssues7d = result.Activity.NewIssues7d
-	}
+// updateRepoState updates the state store with collection results.
+func (s *Scanner) updateRepoState(owner, name string, result *CollectionResult, prev *state.RepoState) {
+	fullName := s.client.RepoKey(owner, name)
 
-	// Carry forward release history, then append new release if we observe one.
-	if prev != nil {
-		newState.LatestReleaseAt = prev.LatestReleaseAt
-		if len(prev.RecentReleaseDates) > 0 {
-			newState.RecentReleaseDates = append(newState.RecentReleaseDates, prev.RecentReleaseDates...)
-		}
+	snap := forge.Snapshot{Collected: result.Collected}
+	if result.Metrics != nil {
+		snap.Stars = result.Metrics.Stars
+		snap.Forks = result.Metrics.Forks
 	}
-	if result.Activity != nil && result.Activity.LatestRelease != nil {
-		latest := result.Activity.LatestRelease.PublishedAt
-		if !latest.IsZero() && !latest.Equal(newState.LatestReleaseAt) {
-			// Prepend newest, keep max 10
-			newState.RecentReleaseDates = append([]time.Time{latest}, newState.RecentReleaseDates...)
-			if len(newState.RecentReleaseDates) > 10 {
-				newState.RecentReleaseDates = newState.RecentReleaseDates[:10]
-			}
-			newState.LatestReleaseAt = latest
+	if result.Activity != nil {
+		snap.Contributors = result.Activity.Contributors
+		snap.MergedPRs7d = result.Activity.MergedPRs7d
+		snap.NewIssues7d = result.Activity.NewIssues7d
+		if result.Activity.LatestRelease != nil {
+			snap.LatestRelease = result.Activity.LatestRelease.PublishedAt
 		}
 	}
 
-	// Build scoring metrics from current and previous data
-	metrics := scoring.RepoMetrics{
-		Stars:              newState.Stars,
-		Forks:              newState.Forks,
-		Contributors:       newState.Contributors,
-		MergedPRs7d:        newState.MergedPRs7d,
-		NewIssues7d:        newState.NewIssues7d,
-		RecentReleaseDates: newState.RecentReleaseDates,
-		Now:                result.Collected,
-	}
+	newState := forge.NextState(s.calculator, prev, snap)
+	newState.Host = s.client.Host()
+	newState.Owner = owner
+	newState.Name = name
 
-	// Include previous state for velocity calculations
-	if prev != nil && !prev.LastCollected.IsZero() {
-		metrics.StarsPrev = prev.Stars
-		metrics.ForksPrev = prev.Forks
-		metrics.ContributorsPrev = prev.Contributors
-		metrics.DaysElapsed = result.Collected.Sub(prev.LastCollected).Hours() / 24
-		metrics.PrevStarVelocity = prev.StarVelocity
-
-		// Store previous values for reference
-		newState.StarsPrev = prev.Stars
-		newState.ForksPrev = prev.Forks
-		newState.ContributorsPrev = prev.Contributors
+	// Store conditional request info for future requests
+	if result.ConditionInfo != nil {
+		newState.ETag = result.ConditionInfo.ETag
+		newState.LastModified = result.ConditionInfo.LastModified
 	}
 
-	// Calculate all velocities using the scoring calculator
-	velocities := s.calculator.CalculateVelocities(metrics)
-	newState.StarVelocity = velocities.StarVelocity
-	newState.StarAcceleration = velocities.StarAcceleration
-	newState.ForkVelocity = velocities.ForkVelocity
-	newState.ReleaseCadence = velocities.ReleaseCadence
-	newState.PRVelocity = velocities.PRVelocity
-	newState.IssueVelocity = velocities.IssueVelocity
-	newState.ContributorGrowth = velocities.ContributorGrowth
-
-	// Calculate raw growth score
-	newState.GrowthScore = s.calculator.CalculateRawScore(velocities)
-
 	s.store.SetRepoState(fullName, newState)
 }
 
@@ -314,6 +312,10 @@ func (s *Scanner) ScanBulk(ctx context.Context, repos []Repo) (*ScanResult, erro
 		s.log("warn", "Bulk fetch failed", "error", err, "partial_metrics", len(bulk.Metrics))
 		// Fall through — caller may still want the partial results.
 	}
+	s.log("debug", "Bulk fetch cost",
+		"queries", bulk.QueryCount,
+		"points", bulk.Cost,
+		"next_batch_size", s.client.GraphQLBatchStats().BatchSize)
 	if n := len(bulk.FailedBatches); n > 0 {
 		s.log("warn", "Bulk fetch had per-batch failures",
 			"failed_batches", n,
@@ -338,6 +340,8 @@ func (s *Scanner) ScanBulk(ctx context.Context, repos []Repo) (*ScanResult, erro
 		}
 	}
 
+	updated := make(map[string]bool, len(repos))
+
 	notFound := make(map[string]struct{}, len(bulk.NotFound))
 	for _, fn := range bulk.NotFound {
 		notFound[fn] = struct{}{}
@@ -352,16 +356,24 @@ func (s *Scanner) ScanBulk(ctx context.Context, repos []Repo) (*ScanResult, erro
 		default:
 		}
 
-		fullName := fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
+		// The bulk response is keyed by owner/name; state by the
+		// host-qualified key.
+		apiName := fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
+		fullName := s.client.RepoKey(repo.Owner, repo.Name)
 
-		if _, gone := notFound[fullName]; gone {
+		if _, gone := notFound[apiName]; gone {
 			result.RepoGone++
 			result.GoneRepos = append(result.GoneRepos, fullName)
 			s.log("info", "Repo not found via graphql (deleted/renamed), classified as repo_gone", "repo", fullName)
 			continue
 		}
 
-		metrics := bulk.Metrics[fullName]
+		if updated[fullName] {
+			result.Skipped++
+			continue
+		}
+
+		metrics := bulk.Metrics[apiName]
 		if metrics == nil {
 			result.Failed++
 			result.FailedRepos = append(result.FailedRepos, fullName)
@@ -369,10 +381,19 @@ func (s *Scanner) ScanBulk(ctx context.Context, repos []Repo) (*ScanResult, erro
 		}
 
 		prevState := s.store.GetRepoState(fullName)
+		target := repo
+		if metrics.RenamedFrom(repo.Owner, repo.Name) {
+			var done bool
+			target, prevState, done = s.followRename(result, repo, metrics.FullName, updated)
+			if done {
+				result.Successful++
+				continue
+			}
+		}
 		collResult := &CollectionResult{
-			Owner:     repo.Owner,
-			Name:      repo.Name,
-			FullName:  fullName,
+			Owner:     target.Owner,
+			Name:      target.Name,
+			FullName:  fmt.Sprintf("%s/%s", target.Owner, target.Name),
 			Metrics:   metrics,
 			Collected: time.Now(),
 		}
@@ -387,12 +408,13 @@ func (s *Scanner) ScanBulk(ctx context.Context, repos []Repo) (*ScanResult, erro
 		if s.collector != nil && s.collector.collectActivity {
 			if metrics.Activity != nil && !metrics.ActivityTruncated {
 				collResult.Activity = metrics.Activity
-			} else if activity, err := s.client.GetActivityMetrics(ctx, repo.Owner, repo.Name); err == nil {
+			} else if activity, err := s.client.GetActivityMetrics(ctx, target.Owner, target.Name); err == nil {
 				collResult.Activity = activity
 			}
 		}
 
-		s.updateRepoState(repo.Owner, repo.Name, collResult, prevState)
+		s.updateRepoState(target.Owner, target.Name, collResult, prevState)
+		updated[s.client.RepoKey(target.Owner, target.Name)] = true
 		result.Successful++
 		result.Updated++
 	}
@@ -86,6 +86,52 @@ func TestScanner_Scan_Success(t *testing.T) {
 	}
 }
 
+func TestScanner_Scan_EnterpriseHostKeysState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v3/") {
+			t.Errorf("request outside the host's REST base: %s", r.URL.Path)
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/repos/test/repo"):
+			w.Write([]byte(`{"owner": {"login": "test"}, "name": "repo", "full_name": "test/repo", "stargazers_count": 150, "forks_count": 15}`))
+		case strings.Contains(r.URL.Path, "/releases"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient("ghe-token")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	client.SetBaseURL(server.URL + "/api/v3")
+	client.SetHost("ghe.corp")
+
+	store := state.NewStore(filepath.Join(t.TempDir(), "state.json"))
+	store.SetRepoState("test/repo", state.RepoState{Owner: "test", Name: "repo", Stars: 7})
+
+	result, err := NewScanner(client, store).Scan(context.Background(), []Repo{{Host: "ghe.corp", Owner: "test", Name: "repo"}})
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	if result.Successful != 1 {
+		t.Fatalf("Successful = %d, want 1 (failed: %v)", result.Successful, result.FailedRepos)
+	}
+
+	internal := store.GetRepoState("ghe.corp/test/repo")
+	if internal == nil {
+		t.Fatal("state not stored under the host-qualified key")
+	}
+	if internal.Host != "ghe.corp" || internal.Stars != 150 {
+		t.Errorf("internal state = host %q stars %d, want ghe.corp / 150", internal.Host, internal.Stars)
+	}
+	if public := store.GetRepoState("test/repo"); public == nil || public.Stars != 7 {
+		t.Errorf("github.com state for the same owner/name was touched: %+v", public)
+	}
+}
+
 func TestScanner_Scan_VelocityCalculation(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
@@ -469,3 +515,62 @@ func TestIsRepoNotFoundError_MatchesGoneType(t *testing.T) {
 		})
 	}
 }
+
+// TestScanner_Scan_FollowsRename — a repo tracked under its old name is
+// re-keyed under the new one, its history merged, and the rename is
+// reported so the caller can persist the alias.
+func TestScanner_Scan_FollowsRename(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/old/name":
+			http.Redirect(w, r, "/repos/new/name", http.StatusMovedPermanently)
+		case "/repos/new/name":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"owner": {"login": "new"}, "name": "name", "full_name": "new/name", "stargazers_count": 200}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-token")
+	client.SetBaseURL(server.URL)
+
+	store := state.NewStore(filepath.Join(t.TempDir(), "state.json"))
+	released := time.Now().Add(-30 * 24 * time.Hour).UTC()
+	store.SetRepoState("old/name", state.RepoState{
+		Owner: "old", Name: "name", Stars: 150,
+		LastCollected:      time.Now().Add(-24 * time.Hour),
+		RecentReleaseDates: []time.Time{released},
+	})
+
+	scanner := NewScanner(client, store)
+	result, err := scanner.Scan(context.Background(), []Repo{
+		{Owner: "old", Name: "name"},
+		{Owner: "new", Name: "name"},
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if got := result.Renamed["old/name"]; got != "new/name" {
+		t.Errorf("Renamed = %v, want old/name → new/name", result.Renamed)
+	}
+	if result.Updated != 1 || result.Skipped != 1 {
+		t.Errorf("Updated = %d, Skipped = %d, want 1 and 1 (new name not refreshed twice)", result.Updated, result.Skipped)
+	}
+	if store.GetRepoState("old/name") != nil {
+		t.Error("old name still in store after rename")
+	}
+	rs := store.GetRepoState("new/name")
+	if rs == nil {
+		t.Fatal("new name missing from store")
+	}
+	if rs.Owner != "new" || rs.Stars != 200 || rs.StarsPrev != 150 {
+		t.Errorf("state = owner %q stars %d prev %d, want new/200/150", rs.Owner, rs.Stars, rs.StarsPrev)
+	}
+	if len(rs.RecentReleaseDates) != 1 || !rs.RecentReleaseDates[0].Equal(released) {
+		t.Errorf("RecentReleaseDates = %v, want release history carried over", rs.RecentReleaseDates)
+	}
+}
@@ -19,6 +19,15 @@ type SearchResult struct {
 	Forks       int
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	// Fork and Mirror flag non-canonical copies. The search API does
+	// not expose the parent, so resolving a fork's upstream needs a
+	// GetRepository call.
+	Fork   bool
+	Mirror bool
+
+	// HTMLURL is the repo's web page on the server that was searched.
+	HTMLURL string
 }
 
 // searchResponse represents the GitHub search API response.
@@ -42,6 +51,9 @@ type searchResultItem struct {
 	ForksCount      int      `json:"forks_count"`
 	CreatedAt       string   `json:"created_at"`
 	UpdatedAt       string   `json:"updated_at"`
+	Fork            bool     `json:"fork"`
+	MirrorURL       string   `json:"mirror_url"`
+	HTMLURL         string   `json:"html_url"`
 }
 
 // SearchRepositories searches for repositories matching the given query.
@@ -99,6 +111,9 @@ func (c *Client) SearchRepositories(ctx context.Context, query, sort, order stri
 			Forks:       item.ForksCount,
 			CreatedAt:   createdAt,
 			UpdatedAt:   updatedAt,
+			Fork:        item.Fork,
+			Mirror:      item.MirrorURL != "",
+			HTMLURL:     item.HTMLURL,
 		})
 	}
 
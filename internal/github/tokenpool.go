@@ -0,0 +1,288 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/cassette"
+)
+
+// tokenpool.go lets one Client spread its calls over several tokens.
+// Each token's budget is tracked per rate-limit resource from the
+// response headers, and every request goes to the token with the most
+// remaining budget for the resource it will be charged to. Client's
+// aggregate view (RateLimitInfo, ShouldBackoff, the rate-limit gauges)
+// sums the REST ("core") budget across the pool.
+
+// Rate-limit resources tracked separately per token. GitHub names the
+// bucket a response was charged to in X-RateLimit-Resource.
+const (
+	ResourceCore    = "core"
+	ResourceGraphQL = "graphql"
+	ResourceSearch  = "search"
+)
+
+// TokenRateLimitObserver is optionally implemented by an APIObserver
+// that wants per-token budgets. It is called after each response with
+// the budget of the token that served it.
+type TokenRateLimitObserver interface {
+	ObserveTokenRateLimit(tokenID, resource string, limit, remaining int, resetAt time.Time)
+}
+
+// TokenRateLimit is one token's budget for one resource.
+type TokenRateLimit struct {
+	// TokenID is the redacted token label (see TokenID).
+	TokenID  string
+	Resource string
+	RateLimit
+}
+
+// poolToken is one token and its last-seen budgets. limits is guarded
+// by Client.mu. A GitHub App member has app set and no fixed token.
+type poolToken struct {
+	token  string
+	app    *appInstallation
+	id     string
+	limits map[string]RateLimit
+}
+
+// bearer returns the credential to send for tok, refreshing a GitHub
+// App installation token when it is due.
+func (c *Client) bearer(ctx context.Context, tok *poolToken) (string, error) {
+	if tok.app != nil {
+		return tok.app.currentToken(ctx, c)
+	}
+	return tok.token, nil
+}
+
+// TokenID returns a stable label for token that is safe to log and to
+// use as a metric attribute: a short hash, never the token itself.
+func TokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "tok_" + hex.EncodeToString(sum[:4])
+}
+
+// NewPoolClient creates a client that rotates over tokens. Blank and
+// duplicate entries are dropped; at least one token must remain.
+func NewPoolClient(tokens []string) (*Client, error) {
+	seen := make(map[string]bool, len(tokens))
+	pool := make([]*poolToken, 0, len(tokens))
+	for _, t := range tokens {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		pool = append(pool, &poolToken{token: t, id: TokenID(t), limits: make(map[string]RateLimit)})
+	}
+	if len(pool) == 0 {
+		return nil, fmt.Errorf("github token cannot be empty")
+	}
+
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: cassette.Transport(cassette.ServiceGitHub),
+		},
+		tokens:  pool,
+		baseURL: DefaultBaseURL,
+	}, nil
+}
+
+// TokenCount returns the number of tokens in the pool.
+func (c *Client) TokenCount() int {
+	return len(c.tokens)
+}
+
+// TokenRateLimits returns every known per-token budget, in pool order.
+func (c *Client) TokenRateLimits() []TokenRateLimit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var out []TokenRateLimit
+	for _, t := range c.tokens {
+		for _, res := range []string{ResourceCore, ResourceGraphQL, ResourceSearch} {
+			if rl, ok := t.limits[res]; ok {
+				out = append(out, TokenRateLimit{TokenID: t.id, Resource: res, RateLimit: rl})
+			}
+		}
+	}
+	return out
+}
+
+// ResourceRateLimitInfo returns the pool-wide budget for resource, e.g.
+// ResourceGraphQL. RateLimitInfo is the ResourceCore view.
+func (c *Client) ResourceRateLimitInfo(resource string) RateLimit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.aggregateLocked(resource)
+}
+
+// aggregateLocked sums resource's budget across the pool. Tokens that
+// have not reported a budget yet are assumed to have a full window,
+// sized like the largest known one, so the pool is never reported
+// exhausted while a token is still untried. Reset is the earliest known
+// reset, i.e. when budget next comes back; a reset already in the past
+// is left for IsRateLimitExhausted to clear, as with a single token.
+// Caller must hold c.mu.
+func (c *Client) aggregateLocked(resource string) RateLimit {
+	var agg RateLimit
+	known, unknown, maxLimit := 0, 0, 0
+	for _, t := range c.tokens {
+		rl, ok := t.limits[resource]
+		if !ok {
+			unknown++
+			continue
+		}
+		known++
+		agg.Limit += rl.Limit
+		agg.Remaining += rl.Remaining
+		if rl.Limit > maxLimit {
+			maxLimit = rl.Limit
+		}
+		if !rl.Reset.IsZero() && (agg.Reset.IsZero() || rl.Reset.Before(agg.Reset)) {
+			agg.Reset = rl.Reset
+		}
+	}
+	if known == 0 {
+		return RateLimit{}
+	}
+	agg.Limit += unknown * maxLimit
+	agg.Remaining += unknown * maxLimit
+	return agg
+}
+
+// pickToken returns the token with the most remaining budget for
+// resource. A token with unknown budget wins over any known one, so a
+// fresh pool probes every token once before settling. Ties go to the
+// earlier token.
+func (c *Client) pickToken(resource string) *poolToken {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	best, bestRemaining := c.tokens[0], -1
+	for _, t := range c.tokens {
+		remaining := math.MaxInt32
+		if rl, ok := t.limits[resource]; ok && rl.Limit > 0 && (rl.Reset.IsZero() || !rl.Reset.Before(now)) {
+			remaining = rl.Remaining
+		}
+		if remaining > bestRemaining {
+			best, bestRemaining = t, remaining
+		}
+	}
+	return best
+}
+
+// credentialScope names the credentials the client sends, for the HTTP
+// cache key: clients with different tokens or App installations that
+// share one cache must not serve each other's responses. It is derived
+// from token IDs, never from the tokens themselves.
+func (c *Client) credentialScope() string {
+	ids := make([]string, 0, len(c.tokens))
+	for _, t := range c.tokens {
+		id := t.id
+		if t.app != nil {
+			t.app.mu.Lock()
+			if t.app.installationID != 0 {
+				id = fmt.Sprintf("%s/%d", id, t.app.installationID)
+			}
+			t.app.mu.Unlock()
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// poolAuthorization reports whether header is the Authorization value
+// of one of the pool's tokens, i.e. it was set by Do on an earlier
+// attempt and may be replaced when the request is retried.
+func (c *Client) poolAuthorization(header string) bool {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return false
+	}
+	for _, t := range c.tokens {
+		if t.app != nil && t.app.issued(token) || t.app == nil && token == t.token {
+			return true
+		}
+	}
+	return false
+}
+
+// requestResource guesses the rate-limit resource a request will be
+// charged to, before the response names it.
+func requestResource(req *http.Request) string {
+	path := req.URL.Path
+	switch {
+	case strings.HasSuffix(path, graphqlEndpoint):
+		return ResourceGraphQL
+	case strings.Contains(path, "/search/"):
+		return ResourceSearch
+	}
+	return ResourceCore
+}
+
+// updateRateLimitFromHeaders records the `X-RateLimit-*` headers of a
+// response served by tok against resource (or the resource the response
+// names) and refreshes the pool-wide core view. It returns the resource
+// and the token's updated budget; ok is false when the response carried
+// no rate-limit headers.
+func (c *Client) updateRateLimitFromHeaders(tok *poolToken, resource string, headers http.Header) (string, RateLimit, bool) {
+	if r := strings.TrimSpace(headers.Get("X-RateLimit-Resource")); r != "" {
+		resource = r
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rl := tok.limits[resource]
+	seen := false
+	if limit := headers.Get("X-RateLimit-Limit"); limit != "" {
+		if v, err := strconv.Atoi(limit); err == nil {
+			rl.Limit = v
+			seen = true
+		}
+	}
+
+	if remaining := headers.Get("X-RateLimit-Remaining"); remaining != "" {
+		if v, err := strconv.Atoi(remaining); err == nil {
+			rl.Remaining = v
+			seen = true
+		}
+	}
+
+	if reset := headers.Get("X-RateLimit-Reset"); reset != "" {
+		if v, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			rl.Reset = time.Unix(v, 0)
+			seen = true
+		}
+	}
+
+	if !seen {
+		return resource, rl, false
+	}
+	tok.limits[resource] = rl
+	if resource == ResourceCore {
+		c.rateLimit = c.aggregateLocked(ResourceCore)
+	}
+	return resource, rl, true
+}
+
+// clearTokenBudgetsLocked forgets every token's budget for resource, so
+// they are treated as unknown (full) until the next response. Caller
+// must hold c.mu.
+func (c *Client) clearTokenBudgetsLocked(resource string) {
+	for _, t := range c.tokens {
+		delete(t.limits, resource)
+	}
+}
@@ -0,0 +1,222 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// budgetServer answers every request with X-RateLimit headers tracking
+// a per-token, per-resource budget, and counts calls per token.
+type budgetServer struct {
+	mu     sync.Mutex
+	budget map[string]int // "token/resource" -> remaining
+	calls  map[string]int // token -> calls
+	reset  time.Time
+}
+
+func newBudgetServer(t *testing.T, budget map[string]int) (*budgetServer, *httptest.Server) {
+	t.Helper()
+	b := &budgetServer{budget: budget, calls: make(map[string]int), reset: time.Now().Add(time.Hour)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		resource := ResourceCore
+		if r.URL.Path == graphqlEndpoint {
+			resource = ResourceGraphQL
+		}
+
+		b.mu.Lock()
+		b.calls[token]++
+		key := token + "/" + resource
+		remaining := b.budget[key]
+		if remaining > 0 {
+			b.budget[key] = remaining - 1
+			remaining--
+		}
+		b.mu.Unlock()
+
+		w.Header().Set("X-RateLimit-Resource", resource)
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(b.reset.Unix(), 10))
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return b, srv
+}
+
+func TestNewPoolClient_DedupesAndRejectsEmpty(t *testing.T) {
+	c, err := NewPoolClient([]string{" a ", "b", "a", ""})
+	if err != nil {
+		t.Fatalf("NewPoolClient: %v", err)
+	}
+	if c.TokenCount() != 2 {
+		t.Errorf("TokenCount = %d, want 2", c.TokenCount())
+	}
+	if _, err := NewPoolClient([]string{" ", ""}); err == nil {
+		t.Error("expected error for a pool without tokens")
+	}
+}
+
+func TestTokenID_Redacts(t *testing.T) {
+	id := TokenID("ghp_supersecretvalue")
+	if strings.Contains(id, "supersecret") || !strings.HasPrefix(id, "tok_") {
+		t.Errorf("TokenID = %q, want a redacted tok_ label", id)
+	}
+	if id != TokenID("ghp_supersecretvalue") {
+		t.Error("TokenID should be stable")
+	}
+}
+
+func TestPool_RoutesToTokenWithMostBudget(t *testing.T) {
+	b, srv := newBudgetServer(t, map[string]int{
+		"low/core":  100,
+		"high/core": 4000,
+	})
+	c, _ := NewPoolClient([]string{"low", "high"})
+	c.SetBaseURL(srv.URL)
+
+	for i := 0; i < 10; i++ {
+		resp, err := c.Get(context.Background(), "/repos/o/r")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// One probe each, then everything goes to the fuller token.
+	if b.calls["low"] != 1 || b.calls["high"] != 9 {
+		t.Errorf("calls = %v, want low=1 high=9", b.calls)
+	}
+	if rl := c.RateLimitInfo(); rl.Limit != 10000 || rl.Remaining != 99+3991 {
+		t.Errorf("pool RateLimitInfo = %+v, want 10000 limit and 4090 remaining", rl)
+	}
+	if got := len(c.TokenRateLimits()); got != 2 {
+		t.Errorf("TokenRateLimits = %d entries, want 2", got)
+	}
+}
+
+func TestPool_ShouldBackoffUsesWholePool(t *testing.T) {
+	_, srv := newBudgetServer(t, map[string]int{
+		"a/core": 61,
+		"b/core": 61,
+	})
+	c, _ := NewPoolClient([]string{"a", "b"})
+	c.SetBaseURL(srv.URL)
+	c.SetRateLimitOptions(RateLimitOptions{Threshold: 100})
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.Get(context.Background(), "/repos/o/r")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+	}
+	// 60 + 60 left: each token is below the threshold, the pool is not.
+	if c.ShouldBackoff() {
+		t.Errorf("ShouldBackoff = true with %+v", c.RateLimitInfo())
+	}
+}
+
+func TestPool_GraphQLBudgetTrackedSeparately(t *testing.T) {
+	_, srv := newBudgetServer(t, map[string]int{
+		"a/core":    1,
+		"a/graphql": 500,
+	})
+	c, _ := NewPoolClient([]string{"a"})
+	c.SetBaseURL(srv.URL)
+
+	resp, err := c.Get(context.Background(), "/repos/o/r")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if !c.IsRateLimitExhausted() {
+		t.Fatalf("REST budget should be exhausted: %+v", c.RateLimitInfo())
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+graphqlEndpoint, nil)
+	resp, err = c.Do(req)
+	if err != nil {
+		t.Fatalf("GraphQL call refused although its budget is untouched: %v", err)
+	}
+	resp.Body.Close()
+
+	if rl := c.ResourceRateLimitInfo(ResourceGraphQL); rl.Remaining != 499 {
+		t.Errorf("graphql budget = %+v, want 499 remaining", rl)
+	}
+	if rl := c.RateLimitInfo(); rl.Remaining != 0 {
+		t.Errorf("core budget = %+v, want 0 remaining", rl)
+	}
+}
+
+func TestPool_RetryMovesToAnotherToken(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Lock()
+		seen = append(seen, token)
+		mu.Unlock()
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		if token == "spent" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, _ := NewPoolClient([]string{"spent", "fresh"})
+	c.SetBaseURL(srv.URL)
+	c.SetRetryConfig(RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	resp, err := c.GetWithRetry(context.Background(), "/repos/o/r")
+	if err != nil {
+		t.Fatalf("GetWithRetry: %v", err)
+	}
+	resp.Body.Close()
+	if len(seen) != 2 || seen[0] != "spent" || seen[1] != "fresh" {
+		t.Errorf("tokens used = %v, want [spent fresh]", seen)
+	}
+}
+
+type tokenObserver struct {
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+func (o *tokenObserver) ObserveCall(string, string)           {}
+func (o *tokenObserver) ObserveRateLimit(int, int, time.Time) {}
+func (o *tokenObserver) ObserveTokenRateLimit(id, _ string, _, remaining int, _ time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ids[id] = remaining
+}
+
+func TestPool_ObserverReceivesRedactedTokenBudgets(t *testing.T) {
+	_, srv := newBudgetServer(t, map[string]int{"secret-a/core": 10, "secret-b/core": 20})
+	c, _ := NewPoolClient([]string{"secret-a", "secret-b"})
+	c.SetBaseURL(srv.URL)
+	obs := &tokenObserver{ids: make(map[string]int)}
+	c.SetAPIObserver(obs)
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.Get(context.Background(), "/repos/o/r")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if obs.ids[TokenID("secret-a")] != 9 || obs.ids[TokenID("secret-b")] != 19 {
+		t.Errorf("per-token budgets = %v", obs.ids)
+	}
+}
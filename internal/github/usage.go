@@ -0,0 +1,62 @@
+package github
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// APIUsage is a running total of the GitHub API budget a Client has
+// been charged. Take a snapshot before and after a piece of work and
+// Sub them to see what it cost.
+type APIUsage struct {
+	// Core and Search count requests that reached GitHub. A 304 and a
+	// response served from the HTTP cache are free and not counted.
+	Core   int
+	Search int
+	// GraphQL counts the rate-limit points bulk queries reported.
+	GraphQL int
+}
+
+// Sub returns the usage between the snapshot prev and u.
+func (u APIUsage) Sub(prev APIUsage) APIUsage {
+	return APIUsage{
+		Core:    u.Core - prev.Core,
+		Search:  u.Search - prev.Search,
+		GraphQL: u.GraphQL - prev.GraphQL,
+	}
+}
+
+// usageCounter backs Client.APIUsage.
+type usageCounter struct {
+	core, search, graphql atomic.Int64
+}
+
+// request counts one response from GitHub. GraphQL requests are
+// counted in points by graphqlPoints instead.
+func (u *usageCounter) request(resource string, status int) {
+	if status == http.StatusNotModified {
+		return
+	}
+	switch resource {
+	case ResourceCore:
+		u.core.Add(1)
+	case ResourceSearch:
+		u.search.Add(1)
+	}
+}
+
+// graphqlPoints counts the points one GraphQL query was charged. GitHub
+// charges at least one.
+func (u *usageCounter) graphqlPoints(cost int) {
+	u.graphql.Add(int64(max(cost, 1)))
+}
+
+// APIUsage returns the budget this client has been charged since it
+// was created.
+func (c *Client) APIUsage() APIUsage {
+	return APIUsage{
+		Core:    int(c.usage.core.Load()),
+		Search:  int(c.usage.search.Load()),
+		GraphQL: int(c.usage.graphql.Load()),
+	}
+}
@@ -0,0 +1,44 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_APIUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("tkn")
+	client.SetBaseURL(server.URL)
+	ctx := context.Background()
+	before := client.APIUsage()
+
+	for _, path := range []string{"/repos/o/r", "/repos/o/r/pulls", "/search/repositories?q=x"} {
+		resp, err := client.Get(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	req, _ := client.newRequest(ctx, http.MethodGet, "/repos/o/r")
+	req.Header.Set("If-None-Match", `"v1"`)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	got := client.APIUsage().Sub(before)
+	if got != (APIUsage{Core: 2, Search: 1}) {
+		t.Errorf("usage = %+v, want 2 core and 1 search; 304s are free", got)
+	}
+}
@@ -0,0 +1,145 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// webhook.go verifies and decodes GitHub webhook deliveries. Only the
+// fields the daemon applies between scans are decoded: the repository
+// counters every payload carries, the action, the sender, and the
+// release or pull-request timestamps.
+
+// Webhook event names, as sent in the X-GitHub-Event header.
+const (
+	WebhookStar        = "star"
+	WebhookFork        = "fork"
+	WebhookRelease     = "release"
+	WebhookPullRequest = "pull_request"
+	WebhookIssues      = "issues"
+	WebhookPing        = "ping"
+)
+
+// ErrUnsupportedWebhook is returned by ParseWebhookEvent for events the
+// daemon does not ingest.
+var ErrUnsupportedWebhook = errors.New("unsupported webhook event")
+
+// ErrWebhookSignature is returned for a delivery whose
+// X-Hub-Signature-256 is missing or does not match the secret.
+var ErrWebhookSignature = errors.New("webhook signature mismatch")
+
+// VerifyWebhookSignature checks the X-Hub-Signature-256 header of a
+// delivery: "sha256=" followed by the hex HMAC-SHA256 of the body keyed
+// with the webhook secret.
+func VerifyWebhookSignature(secret string, body []byte, signature string) error {
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrWebhookSignature
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return ErrWebhookSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrWebhookSignature
+	}
+	return nil
+}
+
+// WebhookEvent is the part of a delivery the daemon uses.
+type WebhookEvent struct {
+	// Event is the X-GitHub-Event name, e.g. WebhookStar.
+	Event  string
+	Action string
+	// FullName is the repository's owner/name.
+	FullName string
+	Sender   string
+
+	// Stars and Forks are the repository's counters as of the
+	// delivery, when HasCounts.
+	Stars     int
+	Forks     int
+	HasCounts bool
+
+	// ReleasePublishedAt is set for a published release.
+	ReleasePublishedAt time.Time
+	// Merged reports a pull request closed by merging.
+	Merged bool
+}
+
+// IsActivity reports whether the delivery is a new star, fork, release,
+// pull request or issue, as opposed to e.g. an unstar or an edit.
+func (e WebhookEvent) IsActivity() bool {
+	switch e.Event {
+	case WebhookStar:
+		return e.Action == "created"
+	case WebhookFork:
+		return true
+	case WebhookRelease:
+		return e.Action == "published"
+	case WebhookPullRequest, WebhookIssues:
+		return e.Action == "opened" || e.Action == "closed" || e.Action == "reopened"
+	}
+	return false
+}
+
+// webhookPayload is the JSON shape shared by the supported events.
+type webhookPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName        string `json:"full_name"`
+		StargazersCount *int   `json:"stargazers_count"`
+		ForksCount      *int   `json:"forks_count"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+	Release struct {
+		PublishedAt *time.Time `json:"published_at"`
+	} `json:"release"`
+	PullRequest struct {
+		Merged bool `json:"merged"`
+	} `json:"pull_request"`
+}
+
+// ParseWebhookEvent decodes a delivery of the named event. Events other
+// than star, fork, release, pull_request and issues return
+// ErrUnsupportedWebhook.
+func ParseWebhookEvent(event string, body []byte) (WebhookEvent, error) {
+	switch event {
+	case WebhookStar, WebhookFork, WebhookRelease, WebhookPullRequest, WebhookIssues:
+	default:
+		return WebhookEvent{}, fmt.Errorf("%w %q", ErrUnsupportedWebhook, event)
+	}
+
+	var p webhookPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return WebhookEvent{}, fmt.Errorf("decoding %s webhook: %w", event, err)
+	}
+	if p.Repository.FullName == "" {
+		return WebhookEvent{}, fmt.Errorf("%s webhook has no repository", event)
+	}
+
+	e := WebhookEvent{
+		Event:    event,
+		Action:   p.Action,
+		FullName: p.Repository.FullName,
+		Sender:   p.Sender.Login,
+		Merged:   event == WebhookPullRequest && p.PullRequest.Merged,
+	}
+	if p.Repository.StargazersCount != nil && p.Repository.ForksCount != nil {
+		e.Stars, e.Forks, e.HasCounts = *p.Repository.StargazersCount, *p.Repository.ForksCount, true
+	}
+	if event == WebhookRelease && p.Action == "published" && p.Release.PublishedAt != nil {
+		e.ReleasePublishedAt = p.Release.PublishedAt.UTC()
+	}
+	return e, nil
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"action":"created"}`)
+	if err := VerifyWebhookSignature("s3cret", body, sign("s3cret", body)); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	for name, sig := range map[string]string{
+		"wrong secret": sign("other", body),
+		"missing":      "",
+		"sha1":         "sha1=abc",
+		"not hex":      "sha256=zz",
+	} {
+		if err := VerifyWebhookSignature("s3cret", body, sig); !errors.Is(err, ErrWebhookSignature) {
+			t.Errorf("%s: err = %v, want ErrWebhookSignature", name, err)
+		}
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	release := []byte(`{
+		"action": "published",
+		"release": {"tag_name": "v1.2.0", "published_at": "2026-10-18T09:30:00Z"},
+		"repository": {"full_name": "acme/radar", "stargazers_count": 1200, "forks_count": 80},
+		"sender": {"login": "octocat"}
+	}`)
+	e, err := ParseWebhookEvent(WebhookRelease, release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	if e.FullName != "acme/radar" || !e.HasCounts || e.Stars != 1200 || e.Forks != 80 || e.Sender != "octocat" || !e.ReleasePublishedAt.Equal(want) {
+		t.Errorf("release event = %+v", e)
+	}
+	if !e.IsActivity() {
+		t.Error("published release is not activity")
+	}
+
+	e, err = ParseWebhookEvent(WebhookPullRequest, []byte(`{"action":"closed","pull_request":{"merged":true},"repository":{"full_name":"acme/radar"}}`))
+	if err != nil || !e.Merged {
+		t.Errorf("merged pull request = %+v, %v", e, err)
+	}
+
+	e, _ = ParseWebhookEvent(WebhookStar, []byte(`{"action":"deleted","repository":{"full_name":"acme/radar","stargazers_count":1199}}`))
+	if e.IsActivity() {
+		t.Error("unstar counted as activity")
+	}
+
+	if e.HasCounts {
+		t.Error("payload without counters reported counts")
+	}
+
+	if _, err := ParseWebhookEvent("push", []byte(`{}`)); !errors.Is(err, ErrUnsupportedWebhook) {
+		t.Error("push event accepted")
+	}
+	if _, err := ParseWebhookEvent(WebhookFork, []byte(`{"action":"created"}`)); err == nil {
+		t.Error("event without repository accepted")
+	}
+}
@@ -0,0 +1,164 @@
+// Package gitlab implements forge.Provider for GitLab.com and
+// self-managed GitLab, over the REST API v4.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/forge"
+)
+
+// DefaultBaseURL is the GitLab.com REST API.
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+const (
+	defaultTimeout = 30 * time.Second
+	userAgent      = "github-radar"
+
+	// maxErrorBody bounds how much of an error response is kept.
+	maxErrorBody = 512
+)
+
+// Client is a GitLab REST client. It implements forge.Provider.
+type Client struct {
+	name       string
+	baseURL    string
+	token      string
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	rateLimit forge.RateLimit
+}
+
+var _ forge.Provider = (*Client)(nil)
+
+// NewClient creates a client for the GitLab instance at baseURL
+// (DefaultBaseURL when empty) whose repos are keyed name:owner/repo.
+// token is a personal, group or project access token; empty for
+// anonymous access.
+func NewClient(name, baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		name:       name,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// SetHTTPClient sets a custom HTTP client (useful for testing).
+func (c *Client) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// Name implements forge.Provider.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// RateLimit implements forge.Provider with the RateLimit-* headers of
+// the last response.
+func (c *Client) RateLimit() forge.RateLimit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rateLimit
+}
+
+// APIError is a non-2xx GitLab response. A 404 matches forge.ErrNotFound.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gitlab: GET %s: status %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+// Is reports a 404 as forge.ErrNotFound.
+func (e *APIError) Is(target error) bool {
+	return target == forge.ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// projectPath returns the API path of a project: its URL-encoded
+// namespace/name path.
+func projectPath(owner, name string) string {
+	return "/projects/" + url.PathEscape(owner+"/"+name)
+}
+
+// get issues a GET for path and returns the successful response, whose
+// body the caller must close.
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: GET %s: %w", path, err)
+	}
+	c.updateRateLimit(resp.Header)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Path: path, Message: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// getJSON issues a GET for path and decodes the JSON body into v. It
+// returns the response headers for pagination.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) (http.Header, error) {
+	resp, err := c.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("gitlab: decoding %s: %w", path, err)
+	}
+	return resp.Header, nil
+}
+
+// updateRateLimit records the RateLimit-* headers GitLab sends on
+// rate-limited endpoints.
+func (c *Client) updateRateLimit(h http.Header) {
+	limit, err := strconv.Atoi(h.Get("RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, _ := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	reset, _ := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = forge.RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+}
+
+// headerInt parses a numeric pagination header such as X-Total; ok is
+// false when GitLab omitted it (it does for very large result sets).
+func headerInt(h http.Header, key string) (int, bool) {
+	n, err := strconv.Atoi(h.Get(key))
+	return n, err == nil
+}
@@ -0,0 +1,131 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/forge"
+)
+
+// newStub serves one project, gnome/world/podcasts, under /api/v4.
+func newStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	const project = "/api/v4/projects/gnome%2Fworld%2Fpodcasts"
+	recent := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-test" {
+			t.Errorf("%s: missing token", r.URL.Path)
+		}
+		w.Header().Set("RateLimit-Limit", "2000")
+		w.Header().Set("RateLimit-Remaining", "1999")
+		w.Header().Set("RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Minute).Unix()))
+
+		switch p := r.URL.EscapedPath(); p {
+		case project:
+			fmt.Fprint(w, `{
+				"path": "podcasts",
+				"path_with_namespace": "gnome/world/podcasts",
+				"namespace": {"full_path": "gnome/world"},
+				"description": "Podcast app for GNOME",
+				"topics": ["gnome", "podcasts"],
+				"star_count": 120,
+				"forks_count": 30,
+				"open_issues_count": 12,
+				"default_branch": "main",
+				"readme_url": "https://gitlab.example/gnome/world/podcasts/-/blob/main/README.md",
+				"web_url": "https://gitlab.example/gnome/world/podcasts",
+				"forked_from_project": {"path_with_namespace": "alatiera/podcasts"}
+			}`)
+		case project + "/merge_requests":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprintf(w, `[{"merged_at": %q}, {"merged_at": %q}]`, recent, old)
+				return
+			}
+			fmt.Fprintf(w, `[{"merged_at": %q}]`, recent)
+		case project + "/issues":
+			w.Header().Set("X-Total", "4")
+			fmt.Fprint(w, `[{}]`)
+		case project + "/repository/contributors":
+			w.Header().Set("X-Total", "17")
+			fmt.Fprint(w, `[{}]`)
+		case project + "/releases":
+			fmt.Fprintf(w, `[{"released_at": %q}]`, recent)
+		case project + "/repository/files/README.md/raw":
+			if r.URL.Query().Get("ref") != "main" {
+				t.Errorf("readme ref = %q, want main", r.URL.Query().Get("ref"))
+			}
+			fmt.Fprint(w, "# Podcasts")
+		case "/api/v4/projects":
+			if r.URL.Query().Get("search") != "podcast" || r.URL.Query().Get("order_by") != "star_count" {
+				t.Errorf("search query = %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `[{"path": "podcasts", "path_with_namespace": "gnome/world/podcasts", "namespace": {"full_path": "gnome/world"}, "star_count": 120}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "404 Project Not Found"}`)
+		}
+	}))
+}
+
+func TestClient_Provider(t *testing.T) {
+	server := newStub(t)
+	defer server.Close()
+	c := NewClient("gitlab", server.URL+"/api/v4", "glpat-test")
+	ctx := context.Background()
+
+	repo, err := c.GetRepository(ctx, "gnome/world", "podcasts")
+	if err != nil {
+		t.Fatalf("GetRepository() error: %v", err)
+	}
+	if repo.Owner != "gnome/world" || repo.Name != "podcasts" || repo.Stars != 120 || repo.Forks != 30 {
+		t.Errorf("GetRepository() = %+v", repo)
+	}
+	if !repo.Fork || repo.Upstream != "alatiera/podcasts" || len(repo.Topics) != 2 {
+		t.Errorf("fork/topics = %v %q %v", repo.Fork, repo.Upstream, repo.Topics)
+	}
+
+	a, err := c.GetActivity(ctx, "gnome/world", "podcasts")
+	if err != nil {
+		t.Fatalf("GetActivity() error: %v", err)
+	}
+	if a.MergedPRs7d != 2 || a.NewIssues7d != 4 || a.Contributors != 17 || a.LatestRelease.IsZero() {
+		t.Errorf("GetActivity() = %+v", a)
+	}
+
+	readme, err := c.GetReadme(ctx, "gnome/world", "podcasts")
+	if err != nil || !readme.Found || readme.Content != "# Podcasts" {
+		t.Errorf("GetReadme() = %+v, %v", readme, err)
+	}
+
+	results, err := c.SearchRepositories(ctx, "podcast", 5)
+	if err != nil || len(results) != 1 || results[0].FullName != "gnome/world/podcasts" {
+		t.Errorf("SearchRepositories() = %+v, %v", results, err)
+	}
+
+	if rl := c.RateLimit(); rl.Limit != 2000 || rl.Remaining != 1999 {
+		t.Errorf("RateLimit() = %+v", rl)
+	}
+}
+
+func TestClient_NotFound(t *testing.T) {
+	server := newStub(t)
+	defer server.Close()
+	c := NewClient("gitlab", server.URL+"/api/v4", "glpat-test")
+
+	_, err := c.GetRepository(context.Background(), "gnome", "missing")
+	if !errors.Is(err, forge.ErrNotFound) {
+		t.Fatalf("GetRepository() error = %v, want forge.ErrNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "404") {
+		t.Errorf("error %q does not carry the status", err)
+	}
+}
@@ -0,0 +1,243 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/hrexed/github-radar/internal/forge"
+)
+
+// maxActivityPages bounds how many pages of merge requests GetActivity
+// walks; a project merging more than that in a week is counted as the
+// cap.
+const maxActivityPages = 10
+
+// activityPageSize is the per_page used when walking merge requests.
+const activityPageSize = 100
+
+// projectResponse is the part of a GitLab project the provider reads.
+type projectResponse struct {
+	Path              string   `json:"path"`
+	PathWithNamespace string   `json:"path_with_namespace"`
+	Description       string   `json:"description"`
+	Topics            []string `json:"topics"`
+	TagList           []string `json:"tag_list"` // pre-14.0 name of topics
+	StarCount         int      `json:"star_count"`
+	ForksCount        int      `json:"forks_count"`
+	OpenIssuesCount   int      `json:"open_issues_count"`
+	Mirror            bool     `json:"mirror"`
+	Archived          bool     `json:"archived"`
+	DefaultBranch     string   `json:"default_branch"`
+	ReadmeURL         string   `json:"readme_url"`
+	WebURL            string   `json:"web_url"`
+	CreatedAt         string   `json:"created_at"`
+	LastActivityAt    string   `json:"last_activity_at"`
+	Namespace         struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+	ForkedFromProject *struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"forked_from_project"`
+}
+
+func (p *projectResponse) toRepository() *forge.Repository {
+	r := &forge.Repository{
+		Owner:       p.Namespace.FullPath,
+		Name:        p.Path,
+		FullName:    p.PathWithNamespace,
+		Description: p.Description,
+		Topics:      p.Topics,
+		Stars:       p.StarCount,
+		Forks:       p.ForksCount,
+		OpenIssues:  p.OpenIssuesCount,
+		Fork:        p.ForkedFromProject != nil,
+		Mirror:      p.Mirror,
+		Archived:    p.Archived,
+		URL:         p.WebURL,
+	}
+	if len(r.Topics) == 0 {
+		r.Topics = p.TagList
+	}
+	if p.ForkedFromProject != nil {
+		r.Upstream = p.ForkedFromProject.PathWithNamespace
+	}
+	r.CreatedAt, _ = time.Parse(time.RFC3339, p.CreatedAt)
+	r.UpdatedAt, _ = time.Parse(time.RFC3339, p.LastActivityAt)
+	return r
+}
+
+// getProject fetches the raw project payload.
+func (c *Client) getProject(ctx context.Context, owner, name string) (*projectResponse, error) {
+	var p projectResponse
+	if _, err := c.getJSON(ctx, projectPath(owner, name), nil, &p); err != nil {
+		return nil, fmt.Errorf("fetching project %s/%s: %w", owner, name, err)
+	}
+	return &p, nil
+}
+
+// GetRepository implements forge.Provider. GitLab does not report a
+// single primary language, so Language is left empty.
+func (c *Client) GetRepository(ctx context.Context, owner, name string) (*forge.Repository, error) {
+	p, err := c.getProject(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return p.toRepository(), nil
+}
+
+// GetActivity implements forge.Provider: merge requests merged and
+// issues opened in the last week, the contributor count, and the latest
+// release.
+func (c *Client) GetActivity(ctx context.Context, owner, name string) (*forge.Activity, error) {
+	since := time.Now().Add(-forge.ActivityWindow)
+	a := &forge.Activity{}
+	var errs []error
+
+	merged, err := c.mergedSince(ctx, owner, name, since)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("merge requests: %w", err))
+	}
+	a.MergedPRs7d = merged
+
+	if a.NewIssues7d, err = c.countTotal(ctx, projectPath(owner, name)+"/issues", url.Values{
+		"created_after": {since.UTC().Format(time.RFC3339)},
+		"scope":         {"all"},
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("issues: %w", err))
+	}
+
+	if a.Contributors, err = c.countTotal(ctx, projectPath(owner, name)+"/repository/contributors", nil); err != nil {
+		errs = append(errs, fmt.Errorf("contributors: %w", err))
+	}
+
+	var releases []struct {
+		ReleasedAt time.Time `json:"released_at"`
+	}
+	_, err = c.getJSON(ctx, projectPath(owner, name)+"/releases", url.Values{
+		"order_by": {"released_at"},
+		"sort":     {"desc"},
+		"per_page": {"1"},
+	}, &releases)
+	switch {
+	case errors.Is(err, forge.ErrNotFound):
+		// Releases disabled for the project.
+	case err != nil:
+		errs = append(errs, fmt.Errorf("releases: %w", err))
+	case len(releases) > 0:
+		a.LatestRelease = releases[0].ReleasedAt.UTC()
+	}
+
+	if len(errs) > 0 {
+		return a, fmt.Errorf("partial activity for %s/%s: %w", owner, name, errors.Join(errs...))
+	}
+	return a, nil
+}
+
+// mergedSince counts merge requests merged at or after since. GitLab
+// cannot filter on merge time, so it walks merge requests updated since
+// then and checks merged_at.
+func (c *Client) mergedSince(ctx context.Context, owner, name string, since time.Time) (int, error) {
+	query := url.Values{
+		"state":         {"merged"},
+		"updated_after": {since.UTC().Format(time.RFC3339)},
+		"per_page":      {strconv.Itoa(activityPageSize)},
+	}
+	count := 0
+	for page := 1; page <= maxActivityPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var mrs []struct {
+			MergedAt *time.Time `json:"merged_at"`
+		}
+		h, err := c.getJSON(ctx, projectPath(owner, name)+"/merge_requests", query, &mrs)
+		if err != nil {
+			return count, err
+		}
+		for _, mr := range mrs {
+			if mr.MergedAt != nil && !mr.MergedAt.Before(since) {
+				count++
+			}
+		}
+		if h.Get("X-Next-Page") == "" {
+			break
+		}
+	}
+	return count, nil
+}
+
+// countTotal returns the X-Total of a list endpoint, fetching a single
+// item. When GitLab omits the header it falls back to the page length.
+func (c *Client) countTotal(ctx context.Context, apiPath string, query url.Values) (int, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("per_page", "1")
+	var items []struct{}
+	h, err := c.getJSON(ctx, apiPath, q, &items)
+	if err != nil {
+		return 0, err
+	}
+	if n, ok := headerInt(h, "X-Total"); ok {
+		return n, nil
+	}
+	return len(items), nil
+}
+
+// GetReadme implements forge.Provider, reading the file the project
+// reports as its README from the default branch.
+func (c *Client) GetReadme(ctx context.Context, owner, name string) (*forge.Readme, error) {
+	p, err := c.getProject(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if p.ReadmeURL == "" || p.DefaultBranch == "" {
+		return &forge.Readme{}, nil
+	}
+
+	file := path.Base(p.ReadmeURL)
+	resp, err := c.get(ctx, projectPath(owner, name)+"/repository/files/"+url.PathEscape(file)+"/raw",
+		url.Values{"ref": {p.DefaultBranch}})
+	if errors.Is(err, forge.ErrNotFound) {
+		return &forge.Readme{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching readme for %s/%s: %w", owner, name, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading readme for %s/%s: %w", owner, name, err)
+	}
+	return &forge.Readme{Content: string(body), Found: true}, nil
+}
+
+// SearchRepositories implements forge.Provider over GET /projects,
+// whose search matches project names, paths and descriptions.
+func (c *Client) SearchRepositories(ctx context.Context, query string, limit int) ([]forge.Repository, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	var projects []projectResponse
+	if _, err := c.getJSON(ctx, "/projects", url.Values{
+		"search":   {query},
+		"order_by": {"star_count"},
+		"sort":     {"desc"},
+		"per_page": {strconv.Itoa(limit)},
+	}, &projects); err != nil {
+		return nil, fmt.Errorf("searching projects: %w", err)
+	}
+	out := make([]forge.Repository, 0, len(projects))
+	for i := range projects {
+		out = append(out, *projects[i].toRepository())
+	}
+	return out, nil
+}
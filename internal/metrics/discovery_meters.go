@@ -10,7 +10,7 @@
 // not under github.<entity>.* (which is reserved for observations *about*
 // GitHub data — stars, forks, rate-limit headers, etc.).
 //
-// All 5 instruments are registered up-front so the dashboard JSON can
+// All 6 instruments are registered up-front so the dashboard JSON can
 // reference stable names from day one. Wiring to actual emission sites
 // is the caller's job — see internal/daemon/gharchive_discovery_hooks.go
 // for the discovery-source hooks (lag_seconds, events_processed_total)
@@ -57,6 +57,11 @@ type DiscoveryMeters struct {
 	// a per-type breakdown should emit with the unknown-type sentinel
 	// (see EventTypeUnknown below).
 	EventsProcessed metric.Int64Counter
+
+	// MajorReleasesTotal — counter incremented when a tracked repo
+	// publishes a release inflection point seen in the firehose. Carries
+	// release_kind ∈ {first, graduation, major}.
+	MajorReleasesTotal metric.Int64Counter
 }
 
 // EventTypeUnknown is the sentinel attribute value used when emission
@@ -67,7 +72,7 @@ type DiscoveryMeters struct {
 // rows even on a degraded emission path.
 const EventTypeUnknown = "unknown"
 
-// NewDiscoveryMeters registers the 5 gharchive-discovery instruments on
+// NewDiscoveryMeters registers the 6 gharchive-discovery instruments on
 // the supplied meter. Pass the meter from Exporter.Meter() so the
 // instruments land under the same service.name as the rest of
 // github-radar telemetry.
@@ -121,6 +126,14 @@ func NewDiscoveryMeters(meter metric.Meter) (*DiscoveryMeters, error) {
 		return nil, fmt.Errorf("events_processed_total: %w", err)
 	}
 
+	if dm.MajorReleasesTotal, err = meter.Int64Counter(
+		"github_radar.discovery.gharchive.major_releases_total",
+		metric.WithUnit("1"),
+		metric.WithDescription("Release inflection points (initial version, 1.0 graduation, major bump) published by tracked repos, tagged by release_kind"),
+	); err != nil {
+		return nil, fmt.Errorf("major_releases_total: %w", err)
+	}
+
 	return dm, nil
 }
 
@@ -184,3 +197,18 @@ func (dm *DiscoveryMeters) RecordQueueDepth(ctx context.Context, depth int64) {
 	}
 	dm.QueueDepth.Record(ctx, depth)
 }
+
+// AddMajorRelease increments major_releases_total by 1 with the
+// supplied release_kind attribute. Wired to the gharchive pipeline's
+// OnTrackedRelease hook.
+func (dm *DiscoveryMeters) AddMajorRelease(ctx context.Context, kind string) {
+	if dm == nil || dm.MajorReleasesTotal == nil {
+		return
+	}
+	if kind == "" {
+		kind = EventTypeUnknown
+	}
+	dm.MajorReleasesTotal.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("release_kind", kind),
+	))
+}
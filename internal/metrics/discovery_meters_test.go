@@ -33,7 +33,7 @@ func TestNewDiscoveryMeters_RegistersAllInstruments(t *testing.T) {
 		t.Fatalf("NewDiscoveryMeters err = %v, want nil", err)
 	}
 
-	// All 5 instruments must be non-nil. The spec pins the field set in
+	// All 6 instruments must be non-nil. The spec pins the field set in
 	// docs/observability/gharchive-instrumentation-spec.md §Metric inventory.
 	if dm.LagSeconds == nil {
 		t.Error("LagSeconds = nil, want instrument")
@@ -50,6 +50,9 @@ func TestNewDiscoveryMeters_RegistersAllInstruments(t *testing.T) {
 	if dm.EventsProcessed == nil {
 		t.Error("EventsProcessed = nil, want instrument")
 	}
+	if dm.MajorReleasesTotal == nil {
+		t.Error("MajorReleasesTotal = nil, want instrument")
+	}
 }
 
 // gatherMetricNames collects emitted metric names from the reader for
@@ -82,6 +85,7 @@ func TestDiscoveryMeters_EmissionShape(t *testing.T) {
 	dm.AddCandidates(ctx, "ForkEvent", 3)
 	dm.RecordDedupRatio(ctx, 0.95)
 	dm.RecordQueueDepth(ctx, 128)
+	dm.AddMajorRelease(ctx, "graduation")
 
 	names := gatherMetricNames(t, r)
 	want := map[string]bool{
@@ -90,6 +94,7 @@ func TestDiscoveryMeters_EmissionShape(t *testing.T) {
 		"github_radar.discovery.gharchive.candidates_total":       false,
 		"github_radar.discovery.gharchive.dedup_ratio":            false,
 		"github_radar.discovery.classifier.queue_depth":           false,
+		"github_radar.discovery.gharchive.major_releases_total":   false,
 	}
 	for _, n := range names {
 		if _, ok := want[n]; ok {
@@ -154,6 +159,7 @@ func TestDiscoveryMeters_NilReceiverSafe(t *testing.T) {
 	dm.AddCandidates(ctx, "WatchEvent", 1)
 	dm.RecordDedupRatio(ctx, 0.5)
 	dm.RecordQueueDepth(ctx, 10)
+	dm.AddMajorRelease(ctx, "major")
 }
 
 // TestDiscoveryMeters_EmptyEventTypeFallsBackToUnknown — callers that
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/hrexed/github-radar/internal/logging"
+	"github.com/hrexed/github-radar/internal/repository"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -75,15 +76,31 @@ type Exporter struct {
 	issueVelocityGauge     metric.Float64Gauge
 	contributorGrowthGauge metric.Float64Gauge
 
+	// secondaryLabelGauge carries one point per (repo, secondary label).
+	secondaryLabelGauge metric.Float64Gauge
+
 	// GitHub API budget instruments (T5 / ISI-716)
 	apiRateLimitGauge     metric.Int64Gauge
 	apiRateRemainingGauge metric.Int64Gauge
 	apiRateUsedRatioGauge metric.Float64Gauge
 	apiRateResetSecsGauge metric.Int64Gauge
 	apiCallsCounter       metric.Int64Counter
+	apiTokenLimitGauge    metric.Int64Gauge
+	apiTokenRemainGauge   metric.Int64Gauge
+	apiCacheCounter       metric.Int64Counter
+	apiCacheEntriesGauge  metric.Int64Gauge
+	apiCacheBytesGauge    metric.Int64Gauge
 	refreshTierReposGauge metric.Int64Gauge
 	scanDurationHist      metric.Float64Histogram
 
+	// GraphQL point budget, kept apart from the REST budget above.
+	apiGraphQLLimitGauge     metric.Int64Gauge
+	apiGraphQLRemainingGauge metric.Int64Gauge
+	apiGraphQLUsedRatioGauge metric.Float64Gauge
+	apiGraphQLResetSecsGauge metric.Int64Gauge
+	apiGraphQLCostCounter    metric.Int64Counter
+	apiGraphQLBatchGauge     metric.Int64Gauge
+
 	// Classification health instruments (ISI-775).
 	reposPendingGauge      metric.Int64Gauge
 	classificationRunCount metric.Int64Counter
@@ -330,6 +347,14 @@ func (e *Exporter) createInstruments() error {
 		return err
 	}
 
+	e.secondaryLabelGauge, err = e.meter.Float64Gauge("github.repo.secondary_label",
+		metric.WithDescription("Confidence of a secondary category label, one series per repo and label"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
 	// GitHub API budget instruments (T5 / ISI-716) -----------------------
 	e.apiRateLimitGauge, err = e.meter.Int64Gauge("github.api.rate_limit.limit",
 		metric.WithDescription("GitHub API rate limit ceiling from X-RateLimit-Limit"),
@@ -363,6 +388,22 @@ func (e *Exporter) createInstruments() error {
 		return err
 	}
 
+	e.apiTokenLimitGauge, err = e.meter.Int64Gauge("github.api.token.rate_limit.limit",
+		metric.WithDescription("Rate limit ceiling of one pooled token, by redacted token_id and resource"),
+		metric.WithUnit("{calls}/h"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiTokenRemainGauge, err = e.meter.Int64Gauge("github.api.token.rate_limit.remaining",
+		metric.WithDescription("Remaining calls of one pooled token, by redacted token_id and resource"),
+		metric.WithUnit("{calls}"),
+	)
+	if err != nil {
+		return err
+	}
+
 	e.apiCallsCounter, err = e.meter.Int64Counter("github.api.calls_total",
 		metric.WithDescription("GitHub API calls issued, tagged by resource and result"),
 		metric.WithUnit("{calls}"),
@@ -371,6 +412,78 @@ func (e *Exporter) createInstruments() error {
 		return err
 	}
 
+	e.apiCacheCounter, err = e.meter.Int64Counter("github.api.cache.requests_total",
+		metric.WithDescription("Cacheable GitHub REST requests, tagged by endpoint and result (hit, revalidated, miss)"),
+		metric.WithUnit("{requests}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiCacheEntriesGauge, err = e.meter.Int64Gauge("github.api.cache.entries",
+		metric.WithDescription("Responses stored in the GitHub HTTP cache"),
+		metric.WithUnit("{entries}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiCacheBytesGauge, err = e.meter.Int64Gauge("github.api.cache.size",
+		metric.WithDescription("Bytes of response bodies stored in the GitHub HTTP cache"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiGraphQLLimitGauge, err = e.meter.Int64Gauge("github.api.graphql.rate_limit.limit",
+		metric.WithDescription("GitHub GraphQL point budget ceiling, summed across the token pool"),
+		metric.WithUnit("{points}/h"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiGraphQLRemainingGauge, err = e.meter.Int64Gauge("github.api.graphql.rate_limit.remaining",
+		metric.WithDescription("Remaining GitHub GraphQL points in the current window"),
+		metric.WithUnit("{points}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiGraphQLUsedRatioGauge, err = e.meter.Float64Gauge("github.api.graphql.rate_limit.used_ratio",
+		metric.WithDescription("Fraction of the GraphQL point budget consumed (0.0 - 1.0)"),
+		metric.WithUnit("{ratio}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiGraphQLResetSecsGauge, err = e.meter.Int64Gauge("github.api.graphql.rate_limit.reset_seconds",
+		metric.WithDescription("Seconds until the GraphQL point budget resets"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiGraphQLCostCounter, err = e.meter.Int64Counter("github.api.graphql.cost_total",
+		metric.WithDescription("GraphQL points charged for bulk metadata queries"),
+		metric.WithUnit("{points}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	e.apiGraphQLBatchGauge, err = e.meter.Int64Gauge("github.api.graphql.batch_size",
+		metric.WithDescription("Repos requested by the latest GraphQL bulk metadata query"),
+		metric.WithUnit("{repos}"),
+	)
+	if err != nil {
+		return err
+	}
+
 	e.refreshTierReposGauge, err = e.meter.Int64Gauge("github.api.refresh_tier.repos",
 		metric.WithDescription("Repo count currently assigned to each refresh tier"),
 		metric.WithUnit("{repos}"),
@@ -466,6 +579,11 @@ func (e *Exporter) createInstruments() error {
 
 // RepoMetrics contains metrics to record for a repository.
 type RepoMetrics struct {
+	// Provider is the forge the repo lives on; empty means GitHub.
+	Provider string
+	// Host is the GitHub Enterprise host the repo lives on; empty means
+	// github.com.
+	Host       string
 	Owner      string
 	Name       string
 	Language   string
@@ -479,6 +597,12 @@ type RepoMetrics struct {
 	// dependency gate in ISI-718 retired that emission — see ISI-989.)
 	Subcategory string
 
+	// SecondaryLabels are the repo's secondary labels, most confident
+	// first. They are exported as github.repo.secondary_label rather
+	// than as attributes, so the per-repo series keep the primary label
+	// only.
+	SecondaryLabels []SecondaryLabel
+
 	Stars        int
 	Forks        int
 	OpenIssues   int
@@ -494,16 +618,19 @@ type RepoMetrics struct {
 	ContributorGrowth float64
 }
 
+// SecondaryLabel is one secondary (category, subcategory) label of a repo.
+type SecondaryLabel struct {
+	Category    string
+	Subcategory string
+	Confidence  float64
+}
+
 // attributes builds the OTel attribute set for a RepoMetrics row. Extracted
 // from RecordRepoMetrics so the attribute shape (especially the v3 subcategory
 // emission added in ISI-786) can be asserted directly in unit tests without
 // standing up the full meter provider pipeline.
 func (m RepoMetrics) attributes() []attribute.KeyValue {
-	attrs := []attribute.KeyValue{
-		attribute.String("repo_owner", m.Owner),
-		attribute.String("repo_name", m.Name),
-		attribute.String("repo_full_name", m.Owner+"/"+m.Name),
-	}
+	attrs := m.repoAttributes()
 
 	if m.Language != "" {
 		attrs = append(attrs, attribute.String("language", m.Language))
@@ -533,6 +660,38 @@ func (m RepoMetrics) attributes() []attribute.KeyValue {
 	return attrs
 }
 
+// repoAttributes identifies the repo a series belongs to.
+func (m RepoMetrics) repoAttributes() []attribute.KeyValue {
+	// Forge repos have no GitHub host; repo_host carries the forge name
+	// so dashboards grouping by host keep them apart.
+	repo := repository.Repo{Provider: m.Provider, Host: m.Host, Owner: m.Owner, Name: m.Name}
+	provider, host := repository.GitHubProvider, repo.HostName()
+	if m.Provider != "" {
+		provider, host = m.Provider, m.Provider
+	}
+	return []attribute.KeyValue{
+		attribute.String("repo_owner", m.Owner),
+		attribute.String("repo_name", m.Name),
+		attribute.String("repo_full_name", repo.FullName()),
+		attribute.String("repo_provider", provider),
+		attribute.String("repo_host", host),
+	}
+}
+
+// secondaryLabelAttributes builds the attribute set of each
+// github.repo.secondary_label point, in SecondaryLabels order.
+func (m RepoMetrics) secondaryLabelAttributes() [][]attribute.KeyValue {
+	out := make([][]attribute.KeyValue, 0, len(m.SecondaryLabels))
+	for _, l := range m.SecondaryLabels {
+		attrs := append(m.repoAttributes(),
+			attribute.String("category", l.Category),
+			attribute.String("subcategory", l.Subcategory),
+		)
+		out = append(out, attrs)
+	}
+	return out
+}
+
 // RecordRepoMetrics records all metrics for a repository.
 func (e *Exporter) RecordRepoMetrics(ctx context.Context, m RepoMetrics) {
 	attrSet := metric.WithAttributes(m.attributes()...)
@@ -554,6 +713,10 @@ func (e *Exporter) RecordRepoMetrics(ctx context.Context, m RepoMetrics) {
 	e.prVelocityGauge.Record(ctx, m.PRVelocity, attrSet)
 	e.issueVelocityGauge.Record(ctx, m.IssueVelocity, attrSet)
 	e.contributorGrowthGauge.Record(ctx, m.ContributorGrowth, attrSet)
+
+	for i, attrs := range m.secondaryLabelAttributes() {
+		e.secondaryLabelGauge.Record(ctx, m.SecondaryLabels[i].Confidence, metric.WithAttributes(attrs...))
+	}
 }
 
 // RateLimitSnapshot carries the inputs needed to populate the GitHub API
@@ -569,15 +732,26 @@ type RateLimitSnapshot struct {
 // any goroutine; a zero Limit is treated as "no data yet" and we skip
 // the used_ratio gauge to avoid a misleading 1.0 reading.
 func (e *Exporter) RecordRateLimit(ctx context.Context, snap RateLimitSnapshot) {
-	e.apiRateLimitGauge.Record(ctx, int64(snap.Limit))
-	e.apiRateRemainingGauge.Record(ctx, int64(snap.Remaining))
+	recordBudget(ctx, snap, e.apiRateLimitGauge, e.apiRateRemainingGauge, e.apiRateUsedRatioGauge, e.apiRateResetSecsGauge)
+}
+
+// RecordGraphQLRateLimit emits the GraphQL point-budget gauges, the
+// GraphQL counterpart of RecordRateLimit.
+func (e *Exporter) RecordGraphQLRateLimit(ctx context.Context, snap RateLimitSnapshot) {
+	recordBudget(ctx, snap, e.apiGraphQLLimitGauge, e.apiGraphQLRemainingGauge, e.apiGraphQLUsedRatioGauge, e.apiGraphQLResetSecsGauge)
+}
+
+// recordBudget emits one set of rate-limit budget gauges.
+func recordBudget(ctx context.Context, snap RateLimitSnapshot, limit, remaining metric.Int64Gauge, usedRatio metric.Float64Gauge, resetSecs metric.Int64Gauge) {
+	limit.Record(ctx, int64(snap.Limit))
+	remaining.Record(ctx, int64(snap.Remaining))
 
 	if snap.Limit > 0 {
 		used := 1.0 - float64(snap.Remaining)/float64(snap.Limit)
 		if used < 0 {
 			used = 0
 		}
-		e.apiRateUsedRatioGauge.Record(ctx, used)
+		usedRatio.Record(ctx, used)
 	}
 
 	if !snap.ResetAt.IsZero() {
@@ -585,10 +759,40 @@ func (e *Exporter) RecordRateLimit(ctx context.Context, snap RateLimitSnapshot)
 		if secs < 0 {
 			secs = 0
 		}
-		e.apiRateResetSecsGauge.Record(ctx, secs)
+		resetSecs.Record(ctx, secs)
+	}
+}
+
+// RecordGraphQLBatch records one bulk metadata query: its size and the
+// points GitHub charged for it.
+func (e *Exporter) RecordGraphQLBatch(ctx context.Context, repos, cost int) {
+	e.apiGraphQLBatchGauge.Record(ctx, int64(repos))
+	if cost > 0 {
+		e.apiGraphQLCostCounter.Add(ctx, int64(cost))
 	}
 }
 
+// TokenRateLimitSnapshot is one pooled token's budget for one rate-limit
+// resource ("core", "graphql", "search"). TokenID is the redacted label
+// from github.TokenID, never the token itself.
+type TokenRateLimitSnapshot struct {
+	TokenID   string
+	Resource  string
+	Limit     int
+	Remaining int
+}
+
+// RecordTokenRateLimit emits the per-token budget gauges. The pool-wide
+// totals stay on the RecordRateLimit gauges.
+func (e *Exporter) RecordTokenRateLimit(ctx context.Context, snap TokenRateLimitSnapshot) {
+	attrs := metric.WithAttributes(
+		attribute.String("token_id", snap.TokenID),
+		attribute.String("resource", snap.Resource),
+	)
+	e.apiTokenLimitGauge.Record(ctx, int64(snap.Limit), attrs)
+	e.apiTokenRemainGauge.Record(ctx, int64(snap.Remaining), attrs)
+}
+
 // RecordAPICall increments the API call counter. `resource` should be
 // one of "repo", "graphql", "search", "activity", "readme"; `result` is
 // "ok" (2xx non-304), "not_modified" (304), "error", or "rate_limited".
@@ -599,6 +803,21 @@ func (e *Exporter) RecordAPICall(ctx context.Context, resource, result string) {
 	))
 }
 
+// RecordHTTPCache counts one cacheable REST request. `result` is "hit"
+// (no request sent), "revalidated" (free 304) or "miss".
+func (e *Exporter) RecordHTTPCache(ctx context.Context, endpoint, result string) {
+	e.apiCacheCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("endpoint", endpoint),
+		attribute.String("result", result),
+	))
+}
+
+// RecordHTTPCacheSize emits the HTTP cache size gauges.
+func (e *Exporter) RecordHTTPCacheSize(ctx context.Context, entries int, bytes int64) {
+	e.apiCacheEntriesGauge.Record(ctx, int64(entries))
+	e.apiCacheBytesGauge.Record(ctx, bytes)
+}
+
 // RecordRefreshTierHistogram emits one gauge reading per tier bucket.
 func (e *Exporter) RecordRefreshTierHistogram(ctx context.Context, counts map[string]int) {
 	for tier, n := range counts {
@@ -284,6 +284,75 @@ func TestRepoMetrics_AttributesEmitV3Taxonomy(t *testing.T) {
 	}
 }
 
+func TestRepoMetrics_SecondaryLabelAttributes(t *testing.T) {
+	m := RepoMetrics{
+		Owner:       "cilium",
+		Name:        "hubble",
+		Categories:  []string{"cloud-native"},
+		Subcategory: "networking",
+		SecondaryLabels: []SecondaryLabel{
+			{Category: "observability", Subcategory: "tracing", Confidence: 0.8},
+			{Category: "security", Subcategory: "runtime", Confidence: 0.6},
+		},
+	}
+	seen := map[string]string{}
+	for _, kv := range m.attributes() {
+		seen[string(kv.Key)] = kv.Value.AsString()
+	}
+	if seen["category"] != "cloud-native" || seen["subcategory"] != "networking" {
+		t.Errorf("primary attributes = %q/%q, want the primary label only", seen["category"], seen["subcategory"])
+	}
+	if _, ok := seen["secondary_categories"]; ok {
+		t.Error("secondary labels must not be an attribute of the per-repo series")
+	}
+
+	points := m.secondaryLabelAttributes()
+	if len(points) != 2 {
+		t.Fatalf("got %d secondary label points, want 2", len(points))
+	}
+	for i, want := range []string{"observability/tracing", "security/runtime"} {
+		seen = map[string]string{}
+		for _, kv := range points[i] {
+			seen[string(kv.Key)] = kv.Value.AsString()
+		}
+		if got := seen["category"] + "/" + seen["subcategory"]; got != want || seen["repo_full_name"] != "cilium/hubble" {
+			t.Errorf("point %d = %v, want %s on cilium/hubble", i, seen, want)
+		}
+	}
+
+	if got := (RepoMetrics{Owner: "o", Name: "r"}).secondaryLabelAttributes(); len(got) != 0 {
+		t.Errorf("single-label repo got %d secondary label points, want none", len(got))
+	}
+}
+
+func TestRepoMetrics_AttributesCarryHost(t *testing.T) {
+	cases := []struct {
+		m            RepoMetrics
+		wantProvider string
+		wantHost     string
+		wantFullName string
+	}{
+		{RepoMetrics{Owner: "team", Name: "svc"}, "github", "github.com", "team/svc"},
+		{RepoMetrics{Host: "ghe.corp", Owner: "team", Name: "svc"}, "github", "ghe.corp", "ghe.corp/team/svc"},
+		{RepoMetrics{Provider: "gitlab", Owner: "gnome/world", Name: "podcasts"}, "gitlab", "gitlab", "gitlab:gnome/world/podcasts"},
+	}
+	for _, tc := range cases {
+		seen := map[string]string{}
+		for _, kv := range tc.m.attributes() {
+			seen[string(kv.Key)] = kv.Value.AsString()
+		}
+		if seen["repo_provider"] != tc.wantProvider {
+			t.Errorf("repo_provider = %q, want %q", seen["repo_provider"], tc.wantProvider)
+		}
+		if seen["repo_host"] != tc.wantHost {
+			t.Errorf("repo_host = %q, want %q", seen["repo_host"], tc.wantHost)
+		}
+		if seen["repo_full_name"] != tc.wantFullName {
+			t.Errorf("repo_full_name = %q, want %q", seen["repo_full_name"], tc.wantFullName)
+		}
+	}
+}
+
 // TestClassificationHealthInstruments asserts the ISI-775 instruments are
 // registered on every exporter — a future caller hitting RecordPendingBuckets
 // or RecordClassificationRun before NewExporter would otherwise nil-panic
@@ -405,7 +474,19 @@ func TestExporter_APIBudgetInstrumentsCreated(t *testing.T) {
 		"apiRateUsedRatioGauge": exp.apiRateUsedRatioGauge,
 		"apiRateResetSecsGauge": exp.apiRateResetSecsGauge,
 		"apiCallsCounter":       exp.apiCallsCounter,
+		"apiTokenLimitGauge":    exp.apiTokenLimitGauge,
+		"apiTokenRemainGauge":   exp.apiTokenRemainGauge,
+		"apiCacheCounter":       exp.apiCacheCounter,
+		"apiCacheEntriesGauge":  exp.apiCacheEntriesGauge,
+		"apiCacheBytesGauge":    exp.apiCacheBytesGauge,
 		"refreshTierReposGauge": exp.refreshTierReposGauge,
+
+		"apiGraphQLLimitGauge":     exp.apiGraphQLLimitGauge,
+		"apiGraphQLRemainingGauge": exp.apiGraphQLRemainingGauge,
+		"apiGraphQLUsedRatioGauge": exp.apiGraphQLUsedRatioGauge,
+		"apiGraphQLResetSecsGauge": exp.apiGraphQLResetSecsGauge,
+		"apiGraphQLCostCounter":    exp.apiGraphQLCostCounter,
+		"apiGraphQLBatchGauge":     exp.apiGraphQLBatchGauge,
 	}
 	for name, inst := range instruments {
 		if inst == nil {
@@ -431,6 +512,21 @@ func TestExporter_RecordRateLimit_HappyPath(t *testing.T) {
 	exp.RecordRateLimit(context.Background(), RateLimitSnapshot{})
 }
 
+func TestExporter_RecordTokenRateLimit(t *testing.T) {
+	exp, err := NewExporter(ExporterConfig{ServiceName: "t", DryRun: true})
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	defer exp.ShutdownWithTimeout()
+
+	exp.RecordTokenRateLimit(context.Background(), TokenRateLimitSnapshot{
+		TokenID:   "tok_0a1b2c3d",
+		Resource:  "graphql",
+		Limit:     5000,
+		Remaining: 1200,
+	})
+}
+
 func TestExporter_RecordAPICall_Counter(t *testing.T) {
 	exp, err := NewExporter(ExporterConfig{ServiceName: "t", DryRun: true})
 	if err != nil {
@@ -444,6 +540,32 @@ func TestExporter_RecordAPICall_Counter(t *testing.T) {
 	exp.RecordAPICall(ctx, "repo", "rate_limited")
 }
 
+func TestExporter_RecordHTTPCache(t *testing.T) {
+	exp, err := NewExporter(ExporterConfig{ServiceName: "t", DryRun: true})
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	defer exp.ShutdownWithTimeout()
+
+	ctx := context.Background()
+	exp.RecordHTTPCache(ctx, "pulls", "revalidated")
+	exp.RecordHTTPCache(ctx, "releases", "hit")
+	exp.RecordHTTPCacheSize(ctx, 42, 1<<20)
+}
+
+func TestExporter_RecordGraphQLBudget(t *testing.T) {
+	exp, err := NewExporter(ExporterConfig{ServiceName: "t", DryRun: true})
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	defer exp.ShutdownWithTimeout()
+
+	ctx := context.Background()
+	exp.RecordGraphQLRateLimit(ctx, RateLimitSnapshot{Limit: 5000, Remaining: 4200, ResetAt: time.Now().Add(time.Hour)})
+	exp.RecordGraphQLRateLimit(ctx, RateLimitSnapshot{}) // no data yet
+	exp.RecordGraphQLBatch(ctx, 25, 3)
+}
+
 func TestExporter_RecordRefreshTierHistogram(t *testing.T) {
 	exp, err := NewExporter(ExporterConfig{ServiceName: "t", DryRun: true})
 	if err != nil {
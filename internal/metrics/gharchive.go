@@ -1,31 +1,19 @@
 package metrics
 
 import (
-	"compress/gzip"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hrexed/github-radar/internal/cassette"
+	"github.com/hrexed/github-radar/internal/gharchive"
 	"github.com/hrexed/github-radar/internal/logging"
 )
 
-type ghArchiveEvent struct {
-	Type string `json:"type"`
-	Repo struct {
-		Name string `json:"name"`
-	} `json:"repo"`
-	Payload json.RawMessage `json:"payload"`
-	Action  string          `json:"action"`
-	Actor   struct {
-		Login string `json:"login"`
-	} `json:"actor"`
-	CreatedAt string `json:"created_at"`
-}
-
 type releasePayload struct {
 	Release struct {
 		PublishedAt string `json:"published_at"`
@@ -72,10 +60,129 @@ func newRepoAccumulator(owner, name string) *repoAccumulator {
 	}
 }
 
+func (acc *repoAccumulator) apply(evt *gharchive.Event) {
+	switch evt.Type {
+	case "WatchEvent":
+		acc.starEvents++
+		acc.uniqueStarrers[evt.Actor.Login] = true
+
+	case "ForkEvent":
+		acc.forkEvents++
+		acc.uniqueForkers[evt.Actor.Login] = true
+
+	case "PullRequestEvent":
+		var e struct {
+			Payload pullRequestPayload `json:"payload"`
+		}
+		if evt.Unmarshal(&e) == nil {
+			if e.Payload.Action == "closed" && e.Payload.PullRequest.Merged {
+				acc.mergedPRs[e.Payload.Number] = true
+			}
+		}
+
+	case "IssuesEvent":
+		var e struct {
+			Payload issuesPayload `json:"payload"`
+		}
+		if evt.Unmarshal(&e) == nil {
+			if e.Payload.Action == "opened" {
+				acc.openedIssues[e.Payload.Number] = true
+			}
+		}
+
+	case "ReleaseEvent":
+		var e struct {
+			Payload releasePayload `json:"payload"`
+		}
+		if evt.Unmarshal(&e) == nil {
+			pl := e.Payload
+			if pl.Action == "published" && pl.Release.PublishedAt != "" {
+				if t, err := time.Parse(time.RFC3339, pl.Release.PublishedAt); err == nil {
+					acc.releases = append(acc.releases, t)
+				}
+			}
+		}
+	}
+}
+
+// merge folds other (the same repo, another hour) into acc.
+func (acc *repoAccumulator) merge(other *repoAccumulator) {
+	acc.starEvents += other.starEvents
+	acc.forkEvents += other.forkEvents
+	for k := range other.mergedPRs {
+		acc.mergedPRs[k] = true
+	}
+	for k := range other.openedIssues {
+		acc.openedIssues[k] = true
+	}
+	for k := range other.uniqueStarrers {
+		acc.uniqueStarrers[k] = true
+	}
+	for k := range other.uniqueForkers {
+		acc.uniqueForkers[k] = true
+	}
+	acc.releases = append(acc.releases, other.releases...)
+}
+
+// archiveHour is one hour of tracked-repo activity. accs holds an
+// accumulator for every repo that was tracked when the hour was read,
+// including repos without events, so coverage can be checked later.
+type archiveHour struct {
+	accs            map[string]*repoAccumulator
+	kept, discarded int64
+}
+
+func newArchiveHour(tracked map[string]RepoRef) *archiveHour {
+	h := &archiveHour{accs: make(map[string]*repoAccumulator, len(tracked))}
+	for key, r := range tracked {
+		h.accs[key] = newRepoAccumulator(r.Owner, r.Name)
+	}
+	return h
+}
+
+func (a *archiveHour) consume(evt *gharchive.Event) {
+	acc, ok := a.accs[evt.Repo.Name]
+	if !ok {
+		a.discarded++
+		return
+	}
+	a.kept++
+	acc.apply(evt)
+}
+
+// covers reports whether every key was tracked when the hour was read.
+func (a *archiveHour) covers(keys []string) bool {
+	for _, k := range keys {
+		if _, ok := a.accs[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultArchiveRetention is how many hours of tracked-repo activity the
+// collector keeps in memory, enough for a daily fallback window.
+const DefaultArchiveRetention = 24 * time.Hour
+
+// HourlyArchiveCollector computes tracked-repo metric deltas from
+// gharchive hourly archives when the live API budget runs low.
+//
+// It is also a gharchive.Consumer: registered on the discovery
+// ingestion engine, it accumulates tracked-repo activity from the same
+// single decode pass as discovery, so a fallback cycle reuses those
+// hours instead of downloading and decoding them again. Hours the
+// engine has not seen (engine disabled, repo added since) are fetched
+// on demand.
 type HourlyArchiveCollector struct {
 	httpClient *http.Client
 	baseURL    string
 	exporter   *Exporter
+	cache      *gharchive.Cache
+	retain     time.Duration
+
+	mu      sync.Mutex
+	tracked map[string]RepoRef
+	hours   map[time.Time]*archiveHour
 }
 
 func NewHourlyArchiveCollector(baseURL string, timeout time.Duration, exporter *Exporter) *HourlyArchiveCollector {
@@ -86,10 +193,85 @@ func NewHourlyArchiveCollector(baseURL string, timeout time.Duration, exporter *
 		timeout = 60 * time.Second
 	}
 	return &HourlyArchiveCollector{
-		httpClient: &http.Client{Timeout: timeout},
+		httpClient: &http.Client{Timeout: timeout, Transport: cassette.Transport(cassette.ServiceGHArchive)},
 		baseURL:    strings.TrimSuffix(baseURL, "/"),
 		exporter:   exporter,
+		retain:     DefaultArchiveRetention,
+		tracked:    make(map[string]RepoRef),
+		hours:      make(map[time.Time]*archiveHour),
+	}
+}
+
+// SetArchiveCache routes archive downloads through c, the on-disk
+// archive cache shared with gharchive discovery. nil disables caching.
+func (h *HourlyArchiveCollector) SetArchiveCache(c *gharchive.Cache) {
+	h.cache = c
+}
+
+// Track replaces the set of repos accumulated from engine-fed hours.
+// The router calls it on every collection cycle.
+func (h *HourlyArchiveCollector) Track(repos []RepoRef) {
+	tracked := make(map[string]RepoRef, len(repos))
+	for _, r := range repos {
+		tracked[r.Owner+"/"+r.Name] = r
+	}
+	h.mu.Lock()
+	h.tracked = tracked
+	h.mu.Unlock()
+}
+
+// BeginHour implements gharchive.Consumer.
+func (h *HourlyArchiveCollector) BeginHour(time.Time) gharchive.HourConsumer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &engineHour{collector: h, hour: newArchiveHour(h.tracked)}
+}
+
+// engineHour is one engine-fed hour being accumulated for the tracked
+// repos.
+type engineHour struct {
+	collector *HourlyArchiveCollector
+	hour      *archiveHour
+}
+
+// ConsumeEvent implements gharchive.HourConsumer.
+func (e *engineHour) ConsumeEvent(evt *gharchive.Event) {
+	e.hour.consume(evt)
+}
+
+// FinishHour implements gharchive.HourConsumer.
+func (e *engineHour) FinishHour(ctx context.Context, sum gharchive.HourSummary) error {
+	h := e.collector
+	h.storeHour(sum.Hour, e.hour)
+	if h.exporter != nil {
+		h.exporter.RecordGHArchiveEventsFiltered(ctx, true, e.hour.kept)
+		h.exporter.RecordGHArchiveEventsFiltered(ctx, false, e.hour.discarded+sum.Malformed)
+	}
+	return nil
+}
+
+// storeHour keeps hour and drops hours older than the retention.
+func (h *HourlyArchiveCollector) storeHour(t time.Time, hour *archiveHour) {
+	t = t.UTC().Truncate(time.Hour)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hours[t] = hour
+	for ht := range h.hours {
+		if t.Sub(ht) >= h.retain {
+			delete(h.hours, ht)
+		}
+	}
+}
+
+// storedHour returns the hour at t when it covers keys.
+func (h *HourlyArchiveCollector) storedHour(t time.Time, keys []string) *archiveHour {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hour := h.hours[t]
+	if hour == nil || !hour.covers(keys) {
+		return nil
 	}
+	return hour
 }
 
 func (h *HourlyArchiveCollector) Collect(ctx context.Context, repos []RepoRef, window time.Duration) ([]CollectedMetrics, error) {
@@ -98,9 +280,13 @@ func (h *HourlyArchiveCollector) Collect(ctx context.Context, repos []RepoRef, w
 	}
 
 	accumulators := make(map[string]*repoAccumulator, len(repos))
+	requested := make(map[string]RepoRef, len(repos))
+	keys := make([]string, 0, len(repos))
 	for _, r := range repos {
 		key := r.Owner + "/" + r.Name
 		accumulators[key] = newRepoAccumulator(r.Owner, r.Name)
+		requested[key] = r
+		keys = append(keys, key)
 	}
 
 	now := time.Now().UTC()
@@ -108,35 +294,27 @@ func (h *HourlyArchiveCollector) Collect(ctx context.Context, repos []RepoRef, w
 	if hours < 1 {
 		hours = 1
 	}
+	if window > h.retain {
+		h.mu.Lock()
+		h.retain = window
+		h.mu.Unlock()
+	}
 
 	totalBytes := int64(0)
 	for offset := 0; offset < hours; offset++ {
-		hourTime := now.Add(-time.Duration(offset) * time.Hour)
-		url := fmt.Sprintf("%s/%s.json.gz", h.baseURL, hourTime.Format("2006-01-02-15"))
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("creating request for %s: %w", url, err)
-		}
-
-		resp, err := h.httpClient.Do(req)
-		if err != nil {
-			logging.Warn("gharchive: failed to fetch hour", "url", url, "error", err)
-			continue
+		hourTime := now.Add(-time.Duration(offset) * time.Hour).Truncate(time.Hour)
+
+		hour := h.storedHour(hourTime, keys)
+		if hour == nil {
+			var bytesRead int64
+			hour, bytesRead = h.fetchHour(ctx, hourTime, requested)
+			totalBytes += bytesRead
+			if hour == nil {
+				continue
+			}
 		}
-
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			logging.Warn("gharchive: non-200 status", "url", url, "status", resp.StatusCode)
-			continue
-		}
-
-		bytesRead, err := h.processArchive(ctx, resp.Body, accumulators)
-		resp.Body.Close()
-		totalBytes += bytesRead
-
-		if err != nil {
-			logging.Warn("gharchive: error processing hour", "url", url, "error", err)
+		for key, acc := range accumulators {
+			acc.merge(hour.accs[key])
 		}
 	}
 
@@ -172,90 +350,71 @@ func (h *HourlyArchiveCollector) Collect(ctx context.Context, repos []RepoRef, w
 	return results, nil
 }
 
-type countingReader struct {
-	reader io.Reader
-	n      int64
-}
+// fetchHour downloads and decodes one hour for the requested repos and
+// keeps it for later cycles. It returns the network bytes read (zero on
+// a cache hit) and nil when the hour is unavailable.
+func (h *HourlyArchiveCollector) fetchHour(ctx context.Context, hourTime time.Time, requested map[string]RepoRef) (*archiveHour, int64) {
+	archive := gharchive.ArchiveName(hourTime)
+	url := fmt.Sprintf("%s/%s.json.gz", h.baseURL, archive)
 
-func (cr *countingReader) Read(p []byte) (int, error) {
-	n, err := cr.reader.Read(p)
-	cr.n += int64(n)
-	return n, err
-}
+	body, cached, err := h.cache.Open(ctx, archive, func(ctx context.Context) (io.ReadCloser, error) {
+		return h.fetch(ctx, url)
+	})
+	if err != nil {
+		logging.Warn("gharchive: failed to fetch hour", "url", url, "error", err)
+		return nil, 0
+	}
+	defer body.Close()
 
-func (h *HourlyArchiveCollector) processArchive(ctx context.Context, body io.Reader, accs map[string]*repoAccumulator) (int64, error) {
+	hour := newArchiveHour(requested)
 	cr := &countingReader{reader: body}
-
 	start := time.Now()
-	gz, err := gzip.NewReader(cr)
+	stats, err := gharchive.Decode(ctx, cr, hour.consume)
+
+	var bytesRead int64
+	// Archives served from the cache cost no download.
+	if !cached {
+		bytesRead = cr.n
+	}
+	if h.exporter != nil {
+		h.exporter.RecordGHArchiveDecodeDuration(ctx, time.Since(start))
+		h.exporter.RecordGHArchiveEventsFiltered(ctx, true, hour.kept)
+		h.exporter.RecordGHArchiveEventsFiltered(ctx, false, hour.discarded+stats.Malformed)
+	}
 	if err != nil {
-		return cr.n, fmt.Errorf("gzip reader: %w", err)
+		logging.Warn("gharchive: error processing hour", "url", url, "error", err)
+		return nil, bytesRead
 	}
-	defer gz.Close()
-
-	var kept, discarded int64
-	dec := json.NewDecoder(gz)
-
-	for {
-		var evt ghArchiveEvent
-		if err := dec.Decode(&evt); err != nil {
-			if err == io.EOF {
-				break
-			}
-			continue
-		}
-
-		acc, ok := accs[evt.Repo.Name]
-		if !ok {
-			discarded++
-			continue
-		}
-
-		kept++
-
-		switch evt.Type {
-		case "WatchEvent":
-			acc.starEvents++
-			acc.uniqueStarrers[evt.Actor.Login] = true
-
-		case "ForkEvent":
-			acc.forkEvents++
-			acc.uniqueForkers[evt.Actor.Login] = true
-
-		case "PullRequestEvent":
-			var pl pullRequestPayload
-			if json.Unmarshal(evt.Payload, &pl) == nil {
-				if pl.Action == "closed" && pl.PullRequest.Merged {
-					acc.mergedPRs[pl.Number] = true
-				}
-			}
+	h.storeHour(hourTime, hour)
+	return hour, bytesRead
+}
 
-		case "IssuesEvent":
-			var pl issuesPayload
-			if json.Unmarshal(evt.Payload, &pl) == nil {
-				if pl.Action == "opened" {
-					acc.openedIssues[pl.Number] = true
-				}
-			}
+// fetch downloads one hourly archive from the origin.
+func (h *HourlyArchiveCollector) fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request for %s: %w", url, err)
+	}
 
-		case "ReleaseEvent":
-			var pl releasePayload
-			if json.Unmarshal(evt.Payload, &pl) == nil {
-				if pl.Action == "published" && pl.Release.PublishedAt != "" {
-					if t, err := time.Parse(time.RFC3339, pl.Release.PublishedAt); err == nil {
-						acc.releases = append(acc.releases, t)
-					}
-				}
-			}
-		}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
 
-	if h.exporter != nil {
-		decodeDur := time.Since(start)
-		h.exporter.RecordGHArchiveDecodeDuration(ctx, decodeDur)
-		h.exporter.RecordGHArchiveEventsFiltered(ctx, true, kept)
-		h.exporter.RecordGHArchiveEventsFiltered(ctx, false, discarded)
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("non-200 status %d", resp.StatusCode)
 	}
+	return resp.Body, nil
+}
 
-	return cr.n, nil
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.n += int64(n)
+	return n, err
 }
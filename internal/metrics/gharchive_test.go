@@ -10,8 +10,24 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/hrexed/github-radar/internal/gharchive"
 )
 
+// ghArchiveEvent is the archive line shape the fixtures encode.
+type ghArchiveEvent struct {
+	Type string `json:"type"`
+	Repo struct {
+		Name string `json:"name"`
+	} `json:"repo"`
+	Payload json.RawMessage `json:"payload"`
+	Action  string          `json:"action"`
+	Actor   struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+	CreatedAt string `json:"created_at"`
+}
+
 func makeGZippedArchive(events []ghArchiveEvent) ([]byte, error) {
 	var buf bytes.Buffer
 	gz := gzip.NewWriter(&buf)
@@ -293,3 +309,108 @@ func TestNewHourlyArchiveCollector_Defaults(t *testing.T) {
 		t.Errorf("Timeout = %v, want 60s", c.httpClient.Timeout)
 	}
 }
+
+func TestHourlyArchiveCollector_ArchiveCache(t *testing.T) {
+	archive, err := makeGZippedArchive([]ghArchiveEvent{
+		makeTestEvent("WatchEvent", "kubernetes/kubernetes", "user1"),
+	})
+	if err != nil {
+		t.Fatalf("makeGZippedArchive: %v", err)
+	}
+
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(archive)
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	cache, err := gharchive.NewCache(gharchive.CacheConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	collector := NewHourlyArchiveCollector(ts.URL, 10*time.Second, nil)
+	collector.SetArchiveCache(cache)
+
+	repos := []RepoRef{{Owner: "kubernetes", Name: "kubernetes"}}
+	if _, err := collector.Collect(context.Background(), repos, time.Hour); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	// Offline over the same directory: served from disk, no requests.
+	offline, err := gharchive.NewCache(gharchive.CacheConfig{Dir: dir, Offline: true})
+	if err != nil {
+		t.Fatalf("NewCache offline: %v", err)
+	}
+	collector.SetArchiveCache(offline)
+	results, err := collector.Collect(context.Background(), repos, time.Hour)
+	if err != nil {
+		t.Fatalf("Collect offline: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("origin requests = %d, want 1", requests)
+	}
+	if results[0].Stars != 1 {
+		t.Errorf("Stars = %d, want 1", results[0].Stars)
+	}
+}
+
+// memCursor is an in-memory gharchive.CursorStore.
+type memCursor struct{ c gharchive.Cursor }
+
+func (m *memCursor) GetCursor(context.Context) (gharchive.Cursor, error) { return m.c, nil }
+func (m *memCursor) SetCursor(_ context.Context, c gharchive.Cursor) error {
+	m.c = c
+	return nil
+}
+
+func TestHourlyArchiveCollector_ReusesEngineHours(t *testing.T) {
+	archive, err := makeGZippedArchive([]ghArchiveEvent{
+		makeTestEvent("WatchEvent", "kubernetes/kubernetes", "user1"),
+		makeTestEvent("WatchEvent", "other/repo", "user2"),
+	})
+	if err != nil {
+		t.Fatalf("makeGZippedArchive: %v", err)
+	}
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(archive)
+	}))
+	defer ts.Close()
+
+	repos := []RepoRef{{Owner: "kubernetes", Name: "kubernetes"}}
+	collector := NewHourlyArchiveCollector(ts.URL, 10*time.Second, nil)
+	collector.Track(repos)
+
+	engine := gharchive.NewEngine(gharchive.EngineConfig{BaseURL: ts.URL}, &memCursor{}, gharchive.Hooks{})
+	engine.Register(collector)
+	if err := engine.ProcessArchive(context.Background(), gharchive.ArchiveName(time.Now())); err != nil {
+		t.Fatalf("ProcessArchive: %v", err)
+	}
+
+	results, err := collector.Collect(context.Background(), repos, time.Hour)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("origin requests = %d, want 1 (hour reused from the engine)", requests)
+	}
+	if results[0].Stars != 1 {
+		t.Errorf("Stars = %d, want 1", results[0].Stars)
+	}
+
+	// A repo not tracked when the hour was ingested forces a fetch.
+	wider := append(repos, RepoRef{Owner: "other", Name: "repo"})
+	results, err = collector.Collect(context.Background(), wider, time.Hour)
+	if err != nil {
+		t.Fatalf("Collect wider: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("origin requests = %d, want 2", requests)
+	}
+	if results[1].Stars != 1 {
+		t.Errorf("other/repo Stars = %d, want 1", results[1].Stars)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hrexed/github-radar/internal/gharchive"
 	"github.com/hrexed/github-radar/internal/github"
 	"github.com/hrexed/github-radar/internal/logging"
 	"github.com/hrexed/github-radar/internal/scoring"
@@ -22,9 +23,12 @@ type Router struct {
 }
 
 type RouterConfig struct {
-	GHArchiveEnabled     bool
-	GHArchiveBaseURL     string
-	GHArchiveTimeout     time.Duration
+	GHArchiveEnabled bool
+	GHArchiveBaseURL string
+	GHArchiveTimeout time.Duration
+	// GHArchiveCache is the on-disk archive cache shared with gharchive
+	// discovery; nil downloads every hour on every fallback.
+	GHArchiveCache       *gharchive.Cache
 	FallbackThresholdPct float64
 }
 
@@ -53,6 +57,7 @@ func NewRouter(client *github.Client, store *state.Store, weights scoring.Weight
 
 	if r.enabled {
 		r.gharchive = NewHourlyArchiveCollector(cfg.GHArchiveBaseURL, cfg.GHArchiveTimeout, exporter)
+		r.gharchive.SetArchiveCache(cfg.GHArchiveCache)
 		logging.Info("collector router: gharchive fallback enabled",
 			"threshold_pct", r.threshold,
 			"base_url", cfg.GHArchiveBaseURL)
@@ -104,6 +109,21 @@ func (r *Router) Collect(ctx context.Context, repos []RepoRef, window time.Durat
 	return r.live.Collect(ctx, repos, window)
 }
 
+// ArchiveCollector returns the gharchive fallback collector, or nil when
+// the fallback is disabled. The daemon registers it on the gharchive
+// discovery engine so both share one decode pass per archive.
+func (r *Router) ArchiveCollector() *HourlyArchiveCollector {
+	return r.gharchive
+}
+
+// TrackRepos tells the gharchive fallback which repos to accumulate from
+// archives ingested between fallback cycles. No-op when disabled.
+func (r *Router) TrackRepos(repos []RepoRef) {
+	if r.gharchive != nil {
+		r.gharchive.Track(repos)
+	}
+}
+
 func (r *Router) LiveCollector() *LiveAPICollector {
 	return r.live
 }
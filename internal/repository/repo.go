@@ -7,22 +7,122 @@ import (
 	"strings"
 )
 
-// Repo represents a GitHub repository.
+// DefaultHost is the host of repositories named without one.
+const DefaultHost = "github.com"
+
+// GitHubProvider is the provider name of GitHub repositories, which are
+// keyed without it.
+const GitHubProvider = "github"
+
+// Repo represents a repository. Host is empty for github.com and names
+// a GitHub Enterprise Server otherwise. Provider is empty for GitHub and
+// names a configured GitLab or Gitea/Forgejo forge otherwise; on those
+// Owner is the full namespace path (GitLab groups nest).
 type Repo struct {
-	Owner string
-	Name  string
+	Provider string
+	Host     string
+	Owner    string
+	Name     string
 }
 
-// FullName returns the repository in owner/repo format.
+// FullName returns the repository in owner/repo format, prefixed with
+// its host when that is not github.com (ghe.corp/owner/repo), or with
+// its provider on another forge (gitlab:owner/repo).
 func (r Repo) FullName() string {
-	return r.Owner + "/" + r.Name
+	if r.Provider != "" {
+		return JoinForgeName(r.Provider, r.Owner, r.Name)
+	}
+	return JoinFullName(r.Host, r.Owner, r.Name)
 }
 
-// String returns the repository in owner/repo format.
+// String returns the repository's FullName.
 func (r Repo) String() string {
 	return r.FullName()
 }
 
+// HostName returns the repository's host, DefaultHost when unset.
+func (r Repo) HostName() string {
+	if r.Host == "" {
+		return DefaultHost
+	}
+	return r.Host
+}
+
+// JoinFullName builds the key a repository is stored under: owner/repo
+// on github.com, host/owner/repo on any other host.
+func JoinFullName(host, owner, name string) string {
+	if host == "" || strings.EqualFold(host, DefaultHost) {
+		return owner + "/" + name
+	}
+	return host + "/" + owner + "/" + name
+}
+
+// SplitFullName is the inverse of JoinFullName. host is empty for
+// github.com names; ok is false unless fullName has two or three
+// non-empty segments, and for provider-qualified names (see ParseKey).
+func SplitFullName(fullName string) (host, owner, name string, ok bool) {
+	if provider, _ := SplitProvider(fullName); provider != "" {
+		return "", "", "", false
+	}
+	parts := strings.Split(fullName, "/")
+	switch {
+	case len(parts) == 2:
+		owner, name = parts[0], parts[1]
+	case len(parts) == 3:
+		host, owner, name = parts[0], parts[1], parts[2]
+		if host == "" {
+			return "", "", "", false
+		}
+		if strings.EqualFold(host, DefaultHost) {
+			host = ""
+		}
+	default:
+		return "", "", "", false
+	}
+	if owner == "" || name == "" {
+		return "", "", "", false
+	}
+	return host, owner, name, true
+}
+
+// JoinForgeName builds the key a repository on a non-GitHub forge is
+// stored under: provider:owner/repo.
+func JoinForgeName(provider, owner, name string) string {
+	return provider + ":" + owner + "/" + name
+}
+
+// SplitProvider splits a provider-qualified key into the provider and
+// the owner/repo path. provider is empty for GitHub keys, which carry no
+// colon before their first slash, and for URLs (https://...).
+func SplitProvider(key string) (provider, rest string) {
+	prefix, rest, ok := strings.Cut(key, ":")
+	if !ok || prefix == "" || strings.Contains(prefix, "/") || strings.HasPrefix(rest, "/") {
+		return "", key
+	}
+	return prefix, rest
+}
+
+// ParseKey parses a state or database key of any form: owner/repo,
+// host/owner/repo or provider:namespace/repo. The "github" provider
+// names GitHub itself, so github:owner/repo parses as owner/repo. ok is
+// false for malformed keys.
+func ParseKey(key string) (Repo, bool) {
+	if provider, rest := SplitProvider(key); strings.EqualFold(provider, GitHubProvider) {
+		key = rest
+	} else if provider != "" {
+		i := strings.LastIndex(rest, "/")
+		if i <= 0 || i == len(rest)-1 || strings.Contains(rest, "//") {
+			return Repo{}, false
+		}
+		return Repo{Provider: provider, Owner: rest[:i], Name: rest[i+1:]}, true
+	}
+	host, owner, name, ok := SplitFullName(key)
+	if !ok {
+		return Repo{}, false
+	}
+	return Repo{Host: host, Owner: owner, Name: name}, true
+}
+
 // ParseError represents a repository parsing error.
 type ParseError struct {
 	Input string
@@ -34,7 +134,9 @@ func (e *ParseError) Error() string {
 Expected formats:
   - owner/repo
   - https://github.com/owner/repo
-  - github.com/owner/repo`, e.Input)
+  - github.com/owner/repo
+  - ghe.example.com/owner/repo (GitHub Enterprise Server)
+  - gitlab:group/repo (a forge configured under forges)`, e.Input)
 }
 
 // Parse parses a repository identifier in various formats.
@@ -45,21 +147,50 @@ Expected formats:
 //   - github.com/owner/repo
 //   - https://github.com/owner/repo.git
 //   - https://github.com/owner/repo/
+//   - ghe.example.com/owner/repo (host-qualified, for GitHub Enterprise
+//     Server; the host must contain a dot)
+//   - gitlab:group/subgroup/repo (provider-qualified, for a GitLab or
+//     Gitea/Forgejo forge configured under forges)
 func Parse(input string) (Repo, error) {
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return Repo{}, &ParseError{Input: input}
 	}
 
+	// Provider-qualified name: provider:namespace/repo.
+	if provider, _ := SplitProvider(input); provider != "" && !strings.Contains(provider, ".") {
+		repo, ok := ParseKey(strings.TrimSuffix(input, "/"))
+		if !ok || strings.ContainsAny(input, " \t\n") {
+			return Repo{}, &ParseError{Input: input}
+		}
+		return repo, nil
+	}
+
 	// Try to parse as URL first
 	if strings.Contains(input, "github.com") {
 		return parseGitHubURL(input)
 	}
 
+	// Host-qualified name: host/owner/repo.
+	if host, rest, ok := strings.Cut(strings.Trim(input, "/"), "/"); ok && strings.Count(rest, "/") == 1 && isHostLike(host) {
+		repo, err := parseOwnerRepo(rest)
+		if err != nil {
+			return Repo{}, &ParseError{Input: input}
+		}
+		repo.Host = host
+		return repo, nil
+	}
+
 	// Try simple owner/repo format
 	return parseOwnerRepo(input)
 }
 
+// isHostLike reports whether s looks like a hostname rather than a
+// GitHub owner, which cannot contain dots.
+func isHostLike(s string) bool {
+	return strings.Contains(s, ".") && !strings.ContainsAny(s, " \t\n")
+}
+
 // parseGitHubURL parses a GitHub URL in various formats.
 func parseGitHubURL(input string) (Repo, error) {
 	// Add scheme if missing
@@ -165,6 +165,55 @@ func TestRepo_FullName(t *testing.T) {
 	}
 }
 
+func TestParse_HostQualified(t *testing.T) {
+	repo, err := Parse("ghe.corp.example/platform/deployer")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	want := Repo{Host: "ghe.corp.example", Owner: "platform", Name: "deployer"}
+	if repo != want {
+		t.Errorf("Parse() = %+v, want %+v", repo, want)
+	}
+	if repo.FullName() != "ghe.corp.example/platform/deployer" {
+		t.Errorf("FullName() = %q", repo.FullName())
+	}
+	if repo.HostName() != "ghe.corp.example" {
+		t.Errorf("HostName() = %q", repo.HostName())
+	}
+}
+
+func TestSplitJoinFullName(t *testing.T) {
+	tests := []struct {
+		input             string
+		host, owner, name string
+		ok                bool
+		joined            string
+	}{
+		{input: "owner/repo", owner: "owner", name: "repo", ok: true, joined: "owner/repo"},
+		{input: "ghe.corp/owner/repo", host: "ghe.corp", owner: "owner", name: "repo", ok: true, joined: "ghe.corp/owner/repo"},
+		{input: "github.com/owner/repo", owner: "owner", name: "repo", ok: true, joined: "owner/repo"},
+		{input: "noslash"},
+		{input: "/owner/repo"},
+		{input: "ghe.corp/owner/"},
+		{input: "a/b/c/d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			host, owner, name, ok := SplitFullName(tt.input)
+			if host != tt.host || owner != tt.owner || name != tt.name || ok != tt.ok {
+				t.Fatalf("SplitFullName(%q) = (%q, %q, %q, %v), want (%q, %q, %q, %v)",
+					tt.input, host, owner, name, ok, tt.host, tt.owner, tt.name, tt.ok)
+			}
+			if ok {
+				if got := JoinFullName(host, owner, name); got != tt.joined {
+					t.Errorf("JoinFullName() = %q, want %q", got, tt.joined)
+				}
+			}
+		})
+	}
+}
+
 func TestRepo_String(t *testing.T) {
 	repo := Repo{Owner: "prometheus", Name: "prometheus"}
 	if repo.String() != "prometheus/prometheus" {
@@ -240,3 +289,50 @@ func TestParse_AllValidFormats(t *testing.T) {
 		})
 	}
 }
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Repo
+		ok   bool
+	}{
+		{key: "owner/repo", want: Repo{Owner: "owner", Name: "repo"}, ok: true},
+		{key: "ghe.corp/owner/repo", want: Repo{Host: "ghe.corp", Owner: "owner", Name: "repo"}, ok: true},
+		{key: "gitlab:inkscape/inkscape", want: Repo{Provider: "gitlab", Owner: "inkscape", Name: "inkscape"}, ok: true},
+		{key: "gitlab:gnome/world/podcasts", want: Repo{Provider: "gitlab", Owner: "gnome/world", Name: "podcasts"}, ok: true},
+		{key: "codeberg:forgejo/forgejo", want: Repo{Provider: "codeberg", Owner: "forgejo", Name: "forgejo"}, ok: true},
+		{key: "gitlab:noslash"},
+		{key: "github:owner/repo", want: Repo{Owner: "owner", Name: "repo"}, ok: true},
+		{key: "gitlab:owner/"},
+		{key: "gitlab:a//b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := ParseKey(tt.key)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("ParseKey(%q) = (%+v, %v), want (%+v, %v)", tt.key, got, ok, tt.want, tt.ok)
+			}
+			if ok && tt.want.Provider != "" && got.FullName() != tt.key {
+				t.Errorf("FullName() = %q, want %q", got.FullName(), tt.key)
+			}
+		})
+	}
+
+	if _, _, _, ok := SplitFullName("gitlab:group/sub/repo"); ok {
+		t.Error("SplitFullName() accepted a provider-qualified key")
+	}
+}
+
+func TestParse_ProviderQualified(t *testing.T) {
+	repo, err := Parse("gitlab:gnome/world/podcasts")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	want := Repo{Provider: "gitlab", Owner: "gnome/world", Name: "podcasts"}
+	if repo != want {
+		t.Errorf("Parse() = %+v, want %+v", repo, want)
+	}
+	if _, err := Parse("gitlab:noslash"); err == nil {
+		t.Error("Parse() accepted a provider key without a namespace")
+	}
+}
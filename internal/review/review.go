@@ -0,0 +1,192 @@
+// Package review implements the human review queue for classifications
+// the pipeline was unsure about: repos left in needs_review by a low
+// confidence or a split ensemble vote. Decisions are pinned as
+// force_category / force_subcategory with the reviewer and a timestamp.
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+// ErrNotQueued is returned for a repo that is not awaiting review.
+var ErrNotQueued = errors.New("repo is not in the review queue")
+
+// Item is one repo awaiting review: the classifier's answer, resolved to
+// a v3 (category, subcategory) pair, and what a reviewer needs to judge
+// it. Description and Readme are only filled by Queue.Item.
+type Item struct {
+	FullName    string  `json:"full_name"`
+	Category    string  `json:"category"`
+	Subcategory string  `json:"subcategory"`
+	Confidence  float64 `json:"confidence"`
+	Model       string  `json:"model"`
+	Reasoning   string  `json:"reasoning,omitempty"`
+	Agreement   float64 `json:"agreement,omitempty"`
+	Votes       []Vote  `json:"votes,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Readme      string  `json:"readme,omitempty"`
+}
+
+// Vote is one ensemble member's answer.
+type Vote struct {
+	Model      string  `json:"model"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning,omitempty"`
+}
+
+// Decision is a recorded review.
+type Decision struct {
+	FullName    string `json:"full_name"`
+	Action      string `json:"action"`
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+	Reviewer    string `json:"reviewer"`
+	ReviewedAt  string `json:"reviewed_at"`
+}
+
+// Describer live-fetches the description and README excerpt of a repo.
+// classification.Pipeline implements it.
+type Describer interface {
+	Describe(ctx context.Context, fullName string) (description, readme string, err error)
+}
+
+// Queue reads the review queue from the database and records decisions.
+type Queue struct {
+	db        *database.DB
+	describer Describer
+}
+
+// NewQueue creates a review queue over db. describer may be nil, in
+// which case items carry no description or README.
+func NewQueue(db *database.DB, describer Describer) *Queue {
+	return &Queue{db: db, describer: describer}
+}
+
+// Pending returns up to limit queued repos, least confident first,
+// without their description or README. limit <= 0 returns them all.
+func (q *Queue) Pending(limit int) ([]Item, error) {
+	repos, err := q.db.ReviewQueue(limit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, len(repos))
+	for _, r := range repos {
+		item, err := q.item(r)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+// Item returns the queued repo fullName with its live description and
+// README excerpt. A failed fetch is not an error: the item is returned
+// without them.
+func (q *Queue) Item(ctx context.Context, fullName string) (*Item, error) {
+	r, err := q.queued(fullName)
+	if err != nil {
+		return nil, err
+	}
+	item, err := q.item(*r)
+	if err != nil {
+		return nil, err
+	}
+	if q.describer != nil {
+		if desc, readme, err := q.describer.Describe(ctx, fullName); err == nil {
+			item.Description, item.Readme = desc, readme
+		}
+	}
+	return item, nil
+}
+
+// Accept confirms the classifier's answer for a queued repo.
+func (q *Queue) Accept(fullName, reviewer string) (*Decision, error) {
+	r, err := q.queued(fullName)
+	if err != nil {
+		return nil, err
+	}
+	category, subcategory, _ := r.ResolveTaxonomy()
+	return q.record(database.Review{
+		FullName: fullName, Action: database.ReviewAccept,
+		Category: category, Subcategory: subcategory, Reviewer: reviewer,
+	})
+}
+
+// Override replaces the classifier's answer for a queued repo with
+// (category, subcategory), which must satisfy database.IsAllowedPair.
+func (q *Queue) Override(fullName, category, subcategory, reviewer string) (*Decision, error) {
+	if _, err := q.queued(fullName); err != nil {
+		return nil, err
+	}
+	return q.record(database.Review{
+		FullName: fullName, Action: database.ReviewOverride,
+		Category: category, Subcategory: subcategory, Reviewer: reviewer,
+	})
+}
+
+// queued returns fullName's record if it is awaiting review.
+func (q *Queue) queued(fullName string) (*database.RepoRecord, error) {
+	r, err := q.db.GetRepo(fullName)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.Status != "needs_review" || r.Excluded != 0 || r.ForceCategory != "" {
+		return nil, fmt.Errorf("%s: %w", fullName, ErrNotQueued)
+	}
+	return r, nil
+}
+
+// item builds the queue entry for r from the stored classification.
+func (q *Queue) item(r database.RepoRecord) (*Item, error) {
+	category, subcategory, _ := r.ResolveTaxonomy()
+	reasoning, err := q.db.ClassificationReasoning(r.FullName)
+	if err != nil {
+		return nil, err
+	}
+	agreement, votes, err := q.db.EnsembleVotes(r.FullName)
+	if err != nil {
+		return nil, err
+	}
+	item := &Item{
+		FullName:    r.FullName,
+		Category:    category,
+		Subcategory: subcategory,
+		Confidence:  r.CategoryConfidence,
+		Model:       r.ModelUsed,
+		Reasoning:   reasoning,
+		Agreement:   agreement,
+	}
+	for _, v := range votes {
+		item.Votes = append(item.Votes, Vote{Model: v.Model, Category: v.Category, Confidence: v.Confidence, Reasoning: v.Reasoning})
+	}
+	return item, nil
+}
+
+// record writes r and returns it as stored.
+func (q *Queue) record(r database.Review) (*Decision, error) {
+	if r.Reviewer == "" {
+		return nil, errors.New("reviewer is required")
+	}
+	if err := q.db.RecordReview(r); err != nil {
+		return nil, err
+	}
+	history, err := q.db.Reviews(r.FullName)
+	if err != nil {
+		return nil, err
+	}
+	last := history[len(history)-1]
+	return &Decision{
+		FullName:    last.FullName,
+		Action:      last.Action,
+		Category:    last.Category,
+		Subcategory: last.Subcategory,
+		Reviewer:    last.Reviewer,
+		ReviewedAt:  last.ReviewedAt,
+	}, nil
+}
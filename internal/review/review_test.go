@@ -0,0 +1,128 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/hrexed/github-radar/internal/database"
+)
+
+type stubDescriber struct{ calls int }
+
+func (s *stubDescriber) Describe(_ context.Context, fullName string) (string, string, error) {
+	s.calls++
+	return "about " + fullName, "# " + fullName, nil
+}
+
+func openQueue(t *testing.T, describer Describer) (*Queue, *database.DB) {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repos := []*database.RepoRecord{
+		{FullName: "a/agent", Owner: "a", Name: "agent", Status: "needs_review", PrimaryCategory: "ai-agents", CategoryConfidence: 0.4, ModelUsed: "m"},
+		{FullName: "a/split", Owner: "a", Name: "split", Status: "needs_review", PrimaryCategory: "kubernetes", CategoryConfidence: 0.3, ModelUsed: "m1+m2"},
+		{FullName: "a/done", Owner: "a", Name: "done", Status: "active", PrimaryCategory: "kubernetes", CategoryConfidence: 0.9},
+	}
+	for _, r := range repos {
+		if err := db.UpsertRepo(r); err != nil {
+			t.Fatalf("UpsertRepo: %v", err)
+		}
+	}
+	if err := db.SetClassificationReasoning("a/agent", "builds agents"); err != nil {
+		t.Fatalf("SetClassificationReasoning: %v", err)
+	}
+	votes := []database.EnsembleVote{
+		{Model: "m1", Category: "kubernetes", Confidence: 0.6, Reasoning: "k8s"},
+		{Model: "m2", Category: "networking", Confidence: 0.5, Reasoning: "cni"},
+	}
+	if err := db.SetEnsembleVotes("a/split", 0.5, votes, 0.6); err != nil {
+		t.Fatalf("SetEnsembleVotes: %v", err)
+	}
+	return NewQueue(db, describer), db
+}
+
+func TestQueue_Pending(t *testing.T) {
+	describer := &stubDescriber{}
+	q, _ := openQueue(t, describer)
+
+	items, err := q.Pending(0)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(items) != 2 || items[0].FullName != "a/split" || items[1].FullName != "a/agent" {
+		t.Fatalf("Pending = %+v, want [a/split a/agent]", items)
+	}
+	split, agent := items[0], items[1]
+	if split.Category != "cloud-native" || split.Subcategory != "kubernetes" || split.Agreement != 0.5 || len(split.Votes) != 2 || split.Votes[1].Category != "networking" {
+		t.Errorf("split item = %+v", split)
+	}
+	if agent.Category != "ai" || agent.Subcategory != "agents" || agent.Reasoning != "builds agents" || agent.Votes != nil {
+		t.Errorf("agent item = %+v", agent)
+	}
+	if describer.calls != 0 {
+		t.Errorf("Pending fetched %d descriptions, want none", describer.calls)
+	}
+}
+
+func TestQueue_Item(t *testing.T) {
+	q, _ := openQueue(t, &stubDescriber{})
+
+	item, err := q.Item(context.Background(), "a/agent")
+	if err != nil {
+		t.Fatalf("Item: %v", err)
+	}
+	if item.Description != "about a/agent" || item.Readme != "# a/agent" {
+		t.Errorf("Item = %+v, want the live description and README", item)
+	}
+	if _, err := q.Item(context.Background(), "a/done"); !errors.Is(err, ErrNotQueued) {
+		t.Errorf("active repo: err = %v, want ErrNotQueued", err)
+	}
+
+	noFetch, _ := openQueue(t, nil)
+	if item, err := noFetch.Item(context.Background(), "a/agent"); err != nil || item.Description != "" {
+		t.Errorf("Item without describer = %+v, %v", item, err)
+	}
+}
+
+func TestQueue_AcceptAndOverride(t *testing.T) {
+	q, db := openQueue(t, nil)
+
+	d, err := q.Accept("a/agent", "alex")
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if d.Action != database.ReviewAccept || d.Category != "ai" || d.Subcategory != "agents" || d.Reviewer != "alex" || d.ReviewedAt == "" {
+		t.Errorf("Accept = %+v, want the resolved classifier pair", d)
+	}
+	if _, err := q.Accept("a/agent", "alex"); !errors.Is(err, ErrNotQueued) {
+		t.Errorf("second review: err = %v, want ErrNotQueued", err)
+	}
+
+	if _, err := q.Override("a/split", "cloud-native", "bogus", "sam"); !errors.Is(err, database.ErrPairNotAllowed) {
+		t.Errorf("bad pair: err = %v, want ErrPairNotAllowed", err)
+	}
+	if _, err := q.Override("a/split", "cloud-native", "networking", ""); err == nil {
+		t.Error("missing reviewer: want an error")
+	}
+	d, err = q.Override("a/split", "cloud-native", "networking", "sam")
+	if err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+	if d.Action != database.ReviewOverride || d.Subcategory != "networking" {
+		t.Errorf("Override = %+v", d)
+	}
+	r, _ := db.GetRepo("a/split")
+	if r.ForceCategory != "cloud-native" || r.ForceSubcategory != "networking" || r.Status != "active" {
+		t.Errorf("repo after override = %+v", r)
+	}
+
+	if items, _ := q.Pending(0); len(items) != 0 {
+		t.Errorf("queue after reviews = %+v, want empty", items)
+	}
+}
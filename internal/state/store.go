@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -56,6 +58,8 @@ type StarObservation struct {
 
 // RepoState contains persisted metrics for a single repository.
 type RepoState struct {
+	Provider         string    `json:"provider,omitempty"` // empty for GitHub
+	Host             string    `json:"host,omitempty"`     // empty for github.com
 	Owner            string    `json:"owner"`
 	Name             string    `json:"name"`
 	Stars            int       `json:"stars"`
@@ -235,6 +239,33 @@ func (s *Store) SetRepoState(fullName string, state RepoState) {
 	s.modified = true
 }
 
+// UpdateRepoState applies update to a tracked repository's state in
+// place. fullName is matched case-insensitively, as GitHub does; the
+// stored key is returned. ok is false, and update is not called, when
+// the repository is not tracked.
+func (s *Store) UpdateRepoState(fullName string, update func(*RepoState)) (key string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key = fullName
+	state, ok := s.state.Repos[key]
+	if !ok {
+		for k, v := range s.state.Repos {
+			if strings.EqualFold(k, fullName) {
+				key, state, ok = k, v, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", false
+	}
+	update(&state)
+	s.state.Repos[key] = state
+	s.modified = true
+	return key, true
+}
+
 // DeleteRepoState removes a repository from state.
 func (s *Store) DeleteRepoState(fullName string) {
 	s.mu.Lock()
@@ -367,3 +398,86 @@ func (s *Store) SetStarObservation(fullName string, obs StarObservation) {
 	s.state.StarObservations[fullName] = obs
 	s.modified = true
 }
+
+// MergeRepo folds everything recorded under alias into canonical and
+// removes the alias entries, so a renamed repo (or a fork collapsed
+// onto its upstream) keeps one history instead of two. Returns true
+// when anything was moved.
+//
+// When both names carry a RepoState, the more recently collected one
+// wins field-by-field and the release histories are unioned (newest
+// first, max 10). Owner/Name are rewritten to the canonical name.
+func (s *Store) MergeRepo(alias, canonical string) bool {
+	if alias == "" || canonical == "" || alias == canonical {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+
+	if from, ok := s.state.Repos[alias]; ok {
+		merged := from
+		if into, ok := s.state.Repos[canonical]; ok {
+			merged = mergeRepoStates(into, from)
+		}
+		if i := strings.IndexByte(canonical, '/'); i > 0 {
+			merged.Owner, merged.Name = canonical[:i], canonical[i+1:]
+		}
+		s.state.Repos[canonical] = merged
+		delete(s.state.Repos, alias)
+		changed = true
+	}
+
+	if obs, ok := s.state.StarObservations[alias]; ok {
+		if cur, ok := s.state.StarObservations[canonical]; !ok || obs.ObservedAt.After(cur.ObservedAt) {
+			s.state.StarObservations[canonical] = obs
+		}
+		delete(s.state.StarObservations, alias)
+		changed = true
+	}
+
+	if s.state.Discovery.KnownRepos[alias] {
+		s.state.Discovery.KnownRepos[canonical] = true
+		delete(s.state.Discovery.KnownRepos, alias)
+		changed = true
+	}
+
+	if changed {
+		s.modified = true
+	}
+	return changed
+}
+
+// mergeRepoStates combines two states for the same repository. The
+// most recently collected state is the base; release history from both
+// is kept.
+func mergeRepoStates(a, b RepoState) RepoState {
+	base, other := a, b
+	if b.LastCollected.After(a.LastCollected) {
+		base, other = b, a
+	}
+
+	if other.LatestReleaseAt.After(base.LatestReleaseAt) {
+		base.LatestReleaseAt = other.LatestReleaseAt
+	}
+
+	seen := make(map[int64]bool, len(base.RecentReleaseDates)+len(other.RecentReleaseDates))
+	dates := make([]time.Time, 0, len(base.RecentReleaseDates)+len(other.RecentReleaseDates))
+	for _, t := range append(append([]time.Time{}, base.RecentReleaseDates...), other.RecentReleaseDates...) {
+		if seen[t.UnixNano()] {
+			continue
+		}
+		seen[t.UnixNano()] = true
+		dates = append(dates, t)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].After(dates[j]) })
+	if len(dates) > 10 {
+		dates = dates[:10]
+	}
+	if len(dates) > 0 {
+		base.RecentReleaseDates = dates
+	}
+	return base
+}
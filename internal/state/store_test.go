@@ -120,6 +120,24 @@ func TestStore_DeleteRepoState(t *testing.T) {
 	}
 }
 
+func TestStore_UpdateRepoState(t *testing.T) {
+	store := NewStore("")
+	store.SetRepoState("Owner/Repo", RepoState{Stars: 100, StarsPrev: 90})
+
+	key, ok := store.UpdateRepoState("owner/repo", func(rs *RepoState) { rs.Stars = 105 })
+	if !ok || key != "Owner/Repo" {
+		t.Fatalf("UpdateRepoState() = %q, %v; want the stored key", key, ok)
+	}
+	if got := store.GetRepoState("Owner/Repo"); got.Stars != 105 || got.StarsPrev != 90 {
+		t.Errorf("state = %+v, want stars 105 and stars_prev untouched", got)
+	}
+
+	called := false
+	if _, ok := store.UpdateRepoState("other/repo", func(*RepoState) { called = true }); ok || called {
+		t.Error("UpdateRepoState() applied an update to an untracked repo")
+	}
+}
+
 func TestStore_AllRepoStates(t *testing.T) {
 	store := NewStore("")
 	store.SetRepoState("repo1", RepoState{Stars: 100})
@@ -324,3 +342,53 @@ func TestStore_LegacyStateWithoutStarObservations(t *testing.T) {
 		t.Error("post-write GetStarObservation() ok=false; want true")
 	}
 }
+
+func TestStore_MergeRepo(t *testing.T) {
+	store := NewStore("")
+	older := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+	r1 := older.Add(-30 * 24 * time.Hour)
+	r2 := older.Add(-10 * 24 * time.Hour)
+
+	store.SetRepoState("old/name", RepoState{
+		Owner: "old", Name: "name", Stars: 90, LastCollected: older,
+		LatestReleaseAt: r2, RecentReleaseDates: []time.Time{r2, r1},
+	})
+	store.SetRepoState("new/name", RepoState{
+		Owner: "new", Name: "name", Stars: 100, LastCollected: newer,
+		RecentReleaseDates: []time.Time{r2},
+	})
+	store.SetStarObservation("old/name", StarObservation{Stars: 90, ObservedAt: older})
+	store.MarkKnownRepo("old/name")
+
+	if !store.MergeRepo("old/name", "new/name") {
+		t.Fatal("MergeRepo() = false, want true")
+	}
+
+	if store.GetRepoState("old/name") != nil {
+		t.Error("alias state not removed")
+	}
+	got := store.GetRepoState("new/name")
+	if got == nil {
+		t.Fatal("canonical state missing")
+	}
+	if got.Stars != 100 || got.Owner != "new" {
+		t.Errorf("merged = stars %d owner %q, want the newer state (100, new)", got.Stars, got.Owner)
+	}
+	if !got.LatestReleaseAt.Equal(r2) || len(got.RecentReleaseDates) != 2 {
+		t.Errorf("release history = %v / %v, want union of both", got.LatestReleaseAt, got.RecentReleaseDates)
+	}
+	if _, ok := store.GetStarObservation("old/name"); ok {
+		t.Error("alias star observation not moved")
+	}
+	if obs, ok := store.GetStarObservation("new/name"); !ok || obs.Stars != 90 {
+		t.Errorf("canonical star observation = %+v, %v", obs, ok)
+	}
+	if store.IsKnownRepo("old/name") || !store.IsKnownRepo("new/name") {
+		t.Error("known-repo marker not moved to canonical")
+	}
+
+	if store.MergeRepo("old/name", "new/name") {
+		t.Error("second MergeRepo() = true, want false (nothing left to move)")
+	}
+}
@@ -16,6 +16,9 @@
 //	GET  /repos/{owner}/{name}/issues                 -> activity (recent issues)
 //	GET  /repos/{owner}/{name}/contributors           -> activity (contributors)
 //	GET  /repos/{owner}/{name}/releases/latest        -> activity (latest release)
+//	GET  /app/installations                           -> GitHub App installations (App mode)
+//	GET  /app/installations/{id}                      -> one installation (App mode)
+//	POST /app/installations/{id}/access_tokens        -> installation token exchange (App mode)
 //
 // All responses carry X-RateLimit-Limit / X-RateLimit-Remaining /
 // X-RateLimit-Reset headers; Remaining decrements per request and resets
@@ -23,6 +26,10 @@
 package ghstub
 
 import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -87,6 +94,28 @@ type Config struct {
 	// header when the secondary-rate-limit injector fires. Defaults to
 	// 60 (seconds).
 	SecondaryRateLimitRetryAfter int
+
+	// AppID, when non-zero, turns on GitHub App mode: the /app endpoints
+	// accept a JWT issued for this App, and every other request must
+	// carry an unexpired installation token minted by the exchange
+	// endpoint, else it gets a 401.
+	AppID int64
+
+	// AppPublicKey, when set, is used to verify the App JWT signature.
+	// Without it only the JWT's claims are checked.
+	AppPublicKey *rsa.PublicKey
+
+	// AppInstallationID is the ID of the App's single installation.
+	// Defaults to 1.
+	AppInstallationID int64
+
+	// AppPermissions are reported by the token exchange. Defaults to
+	// metadata=read, contents=read.
+	AppPermissions map[string]string
+
+	// AppTokenTTL is the lifetime of minted installation tokens,
+	// measured on Now. Defaults to 1h, like GitHub.
+	AppTokenTTL time.Duration
 }
 
 // Stub is a running httptest-backed GitHub API stub.
@@ -103,6 +132,8 @@ type Stub struct {
 	primaryFired   bool
 	secondaryFired bool
 	batchSeen      int
+	appTokens      map[string]time.Time // installation token -> expiry
+	appTokenSeq    int
 
 	// CallCounts is incremented per served request. Mostly useful for
 	// debugging when a scenario fails. Atomic so the harness can read
@@ -112,6 +143,8 @@ type Stub struct {
 	NotModifiedHits atomic.Int64
 	ActivityCalls   atomic.Int64 // pulls + issues + contributors + releases combined
 	RateLimitedHits atomic.Int64 // 403/429 responses served
+	TokenExchanges  atomic.Int64 // installation tokens minted
+	Unauthorized    atomic.Int64 // 401 responses served in App mode
 }
 
 // New creates and starts a new stub. Caller must call Close() to release
@@ -134,6 +167,15 @@ func New(cfg Config) *Stub {
 	if cfg.SecondaryRateLimitRetryAfter == 0 {
 		cfg.SecondaryRateLimitRetryAfter = 60
 	}
+	if cfg.AppInstallationID == 0 {
+		cfg.AppInstallationID = 1
+	}
+	if cfg.AppPermissions == nil {
+		cfg.AppPermissions = map[string]string{"metadata": "read", "contents": "read"}
+	}
+	if cfg.AppTokenTTL == 0 {
+		cfg.AppTokenTTL = time.Hour
+	}
 
 	s := &Stub{
 		cfg:           cfg,
@@ -141,6 +183,7 @@ func New(cfg Config) *Stub {
 		rateRemaining: cfg.RateLimit,
 		rateResetAt:   cfg.Now().Add(cfg.Window),
 		repoETag:      map[string]string{},
+		appTokens:     map[string]time.Time{},
 	}
 	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
 	return s
@@ -168,6 +211,8 @@ func (s *Stub) Reset() {
 	s.NotModifiedHits.Store(0)
 	s.ActivityCalls.Store(0)
 	s.RateLimitedHits.Store(0)
+	s.TokenExchanges.Store(0)
+	s.Unauthorized.Store(0)
 }
 
 // Snapshot is a read-only view of the stub's instrumentation counters.
@@ -177,6 +222,8 @@ type Snapshot struct {
 	NotModifiedHits int64
 	ActivityCalls   int64
 	RateLimitedHits int64
+	TokenExchanges  int64
+	Unauthorized    int64
 	RateRemaining   int
 }
 
@@ -191,6 +238,8 @@ func (s *Stub) Snapshot() Snapshot {
 		NotModifiedHits: s.NotModifiedHits.Load(),
 		ActivityCalls:   s.ActivityCalls.Load(),
 		RateLimitedHits: s.RateLimitedHits.Load(),
+		TokenExchanges:  s.TokenExchanges.Load(),
+		Unauthorized:    s.Unauthorized.Load(),
 		RateRemaining:   rem,
 	}
 }
@@ -207,6 +256,20 @@ func (s *Stub) CurrentETag(fullName string) string {
 // serve is the single httptest handler. It dispatches by path and
 // method, applies rate-limit accounting, and finally writes the body.
 func (s *Stub) serve(w http.ResponseWriter, r *http.Request) {
+	// App mode: the /app endpoints authenticate with the App JWT, all
+	// others with an installation token.
+	if s.cfg.AppID != 0 {
+		if strings.HasPrefix(r.URL.Path, "/app/") {
+			s.handleApp(w, r)
+			return
+		}
+		if !s.validInstallationToken(r) {
+			s.Unauthorized.Add(1)
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+	}
+
 	// Decide whether to inject a fault before doing real work. The
 	// injectors are ordered: primary (403) wins over secondary (429);
 	// both consume one request from the budget.
@@ -448,6 +511,104 @@ func (s *Stub) handleGraphQL(w http.ResponseWriter, r *http.Request) {
 	s.GraphQLCalls.Add(1)
 }
 
+// handleApp serves the GitHub App endpoints.
+func (s *Stub) handleApp(w http.ResponseWriter, r *http.Request) {
+	if err := s.verifyAppJWT(r); err != nil {
+		s.Unauthorized.Add(1)
+		http.Error(w, `{"message":"`+err.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+	installPath := "/app/installations/" + strconv.FormatInt(s.cfg.AppInstallationID, 10)
+	installation := map[string]interface{}{
+		"id":      s.cfg.AppInstallationID,
+		"app_id":  s.cfg.AppID,
+		"account": map[string]string{"login": "stub-org"},
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/app/installations":
+		json.NewEncoder(w).Encode([]interface{}{installation})
+	case r.Method == http.MethodGet && r.URL.Path == installPath:
+		json.NewEncoder(w).Encode(installation)
+	case r.Method == http.MethodPost && r.URL.Path == installPath+"/access_tokens":
+		s.mu.Lock()
+		s.appTokenSeq++
+		token := fmt.Sprintf("ghs_stub_%d", s.appTokenSeq)
+		expires := s.cfg.Now().Add(s.cfg.AppTokenTTL)
+		s.appTokens[token] = expires
+		s.mu.Unlock()
+		s.TokenExchanges.Add(1)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":                token,
+			"expires_at":           expires.UTC().Format(time.RFC3339),
+			"permissions":          s.cfg.AppPermissions,
+			"repository_selection": "all",
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// verifyAppJWT checks the App JWT's algorithm, issuer and expiry and,
+// when AppPublicKey is set, its signature.
+func (s *Stub) verifyAppJWT(r *http.Request) error {
+	parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed jwt")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Exp int64  `json:"exp"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "RS256" {
+		return fmt.Errorf("jwt must use RS256")
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return fmt.Errorf("malformed jwt claims")
+	}
+	if claims.Iss != strconv.FormatInt(s.cfg.AppID, 10) {
+		return fmt.Errorf("jwt issuer %q is not app %d", claims.Iss, s.cfg.AppID)
+	}
+	if !s.cfg.Now().Before(time.Unix(claims.Exp, 0)) {
+		return fmt.Errorf("jwt expired")
+	}
+	if s.cfg.AppPublicKey != nil {
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return fmt.Errorf("malformed jwt signature")
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(s.cfg.AppPublicKey, crypto.SHA256, digest[:], sig); err != nil {
+			return fmt.Errorf("jwt signature does not verify")
+		}
+	}
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// validInstallationToken reports whether r carries an installation
+// token the stub minted and that has not expired.
+func (s *Stub) validInstallationToken(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.appTokens[token]
+	return ok && s.cfg.Now().Before(expires)
+}
+
 // repoResponse mints a stable repository JSON for the REST handler.
 func repoResponse(owner, name string) map[string]interface{} {
 	return map[string]interface{}{
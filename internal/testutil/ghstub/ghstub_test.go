@@ -2,7 +2,10 @@ package ghstub
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -192,6 +195,55 @@ func TestStub_SecondaryRateLimit_L5b(t *testing.T) {
 	}
 }
 
+func TestStub_AppMode_RequiresUnexpiredInstallationToken(t *testing.T) {
+	clock := &simClock{now: time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)}
+	stub := New(Config{Now: clock.Now, AppID: 42})
+	defer stub.Close()
+
+	resp, _ := http.Get(stub.URL() + "/repos/foo/bar")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("tokenless status = %d, want 401", resp.StatusCode)
+	}
+
+	// Unsigned JWT: accepted because no AppPublicKey is configured.
+	enc := base64.RawURLEncoding.EncodeToString
+	jwt := enc([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." +
+		enc([]byte(`{"iss":"42","exp":`+strconv.FormatInt(clock.Now().Add(5*time.Minute).Unix(), 10)+`}`)) + ".sig"
+	req, _ := http.NewRequest(http.MethodPost, stub.URL()+"/app/installations/1/access_tokens", nil)
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	var body struct {
+		Token string `json:"token"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || body.Token == "" {
+		t.Fatalf("exchange status = %d token = %q", resp.StatusCode, body.Token)
+	}
+
+	get := func() int {
+		req, _ := http.NewRequest(http.MethodGet, stub.URL()+"/repos/foo/bar", nil)
+		req.Header.Set("Authorization", "Bearer "+body.Token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := get(); code != http.StatusOK {
+		t.Errorf("status with fresh token = %d, want 200", code)
+	}
+	clock.Advance(61 * time.Minute)
+	if code := get(); code != http.StatusUnauthorized {
+		t.Errorf("status with expired token = %d, want 401", code)
+	}
+}
+
 // simClock is a deterministic clock for the smoke tests.
 type simClock struct {
 	mu  sync.Mutex